@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package createconfigcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// batch creates the DIDs of the members concurrently, reporting a line per result. The DIDs created are saved to
+// the checkpoint file, if any, so a failed run can be resumed without recreating the DIDs already created.
+type batch struct {
+	parameters *parameters
+	out        io.Writer
+
+	mutex sync.Mutex
+	// DIDs by member domain, loaded from and saved to the checkpoint file
+	dids     map[string]string
+	done     int
+	created  int
+	resumed  int
+	failed   int
+	firstErr error
+}
+
+// createDIDs creates the DIDs of the members, returning them by member domain
+func createDIDs(parameters *parameters) (map[string]string, error) {
+	b := &batch{parameters: parameters, out: parameters.out, dids: map[string]string{}}
+
+	if b.out == nil {
+		b.out = ioutil.Discard
+	}
+
+	if err := b.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	concurrency := parameters.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	members := make(chan *memberData)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for member := range members {
+				b.createDID(member)
+			}
+		}()
+	}
+
+	for _, member := range parameters.config.MembersData {
+		members <- member
+	}
+
+	close(members)
+	wg.Wait()
+
+	fmt.Fprintf(b.out, "%d DIDs created, %d resumed from checkpoint, %d failed\n", // nolint: errcheck
+		b.created, b.resumed, b.failed)
+
+	if b.failed > 0 {
+		return nil, fmt.Errorf("failed to create %d of %d DIDs: %w", b.failed, len(parameters.config.MembersData),
+			b.firstErr)
+	}
+
+	return b.dids, nil
+}
+
+func (b *batch) createDID(member *memberData) {
+	b.mutex.Lock()
+	did, resumed := b.dids[member.Domain]
+	b.mutex.Unlock()
+
+	var err error
+
+	if !resumed {
+		doc, e := createDID(b.parameters.didClient, b.parameters.sidetreeURL, &member.jsonWebKey)
+		if e != nil {
+			err = fmt.Errorf("create DID of '%s': %w", member.Domain, e)
+		} else {
+			did = doc.ID
+		}
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.done++
+
+	progress := fmt.Sprintf("[%d/%d] %s", b.done, len(b.parameters.config.MembersData), member.Domain)
+
+	switch {
+	case err != nil:
+		b.failed++
+
+		if b.firstErr == nil {
+			b.firstErr = err
+		}
+
+		fmt.Fprintf(b.out, "%s: failed: %s\n", progress, err) // nolint: errcheck
+	case resumed:
+		b.resumed++
+
+		fmt.Fprintf(b.out, "%s: %s (checkpoint)\n", progress, did) // nolint: errcheck
+	default:
+		b.created++
+		b.dids[member.Domain] = did
+
+		// the DID is created, so failing to save it only prevents resuming
+		if err = b.saveCheckpoint(); err != nil {
+			fmt.Fprintf(b.out, "%s: %s, failed to save checkpoint: %s\n", progress, did, err) // nolint: errcheck
+
+			return
+		}
+
+		fmt.Fprintf(b.out, "%s: %s\n", progress, did) // nolint: errcheck
+	}
+}
+
+func (b *batch) loadCheckpoint() error {
+	if b.parameters.checkpointFile == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(b.parameters.checkpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint file '%s': %w", b.parameters.checkpointFile, err)
+	}
+
+	if err := json.Unmarshal(data, &b.dids); err != nil {
+		return fmt.Errorf("failed to unmarshal checkpoint file '%s': %w", b.parameters.checkpointFile, err)
+	}
+
+	return nil
+}
+
+// saveCheckpoint must be called with the mutex locked
+func (b *batch) saveCheckpoint() error {
+	if b.parameters.checkpointFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(b.dids)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(b.parameters.checkpointFile, data, 0600)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package createconfigcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	gojose "github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+)
+
+type batchDIDClient struct {
+	mutex sync.Mutex
+	calls int
+	err   error
+}
+
+func (c *batchDIDClient) CreateDID(domain string, opts ...did.CreateDIDOption) (*docdid.Doc, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.calls++
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &docdid.Doc{ID: fmt.Sprintf("did:test:%d", c.calls)}, nil
+}
+
+func batchParameters(client didClient, members ...string) *parameters {
+	c := &config{}
+
+	for _, m := range members {
+		c.MembersData = append(c.MembersData, &memberData{Domain: m, jsonWebKey: gojose.JSONWebKey{Key: []byte("k")}})
+	}
+
+	return &parameters{didClient: client, config: c, out: &bytes.Buffer{}}
+}
+
+func TestCreateDIDs(t *testing.T) {
+	t.Run("success - concurrent", func(t *testing.T) {
+		client := &batchDIDClient{}
+		p := batchParameters(client, "one", "two", "three")
+		p.concurrency = 2
+
+		dids, err := createDIDs(p)
+		require.NoError(t, err)
+		require.Len(t, dids, 3)
+		require.Equal(t, 3, client.calls)
+
+		out := p.out.(*bytes.Buffer).String()
+		require.Contains(t, out, "[3/3]")
+		require.Contains(t, out, "3 DIDs created, 0 resumed from checkpoint, 0 failed")
+	})
+
+	t.Run("success - resumed from checkpoint", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		checkpoint := path.Join(dir, "checkpoint.json")
+		require.NoError(t, ioutil.WriteFile(checkpoint, []byte(`{"one":"did:test:one"}`), 0600))
+
+		client := &batchDIDClient{}
+		p := batchParameters(client, "one", "two")
+		p.checkpointFile = checkpoint
+
+		dids, err := createDIDs(p)
+		require.NoError(t, err)
+		require.Equal(t, "did:test:one", dids["one"])
+		require.Equal(t, 1, client.calls)
+
+		out := p.out.(*bytes.Buffer).String()
+		require.Contains(t, out, "one: did:test:one (checkpoint)")
+		require.Contains(t, out, "1 DIDs created, 1 resumed from checkpoint, 0 failed")
+
+		data, err := ioutil.ReadFile(checkpoint) // nolint: gosec
+		require.NoError(t, err)
+
+		saved := map[string]string{}
+		require.NoError(t, json.Unmarshal(data, &saved))
+		require.Equal(t, dids, saved)
+	})
+
+	t.Run("failure - create DID", func(t *testing.T) {
+		p := batchParameters(&batchDIDClient{err: errors.New("sidetree unavailable")}, "one", "two")
+
+		_, err := createDIDs(p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create 2 of 2 DIDs: create DID of")
+		require.Contains(t, err.Error(), "sidetree unavailable")
+		require.Contains(t, p.out.(*bytes.Buffer).String(), "0 DIDs created, 0 resumed from checkpoint, 2 failed")
+	})
+
+	t.Run("failure - invalid checkpoint", func(t *testing.T) {
+		file, err := ioutil.TempFile("", "*.json")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+		p := batchParameters(&batchDIDClient{}, "one")
+		p.checkpointFile = file.Name()
+
+		_, err = createDIDs(p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal checkpoint file")
+
+		p.checkpointFile = os.TempDir()
+
+		_, err = createDIDs(p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read checkpoint file")
+	})
+
+	t.Run("failure - checkpoint not saved", func(t *testing.T) {
+		p := batchParameters(&batchDIDClient{}, "one")
+		p.checkpointFile = path.Join(os.TempDir(), "missing", "checkpoint.json")
+
+		dids, err := createDIDs(p)
+		require.NoError(t, err)
+		require.Len(t, dids, 1)
+		require.Contains(t, p.out.(*bytes.Buffer).String(), "failed to save checkpoint")
+	})
+}
+
+func TestGetBatchParameters(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		os.Clearenv()
+
+		concurrency, checkpointFile, err := getBatchParameters(GetCreateConfigCmd())
+		require.NoError(t, err)
+		require.Equal(t, 1, concurrency)
+		require.Empty(t, checkpointFile)
+	})
+
+	t.Run("invalid concurrency", func(t *testing.T) {
+		require.NoError(t, os.Setenv(concurrencyEnvKey, "0"))
+
+		defer func() { require.NoError(t, os.Unsetenv(concurrencyEnvKey)) }()
+
+		_, _, err := getBatchParameters(GetCreateConfigCmd())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid concurrency '0'")
+	})
+}
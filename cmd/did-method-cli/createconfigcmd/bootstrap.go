@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package createconfigcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+)
+
+const wellKnownDirectory = ".well-known"
+
+// GetBootstrapCmd returns the Cobra bootstrap command.
+func GetBootstrapCmd() *cobra.Command {
+	bootstrapCmd := createBootstrapCmd()
+
+	createFlags(bootstrapCmd)
+
+	return bootstrapCmd
+}
+
+func createBootstrapCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Bootstrap a consortium from a manifest",
+		Long: "Create the DIDs of the consortium members, the signed consortium and stakeholder configs and the " +
+			"did-configurations described by the config file, and lay them out in a directory per domain, ready to " +
+			"be published under the domain's web root",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parameters, outputDirectory, err := getParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err = validateManifest(parameters.config); err != nil {
+				return err
+			}
+
+			filesData, didConfData, err := createConfig(parameters)
+			if err != nil {
+				return err
+			}
+
+			err = os.RemoveAll(outputDirectory)
+			if err != nil {
+				return fmt.Errorf("remove outputDirectory: %w", err)
+			}
+
+			return writeBootstrap(outputDirectory, parameters.config, filesData, didConfData)
+		},
+	}
+}
+
+// validateManifest checks the config file describes a consortium before any member DID is created
+func validateManifest(config *config) error {
+	if config.ConsortiumData.Domain == "" {
+		return fmt.Errorf("consortium domain is required")
+	}
+
+	if len(config.MembersData) == 0 {
+		return fmt.Errorf("at least one member is required")
+	}
+
+	domains := map[string]bool{config.ConsortiumData.Domain: true}
+
+	for _, member := range config.MembersData {
+		if member.Domain == "" {
+			return fmt.Errorf("member domain is required")
+		}
+
+		if domains[member.Domain] {
+			return fmt.Errorf("duplicate domain '%s'", member.Domain)
+		}
+
+		domains[member.Domain] = true
+	}
+
+	return nil
+}
+
+// writeBootstrap lays out the files each domain publishes under `<output directory>/<domain>/.well-known`: the
+// consortium config in `did-trustbloc/<consortium domain>.json`, which members mirror, and for each member its
+// stakeholder config in `did-trustbloc/<member domain>.json` and the `did-configuration.json` linking its domain
+// to its DID
+func writeBootstrap(outputDirectory string, config *config, filesData, didConfData map[string][]byte) error {
+	consortiumDomain := config.ConsortiumData.Domain
+
+	err := writeWellKnownFile(outputDirectory, consortiumDomain, path.Join("did-trustbloc", consortiumDomain+".json"),
+		filesData[consortiumDomain])
+	if err != nil {
+		return err
+	}
+
+	for _, member := range config.MembersData {
+		files := map[string][]byte{
+			path.Join("did-trustbloc", consortiumDomain+".json"): filesData[consortiumDomain],
+			path.Join("did-trustbloc", member.Domain+".json"):    filesData[member.Domain],
+			"did-configuration.json":                             didConfData[member.Domain],
+		}
+
+		for name, data := range files {
+			if err := writeWellKnownFile(outputDirectory, member.Domain, name, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeWellKnownFile(outputDirectory, domain, name string, data []byte) error {
+	filePath := path.Join(outputDirectory, domain, wellKnownDirectory, name)
+
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package createconfigcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapCmd(t *testing.T) {
+	t.Run("test missing arg", func(t *testing.T) {
+		os.Clearenv()
+
+		cmd := GetBootstrapCmd()
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither sidetree-url (command line flag) nor DID_METHOD_CLI_SIDETREE_URL")
+	})
+
+	t.Run("test invalid manifest", func(t *testing.T) {
+		file, err := ioutil.TempFile("", "*.json")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+		_, err = file.WriteString(`{"consortium_data":{"domain":"consortium.net"}}`)
+		require.NoError(t, err)
+
+		cmd := GetBootstrapCmd()
+
+		var args []string
+		args = append(args, sidetreeURLArg()...)
+		args = append(args, configFileArg(file.Name())...)
+
+		cmd.SetArgs(args)
+
+		err = cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one member is required")
+	})
+
+	t.Run("test bootstrap and write the files of each domain", func(t *testing.T) {
+		os.Clearenv()
+
+		jwkFile, err := ioutil.TempFile("", "*.json")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.Remove(jwkFile.Name())) }()
+
+		_, err = jwkFile.WriteString(jwkData)
+		require.NoError(t, err)
+
+		file, err := ioutil.TempFile("", "*.json")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+		_, err = file.WriteString(fmt.Sprintf(configData, jwkFile.Name()))
+		require.NoError(t, err)
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+
+		defer func() { require.NoError(t, os.Unsetenv(configFileEnvKey)) }()
+
+		c, err := getConfig(&cobra.Command{})
+		require.NoError(t, err)
+		require.NoError(t, validateManifest(c))
+
+		filesData, didConfData, err := createConfig(&parameters{config: c,
+			didClient: &mockDIDClient{&docdid.Doc{ID: "did:test:123"}}})
+		require.NoError(t, err)
+
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		require.NoError(t, writeBootstrap(dir, c, filesData, didConfData))
+
+		for _, f := range []string{
+			"consortium.net/.well-known/did-trustbloc/consortium.net.json",
+			"stakeholder.one/.well-known/did-trustbloc/consortium.net.json",
+			"stakeholder.one/.well-known/did-trustbloc/stakeholder.one.json",
+			"stakeholder.one/.well-known/did-configuration.json",
+		} {
+			_, err = os.Stat(path.Join(dir, f))
+			require.NoError(t, err, f)
+		}
+
+		mirror, err := ioutil.ReadFile(path.Join(dir, "stakeholder.one/.well-known/did-trustbloc/consortium.net.json"))
+		require.NoError(t, err)
+		require.Equal(t, filesData["consortium.net"], mirror)
+
+		didConf, err := ioutil.ReadFile(path.Join(dir, "stakeholder.one/.well-known/did-configuration.json"))
+		require.NoError(t, err)
+		require.True(t, json.Valid(didConf))
+
+		require.Error(t, writeBootstrap(file.Name(), c, filesData, didConfData))
+	})
+}
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config
+		err    string
+	}{
+		{name: "missing consortium domain", config: &config{}, err: "consortium domain is required"},
+		{name: "missing member domain", config: &config{ConsortiumData: consortiumData{Domain: "consortium.net"},
+			MembersData: []*memberData{{}}}, err: "member domain is required"},
+		{name: "duplicate member domain", config: &config{ConsortiumData: consortiumData{Domain: "consortium.net"},
+			MembersData: []*memberData{{Domain: "stakeholder.one"}, {Domain: "stakeholder.one"}}},
+			err: "duplicate domain 'stakeholder.one'"},
+		{name: "member domain of the consortium", config: &config{
+			ConsortiumData: consortiumData{Domain: "consortium.net"},
+			MembersData:    []*memberData{{Domain: "consortium.net"}}},
+			err: "duplicate domain 'consortium.net'"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateManifest(tc.config)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
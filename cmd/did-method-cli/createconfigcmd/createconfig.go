@@ -10,6 +10,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -21,8 +22,12 @@ import (
 	gojose "github.com/square/go-jose/v3"
 	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
 	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/piv"
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/pkcs11"
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/webkms"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/didconfiguration"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
@@ -54,6 +59,37 @@ const (
 	configFileFlagUsage = "Config file include data required for creating well known config files " +
 		" Alternatively, this can be set with the following environment variable: " + configFileEnvKey
 
+	webKMSAuthTokenFlagName  = "webkms-auth-token"
+	webKMSAuthTokenEnvKey    = "DID_METHOD_CLI_WEBKMS_AUTH_TOKEN" //nolint: gosec
+	webKMSAuthTokenFlagUsage = "The auth token used to access WebKMS keys referenced in the config file " +
+		" Alternatively, this can be set with the following environment variable: " + webKMSAuthTokenEnvKey
+
+	pkcs11ModuleFlagName  = "pkcs11-module"
+	pkcs11ModuleEnvKey    = "DID_METHOD_CLI_PKCS11_MODULE"
+	pkcs11ModuleFlagUsage = "Path to the PKCS#11 module used to access HSM or PIV token keys referenced in the config" +
+		" file " +
+		" Alternatively, this can be set with the following environment variable: " + pkcs11ModuleEnvKey
+
+	pkcs11TokenLabelFlagName  = "pkcs11-token-label"
+	pkcs11TokenLabelEnvKey    = "DID_METHOD_CLI_PKCS11_TOKEN_LABEL"
+	pkcs11TokenLabelFlagUsage = "Label of the PKCS#11 token holding the keys, defaults to the first available slot " +
+		" Alternatively, this can be set with the following environment variable: " + pkcs11TokenLabelEnvKey
+
+	pkcs11PINFlagName  = "pkcs11-pin"
+	pkcs11PINEnvKey    = "DID_METHOD_CLI_PKCS11_PIN" //nolint: gosec
+	pkcs11PINFlagUsage = "The user PIN of the PKCS#11 token, prompted for PIV tokens if not set " +
+		" Alternatively, this can be set with the following environment variable: " + pkcs11PINEnvKey
+
+	concurrencyFlagName  = "concurrency"
+	concurrencyEnvKey    = "DID_METHOD_CLI_CONCURRENCY"
+	concurrencyFlagUsage = "Number of member DIDs created concurrently. Defaults to 1." +
+		" Alternatively, this can be set with the following environment variable: " + concurrencyEnvKey
+
+	checkpointFileFlagName  = "checkpoint-file"
+	checkpointFileEnvKey    = "DID_METHOD_CLI_CHECKPOINT_FILE"
+	checkpointFileFlagUsage = "File saving the member DIDs created, so a failed run resumes without recreating them." +
+		" Alternatively, this can be set with the following environment variable: " + checkpointFileEnvKey
+
 	outputDirectoryFlagName  = "output-directory"
 	outputDirectoryEnvKey    = "DID_METHOD_CLI_OUTPUT_DIRECTORY" //nolint: gosec
 	outputDirectoryFlagUsage = "Output directory " +
@@ -70,6 +106,8 @@ type consortiumData struct {
 	Domain string `json:"domain,omitempty"`
 	// Policy contains the consortium policy configuration
 	Policy models.ConsortiumPolicy `json:"policy"`
+	// Version is the sequence number of the consortium config
+	Version uint64 `json:"version,omitempty"`
 }
 
 type memberData struct {
@@ -79,8 +117,17 @@ type memberData struct {
 	Policy models.StakeholderSettings `json:"policy"`
 	// Endpoints is a list of sidetree endpoints owned by this stakeholder organization
 	Endpoints []string `json:"endpoints"`
+	// JWKSURL is the url of a JSON Web Key Set of the member's signing keys, on the member's domain
+	JWKSURL string `json:"jwksUrl,omitempty"`
 	// PrivateKeyJwk is privatekey jwk file
 	PrivateKeyJwkPath string `json:"privateKeyJwkPath,omitempty"`
+	// WebKMSKeyURL is the url of a WebKMS key, used instead of PrivateKeyJwkPath when the key is held by a KMS
+	WebKMSKeyURL string `json:"webKmsKeyUrl,omitempty"`
+	// PKCS11KeyLabel is the label of a key held by a PKCS#11 token (HSM), used instead of PrivateKeyJwkPath
+	PKCS11KeyLabel string `json:"pkcs11KeyLabel,omitempty"`
+	// PIVSlot is the slot (9a, 9c, 9d or 9e) of a key held by a PIV token (eg. YubiKey), used instead of
+	// PrivateKeyJwkPath
+	PIVSlot string `json:"pivSlot,omitempty"`
 
 	jsonWebKey gojose.JSONWebKey
 	sigKey     gojose.SigningKey
@@ -91,9 +138,13 @@ type didClient interface {
 }
 
 type parameters struct {
-	sidetreeURL string
-	didClient   didClient
-	config      *config
+	sidetreeURL    string
+	didClient      didClient
+	config         *config
+	concurrency    int
+	checkpointFile string
+	// out receives the progress of the DID creation
+	out io.Writer
 }
 
 // GetCreateConfigCmd returns the Cobra create conifg command.
@@ -111,41 +162,11 @@ func createCreateConfigCmd() *cobra.Command {
 		Short: "Create did method config file",
 		Long:  "Create did method config file",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sidetreeURL, err := cmdutils.GetUserSetVarFromString(cmd, sidetreeURLFlagName, sidetreeURLEnvKey,
-				false)
-			if err != nil {
-				return err
-			}
-
-			rootCAs, err := getRootCAs(cmd)
+			parameters, outputDirectory, err := getParameters(cmd)
 			if err != nil {
 				return err
 			}
 
-			sidetreeWriteToken, err := cmdutils.GetUserSetVarFromString(cmd, sidetreeWriteTokenFlagName,
-				sidetreeWriteTokenEnvKey, true)
-			if err != nil {
-				return err
-			}
-
-			outputDirectory, err := cmdutils.GetUserSetVarFromString(cmd, outputDirectoryFlagName,
-				outputDirectoryEnvKey, true)
-			if err != nil {
-				return err
-			}
-
-			config, err := getConfig(cmd)
-			if err != nil {
-				return err
-			}
-
-			parameters := &parameters{
-				sidetreeURL: strings.TrimSpace(sidetreeURL),
-				didClient: did.New(did.WithAuthToken(sidetreeWriteToken),
-					did.WithTLSConfig(&tls.Config{RootCAs: rootCAs})),
-				config: config,
-			}
-
 			filesData, didConfData, err := createConfig(parameters)
 			if err != nil {
 				return err
@@ -166,6 +187,75 @@ func createCreateConfigCmd() *cobra.Command {
 	}
 }
 
+// getParameters returns the parameters of the config creation and the output directory set by the command flags
+func getParameters(cmd *cobra.Command) (*parameters, string, error) {
+	sidetreeURL, err := cmdutils.GetUserSetVarFromString(cmd, sidetreeURLFlagName, sidetreeURLEnvKey,
+		false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rootCAs, err := getRootCAs(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sidetreeWriteToken, err := cmdutils.GetUserSetVarFromString(cmd, sidetreeWriteTokenFlagName,
+		sidetreeWriteTokenEnvKey, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	outputDirectory, err := cmdutils.GetUserSetVarFromString(cmd, outputDirectoryFlagName,
+		outputDirectoryEnvKey, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	concurrency, checkpointFile, err := getBatchParameters(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config, err := getConfig(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &parameters{
+		sidetreeURL: strings.TrimSpace(sidetreeURL),
+		didClient: did.New(did.WithAuthToken(sidetreeWriteToken),
+			did.WithTLSConfig(&tls.Config{RootCAs: rootCAs})),
+		config:         config,
+		concurrency:    concurrency,
+		checkpointFile: checkpointFile,
+		out:            cmd.ErrOrStderr(),
+	}, outputDirectory, nil
+}
+
+func getBatchParameters(cmd *cobra.Command) (int, string, error) {
+	concurrencyString, err := cmdutils.GetUserSetVarFromString(cmd, concurrencyFlagName, concurrencyEnvKey, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	concurrency := 1
+
+	if concurrencyString != "" {
+		concurrency, err = strconv.Atoi(concurrencyString)
+		if err != nil || concurrency < 1 {
+			return 0, "", fmt.Errorf("invalid concurrency '%s', expected a positive number", concurrencyString)
+		}
+	}
+
+	checkpointFile, err := cmdutils.GetUserSetVarFromString(cmd, checkpointFileFlagName, checkpointFileEnvKey, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return concurrency, checkpointFile, nil
+}
+
 func writeConfig(outputDirectory string, filesData map[string][]byte) error {
 	if outputDirectory != "" {
 		if err := os.MkdirAll(outputDirectory, 0755); err != nil {
@@ -236,19 +326,140 @@ func getConfig(cmd *cobra.Command) (*config, error) {
 	}
 
 	for _, member := range config.MembersData {
-		jwkData, err := ioutil.ReadFile(member.PrivateKeyJwkPath) //nolint: gosec
-		if err != nil {
-			return nil, fmt.Errorf("failed to read jwk file '%s' : %w", member.PrivateKeyJwkPath, err)
+		if err := setMemberKey(cmd, member); err != nil {
+			return nil, err
 		}
+	}
+
+	return &config, nil
+}
+
+func setMemberKey(cmd *cobra.Command, member *memberData) error {
+	switch {
+	case member.WebKMSKeyURL != "":
+		return setWebKMSKey(cmd, member)
+	case member.PKCS11KeyLabel != "":
+		return setPKCS11Key(cmd, member)
+	case member.PIVSlot != "":
+		return setPIVKey(cmd, member)
+	}
+
+	jwkData, err := ioutil.ReadFile(member.PrivateKeyJwkPath) //nolint: gosec
+	if err != nil {
+		return fmt.Errorf("failed to read jwk file '%s' : %w", member.PrivateKeyJwkPath, err)
+	}
+
+	if err := member.jsonWebKey.UnmarshalJSON(jwkData); err != nil {
+		return fmt.Errorf("failed to unmarshal to jwk: %w", err)
+	}
+	// TODO add support for ECDSA using P-256 and SHA-256
+	member.sigKey = gojose.SigningKey{Key: member.jsonWebKey.Key, Algorithm: gojose.EdDSA}
+
+	return nil
+}
+
+func setWebKMSKey(cmd *cobra.Command, member *memberData) error {
+	authToken, err := cmdutils.GetUserSetVarFromString(cmd, webKMSAuthTokenFlagName, webKMSAuthTokenEnvKey, true)
+	if err != nil {
+		return err
+	}
 
-		if err := member.jsonWebKey.UnmarshalJSON(jwkData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal to jwk: %w", err)
+	rootCAs, err := getRootCAs(cmd)
+	if err != nil {
+		return err
+	}
+
+	signer, err := webkms.NewSigner(member.WebKMSKeyURL, webkms.WithAuthToken(authToken),
+		webkms.WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+		webkms.WithKeyID(path.Base(member.WebKMSKeyURL)))
+	if err != nil {
+		return fmt.Errorf("failed to create webkms signer for '%s': %w", member.Domain, err)
+	}
+
+	member.jsonWebKey = *signer.Public()
+	member.sigKey = gojose.SigningKey{Key: signer, Algorithm: signer.Algs()[0]}
+
+	return nil
+}
+
+func setPKCS11Key(cmd *cobra.Command, member *memberData) error {
+	module, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11ModuleFlagName, pkcs11ModuleEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	tokenLabel, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11TokenLabelFlagName, pkcs11TokenLabelEnvKey, true)
+	if err != nil {
+		return err
+	}
+
+	pin, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11PINFlagName, pkcs11PINEnvKey, true)
+	if err != nil {
+		return err
+	}
+
+	signer, err := pkcs11.NewSigner(module, member.PKCS11KeyLabel, pkcs11.WithTokenLabel(tokenLabel),
+		pkcs11.WithPIN(pin))
+	if err != nil {
+		return fmt.Errorf("failed to create pkcs11 signer for '%s': %w", member.Domain, err)
+	}
+
+	member.jsonWebKey = *signer.Public()
+	member.sigKey = gojose.SigningKey{Key: signer, Algorithm: signer.Algs()[0]}
+
+	return nil
+}
+
+func setPIVKey(cmd *cobra.Command, member *memberData) error {
+	module, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11ModuleFlagName, pkcs11ModuleEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	tokenLabel, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11TokenLabelFlagName, pkcs11TokenLabelEnvKey, true)
+	if err != nil {
+		return err
+	}
+
+	pin, err := cmdutils.GetUserSetVarFromString(cmd, pkcs11PINFlagName, pkcs11PINEnvKey, true)
+	if err != nil {
+		return err
+	}
+
+	if pin == "" {
+		pin, err = readPIN(fmt.Sprintf("PIN of the PIV token holding the key of '%s': ", member.Domain))
+		if err != nil {
+			return fmt.Errorf("failed to read piv pin: %w", err)
 		}
-		// TODO add support for ECDSA using P-256 and SHA-256
-		member.sigKey = gojose.SigningKey{Key: member.jsonWebKey.Key, Algorithm: gojose.EdDSA}
 	}
 
-	return &config, nil
+	signer, err := piv.NewSigner(module, member.PIVSlot, pkcs11.WithTokenLabel(tokenLabel), pkcs11.WithPIN(pin))
+	if err != nil {
+		return fmt.Errorf("failed to create piv signer for '%s': %w", member.Domain, err)
+	}
+
+	member.jsonWebKey = *signer.Public()
+	member.sigKey = gojose.SigningKey{Key: signer, Algorithm: signer.Algs()[0]}
+
+	return nil
+}
+
+// readPIN prompts for a PIN on the terminal, without echoing it. No PIN is read if stdin isn't a terminal.
+// This variable may be overridden by unit tests.
+// nolint: gochecknoglobals
+var readPIN = func(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return "", nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt) // nolint: errcheck
+
+	pin, err := terminal.ReadPassword(fd)
+
+	fmt.Fprintln(os.Stderr) // nolint: errcheck
+
+	return string(pin), err
 }
 
 func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
@@ -283,6 +494,12 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(sidetreeWriteTokenFlagName, "", "", sidetreeWriteTokenFlagUsage)
 	startCmd.Flags().StringP(configFileFlagName, "", "", configFileFlagUsage)
 	startCmd.Flags().StringP(outputDirectoryFlagName, "", "", outputDirectoryFlagUsage)
+	startCmd.Flags().StringP(webKMSAuthTokenFlagName, "", "", webKMSAuthTokenFlagUsage)
+	startCmd.Flags().StringP(pkcs11ModuleFlagName, "", "", pkcs11ModuleFlagUsage)
+	startCmd.Flags().StringP(pkcs11TokenLabelFlagName, "", "", pkcs11TokenLabelFlagUsage)
+	startCmd.Flags().StringP(pkcs11PINFlagName, "", "", pkcs11PINFlagUsage)
+	startCmd.Flags().StringP(concurrencyFlagName, "", "", concurrencyFlagUsage)
+	startCmd.Flags().StringP(checkpointFileFlagName, "", "", checkpointFileFlagUsage)
 }
 
 func createConfig(parameters *parameters) (map[string][]byte, map[string][]byte, error) {
@@ -292,45 +509,24 @@ func createConfig(parameters *parameters) (map[string][]byte, map[string][]byte,
 	didConfData := make(map[string][]byte)
 
 	consortium := models.Consortium{Domain: parameters.config.ConsortiumData.Domain,
-		Policy: parameters.config.ConsortiumData.Policy}
-
-	for _, member := range parameters.config.MembersData {
-		didDoc, err := createDID(parameters.didClient, parameters.sidetreeURL, &member.jsonWebKey)
-		if err != nil {
-			return nil, nil, err
-		}
+		Policy: parameters.config.ConsortiumData.Policy, Version: parameters.config.ConsortiumData.Version}
 
-		pubKey, err := member.jsonWebKey.Public().MarshalJSON()
-		if err != nil {
-			return nil, nil, err
-		}
-
-		consortium.Members = append(consortium.Members, &models.StakeholderListElement{Domain: member.Domain,
-			DID: didDoc.ID, PublicKey: models.PublicKey{ID: didDoc.ID + "#" + member.jsonWebKey.KeyID,
-				JWK: pubKey}})
-
-		stakeholder := models.Stakeholder{Domain: member.Domain, DID: didDoc.ID,
-			Policy: member.Policy, Endpoints: member.Endpoints}
+	dids, err := createDIDs(parameters)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		stakeholderBytes, err := json.Marshal(stakeholder)
-		if err != nil {
-			return nil, nil, err
+	for _, member := range parameters.config.MembersData {
+		element, jws, didConf, e := createMemberConfig(member, dids[member.Domain])
+		if e != nil {
+			return nil, nil, e
 		}
 
-		jws, err :=
-			signConfig(stakeholderBytes, []gojose.SigningKey{member.sigKey})
-		if err != nil {
-			return nil, nil, err
-		}
+		consortium.Members = append(consortium.Members, element)
 
 		sigKeys = append(sigKeys, member.sigKey)
 
-		filesData[member.Domain] = []byte(jws)
-
-		didConf, err := createDIDConfiguration(member.Domain, didDoc.ID, 0, &member.sigKey)
-		if err != nil {
-			return nil, nil, fmt.Errorf("did configuration failed %w: ", err)
-		}
+		filesData[member.Domain] = jws
 
 		didConfData[member.Domain] = didConf
 	}
@@ -350,6 +546,39 @@ func createConfig(parameters *parameters) (map[string][]byte, map[string][]byte,
 	return filesData, didConfData, nil
 }
 
+// createMemberConfig returns the consortium list element, the signed stakeholder config and the did-configuration
+// of a member
+func createMemberConfig(member *memberData, memberDID string) (*models.StakeholderListElement, []byte, []byte,
+	error) {
+	pubKey, err := member.jsonWebKey.Public().MarshalJSON()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	element := &models.StakeholderListElement{Domain: member.Domain, DID: memberDID,
+		PublicKey: models.PublicKey{ID: memberDID + "#" + member.jsonWebKey.KeyID, JWK: pubKey}}
+
+	stakeholder := models.Stakeholder{Domain: member.Domain, DID: memberDID,
+		Policy: member.Policy, Endpoints: member.Endpoints, JWKSURL: member.JWKSURL}
+
+	stakeholderBytes, err := json.Marshal(stakeholder)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	jws, err := signConfig(stakeholderBytes, []gojose.SigningKey{member.sigKey})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	didConf, err := createDIDConfiguration(member.Domain, memberDID, 0, &member.sigKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("did configuration failed %w: ", err)
+	}
+
+	return element, []byte(jws), didConf, nil
+}
+
 func signConfig(configBytes []byte, keys []gojose.SigningKey) (string, error) {
 	signer, err := gojose.NewMultiSigner(keys, nil)
 	if err != nil {
@@ -372,7 +601,7 @@ func createDID(didClient didClient, sidetreeURL string, jwk *gojose.JSONWebKey)
 
 	general := did.PublicKey{
 		ID:       jwk.KeyID,
-		Type:     did.JWSVerificationKey2020,
+		Type:     did.JSONWebKey2020,
 		Encoding: did.PublicKeyEncodingJwk,
 		KeyType:  did.Ed25519KeyType,
 		Value:    pkBytes,
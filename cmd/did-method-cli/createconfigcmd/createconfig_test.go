@@ -6,12 +6,21 @@ SPDX-License-Identifier: Apache-2.0
 package createconfigcmd
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	gojose "github.com/square/go-jose/v3"
 
 	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/trustbloc/trustbloc-did-method/pkg/did"
@@ -227,6 +236,108 @@ func TestCreateConfigCmd(t *testing.T) {
 	})
 }
 
+func TestCreateConfigWithWebKMS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	kmsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer kmsToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/export") {
+			fmt.Fprintf(w, `{"publicKey":"%s"}`, base64.RawURLEncoding.EncodeToString(pub))
+			return
+		}
+
+		var req struct {
+			Message string `json:"message"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		msg, err := base64.RawURLEncoding.DecodeString(req.Message)
+		require.NoError(t, err)
+
+		fmt.Fprintf(w, `{"signature":"%s"}`, base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, msg)))
+	}))
+	defer kmsServer.Close()
+
+	file, err := ioutil.TempFile("", "*.json")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+	_, err = file.WriteString(strings.Replace(configData, `"privateKeyJwkPath": "%s"`,
+		`"webKmsKeyUrl": "`+kmsServer.URL+`/keys/key1"`, 1))
+	require.NoError(t, err)
+
+	t.Run("test create config signed by webkms key", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+		require.NoError(t, os.Setenv(webKMSAuthTokenEnvKey, "kmsToken"))
+
+		c, err := getConfig(&cobra.Command{})
+		require.NoError(t, err)
+		require.Equal(t, "key1", c.MembersData[0].jsonWebKey.KeyID)
+
+		filesData, didConfData, err := createConfig(&parameters{config: c,
+			didClient: &mockDIDClient{&docdid.Doc{ID: "did:test:123"}}})
+		require.NoError(t, err)
+		require.Contains(t, didConfData, "stakeholder.one")
+
+		stakeholderFile, err := gojose.ParseSigned(string(filesData["stakeholder.one"]))
+		require.NoError(t, err)
+
+		_, err = stakeholderFile.Verify(pub)
+		require.NoError(t, err)
+	})
+
+	t.Run("test webkms unauthorized", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create webkms signer")
+	})
+}
+
+func TestCreateConfigWithPKCS11(t *testing.T) {
+	file, err := ioutil.TempFile("", "*.json")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+	_, err = file.WriteString(strings.Replace(configData, `"privateKeyJwkPath": "%s"`,
+		`"pkcs11KeyLabel": "key1"`, 1))
+	require.NoError(t, err)
+
+	t.Run("test pkcs11 module missing", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither pkcs11-module (command line flag) nor DID_METHOD_CLI_PKCS11_MODULE")
+	})
+
+	t.Run("test pkcs11 module not found", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+		require.NoError(t, os.Setenv(pkcs11ModuleEnvKey, "/not/a/module.so"))
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create pkcs11 signer")
+	})
+}
+
 func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
 	os.Clearenv()
 
@@ -256,3 +367,79 @@ type mockDIDClient struct {
 func (m *mockDIDClient) CreateDID(domain string, opts ...did.CreateDIDOption) (*docdid.Doc, error) {
 	return m.createDIDValue, nil
 }
+
+func TestCreateConfigWithPIV(t *testing.T) {
+	file, err := ioutil.TempFile("", "*.json")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.Remove(file.Name())) }()
+
+	_, err = file.WriteString(strings.Replace(configData, `"privateKeyJwkPath": "%s"`,
+		`"pivSlot": "9c"`, 1))
+	require.NoError(t, err)
+
+	prevReadPIN := readPIN
+
+	defer func() { readPIN = prevReadPIN }()
+
+	t.Run("test pin prompted", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+		require.NoError(t, os.Setenv(pkcs11ModuleEnvKey, "/not/a/module.so"))
+
+		var prompt string
+
+		readPIN = func(p string) (string, error) {
+			prompt = p
+
+			return "123456", nil
+		}
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create piv signer for 'stakeholder.one'")
+		require.Contains(t, prompt, "stakeholder.one")
+	})
+
+	t.Run("test pin set by env", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+		require.NoError(t, os.Setenv(pkcs11ModuleEnvKey, "/not/a/module.so"))
+		require.NoError(t, os.Setenv(pkcs11PINEnvKey, "123456"))
+
+		readPIN = func(string) (string, error) {
+			return "", errors.New("unexpected prompt")
+		}
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create piv signer")
+	})
+
+	t.Run("test pin prompt error", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+		require.NoError(t, os.Setenv(pkcs11ModuleEnvKey, "/not/a/module.so"))
+
+		readPIN = func(string) (string, error) {
+			return "", errors.New("no tty")
+		}
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read piv pin: no tty")
+	})
+
+	t.Run("test pkcs11 module missing", func(t *testing.T) {
+		os.Clearenv()
+
+		require.NoError(t, os.Setenv(configFileEnvKey, file.Name()))
+
+		_, err := getConfig(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither pkcs11-module (command line flag) nor DID_METHOD_CLI_PKCS11_MODULE")
+	})
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endpointscmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+)
+
+const (
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "DID_METHOD_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "DID_METHOD_CLI_TLS_CACERTS"
+
+	sidetreeReadTokenFlagName  = "sidetree-read-token"
+	sidetreeReadTokenEnvKey    = "DID_METHOD_CLI_SIDETREE_READ_TOKEN" //nolint: gosec
+	sidetreeReadTokenFlagUsage = "The sidetree read token " +
+		" Alternatively, this can be set with the following environment variable: " + sidetreeReadTokenEnvKey
+
+	formatFlagName  = "format"
+	formatEnvKey    = "DID_METHOD_CLI_FORMAT"
+	formatFlagUsage = "Output format. Possible values [table] [json]. Defaults to table." +
+		" Alternatively, this can be set with the following environment variable: " + formatEnvKey
+
+	tableFormat = "table"
+	jsonFormat  = "json"
+)
+
+type endpointInspector interface {
+	InspectEndpoints(domain string) (*trustbloc.EndpointsReport, error)
+}
+
+// GetEndpointsCmd returns the Cobra endpoints command.
+func GetEndpointsCmd() *cobra.Command {
+	return getEndpointsCmd(func(opts ...trustbloc.Option) endpointInspector {
+		return trustbloc.New(opts...)
+	})
+}
+
+func getEndpointsCmd(newInspector func(opts ...trustbloc.Option) endpointInspector) *cobra.Command {
+	endpointsCmd := &cobra.Command{
+		Use:   "endpoints <domain>",
+		Short: "Inspect the endpoints of a consortium",
+		Long: "Run the discovery and selection of the endpoints of a consortium, and print each stakeholder," +
+			" its endpoints, their selection decision and probe latency",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmdutils.GetUserSetVarFromString(cmd, formatFlagName, formatEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			if format == "" {
+				format = tableFormat
+			}
+
+			if format != tableFormat && format != jsonFormat {
+				return fmt.Errorf("invalid format '%s', expected %s or %s", format, tableFormat, jsonFormat)
+			}
+
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			sidetreeReadToken, err := cmdutils.GetUserSetVarFromString(cmd, sidetreeReadTokenFlagName,
+				sidetreeReadTokenEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			inspector := newInspector(trustbloc.WithTLSConfig(&tls.Config{RootCAs: rootCAs}),
+				trustbloc.WithAuthToken(sidetreeReadToken))
+
+			report, err := inspector.InspectEndpoints(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to inspect endpoints of %s: %w", args[0], err)
+			}
+
+			if format == jsonFormat {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+
+				return encoder.Encode(report)
+			}
+
+			return writeTable(cmd.OutOrStdout(), report)
+		},
+	}
+
+	createFlags(endpointsCmd)
+
+	return endpointsCmd
+}
+
+func writeTable(out io.Writer, report *trustbloc.EndpointsReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STAKEHOLDER\tENDPOINT\tSELECTED\tDECISION\tLATENCY\tPROBE") // nolint: errcheck
+
+	for _, s := range report.Stakeholders {
+		domain := s.Domain
+		if domain == "" {
+			domain = "-"
+		}
+
+		for _, e := range s.Endpoints {
+			probe := "ok"
+			if !e.Probe.OK {
+				probe = e.Probe.Error
+			}
+
+			// nolint: errcheck
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%dms\t%s\n", domain, e.URL, e.Selected, e.Decision, e.Probe.LatencyMS,
+				probe)
+		}
+	}
+
+	return w.Flush()
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString, err := cmdutils.GetUserSetVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsSystemCertPool := false
+	if tlsSystemCertPoolString != "" {
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts, err := cmdutils.GetUserSetVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(endpointsCmd *cobra.Command) {
+	endpointsCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	endpointsCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	endpointsCmd.Flags().StringP(sidetreeReadTokenFlagName, "", "", sidetreeReadTokenFlagUsage)
+	endpointsCmd.Flags().StringP(formatFlagName, "", "", formatFlagUsage)
+}
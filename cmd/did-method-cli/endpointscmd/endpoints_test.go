@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endpointscmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+)
+
+const flag = "--"
+
+type mockInspector struct {
+	report *trustbloc.EndpointsReport
+	err    error
+}
+
+func (m *mockInspector) InspectEndpoints(domain string) (*trustbloc.EndpointsReport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.report.Domain = domain
+
+	return m.report, nil
+}
+
+func testReport() *trustbloc.EndpointsReport {
+	return &trustbloc.EndpointsReport{Stakeholders: []*trustbloc.StakeholderEndpoints{
+		{Domain: "stakeholder.one", Endpoints: []*trustbloc.EndpointReport{
+			{URL: "https://one.example.com/sidetree", Selected: true, Decision: trustbloc.DecisionSelected,
+				Probe: &trustbloc.HealthCheck{OK: true, LatencyMS: 12}},
+		}},
+		{Domain: "stakeholder.two", Endpoints: []*trustbloc.EndpointReport{
+			{URL: "https://two.example.com/sidetree", Decision: trustbloc.DecisionNotSelected,
+				Probe: &trustbloc.HealthCheck{LatencyMS: 3, Error: "connection refused"}},
+		}},
+	}}
+}
+
+func executeCmd(inspector endpointInspector, args ...string) (string, error) {
+	cmd := getEndpointsCmd(func(opts ...trustbloc.Option) endpointInspector {
+		return inspector
+	})
+
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
+
+func TestEndpointsCmd(t *testing.T) {
+	t.Run("success - table", func(t *testing.T) {
+		out, err := executeCmd(&mockInspector{report: testReport()}, "testnet.example.com")
+		require.NoError(t, err)
+		require.Equal(t, ""+
+			"STAKEHOLDER      ENDPOINT                          SELECTED  DECISION"+
+			"                               LATENCY  PROBE\n"+
+			"stakeholder.one  https://one.example.com/sidetree  true      selected"+
+			"                               12ms     ok\n"+
+			"stakeholder.two  https://two.example.com/sidetree  false     not selected by the consortium policy"+
+			"  3ms      connection refused\n", out)
+	})
+
+	t.Run("success - table with resolver", func(t *testing.T) {
+		out, err := executeCmd(&mockInspector{report: &trustbloc.EndpointsReport{
+			Stakeholders: []*trustbloc.StakeholderEndpoints{{Endpoints: []*trustbloc.EndpointReport{
+				{URL: "https://resolver.example.com", Selected: true, Decision: trustbloc.DecisionResolver,
+					Probe: &trustbloc.HealthCheck{OK: true}}}}}}}, "testnet.example.com")
+		require.NoError(t, err)
+		require.Contains(t, out, "-            https://resolver.example.com  true      resolver url configured")
+	})
+
+	t.Run("success - json", func(t *testing.T) {
+		out, err := executeCmd(&mockInspector{report: testReport()}, "testnet.example.com",
+			flag+formatFlagName, jsonFormat)
+		require.NoError(t, err)
+
+		report := &trustbloc.EndpointsReport{}
+		require.NoError(t, json.Unmarshal([]byte(out), report))
+		require.Equal(t, "testnet.example.com", report.Domain)
+		require.Len(t, report.Stakeholders, 2)
+		require.True(t, report.Stakeholders[0].Endpoints[0].Selected)
+	})
+
+	t.Run("failure - missing domain", func(t *testing.T) {
+		_, err := executeCmd(&mockInspector{report: testReport()})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "accepts 1 arg(s), received 0")
+	})
+
+	t.Run("failure - invalid format", func(t *testing.T) {
+		_, err := executeCmd(&mockInspector{report: testReport()}, "testnet.example.com", flag+formatFlagName, "xml")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid format 'xml'")
+	})
+
+	t.Run("failure - inspection error", func(t *testing.T) {
+		_, err := executeCmd(&mockInspector{err: fmt.Errorf("consortium not found")}, "testnet.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to inspect endpoints of testnet.example.com: consortium not found")
+	})
+
+	t.Run("failure - invalid tls flags", func(t *testing.T) {
+		_, err := executeCmd(&mockInspector{report: testReport()}, "testnet.example.com",
+			flag+tlsSystemCertPoolFlagName, "wrong")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid syntax")
+
+		_, err = executeCmd(&mockInspector{report: testReport()}, "testnet.example.com",
+			flag+tlsCACertsFlagName, "missing.pem")
+		require.Error(t, err)
+	})
+}
+
+func TestGetEndpointsCmd(t *testing.T) {
+	require.NoError(t, os.Setenv(formatEnvKey, "xml"))
+
+	defer func() { require.NoError(t, os.Unsetenv(formatEnvKey)) }()
+
+	cmd := GetEndpointsCmd()
+	cmd.SetArgs([]string{"testnet.example.com"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid format 'xml'")
+}
@@ -13,6 +13,7 @@ require (
 	github.com/stretchr/testify v1.6.1
 	github.com/trustbloc/edge-core v0.1.4-0.20200709143857-e104bb29f6c6
 	github.com/trustbloc/trustbloc-did-method v0.0.0-00010101000000-000000000000
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
 
 go 1.13
@@ -7,10 +7,16 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/trustbloc/trustbloc-did-method/cmd/did-method-cli/createconfigcmd"
+	"github.com/trustbloc/trustbloc-did-method/cmd/did-method-cli/endpointscmd"
+	"github.com/trustbloc/trustbloc-did-method/cmd/did-method-cli/schemacmd"
+	"github.com/trustbloc/trustbloc-did-method/cmd/did-method-cli/trustlogcmd"
+	"github.com/trustbloc/trustbloc-did-method/cmd/did-method-cli/versioncmd"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
 )
 
 func main() {
@@ -21,8 +27,20 @@ func main() {
 	}
 
 	rootCmd.AddCommand(createconfigcmd.GetCreateConfigCmd())
+	rootCmd.AddCommand(createconfigcmd.GetBootstrapCmd())
+	rootCmd.AddCommand(endpointscmd.GetEndpointsCmd())
+	rootCmd.AddCommand(schemacmd.GetSchemaCmd())
+	rootCmd.AddCommand(trustlogcmd.GetTrustLogCmd())
+	rootCmd.AddCommand(versioncmd.GetVersionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Failed to run did method cli: %s", err.Error())
+		if code := errcode.Of(err); code != "" {
+			log.Printf("Failed to run did method cli: [%s] %s", code, err.Error())
+		} else {
+			log.Printf("Failed to run did method cli: %s", err.Error())
+		}
+
+		// scripts key off the exit code of coded errors, eg. 23 if the endorsement threshold isn't met
+		os.Exit(errcode.ExitCode(err))
 	}
 }
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schemacmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	validateFlagName  = "validate"
+	validateFlagUsage = "Path of a JSON file to validate against the schema, instead of printing the schema"
+)
+
+// GetSchemaCmd returns the Cobra schema command.
+func GetSchemaCmd() *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema <name>",
+		Short: "Print or validate against the JSON Schema of a config file",
+		Long: "Print the JSON Schema of a config file, or validate a file against it. Schemas: " +
+			strings.Join(models.SchemaNames(), ", "),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := models.Schema(args[0])
+			if err != nil {
+				return err
+			}
+
+			file, err := cmd.Flags().GetString(validateFlagName)
+			if err != nil {
+				return err
+			}
+
+			if file == "" {
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+
+				return err
+			}
+
+			data, err := ioutil.ReadFile(file) // nolint: gosec
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+
+			if err := models.Validate(args[0], data); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s is a valid %s\n", file, args[0])
+
+			return err
+		},
+	}
+
+	schemaCmd.Flags().String(validateFlagName, "", validateFlagUsage)
+
+	return schemaCmd
+}
@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schemacmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func executeCmd(args ...string) (string, error) {
+	cmd := GetSchemaCmd()
+
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
+
+func TestSchemaCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+
+		return file
+	}
+
+	t.Run("print schema", func(t *testing.T) {
+		out, err := executeCmd("stakeholder")
+		require.NoError(t, err)
+		require.True(t, json.Valid([]byte(out)))
+		require.Contains(t, out, "did:trustbloc stakeholder config")
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		file := write("valid.json", `{"entries": [{"did": "did:key:z6Mk", "jwt": "eyJh.eyJp.c2ln"}]}`)
+
+		out, err := executeCmd("did-configuration", "--validate", file)
+		require.NoError(t, err)
+		require.Contains(t, out, "is a valid did-configuration")
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		file := write("invalid.json", `{"entries": [{"did": "did:key:z6Mk"}]}`)
+
+		_, err := executeCmd("did-configuration", "--validate", file)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jwt is required")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := executeCmd("consortium", "--validate", filepath.Join(dir, "missing.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read")
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		_, err := executeCmd("foo")
+		require.EqualError(t, err, "unknown schema 'foo'")
+	})
+}
@@ -0,0 +1,257 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustlogcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+)
+
+const (
+	urlFlagName  = "url"
+	urlEnvKey    = "DID_METHOD_CLI_URL"
+	urlFlagUsage = "URL of the did-method service whose trust log is audited, eg. https://localhost:8080." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+
+	adminTokenFlagName  = "admin-token"
+	adminTokenEnvKey    = "DID_METHOD_CLI_ADMIN_TOKEN" //nolint: gosec
+	adminTokenFlagUsage = "Bearer token of an admin of the did-method service." +
+		" Alternatively, this can be set with the following environment variable: " + adminTokenEnvKey
+
+	domainFlagName  = "domain"
+	domainFlagUsage = "Only print the entries of the given consortium domain"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "DID_METHOD_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "DID_METHOD_CLI_TLS_CACERTS"
+
+	formatFlagName  = "format"
+	formatEnvKey    = "DID_METHOD_CLI_FORMAT"
+	formatFlagUsage = "Output format. Possible values [table] [json]. Defaults to table." +
+		" Alternatively, this can be set with the following environment variable: " + formatEnvKey
+
+	tableFormat = "table"
+	jsonFormat  = "json"
+
+	trustLogPath   = "/admin/trust-log"
+	requestTimeout = 30 * time.Second
+)
+
+type trustLogResponse struct {
+	Entries []*trustlog.Entry `json:"entries"`
+}
+
+// GetTrustLogCmd returns the Cobra trust-log command.
+func GetTrustLogCmd() *cobra.Command {
+	trustLogCmd := &cobra.Command{
+		Use:   "trust-log",
+		Short: "Audit the consortium configs trusted by a did-method service",
+		Long: "Fetch the trust log of a did-method service, verify its hash chain locally, and print the consortium" +
+			" config versions the service trusted, when, and which stakeholders they were verified by",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			format, err := getFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := fetchTrustLog(cmd, url)
+			if err != nil {
+				return err
+			}
+
+			// the whole log is verified, before filtering the entries printed
+			verifyErr := trustlog.Verify(entries)
+
+			domain, err := cmd.Flags().GetString(domainFlagName)
+			if err != nil {
+				return err
+			}
+
+			if err := write(cmd.OutOrStdout(), format, filter(entries, domain)); err != nil {
+				return err
+			}
+
+			if verifyErr != nil {
+				return fmt.Errorf("trust log verification failed: %w", verifyErr)
+			}
+
+			return nil
+		},
+	}
+
+	createFlags(trustLogCmd)
+
+	return trustLogCmd
+}
+
+func getFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmdutils.GetUserSetVarFromString(cmd, formatFlagName, formatEnvKey, true)
+	if err != nil {
+		return "", err
+	}
+
+	if format == "" {
+		format = tableFormat
+	}
+
+	if format != tableFormat && format != jsonFormat {
+		return "", fmt.Errorf("invalid format '%s', expected %s or %s", format, tableFormat, jsonFormat)
+	}
+
+	return format, nil
+}
+
+func fetchTrustLog(cmd *cobra.Command, url string) ([]*trustlog.Entry, error) {
+	rootCAs, err := getRootCAs(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	adminToken, err := cmdutils.GetUserSetVarFromString(cmd, adminTokenFlagName, adminTokenEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(url, "/")+trustLogPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trust log request: %w", err)
+	}
+
+	if adminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+	}
+
+	client := &http.Client{Timeout: requestTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlspolicy.Apply(&tls.Config{RootCAs: rootCAs})}}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trust log: %w", err)
+	}
+
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust log: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("trust log request failed: error %d, `%s`", resp.StatusCode, string(body))
+	}
+
+	trustLog := &trustLogResponse{}
+
+	if err := json.Unmarshal(body, trustLog); err != nil {
+		return nil, fmt.Errorf("failed to parse trust log: %w", err)
+	}
+
+	return trustLog.Entries, nil
+}
+
+func filter(entries []*trustlog.Entry, domain string) []*trustlog.Entry {
+	if domain == "" {
+		return entries
+	}
+
+	filtered := []*trustlog.Entry{}
+
+	for _, entry := range entries {
+		if entry.Domain == domain {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+func write(out io.Writer, format string, entries []*trustlog.Entry) error {
+	if format == jsonFormat {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(entries)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SEQUENCE\tTIME\tDOMAIN\tVERSION\tMEMBERS\tVERIFIED BY") // nolint: errcheck
+
+	for _, entry := range entries {
+		verifiedBy := strings.Join(entry.Evidence.Stakeholders, ",")
+		if entry.Evidence.LocalOverride {
+			verifiedBy = "local override"
+		}
+
+		// nolint: errcheck
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", entry.Sequence, entry.Time.Format(time.RFC3339), entry.Domain,
+			entry.Version, len(entry.Members), verifiedBy)
+	}
+
+	return w.Flush()
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString, err := cmdutils.GetUserSetVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsSystemCertPool := false
+	if tlsSystemCertPoolString != "" {
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts, err := cmdutils.GetUserSetVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(trustLogCmd *cobra.Command) {
+	trustLogCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	trustLogCmd.Flags().StringP(adminTokenFlagName, "", "", adminTokenFlagUsage)
+	trustLogCmd.Flags().StringP(domainFlagName, "", "", domainFlagUsage)
+	trustLogCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	trustLogCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	trustLogCmd.Flags().StringP(formatFlagName, "", "", formatFlagUsage)
+}
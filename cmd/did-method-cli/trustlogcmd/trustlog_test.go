@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustlogcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+)
+
+const (
+	flag       = "--"
+	adminToken = "token"
+)
+
+func testEntries(t *testing.T) []*trustlog.Entry {
+	l, err := trustlog.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	for _, c := range []*models.Consortium{
+		{Domain: "testnet", Version: 1, Members: []*models.StakeholderListElement{{Domain: "one", DID: "did:a:1"}}},
+		{Domain: "other", Version: 1},
+		{Domain: "testnet", Version: 2, Members: []*models.StakeholderListElement{{Domain: "one", DID: "did:a:1"},
+			{Domain: "two", DID: "did:a:2"}}},
+	} {
+		_, err = l.Record(c.Domain, &models.ConsortiumFileData{Config: c}, []string{"one"}, c.Domain == "other")
+		require.NoError(t, err)
+	}
+
+	entries, err := l.Entries("")
+	require.NoError(t, err)
+
+	return entries
+}
+
+func trustLogServer(t *testing.T, entries []*trustlog.Entry) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != trustLogPath || r.Header.Get("Authorization") != "Bearer "+adminToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "unauthorized")
+
+			return
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(&trustLogResponse{Entries: entries}))
+	}))
+}
+
+func executeCmd(args ...string) (string, error) {
+	cmd := GetTrustLogCmd()
+
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
+
+func TestTrustLogCmd(t *testing.T) {
+	entries := testEntries(t)
+
+	serv := trustLogServer(t, entries)
+	defer serv.Close()
+
+	t.Run("success - table", func(t *testing.T) {
+		out, err := executeCmd(flag+urlFlagName, serv.URL+"/", flag+adminTokenFlagName, adminToken)
+		require.NoError(t, err)
+		require.Contains(t, out, "SEQUENCE")
+		require.Contains(t, out, "local override")
+		require.Regexp(t, `2\s+\S+\s+testnet\s+2\s+2\s+one`, out)
+	})
+
+	t.Run("success - json of a domain", func(t *testing.T) {
+		out, err := executeCmd(flag+urlFlagName, serv.URL, flag+adminTokenFlagName, adminToken,
+			flag+domainFlagName, "testnet", flag+formatFlagName, jsonFormat)
+		require.NoError(t, err)
+
+		var printed []*trustlog.Entry
+		require.NoError(t, json.Unmarshal([]byte(out), &printed))
+		require.Len(t, printed, 2)
+		require.Equal(t, entries[2].Hash, printed[1].Hash)
+	})
+
+	t.Run("failure - tampered log", func(t *testing.T) {
+		tampered := testEntries(t)
+		tampered[1].Members = map[string]string{"evil": "did:a:evil"}
+
+		tamperedServ := trustLogServer(t, tampered)
+		defer tamperedServ.Close()
+
+		out, err := executeCmd(flag+urlFlagName, tamperedServ.URL, flag+adminTokenFlagName, adminToken)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "trust log verification failed: trust log entry 1 doesn't match its hash")
+		require.Contains(t, out, "SEQUENCE")
+	})
+
+	t.Run("failure - unauthorized", func(t *testing.T) {
+		_, err := executeCmd(flag+urlFlagName, serv.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "trust log request failed: error 401")
+	})
+
+	t.Run("failure - invalid response", func(t *testing.T) {
+		badServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "{")
+		}))
+		defer badServ.Close()
+
+		_, err := executeCmd(flag+urlFlagName, badServ.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse trust log")
+	})
+
+	t.Run("failure - missing url", func(t *testing.T) {
+		_, err := executeCmd()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "url")
+	})
+
+	t.Run("failure - invalid format", func(t *testing.T) {
+		_, err := executeCmd(flag+urlFlagName, serv.URL, flag+formatFlagName, "xml")
+		require.EqualError(t, err, "invalid format 'xml', expected table or json")
+	})
+
+	t.Run("failure - unreachable", func(t *testing.T) {
+		_, err := executeCmd(flag+urlFlagName, "http://127.0.0.1:0")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch trust log")
+	})
+
+	t.Run("failure - invalid tls flags", func(t *testing.T) {
+		_, err := executeCmd(flag+urlFlagName, serv.URL, flag+tlsSystemCertPoolFlagName, "maybe")
+		require.Error(t, err)
+
+		_, err = executeCmd(flag+urlFlagName, serv.URL, flag+tlsCACertsFlagName, "/missing.pem")
+		require.Error(t, err)
+	})
+}
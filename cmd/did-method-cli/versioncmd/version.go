@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package versioncmd
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/version"
+)
+
+const (
+	urlFlagName  = "url"
+	urlEnvKey    = "DID_METHOD_CLI_URL"
+	urlFlagUsage = "URL of a did-method service whose version is printed as well, eg. https://localhost:8080." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "DID_METHOD_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "DID_METHOD_CLI_TLS_CACERTS"
+
+	infoPath       = "/info"
+	requestTimeout = 30 * time.Second
+)
+
+// GetVersionCmd returns the Cobra version command.
+func GetVersionCmd() *cobra.Command {
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the cli, and optionally of a did-method service",
+		Long: "Print the version, git commit, build date and supported sidetree protocol versions of the cli, and of" +
+			" the did-method service at the given URL if set, so operators can verify what's deployed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			if err := write(cmd.OutOrStdout(), "cli", version.Get()); err != nil {
+				return err
+			}
+
+			if url == "" {
+				return nil
+			}
+
+			info, err := fetchInfo(cmd, url)
+			if err != nil {
+				return err
+			}
+
+			return write(cmd.OutOrStdout(), "service "+url, info)
+		},
+	}
+
+	createFlags(versionCmd)
+
+	return versionCmd
+}
+
+func fetchInfo(cmd *cobra.Command, url string) (*version.Info, error) {
+	tlsConfig, err := getTLSConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: requestTimeout, Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	resp, err := client.Get(strings.TrimSuffix(url, "/") + infoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch service info: %w", err)
+	}
+
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service info: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("service info request failed: error %d, `%s`", resp.StatusCode, string(body))
+	}
+
+	info := &version.Info{}
+
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, fmt.Errorf("failed to parse service info: %w", err)
+	}
+
+	return info, nil
+}
+
+func write(out io.Writer, title string, info *version.Info) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "%s:\n", title) // nolint: errcheck
+
+	for _, field := range [][2]string{
+		{"Version", info.Version},
+		{"Git commit", info.GitCommit},
+		{"Build date", info.BuildDate},
+		{"Go version", info.GoVersion},
+		{"Sidetree protocol versions", strings.Join(info.SidetreeProtocolVersions, ", ")},
+	} {
+		fmt.Fprintf(w, "  %s:\t%s\n", field[0], field[1]) // nolint: errcheck
+	}
+
+	return w.Flush()
+}
+
+func getTLSConfig(cmd *cobra.Command) (*tls.Config, error) {
+	tlsSystemCertPoolString, err := cmdutils.GetUserSetVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsSystemCertPool := false
+	if tlsSystemCertPoolString != "" {
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts, err := cmdutils.GetUserSetVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCAs, err := tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlspolicy.Apply(&tls.Config{RootCAs: rootCAs}), nil
+}
+
+func createFlags(versionCmd *cobra.Command) {
+	versionCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	versionCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	versionCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+}
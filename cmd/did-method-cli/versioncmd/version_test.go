@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package versioncmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/version"
+)
+
+const flag = "--"
+
+func infoServer(t *testing.T, info *version.Info) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info == nil || r.URL.Path != infoPath {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "not found")
+
+			return
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(info))
+	}))
+}
+
+func TestVersionCmd(t *testing.T) {
+	t.Run("success - cli version", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		cmd := GetVersionCmd()
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), "cli:")
+		require.Contains(t, out.String(), "Version:")
+		require.Contains(t, out.String(), version.SidetreeProtocolVersion)
+		require.NotContains(t, out.String(), "service")
+	})
+
+	t.Run("success - service version", func(t *testing.T) {
+		serv := infoServer(t, &version.Info{Version: "v0.1.4", GitCommit: "1873cf7", BuildDate: "2020-08-27",
+			GoVersion: "go1.13.1", SidetreeProtocolVersions: []string{"0.1.0"}})
+		defer serv.Close()
+
+		out := &bytes.Buffer{}
+
+		cmd := GetVersionCmd()
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{flag + urlFlagName, serv.URL})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), "service "+serv.URL+":")
+		require.Contains(t, out.String(), "v0.1.4")
+		require.Contains(t, out.String(), "1873cf7")
+	})
+
+	t.Run("failure - service without info", func(t *testing.T) {
+		serv := infoServer(t, nil)
+		defer serv.Close()
+
+		cmd := GetVersionCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{flag + urlFlagName, serv.URL})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service info request failed: error 404")
+	})
+
+	t.Run("failure - service unreachable", func(t *testing.T) {
+		cmd := GetVersionCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{flag + urlFlagName, "http://localhost:0"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch service info")
+	})
+
+	t.Run("failure - invalid tls system cert pool", func(t *testing.T) {
+		cmd := GetVersionCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{flag + urlFlagName, "http://localhost:0", flag + tlsSystemCertPoolFlagName, "wrong"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid syntax")
+	})
+}
@@ -9,11 +9,16 @@ replace github.com/trustbloc/trustbloc-did-method => ../..
 replace github.com/piprate/json-gold => github.com/trustbloc/json-gold v0.3.1-0.20200414173446-30d742ee949e
 
 require (
+	github.com/golang/protobuf v1.3.3
 	github.com/gorilla/mux v1.7.4
+	github.com/hyperledger/aries-framework-go v0.1.4-0.20200827142339-1873cf75190d
+	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v1.0.0
+	github.com/square/go-jose/v3 v3.0.0-20200630053402-0a67ce9b0693
 	github.com/stretchr/testify v1.6.1
 	github.com/trustbloc/edge-core v0.1.4-0.20200709143857-e104bb29f6c6
 	github.com/trustbloc/trustbloc-did-method v0.0.0
+	google.golang.org/grpc v1.27.1
 )
 
 go 1.13
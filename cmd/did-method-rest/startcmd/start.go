@@ -6,21 +6,41 @@ SPDX-License-Identifier: Apache-2.0
 package startcmd
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/trustbloc/trustbloc-did-method/pkg/restapi/healthcheck"
 
 	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/square/go-jose/v3"
 	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
 	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
+	grpcdidmethod "github.com/trustbloc/trustbloc-did-method/pkg/grpcapi/didmethod"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
 	"github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod"
 	"github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod/operation"
+	healthcheckops "github.com/trustbloc/trustbloc-did-method/pkg/restapi/healthcheck/operation"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
 )
 
 const (
@@ -29,6 +49,12 @@ const (
 	hostURLFlagUsage     = "URL to run the bloc did method instance on. Format: HostName:Port."
 	hostURLEnvKey        = "DID_METHOD_HOST_URL"
 
+	grpcHostURLFlagName  = "grpc-host-url"
+	grpcHostURLFlagUsage = "URL to serve the gRPC API on. Format: HostName:Port. The gRPC API is disabled if not set." +
+		" It's served over TLS with the certificate of the REST API, if set." +
+		" Alternatively, this can be set with the following environment variable: " + grpcHostURLEnvKey
+	grpcHostURLEnvKey = "DID_METHOD_GRPC_HOST_URL"
+
 	tlsSystemCertPoolFlagName      = "tls-systemcertpool"
 	tlsSystemCertPoolFlagShorthand = "s"
 	tlsSystemCertPoolFlagUsage     = "Use system certificate pool." +
@@ -42,6 +68,36 @@ const (
 		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
 	tlsCACertsEnvKey = "DID_METHOD_TLS_CACERTS"
 
+	tlsMinVersionFlagName  = "tls-min-version"
+	tlsMinVersionEnvKey    = "DID_METHOD_TLS_MIN_VERSION"
+	tlsMinVersionFlagUsage = "Minimum TLS version of the connections of the service, inbound and outbound." +
+		" Possible values [1.2] [1.3]. Defaults to 1.2 if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsMinVersionEnvKey
+
+	tlsCipherSuitesFlagName  = "tls-cipher-suites"
+	tlsCipherSuitesEnvKey    = "DID_METHOD_TLS_CIPHER_SUITES"
+	tlsCipherSuitesFlagUsage = "Comma-Separated list of the TLS 1.2 cipher suites allowed, eg." +
+		" TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256. Only ECDHE suites with AES-GCM or ChaCha20-Poly1305 are" +
+		" supported, all of them are allowed if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCipherSuitesEnvKey
+
+	tlsServeCertFlagName  = "tls-serve-cert"
+	tlsServeCertEnvKey    = "DID_METHOD_TLS_SERVE_CERT"
+	tlsServeCertFlagUsage = "Path of the PEM encoded certificate the REST and gRPC APIs are served over TLS with." +
+		" They're served without TLS if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsServeCertEnvKey
+
+	tlsServeKeyFlagName  = "tls-serve-key"
+	tlsServeKeyEnvKey    = "DID_METHOD_TLS_SERVE_KEY"
+	tlsServeKeyFlagUsage = "Path of the PEM encoded private key of the certificate the REST API is served with." +
+		" Alternatively, this can be set with the following environment variable: " + tlsServeKeyEnvKey
+
+	logLevelFlagName  = "log-level"
+	logLevelEnvKey    = "DID_METHOD_LOG_LEVEL"
+	logLevelFlagUsage = "Level of the log entries written by the service." +
+		" Possible values [debug] [info] [warning] [error]. Defaults to info if not set." +
+		" Alternatively, this can be set with the following environment variable: " + logLevelEnvKey
+
 	domainFlagName      = "domain"
 	domainFlagShorthand = "b"
 	domainFlagUsage     = "domain"
@@ -50,7 +106,8 @@ const (
 	modeFlagName      = "mode"
 	modeFlagShorthand = "m"
 	modeFlagUsage     = "Mode in which the did-method service will run. Possible values: " +
-		"['registrar', 'resolver', 'combined'] (default: combined)."
+		"['registrar', 'resolver', 'combined', 'proxy'] (default: combined). In proxy mode, the service is a caching" +
+		" resolver proxy signing the resolution results it serves to lightweight clients."
 	modeEnvKey = "DID_METHOD_MODE"
 
 	sidetreeReadTokenFlagName  = "sidetree-read-token"
@@ -62,6 +119,57 @@ const (
 	sidetreeWriteTokenEnvKey    = "SIDETREE_WRITE_TOKEN" //nolint: gosec
 	sidetreeWriteTokenFlagUsage = "The sidetree write token " +
 		" Alternatively, this can be set with the following environment variable: " + sidetreeWriteTokenEnvKey
+
+	adminTokenFlagName  = "admin-token"
+	adminTokenEnvKey    = "DID_METHOD_ADMIN_TOKENS" //nolint: gosec
+	adminTokenFlagUsage = "Bearer token of an admin of the trust anchor admin API. Format: name=token, where name" +
+		" identifies the admin in the audit log. This flag can be repeated, allowing for multiple admins." +
+		" The admin API is disabled if not set." +
+		" Alternatively, this can be set with the following environment variable (in CSV format): " + adminTokenEnvKey
+
+	proxySigningKeyFlagName  = "proxy-signing-key"
+	proxySigningKeyEnvKey    = "DID_METHOD_PROXY_SIGNING_KEY"
+	proxySigningKeyFlagUsage = "Path to the PEM encoded PKCS#8 Ed25519 or P-256 private key signing the resolution" +
+		" results served in proxy mode, required in proxy mode." +
+		" Alternatively, this can be set with the following environment variable: " + proxySigningKeyEnvKey
+
+	proxyCacheTTLFlagName  = "proxy-cache-ttl"
+	proxyCacheTTLEnvKey    = "DID_METHOD_PROXY_CACHE_TTL"
+	proxyCacheTTLFlagUsage = "TTL of the DID docs cached in proxy mode, eg. 30s. Defaults to 1m." +
+		" Alternatively, this can be set with the following environment variable: " + proxyCacheTTLEnvKey
+
+	proxyWatchIntervalFlagName  = "proxy-watch-interval"
+	proxyWatchIntervalEnvKey    = "DID_METHOD_PROXY_WATCH_INTERVAL"
+	proxyWatchIntervalFlagUsage = "Interval between the polls of the DIDs whose update events are streamed in proxy" +
+		" mode, eg. 30s. Defaults to 1m." +
+		" Alternatively, this can be set with the following environment variable: " + proxyWatchIntervalEnvKey
+
+	auditLogFileFlagName  = "audit-log-file"
+	auditLogFileEnvKey    = "DID_METHOD_AUDIT_LOG_FILE"
+	auditLogFileFlagUsage = "Path of the file the DID operations processed by the registrar are audited to," +
+		" one JSON record per line." +
+		" Alternatively, this can be set with the following environment variable: " + auditLogFileEnvKey
+
+	auditStorePathFlagName  = "audit-store-path"
+	auditStorePathEnvKey    = "DID_METHOD_AUDIT_STORE_PATH"
+	auditStorePathFlagUsage = "Path of the LevelDB database the DID operations processed by the registrar are" +
+		" audited to. Alternatively, this can be set with the following environment variable: " + auditStorePathEnvKey
+
+	auditWebhookURLFlagName  = "audit-webhook-url"
+	auditWebhookURLEnvKey    = "DID_METHOD_AUDIT_WEBHOOK_URL"
+	auditWebhookURLFlagUsage = "URL of the webhook the DID operations processed by the registrar are posted to." +
+		" Alternatively, this can be set with the following environment variable: " + auditWebhookURLEnvKey
+
+	trustLogStorePathFlagName  = "trust-log-store-path"
+	trustLogStorePathEnvKey    = "DID_METHOD_TRUST_LOG_STORE_PATH"
+	trustLogStorePathFlagUsage = "Path of the LevelDB database the consortium config versions trusted by the" +
+		" service are logged to, as a hash-linked log served by the admin API." +
+		" Alternatively, this can be set with the following environment variable: " + trustLogStorePathEnvKey
+
+	auditWebhookTokenFlagName  = "audit-webhook-token"
+	auditWebhookTokenEnvKey    = "DID_METHOD_AUDIT_WEBHOOK_TOKEN" //nolint: gosec
+	auditWebhookTokenFlagUsage = "Bearer token authorizing the posts to the audit webhook." +
+		" Alternatively, this can be set with the following environment variable: " + auditWebhookTokenEnvKey
 )
 
 // mode in which to run the did-method service
@@ -71,10 +179,12 @@ const (
 	registrar mode = "registrar"
 	resolver  mode = "resolver"
 	combined  mode = "combined"
+	proxy     mode = "proxy"
 )
 
 type server interface {
 	ListenAndServe(host string, router http.Handler) error
+	ListenAndServeTLS(host, certFile, keyFile string, tlsConfig *tls.Config, router http.Handler) error
 }
 
 // HTTPServer represents an actual HTTP server implementation.
@@ -85,15 +195,50 @@ func (s *HTTPServer) ListenAndServe(host string, router http.Handler) error {
 	return http.ListenAndServe(host, router)
 }
 
+// ListenAndServeTLS starts the server over HTTPS with the given certificate and TLS config, using the standard Go
+// HTTP server implementation.
+func (s *HTTPServer) ListenAndServeTLS(host, certFile, keyFile string, tlsConfig *tls.Config,
+	router http.Handler) error {
+	srv := &http.Server{Addr: host, Handler: router, TLSConfig: tlsConfig}
+
+	return srv.ListenAndServeTLS(certFile, keyFile)
+}
+
 type parameters struct {
 	srv                server
 	hostURL            string
+	grpcHostURL        string
 	tlsSystemCertPool  bool
 	tlsCACerts         []string
+	tls                *tlsParameters
+	logger             log.Logger
 	blocDomain         string
 	mode               string
 	sidetreeReadToken  string
 	sidetreeWriteToken string
+	adminTokens        map[string]string
+	proxy              *proxyParameters
+	audit              *auditParameters
+}
+
+type tlsParameters struct {
+	policy   *tlspolicy.Policy
+	certFile string
+	keyFile  string
+}
+
+type proxyParameters struct {
+	signingKey    *jose.SigningKey
+	cacheTTL      time.Duration
+	watchInterval time.Duration
+}
+
+type auditParameters struct {
+	logFile           string
+	storePath         string
+	webhookURL        string
+	webhookToken      string
+	trustLogStorePath string
 }
 
 // GetStartCmd returns the Cobra start command.
@@ -121,11 +266,26 @@ func createStartCmd(srv server) *cobra.Command { //nolint: funlen
 				return err
 			}
 
+			grpcHostURL, err := cmdutils.GetUserSetVarFromString(cmd, grpcHostURLFlagName, grpcHostURLEnvKey, true)
+			if err != nil {
+				return err
+			}
+
 			tlsSystemCertPool, tlsCACerts, err := getTLS(cmd)
 			if err != nil {
 				return err
 			}
 
+			tlsParams, err := getTLSParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
 			blocDomain, err := cmdutils.GetUserSetVarFromString(cmd, domainFlagName, domainEnvKey,
 				!isRegistrar(mode))
 			if err != nil {
@@ -144,15 +304,36 @@ func createStartCmd(srv server) *cobra.Command { //nolint: funlen
 				return err
 			}
 
+			adminTokens, err := getAdminTokens(cmd)
+			if err != nil {
+				return err
+			}
+
+			proxyParams, err := getProxyParameters(cmd, mode)
+			if err != nil {
+				return err
+			}
+
+			auditParams, err := getAuditParameters(cmd)
+			if err != nil {
+				return err
+			}
+
 			parameters := &parameters{
 				srv:                srv,
 				hostURL:            strings.TrimSpace(hostURL),
+				grpcHostURL:        strings.TrimSpace(grpcHostURL),
 				tlsSystemCertPool:  tlsSystemCertPool,
 				tlsCACerts:         tlsCACerts,
+				tls:                tlsParams,
+				logger:             logger,
 				blocDomain:         blocDomain,
 				mode:               mode,
 				sidetreeReadToken:  sidetreeReadToken,
 				sidetreeWriteToken: sidetreeWriteToken,
+				adminTokens:        adminTokens,
+				proxy:              proxyParams,
+				audit:              auditParams,
 			}
 
 			return startDidMethod(parameters)
@@ -184,6 +365,220 @@ func getTLS(cmd *cobra.Command) (bool, []string, error) {
 	return tlsSystemCertPool, tlsCACerts, nil
 }
 
+// getTLSParameters returns the TLS policy of the service and the certificate the REST API is served with, checking
+// that the policy is secure and that the certificate can be loaded at startup
+func getTLSParameters(cmd *cobra.Command) (*tlsParameters, error) {
+	minVersion, err := cmdutils.GetUserSetVarFromString(cmd, tlsMinVersionFlagName, tlsMinVersionEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherSuites, err := cmdutils.GetUserSetVarFromArrayString(cmd, tlsCipherSuitesFlagName,
+		tlsCipherSuitesEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := tlspolicy.New(minVersion, cipherSuites)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS policy: %w", err)
+	}
+
+	params := &tlsParameters{policy: policy}
+
+	params.certFile, err = cmdutils.GetUserSetVarFromString(cmd, tlsServeCertFlagName, tlsServeCertEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	params.keyFile, err = cmdutils.GetUserSetVarFromString(cmd, tlsServeKeyFlagName, tlsServeKeyEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if (params.certFile == "") != (params.keyFile == "") {
+		return nil, fmt.Errorf("both %s and %s must be set to serve over HTTPS", tlsServeCertFlagName,
+			tlsServeKeyFlagName)
+	}
+
+	if params.certFile != "" {
+		if _, err = tls.LoadX509KeyPair(params.certFile, params.keyFile); err != nil {
+			return nil, fmt.Errorf("invalid TLS serve certificate: %w", err)
+		}
+	}
+
+	return params, nil
+}
+
+// getLogger returns the logger of the service, writing the entries of the configured level and above
+func getLogger(cmd *cobra.Command) (log.Logger, error) {
+	levelString, err := cmdutils.GetUserSetVarFromString(cmd, logLevelFlagName, logLevelEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logrus.New()
+
+	if levelString == "" {
+		return log.New(logger), nil
+	}
+
+	level, err := logrus.ParseLevel(levelString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	logger.SetLevel(level)
+
+	return log.New(logger), nil
+}
+
+func getAdminTokens(cmd *cobra.Command) (map[string]string, error) {
+	values, err := cmdutils.GetUserSetVarFromArrayString(cmd, adminTokenFlagName, adminTokenEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]string, len(values))
+
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2) // nolint: gomnd
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid admin token, expected format name=token")
+		}
+
+		tokens[parts[0]] = parts[1]
+	}
+
+	return tokens, nil
+}
+
+func getProxyParameters(cmd *cobra.Command, mode string) (*proxyParameters, error) {
+	if mode != string(proxy) {
+		return &proxyParameters{}, nil
+	}
+
+	keyPath, err := cmdutils.GetUserSetVarFromString(cmd, proxySigningKeyFlagName, proxySigningKeyEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := readSigningKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy signing key: %w", err)
+	}
+
+	cacheTTL, err := getDuration(cmd, proxyCacheTTLFlagName, proxyCacheTTLEnvKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy cache ttl: %w", err)
+	}
+
+	watchInterval, err := getDuration(cmd, proxyWatchIntervalFlagName, proxyWatchIntervalEnvKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy watch interval: %w", err)
+	}
+
+	return &proxyParameters{signingKey: signingKey, cacheTTL: cacheTTL, watchInterval: watchInterval}, nil
+}
+
+func getAuditParameters(cmd *cobra.Command) (*auditParameters, error) {
+	params := &auditParameters{}
+
+	for _, v := range []struct {
+		value  *string
+		flag   string
+		envKey string
+	}{
+		{&params.logFile, auditLogFileFlagName, auditLogFileEnvKey},
+		{&params.storePath, auditStorePathFlagName, auditStorePathEnvKey},
+		{&params.webhookURL, auditWebhookURLFlagName, auditWebhookURLEnvKey},
+		{&params.webhookToken, auditWebhookTokenFlagName, auditWebhookTokenEnvKey},
+		{&params.trustLogStorePath, trustLogStorePathFlagName, trustLogStorePathEnvKey},
+	} {
+		value, err := cmdutils.GetUserSetVarFromString(cmd, v.flag, v.envKey, true)
+		if err != nil {
+			return nil, err
+		}
+
+		*v.value = value
+	}
+
+	return params, nil
+}
+
+// newAuditSink creates the sink of the configured audit destinations, or nil if none is configured
+func newAuditSink(params *auditParameters, tlsConfig *tls.Config) (audit.Sink, error) {
+	var sinks audit.MultiSink
+
+	if params.logFile != "" {
+		fileSink, err := audit.NewFileSink(params.logFile)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, fileSink)
+	}
+
+	if params.storePath != "" {
+		storeSink, err := audit.NewStoreSink(leveldb.NewProvider(params.storePath))
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, storeSink)
+	}
+
+	if params.webhookURL != "" {
+		sinks = append(sinks, audit.NewWebhookSink(params.webhookURL, audit.WithTLSConfig(tlsConfig),
+			audit.WithAuthToken(params.webhookToken)))
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	return sinks, nil
+}
+
+// getDuration returns the optional duration set by a flag or environment variable, or zero if not set
+func getDuration(cmd *cobra.Command, flagName, envKey string) (time.Duration, error) {
+	value, err := cmdutils.GetUserSetVarFromString(cmd, flagName, envKey, true)
+	if err != nil || value == "" {
+		return 0, err
+	}
+
+	return time.ParseDuration(value)
+}
+
+// readSigningKey reads a PEM encoded PKCS#8 Ed25519 or P-256 private key
+func readSigningKey(path string) (*jose.SigningKey, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return &jose.SigningKey{Algorithm: jose.EdDSA, Key: k}, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve == elliptic.P256() {
+			return &jose.SigningKey{Algorithm: jose.ES256, Key: k}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported key type, expected an Ed25519 or P-256 key")
+}
+
 func getMode(cmd *cobra.Command) (string, error) {
 	mode, err := cmdutils.GetUserSetVarFromString(cmd, modeFlagName, modeEnvKey, true)
 	if err != nil {
@@ -203,13 +598,28 @@ func getMode(cmd *cobra.Command) (string, error) {
 
 func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(hostURLFlagName, hostURLFlagShorthand, "", hostURLFlagUsage)
+	startCmd.Flags().StringP(grpcHostURLFlagName, "", "", grpcHostURLFlagUsage)
 	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, tlsSystemCertPoolFlagShorthand, "",
 		tlsSystemCertPoolFlagUsage)
 	startCmd.Flags().StringArrayP(tlsCACertsFlagName, tlsCACertsFlagShorthand, []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(tlsMinVersionFlagName, "", "", tlsMinVersionFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCipherSuitesFlagName, "", []string{}, tlsCipherSuitesFlagUsage)
+	startCmd.Flags().StringP(tlsServeCertFlagName, "", "", tlsServeCertFlagUsage)
+	startCmd.Flags().StringP(tlsServeKeyFlagName, "", "", tlsServeKeyFlagUsage)
+	startCmd.Flags().StringP(logLevelFlagName, "", "", logLevelFlagUsage)
 	startCmd.Flags().StringP(domainFlagName, domainFlagShorthand, "", domainFlagUsage)
 	startCmd.Flags().StringP(modeFlagName, modeFlagShorthand, "", modeFlagUsage)
 	startCmd.Flags().StringP(sidetreeReadTokenFlagName, "", "", sidetreeReadTokenFlagUsage)
 	startCmd.Flags().StringP(sidetreeWriteTokenFlagName, "", "", sidetreeWriteTokenFlagUsage)
+	startCmd.Flags().StringArrayP(adminTokenFlagName, "", []string{}, adminTokenFlagUsage)
+	startCmd.Flags().StringP(proxySigningKeyFlagName, "", "", proxySigningKeyFlagUsage)
+	startCmd.Flags().StringP(proxyCacheTTLFlagName, "", "", proxyCacheTTLFlagUsage)
+	startCmd.Flags().StringP(proxyWatchIntervalFlagName, "", "", proxyWatchIntervalFlagUsage)
+	startCmd.Flags().StringP(auditLogFileFlagName, "", "", auditLogFileFlagUsage)
+	startCmd.Flags().StringP(auditStorePathFlagName, "", "", auditStorePathFlagUsage)
+	startCmd.Flags().StringP(auditWebhookURLFlagName, "", "", auditWebhookURLFlagUsage)
+	startCmd.Flags().StringP(auditWebhookTokenFlagName, "", "", auditWebhookTokenFlagUsage)
+	startCmd.Flags().StringP(trustLogStorePathFlagName, "", "", trustLogStorePathFlagUsage)
 }
 
 func startDidMethod(parameters *parameters) error {
@@ -218,17 +628,58 @@ func startDidMethod(parameters *parameters) error {
 		return err
 	}
 
-	didMethodService, err := didmethod.New(&operation.Config{TLSConfig: &tls.Config{RootCAs: rootCAs},
+	// all outbound connections comply with the TLS policy
+	tlsConfig := parameters.tls.policy.Apply(&tls.Config{RootCAs: rootCAs})
+
+	auditSink, err := newAuditSink(parameters.audit, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	var trustLog *trustlog.Log
+
+	if parameters.audit.trustLogStorePath != "" {
+		trustLog, err = trustlog.New(leveldb.NewProvider(parameters.audit.trustLogStorePath))
+		if err != nil {
+			return err
+		}
+	}
+
+	didMethodService, err := didmethod.New(&operation.Config{TLSConfig: tlsConfig,
 		BlocDomain: parameters.blocDomain, Mode: parameters.mode, SidetreeReadToken: parameters.sidetreeReadToken,
-		SidetreeWriteToken: parameters.sidetreeWriteToken})
+		SidetreeWriteToken: parameters.sidetreeWriteToken, AdminTokens: parameters.adminTokens,
+		ProxySigningKey: parameters.proxy.signingKey, ProxyCacheTTL: parameters.proxy.cacheTTL,
+		WatchInterval: parameters.proxy.watchInterval, AuditSink: auditSink, TrustLog: trustLog,
+		Logger: parameters.logger})
 	if err != nil {
 		return err
 	}
 
+	var serveTLSConfig *tls.Config
+
+	if parameters.tls.certFile != "" {
+		// clients may authenticate with a certificate, whose subject identifies them in the audit records
+		serveTLSConfig = parameters.tls.policy.Apply(&tls.Config{PreferServerCipherSuites: true,
+			ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: rootCAs})
+	}
+
+	if parameters.grpcHostURL != "" {
+		var grpcSrv *grpc.Server
+
+		grpcSrv, err = startGRPCServer(parameters, grpcdidmethod.New(didMethodService.Service(), parameters.mode),
+			serveTLSConfig)
+		if err != nil {
+			return err
+		}
+
+		// the gRPC API stops with the REST API
+		defer grpcSrv.GracefulStop()
+	}
+
 	router := mux.NewRouter()
 
 	// add health check endpoint
-	healthCheckService := healthcheck.New()
+	healthCheckService := healthcheck.New(healthcheckops.WithLogger(parameters.logger))
 
 	healthCheckHandlers := healthCheckService.GetOperations()
 	for _, handler := range healthCheckHandlers {
@@ -241,11 +692,51 @@ func startDidMethod(parameters *parameters) error {
 		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
 	}
 
+	if parameters.tls.certFile != "" {
+		return parameters.srv.ListenAndServeTLS(parameters.hostURL, parameters.tls.certFile, parameters.tls.keyFile,
+			serveTLSConfig, router)
+	}
+
 	return parameters.srv.ListenAndServe(parameters.hostURL, router)
 }
 
+// startGRPCServer serves the gRPC API in the background, sharing the did method operations of the REST API. As the
+// REST API, it's served over TLS with the serve certificate and TLS config, if a serve certificate is set.
+func startGRPCServer(parameters *parameters, service *grpcdidmethod.Service,
+	tlsConfig *tls.Config) (*grpc.Server, error) {
+	var opts []grpc.ServerOption
+
+	if tlsConfig != nil {
+		cert, err := tls.LoadX509KeyPair(parameters.tls.certFile, parameters.tls.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS serve certificate: %w", err)
+		}
+
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.Certificates = []tls.Certificate{cert}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	lis, err := net.Listen("tcp", parameters.grpcHostURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on grpc host url: %w", err)
+	}
+
+	srv := grpc.NewServer(opts...)
+	grpcdidmethod.RegisterDIDMethodServer(srv, service)
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			parameters.logger.Errorf("gRPC server stopped: %s", err)
+		}
+	}()
+
+	return srv, nil
+}
+
 func supportedMode(mode string) bool {
-	if len(mode) > 0 && mode != string(registrar) && mode != string(resolver) {
+	if len(mode) > 0 && mode != string(registrar) && mode != string(resolver) && mode != string(proxy) {
 		return false
 	}
 
@@ -6,19 +6,62 @@ SPDX-License-Identifier: Apache-2.0
 package startcmd
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	grpcdidmethod "github.com/trustbloc/trustbloc-did-method/pkg/grpcapi/didmethod"
 )
 
-const flag = "--"
+const (
+	flag           = "--"
+	startcmdGoFile = "start.go"
+)
 
-type mockServer struct{}
+type mockServer struct {
+	tlsConfig *tls.Config
+	// serve is called while the server is serving, if set
+	serve func()
+}
 
 func (s *mockServer) ListenAndServe(host string, handler http.Handler) error {
+	if s.serve != nil {
+		s.serve()
+	}
+
+	return nil
+}
+
+func (s *mockServer) ListenAndServeTLS(host, certFile, keyFile string, tlsConfig *tls.Config,
+	router http.Handler) error {
+	s.tlsConfig = tlsConfig
+
+	if s.serve != nil {
+		s.serve()
+	}
+
 	return nil
 }
 
@@ -29,6 +72,13 @@ func TestListenAndServe(t *testing.T) {
 	require.Contains(t, err.Error(), "listen tcp: address 7: missing port in address")
 }
 
+func TestListenAndServeTLS(t *testing.T) {
+	h := HTTPServer{}
+	err := h.ListenAndServeTLS("localhost:0", "missing.crt", "missing.key", &tls.Config{}, nil) // nolint: gosec
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no such file or directory")
+}
+
 func TestStartCmdContents(t *testing.T) {
 	startCmd := GetStartCmd(&mockServer{})
 
@@ -72,6 +122,338 @@ func TestStartCmdValidArgsEnvVar(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestStartCmdWithGRPC(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+grpcHostURLFlagName, "localhost:0"))
+
+		require.NoError(t, startCmd.Execute())
+	})
+
+	t.Run("success - stopped with the REST API", func(t *testing.T) {
+		hostURL := freeHostURL(t)
+		served := false
+
+		startCmd := GetStartCmd(&mockServer{serve: func() {
+			conn, err := net.Dial("tcp", hostURL)
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+
+			served = true
+		}})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+grpcHostURLFlagName, hostURL))
+
+		require.NoError(t, startCmd.Execute())
+		require.True(t, served)
+
+		_, err := net.Dial("tcp", hostURL)
+		require.Error(t, err)
+	})
+
+	t.Run("failure - invalid grpc host url", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+grpcHostURLFlagName, "7"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to listen on grpc host url")
+	})
+}
+
+func TestAdminTokenFlag(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+adminTokenFlagName, "alice=token1",
+			flag+adminTokenFlagName, "bob=token2"))
+
+		require.NoError(t, startCmd.Execute())
+	})
+
+	t.Run("failure - invalid admin token", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+adminTokenFlagName, "token"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid admin token, expected format name=token")
+	})
+}
+
+func TestAuditFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	t.Run("success", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+auditLogFileFlagName, filepath.Join(dir, "audit.log"),
+			flag+auditStorePathFlagName, filepath.Join(dir, "db"),
+			flag+auditWebhookURLFlagName, "https://audit.example.com", flag+auditWebhookTokenFlagName, "token",
+			flag+trustLogStorePathFlagName, filepath.Join(dir, "trustlog")))
+
+		require.NoError(t, startCmd.Execute())
+		require.FileExists(t, filepath.Join(dir, "audit.log"))
+	})
+
+	t.Run("failure - invalid trust log store path", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+trustLogStorePathFlagName,
+			filepath.Join(dir, "audit.log", "db")))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open trust log store")
+	})
+
+	t.Run("failure - invalid audit log file", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+auditLogFileFlagName, filepath.Join(dir, "missing", "audit.log")))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open audit log file")
+	})
+}
+
+func TestTLSFlags(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keyFile := writeKeyFile(t, key)
+	defer func() { require.NoError(t, os.Remove(keyFile)) }()
+
+	certFile := writeCertFile(t, key)
+	defer func() { require.NoError(t, os.Remove(certFile)) }()
+
+	t.Run("success - serve over https", func(t *testing.T) {
+		srv := &mockServer{}
+		startCmd := GetStartCmd(srv)
+
+		startCmd.SetArgs(append(getValidArgs(), flag+tlsMinVersionFlagName, "1.3",
+			flag+tlsCipherSuitesFlagName, "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+			flag+tlsServeCertFlagName, certFile, flag+tlsServeKeyFlagName, keyFile))
+
+		require.NoError(t, startCmd.Execute())
+		require.NotNil(t, srv.tlsConfig)
+		require.Equal(t, uint16(tls.VersionTLS13), srv.tlsConfig.MinVersion)
+		require.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, srv.tlsConfig.CipherSuites)
+	})
+
+	t.Run("success - serve gRPC over TLS", func(t *testing.T) {
+		hostURL := freeHostURL(t)
+		served := false
+
+		srv := &mockServer{serve: func() {
+			certPEM, err := ioutil.ReadFile(filepath.Clean(certFile))
+			require.NoError(t, err)
+
+			rootCAs := x509.NewCertPool()
+			require.True(t, rootCAs.AppendCertsFromPEM(certPEM))
+
+			// the client authenticates with the serve certificate, trusted with the tls-cacerts flag
+			clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := grpc.DialContext(ctx, hostURL, grpc.WithBlock(),
+				grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: rootCAs, ServerName: "localhost",
+					Certificates: []tls.Certificate{clientCert}, MinVersion: tls.VersionTLS13})))
+			require.NoError(t, err)
+
+			defer func() { require.NoError(t, conn.Close()) }()
+
+			_, err = grpcdidmethod.NewDIDMethodClient(conn).Create(ctx, &wrappers.BytesValue{Value: []byte("{")})
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			served = true
+		}}
+
+		startCmd := GetStartCmd(srv)
+
+		startCmd.SetArgs(append(getValidArgs(), flag+grpcHostURLFlagName, hostURL,
+			flag+tlsMinVersionFlagName, "1.3", flag+tlsCACertsFlagName, certFile,
+			flag+tlsServeCertFlagName, certFile, flag+tlsServeKeyFlagName, keyFile))
+
+		require.NoError(t, startCmd.Execute())
+		require.True(t, served)
+		require.Equal(t, tls.VerifyClientCertIfGiven, srv.tlsConfig.ClientAuth)
+	})
+
+	t.Run("failure - TLS version older than 1.2", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+tlsMinVersionFlagName, "1.0"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid TLS policy: unsupported TLS version 1.0")
+	})
+
+	t.Run("failure - insecure cipher suite", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+tlsCipherSuitesFlagName, "TLS_RSA_WITH_RC4_128_SHA"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid TLS policy: unsupported TLS cipher suite TLS_RSA_WITH_RC4_128_SHA")
+	})
+
+	t.Run("failure - certificate without key", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+tlsServeCertFlagName, certFile))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "both tls-serve-cert and tls-serve-key must be set")
+	})
+
+	t.Run("failure - invalid certificate", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+tlsServeCertFlagName, keyFile,
+			flag+tlsServeKeyFlagName, keyFile))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid TLS serve certificate")
+	})
+}
+
+// freeHostURL returns the address of a free local port
+func freeHostURL(t *testing.T) string {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, lis.Close()) }()
+
+	return lis.Addr().String()
+}
+
+func writeCertFile(t *testing.T, key *ecdsa.PrivateKey) string {
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "localhost"},
+		NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour), DNSNames: []string{"localhost"}}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "cert*.pem")
+	require.NoError(t, err)
+
+	require.NoError(t, pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	require.NoError(t, file.Close())
+
+	return file.Name()
+}
+
+func writeKeyFile(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "key*.pem")
+	require.NoError(t, err)
+
+	require.NoError(t, pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, file.Close())
+
+	return file.Name()
+}
+
+func TestProxyMode(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edKeyFile := writeKeyFile(t, edKey)
+	defer func() { require.NoError(t, os.Remove(edKeyFile)) }()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecKeyFile := writeKeyFile(t, ecKey)
+	defer func() { require.NoError(t, os.Remove(ecKeyFile)) }()
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	p384KeyFile := writeKeyFile(t, p384Key)
+	defer func() { require.NoError(t, os.Remove(p384KeyFile)) }()
+
+	proxyArgs := func(args ...string) []string {
+		return append(append(hostURLArg(), flag+modeFlagName, string(proxy)), args...)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		for _, keyFile := range []string{edKeyFile, ecKeyFile} {
+			startCmd := GetStartCmd(&mockServer{})
+
+			startCmd.SetArgs(proxyArgs(flag+proxySigningKeyFlagName, keyFile, flag+proxyCacheTTLFlagName, "30s",
+				flag+proxyWatchIntervalFlagName, "10s"))
+
+			require.NoError(t, startCmd.Execute())
+		}
+	})
+
+	t.Run("failure - missing signing key", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(proxyArgs())
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither proxy-signing-key (command line flag) nor "+
+			"DID_METHOD_PROXY_SIGNING_KEY (environment variable) have been set.")
+	})
+
+	t.Run("failure - invalid signing key", func(t *testing.T) {
+		for keyFile, msg := range map[string]string{
+			p384KeyFile:    "unsupported key type",
+			"missing.pem":  "no such file",
+			startcmdGoFile: "no PEM data found",
+		} {
+			startCmd := GetStartCmd(&mockServer{})
+
+			startCmd.SetArgs(proxyArgs(flag+proxySigningKeyFlagName, keyFile))
+
+			err := startCmd.Execute()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid proxy signing key")
+			require.Contains(t, err.Error(), msg)
+		}
+	})
+
+	t.Run("failure - invalid cache ttl", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(proxyArgs(flag+proxySigningKeyFlagName, edKeyFile, flag+proxyCacheTTLFlagName, "1"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid proxy cache ttl")
+	})
+
+	t.Run("failure - invalid watch interval", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(proxyArgs(flag+proxySigningKeyFlagName, edKeyFile, flag+proxyWatchIntervalFlagName, "x"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid proxy watch interval")
+	})
+}
+
 func TestInValidModeVar(t *testing.T) {
 	startCmd := GetStartCmd(&mockServer{})
 
@@ -118,6 +500,26 @@ func TestDomainFlagVar(t *testing.T) {
 	})
 }
 
+func TestLogLevelFlag(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+logLevelFlagName, "debug"))
+
+		require.NoError(t, startCmd.Execute())
+	})
+
+	t.Run("failure - invalid log level", func(t *testing.T) {
+		startCmd := GetStartCmd(&mockServer{})
+
+		startCmd.SetArgs(append(getValidArgs(), flag+logLevelFlagName, "verbose"))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid log level")
+	})
+}
+
 func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
 	startCmd := GetStartCmd(&mockServer{})
 
@@ -0,0 +1,13 @@
+// Copyright SecureKey Technologies Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+module github.com/trustbloc/trustbloc-did-method/cmd/did-method-wasm
+
+replace github.com/trustbloc/trustbloc-did-method => ../..
+
+replace github.com/piprate/json-gold => github.com/trustbloc/json-gold v0.3.1-0.20200414173446-30d742ee949e
+
+require github.com/trustbloc/trustbloc-did-method v0.0.0
+
+go 1.13
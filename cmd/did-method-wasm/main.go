@@ -0,0 +1,117 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command did-method-wasm exposes did:trustbloc resolution to browser wallets. Once the wasm module is running,
+// the global trustblocDID.newResolver(config) creates a resolver, config being an optional object with the
+// resolverURL and authToken properties. The resolver's resolve(did) method returns a promise of the DID
+// resolution result, and close() frees its resources.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func main() {
+	js.Global().Set("trustblocDID", js.ValueOf(map[string]interface{}{
+		"newResolver": js.FuncOf(newResolver),
+	}))
+
+	select {}
+}
+
+func newResolver(_ js.Value, args []js.Value) interface{} {
+	var opts []trustbloc.Option
+
+	if len(args) > 0 {
+		if resolverURL := stringProperty(args[0], "resolverURL"); resolverURL != "" {
+			opts = append(opts, trustbloc.WithResolverURL(resolverURL))
+		}
+
+		if authToken := stringProperty(args[0], "authToken"); authToken != "" {
+			opts = append(opts, trustbloc.WithAuthToken(authToken))
+		}
+	}
+
+	v := trustbloc.New(opts...)
+
+	return js.ValueOf(map[string]interface{}{
+		"resolve": js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			if len(args) == 0 || args[0].Type() != js.TypeString {
+				return reject("resolve expects a DID string")
+			}
+
+			did := args[0].String()
+
+			return promise(func() (js.Value, error) {
+				doc, err := v.Read(did)
+				if err != nil {
+					return js.Undefined(), err
+				}
+
+				result, err := models.MakeDIDResolutionResult(doc)
+				if err != nil {
+					return js.Undefined(), err
+				}
+
+				return js.Global().Get("JSON").Call("parse", string(result)), nil
+			})
+		}),
+		"close": js.FuncOf(func(js.Value, []js.Value) interface{} {
+			return promise(func() (js.Value, error) {
+				return js.Undefined(), v.Close()
+			})
+		}),
+	})
+}
+
+// promise runs fn in a goroutine, as blocking calls such as http requests would deadlock the js event loop,
+// and returns a promise of its result
+func promise(fn func() (js.Value, error)) js.Value {
+	var handler js.Func
+
+	handler = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		resolve, rejectFn := args[0], args[1]
+
+		go func() {
+			defer handler.Release()
+
+			val, err := fn()
+			if err != nil {
+				rejectFn.Invoke(js.Global().Get("Error").New(err.Error()))
+
+				return
+			}
+
+			resolve.Invoke(val)
+		}()
+
+		return nil
+	})
+
+	return js.Global().Get("Promise").New(handler)
+}
+
+func reject(msg string) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(msg))
+}
+
+func stringProperty(obj js.Value, name string) string {
+	if obj.Type() != js.TypeObject {
+		return ""
+	}
+
+	prop := obj.Get(name)
+	if prop.Type() != js.TypeString {
+		return ""
+	}
+
+	return prop.String()
+}
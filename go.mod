@@ -10,11 +10,18 @@ go 1.13
 
 require (
 	github.com/bluele/gcache v0.0.0-20190518031135-bc40bd653833
+	github.com/btcsuite/btcd v0.20.1-beta
 	github.com/btcsuite/btcutil v1.0.1
+	github.com/golang/protobuf v1.3.3
+	github.com/google/uuid v1.1.1
 	github.com/gorilla/mux v1.7.4
 	github.com/hyperledger/aries-framework-go v0.1.4-0.20200827142339-1873cf75190d
+	github.com/miekg/pkcs11 v1.0.3
+	github.com/piprate/json-gold v0.3.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/square/go-jose/v3 v3.0.0-20200630053402-0a67ce9b0693
 	github.com/stretchr/testify v1.6.1
 	github.com/trustbloc/sidetree-core-go v0.1.4-0.20200818145448-94243b40fa44
+	github.com/xeipuuv/gojsonschema v1.2.0
+	google.golang.org/grpc v1.27.1
 )
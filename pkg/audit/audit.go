@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Operation is the type of a DID operation recorded in the audit log
+type Operation string
+
+// DID operations
+const (
+	Create     Operation = "create"
+	Update     Operation = "update"
+	Recover    Operation = "recover"
+	Deactivate Operation = "deactivate"
+)
+
+// Record is the audit log entry of a DID operation processed by the registrar
+type Record struct {
+	Time      time.Time `json:"time"`
+	Operation Operation `json:"operation"`
+	// Requester identifies who requested the operation, eg. the subject of its TLS client certificate
+	Requester string `json:"requester"`
+	DID       string `json:"did,omitempty"`
+	// Patches are the patches applied to the DID document by the operation
+	Patches []json.RawMessage `json:"patches,omitempty"`
+	// OperationHash is the base64url encoded SHA-256 hash of the operation request
+	OperationHash string `json:"operationHash"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Sink stores audit records
+type Sink interface {
+	Write(record *Record) error
+}
+
+// Hash returns the operation hash of an operation request
+func Hash(request []byte) string {
+	hash := sha256.Sum256(request)
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// MultiSink writes audit records to several sinks
+type MultiSink []Sink
+
+// Write writes the record to each sink, failing if any of them fails
+func (m MultiSink) Write(record *Record) error {
+	var errs []error
+
+	for _, sink := range m {
+		if err := sink.Write(record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to write audit record: %v", errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord() *Record {
+	return &Record{
+		Time:          time.Now().UTC(),
+		Operation:     Create,
+		Requester:     "CN=client",
+		DID:           "did:trustbloc:testnet:123",
+		Patches:       []json.RawMessage{[]byte(`{"action":"replace"}`)},
+		OperationHash: Hash([]byte("request")),
+		Success:       true,
+	}
+}
+
+func TestHash(t *testing.T) {
+	require.Equal(t, "H1i5FFsk0QjXrDiIcziz6jIpgzucHkGCUDQ_kHv9EEc", Hash([]byte("request")))
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	path := filepath.Join(dir, "audit.log")
+
+	t.Run("success", func(t *testing.T) {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Write(testRecord()))
+		require.NoError(t, sink.Write(testRecord()))
+		require.NoError(t, sink.Close())
+
+		file, err := os.Open(path) // nolint: gosec
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, file.Close()) }()
+
+		scanner := bufio.NewScanner(file)
+		lines := 0
+
+		for scanner.Scan() {
+			record := &Record{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+			require.Equal(t, "did:trustbloc:testnet:123", record.DID)
+
+			lines++
+		}
+
+		require.Equal(t, 2, lines)
+	})
+
+	t.Run("failure - closed file", func(t *testing.T) {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Close())
+
+		err = sink.Write(testRecord())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to write audit record")
+	})
+
+	t.Run("failure - invalid path", func(t *testing.T) {
+		_, err := NewFileSink(filepath.Join(dir, "missing", "audit.log"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open audit log file")
+	})
+}
+
+type mockProvider struct {
+	storage.Provider
+	err error
+}
+
+func (p *mockProvider) OpenStore(name string) (storage.Store, error) {
+	return nil, p.err
+}
+
+func TestStoreSink(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		sink, err := NewStoreSink(provider)
+		require.NoError(t, err)
+
+		record := testRecord()
+		require.NoError(t, sink.Write(record))
+
+		store, err := provider.OpenStore(StoreName)
+		require.NoError(t, err)
+
+		recordBytes, err := store.Get(record.Time.Format(time.RFC3339Nano) + "_" + record.OperationHash)
+		require.NoError(t, err)
+		require.Contains(t, string(recordBytes), `"operation":"create"`)
+	})
+
+	t.Run("failure - store not opened", func(t *testing.T) {
+		_, err := NewStoreSink(&mockProvider{err: errors.New("unavailable")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open audit store: unavailable")
+	})
+}
+
+func TestWebhookSink(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			record := &Record{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(record))
+			require.Equal(t, Create, record.Operation)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, NewWebhookSink(srv.URL, WithAuthToken("token"), WithTLSConfig(nil)).Write(testRecord()))
+	})
+
+	t.Run("failure - webhook error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		err := NewWebhookSink(srv.URL).Write(testRecord())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "returned status '500'")
+	})
+
+	t.Run("failure - webhook unreachable", func(t *testing.T) {
+		err := NewWebhookSink("http://127.0.0.1:0").Write(testRecord())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to post audit record")
+
+		err = NewWebhookSink("http://[::1").Write(testRecord())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create audit webhook request")
+	})
+}
+
+type mockSink struct {
+	records []*Record
+	err     error
+}
+
+func (s *mockSink) Write(record *Record) error {
+	s.records = append(s.records, record)
+
+	return s.err
+}
+
+func TestMultiSink(t *testing.T) {
+	first := &mockSink{}
+	second := &mockSink{}
+
+	require.NoError(t, MultiSink{first, second}.Write(testRecord()))
+	require.Len(t, first.records, 1)
+	require.Len(t, second.records, 1)
+
+	second.err = errors.New("unavailable")
+
+	err := MultiSink{first, second}.Write(testRecord())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unavailable")
+	require.Len(t, first.records, 2)
+}
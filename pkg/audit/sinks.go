@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+)
+
+const (
+	// StoreName is the name of the store the audit records are put in by the store sink
+	StoreName = "audit"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// FileSink appends audit records to a file, one JSON record per line
+type FileSink struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileSink opens the audit log file at the given path, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // nolint: gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the record to the file
+func (s *FileSink) Write(record *Record) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err = s.file.Write(append(recordBytes, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record to %s: %w", s.file.Name(), err)
+	}
+
+	return nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// StoreSink puts audit records in a store of a storage provider. Records are keyed by time, so iterating the store
+// lists them in chronological order.
+type StoreSink struct {
+	store storage.Store
+}
+
+// NewStoreSink opens the audit store of the given storage provider
+func NewStoreSink(provider storage.Provider) (*StoreSink, error) {
+	store, err := provider.OpenStore(StoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit store: %w", err)
+	}
+
+	return &StoreSink{store: store}, nil
+}
+
+// Write puts the record in the store
+func (s *StoreSink) Write(record *Record) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	key := record.Time.UTC().Format(time.RFC3339Nano) + "_" + record.OperationHash
+
+	if err = s.store.Put(key, recordBytes); err != nil {
+		return fmt.Errorf("failed to put audit record in store: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookSink posts audit records to a webhook
+type WebhookSink struct {
+	url        string
+	authToken  string
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+}
+
+// NewWebhookSink creates a sink posting the audit records as JSON to the given url
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{url: url}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.httpClient = &http.Client{Timeout: webhookTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlspolicy.Apply(s.tlsConfig)}}
+
+	return s
+}
+
+// Write posts the record to the webhook
+func (s *WebhookSink) Write(record *Record) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(recordBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create audit webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post audit record to %s: %w", s.url, err)
+	}
+
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(resp.Body) // nolint: errcheck
+
+		return fmt.Errorf("audit webhook %s returned status '%d' body %s", s.url, resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// WebhookOption configures the webhook sink
+type WebhookOption func(opts *WebhookSink)
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
+func WithTLSConfig(tlsConfig *tls.Config) WebhookOption {
+	return func(opts *WebhookSink) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithAuthToken option sets the bearer token authorizing the posts to the webhook
+func WithAuthToken(authToken string) WebhookOption {
+	return func(opts *WebhookSink) {
+		opts.authToken = authToken
+	}
+}
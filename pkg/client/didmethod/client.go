@@ -0,0 +1,247 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package didmethod is a one-stop client of the did:trustbloc method: it creates, resolves, updates, recovers and
+// deactivates DIDs, wiring the VDRI, the DID operation client and the key store of the DIDs' operation keys.
+package didmethod
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// key IDs of the operation keys, set in the headers of the signed operations
+const (
+	updateKeyID   = "update"
+	recoveryKeyID = "recovery"
+)
+
+type endpointService interface {
+	GetEndpoints(domain string) ([]*models.Endpoint, error)
+}
+
+// Client manages the DIDs of a consortium. The update and recovery keys of the DIDs it creates are generated and
+// kept in its key store, and rotated by each operation.
+type Client struct {
+	domain          string
+	vdri            *trustbloc.VDRI
+	didClient       *did.Client
+	endpointService endpointService
+	keys            *KeyStore
+}
+
+type clientOpts struct {
+	tlsConfig       *tls.Config
+	authToken       string
+	storageProvider storage.Provider
+	vdriOpts        []trustbloc.Option
+}
+
+// New creates a client of the DIDs of the given consortium domain
+func New(domain string, opts ...Option) (*Client, error) {
+	if domain == "" {
+		return nil, errors.New("consortium domain is required")
+	}
+
+	o := &clientOpts{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.storageProvider == nil {
+		o.storageProvider = mem.NewProvider()
+	}
+
+	keys, err := NewKeyStore(o.storageProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	vdriOpts := []trustbloc.Option{trustbloc.WithTLSConfig(o.tlsConfig)}
+	didOpts := []did.Option{did.WithTLSConfig(o.tlsConfig)}
+
+	if o.authToken != "" {
+		vdriOpts = append(vdriOpts, trustbloc.WithAuthToken(o.authToken))
+		didOpts = append(didOpts, did.WithAuthToken(o.authToken))
+	}
+
+	v := trustbloc.New(append(vdriOpts, o.vdriOpts...)...)
+
+	return &Client{domain: domain, vdri: v, didClient: did.New(didOpts...), endpointService: v, keys: keys}, nil
+}
+
+// CreateDID creates a DID with the public keys and services given as options, generating its update and recovery
+// keys
+func (c *Client) CreateDID(opts ...did.CreateDIDOption) (*docdid.Doc, error) {
+	sidetreeEndpoint, err := c.sidetreeEndpoint(c.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := did.NewOperationKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate operation keys: %w", err)
+	}
+
+	opts = append(append(opts, keys.CreateDIDOptions()...), did.WithSidetreeEndpoint(sidetreeEndpoint))
+
+	doc, err := c.didClient.CreateDID("", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.keys.Put(doc.ID, keys); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// ResolveDID resolves a DID
+func (c *Client) ResolveDID(didID string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	return c.vdri.Read(didID, opts...)
+}
+
+// UpdateDID updates a DID with the patches given as options, signed with the current update key of the DID
+func (c *Client) UpdateDID(didID string, opts ...did.UpdateDIDOption) error {
+	keys, sidetreeEndpoint, err := c.operationParams(didID)
+	if err != nil {
+		return err
+	}
+
+	signingOpts, next, err := keys.RotateUpdateKey(updateKeyID)
+	if err != nil {
+		return err
+	}
+
+	opts = append(append(opts, signingOpts...), did.WithUpdateSidetreeEndpoint(sidetreeEndpoint))
+
+	if err = c.didClient.UpdateDID(didID, opts...); err != nil {
+		return err
+	}
+
+	return c.keys.Put(didID, next)
+}
+
+// RecoverDID replaces the document of a DID with the public keys and services given as options, signed with the
+// current recovery key of the DID
+func (c *Client) RecoverDID(didID string, opts ...did.RecoverDIDOption) error {
+	keys, sidetreeEndpoint, err := c.operationParams(didID)
+	if err != nil {
+		return err
+	}
+
+	signingOpts, next, err := keys.RotateRecoveryKey(recoveryKeyID)
+	if err != nil {
+		return err
+	}
+
+	opts = append(append(opts, signingOpts...), did.WithRecoverSidetreeEndpoint(sidetreeEndpoint))
+
+	if err = c.didClient.RecoverDID(didID, opts...); err != nil {
+		return err
+	}
+
+	return c.keys.Put(didID, next)
+}
+
+// DeactivateDID deactivates a DID, signed with its current recovery key. Its keys are deleted from the key store.
+func (c *Client) DeactivateDID(didID string) error {
+	keys, sidetreeEndpoint, err := c.operationParams(didID)
+	if err != nil {
+		return err
+	}
+
+	opts := append(keys.DeactivateDIDOptions(recoveryKeyID), did.WithDeactivateSidetreeEndpoint(sidetreeEndpoint))
+
+	if err = c.didClient.DeactivateDID(didID, opts...); err != nil {
+		return err
+	}
+
+	return c.keys.Delete(didID)
+}
+
+// Close frees the resources of the client
+func (c *Client) Close() error {
+	return c.vdri.Close()
+}
+
+// operationParams returns the operation keys of a DID and the sidetree endpoint its operations are sent to
+func (c *Client) operationParams(didID string) (*did.OperationKeys, string, error) {
+	parsedDID, err := models.ParseDID(didID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys, err := c.keys.Get(didID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sidetreeEndpoint, err := c.sidetreeEndpoint(parsedDID.Domain)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return keys, sidetreeEndpoint, nil
+}
+
+// sidetreeEndpoint returns the first sidetree endpoint of a consortium, which is validated first
+func (c *Client) sidetreeEndpoint(domain string) (string, error) {
+	endpoints, err := c.endpointService.GetEndpoints(domain)
+	if err != nil {
+		return "", fmt.Errorf("failed to get endpoints of %s: %w", domain, err)
+	}
+
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("no endpoints in %s", domain)
+	}
+
+	return endpoints[0].URL, nil
+}
+
+// Option is a client option
+type Option func(opts *clientOpts)
+
+// WithTLSConfig option sets the TLS config of the connections to the consortium, its stakeholders and sidetree
+// endpoints
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *clientOpts) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithAuthToken option sets the auth token sent to the sidetree endpoints
+func WithAuthToken(authToken string) Option {
+	return func(opts *clientOpts) {
+		opts.authToken = authToken
+	}
+}
+
+// WithStorageProvider option sets the storage provider of the key store of the DIDs' operation keys. Defaults to an
+// in-memory store: the keys of the DIDs are then lost when the process exits, and the DIDs can't be updated anymore.
+func WithStorageProvider(provider storage.Provider) Option {
+	return func(opts *clientOpts) {
+		opts.storageProvider = provider
+	}
+}
+
+// WithVDRIOptions option sets additional options of the VDRI the DIDs are resolved and the consortium validated with
+func WithVDRIOptions(vdriOpts ...trustbloc.Option) Option {
+	return func(opts *clientOpts) {
+		opts.vdriOpts = append(opts.vdriOpts, vdriOpts...)
+	}
+}
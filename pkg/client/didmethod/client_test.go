@@ -0,0 +1,235 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	testDomain = "testnet.example.com"
+	testDID    = "did:trustbloc:" + testDomain + ":EiAtestsuffix"
+	testDoc    = `{"@context":["https://w3id.org/did/v1"],"id":"` + testDID + `"}`
+)
+
+// sidetreeServer is a sidetree node creating and resolving testDID, recording the types of the operations it receives
+type sidetreeServer struct {
+	*httptest.Server
+	operations []string
+	mutex      sync.Mutex
+}
+
+func newSidetreeServer(t *testing.T) *sidetreeServer {
+	s := &sidetreeServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/did+ld+json")
+			fmt.Fprint(w, testDoc)
+
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		op := &struct {
+			Type string `json:"type"`
+		}{}
+		require.NoError(t, json.Unmarshal(body, op))
+
+		s.mutex.Lock()
+		s.operations = append(s.operations, op.Type)
+		s.mutex.Unlock()
+
+		if op.Type == "create" {
+			fmt.Fprintf(w, `{"didDocument":%s}`, testDoc)
+		}
+	}))
+
+	return s
+}
+
+func newTestClient(t *testing.T, serv *sidetreeServer, opts ...Option) *Client {
+	c, err := New(testDomain, append(opts, WithAuthToken("token"),
+		WithVDRIOptions(trustbloc.WithResolverURL(serv.URL), trustbloc.WithAllowInsecureHTTP("127.0.0.1")))...)
+	require.NoError(t, err)
+
+	c.endpointService = &mockendpoint.MockEndpointService{
+		GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+			require.Equal(t, testDomain, domain)
+
+			return []*models.Endpoint{{URL: serv.URL}}, nil
+		}}
+
+	return c
+}
+
+func publicKey(t *testing.T) *did.PublicKey {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return &did.PublicKey{ID: "key1", Type: did.JWSVerificationKey2020, Encoding: did.PublicKeyEncodingJwk,
+		KeyType: did.Ed25519KeyType, Value: pubKey, Purpose: []string{did.KeyPurposeGeneral}}
+}
+
+func TestClient(t *testing.T) {
+	serv := newSidetreeServer(t)
+	defer serv.Close()
+
+	c := newTestClient(t, serv)
+
+	defer func() {
+		require.NoError(t, c.Close())
+	}()
+
+	doc, err := c.CreateDID(did.WithPublicKey(publicKey(t)))
+	require.NoError(t, err)
+	require.Equal(t, testDID, doc.ID)
+
+	created, err := c.keys.Get(testDID)
+	require.NoError(t, err)
+
+	resolved, err := c.ResolveDID(testDID)
+	require.NoError(t, err)
+	require.Equal(t, testDID, resolved.ID)
+
+	require.NoError(t, c.UpdateDID(testDID, did.WithRemoveServices("agent")))
+
+	updated, err := c.keys.Get(testDID)
+	require.NoError(t, err)
+	require.NotEqual(t, created.Update, updated.Update)
+	require.Equal(t, created.Recovery, updated.Recovery)
+
+	require.NoError(t, c.RecoverDID(testDID, did.WithRecoverPublicKey(publicKey(t))))
+
+	recovered, err := c.keys.Get(testDID)
+	require.NoError(t, err)
+	require.NotEqual(t, updated.Update, recovered.Update)
+	require.NotEqual(t, updated.Recovery, recovered.Recovery)
+
+	require.NoError(t, c.DeactivateDID(testDID))
+
+	_, err = c.keys.Get(testDID)
+	require.Error(t, err)
+
+	require.Equal(t, []string{"create", "update", "recover", "deactivate"}, serv.operations)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("failure - no domain", func(t *testing.T) {
+		_, err := New("")
+		require.EqualError(t, err, "consortium domain is required")
+	})
+
+	t.Run("failure - key store", func(t *testing.T) {
+		_, err := New(testDomain, WithTLSConfig(nil),
+			WithStorageProvider(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("unavailable")}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open key store")
+	})
+}
+
+func TestClient_Failures(t *testing.T) {
+	serv := newSidetreeServer(t)
+	defer serv.Close()
+
+	t.Run("failure - get endpoints", func(t *testing.T) {
+		c := newTestClient(t, serv)
+		c.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				return nil, errors.New("invalid consortium")
+			}}
+
+		_, err := c.CreateDID(did.WithPublicKey(publicKey(t)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints of testnet.example.com: invalid consortium")
+
+		c.endpointService = &mockendpoint.MockEndpointService{}
+
+		_, err = c.CreateDID(did.WithPublicKey(publicKey(t)))
+		require.EqualError(t, err, "no endpoints in testnet.example.com")
+	})
+
+	t.Run("failure - create", func(t *testing.T) {
+		_, err := newTestClient(t, serv).CreateDID(did.WithService(&docdid.Service{ID: "agent"}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service type is missing")
+	})
+
+	t.Run("failure - key store", func(t *testing.T) {
+		provider := mockstorage.NewMockStoreProvider()
+		provider.Store.ErrPut = errors.New("put error")
+
+		_, err := newTestClient(t, serv, WithStorageProvider(provider)).CreateDID(did.WithPublicKey(publicKey(t)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put error")
+	})
+
+	t.Run("failure - unknown DID", func(t *testing.T) {
+		c := newTestClient(t, serv)
+
+		err := c.UpdateDID(testDID, did.WithRemoveServices("agent"))
+		require.EqualError(t, err, "no operation keys for "+testDID)
+
+		err = c.RecoverDID(testDID)
+		require.EqualError(t, err, "no operation keys for "+testDID)
+
+		err = c.DeactivateDID(testDID)
+		require.EqualError(t, err, "no operation keys for "+testDID)
+
+		err = c.DeactivateDID("did:trustbloc:EiAtestsuffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:trustbloc DID")
+	})
+
+	t.Run("failure - operations", func(t *testing.T) {
+		c := newTestClient(t, serv)
+
+		_, err := c.CreateDID(did.WithPublicKey(publicKey(t)))
+		require.NoError(t, err)
+
+		keys, err := c.keys.Get(testDID)
+		require.NoError(t, err)
+
+		err = c.UpdateDID(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one patch is required")
+
+		err = c.RecoverDID(testDID, did.WithRecoverService(&docdid.Service{ID: "agent"}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service type is missing")
+
+		// failed operations don't rotate the keys
+		unchanged, err := c.keys.Get(testDID)
+		require.NoError(t, err)
+		require.Equal(t, keys, unchanged)
+
+		serv.Close()
+
+		err = c.DeactivateDID(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send deactivate sidetree request")
+	})
+}
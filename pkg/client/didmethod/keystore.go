@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+)
+
+// KeyStoreName is the name of the store the operation keys of DIDs are put in
+const KeyStoreName = "didmethod_keys"
+
+// KeyStore stores the update and recovery keys of DIDs between operations
+type KeyStore struct {
+	store storage.Store
+}
+
+// NewKeyStore opens the key store in the store of the given storage provider
+func NewKeyStore(provider storage.Provider) (*KeyStore, error) {
+	store, err := provider.OpenStore(KeyStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key store: %w", err)
+	}
+
+	return &KeyStore{store: store}, nil
+}
+
+// Get returns the operation keys of a DID
+func (s *KeyStore) Get(didID string) (*did.OperationKeys, error) {
+	data, err := s.store.Get(didID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("no operation keys for %s", didID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation keys of %s: %w", didID, err)
+	}
+
+	return did.ParseOperationKeys(data)
+}
+
+// Put stores the operation keys of a DID, replacing its previous keys
+func (s *KeyStore) Put(didID string, keys *did.OperationKeys) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation keys: %w", err)
+	}
+
+	if err = s.store.Put(didID, data); err != nil {
+		return fmt.Errorf("failed to put operation keys of %s: %w", didID, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the operation keys of a DID
+func (s *KeyStore) Delete(didID string) error {
+	if err := s.store.Delete(didID); err != nil {
+		return fmt.Errorf("failed to delete operation keys of %s: %w", didID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"errors"
+	"testing"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+)
+
+func TestKeyStore(t *testing.T) {
+	keys, err := did.NewOperationKeys()
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		s, err := NewKeyStore(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(testDID, keys))
+
+		stored, err := s.Get(testDID)
+		require.NoError(t, err)
+		require.Equal(t, keys, stored)
+
+		require.NoError(t, s.Delete(testDID))
+
+		_, err = s.Get(testDID)
+		require.EqualError(t, err, "no operation keys for "+testDID)
+	})
+
+	t.Run("failure - store not opened", func(t *testing.T) {
+		_, err := NewKeyStore(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("unavailable")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open key store: unavailable")
+	})
+
+	t.Run("failure - store errors", func(t *testing.T) {
+		provider := mockstorage.NewMockStoreProvider()
+		provider.Store.ErrPut = errors.New("put error")
+		provider.Store.ErrGet = errors.New("get error")
+		provider.Store.ErrDelete = errors.New("delete error")
+
+		s, err := NewKeyStore(provider)
+		require.NoError(t, err)
+
+		err = s.Put(testDID, keys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put error")
+
+		_, err = s.Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get error")
+
+		err = s.Delete(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete error")
+	})
+
+	t.Run("failure - invalid keys", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := NewKeyStore(provider)
+		require.NoError(t, err)
+
+		store, err := provider.OpenStore(KeyStoreName)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(testDID, []byte("{}")))
+
+		_, err = s.Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported operation keys version")
+	})
+}
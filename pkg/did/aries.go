@@ -0,0 +1,323 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	gojose "github.com/square/go-jose/v3"
+)
+
+// verification relationships of the key purposes, in the order they are listed in docs
+var purposeRelationships = []struct { // nolint: gochecknoglobals
+	purpose      string
+	relationship docdid.VerificationRelationship
+}{
+	{KeyPurposeAuth, docdid.Authentication},
+	{KeyPurposeAssertion, docdid.AssertionMethod},
+	{KeyPurposeDelegation, docdid.CapabilityDelegation},
+	{KeyPurposeInvocation, docdid.CapabilityInvocation},
+	{KeyPurposeAgreement, docdid.KeyAgreement},
+}
+
+// PurposeRelationship returns the verification relationship the keys with the given purpose are listed in. The
+// general purpose has none: general keys are listed in the public keys of the document.
+func PurposeRelationship(purpose string) (docdid.VerificationRelationship, bool) {
+	for _, pr := range purposeRelationships {
+		if pr.purpose == purpose {
+			return pr.relationship, true
+		}
+	}
+
+	return docdid.VerificationRelationshipGeneral, false
+}
+
+// ToAriesDoc converts a doc to an aries DID document with the given DID. Keys with the general purpose (or
+// without purposes) are listed as public keys, and referenced by the verification relationships of their other
+// purposes; other keys are embedded in their verification relationships. Recovery and update keys are omitted,
+// as they are not part of the resolved document.
+func ToAriesDoc(doc *Doc, didID string) (*docdid.Doc, error) {
+	out := &docdid.Doc{Context: append([]string{docdid.Context}, extensionContexts(doc.Context)...), ID: didID}
+
+	for i := range doc.PublicKey {
+		pk := &doc.PublicKey[i]
+		if pk.Recovery || pk.Update {
+			continue
+		}
+
+		ariesPK, err := ariesPublicKey(pk, didID)
+		if err != nil {
+			return nil, err
+		}
+
+		general := len(pk.Purpose) == 0 || hasPurpose(pk, KeyPurposeGeneral)
+		if general {
+			out.PublicKey = append(out.PublicKey, *ariesPK)
+		}
+
+		for _, pr := range purposeRelationships {
+			if !hasPurpose(pk, pr.purpose) {
+				continue
+			}
+
+			var vm *docdid.VerificationMethod
+			if general {
+				vm = docdid.NewReferencedVerificationMethod(ariesPK, pr.relationship, false)
+			} else {
+				vm = docdid.NewEmbeddedVerificationMethod(ariesPK, pr.relationship)
+			}
+
+			addVerificationMethod(out, vm)
+		}
+	}
+
+	for i := range doc.Service {
+		svc := doc.Service[i]
+		if strings.HasPrefix(svc.ID, "#") {
+			svc.ID = didID + svc.ID
+		}
+
+		out.Service = append(out.Service, svc)
+	}
+
+	return out, nil
+}
+
+// FromAriesDoc converts an aries DID document to a doc, eg. to create or update a DID from a resolved document.
+// Public keys listed in the document get the general purpose, and the purposes of the verification relationships
+// referencing or embedding them.
+func FromAriesDoc(doc *docdid.Doc) (*Doc, error) {
+	out := &Doc{Context: extensionContexts(doc.Context)}
+	keys := make(map[string]*PublicKey)
+
+	var order []string
+
+	add := func(ariesPK *docdid.PublicKey, purpose string) error {
+		pk, ok := keys[ariesPK.ID]
+		if !ok {
+			var err error
+
+			pk, err = FromAriesPublicKey(ariesPK)
+			if err != nil {
+				return err
+			}
+
+			keys[ariesPK.ID] = pk
+			order = append(order, ariesPK.ID)
+		}
+
+		if !hasPurpose(pk, purpose) {
+			pk.Purpose = append(pk.Purpose, purpose)
+		}
+
+		return nil
+	}
+
+	for i := range doc.PublicKey {
+		if err := add(&doc.PublicKey[i], KeyPurposeGeneral); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pr := range purposeRelationships {
+		for _, vm := range verificationMethods(doc, pr.relationship) {
+			vm := vm
+			if err := add(&vm.PublicKey, pr.purpose); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, id := range order {
+		out.PublicKey = append(out.PublicKey, *keys[id])
+	}
+
+	out.Service = append(out.Service, doc.Service...)
+
+	return out, nil
+}
+
+// BLS12-381 G2 and X25519 public keys, which JWKs of the aries framework don't support
+type (
+	bls12381G2PublicKey []byte
+	x25519PublicKey     []byte
+)
+
+// FromAriesPublicKey converts an aries public key, given as JWK or as raw Ed25519VerificationKey2018,
+// Bls12381G2Key2020 or X25519KeyAgreementKey2019 bytes, to a JWK encoded public key without purposes
+func FromAriesPublicKey(pk *docdid.PublicKey) (*PublicKey, error) {
+	var key interface{}
+
+	if jwk := pk.JSONWebKey(); jwk != nil {
+		key = jwk.Key
+	} else {
+		key = rawPublicKey(pk)
+	}
+
+	out := &PublicKey{
+		ID:       pk.ID[strings.LastIndex(pk.ID, "#")+1:],
+		Type:     pk.Type,
+		Encoding: PublicKeyEncodingJwk,
+	}
+
+	// the controller is only kept when it isn't the DID of the key
+	if pk.Controller != "" && !strings.HasPrefix(pk.ID, pk.Controller+"#") {
+		out.Controller = pk.Controller
+	}
+
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		out.KeyType = Ed25519KeyType
+		out.Value = k
+	case *ecdsa.PublicKey:
+		out.KeyType = ecKeyType(k.Curve)
+		if out.KeyType == "" {
+			return nil, fmt.Errorf("public key %s: unsupported curve %s", pk.ID, k.Curve.Params().Name)
+		}
+
+		out.Value = elliptic.Marshal(k.Curve, k.X, k.Y)
+	case bls12381G2PublicKey:
+		out.KeyType = Bls12381G2KeyType
+		out.Value = k
+	case x25519PublicKey:
+		out.KeyType = X25519KeyType
+		out.Value = k
+	default:
+		return nil, fmt.Errorf("public key %s: unsupported key type %s", pk.ID, pk.Type)
+	}
+
+	return out, nil
+}
+
+// rawPublicKey returns the key of an aries public key given as raw bytes, or nil if its type isn't supported
+func rawPublicKey(pk *docdid.PublicKey) interface{} {
+	switch pk.Type {
+	case Ed25519VerificationKey2018:
+		return ed25519.PublicKey(pk.Value)
+	case Bls12381G2Key2020:
+		return bls12381G2PublicKey(pk.Value)
+	case X25519KeyAgreementKey2019:
+		return x25519PublicKey(pk.Value)
+	}
+
+	return nil
+}
+
+func ariesPublicKey(pk *PublicKey, didID string) (*docdid.PublicKey, error) {
+	var key interface{}
+
+	id := didID + "#" + strings.TrimPrefix(pk.ID, "#")
+
+	controller := didID
+	if pk.Controller != "" {
+		controller = pk.Controller
+	}
+
+	if pk.JWK != nil {
+		return ariesJWKPublicKey(pk, id, controller)
+	}
+
+	switch pk.KeyType {
+	case Ed25519KeyType:
+		key = ed25519.PublicKey(pk.Value)
+	case P256KeyType, P384KeyType, P521KeyType, Secp256k1KeyType:
+		ecKey, err := ecPublicKey(pk)
+		if err != nil {
+			return nil, err
+		}
+
+		key = ecKey
+	case Bls12381G2KeyType, X25519KeyType:
+		return docdid.NewPublicKeyFromBytes(id, pk.Type, controller, pk.Value), nil
+	default:
+		return nil, fmt.Errorf("invalid key type: %s", pk.KeyType)
+	}
+
+	// the kid of the JWK is the fragment of the key ID, as JsonWebKey2020 requires
+	return docdid.NewPublicKeyFromJWK(id, pk.Type, controller,
+		&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: key, KeyID: strings.TrimPrefix(pk.ID, "#")}})
+}
+
+// ariesJWKPublicKey converts a public key set as JWK to an aries public key
+func ariesJWKPublicKey(pk *PublicKey, id, controller string) (*docdid.PublicKey, error) {
+	data, err := json.Marshal(pk.JWK)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := &jose.JWK{}
+
+	if err = jwk.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("public key %s: invalid JWK: %w", pk.ID, err)
+	}
+
+	// the kid of the JWK is the fragment of the key ID, as JsonWebKey2020 requires
+	jwk.KeyID = strings.TrimPrefix(pk.ID, "#")
+
+	return docdid.NewPublicKeyFromJWK(id, pk.Type, controller, jwk)
+}
+
+// extensionContexts returns the contexts other than the DID context
+func extensionContexts(contexts []string) []string {
+	var out []string
+
+	for _, context := range contexts {
+		if context != docdid.Context {
+			out = append(out, context)
+		}
+	}
+
+	return out
+}
+
+func addVerificationMethod(doc *docdid.Doc, vm *docdid.VerificationMethod) {
+	switch vm.Relationship { // nolint: exhaustive
+	case docdid.Authentication:
+		doc.Authentication = append(doc.Authentication, *vm)
+	case docdid.AssertionMethod:
+		doc.AssertionMethod = append(doc.AssertionMethod, *vm)
+	case docdid.CapabilityDelegation:
+		doc.CapabilityDelegation = append(doc.CapabilityDelegation, *vm)
+	case docdid.CapabilityInvocation:
+		doc.CapabilityInvocation = append(doc.CapabilityInvocation, *vm)
+	case docdid.KeyAgreement:
+		doc.KeyAgreement = append(doc.KeyAgreement, *vm)
+	}
+}
+
+func verificationMethods(doc *docdid.Doc, relationship docdid.VerificationRelationship) []docdid.VerificationMethod {
+	switch relationship { // nolint: exhaustive
+	case docdid.Authentication:
+		return doc.Authentication
+	case docdid.AssertionMethod:
+		return doc.AssertionMethod
+	case docdid.CapabilityDelegation:
+		return doc.CapabilityDelegation
+	case docdid.CapabilityInvocation:
+		return doc.CapabilityInvocation
+	case docdid.KeyAgreement:
+		return doc.KeyAgreement
+	}
+
+	return nil
+}
+
+func hasPurpose(pk *PublicKey, purpose string) bool {
+	for _, p := range pk.Purpose {
+		if p == purpose {
+			return true
+		}
+	}
+
+	return false
+}
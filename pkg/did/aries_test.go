@@ -0,0 +1,328 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	gojose "github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const testDID = "did:trustbloc:testnet:123"
+
+func TestToAriesDoc(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecPub := elliptic.Marshal(elliptic.P256(), ecPriv.X, ecPriv.Y)
+
+	t.Run("success", func(t *testing.T) {
+		doc := &Doc{
+			PublicKey: []PublicKey{
+				{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+					Value: edPub, Purpose: []string{KeyPurposeGeneral, KeyPurposeAuth}},
+				{ID: "#key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: P256KeyType,
+					Value: ecPub, Purpose: []string{KeyPurposeAssertion, KeyPurposeInvocation}},
+				{ID: "recovery", Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: edPub,
+					Recovery: true},
+			},
+			Service: []docdid.Service{{ID: "#hub", Type: "hub", ServiceEndpoint: "https://example.com/hub"}},
+		}
+
+		ariesDoc, err := ToAriesDoc(doc, testDID)
+		require.NoError(t, err)
+		require.Equal(t, testDID, ariesDoc.ID)
+
+		require.Len(t, ariesDoc.PublicKey, 1)
+		require.Equal(t, testDID+"#key1", ariesDoc.PublicKey[0].ID)
+		require.Equal(t, testDID, ariesDoc.PublicKey[0].Controller)
+		require.Equal(t, "key1", ariesDoc.PublicKey[0].JSONWebKey().KeyID)
+
+		require.Len(t, ariesDoc.Authentication, 1)
+		require.False(t, ariesDoc.Authentication[0].Embedded)
+		require.Equal(t, testDID+"#key1", ariesDoc.Authentication[0].PublicKey.ID)
+
+		require.Len(t, ariesDoc.AssertionMethod, 1)
+		require.True(t, ariesDoc.AssertionMethod[0].Embedded)
+		require.Equal(t, testDID+"#key2", ariesDoc.AssertionMethod[0].PublicKey.ID)
+		require.Len(t, ariesDoc.CapabilityInvocation, 1)
+		require.Empty(t, ariesDoc.CapabilityDelegation)
+
+		require.Equal(t, testDID+"#hub", ariesDoc.Service[0].ID)
+
+		// the converted doc serializes to a valid did document
+		bytes, err := ariesDoc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := docdid.ParseDocument(bytes)
+		require.NoError(t, err)
+		require.Len(t, parsed.Authentication, 1)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{
+			{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+				Value: edPub, Purpose: []string{KeyPurposeGeneral, KeyPurposeAuth, KeyPurposeDelegation}},
+			{ID: "key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: P256KeyType,
+				Value: ecPub, Purpose: []string{KeyPurposeAssertion}},
+		}}
+
+		ariesDoc, err := ToAriesDoc(doc, testDID)
+		require.NoError(t, err)
+
+		converted, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Equal(t, doc, converted)
+	})
+
+	t.Run("contexts", func(t *testing.T) {
+		doc := &Doc{Context: []string{"https://example.com/context/v1"}}
+
+		ariesDoc, err := ToAriesDoc(doc, testDID)
+		require.NoError(t, err)
+		require.Equal(t, []string{docdid.Context, "https://example.com/context/v1"}, ariesDoc.Context)
+
+		converted, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Equal(t, doc, converted)
+	})
+
+	t.Run("controller", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{
+			{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+				Value: edPub, Purpose: []string{KeyPurposeGeneral}, Controller: "did:example:controller"},
+		}}
+
+		ariesDoc, err := ToAriesDoc(doc, testDID)
+		require.NoError(t, err)
+		require.Equal(t, "did:example:controller", ariesDoc.PublicKey[0].Controller)
+
+		converted, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Equal(t, doc, converted)
+	})
+
+	t.Run("failure - invalid key type", func(t *testing.T) {
+		_, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{{ID: "key1", KeyType: "RSA"}}}, testDID)
+		require.EqualError(t, err, "invalid key type: RSA")
+	})
+
+	t.Run("failure - invalid P-256 point", func(t *testing.T) {
+		_, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{{ID: "key1", KeyType: P256KeyType, Value: edPub}}}, testDID)
+		require.EqualError(t, err, "public key key1: invalid P-256 point")
+	})
+}
+
+func TestPurposeRelationships(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	relationships := map[string]docdid.VerificationRelationship{
+		KeyPurposeAuth:       docdid.Authentication,
+		KeyPurposeAssertion:  docdid.AssertionMethod,
+		KeyPurposeDelegation: docdid.CapabilityDelegation,
+		KeyPurposeInvocation: docdid.CapabilityInvocation,
+		KeyPurposeAgreement:  docdid.KeyAgreement,
+	}
+
+	for purpose, relationship := range relationships {
+		r, ok := PurposeRelationship(purpose)
+		require.True(t, ok)
+		require.Equal(t, relationship, r)
+	}
+
+	_, ok := PurposeRelationship(KeyPurposeGeneral)
+	require.False(t, ok)
+
+	purposes := []string{KeyPurposeAuth, KeyPurposeAssertion, KeyPurposeDelegation, KeyPurposeInvocation,
+		KeyPurposeAgreement, KeyPurposeGeneral}
+
+	// every combination of purposes, as a bit set
+	for combination := 1; combination < 1<<len(purposes); combination++ {
+		var keyPurposes []string
+
+		for i, purpose := range purposes {
+			if combination&(1<<i) != 0 {
+				keyPurposes = append(keyPurposes, purpose)
+			}
+		}
+
+		pk := PublicKey{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: keyPurposes}
+		require.NoError(t, ValidateDocument(&Doc{PublicKey: []PublicKey{pk}}), keyPurposes)
+
+		ariesDoc, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{pk}}, testDID)
+		require.NoError(t, err)
+
+		general := hasPurpose(&pk, KeyPurposeGeneral)
+		require.Equal(t, general, len(ariesDoc.PublicKey) == 1, keyPurposes)
+
+		for purpose, relationship := range relationships {
+			vms := verificationMethods(ariesDoc, relationship)
+			if !hasPurpose(&pk, purpose) {
+				require.Empty(t, vms, keyPurposes)
+
+				continue
+			}
+
+			require.Len(t, vms, 1, keyPurposes)
+			require.Equal(t, testDID+"#key1", vms[0].PublicKey.ID)
+			require.Equal(t, !general, vms[0].Embedded, keyPurposes)
+		}
+
+		doc, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Len(t, doc.PublicKey, 1)
+		require.ElementsMatch(t, keyPurposes, doc.PublicKey[0].Purpose)
+	}
+}
+
+func TestFromAriesDoc(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pk1 := docdid.NewPublicKeyFromBytes(testDID+"#key1", Ed25519VerificationKey2018, testDID, pub)
+	pk2 := docdid.NewPublicKeyFromBytes(testDID+"#key2", Ed25519VerificationKey2018, testDID, pub)
+
+	t.Run("success", func(t *testing.T) {
+		doc, err := FromAriesDoc(&docdid.Doc{
+			ID:        testDID,
+			PublicKey: []docdid.PublicKey{*pk1},
+			Authentication: []docdid.VerificationMethod{
+				*docdid.NewReferencedVerificationMethod(pk1, docdid.Authentication, false)},
+			AssertionMethod: []docdid.VerificationMethod{
+				*docdid.NewReferencedVerificationMethod(pk1, docdid.AssertionMethod, false),
+				*docdid.NewEmbeddedVerificationMethod(pk2, docdid.AssertionMethod)},
+			Service: []docdid.Service{{ID: "hub", Type: "hub"}},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, doc.PublicKey, 2)
+		require.Equal(t, "key1", doc.PublicKey[0].ID)
+		require.Equal(t, []string{KeyPurposeGeneral, KeyPurposeAuth, KeyPurposeAssertion}, doc.PublicKey[0].Purpose)
+		require.Equal(t, "key2", doc.PublicKey[1].ID)
+		require.Equal(t, []string{KeyPurposeAssertion}, doc.PublicKey[1].Purpose)
+		require.Equal(t, []docdid.Service{{ID: "hub", Type: "hub"}}, doc.Service)
+	})
+
+	t.Run("failure - unsupported key", func(t *testing.T) {
+		_, err := FromAriesDoc(&docdid.Doc{
+			Authentication: []docdid.VerificationMethod{*docdid.NewEmbeddedVerificationMethod(
+				docdid.NewPublicKeyFromBytes("#key3", "RsaVerificationKey2018", testDID, []byte("key")),
+				docdid.Authentication)},
+		})
+		require.EqualError(t, err, "public key #key3: unsupported key type RsaVerificationKey2018")
+	})
+}
+
+func TestFromAriesPublicKey(t *testing.T) {
+	t.Run("ed25519 key", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		pk, err := FromAriesPublicKey(docdid.NewPublicKeyFromBytes("did:example:123#key1",
+			Ed25519VerificationKey2018, "did:example:123", pub))
+		require.NoError(t, err)
+		require.Equal(t, "key1", pk.ID)
+		require.Equal(t, Ed25519KeyType, pk.KeyType)
+		require.Equal(t, []byte(pub), pk.Value)
+	})
+
+	t.Run("P-256 key", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		pub := &priv.PublicKey
+
+		ariesPK, err := docdid.NewPublicKeyFromJWK("key2", JWSVerificationKey2020, "did:example:123",
+			&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: pub}})
+		require.NoError(t, err)
+
+		pk, err := FromAriesPublicKey(ariesPK)
+		require.NoError(t, err)
+		require.Equal(t, "key2", pk.ID)
+		require.Equal(t, P256KeyType, pk.KeyType)
+		require.Equal(t, elliptic.Marshal(pub.Curve, pub.X, pub.Y), pk.Value)
+	})
+
+	t.Run("P-384 and P-521 keys", func(t *testing.T) {
+		for keyType, curve := range map[string]elliptic.Curve{P384KeyType: elliptic.P384(), P521KeyType: elliptic.P521()} {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			pub := elliptic.Marshal(curve, priv.X, priv.Y)
+
+			ariesPK, err := ariesPublicKey(&PublicKey{ID: "key3", Type: JWSVerificationKey2020, KeyType: keyType,
+				Value: pub}, "did:example:123")
+			require.NoError(t, err)
+
+			pk, err := FromAriesPublicKey(ariesPK)
+			require.NoError(t, err)
+			require.Equal(t, keyType, pk.KeyType)
+			require.Equal(t, pub, pk.Value)
+		}
+	})
+
+	t.Run("BLS12-381 G2 key", func(t *testing.T) {
+		pub := make([]byte, 96)
+		_, err := rand.Read(pub)
+		require.NoError(t, err)
+
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key4", Type: Bls12381G2Key2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Bls12381G2KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral, KeyPurposeAssertion}}}}
+
+		ariesDoc, err := ToAriesDoc(doc, "did:example:123")
+		require.NoError(t, err)
+		require.Equal(t, pub, ariesDoc.PublicKey[0].Value)
+
+		converted, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Equal(t, doc, converted)
+	})
+
+	t.Run("X25519 key agreement key", func(t *testing.T) {
+		pub := make([]byte, 32)
+		_, err := rand.Read(pub)
+		require.NoError(t, err)
+
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key5", Type: X25519KeyAgreementKey2019,
+			Encoding: PublicKeyEncodingJwk, KeyType: X25519KeyType, Value: pub, Purpose: []string{KeyPurposeAgreement}}}}
+
+		ariesDoc, err := ToAriesDoc(doc, "did:example:123")
+		require.NoError(t, err)
+		require.Empty(t, ariesDoc.PublicKey)
+		require.Len(t, ariesDoc.KeyAgreement, 1)
+		require.True(t, ariesDoc.KeyAgreement[0].Embedded)
+		require.Equal(t, pub, ariesDoc.KeyAgreement[0].PublicKey.Value)
+
+		converted, err := FromAriesDoc(ariesDoc)
+		require.NoError(t, err)
+		require.Equal(t, doc, converted)
+	})
+
+	t.Run("failure - unsupported curve", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		require.NoError(t, err)
+
+		ariesPK, err := docdid.NewPublicKeyFromJWK("key3", JWSVerificationKey2020, "did:example:123",
+			&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: &priv.PublicKey}})
+		require.NoError(t, err)
+
+		_, err = FromAriesPublicKey(ariesPK)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported curve P-224")
+	})
+}
@@ -7,7 +7,7 @@ package did
 
 import (
 	"bytes"
-	"crypto/ed25519"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -17,13 +17,15 @@ import (
 	"net/http"
 
 	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
-	log "github.com/sirupsen/logrus"
 	"github.com/square/go-jose/v3"
 	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
-	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/httpconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/discovery/staticdiscovery"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/endpoint"
@@ -44,7 +46,9 @@ type Client struct {
 	endpointService endpointService
 	client          *http.Client
 	tlsConfig       *tls.Config
+	pins            map[string][]string
 	authToken       string
+	logger          log.Logger
 }
 
 type didResolution struct {
@@ -63,8 +67,9 @@ func New(opts ...Option) *Client {
 		opt(c)
 	}
 
-	c.client.Transport = &http.Transport{TLSClientConfig: c.tlsConfig}
-	configService := httpconfig.NewService(httpconfig.WithTLSConfig(c.tlsConfig))
+	c.logger = log.OrDefault(c.logger)
+	c.client.Transport = transport.New(c.tlsConfig, &transport.Options{PinnedCertificates: c.pins})
+	configService := httpconfig.NewService(httpconfig.WithTransport(c.client.Transport))
 	c.endpointService = endpoint.NewService(
 		staticdiscovery.NewService(configService),
 		staticselection.NewService(configService))
@@ -87,16 +92,12 @@ func (c *Client) CreateDID(domain string, opts ...CreateDIDOption) (*docdid.Doc,
 	sidetreeEndpoint := createDIDOpts.sidetreeEndpoint
 
 	if domain != "" {
-		endpoints, err := c.endpointService.GetEndpoints(domain)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get endpoints: %w", err)
-		}
+		var err error
 
-		if len(endpoints) == 0 {
-			return nil, errors.New("list of endpoints is empty")
+		sidetreeEndpoint, err = c.sidetreeEndpoint(domain)
+		if err != nil {
+			return nil, err
 		}
-
-		sidetreeEndpoint = endpoints[0].URL
 	}
 
 	req, err := c.buildSideTreeRequest(createDIDOpts)
@@ -104,7 +105,18 @@ func (c *Client) CreateDID(domain string, opts ...CreateDIDOption) (*docdid.Doc,
 		return nil, fmt.Errorf("failed to build sidetree request: %w", err)
 	}
 
-	resDoc, err := c.sendCreateRequest(req, sidetreeEndpoint)
+	if createDIDOpts.requestHandler != nil {
+		if err = handleCreateRequest(req, createDIDOpts.requestHandler); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := createDIDOpts.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	resDoc, err := c.sendCreateRequest(ctx, req, sidetreeEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send create sidetree request: %w", err)
 	}
@@ -133,10 +145,8 @@ func unwrapPubKeyJWK(key PublicKey) (*PublicKey, error) { // nolint: gocritic
 	return &out, nil
 }
 
-// buildSideTreeRequest request builder for sidetree public DID creation
-func (c *Client) buildSideTreeRequest(createDIDOpts *CreateDIDOpts) ([]byte, error) {
-	publicKeys := createDIDOpts.publicKeys
-
+// unwrapPublicKeys unwraps the JWK values of public keys, and sets the thumbprint of the keys without ID as their ID
+func unwrapPublicKeys(publicKeys []PublicKey) ([]PublicKey, error) {
 	var parsedKeys []PublicKey
 
 	for _, key := range publicKeys {
@@ -145,12 +155,37 @@ func (c *Client) buildSideTreeRequest(createDIDOpts *CreateDIDOpts) ([]byte, err
 			return nil, err
 		}
 
+		if parsedKey.ID == "" && !parsedKey.Recovery && !parsedKey.Update {
+			parsedKey.ID, err = parsedKey.Thumbprint()
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute public key ID: %w", err)
+			}
+		}
+
 		parsedKeys = append(parsedKeys, *parsedKey)
 	}
 
+	return parsedKeys, nil
+}
+
+// buildSideTreeRequest request builder for sidetree public DID creation
+func (c *Client) buildSideTreeRequest(createDIDOpts *CreateDIDOpts) ([]byte, error) {
+	publicKeys := createDIDOpts.publicKeys
+
+	parsedKeys, err := unwrapPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	doc := &Doc{
-		PublicKey: parsedKeys,
-		Service:   createDIDOpts.services,
+		Context:     createDIDOpts.contexts,
+		PublicKey:   parsedKeys,
+		Service:     createDIDOpts.services,
+		AlsoKnownAs: createDIDOpts.alsoKnownAs,
+	}
+
+	if err = ValidateDocument(doc); err != nil {
+		return nil, err
 	}
 
 	docBytes, err := doc.JSONBytes()
@@ -191,6 +226,22 @@ func (c *Client) buildSideTreeRequest(createDIDOpts *CreateDIDOpts) ([]byte, err
 	return req, nil
 }
 
+// handleCreateRequest calls the handler with the suffix of the DID created by a sidetree create request, computed
+// from its suffix data like sidetree does
+func handleCreateRequest(req []byte, handler func(didSuffix string) error) error {
+	createReq := &model.CreateRequest{}
+	if err := json.Unmarshal(req, createReq); err != nil {
+		return fmt.Errorf("failed to parse sidetree request: %w", err)
+	}
+
+	didSuffix, err := docutil.CalculateUniqueSuffix(createReq.SuffixData, sha2_256)
+	if err != nil {
+		return fmt.Errorf("failed to compute DID suffix: %w", err)
+	}
+
+	return handler(didSuffix)
+}
+
 func (c *Client) getRecoveryKey(publicKeys []PublicKey) (*jws.JWK, error) {
 	for _, v := range publicKeys {
 		if v.Recovery {
@@ -198,7 +249,7 @@ func (c *Client) getRecoveryKey(publicKeys []PublicKey) (*jws.JWK, error) {
 				return nil, fmt.Errorf("recovery public key encoding not supported: %s", v.Encoding)
 			}
 
-			return pubkey.GetPublicKeyJWK(ed25519.PublicKey(v.Value))
+			return operationKeyJWK(v)
 		}
 	}
 
@@ -212,40 +263,32 @@ func (c *Client) getUpdateKey(publicKeys []PublicKey) (*jws.JWK, error) {
 				return nil, fmt.Errorf("update public key encoding not supported: %s", v.Encoding)
 			}
 
-			return pubkey.GetPublicKeyJWK(ed25519.PublicKey(v.Value))
+			return operationKeyJWK(v)
 		}
 	}
 
 	return nil, fmt.Errorf("update key not found")
 }
 
-func (c *Client) sendCreateRequest(req []byte, endpointURL string) (*docdid.Doc, error) {
-	httpReq, err := http.NewRequest(http.MethodPost, endpointURL+"/operations", bytes.NewReader(req))
+// operationKeyJWK returns the JWK of a recovery or update key, with its value unwrapped like the values of the
+// document keys, so JSON JWKs and EC keys are supported too. Raw values without key type are Ed25519 keys.
+func operationKeyJWK(key PublicKey) (*jws.JWK, error) { // nolint: gocritic
+	parsedKey, err := unwrapPubKeyJWK(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create http request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	if c.authToken != "" {
-		httpReq.Header.Add("Authorization", c.authToken)
+		return nil, err
 	}
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	if parsedKey.JWK == nil && parsedKey.KeyType == "" {
+		parsedKey.KeyType = Ed25519KeyType
 	}
 
-	defer closeResponseBody(resp.Body)
+	return publicKeyJWK(parsedKey)
+}
 
-	responseBytes, err := ioutil.ReadAll(resp.Body)
+func (c *Client) sendCreateRequest(ctx context.Context, req []byte, endpointURL string) (*docdid.Doc, error) {
+	responseBytes, err := c.sendRequest(ctx, req, endpointURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response : %s", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("got unexpected response from %s status '%d' body %s",
-			endpointURL, resp.StatusCode, responseBytes)
+		return nil, err
 	}
 
 	var r didResolution
@@ -267,10 +310,43 @@ func (c *Client) sendCreateRequest(req []byte, endpointURL string) (*docdid.Doc,
 	return didDoc, nil
 }
 
-func closeResponseBody(respBody io.Closer) {
+// sendRequest posts a sidetree operation request to the given endpoint, returning the response body
+func (c *Client) sendRequest(ctx context.Context, req []byte, endpointURL string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL+"/operations", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	if c.authToken != "" {
+		httpReq.Header.Add("Authorization", c.authToken)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer c.closeResponseBody(resp.Body)
+
+	responseBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response : %s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got unexpected response from %s status '%d' body %s",
+			endpointURL, resp.StatusCode, responseBytes)
+	}
+
+	return responseBytes, nil
+}
+
+func (c *Client) closeResponseBody(respBody io.Closer) {
 	e := respBody.Close()
 	if e != nil {
-		log.Errorf("Failed to close response body: %v", e)
+		c.logger.Errorf("Failed to close response body: %v", e)
 	}
 }
 
@@ -284,6 +360,15 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 	}
 }
 
+// WithPinnedCertificates option requires the certificate chains presented by the given hosts to match one of the
+// host's pins. A pin is the base64 encoded SHA-256 digest of a certificate or of its public key info, optionally
+// prefixed with "sha256/".
+func WithPinnedCertificates(pins map[string][]string) Option {
+	return func(opts *Client) {
+		opts.pins = pins
+	}
+}
+
 // WithAuthToken add auth token
 func WithAuthToken(authToken string) Option {
 	return func(opts *Client) {
@@ -291,11 +376,22 @@ func WithAuthToken(authToken string) Option {
 	}
 }
 
+// WithLogger option sets the logger of the client. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *Client) {
+		opts.logger = logger
+	}
+}
+
 // CreateDIDOpts create did opts
 type CreateDIDOpts struct {
 	publicKeys       []PublicKey
 	services         []docdid.Service
+	contexts         []string
+	alsoKnownAs      []string
 	sidetreeEndpoint string
+	ctx              context.Context
+	requestHandler   func(didSuffix string) error
 }
 
 // CreateDIDOption is a create DID option
@@ -315,9 +411,38 @@ func WithService(service *docdid.Service) CreateDIDOption {
 	}
 }
 
+// WithContext add JSON-LD contexts of document extensions
+func WithContext(contexts ...string) CreateDIDOption {
+	return func(opts *CreateDIDOpts) {
+		opts.contexts = append(opts.contexts, contexts...)
+	}
+}
+
+// WithAlsoKnownAs add URIs of identifiers equivalent to the DID
+func WithAlsoKnownAs(uris ...string) CreateDIDOption {
+	return func(opts *CreateDIDOpts) {
+		opts.alsoKnownAs = append(opts.alsoKnownAs, uris...)
+	}
+}
+
 // WithSidetreeEndpoint go directly to sidetree
 func WithSidetreeEndpoint(sidetreeEndpoint string) CreateDIDOption {
 	return func(opts *CreateDIDOpts) {
 		opts.sidetreeEndpoint = sidetreeEndpoint
 	}
 }
+
+// WithCreateContext sets the context of the create request, which is aborted when the context is done
+func WithCreateContext(ctx context.Context) CreateDIDOption {
+	return func(opts *CreateDIDOpts) {
+		opts.ctx = ctx
+	}
+}
+
+// WithCreateRequestHandler calls the handler with the suffix of the DID once the create request is built, before it's
+// sent, eg. to keep the operation keys of the DID before it can exist. An error of the handler aborts the creation.
+func WithCreateRequestHandler(handler func(didSuffix string) error) CreateDIDOption {
+	return func(opts *CreateDIDOpts) {
+		opts.requestHandler = handler
+	}
+}
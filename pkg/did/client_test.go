@@ -6,19 +6,26 @@ SPDX-License-Identifier: Apache-2.0
 package did
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 
 	mockdiscovery "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/discovery"
 	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
@@ -32,6 +39,61 @@ const (
 	updateKeyID   = "update"
 )
 
+// createServer serves sidetree create requests, capturing their delta
+func createServer(t *testing.T, delta *model.DeltaModel) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req := &model.CreateRequest{}
+		require.NoError(t, json.Unmarshal(body, req))
+		require.Equal(t, model.OperationTypeCreate, req.Operation)
+
+		deltaBytes, err := docutil.DecodeString(req.Delta)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(deltaBytes, delta))
+
+		docBytes, err := (&did.Doc{ID: "did1", Context: []string{did.Context}}).JSONBytes()
+		require.NoError(t, err)
+
+		_, err = w.Write(docBytes)
+		require.NoError(t, err)
+	}))
+}
+
+// createdPublicKeys returns the public keys added by the patches of a create delta
+func createdPublicKeys(t *testing.T, delta *model.DeltaModel) []map[string]interface{} {
+	var keys []map[string]interface{}
+
+	for _, p := range delta.Patches {
+		if p.GetAction() != patch.AddPublicKeys {
+			continue
+		}
+
+		b, err := json.Marshal(p.GetValue(patch.PublicKeys))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, &keys))
+	}
+
+	return keys
+}
+
+// operationKeys returns the options of the recovery and update keys of a create request
+func operationKeys(t *testing.T) []CreateDIDOption {
+	recoveryPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	updatePubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return []CreateDIDOption{
+		WithPublicKey(&PublicKey{ID: recoveryKeyID, Type: Ed25519VerificationKey2018, Encoding: PublicKeyEncodingJwk,
+			Value: recoveryPubKey, Recovery: true}),
+		WithPublicKey(&PublicKey{ID: updateKeyID, Type: Ed25519VerificationKey2018, Encoding: PublicKeyEncodingJwk,
+			Value: updatePubKey, Update: true}),
+	}
+}
+
 func TestClient_CreateDID(t *testing.T) {
 	t.Run("test domain is empty", func(t *testing.T) {
 		v := New()
@@ -154,6 +216,20 @@ func TestClient_CreateDID(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to parse public DID document")
 		require.Nil(t, doc)
+
+		// test canceled context
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		doc, err = v.CreateDID("testnet",
+			WithPublicKey(&PublicKey{ID: recoveryKeyID, Encoding: PublicKeyEncodingJwk,
+				Value: ed25519RecoveryPubKey, KeyType: Ed25519KeyType, Recovery: true}),
+			WithPublicKey(&PublicKey{ID: updateKeyID, Encoding: PublicKeyEncodingJwk,
+				Value: ed25519UpdatePubKey, KeyType: Ed25519KeyType, Update: true}),
+			WithCreateContext(ctx))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Nil(t, doc)
 	})
 
 	t.Run("test success", func(t *testing.T) {
@@ -208,6 +284,173 @@ func TestClient_CreateDID(t *testing.T) {
 		require.Equal(t, "did1", doc.ID)
 	})
 
+	t.Run("test JsonWebKey2020 key", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := createServer(t, delta)
+
+		defer serv.Close()
+
+		ecPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		key := &PublicKey{ID: "key1", Type: JSONWebKey2020, Encoding: PublicKeyEncodingJwk, KeyType: P256KeyType,
+			Value:   elliptic.Marshal(ecPrivKey.Curve, ecPrivKey.X, ecPrivKey.Y),
+			Purpose: []string{KeyPurposeGeneral}}
+
+		_, err = New().CreateDID("", append(operationKeys(t), WithPublicKey(key),
+			WithSidetreeEndpoint(serv.URL))...)
+		require.NoError(t, err)
+
+		// the JWK of the sidetree operation has no kid
+		keys := createdPublicKeys(t, delta)
+		require.Len(t, keys, 1)
+		require.Equal(t, JSONWebKey2020, keys[0]["type"])
+
+		jwk, ok := keys[0]["jwk"].(map[string]interface{})
+		require.True(t, ok)
+		require.Len(t, jwk, 4)
+		require.Equal(t, "EC", jwk["kty"])
+		require.Equal(t, "P-256", jwk["crv"])
+		require.NotContains(t, jwk, "kid")
+
+		// the kid is set in the aries doc
+		ariesDoc, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{*key}}, "did:trustbloc:testnet:EiAsuffix")
+		require.NoError(t, err)
+		require.Len(t, ariesDoc.PublicKey, 1)
+		require.Equal(t, "did:trustbloc:testnet:EiAsuffix#key1", ariesDoc.PublicKey[0].ID)
+		require.Equal(t, "key1", ariesDoc.PublicKey[0].JSONWebKey().KeyID)
+
+		docBytes, err := ariesDoc.JSONBytes()
+		require.NoError(t, err)
+		require.Contains(t, string(docBytes), `"kid":"key1"`)
+	})
+
+	t.Run("test create request handler", func(t *testing.T) {
+		var sentSuffix string
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			req := &model.CreateRequest{}
+			require.NoError(t, json.Unmarshal(body, req))
+
+			sentSuffix, err = docutil.CalculateUniqueSuffix(req.SuffixData, sha2_256)
+			require.NoError(t, err)
+
+			docBytes, err := (&did.Doc{ID: "did1", Context: []string{did.Context}}).JSONBytes()
+			require.NoError(t, err)
+
+			_, err = w.Write(docBytes)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		var handledSuffix string
+
+		_, err := New().CreateDID("", append(operationKeys(t), WithSidetreeEndpoint(serv.URL),
+			WithCreateRequestHandler(func(didSuffix string) error {
+				require.Empty(t, sentSuffix, "the handler is called before the request is sent")
+
+				handledSuffix = didSuffix
+
+				return nil
+			}))...)
+		require.NoError(t, err)
+		require.NotEmpty(t, handledSuffix)
+		require.Equal(t, sentSuffix, handledSuffix)
+
+		sentSuffix = ""
+
+		_, err = New().CreateDID("", append(operationKeys(t), WithSidetreeEndpoint(serv.URL),
+			WithCreateRequestHandler(func(string) error {
+				return errors.New("handler error")
+			}))...)
+		require.EqualError(t, err, "handler error")
+		require.Empty(t, sentSuffix)
+	})
+
+	t.Run("test key controller", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := createServer(t, delta)
+
+		defer serv.Close()
+
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		key := &PublicKey{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral},
+			Controller: "did:example:controller"}
+
+		_, err = New().CreateDID("", append(operationKeys(t), WithPublicKey(key),
+			WithSidetreeEndpoint(serv.URL))...)
+		require.NoError(t, err)
+
+		// sidetree only accepts the id, type, purpose and jwk of keys
+		keys := createdPublicKeys(t, delta)
+		require.Len(t, keys, 1)
+		require.Len(t, keys[0], 4)
+		require.NotContains(t, keys[0], "controller")
+
+		ariesDoc, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{*key}}, "did:trustbloc:testnet:EiAsuffix")
+		require.NoError(t, err)
+		require.Equal(t, "did:example:controller", ariesDoc.PublicKey[0].Controller)
+	})
+
+	t.Run("test extension properties", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := createServer(t, delta)
+
+		defer serv.Close()
+
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		key := &PublicKey{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral},
+			Properties: map[string]interface{}{"expires": "2030-01-01T00:00:00Z"}}
+		service := &did.Service{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com",
+			Properties: map[string]interface{}{"description": "agent"}}
+
+		_, err = New().CreateDID("", append(operationKeys(t), WithPublicKey(key), WithService(service),
+			WithSidetreeEndpoint(serv.URL))...)
+		require.NoError(t, err)
+
+		// the properties of keys aren't sent, sidetree only accepts their id, type, purpose and jwk
+		keys := createdPublicKeys(t, delta)
+		require.Len(t, keys, 1)
+		require.Len(t, keys[0], 4)
+		require.NotContains(t, keys[0], "expires")
+
+		// the properties of services are sent, and kept in aries docs
+		deltaBytes, err := json.Marshal(delta)
+		require.NoError(t, err)
+		require.Contains(t, string(deltaBytes), `"description":"agent"`)
+
+		ariesDoc, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{*key}, Service: []did.Service{*service}},
+			"did:trustbloc:testnet:EiAsuffix")
+		require.NoError(t, err)
+		require.Equal(t, service.Properties, ariesDoc.Service[0].Properties)
+	})
+
+	t.Run("test pinned certificates", func(t *testing.T) {
+		serv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.FailNow(t, "request sent to a host with a pinned certificate that doesn't match")
+		}))
+		defer serv.Close()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(serv.Certificate())
+
+		v := New(WithTLSConfig(&tls.Config{RootCAs: roots}), //nolint: gosec
+			WithPinnedCertificates(map[string][]string{"127.0.0.1": {"sha256/other"}}))
+
+		_, err := v.CreateDID("", append(operationKeys(t), WithSidetreeEndpoint(serv.URL))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match any pinned certificate")
+	})
+
 	t.Run("test create DID - invalid key type", func(t *testing.T) {
 		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			bytes, err := (&did.Doc{ID: "did1", Context: []string{did.Context}}).JSONBytes()
@@ -225,8 +468,9 @@ func TestClient_CreateDID(t *testing.T) {
 			}}
 
 		doc, err := v.CreateDID("testnet",
-			WithPublicKey(&PublicKey{ID: "#key1",
+			WithPublicKey(&PublicKey{ID: "key1",
 				Type:     JWSVerificationKey2020,
+				Purpose:  []string{KeyPurposeGeneral},
 				Encoding: PublicKeyEncodingJwk,
 				KeyType:  "InvalidKeyType",
 			}),
@@ -256,8 +500,9 @@ func TestClient_CreateDID(t *testing.T) {
 		require.NoError(t, err)
 
 		doc, err := v.CreateDID("testnet",
-			WithPublicKey(&PublicKey{ID: "#key1",
+			WithPublicKey(&PublicKey{ID: "key1",
 				Type:     JWSVerificationKey2020,
+				Purpose:  []string{KeyPurposeGeneral},
 				Encoding: PublicKeyEncodingJwk,
 				KeyType:  P256KeyType,
 				Value:    ed25519PubKey,
@@ -287,10 +532,12 @@ func TestClient_CreateDID(t *testing.T) {
 				return []*models.Endpoint{{URL: serv.URL}}, nil
 			}}
 
-		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "#key1",
-			Type: JWSVerificationKey2020, Encoding: "wrong", Value: pubKey, Recovery: true}),
-			WithPublicKey(&PublicKey{ID: "#key2",
-				Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "key1",
+			Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+			Encoding: "wrong", Value: pubKey, Recovery: true}),
+			WithPublicKey(&PublicKey{ID: "key2",
+				Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+				Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
 				Value: []byte("value")}))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to get recovery key")
@@ -316,10 +563,12 @@ func TestClient_CreateDID(t *testing.T) {
 				return []*models.Endpoint{{URL: serv.URL}}, nil
 			}}
 
-		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "#key1",
-			Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, Value: pubKey, KeyType: Ed25519KeyType}),
-			WithPublicKey(&PublicKey{ID: "#key2",
-				Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, Value: pubKey, KeyType: Ed25519KeyType}))
+		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "key1",
+			Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+			Encoding: PublicKeyEncodingJwk, Value: pubKey, KeyType: Ed25519KeyType}),
+			WithPublicKey(&PublicKey{ID: "key2",
+				Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+				Encoding: PublicKeyEncodingJwk, Value: pubKey, KeyType: Ed25519KeyType}))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "recovery key not found")
 		require.Nil(t, doc)
@@ -344,10 +593,12 @@ func TestClient_CreateDID(t *testing.T) {
 				return []*models.Endpoint{{URL: serv.URL}}, nil
 			}}
 
-		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "#key1",
-			Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, Value: pubKey, Recovery: true}),
-			WithPublicKey(&PublicKey{ID: "#key2",
-				Type: JWSVerificationKey2020, Encoding: "wrong", Value: []byte("wrongValue")}))
+		doc, err := v.CreateDID("testnet", WithPublicKey(&PublicKey{ID: "key1",
+			Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+			Encoding: PublicKeyEncodingJwk, Value: pubKey, Recovery: true}),
+			WithPublicKey(&PublicKey{ID: "key2",
+				Type: JWSVerificationKey2020, Purpose: []string{KeyPurposeGeneral},
+				Encoding: "wrong", Value: []byte("wrongValue")}))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "public key encoding not supported")
 		require.Nil(t, doc)
@@ -356,7 +607,8 @@ func TestClient_CreateDID(t *testing.T) {
 	t.Run("test opts", func(t *testing.T) {
 		// test WithTLSConfig
 		var opts []Option
-		opts = append(opts, WithTLSConfig(&tls.Config{ServerName: "test"}), WithAuthToken("tk1"))
+		opts = append(opts, WithTLSConfig(&tls.Config{ServerName: "test"}), WithAuthToken("tk1"),
+			WithPinnedCertificates(map[string][]string{"example.com": {"pin"}}))
 
 		c := &Client{}
 
@@ -367,6 +619,7 @@ func TestClient_CreateDID(t *testing.T) {
 
 		require.Equal(t, "test", c.tlsConfig.ServerName)
 		require.Equal(t, "Bearer tk1", c.authToken)
+		require.Equal(t, map[string][]string{"example.com": {"pin"}}, c.pins)
 
 		// test WithPublicKey
 		var createOpts []CreateDIDOption
@@ -395,6 +648,14 @@ func TestClient_CreateDID(t *testing.T) {
 		require.Equal(t, 1, len(createDIDOpts.services))
 		require.Equal(t, "serviceID", createDIDOpts.services[0].ID)
 		require.Equal(t, "sidetree", createDIDOpts.sidetreeEndpoint)
+
+		// test WithContext and WithAlsoKnownAs
+		createDIDOpts = &CreateDIDOpts{}
+		WithContext("https://example.com/context/v1")(createDIDOpts)
+		WithAlsoKnownAs("https://example.com/user", "did:example:123")(createDIDOpts)
+
+		require.Equal(t, []string{"https://example.com/context/v1"}, createDIDOpts.contexts)
+		require.Equal(t, []string{"https://example.com/user", "did:example:123"}, createDIDOpts.alsoKnownAs)
 	})
 }
 
@@ -418,7 +679,7 @@ func Test_unwrapPubKeyJWK(t *testing.T) {
 		require.Contains(t, string(key2.Value), "test value")
 	})
 
-	t.Run("error unsupported type", func(t *testing.T) {
+	t.Run("unwrap wrapped EC jwk", func(t *testing.T) {
 		key := PublicKey{Value: []byte(`{
           "kty":"EC",
           "crv":"P-256",
@@ -426,12 +687,47 @@ func Test_unwrapPubKeyJWK(t *testing.T) {
           "y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM",
           "use":"enc",
           "kid":"1"}`)}
+		key2, err := unwrapPubKeyJWK(key)
+		require.NoError(t, err)
+		require.Equal(t, P256KeyType, key2.KeyType)
+		require.Len(t, key2.Value, 65)
+	})
+
+	t.Run("error unsupported type", func(t *testing.T) {
+		key := PublicKey{Value: []byte(`{"kty":"oct","k":"c2VjcmV0","kid":"1"}`)}
 		_, err := unwrapPubKeyJWK(key)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unsupported PublicKey source key type")
 	})
 }
 
+func Test_unwrapPublicKeys(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("success - key IDs", func(t *testing.T) {
+		keys, err := unwrapPublicKeys([]PublicKey{
+			{ID: "key1", KeyType: Ed25519KeyType, Value: pubKey},
+			{KeyType: Ed25519KeyType, Value: pubKey},
+			{KeyType: Ed25519KeyType, Value: pubKey, Recovery: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		require.Equal(t, "key1", keys[0].ID)
+
+		thumbprint, err := keys[1].Thumbprint()
+		require.NoError(t, err)
+		require.Equal(t, thumbprint, keys[1].ID)
+		require.Empty(t, keys[2].ID)
+	})
+
+	t.Run("failure - key ID", func(t *testing.T) {
+		_, err := unwrapPublicKeys([]PublicKey{{KeyType: "unknown", Value: pubKey}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute public key ID: invalid key type: unknown")
+	})
+}
+
 func discoveryMock(endpoints []*models.Endpoint, err error) *mockdiscovery.MockDiscoveryService {
 	return &mockdiscovery.MockDiscoveryService{
 		GetEndpointsFunc: func(string) ([]*models.Endpoint, error) {
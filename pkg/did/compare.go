@@ -0,0 +1,280 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/jsoncanonicalizer"
+)
+
+// didDocSets are the DID doc properties whose values are unordered sets
+var didDocSets = []string{ // nolint: gochecknoglobals
+	"publicKey", "verificationMethod", "authentication", "assertionMethod", "keyAgreement",
+	"capabilityInvocation", "capabilityDelegation", "service",
+}
+
+// Difference is a property whose value differs between two DID docs. The path locates the property,
+// with set elements (keys, verification relationships and services) identified by their id, eg.
+// `publicKey[did:example:123#key1].publicKeyJwk.x`. A value is nil if the property is missing from that doc.
+type Difference struct {
+	Path   string
+	First  interface{}
+	Second interface{}
+}
+
+// String formats the difference as `path: first != second`
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Path, jsonString(d.First), jsonString(d.Second))
+}
+
+// Canonicalize canonicalizes the JSON of a DID doc using the JSON Canonicalization Scheme (RFC 8785), with
+// the unordered sets of the doc (keys, verification relationships and services) sorted, so that docs with the same
+// contents have the same canonical form
+func Canonicalize(docBytes []byte) ([]byte, error) {
+	docMap, err := decode(docBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range didDocSets {
+		set, ok := docMap[name].([]interface{})
+		if !ok {
+			continue
+		}
+
+		if err := sortSet(set); err != nil {
+			return nil, err
+		}
+	}
+
+	return jsoncanonicalizer.Canonicalize(docMap)
+}
+
+// Equal returns true if both DID docs have the same contents, regardless of the order of their sets
+func Equal(doc1, doc2 *docdid.Doc) (bool, error) {
+	diffs, err := Compare(doc1, doc2)
+	if err != nil {
+		return false, err
+	}
+
+	return len(diffs) == 0, nil
+}
+
+// Compare returns the differences between two DID docs, sorted by path. Sets are compared regardless of
+// the order of their elements.
+func Compare(doc1, doc2 *docdid.Doc) ([]Difference, error) {
+	m1, err := docMap(doc1)
+	if err != nil {
+		return nil, fmt.Errorf("first doc: %w", err)
+	}
+
+	m2, err := docMap(doc2)
+	if err != nil {
+		return nil, fmt.Errorf("second doc: %w", err)
+	}
+
+	for _, name := range didDocSets {
+		if err := setToMap(m1, name); err != nil {
+			return nil, fmt.Errorf("first doc: %w", err)
+		}
+
+		if err := setToMap(m2, name); err != nil {
+			return nil, fmt.Errorf("second doc: %w", err)
+		}
+	}
+
+	var diffs []Difference
+
+	if err := compareMaps("", m1, m2, &diffs); err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
+func docMap(doc *docdid.Doc) (map[string]interface{}, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(docBytes)
+}
+
+func decode(docBytes []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(docBytes))
+	decoder.UseNumber()
+
+	docMap := map[string]interface{}{}
+
+	if err := decoder.Decode(&docMap); err != nil {
+		return nil, err
+	}
+
+	return docMap, nil
+}
+
+// setToMap replaces a set of a doc by a map of its elements keyed by id, or by canonical form for elements without
+// an id (eg. references to keys in verification relationships)
+func setToMap(docMap map[string]interface{}, name string) error {
+	set, ok := docMap[name].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	elems := make(map[string]interface{}, len(set))
+
+	for _, elem := range set {
+		key, err := setElementKey(elem)
+		if err != nil {
+			return err
+		}
+
+		elems["["+key+"]"] = elem
+	}
+
+	docMap[name] = elems
+
+	return nil
+}
+
+func setElementKey(elem interface{}) (string, error) {
+	if m, ok := elem.(map[string]interface{}); ok {
+		if id, ok := m["id"].(string); ok {
+			return id, nil
+		}
+	}
+
+	if s, ok := elem.(string); ok {
+		return s, nil
+	}
+
+	canonical, err := jsoncanonicalizer.Canonicalize(elem)
+	if err != nil {
+		return "", err
+	}
+
+	return string(canonical), nil
+}
+
+func compareMaps(path string, m1, m2 map[string]interface{}, diffs *[]Difference) error {
+	keys := make(map[string]bool)
+
+	for k := range m1 {
+		keys[k] = true
+	}
+
+	for k := range m2 {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		if err := compareValues(childPath(path, k), m1[k], m2[k], diffs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func childPath(path, key string) string {
+	if path == "" || key[0] == '[' {
+		return path + key
+	}
+
+	return path + "." + key
+}
+
+func compareValues(path string, v1, v2 interface{}, diffs *[]Difference) error {
+	sub1, ok1 := v1.(map[string]interface{})
+	sub2, ok2 := v2.(map[string]interface{})
+
+	if ok1 && ok2 {
+		return compareMaps(path, sub1, sub2, diffs)
+	}
+
+	// a null property is the same as a missing one
+	if v1 == nil && v2 == nil {
+		return nil
+	}
+
+	if v1 != nil && v2 != nil {
+		c1, err := jsoncanonicalizer.Canonicalize(v1)
+		if err != nil {
+			return err
+		}
+
+		c2, err := jsoncanonicalizer.Canonicalize(v2)
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(c1, c2) {
+			return nil
+		}
+	}
+
+	*diffs = append(*diffs, Difference{Path: path, First: v1, Second: v2})
+
+	return nil
+}
+
+// sortSet sorts the elements of a set by their canonical form
+func sortSet(set []interface{}) error {
+	keys := make([]string, len(set))
+
+	for i, elem := range set {
+		canonical, err := jsoncanonicalizer.Canonicalize(elem)
+		if err != nil {
+			return err
+		}
+
+		keys[i] = string(canonical)
+	}
+
+	sort.Sort(&setSorter{set: set, keys: keys})
+
+	return nil
+}
+
+type setSorter struct {
+	set  []interface{}
+	keys []string
+}
+
+func (s *setSorter) Len() int           { return len(s.set) }
+func (s *setSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+
+func (s *setSorter) Swap(i, j int) {
+	s.set[i], s.set[j] = s.set[j], s.set[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func jsonString(v interface{}) string {
+	if v == nil {
+		return "<missing>"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
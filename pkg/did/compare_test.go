@@ -0,0 +1,123 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+)
+
+const compareDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:trustbloc:testnet:123",
+  "publicKey": [
+    {
+      "id": "did:trustbloc:testnet:123#key1",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:trustbloc:testnet:123",
+      "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+    },
+    {
+      "id": "did:trustbloc:testnet:123#key2",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:trustbloc:testnet:123",
+      "publicKeyBase58": "BxZ2VsJ6xAFmtGWD3ZNwgtZhcszZzGcD4ufBk4qbDLYT"
+    }
+  ],
+  "authentication": ["did:trustbloc:testnet:123#key1"],
+  "service": [
+    {"id": "did:trustbloc:testnet:123#hub", "type": "hub", "serviceEndpoint": "https://example.com/hub"}
+  ]
+}`
+
+// same contents, with the keys in another order
+const compareDocReordered = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:trustbloc:testnet:123",
+  "publicKey": [
+    {
+      "id": "did:trustbloc:testnet:123#key2",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:trustbloc:testnet:123",
+      "publicKeyBase58": "BxZ2VsJ6xAFmtGWD3ZNwgtZhcszZzGcD4ufBk4qbDLYT"
+    },
+    {
+      "id": "did:trustbloc:testnet:123#key1",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:trustbloc:testnet:123",
+      "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+    }
+  ],
+  "authentication": ["did:trustbloc:testnet:123#key1"],
+  "service": [
+    {"id": "did:trustbloc:testnet:123#hub", "type": "hub", "serviceEndpoint": "https://example.com/hub"}
+  ]
+}`
+
+func parseDoc(t *testing.T, doc string) *docdid.Doc {
+	parsed, err := docdid.ParseDocument([]byte(doc))
+	require.NoError(t, err)
+
+	return parsed
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("equal docs", func(t *testing.T) {
+		diffs, err := Compare(parseDoc(t, compareDoc), parseDoc(t, compareDocReordered))
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+
+		equal, err := Equal(parseDoc(t, compareDoc), parseDoc(t, compareDocReordered))
+		require.NoError(t, err)
+		require.True(t, equal)
+	})
+
+	t.Run("different docs", func(t *testing.T) {
+		doc1 := parseDoc(t, compareDoc)
+		doc2 := parseDoc(t, compareDocReordered)
+
+		doc2.Service[0].ServiceEndpoint = "https://other.example.com/hub"
+		doc2.PublicKey = doc2.PublicKey[1:]
+
+		diffs, err := Compare(doc1, doc2)
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+
+		require.Equal(t, "publicKey[did:trustbloc:testnet:123#key2]", diffs[0].Path)
+		require.NotNil(t, diffs[0].First)
+		require.Nil(t, diffs[0].Second)
+		require.Contains(t, diffs[0].String(), "publicKey[did:trustbloc:testnet:123#key2]: {")
+		require.Contains(t, diffs[0].String(), "!= <missing>")
+
+		require.Equal(t, "service[did:trustbloc:testnet:123#hub].serviceEndpoint", diffs[1].Path)
+		require.Equal(t, `service[did:trustbloc:testnet:123#hub].serviceEndpoint: `+
+			`"https://example.com/hub" != "https://other.example.com/hub"`, diffs[1].String())
+
+		equal, err := Equal(doc1, doc2)
+		require.NoError(t, err)
+		require.False(t, equal)
+	})
+}
+
+func TestCanonicalize(t *testing.T) {
+	doc1, err := parseDoc(t, compareDoc).JSONBytes()
+	require.NoError(t, err)
+
+	doc2, err := parseDoc(t, compareDocReordered).JSONBytes()
+	require.NoError(t, err)
+
+	canonical1, err := Canonicalize(doc1)
+	require.NoError(t, err)
+
+	canonical2, err := Canonicalize(doc2)
+	require.NoError(t, err)
+	require.Equal(t, canonical1, canonical2)
+
+	_, err = Canonicalize([]byte("{"))
+	require.Error(t, err)
+}
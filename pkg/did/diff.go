@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+)
+
+// DiffDocs returns the minimal set of sidetree patches updating the old doc into the new one: the public keys and
+// services missing from the new doc are removed, and the new or changed ones are added, sidetree replacing the keys
+// and services added with an existing id. Changes of the contexts and alsoKnownAs are patched with a JSON patch.
+// The patches are sent in one update with WithPatch.
+func DiffDocs(oldDoc, newDoc *Doc) ([]patch.Patch, error) {
+	if err := ValidateDocument(newDoc); err != nil {
+		return nil, fmt.Errorf("invalid new doc: %w", err)
+	}
+
+	patches, err := diffPublicKeysPatches(oldDoc.PublicKey, newDoc.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	servicePatches, err := diffServicesPatches(oldDoc.Service, newDoc.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	patches = append(patches, servicePatches...)
+
+	ops := diffJSONOps(oldDoc, newDoc)
+	if len(ops) == 0 {
+		return patches, nil
+	}
+
+	p, err := jsonPatch(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(patches, p), nil
+}
+
+func diffPublicKeysPatches(oldKeys, newKeys []PublicKey) ([]patch.Patch, error) {
+	oldByID := make(map[string]PublicKey)
+	for _, pk := range oldKeys {
+		oldByID[pk.ID] = pk
+	}
+
+	var added []PublicKey
+
+	for _, pk := range newKeys {
+		old, ok := oldByID[pk.ID]
+		if !ok || !reflect.DeepEqual(old, pk) {
+			added = append(added, pk)
+		}
+
+		delete(oldByID, pk.ID)
+	}
+
+	var removed []string
+
+	for _, pk := range oldKeys {
+		if _, ok := oldByID[pk.ID]; ok {
+			removed = append(removed, pk.ID)
+		}
+	}
+
+	var patches []patch.Patch
+
+	if len(removed) > 0 {
+		p, err := removeIDsPatch(patch.NewRemovePublicKeysPatch, removed)
+		if err != nil {
+			return nil, err
+		}
+
+		patches = append(patches, p)
+	}
+
+	if len(added) > 0 {
+		p, err := addPublicKeysPatch(added)
+		if err != nil {
+			return nil, err
+		}
+
+		patches = append(patches, p)
+	}
+
+	return patches, nil
+}
+
+func diffServicesPatches(oldServices, newServices []docdid.Service) ([]patch.Patch, error) {
+	oldByID := make(map[string]docdid.Service)
+	for _, s := range oldServices {
+		oldByID[s.ID] = s
+	}
+
+	var added []docdid.Service
+
+	for _, s := range newServices {
+		old, ok := oldByID[s.ID]
+		if !ok || !reflect.DeepEqual(old, s) {
+			added = append(added, s)
+		}
+
+		delete(oldByID, s.ID)
+	}
+
+	var removed []string
+
+	for _, s := range oldServices {
+		if _, ok := oldByID[s.ID]; ok {
+			removed = append(removed, s.ID)
+		}
+	}
+
+	var patches []patch.Patch
+
+	if len(removed) > 0 {
+		p, err := removeIDsPatch(patch.NewRemoveServiceEndpointsPatch, removed)
+		if err != nil {
+			return nil, err
+		}
+
+		patches = append(patches, p)
+	}
+
+	if len(added) > 0 {
+		p, err := addServicesPatch(added)
+		if err != nil {
+			return nil, err
+		}
+
+		patches = append(patches, p)
+	}
+
+	return patches, nil
+}
+
+// diffJSONOps returns the JSON patch operations of the changes of the contexts and alsoKnownAs
+func diffJSONOps(oldDoc, newDoc *Doc) []map[string]interface{} {
+	var ops []map[string]interface{}
+
+	for _, property := range []struct {
+		path     string
+		old, new []string
+	}{
+		{"/@context", oldDoc.Context, newDoc.Context},
+		{"/alsoKnownAs", oldDoc.AlsoKnownAs, newDoc.AlsoKnownAs},
+	} {
+		switch {
+		case len(property.old) == 0 && len(property.new) == 0, reflect.DeepEqual(property.old, property.new):
+		case len(property.new) == 0:
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": property.path})
+		default:
+			// adding an existing member replaces it
+			ops = append(ops, map[string]interface{}{"op": "add", "path": property.path, "value": property.new})
+		}
+	}
+
+	return ops
+}
+
+func jsonPatch(ops []map[string]interface{}) (patch.Patch, error) {
+	rawOps, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.NewJSONPatch(string(rawOps))
+}
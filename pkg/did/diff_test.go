@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+)
+
+func TestDiffDocs(t *testing.T) {
+	publicKey := func(id string) PublicKey {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		return PublicKey{ID: id, Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pubKey, Purpose: []string{KeyPurposeGeneral}}
+	}
+
+	service := func(id, endpoint string) docdid.Service {
+		return docdid.Service{ID: id, Type: "did-communication", ServiceEndpoint: endpoint}
+	}
+
+	actions := func(patches []patch.Patch) []string {
+		var a []string
+		for _, p := range patches {
+			a = append(a, fmt.Sprint(p[patch.ActionKey]))
+		}
+
+		return a
+	}
+
+	key1, key2, key3 := publicKey("key1"), publicKey("key2"), publicKey("key3")
+	oldDoc := &Doc{PublicKey: []PublicKey{key1, key2}, Service: []docdid.Service{
+		service("agent", "https://agent.example.com"), service("hub", "https://hub.example.com")}}
+
+	t.Run("success - no changes", func(t *testing.T) {
+		patches, err := DiffDocs(oldDoc, &Doc{PublicKey: []PublicKey{key2, key1}, Service: oldDoc.Service})
+		require.NoError(t, err)
+		require.Empty(t, patches)
+	})
+
+	t.Run("success - public keys", func(t *testing.T) {
+		changed := key2
+		changed.Purpose = []string{KeyPurposeAuth}
+
+		patches, err := DiffDocs(oldDoc, &Doc{PublicKey: []PublicKey{changed, key3}, Service: oldDoc.Service})
+		require.NoError(t, err)
+		require.Equal(t, []string{"remove-public-keys", "add-public-keys"}, actions(patches))
+		require.Equal(t, []interface{}{"key1"}, patches[0][patch.PublicKeys])
+
+		added, ok := patches[1][patch.PublicKeys].([]interface{})
+		require.True(t, ok)
+		require.Len(t, added, 2)
+	})
+
+	t.Run("success - services", func(t *testing.T) {
+		patches, err := DiffDocs(oldDoc, &Doc{PublicKey: oldDoc.PublicKey,
+			Service: []docdid.Service{service("hub", "https://hub2.example.com")}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"remove-service-endpoints", "add-service-endpoints"}, actions(patches))
+		require.Equal(t, []interface{}{"agent"}, patches[0][patch.ServiceEndpointIdsKey])
+	})
+
+	t.Run("success - context and alsoKnownAs", func(t *testing.T) {
+		old := &Doc{AlsoKnownAs: []string{"https://example.com/alice"}}
+
+		patches, err := DiffDocs(old, &Doc{Context: []string{"https://w3id.org/security/v2"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ietf-json-patch"}, actions(patches))
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"op": "add", "path": "/@context",
+				"value": []interface{}{"https://w3id.org/security/v2"}},
+			map[string]interface{}{"op": "remove", "path": "/alsoKnownAs"},
+		}, patches[0][patch.PatchesKey])
+	})
+
+	t.Run("success - everything", func(t *testing.T) {
+		patches, err := DiffDocs(&Doc{}, &Doc{PublicKey: oldDoc.PublicKey, Service: oldDoc.Service,
+			AlsoKnownAs: []string{"https://example.com/alice"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"add-public-keys", "add-service-endpoints", "ietf-json-patch"}, actions(patches))
+
+		patches, err = DiffDocs(oldDoc, &Doc{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"remove-public-keys", "remove-service-endpoints"}, actions(patches))
+	})
+
+	t.Run("failure - invalid new doc", func(t *testing.T) {
+		_, err := DiffDocs(oldDoc, &Doc{Service: []docdid.Service{{ID: "agent"}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid new doc: invalid document: service[0]: service type is missing")
+	})
+}
@@ -9,13 +9,18 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
+	"github.com/btcsuite/btcd/btcec"
 	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/square/go-jose/v3"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/jsoncanonicalizer"
 )
 
 const (
@@ -28,24 +33,31 @@ const (
 	jsonldPriority      = "priority"
 
 	jsonldPublicKeyjwk = "jwk"
+	jsonldController   = "controller"
 
 	// PublicKeyEncodingJwk define jwk encoding type
 	PublicKeyEncodingJwk = "Jwk"
 
-	// KeyPurposeAuth defines key purpose as authentication key
+	// KeyPurposeAuth defines key purpose as authentication key, in the authentication relationship
 	KeyPurposeAuth = "auth"
-	// KeyPurposeAssertion defines key purpose as assertion key
+	// KeyPurposeAssertion defines key purpose as assertion key, in the assertionMethod relationship
 	KeyPurposeAssertion = "assertion"
-	// KeyPurposeDelegation defines key purpose as delegation key
+	// KeyPurposeDelegation defines key purpose as delegation key, in the capabilityDelegation relationship
 	KeyPurposeDelegation = "delegation"
-	// KeyPurposeInvocation defines key purpose as invocation key
+	// KeyPurposeInvocation defines key purpose as invocation key, in the capabilityInvocation relationship
 	KeyPurposeInvocation = "invocation"
-	// KeyPurposeGeneral defines key purpose as general key
+	// KeyPurposeAgreement defines key purpose as key agreement key, in the keyAgreement relationship
+	KeyPurposeAgreement = "agreement"
+	// KeyPurposeGeneral defines key purpose as general key, listed in the public keys of the document and
+	// referenced by the relationships of its other purposes
 	KeyPurposeGeneral = "general"
 
 	// JWSVerificationKey2020 defines key type signature
 	JWSVerificationKey2020 = "JwsVerificationKey2020"
 
+	// JSONWebKey2020 defines key type signature, the kid of its JWK in aries docs is the key ID
+	JSONWebKey2020 = "JsonWebKey2020"
+
 	// Ed25519VerificationKey2018 define key type signature
 	Ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
 
@@ -54,21 +66,72 @@ const (
 
 	// P256KeyType EC P-256 key type
 	P256KeyType = "P256"
+
+	// P384KeyType EC P-384 key type
+	P384KeyType = "P384"
+
+	// P521KeyType EC P-521 key type
+	P521KeyType = "P521"
+
+	// Secp256k1KeyType EC secp256k1 key type
+	Secp256k1KeyType = "Secp256k1"
+
+	// Bls12381G2KeyType BLS12-381 G2 key type, used to verify BBS+ signatures
+	Bls12381G2KeyType = "Bls12381G2"
+
+	// Bls12381G2Key2020 defines key type signature for BBS+ signatures. Sidetree nodes don't accept it yet, so
+	// ValidateDocument rejects documents with such keys; it's supported to convert keys of resolved documents.
+	Bls12381G2Key2020 = "Bls12381G2Key2020"
+
+	// X25519KeyType X25519 key type, used for key agreement
+	X25519KeyType = "X25519"
+
+	// bls12381G2PublicKeySize is the size of a compressed BLS12-381 G2 point
+	bls12381G2PublicKeySize = 96
+
+	// x25519PublicKeySize is the size of an X25519 public key
+	x25519PublicKeySize = 32
+)
+
+// ecCurves are the curves of the EC key types, whose values are uncompressed points
+var ecCurves = map[string]elliptic.Curve{ // nolint: gochecknoglobals
+	P256KeyType:      elliptic.P256(),
+	P384KeyType:      elliptic.P384(),
+	P521KeyType:      elliptic.P521(),
+	Secp256k1KeyType: btcec.S256(),
+}
+
+// the standard members of public keys and services, which aren't parsed as extension properties
+var (
+	publicKeyMembers = []string{ // nolint: gochecknoglobals
+		jsonldID, jsonldType, jsonldUsage, jsonldController, jsonldPublicKeyjwk, jsonldPublicKeyMultibase,
+	}
+	serviceMembers = []string{ // nolint: gochecknoglobals
+		jsonldID, jsonldType, jsonldServicePoint, jsonldRecipientKeys, jsonldRoutingKeys, jsonldPriority,
+	}
 )
 
 type rawDoc struct {
-	PublicKey []map[string]interface{} `json:"publicKey,omitempty"`
-	Service   []map[string]interface{} `json:"service,omitempty"`
+	Context     []string                 `json:"@context,omitempty"`
+	PublicKey   []map[string]interface{} `json:"publicKey,omitempty"`
+	Service     []map[string]interface{} `json:"service,omitempty"`
+	AlsoKnownAs []string                 `json:"alsoKnownAs,omitempty"`
 }
 
 // Doc DID Document definition
 type Doc struct {
+	// Context are the JSON-LD contexts of the document extensions, in addition to the DID context
+	Context   []string
 	PublicKey []PublicKey
 	Service   []docdid.Service
+	// AlsoKnownAs are the URIs of identifiers equivalent to the DID
+	AlsoKnownAs []string
 }
 
 // PublicKey DID doc public key.
 type PublicKey struct {
+	// ID is the fragment of the key ID. When empty, the keys created, added or recovered with get the RFC 7638
+	// thumbprint of their JWK as ID.
 	ID       string
 	Type     string
 	Encoding string
@@ -77,7 +140,19 @@ type PublicKey struct {
 	Recovery bool
 	Update   bool
 
+	// Controller is the DID controlling the key, when it isn't the DID of the document. Sidetree operations don't
+	// carry it: it's set on the key of the aries doc, see ToAriesDoc.
+	Controller string
+
 	Value []byte
+
+	// JWK is the public key as JWK, eg. for keys held in an HSM or KMS exporting their public JWK. When set, the
+	// JWK is used as is instead of the key type and value, and the key must be JWK encoded.
+	JWK *jws.JWK
+
+	// Properties are the extension properties of the key, eg. parsed with ParseDoc. Sidetree operations don't carry
+	// them, as sidetree only accepts the standard members of keys, and aries DID docs don't keep them.
+	Properties map[string]interface{}
 }
 
 // JSONBytes converts document to json bytes
@@ -88,8 +163,10 @@ func (doc *Doc) JSONBytes() ([]byte, error) {
 	}
 
 	raw := &rawDoc{
-		PublicKey: publicKeys,
-		Service:   populateRawServices(doc.Service),
+		Context:     doc.Context,
+		PublicKey:   publicKeys,
+		Service:     populateRawServices(doc.Service),
+		AlsoKnownAs: doc.AlsoKnownAs,
 	}
 
 	byteDoc, err := json.Marshal(raw)
@@ -100,14 +177,47 @@ func (doc *Doc) JSONBytes() ([]byte, error) {
 	return byteDoc, nil
 }
 
-// GetValueFromJWK Populate the PublicKey contents from a JSON Web Key
+// CanonicalJSONBytes converts the document to its canonical JSON (RFC 8785 JSON Canonicalization Scheme), so the
+// same doc always yields identical bytes, eg. for hash-based comparisons
+func (doc *Doc) CanonicalJSONBytes() ([]byte, error) {
+	byteDoc, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := jsoncanonicalizer.Transform(byteDoc)
+	if err != nil {
+		return nil, fmt.Errorf("JSON canonicalization of document failed: %w", err)
+	}
+
+	return canonical, nil
+}
+
+// GetValueFromJWK Populate the PublicKey contents and key type from a JSON Web Key: an Ed25519 key, or an EC key of
+// the P-256, P-384, P-521 or secp256k1 curves
 func (pk *PublicKey) GetValueFromJWK(jwk *jose.JSONWebKey) error {
-	if edKey, ok := jwk.Key.(ed25519.PublicKey); ok {
-		pk.Value = edKey
-		return nil
+	return pk.setValue(jwk.Key)
+}
+
+// setValue sets the contents and key type of the PublicKey from an Ed25519 or EC public key
+func (pk *PublicKey) setValue(publicKey interface{}) error {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		pk.KeyType = Ed25519KeyType
+		pk.Value = key
+	case *ecdsa.PublicKey:
+		keyType := ecKeyType(key.Curve)
+		if keyType == "" {
+			return fmt.Errorf("unsupported PublicKey source curve %s", key.Curve.Params().Name)
+		}
+
+		pk.KeyType = keyType
+		pk.Value = elliptic.Marshal(key.Curve, key.X, key.Y)
+	default:
+		return fmt.Errorf("unsupported PublicKey source key type")
 	}
 
-	return fmt.Errorf("unsupported PublicKey source key type")
+	return nil
 }
 
 func populateRawPublicKeys(pks []PublicKey) ([]map[string]interface{}, error) {
@@ -135,28 +245,19 @@ func populateRawPublicKey(pk *PublicKey) (map[string]interface{}, error) {
 
 	switch pk.Encoding {
 	case PublicKeyEncodingJwk:
-		var jwk *jws.JWK
-
-		var err error
-
-		switch pk.KeyType {
-		case Ed25519KeyType:
-			jwk, err = pubkey.GetPublicKeyJWK(ed25519.PublicKey(pk.Value))
-			if err != nil {
-				return nil, err
-			}
-		case P256KeyType:
-			x, y := elliptic.Unmarshal(elliptic.P256(), pk.Value)
-
-			jwk, err = pubkey.GetPublicKeyJWK(&ecdsa.PublicKey{X: x, Y: y, Curve: elliptic.P256()})
-			if err != nil {
-				return nil, err
-			}
-		default:
-			return nil, fmt.Errorf("invalid key type: %s", pk.KeyType)
+		jwk, err := publicKeyJWK(pk)
+		if err != nil {
+			return nil, err
 		}
 
 		rawPK[jsonldPublicKeyjwk] = jwk
+	case PublicKeyEncodingMultibase:
+		key, err := multibaseKey(pk)
+		if err != nil {
+			return nil, err
+		}
+
+		rawPK[jsonldPublicKeyMultibase] = key
 	default:
 		return nil, fmt.Errorf("public key encoding not supported: %s", pk.Encoding)
 	}
@@ -164,6 +265,85 @@ func populateRawPublicKey(pk *PublicKey) (map[string]interface{}, error) {
 	return rawPK, nil
 }
 
+// Thumbprint returns the RFC 7638 thumbprint of the JWK of the public key, base64url encoded
+func (pk *PublicKey) Thumbprint() (string, error) {
+	jwk, err := publicKeyJWK(pk)
+	if err != nil {
+		return "", err
+	}
+
+	// the required members of the JWK, which encoding/json marshals in lexicographic order without whitespace
+	members := map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	if jwk.Y != "" {
+		members["y"] = jwk.Y
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	thumbprint := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:]), nil
+}
+
+// publicKeyJWK returns the JWK of a public key, according to its key type unless the JWK is set
+func publicKeyJWK(pk *PublicKey) (*jws.JWK, error) {
+	if pk.JWK != nil {
+		return pk.JWK, nil
+	}
+
+	switch pk.KeyType {
+	case Ed25519KeyType:
+		return pubkey.GetPublicKeyJWK(ed25519.PublicKey(pk.Value))
+	case P256KeyType, P384KeyType, P521KeyType, Secp256k1KeyType:
+		curve := ecCurves[pk.KeyType]
+		x, y := elliptic.Unmarshal(curve, pk.Value)
+
+		return pubkey.GetPublicKeyJWK(&ecdsa.PublicKey{X: x, Y: y, Curve: curve})
+	case Bls12381G2KeyType:
+		return bytesJWK(pk, "BLS12-381 G2", "EC", "BLS12381_G2", bls12381G2PublicKeySize)
+	case X25519KeyType:
+		return bytesJWK(pk, "X25519", "OKP", "X25519", x25519PublicKeySize)
+	default:
+		return nil, fmt.Errorf("invalid key type: %s", pk.KeyType)
+	}
+}
+
+// ecPublicKey returns the EC public key of a P256, P384, P521 or Secp256k1 key
+func ecPublicKey(pk *PublicKey) (*ecdsa.PublicKey, error) {
+	curve := ecCurves[pk.KeyType]
+
+	x, y := elliptic.Unmarshal(curve, pk.Value)
+	if x == nil {
+		return nil, fmt.Errorf("public key %s: invalid %s point", pk.ID, curve.Params().Name)
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// bytesJWK returns the JWK of a BLS12-381 G2 or X25519 key, which sidetree-core can't build: its x member is the
+// key value, of the given size
+func bytesJWK(pk *PublicKey, name, kty, crv string, size int) (*jws.JWK, error) {
+	if len(pk.Value) != size {
+		return nil, fmt.Errorf("public key %s: invalid %s key size %d", pk.ID, name, len(pk.Value))
+	}
+
+	return &jws.JWK{Kty: kty, Crv: crv, X: base64.RawURLEncoding.EncodeToString(pk.Value)}, nil
+}
+
+// ecKeyType returns the key type of an EC curve, or an empty string if the curve isn't supported
+func ecKeyType(curve elliptic.Curve) string {
+	for keyType, c := range ecCurves {
+		if c == curve {
+			return keyType
+		}
+	}
+
+	return ""
+}
+
 func populateRawServices(services []docdid.Service) []map[string]interface{} {
 	var rawServices []map[string]interface{}
 
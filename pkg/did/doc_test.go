@@ -6,10 +6,21 @@ SPDX-License-Identifier: Apache-2.0
 package did
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
 
 func TestPublicKey_GetValueFromJWK(t *testing.T) {
@@ -31,7 +42,22 @@ func TestPublicKey_GetValueFromJWK(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("failure - unsupported key type", func(t *testing.T) {
+	t.Run("success - EC values", func(t *testing.T) {
+		for keyType, curve := range map[string]elliptic.Curve{P256KeyType: elliptic.P256(),
+			P384KeyType: elliptic.P384(), P521KeyType: elliptic.P521(), Secp256k1KeyType: btcec.S256()} {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			pk := PublicKey{}
+
+			err = pk.GetValueFromJWK(&jose.JSONWebKey{Key: &priv.PublicKey})
+			require.NoError(t, err)
+			require.Equal(t, keyType, pk.KeyType)
+			require.Equal(t, elliptic.Marshal(curve, priv.X, priv.Y), pk.Value)
+		}
+	})
+
+	t.Run("success - EC value of parsed JWK", func(t *testing.T) {
 		keyJSON := `{
 	"kty":"EC",
 	"crv":"P-256",
@@ -49,7 +75,380 @@ func TestPublicKey_GetValueFromJWK(t *testing.T) {
 		pk := PublicKey{}
 
 		err = pk.GetValueFromJWK(&jwk)
+		require.NoError(t, err)
+		require.Equal(t, P256KeyType, pk.KeyType)
+
+		// the value is serialized back to the same JWK
+		pk.Encoding = PublicKeyEncodingJwk
+
+		jwkBytes, err := publicKeyJWK(&pk)
+		require.NoError(t, err)
+		require.Equal(t, "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4", jwkBytes.X)
+		require.Equal(t, "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM", jwkBytes.Y)
+	})
+
+	t.Run("failure - unsupported curve", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		require.NoError(t, err)
+
+		err = (&PublicKey{}).GetValueFromJWK(&jose.JSONWebKey{Key: &priv.PublicKey})
+		require.EqualError(t, err, "unsupported PublicKey source curve P-224")
+	})
+
+	t.Run("failure - unsupported key type", func(t *testing.T) {
+		err := (&PublicKey{}).GetValueFromJWK(&jose.JSONWebKey{Key: []byte("secret")})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unsupported")
 	})
 }
+
+func TestDoc_JSONBytes(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	doc := &Doc{PublicKey: []PublicKey{
+		{ID: "#key1", Type: JSONWebKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: pub,
+			Purpose: []string{KeyPurposeGeneral}},
+		{ID: "key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+			Value: pub, Purpose: []string{KeyPurposeGeneral}},
+	}}
+
+	bytes, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	var raw struct {
+		PublicKey []struct {
+			ID   string                 `json:"id"`
+			Type string                 `json:"type"`
+			JWK  map[string]interface{} `json:"jwk"`
+		} `json:"publicKey"`
+	}
+
+	require.NoError(t, json.Unmarshal(bytes, &raw))
+	require.Len(t, raw.PublicKey, 2)
+
+	// the kid is only set in aries docs, the JWKs of sidetree operations keep their key members
+	require.Equal(t, JSONWebKey2020, raw.PublicKey[0].Type)
+	require.NotContains(t, raw.PublicKey[0].JWK, "kid")
+	require.Equal(t, "Ed25519", raw.PublicKey[0].JWK["crv"])
+
+	require.Equal(t, JWSVerificationKey2020, raw.PublicKey[1].Type)
+	require.NotContains(t, raw.PublicKey[1].JWK, "kid")
+
+	t.Run("context and alsoKnownAs", func(t *testing.T) {
+		doc := &Doc{Context: []string{"https://example.com/context/v1"},
+			AlsoKnownAs: []string{"https://example.com/user", "did:example:123"}}
+
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"@context":["https://example.com/context/v1"],
+			"alsoKnownAs":["https://example.com/user","did:example:123"]}`, string(bytes))
+	})
+
+	t.Run("controller", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral},
+			Controller: "did:example:controller"}}}
+
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []map[string]interface{} `json:"publicKey"`
+		}
+
+		// the controller isn't part of the sidetree payload
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.NotContains(t, raw.PublicKey[0], "controller")
+	})
+
+	t.Run("extension properties", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral},
+			Properties: map[string]interface{}{"expires": "2030-01-01T00:00:00Z", "id": "key2", "controller": "x"}}}}
+
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []map[string]interface{} `json:"publicKey"`
+		}
+
+		// the extension properties of keys aren't part of the sidetree payload
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.Len(t, raw.PublicKey[0], 4)
+		require.Equal(t, "key1", raw.PublicKey[0]["id"])
+		require.NotContains(t, raw.PublicKey[0], "expires")
+	})
+}
+
+func TestDoc_CanonicalJSONBytes(t *testing.T) {
+	doc := &Doc{AlsoKnownAs: []string{"https://example.com/user"}, Service: []docdid.Service{{ID: "agent",
+		Type: "did-communication", ServiceEndpoint: "https://agent.example.com?a=1&b=2",
+		Properties: map[string]interface{}{"weight": 1.5}}}}
+
+	canonical, err := doc.CanonicalJSONBytes()
+	require.NoError(t, err)
+	require.Equal(t, `{"alsoKnownAs":["https://example.com/user"],`+
+		`"service":[{"endpoint":"https://agent.example.com?a=1&b=2","id":"agent","priority":0,`+
+		`"recipientKeys":null,"routingKeys":null,"type":"did-communication","weight":1.5}]}`, string(canonical))
+
+	for i := 0; i < 10; i++ {
+		again, err := doc.CanonicalJSONBytes()
+		require.NoError(t, err)
+		require.Equal(t, canonical, again)
+	}
+
+	_, err = (&Doc{PublicKey: []PublicKey{{ID: "key1", Encoding: "unknown"}}}).CanonicalJSONBytes()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "public key encoding not supported")
+}
+
+func TestDoc_JSONBytes_ECKeys(t *testing.T) {
+	for keyType, curve := range map[string]elliptic.Curve{
+		P256KeyType: elliptic.P256(), P384KeyType: elliptic.P384(), P521KeyType: elliptic.P521(),
+	} {
+		keyType, curve := keyType, curve
+
+		t.Run(keyType, func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020,
+				Encoding: PublicKeyEncodingJwk, KeyType: keyType, Value: elliptic.Marshal(curve, priv.X, priv.Y),
+				Purpose: []string{KeyPurposeGeneral}}}}
+
+			bytes, err := doc.JSONBytes()
+			require.NoError(t, err)
+
+			var raw struct {
+				PublicKey []struct {
+					JWK map[string]interface{} `json:"jwk"`
+				} `json:"publicKey"`
+			}
+
+			require.NoError(t, json.Unmarshal(bytes, &raw))
+			require.Len(t, raw.PublicKey, 1)
+			require.Equal(t, "EC", raw.PublicKey[0].JWK["kty"])
+			require.Equal(t, curve.Params().Name, raw.PublicKey[0].JWK["crv"])
+		})
+	}
+
+	t.Run("failure - invalid point", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: P384KeyType, Value: []byte("invalid"), Purpose: []string{KeyPurposeGeneral}}}}
+
+		_, err := doc.JSONBytes()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid EC key")
+	})
+}
+
+func TestDoc_JSONBytes_Bls12381G2Key(t *testing.T) {
+	pub := make([]byte, 96)
+	_, err := rand.Read(pub)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: Bls12381G2Key2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Bls12381G2KeyType, Value: pub, Purpose: []string{KeyPurposeAssertion}}}}
+
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []struct {
+				Type string                 `json:"type"`
+				JWK  map[string]interface{} `json:"jwk"`
+			} `json:"publicKey"`
+		}
+
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.Len(t, raw.PublicKey, 1)
+		require.Equal(t, Bls12381G2Key2020, raw.PublicKey[0].Type)
+		require.Equal(t, "EC", raw.PublicKey[0].JWK["kty"])
+		require.Equal(t, "BLS12381_G2", raw.PublicKey[0].JWK["crv"])
+		require.Equal(t, base64.RawURLEncoding.EncodeToString(pub), raw.PublicKey[0].JWK["x"])
+	})
+
+	t.Run("failure - key type rejected by sidetree", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: Bls12381G2Key2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Bls12381G2KeyType, Value: pub, Purpose: []string{KeyPurposeAssertion}}}}
+
+		err := ValidateDocument(doc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "publicKey[0]: invalid key type: Bls12381G2Key2020")
+	})
+
+	t.Run("failure - invalid key size", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: Bls12381G2Key2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Bls12381G2KeyType, Value: pub[:48], Purpose: []string{KeyPurposeAssertion}}}}
+
+		_, err := doc.JSONBytes()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key key1: invalid BLS12-381 G2 key size 48")
+	})
+}
+
+func TestDoc_JSONBytes_X25519Key(t *testing.T) {
+	pub := make([]byte, 32)
+	_, err := rand.Read(pub)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: X25519KeyAgreementKey2019, Encoding: PublicKeyEncodingJwk,
+			KeyType: X25519KeyType, Value: pub, Purpose: []string{KeyPurposeAgreement}}}}
+
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []struct {
+				Purpose []string               `json:"purpose"`
+				JWK     map[string]interface{} `json:"jwk"`
+			} `json:"publicKey"`
+		}
+
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.Len(t, raw.PublicKey, 1)
+		require.Equal(t, []string{KeyPurposeAgreement}, raw.PublicKey[0].Purpose)
+		require.Equal(t, "OKP", raw.PublicKey[0].JWK["kty"])
+		require.Equal(t, "X25519", raw.PublicKey[0].JWK["crv"])
+		require.Equal(t, base64.RawURLEncoding.EncodeToString(pub), raw.PublicKey[0].JWK["x"])
+		require.NoError(t, ValidateDocument(doc))
+	})
+
+	t.Run("failure - invalid key size", func(t *testing.T) {
+		doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: X25519KeyAgreementKey2019, Encoding: PublicKeyEncodingJwk,
+			KeyType: X25519KeyType, Value: pub[:16], Purpose: []string{KeyPurposeAgreement}}}}
+
+		_, err := doc.JSONBytes()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key key1: invalid X25519 key size 16")
+	})
+}
+
+func TestPublicKey_Thumbprint(t *testing.T) {
+	t.Run("success - Ed25519 key", func(t *testing.T) {
+		// the example of RFC 8037 appendix A.3
+		x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+		require.NoError(t, err)
+
+		pk := &PublicKey{KeyType: Ed25519KeyType, Value: x}
+
+		thumbprint, err := pk.Thumbprint()
+		require.NoError(t, err)
+		require.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+	})
+
+	t.Run("success - EC key", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		expected, err := (&jose.JSONWebKey{Key: &priv.PublicKey}).Thumbprint(crypto.SHA256)
+		require.NoError(t, err)
+
+		pk := &PublicKey{KeyType: P256KeyType, Value: elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)}
+
+		thumbprint, err := pk.Thumbprint()
+		require.NoError(t, err)
+		require.Equal(t, base64.RawURLEncoding.EncodeToString(expected), thumbprint)
+	})
+
+	t.Run("failure - invalid key", func(t *testing.T) {
+		_, err := (&PublicKey{KeyType: X25519KeyType, Value: []byte("value")}).Thumbprint()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid X25519 key size 5")
+	})
+}
+
+func TestPublicKey_JWK(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// the JWK exported by a KMS holding the private key
+	jwk, err := pubkey.GetPublicKeyJWK(&priv.PublicKey)
+	require.NoError(t, err)
+
+	pk := PublicKey{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, JWK: jwk,
+		Purpose: []string{KeyPurposeGeneral}}
+
+	t.Run("success - document", func(t *testing.T) {
+		data, err := (&Doc{PublicKey: []PublicKey{pk}}).JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []struct {
+				JWK *jws.JWK `json:"jwk"`
+			} `json:"publicKey"`
+		}
+
+		require.NoError(t, json.Unmarshal(data, &raw))
+		require.Equal(t, jwk, raw.PublicKey[0].JWK)
+
+		thumbprint, err := pk.Thumbprint()
+		require.NoError(t, err)
+
+		expected, err := (&PublicKey{KeyType: P256KeyType,
+			Value: elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)}).Thumbprint()
+		require.NoError(t, err)
+		require.Equal(t, expected, thumbprint)
+	})
+
+	t.Run("success - aries document", func(t *testing.T) {
+		ariesDoc, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{pk}}, testDID)
+		require.NoError(t, err)
+		require.Len(t, ariesDoc.PublicKey, 1)
+		require.Equal(t, "key1", ariesDoc.PublicKey[0].JSONWebKey().KeyID)
+
+		key, ok := ariesDoc.PublicKey[0].JSONWebKey().Key.(*ecdsa.PublicKey)
+		require.True(t, ok)
+		require.Equal(t, 0, priv.X.Cmp(key.X))
+	})
+
+	t.Run("success - operation key", func(t *testing.T) {
+		expected, err := Commitment(&priv.PublicKey)
+		require.NoError(t, err)
+
+		c, err := (&PublicKey{JWK: jwk, Recovery: true}).Commitment()
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+
+		recoveryKey, err := New().getRecoveryKey([]PublicKey{{Encoding: PublicKeyEncodingJwk, JWK: jwk,
+			Recovery: true}})
+		require.NoError(t, err)
+		require.Equal(t, jwk, recoveryKey)
+
+		updateKey, err := New().getUpdateKey([]PublicKey{{Encoding: PublicKeyEncodingJwk, JWK: jwk, Update: true}})
+		require.NoError(t, err)
+		require.Equal(t, jwk, updateKey)
+	})
+
+	t.Run("success - operation key values", func(t *testing.T) {
+		jsonJWK, err := (&jose.JSONWebKey{Key: &priv.PublicKey}).MarshalJSON()
+		require.NoError(t, err)
+
+		recoveryKey, err := New().getRecoveryKey([]PublicKey{{Encoding: PublicKeyEncodingJwk, Value: jsonJWK,
+			Recovery: true}})
+		require.NoError(t, err)
+		require.Equal(t, jwk, recoveryKey)
+
+		updateKey, err := New().getUpdateKey([]PublicKey{{Encoding: PublicKeyEncodingJwk, KeyType: P256KeyType,
+			Value: elliptic.Marshal(elliptic.P256(), priv.X, priv.Y), Update: true}})
+		require.NoError(t, err)
+		require.Equal(t, jwk, updateKey)
+
+		_, err = New().getUpdateKey([]PublicKey{{Encoding: PublicKeyEncodingJwk, KeyType: P384KeyType,
+			Value: []byte("value"), Update: true}})
+		require.Error(t, err)
+	})
+
+	t.Run("failure - invalid JWK", func(t *testing.T) {
+		invalid := pk
+		invalid.JWK = &jws.JWK{Kty: "EC", Crv: "P-256", X: "x"}
+
+		_, err := ToAriesDoc(&Doc{PublicKey: []PublicKey{invalid}}, testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key key1: invalid JWK")
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Merge combines the keys and services of two docs, eg. when keys and services are managed by different systems.
+// The keys and services of base come first, followed by the ones of overlay. A key or service with the same ID in
+// both docs is only kept once if it's identical in both, otherwise Merge fails with a conflict error. Contexts and
+// alsoKnownAs identifiers of both docs are combined without duplicates.
+func Merge(base, overlay *Doc) (*Doc, error) {
+	merged := &Doc{}
+
+	keys := make(map[string]int)
+
+	for _, doc := range []*Doc{base, overlay} {
+		for i := range doc.PublicKey {
+			pk := doc.PublicKey[i]
+			id := strings.TrimPrefix(pk.ID, "#")
+
+			if j, ok := keys[id]; ok {
+				if !reflect.DeepEqual(merged.PublicKey[j], pk) {
+					return nil, fmt.Errorf("conflicting public keys with id %s", id)
+				}
+
+				continue
+			}
+
+			keys[id] = len(merged.PublicKey)
+			merged.PublicKey = append(merged.PublicKey, pk)
+		}
+	}
+
+	services := make(map[string]int)
+
+	for _, doc := range []*Doc{base, overlay} {
+		for i := range doc.Service {
+			svc := doc.Service[i]
+			id := strings.TrimPrefix(svc.ID, "#")
+
+			if j, ok := services[id]; ok {
+				if !reflect.DeepEqual(merged.Service[j], svc) {
+					return nil, fmt.Errorf("conflicting services with id %s", id)
+				}
+
+				continue
+			}
+
+			services[id] = len(merged.Service)
+			merged.Service = append(merged.Service, svc)
+		}
+	}
+
+	for _, doc := range []*Doc{base, overlay} {
+		merged.Context = appendUnique(merged.Context, doc.Context...)
+		merged.AlsoKnownAs = appendUnique(merged.AlsoKnownAs, doc.AlsoKnownAs...)
+	}
+
+	return merged, nil
+}
+
+func appendUnique(values []string, added ...string) []string {
+	for _, v := range added {
+		if !contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	key1 := PublicKey{ID: "key1", Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: []byte("key1"),
+		Purpose: []string{KeyPurposeGeneral}}
+	key2 := PublicKey{ID: "#key2", Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: []byte("key2"),
+		Purpose: []string{KeyPurposeAuth}}
+	hub := docdid.Service{ID: "hub", Type: "hub", ServiceEndpoint: "https://example.com/hub"}
+	agent := docdid.Service{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://example.com/agent"}
+
+	t.Run("success", func(t *testing.T) {
+		merged, err := Merge(
+			&Doc{PublicKey: []PublicKey{key1}, Service: []docdid.Service{hub}},
+			&Doc{PublicKey: []PublicKey{key2}, Service: []docdid.Service{agent}})
+		require.NoError(t, err)
+		require.Equal(t, []PublicKey{key1, key2}, merged.PublicKey)
+		require.Equal(t, []docdid.Service{hub, agent}, merged.Service)
+	})
+
+	t.Run("success - identical entries are kept once", func(t *testing.T) {
+		merged, err := Merge(
+			&Doc{PublicKey: []PublicKey{key1}, Service: []docdid.Service{hub}},
+			&Doc{PublicKey: []PublicKey{key1, key2}, Service: []docdid.Service{hub}})
+		require.NoError(t, err)
+		require.Equal(t, []PublicKey{key1, key2}, merged.PublicKey)
+		require.Equal(t, []docdid.Service{hub}, merged.Service)
+	})
+
+	t.Run("success - contexts and alsoKnownAs", func(t *testing.T) {
+		merged, err := Merge(
+			&Doc{Context: []string{"https://example.com/v1"}, AlsoKnownAs: []string{"did:example:123"}},
+			&Doc{Context: []string{"https://example.com/v1", "https://example.com/v2"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://example.com/v1", "https://example.com/v2"}, merged.Context)
+		require.Equal(t, []string{"did:example:123"}, merged.AlsoKnownAs)
+	})
+
+	t.Run("failure - conflicting keys", func(t *testing.T) {
+		other := key2
+		other.ID = "key2"
+		other.Value = []byte("other")
+
+		_, err := Merge(&Doc{PublicKey: []PublicKey{key2}}, &Doc{PublicKey: []PublicKey{other}})
+		require.EqualError(t, err, "conflicting public keys with id key2")
+	})
+
+	t.Run("failure - conflicting services", func(t *testing.T) {
+		other := hub
+		other.ServiceEndpoint = "https://other.example.com/hub"
+
+		_, err := Merge(&Doc{Service: []docdid.Service{hub}}, &Doc{Service: []docdid.Service{other}})
+		require.EqualError(t, err, "conflicting services with id hub")
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+const (
+	// PublicKeyEncodingMultibase defines multibase encoding type, with the key prefixed by its multicodec code, as
+	// used by the 2020 verification method suites
+	PublicKeyEncodingMultibase = "Multibase"
+
+	// Ed25519VerificationKey2020 defines key type signature, with a multibase encoded key. Sidetree nodes don't accept
+	// it, nor multibase encoded keys, yet, so ValidateDocument rejects documents with such keys.
+	Ed25519VerificationKey2020 = "Ed25519VerificationKey2020"
+
+	jsonldPublicKeyMultibase = "publicKeyMultibase"
+
+	// multibase prefix of base58btc encoded data
+	base58btcPrefix = "z"
+
+	// size of a compressed P-256 point
+	p256CompressedSize = 33
+)
+
+// multicodecPrefixes are the varint encoded multicodec codes of the key types: ed25519-pub and p256-pub
+var multicodecPrefixes = map[string][]byte{ // nolint: gochecknoglobals
+	Ed25519KeyType: {0xed, 0x01},
+	P256KeyType:    {0x80, 0x24},
+}
+
+// multibaseKey returns the base58btc multibase encoding of a public key prefixed by the multicodec code of its type.
+// P-256 keys are compressed.
+func multibaseKey(pk *PublicKey) (string, error) {
+	prefix, ok := multicodecPrefixes[pk.KeyType]
+	if !ok {
+		return "", fmt.Errorf("invalid key type for multibase encoding: %s", pk.KeyType)
+	}
+
+	value := pk.Value
+
+	switch pk.KeyType {
+	case Ed25519KeyType:
+		if len(value) != ed25519.PublicKeySize {
+			return "", fmt.Errorf("public key %s: invalid Ed25519 key size %d", pk.ID, len(value))
+		}
+	case P256KeyType:
+		x, y := elliptic.Unmarshal(elliptic.P256(), value)
+		if x == nil {
+			return "", fmt.Errorf("public key %s: invalid P-256 point", pk.ID)
+		}
+
+		// SEC 1 compressed point: the parity of y followed by x
+		xBytes := x.Bytes()
+
+		value = make([]byte, p256CompressedSize)
+		value[0] = byte(2 + y.Bit(0))
+		copy(value[p256CompressedSize-len(xBytes):], xBytes)
+	}
+
+	return base58btcPrefix + base58.Encode(append(append([]byte{}, prefix...), value...)), nil
+}
+
+// multibaseValue returns the key type and value of a multibase encoded public key. P-256 keys are decompressed.
+func multibaseValue(key string) (string, []byte, error) {
+	if !strings.HasPrefix(key, base58btcPrefix) {
+		return "", nil, errors.New("publicKeyMultibase must be base58btc encoded")
+	}
+
+	decoded := base58.Decode(key[len(base58btcPrefix):])
+
+	for keyType, prefix := range multicodecPrefixes {
+		if !bytes.HasPrefix(decoded, prefix) {
+			continue
+		}
+
+		value := decoded[len(prefix):]
+
+		if keyType == P256KeyType {
+			var err error
+
+			value, err = decompressP256(value)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		return keyType, value, nil
+	}
+
+	return "", nil, errors.New("unsupported multicodec of publicKeyMultibase")
+}
+
+// decompressP256 returns the uncompressed form of a compressed P-256 point
+func decompressP256(compressed []byte) ([]byte, error) {
+	if len(compressed) != p256CompressedSize || compressed[0]&^1 != 2 {
+		return nil, errors.New("invalid compressed P-256 point")
+	}
+
+	curve := elliptic.P256()
+	params := curve.Params()
+
+	// y² = x³ - 3x + b
+	x := new(big.Int).SetBytes(compressed[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, errors.New("invalid compressed P-256 point")
+	}
+
+	y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+	y2.Sub(y2, new(big.Int).Mul(x, big.NewInt(3)))
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+
+	y := new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, errors.New("invalid compressed P-256 point")
+	}
+
+	if y.Bit(0) != uint(compressed[0]&1) {
+		y.Sub(params.P, y)
+	}
+
+	return elliptic.Marshal(curve, x, y), nil
+}
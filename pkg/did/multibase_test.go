@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoc_JSONBytes_Multibase(t *testing.T) {
+	decode := func(t *testing.T, doc *Doc) []byte {
+		bytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		var raw struct {
+			PublicKey []map[string]interface{} `json:"publicKey"`
+		}
+
+		require.NoError(t, json.Unmarshal(bytes, &raw))
+		require.Len(t, raw.PublicKey, 1)
+		require.NotContains(t, raw.PublicKey[0], "jwk")
+
+		key, ok := raw.PublicKey[0]["publicKeyMultibase"].(string)
+		require.True(t, ok)
+		require.True(t, strings.HasPrefix(key, "z"))
+
+		return base58.Decode(key[1:])
+	}
+
+	t.Run("Ed25519 key", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		decoded := decode(t, &Doc{PublicKey: []PublicKey{{ID: "key1", Type: Ed25519VerificationKey2020,
+			Encoding: PublicKeyEncodingMultibase, KeyType: Ed25519KeyType, Value: pub,
+			Purpose: []string{KeyPurposeGeneral}}}})
+
+		require.Equal(t, append([]byte{0xed, 0x01}, pub...), decoded)
+	})
+
+	t.Run("failure - key type and encoding rejected by sidetree", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		err = ValidateDocument(&Doc{PublicKey: []PublicKey{{ID: "key1", Type: Ed25519VerificationKey2020,
+			Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral}},
+			{ID: "key2", Type: JSONWebKey2020, Encoding: PublicKeyEncodingMultibase, KeyType: Ed25519KeyType,
+				Value: pub, Purpose: []string{KeyPurposeGeneral}}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "publicKey[0]: invalid key type: Ed25519VerificationKey2020")
+		require.Contains(t, err.Error(), "publicKey[1]: key has to be in JWK format")
+	})
+
+	t.Run("P-256 key", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		decoded := decode(t, &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JSONWebKey2020,
+			Encoding: PublicKeyEncodingMultibase, KeyType: P256KeyType,
+			Value: elliptic.Marshal(elliptic.P256(), priv.X, priv.Y), Purpose: []string{KeyPurposeGeneral}}}})
+
+		require.Len(t, decoded, 35)
+		require.Equal(t, []byte{0x80, 0x24}, decoded[:2])
+		require.Equal(t, byte(2+priv.Y.Bit(0)), decoded[2])
+		require.Equal(t, 0, priv.X.Cmp(new(big.Int).SetBytes(decoded[3:])))
+	})
+
+	t.Run("failure - invalid keys", func(t *testing.T) {
+		for keyType, msg := range map[string]string{
+			Ed25519KeyType: "public key key1: invalid Ed25519 key size 5",
+			P256KeyType:    "public key key1: invalid P-256 point",
+			P384KeyType:    "invalid key type for multibase encoding: P384",
+		} {
+			doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: Ed25519VerificationKey2020,
+				Encoding: PublicKeyEncodingMultibase, KeyType: keyType, Value: []byte("value")}}}
+
+			_, err := doc.JSONBytes()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), msg)
+		}
+	})
+}
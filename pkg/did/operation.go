@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const (
+	expectedDIDParts = 4
+	domainDIDPart    = 2
+	suffixDIDPart    = 3
+)
+
+// operationRequest holds the fields of a sidetree operation request checked before it's sent
+type operationRequest struct {
+	Operation model.OperationType `json:"type"`
+	DidSuffix string              `json:"did_suffix"`
+}
+
+// SendUpdateRequest sends a sidetree update request of a DID, built and signed by its controller, to the sidetree
+// endpoint of the DID's consortium
+func (c *Client) SendUpdateRequest(didID string, req []byte) error {
+	return c.sendOperationRequest(didID, req, model.OperationTypeUpdate)
+}
+
+// SendDeactivateRequest sends a sidetree deactivate request of a DID, built and signed by its controller, to the
+// sidetree endpoint of the DID's consortium
+func (c *Client) SendDeactivateRequest(didID string, req []byte) error {
+	return c.sendOperationRequest(didID, req, model.OperationTypeDeactivate)
+}
+
+func (c *Client) sendOperationRequest(didID string, req []byte, opType model.OperationType) error {
+	didParts := strings.Split(didID, ":")
+	if len(didParts) != expectedDIDParts {
+		return fmt.Errorf("wrong did %s", didID)
+	}
+
+	op := operationRequest{}
+
+	if err := json.Unmarshal(req, &op); err != nil {
+		return fmt.Errorf("failed to parse sidetree request: %w", err)
+	}
+
+	if op.Operation != opType {
+		return fmt.Errorf("sidetree request type '%s' isn't %s", op.Operation, opType)
+	}
+
+	if op.DidSuffix != didParts[suffixDIDPart] {
+		return fmt.Errorf("sidetree request suffix '%s' doesn't match did %s", op.DidSuffix, didID)
+	}
+
+	endpoints, err := c.endpointService.GetEndpoints(didParts[domainDIDPart])
+	if err != nil {
+		return fmt.Errorf("failed to get endpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		return errors.New("list of endpoints is empty")
+	}
+
+	err = c.postOperationRequest(req, endpoints[0].URL)
+	if err != nil {
+		return fmt.Errorf("failed to send %s sidetree request: %w", opType, err)
+	}
+
+	return nil
+}
+
+func (c *Client) postOperationRequest(req []byte, endpointURL string) error {
+	httpReq, err := http.NewRequest(http.MethodPost, endpointURL+"/operations", bytes.NewReader(req))
+	if err != nil {
+		return fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	if c.authToken != "" {
+		httpReq.Header.Add("Authorization", c.authToken)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer c.closeResponseBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		responseBytes, errRead := ioutil.ReadAll(resp.Body)
+		if errRead != nil {
+			return fmt.Errorf("failed to read response : %w", errRead)
+		}
+
+		return fmt.Errorf("got unexpected response from %s status '%d' body %s",
+			endpointURL, resp.StatusCode, responseBytes)
+	}
+
+	return nil
+}
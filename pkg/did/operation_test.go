@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	operationDID      = "did:trustbloc:testnet:EiAsuffix"
+	updateRequest     = `{"type":"update","did_suffix":"EiAsuffix","signed_data":"jws","delta":"delta"}`
+	deactivateRequest = `{"type":"deactivate","did_suffix":"EiAsuffix","signed_data":"jws"}`
+)
+
+func TestClient_SendUpdateRequest(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/operations", r.URL.Path)
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, updateRequest, string(body))
+		}))
+		defer serv.Close()
+
+		v := New(WithAuthToken("token"))
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				require.Equal(t, "testnet", domain)
+				return []*models.Endpoint{{URL: serv.URL}}, nil
+			}}
+
+		require.NoError(t, v.SendUpdateRequest(operationDID, []byte(updateRequest)))
+	})
+
+	t.Run("test wrong did", func(t *testing.T) {
+		err := New().SendUpdateRequest("did:trustbloc:EiAsuffix", []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wrong did")
+	})
+
+	t.Run("test invalid request", func(t *testing.T) {
+		err := New().SendUpdateRequest(operationDID, []byte("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse sidetree request")
+	})
+
+	t.Run("test wrong operation type", func(t *testing.T) {
+		err := New().SendUpdateRequest(operationDID, []byte(deactivateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sidetree request type 'deactivate' isn't update")
+	})
+
+	t.Run("test suffix of another did", func(t *testing.T) {
+		err := New().SendUpdateRequest("did:trustbloc:testnet:EiAother", []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match did")
+	})
+
+	t.Run("test error from get endpoints", func(t *testing.T) {
+		v := New()
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				return nil, fmt.Errorf("discover error")
+			}}
+
+		err := v.SendUpdateRequest(operationDID, []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discover error")
+
+		v.endpointService = &mockendpoint.MockEndpointService{}
+
+		err = v.SendUpdateRequest(operationDID, []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list of endpoints is empty")
+	})
+
+	t.Run("test error from sidetree", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("invalid signature"))
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		v := New()
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				return []*models.Endpoint{{URL: serv.URL}}, nil
+			}}
+
+		err := v.SendUpdateRequest(operationDID, []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send update sidetree request")
+		require.Contains(t, err.Error(), "status '400' body invalid signature")
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				return []*models.Endpoint{{URL: "url"}}, nil
+			}}
+
+		err = v.SendUpdateRequest(operationDID, []byte(updateRequest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send request")
+	})
+}
+
+func TestClient_SendDeactivateRequest(t *testing.T) {
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, deactivateRequest, string(body))
+	}))
+	defer serv.Close()
+
+	v := New()
+	v.endpointService = &mockendpoint.MockEndpointService{
+		GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+			return []*models.Endpoint{{URL: serv.URL}}, nil
+		}}
+
+	require.NoError(t, v.SendDeactivateRequest(operationDID, []byte(deactivateRequest)))
+
+	err := v.SendDeactivateRequest(operationDID, []byte(updateRequest))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sidetree request type 'update' isn't deactivate")
+}
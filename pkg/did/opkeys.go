@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const operationKeysVersion = 1
+
+// Commitment returns the commitment to the given public key, as set in the create request or in the delta of the
+// previous operation. The next operation has to reveal the public key matching the commitment.
+func Commitment(publicKey interface{}) (string, error) {
+	jwk, err := RevealValue(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return commitment.Calculate(jwk, sha2_256)
+}
+
+// Commitment returns the commitment to the public key, for the recovery and update keys of the create options or of
+// raw sidetree requests. The key is an Ed25519 or EC key.
+func (pk *PublicKey) Commitment() (string, error) {
+	jwk, err := publicKeyJWK(pk)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert public key to JWK: %w", err)
+	}
+
+	return commitment.Calculate(jwk, sha2_256)
+}
+
+// RevealValue returns the value revealed by an operation for the commitment to the given public key, which is the
+// public key in JWK format.
+func RevealValue(publicKey interface{}) (*jws.JWK, error) {
+	jwk, err := pubkey.GetPublicKeyJWK(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert public key to JWK: %w", err)
+	}
+
+	return jwk, nil
+}
+
+// OperationKey is an ed25519 key pair used to sign an update or recovery operation
+type OperationKey struct {
+	PrivateKey ed25519.PrivateKey `json:"privateKey"`
+	Commitment string             `json:"commitment"`
+}
+
+// GenerateOperationKey generates a new operation key pair, with its commitment
+func GenerateOperationKey() (*OperationKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	c, err := Commitment(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &OperationKey{PrivateKey: privateKey, Commitment: c}, nil
+}
+
+// PublicKey returns the public key of the operation key pair
+func (k *OperationKey) PublicKey() ed25519.PublicKey {
+	return k.PrivateKey.Public().(ed25519.PublicKey)
+}
+
+// RevealValue returns the value revealed by the operation signed with this key
+func (k *OperationKey) RevealValue() (*jws.JWK, error) {
+	return RevealValue(k.PublicKey())
+}
+
+// Signer returns a signer of operations with this key, using the given key ID
+func (k *OperationKey) Signer(keyID string) helper.Signer {
+	return edsigner.New(k.PrivateKey, "EdDSA", keyID)
+}
+
+func (k *OperationKey) validate() error {
+	if len(k.PrivateKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid private key size %d", len(k.PrivateKey))
+	}
+
+	c, err := Commitment(k.PublicKey())
+	if err != nil {
+		return err
+	}
+
+	if c != k.Commitment {
+		return errors.New("commitment doesn't match the private key")
+	}
+
+	return nil
+}
+
+// OperationKeys bundles the current update and recovery keys of a DID, serialized in JSON to be stored by
+// integrators between operations
+type OperationKeys struct {
+	Version  int           `json:"version"`
+	Update   *OperationKey `json:"update"`
+	Recovery *OperationKey `json:"recovery"`
+}
+
+// NewOperationKeys generates the update and recovery keys of a new DID
+func NewOperationKeys() (*OperationKeys, error) {
+	update, err := GenerateOperationKey()
+	if err != nil {
+		return nil, fmt.Errorf("update key: %w", err)
+	}
+
+	recovery, err := GenerateOperationKey()
+	if err != nil {
+		return nil, fmt.Errorf("recovery key: %w", err)
+	}
+
+	return &OperationKeys{Version: operationKeysVersion, Update: update, Recovery: recovery}, nil
+}
+
+// ParseOperationKeys parses an operation keys bundle, checking the commitments match the private keys
+func ParseOperationKeys(data []byte) (*OperationKeys, error) {
+	keys := &OperationKeys{}
+
+	if err := json.Unmarshal(data, keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operation keys: %w", err)
+	}
+
+	if keys.Version != operationKeysVersion {
+		return nil, fmt.Errorf("unsupported operation keys version %d", keys.Version)
+	}
+
+	for _, k := range []struct {
+		name string
+		key  *OperationKey
+	}{{"update", keys.Update}, {"recovery", keys.Recovery}} {
+		if k.key == nil {
+			return nil, fmt.Errorf("%s key is missing", k.name)
+		}
+
+		if err := k.key.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s key: %w", k.name, err)
+		}
+	}
+
+	return keys, nil
+}
+
+// CreateDIDOptions returns the options setting the update and recovery public keys of a DID created with the bundle
+func (k *OperationKeys) CreateDIDOptions() []CreateDIDOption {
+	return []CreateDIDOption{
+		WithPublicKey(&PublicKey{Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+			Value: k.Update.PublicKey(), Update: true}),
+		WithPublicKey(&PublicKey{Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType,
+			Value: k.Recovery.PublicKey(), Recovery: true}),
+	}
+}
+
+// RotateUpdateKey generates the next update key. It returns the options signing an update with the current update
+// key and committing to the next one; once the update is accepted the bundle must be replaced with the returned one.
+func (k *OperationKeys) RotateUpdateKey(keyID string) ([]UpdateDIDOption, *OperationKeys, error) {
+	next, err := GenerateOperationKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("next update key: %w", err)
+	}
+
+	opts := []UpdateDIDOption{
+		WithSigningKey(k.Update.Signer(keyID), k.Update.PublicKey()),
+		WithNextUpdatePublicKey(next.PublicKey()),
+	}
+
+	return opts, &OperationKeys{Version: k.Version, Update: next, Recovery: k.Recovery}, nil
+}
+
+// RotateRecoveryKey generates the next recovery and update keys. It returns the options signing a recovery with the
+// current recovery key and committing to the next keys; once the recovery is accepted the bundle must be replaced with
+// the returned one.
+func (k *OperationKeys) RotateRecoveryKey(keyID string) ([]RecoverDIDOption, *OperationKeys, error) {
+	next, err := NewOperationKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := []RecoverDIDOption{
+		WithRecoverySigningKey(k.Recovery.Signer(keyID), k.Recovery.PublicKey()),
+		WithNextRecoveryPublicKey(next.Recovery.PublicKey()),
+		WithRecoverNextUpdatePublicKey(next.Update.PublicKey()),
+	}
+
+	next.Version = k.Version
+
+	return opts, next, nil
+}
+
+// DeactivateDIDOptions returns the options signing the deactivation of a DID with the current recovery key
+func (k *OperationKeys) DeactivateDIDOptions(keyID string) []DeactivateDIDOption {
+	return []DeactivateDIDOption{WithDeactivateSigningKey(k.Recovery.Signer(keyID), k.Recovery.PublicKey())}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestCommitment(t *testing.T) {
+	key, err := GenerateOperationKey()
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(key.PublicKey())
+	require.NoError(t, err)
+
+	expected, err := commitment.Calculate(jwk, sha2_256)
+	require.NoError(t, err)
+	require.Equal(t, expected, key.Commitment)
+
+	reveal, err := key.RevealValue()
+	require.NoError(t, err)
+	require.Equal(t, jwk, reveal)
+
+	_, err = Commitment("wrong")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to convert public key to JWK")
+}
+
+func TestPublicKey_Commitment(t *testing.T) {
+	key, err := GenerateOperationKey()
+	require.NoError(t, err)
+
+	pk := &PublicKey{ID: "recovery", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+		KeyType: Ed25519KeyType, Value: key.PublicKey(), Recovery: true}
+
+	c, err := pk.Commitment()
+	require.NoError(t, err)
+	require.Equal(t, key.Commitment, c)
+
+	pk.KeyType = P256KeyType
+
+	_, err = pk.Commitment()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to convert public key to JWK")
+}
+
+func TestOperationKeys(t *testing.T) {
+	keys, err := NewOperationKeys()
+	require.NoError(t, err)
+
+	t.Run("success - round trip", func(t *testing.T) {
+		data, err := json.Marshal(keys)
+		require.NoError(t, err)
+
+		parsed, err := ParseOperationKeys(data)
+		require.NoError(t, err)
+		require.Equal(t, keys, parsed)
+	})
+
+	t.Run("success - create DID options", func(t *testing.T) {
+		createDIDOpts := &CreateDIDOpts{}
+		for _, opt := range keys.CreateDIDOptions() {
+			opt(createDIDOpts)
+		}
+
+		updateKey, err := New().getUpdateKey(createDIDOpts.publicKeys)
+		require.NoError(t, err)
+
+		c, err := commitment.Calculate(updateKey, sha2_256)
+		require.NoError(t, err)
+		require.Equal(t, keys.Update.Commitment, c)
+
+		recoveryKey, err := New().getRecoveryKey(createDIDOpts.publicKeys)
+		require.NoError(t, err)
+
+		c, err = commitment.Calculate(recoveryKey, sha2_256)
+		require.NoError(t, err)
+		require.Equal(t, keys.Recovery.Commitment, c)
+	})
+
+	t.Run("success - rotate update key", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := updateServer(t, delta)
+
+		defer serv.Close()
+
+		opts, next, err := keys.RotateUpdateKey(updateKeyID)
+		require.NoError(t, err)
+		require.Equal(t, keys.Recovery, next.Recovery)
+		require.NotEqual(t, keys.Update.Commitment, next.Update.Commitment)
+
+		opts = append(opts, WithRemoveServices("agent"), WithUpdateSidetreeEndpoint(serv.URL))
+
+		require.NoError(t, New().UpdateDID(updateTestDID, opts...))
+		require.Equal(t, next.Update.Commitment, delta.UpdateCommitment)
+	})
+
+	t.Run("failure - parse", func(t *testing.T) {
+		_, err := ParseOperationKeys([]byte("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal operation keys")
+
+		_, err = ParseOperationKeys([]byte(`{"version":2}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported operation keys version 2")
+
+		_, err = ParseOperationKeys([]byte(`{"version":1}`))
+		require.Error(t, err)
+		require.EqualError(t, err, "update key is missing")
+
+		other, err := GenerateOperationKey()
+		require.NoError(t, err)
+
+		data, err := json.Marshal(&OperationKeys{Version: 1, Update: keys.Update,
+			Recovery: &OperationKey{PrivateKey: keys.Recovery.PrivateKey, Commitment: other.Commitment}})
+		require.NoError(t, err)
+
+		_, err = ParseOperationKeys(data)
+		require.EqualError(t, err, "invalid recovery key: commitment doesn't match the private key")
+
+		data, err = json.Marshal(&OperationKeys{Version: 1, Update: keys.Update,
+			Recovery: &OperationKey{PrivateKey: ed25519.PrivateKey("short")}})
+		require.NoError(t, err)
+
+		_, err = ParseOperationKeys(data)
+		require.EqualError(t, err, "invalid recovery key: invalid private key size 5")
+	})
+}
@@ -0,0 +1,206 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// rawCreateDoc is a document in the format of create requests, as serialized by JSONBytes
+type rawCreateDoc struct {
+	Context     []string          `json:"@context,omitempty"`
+	PublicKey   []json.RawMessage `json:"publicKey,omitempty"`
+	Service     []json.RawMessage `json:"service,omitempty"`
+	AlsoKnownAs []string          `json:"alsoKnownAs,omitempty"`
+}
+
+type rawCreatePublicKey struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Purpose    []string        `json:"purpose"`
+	Controller string          `json:"controller"`
+	JWK        json.RawMessage `json:"jwk"`
+	Multibase  string          `json:"publicKeyMultibase"`
+}
+
+// ParseDoc parses a DID document into a doc, to modify it before an update or recovery. The document is either a
+// W3C DID document, eg. a resolved one, or a document in the format of create requests (see JSONBytes), which has
+// no id.
+func ParseDoc(data []byte) (*Doc, error) {
+	probe := &struct {
+		ID          string   `json:"id"`
+		AlsoKnownAs []string `json:"alsoKnownAs"`
+	}{}
+
+	if err := json.Unmarshal(data, probe); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	if probe.ID == "" {
+		return parseCreateDoc(data)
+	}
+
+	ariesDoc, err := docdid.ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DID document: %w", err)
+	}
+
+	doc, err := FromAriesDoc(ariesDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.AlsoKnownAs = probe.AlsoKnownAs
+
+	return doc, nil
+}
+
+func parseCreateDoc(data []byte) (*Doc, error) {
+	raw := &rawCreateDoc{}
+
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	doc := &Doc{Context: raw.Context, AlsoKnownAs: raw.AlsoKnownAs}
+
+	for _, rawKey := range raw.PublicKey {
+		pk, err := parseCreatePublicKey(rawKey)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.PublicKey = append(doc.PublicKey, *pk)
+	}
+
+	for _, rawService := range raw.Service {
+		service, err := parseCreateService(rawService)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Service = append(doc.Service, *service)
+	}
+
+	return doc, nil
+}
+
+func parseCreatePublicKey(data json.RawMessage) (*PublicKey, error) {
+	raw := &rawCreatePublicKey{}
+
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	properties, err := extensionProperties(data, publicKeyMembers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	pk := &PublicKey{ID: raw.ID, Type: raw.Type, Purpose: raw.Purpose, Controller: raw.Controller,
+		Properties: properties}
+
+	switch {
+	case len(raw.JWK) > 0:
+		pk.Encoding = PublicKeyEncodingJwk
+		err = pk.valueFromRawJWK(raw.JWK)
+	case raw.Multibase != "":
+		pk.Encoding = PublicKeyEncodingMultibase
+		pk.KeyType, pk.Value, err = multibaseValue(raw.Multibase)
+	default:
+		err = fmt.Errorf("no jwk or publicKeyMultibase")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("public key %s: %w", raw.ID, err)
+	}
+
+	return pk, nil
+}
+
+// valueFromRawJWK sets the value and key type of the public key from a JWK, including the BLS12-381 G2 and X25519
+// keys JWKs of the aries framework don't support
+func (pk *PublicKey) valueFromRawJWK(raw json.RawMessage) error {
+	params := &struct {
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+	}{}
+
+	if err := json.Unmarshal(raw, params); err != nil {
+		return fmt.Errorf("invalid jwk: %w", err)
+	}
+
+	bytesKeyTypes := map[string]string{"BLS12381_G2": Bls12381G2KeyType, "X25519": X25519KeyType}
+
+	if keyType, ok := bytesKeyTypes[params.Crv]; ok {
+		value, err := base64.RawURLEncoding.DecodeString(params.X)
+		if err != nil {
+			return fmt.Errorf("invalid jwk x: %w", err)
+		}
+
+		pk.KeyType = keyType
+		pk.Value = value
+
+		return nil
+	}
+
+	jwk := &jose.JWK{}
+
+	if err := jwk.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+
+	return pk.GetValueFromJWK(&jwk.JSONWebKey)
+}
+
+func parseCreateService(data json.RawMessage) (*docdid.Service, error) {
+	raw := &struct {
+		ID            string   `json:"id"`
+		Type          string   `json:"type"`
+		Endpoint      string   `json:"endpoint"`
+		RecipientKeys []string `json:"recipientKeys"`
+		RoutingKeys   []string `json:"routingKeys"`
+		Priority      uint     `json:"priority"`
+	}{}
+
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("invalid service: %w", err)
+	}
+
+	properties, err := extensionProperties(data, serviceMembers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service: %w", err)
+	}
+
+	return &docdid.Service{ID: raw.ID, Type: raw.Type, ServiceEndpoint: raw.Endpoint,
+		RecipientKeys: raw.RecipientKeys, RoutingKeys: raw.RoutingKeys, Priority: raw.Priority,
+		Properties: properties}, nil
+}
+
+// extensionProperties returns the members of a JSON object other than the given standard members, or nil if there
+// are none
+func extensionProperties(data json.RawMessage, members []string) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, err
+	}
+
+	for _, k := range members {
+		delete(properties, k)
+	}
+
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	return properties, nil
+}
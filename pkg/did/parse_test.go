@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDoc(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecPub := elliptic.Marshal(elliptic.P256(), ecPriv.X, ecPriv.Y)
+
+	t.Run("success - create document", func(t *testing.T) {
+		doc := &Doc{
+			Context:     []string{"https://w3id.org/security/v2"},
+			AlsoKnownAs: []string{"https://example.com/alice"},
+			PublicKey: []PublicKey{
+				{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+					KeyType: Ed25519KeyType, Value: edPub, Purpose: []string{KeyPurposeGeneral}},
+				{ID: "key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: P256KeyType,
+					Value: ecPub, Purpose: []string{KeyPurposeAuth}},
+				{ID: "key3", Type: Bls12381G2Key2020, Encoding: PublicKeyEncodingJwk, KeyType: Bls12381G2KeyType,
+					Value: make([]byte, bls12381G2PublicKeySize), Purpose: []string{KeyPurposeAssertion}},
+				{ID: "key4", Type: X25519KeyAgreementKey2019, Encoding: PublicKeyEncodingJwk, KeyType: X25519KeyType,
+					Value: make([]byte, x25519PublicKeySize), Purpose: []string{KeyPurposeAgreement}},
+				{ID: "key5", Type: Ed25519VerificationKey2020, Encoding: PublicKeyEncodingMultibase,
+					KeyType: Ed25519KeyType, Value: edPub, Purpose: []string{KeyPurposeGeneral}},
+				{ID: "key6", Type: JSONWebKey2020, Encoding: PublicKeyEncodingMultibase, KeyType: P256KeyType,
+					Value: ecPub, Purpose: []string{KeyPurposeGeneral}},
+			},
+			Service: []docdid.Service{{ID: "agent", Type: "did-communication",
+				ServiceEndpoint: "https://agent.example.com", RecipientKeys: []string{"key1"}, Priority: 1,
+				Properties: map[string]interface{}{"description": "agent"}}},
+		}
+
+		data, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := ParseDoc(data)
+		require.NoError(t, err)
+		require.Equal(t, doc, parsed)
+	})
+
+	t.Run("success - DID document", func(t *testing.T) {
+		doc := &Doc{AlsoKnownAs: []string{"https://example.com/alice"}, PublicKey: []PublicKey{{ID: "key1",
+			Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: edPub,
+			Purpose: []string{KeyPurposeGeneral}}}}
+
+		ariesDoc, err := ToAriesDoc(doc, "did:trustbloc:testnet.example.com:EiAtestsuffix")
+		require.NoError(t, err)
+
+		data, err := ariesDoc.JSONBytes()
+		require.NoError(t, err)
+
+		// aries docs don't have alsoKnownAs
+		data = append(data[:len(data)-1], []byte(`,"alsoKnownAs":["https://example.com/alice"]}`)...)
+
+		parsed, err := ParseDoc(data)
+		require.NoError(t, err)
+		require.Equal(t, doc.AlsoKnownAs, parsed.AlsoKnownAs)
+		require.Len(t, parsed.PublicKey, 1)
+		require.Equal(t, "key1", parsed.PublicKey[0].ID)
+		require.Equal(t, edPub, ed25519.PublicKey(parsed.PublicKey[0].Value))
+	})
+
+	t.Run("success - key controller and extension properties", func(t *testing.T) {
+		parsed, err := ParseDoc([]byte(`{"publicKey":[{"id":"key1","type":"JwsVerificationKey2020",` +
+			`"purpose":["general"],"controller":"did:example:controller","expires":"2030-01-01T00:00:00Z",` +
+			`"jwk":{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}]}`))
+		require.NoError(t, err)
+		require.Len(t, parsed.PublicKey, 1)
+		require.Equal(t, "did:example:controller", parsed.PublicKey[0].Controller)
+		require.Equal(t, map[string]interface{}{"expires": "2030-01-01T00:00:00Z"}, parsed.PublicKey[0].Properties)
+	})
+
+	t.Run("failure - invalid documents", func(t *testing.T) {
+		for doc, msg := range map[string]string{
+			`[]`:                                    "failed to parse document",
+			`{"id":"did:example:1","publicKey":{}}`: "failed to parse DID document",
+			`{"publicKey":[{"id":"key1"}]}`:         "public key key1: no jwk or publicKeyMultibase",
+			`{"publicKey":[{"id":"key1","jwk":{"kty":"oct"}}]}`:            "public key key1",
+			`{"publicKey":[{"id":"key1","jwk":{"crv":"X25519","x":"!"}}]}`: "public key key1: invalid jwk x",
+			`{"publicKey":[{"id":"key1","publicKeyMultibase":"abc"}]}`:     "must be base58btc encoded",
+			`{"publicKey":[{"id":"key1","publicKeyMultibase":"z111"}]}`:    "unsupported multicodec",
+			`{"publicKey":[{"id":"key1","purpose":"general"}]}`:            "invalid public key",
+			`{"service":[{"id":"agent","priority":"high"}]}`:               "invalid service",
+		} {
+			_, err := ParseDoc([]byte(doc))
+			require.Error(t, err, doc)
+			require.Contains(t, err.Error(), msg, doc)
+		}
+	})
+
+	t.Run("failure - invalid compressed P-256 point", func(t *testing.T) {
+		_, err := decompressP256([]byte{2, 1})
+		require.EqualError(t, err, "invalid compressed P-256 point")
+
+		// x is greater than the field size
+		compressed := bytes.Repeat([]byte{0xff}, p256CompressedSize)
+		compressed[0] = 2
+
+		_, err = decompressP256(compressed)
+		require.EqualError(t, err, "invalid compressed P-256 point")
+	})
+}
@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const pemPublicKeyType = "PUBLIC KEY"
+
+// ParsePublicKeyPEM parses a PEM encoded PKIX public key, as exported by OpenSSL or cloud KMSs, into a public key
+// whose value and key type are set. The key is an Ed25519 key, or an EC key of the P-256, P-384 or P-521 curves.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if block.Type != pemPublicKeyType {
+		return nil, fmt.Errorf("unsupported PEM block type %s, expected %s", block.Type, pemPublicKeyType)
+	}
+
+	return ParsePublicKeyDER(block.Bytes)
+}
+
+// ParsePublicKeyDER parses a DER encoded PKIX public key into a public key whose value and key type are set. The key
+// is an Ed25519 key, or an EC key of the P-256, P-384 or P-521 curves.
+func ParsePublicKeyDER(der []byte) (*PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PKIX public key: %w", err)
+	}
+
+	pk := &PublicKey{}
+
+	if err = pk.setValue(key); err != nil {
+		return nil, err
+	}
+
+	return pk, nil
+}
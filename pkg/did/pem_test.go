@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublicKeyPEM(t *testing.T) {
+	encode := func(t *testing.T, key interface{}) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	t.Run("success - Ed25519 key", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		pk, err := ParsePublicKeyPEM(encode(t, pub))
+		require.NoError(t, err)
+		require.Equal(t, Ed25519KeyType, pk.KeyType)
+		require.Equal(t, []byte(pub), pk.Value)
+	})
+
+	t.Run("success - EC keys", func(t *testing.T) {
+		for keyType, curve := range map[string]elliptic.Curve{P256KeyType: elliptic.P256(),
+			P384KeyType: elliptic.P384(), P521KeyType: elliptic.P521()} {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			pk, err := ParsePublicKeyPEM(encode(t, &priv.PublicKey))
+			require.NoError(t, err)
+			require.Equal(t, keyType, pk.KeyType)
+			require.Equal(t, elliptic.Marshal(curve, priv.X, priv.Y), pk.Value)
+		}
+	})
+
+	t.Run("failure - invalid PEM", func(t *testing.T) {
+		_, err := ParsePublicKeyPEM([]byte("key"))
+		require.EqualError(t, err, "no PEM block found")
+
+		_, err = ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}))
+		require.EqualError(t, err, "unsupported PEM block type PRIVATE KEY, expected PUBLIC KEY")
+
+		_, err = ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse PKIX public key")
+	})
+
+	t.Run("failure - unsupported key", func(t *testing.T) {
+		priv, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		_, err = ParsePublicKeyPEM(encode(t, &priv.PublicKey))
+		require.EqualError(t, err, "unsupported PublicKey source key type")
+	})
+}
@@ -0,0 +1,238 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// RecoverDID replaces the document of a DID with the public keys and services given as options. The recovery is
+// signed with the current recovery key of the DID, and commits to the next recovery and update keys.
+func (c *Client) RecoverDID(didID string, opts ...RecoverDIDOption) error {
+	recoverDIDOpts := &RecoverDIDOpts{}
+	// Apply options
+	for _, opt := range opts {
+		opt(recoverDIDOpts)
+	}
+
+	parsedDID, err := models.ParseDID(didID)
+	if err != nil {
+		return err
+	}
+
+	sidetreeEndpoint := recoverDIDOpts.sidetreeEndpoint
+
+	if sidetreeEndpoint == "" {
+		sidetreeEndpoint, err = c.sidetreeEndpoint(parsedDID.Domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := buildRecoverRequest(parsedDID.Suffix, recoverDIDOpts)
+	if err != nil {
+		return fmt.Errorf("failed to build sidetree recover request: %w", err)
+	}
+
+	_, err = c.sendRequest(context.Background(), req, sidetreeEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to send recover sidetree request: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivateDID deactivates a DID. The deactivation is signed with the current recovery key of the DID.
+func (c *Client) DeactivateDID(didID string, opts ...DeactivateDIDOption) error {
+	deactivateDIDOpts := &DeactivateDIDOpts{}
+	// Apply options
+	for _, opt := range opts {
+		opt(deactivateDIDOpts)
+	}
+
+	parsedDID, err := models.ParseDID(didID)
+	if err != nil {
+		return err
+	}
+
+	sidetreeEndpoint := deactivateDIDOpts.sidetreeEndpoint
+
+	if sidetreeEndpoint == "" {
+		sidetreeEndpoint, err = c.sidetreeEndpoint(parsedDID.Domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	if deactivateDIDOpts.signer == nil {
+		return errors.New("signing key is required")
+	}
+
+	recoveryKey, err := pubkey.GetPublicKeyJWK(deactivateDIDOpts.recoveryPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid recovery public key: %w", err)
+	}
+
+	req, err := helper.NewDeactivateRequest(&helper.DeactivateRequestInfo{
+		DidSuffix:   parsedDID.Suffix,
+		RecoveryKey: recoveryKey,
+		Signer:      deactivateDIDOpts.signer,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build sidetree deactivate request: %w", err)
+	}
+
+	_, err = c.sendRequest(context.Background(), req, sidetreeEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to send deactivate sidetree request: %w", err)
+	}
+
+	return nil
+}
+
+func buildRecoverRequest(didSuffix string, recoverDIDOpts *RecoverDIDOpts) ([]byte, error) {
+	if recoverDIDOpts.signer == nil {
+		return nil, errors.New("signing key is required")
+	}
+
+	doc, err := recoverDocument(recoverDIDOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryKey, err := pubkey.GetPublicKeyJWK(recoverDIDOpts.recoveryPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recovery public key: %w", err)
+	}
+
+	recoveryCommitment, err := Commitment(recoverDIDOpts.nextRecoveryPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next recovery public key: %w", err)
+	}
+
+	updateCommitment, err := Commitment(recoverDIDOpts.nextUpdatePublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next update public key: %w", err)
+	}
+
+	return helper.NewRecoverRequest(&helper.RecoverRequestInfo{
+		DidSuffix:          didSuffix,
+		RecoveryKey:        recoveryKey,
+		OpaqueDocument:     string(doc),
+		RecoveryCommitment: recoveryCommitment,
+		UpdateCommitment:   updateCommitment,
+		MultihashCode:      sha2_256,
+		Signer:             recoverDIDOpts.signer,
+	})
+}
+
+// recoverDocument returns the document a DID is recovered with
+func recoverDocument(recoverDIDOpts *RecoverDIDOpts) ([]byte, error) {
+	parsedKeys, err := unwrapPublicKeys(recoverDIDOpts.publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Doc{PublicKey: parsedKeys, Service: recoverDIDOpts.services}
+
+	if err = ValidateDocument(doc); err != nil {
+		return nil, err
+	}
+
+	return doc.JSONBytes()
+}
+
+// RecoverDIDOpts recover did opts
+type RecoverDIDOpts struct {
+	publicKeys            []PublicKey
+	services              []docdid.Service
+	signer                helper.Signer
+	recoveryPublicKey     interface{}
+	nextRecoveryPublicKey interface{}
+	nextUpdatePublicKey   interface{}
+	sidetreeEndpoint      string
+}
+
+// RecoverDIDOption is a recover DID option
+type RecoverDIDOption func(opts *RecoverDIDOpts)
+
+// WithRecoverPublicKey adds a public key to the recovered DID document
+func WithRecoverPublicKey(publicKey *PublicKey) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.publicKeys = append(opts.publicKeys, *publicKey)
+	}
+}
+
+// WithRecoverService adds a service to the recovered DID document
+func WithRecoverService(service *docdid.Service) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.services = append(opts.services, *service)
+	}
+}
+
+// WithRecoverySigningKey signs the recovery with the given signer, holding the private key of the current recovery
+// public key of the DID
+func WithRecoverySigningKey(signer helper.Signer, recoveryPublicKey interface{}) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.signer = signer
+		opts.recoveryPublicKey = recoveryPublicKey
+	}
+}
+
+// WithNextRecoveryPublicKey sets the public key the next recovery of the DID must be signed with
+func WithNextRecoveryPublicKey(nextRecoveryPublicKey interface{}) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.nextRecoveryPublicKey = nextRecoveryPublicKey
+	}
+}
+
+// WithRecoverNextUpdatePublicKey sets the public key the next update of the recovered DID must be signed with
+func WithRecoverNextUpdatePublicKey(nextUpdatePublicKey interface{}) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.nextUpdatePublicKey = nextUpdatePublicKey
+	}
+}
+
+// WithRecoverSidetreeEndpoint sends the recovery directly to the given sidetree endpoint
+func WithRecoverSidetreeEndpoint(sidetreeEndpoint string) RecoverDIDOption {
+	return func(opts *RecoverDIDOpts) {
+		opts.sidetreeEndpoint = sidetreeEndpoint
+	}
+}
+
+// DeactivateDIDOpts deactivate did opts
+type DeactivateDIDOpts struct {
+	signer            helper.Signer
+	recoveryPublicKey interface{}
+	sidetreeEndpoint  string
+}
+
+// DeactivateDIDOption is a deactivate DID option
+type DeactivateDIDOption func(opts *DeactivateDIDOpts)
+
+// WithDeactivateSigningKey signs the deactivation with the given signer, holding the private key of the current
+// recovery public key of the DID
+func WithDeactivateSigningKey(signer helper.Signer, recoveryPublicKey interface{}) DeactivateDIDOption {
+	return func(opts *DeactivateDIDOpts) {
+		opts.signer = signer
+		opts.recoveryPublicKey = recoveryPublicKey
+	}
+}
+
+// WithDeactivateSidetreeEndpoint sends the deactivation directly to the given sidetree endpoint
+func WithDeactivateSidetreeEndpoint(sidetreeEndpoint string) DeactivateDIDOption {
+	return func(opts *DeactivateDIDOpts) {
+		opts.sidetreeEndpoint = sidetreeEndpoint
+	}
+}
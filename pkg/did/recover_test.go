@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// operationServer serves sidetree operations, capturing the requests of the given type
+func operationServer(t *testing.T, operation model.OperationType, req interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		op := &struct {
+			Operation model.OperationType `json:"type"`
+		}{}
+		require.NoError(t, json.Unmarshal(body, op))
+		require.Equal(t, operation, op.Operation)
+		require.NoError(t, json.Unmarshal(body, req))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestClient_RecoverDID(t *testing.T) {
+	keys, err := NewOperationKeys()
+	require.NoError(t, err)
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key := &PublicKey{ID: "key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+		KeyType: Ed25519KeyType, Value: pubKey, Purpose: []string{KeyPurposeGeneral}}
+	service := &docdid.Service{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com"}
+
+	t.Run("success", func(t *testing.T) {
+		req := &model.RecoverRequest{}
+		serv := operationServer(t, model.OperationTypeRecover, req)
+
+		defer serv.Close()
+
+		c := New()
+		c.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				require.Equal(t, "testnet.example.com", domain)
+
+				return []*models.Endpoint{{URL: serv.URL}}, nil
+			}}
+
+		opts, next, err := keys.RotateRecoveryKey(recoveryKeyID)
+		require.NoError(t, err)
+		require.NotEqual(t, keys.Recovery.Commitment, next.Recovery.Commitment)
+		require.NotEqual(t, keys.Update.Commitment, next.Update.Commitment)
+
+		opts = append(opts, WithRecoverPublicKey(key), WithRecoverService(service))
+
+		require.NoError(t, c.RecoverDID(updateTestDID, opts...))
+		require.Equal(t, "EiAtestsuffix", req.DidSuffix)
+		require.NotEmpty(t, req.SignedData)
+
+		deltaBytes, err := docutil.DecodeString(req.Delta)
+		require.NoError(t, err)
+
+		delta := &model.DeltaModel{}
+		require.NoError(t, json.Unmarshal(deltaBytes, delta))
+		require.Equal(t, next.Update.Commitment, delta.UpdateCommitment)
+		require.Len(t, delta.Patches, 2)
+		require.ElementsMatch(t, []string{"add-public-keys", "add-service-endpoints"},
+			[]string{fmt.Sprint(delta.Patches[0]["action"]), fmt.Sprint(delta.Patches[1]["action"])})
+	})
+
+	t.Run("failure - invalid DID", func(t *testing.T) {
+		err := New().RecoverDID("did:trustbloc:EiAtestsuffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:trustbloc DID")
+	})
+
+	t.Run("failure - get endpoints", func(t *testing.T) {
+		c := New()
+		c.endpointService = endpoint.NewService(discoveryMock(nil, nil), selectionMock(nil, nil))
+
+		err := c.RecoverDID(updateTestDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list of endpoints is empty")
+	})
+
+	t.Run("failure - keys", func(t *testing.T) {
+		opts := []RecoverDIDOption{WithRecoverPublicKey(key),
+			WithRecoverSidetreeEndpoint("https://sidetree.example.com")}
+
+		err := New().RecoverDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signing key is required")
+
+		opts = append(opts, WithRecoverySigningKey(keys.Recovery.Signer(recoveryKeyID), "wrong"))
+
+		err = New().RecoverDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid recovery public key")
+
+		opts = append(opts, WithRecoverySigningKey(keys.Recovery.Signer(recoveryKeyID), keys.Recovery.PublicKey()))
+
+		err = New().RecoverDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid next recovery public key")
+
+		opts = append(opts, WithNextRecoveryPublicKey(pubKey))
+
+		err = New().RecoverDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid next update public key")
+	})
+
+	t.Run("failure - invalid document", func(t *testing.T) {
+		opts, _, err := keys.RotateRecoveryKey(recoveryKeyID)
+		require.NoError(t, err)
+
+		opts = append(opts, WithRecoverService(&docdid.Service{ID: "agent"}),
+			WithRecoverSidetreeEndpoint("https://sidetree.example.com"))
+
+		err = New().RecoverDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service[0]: service type is missing")
+	})
+
+	t.Run("failure - sidetree error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+
+		defer serv.Close()
+
+		opts, _, err := keys.RotateRecoveryKey(recoveryKeyID)
+		require.NoError(t, err)
+
+		err = New().RecoverDID(updateTestDID, append(opts, WithRecoverPublicKey(key),
+			WithRecoverSidetreeEndpoint(serv.URL))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send recover sidetree request")
+	})
+}
+
+func TestClient_DeactivateDID(t *testing.T) {
+	keys, err := NewOperationKeys()
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		req := &model.DeactivateRequest{}
+		serv := operationServer(t, model.OperationTypeDeactivate, req)
+
+		defer serv.Close()
+
+		opts := append(keys.DeactivateDIDOptions(recoveryKeyID), WithDeactivateSidetreeEndpoint(serv.URL))
+
+		require.NoError(t, New().DeactivateDID(updateTestDID, opts...))
+		require.Equal(t, "EiAtestsuffix", req.DidSuffix)
+		require.NotEmpty(t, req.SignedData)
+	})
+
+	t.Run("failure - invalid DID", func(t *testing.T) {
+		err := New().DeactivateDID("did:trustbloc:EiAtestsuffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:trustbloc DID")
+	})
+
+	t.Run("failure - get endpoints", func(t *testing.T) {
+		c := New()
+		c.endpointService = endpoint.NewService(discoveryMock(nil, fmt.Errorf("discover error")),
+			selectionMock(nil, nil))
+
+		err := c.DeactivateDID(updateTestDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discover error")
+	})
+
+	t.Run("failure - keys", func(t *testing.T) {
+		err := New().DeactivateDID(updateTestDID, WithDeactivateSidetreeEndpoint("https://sidetree.example.com"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signing key is required")
+
+		err = New().DeactivateDID(updateTestDID, WithDeactivateSidetreeEndpoint("https://sidetree.example.com"),
+			WithDeactivateSigningKey(keys.Recovery.Signer(recoveryKeyID), "wrong"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid recovery public key")
+	})
+
+	t.Run("failure - sidetree error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+
+		defer serv.Close()
+
+		opts := append(keys.DeactivateDIDOptions(recoveryKeyID), WithDeactivateSidetreeEndpoint(serv.URL))
+
+		err := New().DeactivateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send deactivate sidetree request")
+	})
+}
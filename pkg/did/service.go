@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+const (
+	// DIDCommServiceType is the type of DIDComm v1 services
+	DIDCommServiceType = "did-communication"
+
+	// DIDCommMessagingServiceType is the type of DIDComm v2 services
+	DIDCommMessagingServiceType = "DIDCommMessaging"
+
+	// LinkedDomainsServiceType is the type of the services linking a DID to the domains of its controller
+	LinkedDomainsServiceType = "LinkedDomains"
+
+	jsonldAccept = "accept"
+)
+
+// DIDCommEndpoint is the serviceEndpoint object of DIDComm v2 services
+type DIDCommEndpoint struct {
+	URI         string
+	Accept      []string
+	RoutingKeys []string
+}
+
+// NewDIDCommService returns a DIDComm v1 service: messages to the DID are packed for the recipient keys, and
+// forwarded through the mediators of the routing keys. Agents pick the services with the lowest priority first.
+func NewDIDCommService(id, endpoint string, recipientKeys, routingKeys []string, priority uint) *docdid.Service {
+	return &docdid.Service{ID: id, Type: DIDCommServiceType, ServiceEndpoint: endpoint,
+		RecipientKeys: recipientKeys, RoutingKeys: routingKeys, Priority: priority}
+}
+
+// NewDIDCommMessagingService returns a DIDComm v2 service with the given serviceEndpoint object. As sidetree nodes
+// and aries DID docs take string endpoints, the members of the object are set as the endpoint, the routing keys and
+// the accept property of the service.
+func NewDIDCommMessagingService(id string, endpoint *DIDCommEndpoint) *docdid.Service {
+	service := &docdid.Service{ID: id, Type: DIDCommMessagingServiceType, ServiceEndpoint: endpoint.URI,
+		RoutingKeys: endpoint.RoutingKeys}
+
+	if len(endpoint.Accept) > 0 {
+		service.Properties = map[string]interface{}{jsonldAccept: endpoint.Accept}
+	}
+
+	return service
+}
+
+// NewLinkedDomainsService returns a LinkedDomains service, linking the DID to the origin of a domain whose DID
+// configuration lists the DID
+func NewLinkedDomainsService(id, origin string) *docdid.Service {
+	return &docdid.Service{ID: id, Type: LinkedDomainsServiceType, ServiceEndpoint: origin}
+}
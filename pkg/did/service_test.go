@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceBuilders(t *testing.T) {
+	t.Run("DIDComm v1", func(t *testing.T) {
+		service := NewDIDCommService("agent", "https://agent.example.com", []string{"key1"},
+			[]string{"did:example:mediator#key1"}, 1)
+
+		require.Equal(t, &docdid.Service{ID: "agent", Type: "did-communication",
+			ServiceEndpoint: "https://agent.example.com", RecipientKeys: []string{"key1"},
+			RoutingKeys: []string{"did:example:mediator#key1"}, Priority: 1}, service)
+		require.NoError(t, ValidateDocument(&Doc{Service: []docdid.Service{*service}}))
+	})
+
+	t.Run("DIDComm v2", func(t *testing.T) {
+		service := NewDIDCommMessagingService("messaging", &DIDCommEndpoint{URI: "https://agent.example.com",
+			Accept: []string{"didcomm/v2"}, RoutingKeys: []string{"did:example:mediator#key1"}})
+		require.NoError(t, ValidateDocument(&Doc{Service: []docdid.Service{*service}}))
+
+		data, err := (&Doc{Service: []docdid.Service{*service}}).JSONBytes()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"service":[{"id":"messaging","type":"DIDCommMessaging",
+			"endpoint":"https://agent.example.com","accept":["didcomm/v2"],
+			"routingKeys":["did:example:mediator#key1"],"recipientKeys":null,"priority":0}]}`, string(data))
+
+		service = NewDIDCommMessagingService("messaging", &DIDCommEndpoint{URI: "https://agent.example.com"})
+		require.Nil(t, service.Properties)
+	})
+
+	t.Run("LinkedDomains", func(t *testing.T) {
+		service := NewLinkedDomainsService("domains", "https://example.com")
+
+		require.Equal(t, &docdid.Service{ID: "domains", Type: "LinkedDomains",
+			ServiceEndpoint: "https://example.com"}, service)
+		require.NoError(t, ValidateDocument(&Doc{Service: []docdid.Service{*service}}))
+	})
+}
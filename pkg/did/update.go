@@ -0,0 +1,457 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/jsoncanonicalizer"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// UpdateDID updates a DID with the patches given as options, which are sent in a single update and applied in order.
+// The update is signed with the current update key of the DID, and commits to the next update key.
+func (c *Client) UpdateDID(didID string, opts ...UpdateDIDOption) error {
+	updateDIDOpts := &UpdateDIDOpts{}
+	// Apply options
+	for _, opt := range opts {
+		opt(updateDIDOpts)
+	}
+
+	parsedDID, err := models.ParseDID(didID)
+	if err != nil {
+		return err
+	}
+
+	sidetreeEndpoint := updateDIDOpts.sidetreeEndpoint
+
+	if sidetreeEndpoint == "" {
+		sidetreeEndpoint, err = c.sidetreeEndpoint(parsedDID.Domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := buildUpdateRequest(parsedDID.Suffix, updateDIDOpts)
+	if err != nil {
+		return fmt.Errorf("failed to build sidetree update request: %w", err)
+	}
+
+	_, err = c.sendRequest(context.Background(), req, sidetreeEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to send update sidetree request: %w", err)
+	}
+
+	return nil
+}
+
+// sidetreeEndpoint returns the first sidetree endpoint of a consortium
+func (c *Client) sidetreeEndpoint(domain string) (string, error) {
+	endpoints, err := c.endpointService.GetEndpoints(domain)
+	if err != nil {
+		return "", fmt.Errorf("failed to get endpoints: %w", err)
+	}
+
+	if len(endpoints) == 0 {
+		return "", errors.New("list of endpoints is empty")
+	}
+
+	return endpoints[0].URL, nil
+}
+
+func buildUpdateRequest(didSuffix string, updateDIDOpts *UpdateDIDOpts) ([]byte, error) {
+	if len(updateDIDOpts.patches) == 0 {
+		return nil, errors.New("at least one patch is required")
+	}
+
+	patches := make([]patch.Patch, len(updateDIDOpts.patches))
+
+	for i, newPatch := range updateDIDOpts.patches {
+		p, err := newPatch()
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: %w", err)
+		}
+
+		patches[i] = p
+	}
+
+	if updateDIDOpts.signer == nil {
+		return nil, errors.New("signing key is required")
+	}
+
+	updateKey, err := pubkey.GetPublicKeyJWK(updateDIDOpts.updatePublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update public key: %w", err)
+	}
+
+	nextUpdateKey, err := pubkey.GetPublicKeyJWK(updateDIDOpts.nextUpdatePublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next update public key: %w", err)
+	}
+
+	updateCommitment, err := commitment.Calculate(nextUpdateKey, sha2_256)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUpdateRequest(&updateRequestInfo{
+		didSuffix:        didSuffix,
+		patches:          patches,
+		updateCommitment: updateCommitment,
+		updateKey:        updateKey,
+		signer:           updateDIDOpts.signer,
+	})
+}
+
+// updateRequestInfo holds the fields of a sidetree update request
+type updateRequestInfo struct {
+	didSuffix        string
+	patches          []patch.Patch
+	updateCommitment string
+	updateKey        *jws.JWK
+	signer           helper.Signer
+}
+
+// newUpdateRequest builds a sidetree update request whose delta, signed with the update key, carries all the given
+// patches, which sidetree applies in order. The request helper of sidetree only builds updates with a single patch.
+func newUpdateRequest(info *updateRequestInfo) ([]byte, error) {
+	if err := validateSigner(info.signer); err != nil {
+		return nil, err
+	}
+
+	delta, err := canonicalJSON(&model.DeltaModel{UpdateCommitment: info.updateCommitment, Patches: info.patches})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delta: %w", err)
+	}
+
+	deltaHash, err := docutil.ComputeMultihash(sha2_256, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData, err := signCompact(&model.UpdateSignedDataModel{
+		UpdateKey: info.updateKey,
+		DeltaHash: docutil.EncodeToString(deltaHash),
+	}, info.signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign update: %w", err)
+	}
+
+	return canonicalJSON(&model.UpdateRequest{
+		Operation:  model.OperationTypeUpdate,
+		DidSuffix:  info.didSuffix,
+		Delta:      docutil.EncodeToString(delta),
+		SignedData: signedData,
+	})
+}
+
+// validateSigner checks the protected headers of a signer, which sidetree restricts to its kid and alg
+func validateSigner(signer helper.Signer) error {
+	headers := signer.Headers()
+
+	if _, ok := headers.KeyID(); !ok {
+		return errors.New("kid must be present in the protected header")
+	}
+
+	if alg, ok := headers.Algorithm(); !ok || alg == "" {
+		return errors.New("algorithm must be present in the protected header")
+	}
+
+	if len(headers) != 2 { // nolint: gomnd
+		return errors.New("protected headers can only contain kid and alg")
+	}
+
+	return nil
+}
+
+// signCompact signs the canonical JSON of a model into a compact JWS, as sidetree signs the data of operations
+func signCompact(v interface{}, signer helper.Signer) (string, error) {
+	payload, err := canonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+
+	headers, err := json.Marshal(signer.Headers())
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headers) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// canonicalJSON marshals a value to JSON canonicalized with JCS, as sidetree hashes and signs it
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsoncanonicalizer.Transform(data)
+}
+
+func addPublicKeysPatch(publicKeys []PublicKey) (patch.Patch, error) {
+	parsedKeys, err := unwrapPublicKeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ValidateDocument(&Doc{PublicKey: parsedKeys}); err != nil {
+		return nil, err
+	}
+
+	rawKeys, err := populateRawPublicKeys(parsedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	rawPublicKeys, err := json.Marshal(rawKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.NewAddPublicKeysPatch(string(rawPublicKeys))
+}
+
+func addServicesPatch(services []docdid.Service) (patch.Patch, error) {
+	if err := ValidateDocument(&Doc{Service: services}); err != nil {
+		return nil, err
+	}
+
+	rawServices, err := json.Marshal(populateRawServices(services))
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.NewAddServiceEndpointsPatch(string(rawServices))
+}
+
+func removeIDsPatch(newPatch func(string) (patch.Patch, error), ids []string) (patch.Patch, error) {
+	if err := validateIDs(ids); err != nil {
+		return nil, err
+	}
+
+	rawIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPatch(string(rawIDs))
+}
+
+// replacePatch returns a patch replacing the whole document with the public keys and services of the given doc
+func replacePatch(doc *Doc) (patch.Patch, error) {
+	parsedKeys, err := unwrapPublicKeys(doc.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedDoc := *doc
+	parsedDoc.PublicKey = parsedKeys
+
+	if err = ValidateDocument(&parsedDoc); err != nil {
+		return nil, err
+	}
+
+	publicKeys, err := populateRawPublicKeys(parsedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	replaceDoc, err := json.Marshal(map[string]interface{}{
+		document.ReplacePublicKeyProperty: publicKeys,
+		document.ReplaceServiceProperty:   populateRawServices(doc.Service),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.NewReplacePatch(string(replaceDoc))
+}
+
+// OperationMode defines how the public keys and services of a doc apply to the ones of a DID document
+type OperationMode string
+
+const (
+	// OperationModeAdd appends the public keys and services to the ones of the DID document, replacing the ones
+	// with the same id
+	OperationModeAdd OperationMode = "add"
+	// OperationModeReplace replaces the public keys and services of the DID document
+	OperationModeReplace OperationMode = "replace"
+)
+
+// DocumentPatches returns the sidetree patches applying the public keys and services of the doc to a DID document
+// in the given mode: a replace patch, or add-public-keys and add-service-endpoints patches, sent in one update with
+// WithPatch.
+func DocumentPatches(doc *Doc, mode OperationMode) ([]patch.Patch, error) {
+	switch mode {
+	case OperationModeReplace:
+		p, err := replacePatch(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return []patch.Patch{p}, nil
+	case OperationModeAdd:
+		var patches []patch.Patch
+
+		if len(doc.PublicKey) > 0 {
+			p, err := addPublicKeysPatch(doc.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+
+			patches = append(patches, p)
+		}
+
+		if len(doc.Service) > 0 {
+			p, err := addServicesPatch(doc.Service)
+			if err != nil {
+				return nil, err
+			}
+
+			patches = append(patches, p)
+		}
+
+		return patches, nil
+	default:
+		return nil, fmt.Errorf("unsupported operation mode: %s", mode)
+	}
+}
+
+// UpdateDIDOpts update did opts
+type UpdateDIDOpts struct {
+	patches             []func() (patch.Patch, error)
+	signer              helper.Signer
+	updatePublicKey     interface{}
+	nextUpdatePublicKey interface{}
+	sidetreeEndpoint    string
+}
+
+// UpdateDIDOption is an update DID option
+type UpdateDIDOption func(opts *UpdateDIDOpts)
+
+// WithSigningKey signs the update with the given signer, holding the private key of the current update public key
+// of the DID
+func WithSigningKey(signer helper.Signer, updatePublicKey interface{}) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.signer = signer
+		opts.updatePublicKey = updatePublicKey
+	}
+}
+
+// WithNextUpdatePublicKey sets the public key the next update of the DID must be signed with
+func WithNextUpdatePublicKey(nextUpdatePublicKey interface{}) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.nextUpdatePublicKey = nextUpdatePublicKey
+	}
+}
+
+// WithUpdateSidetreeEndpoint sends the update directly to the given sidetree endpoint
+func WithUpdateSidetreeEndpoint(sidetreeEndpoint string) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.sidetreeEndpoint = sidetreeEndpoint
+	}
+}
+
+// WithAddPublicKeys adds public keys to the DID document
+func WithAddPublicKeys(publicKeys ...*PublicKey) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		keys := make([]PublicKey, len(publicKeys))
+		for i, pk := range publicKeys {
+			keys[i] = *pk
+		}
+
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return addPublicKeysPatch(keys)
+		})
+	}
+}
+
+// WithRemovePublicKeys removes the public keys with the given IDs from the DID document
+func WithRemovePublicKeys(ids ...string) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return removeIDsPatch(patch.NewRemovePublicKeysPatch, ids)
+		})
+	}
+}
+
+// WithAddServices adds services to the DID document
+func WithAddServices(services ...*docdid.Service) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		svcs := make([]docdid.Service, len(services))
+		for i, s := range services {
+			svcs[i] = *s
+		}
+
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return addServicesPatch(svcs)
+		})
+	}
+}
+
+// WithRemoveServices removes the services with the given IDs from the DID document
+func WithRemoveServices(ids ...string) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return removeIDsPatch(patch.NewRemoveServiceEndpointsPatch, ids)
+		})
+	}
+}
+
+// WithReplaceDocument replaces the public keys and services of the DID document with the ones of the given doc,
+// for callers managing DID documents declaratively. Recovery and update keys of the doc are ignored.
+func WithReplaceDocument(doc *Doc) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return replacePatch(doc)
+		})
+	}
+}
+
+// WithPatch applies the given sidetree patches to the DID document, eg. the patches returned by DiffDocs
+func WithPatch(patches ...patch.Patch) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		for _, p := range patches {
+			p := p
+
+			opts.patches = append(opts.patches, func() (patch.Patch, error) {
+				return p, nil
+			})
+		}
+	}
+}
+
+// WithJSONPatch applies the given IETF JSON Patch (RFC 6902) operations to the DID document, for mutations the
+// other patches can't express. Sidetree rejects JSON patches modifying the public keys or services of the document.
+func WithJSONPatch(patches string) UpdateDIDOption {
+	return func(opts *UpdateDIDOpts) {
+		opts.patches = append(opts.patches, func() (patch.Patch, error) {
+			return patch.NewJSONPatch(patches)
+		})
+	}
+}
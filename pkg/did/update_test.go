@@ -0,0 +1,446 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const updateTestDID = "did:trustbloc:testnet.example.com:EiAtestsuffix"
+
+// updateServer serves sidetree operations, capturing the delta of update requests
+func updateServer(t *testing.T, delta *model.DeltaModel) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req := &model.UpdateRequest{}
+		require.NoError(t, json.Unmarshal(body, req))
+		require.Equal(t, model.OperationTypeUpdate, req.Operation)
+		require.Equal(t, "EiAtestsuffix", req.DidSuffix)
+
+		deltaBytes, err := docutil.DecodeString(req.Delta)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(deltaBytes, delta))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func signingOpts(t *testing.T) []UpdateDIDOption {
+	updatePubKey, updatePrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	nextUpdatePubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return []UpdateDIDOption{
+		WithSigningKey(edsigner.New(updatePrivKey, "EdDSA", updateKeyID), updatePubKey),
+		WithNextUpdatePublicKey(nextUpdatePubKey),
+	}
+}
+
+func TestClient_UpdateDID(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key := &PublicKey{ID: "key2", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+		KeyType: Ed25519KeyType, Value: pubKey, Purpose: []string{KeyPurposeGeneral}}
+	service := &docdid.Service{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com"}
+
+	removePatch, err := patch.NewRemovePublicKeysPatch(`["key1"]`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		patch  UpdateDIDOption
+		action string
+	}{
+		{name: "add public keys", patch: WithAddPublicKeys(key), action: "add-public-keys"},
+		{name: "remove public keys", patch: WithRemovePublicKeys("key1"), action: "remove-public-keys"},
+		{name: "add services", patch: WithAddServices(service), action: "add-service-endpoints"},
+		{name: "remove services", patch: WithRemoveServices("agent"), action: "remove-service-endpoints"},
+		{name: "replace document", patch: WithReplaceDocument(&Doc{PublicKey: []PublicKey{*key},
+			Service: []docdid.Service{*service}}), action: "replace"},
+		{name: "json patch", patch: WithJSONPatch(`[{"op":"add","path":"/alsoKnownAs","value":["https://example.com"]}]`),
+			action: "ietf-json-patch"},
+		{name: "patch", patch: WithPatch(removePatch), action: "remove-public-keys"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run("success - "+tc.name, func(t *testing.T) {
+			delta := &model.DeltaModel{}
+			serv := updateServer(t, delta)
+
+			defer serv.Close()
+
+			opts := append(signingOpts(t), tc.patch, WithUpdateSidetreeEndpoint(serv.URL))
+
+			require.NoError(t, New().UpdateDID(updateTestDID, opts...))
+			require.Len(t, delta.Patches, 1)
+			require.Equal(t, tc.action, fmt.Sprint(delta.Patches[0]["action"]))
+			require.NotEmpty(t, delta.UpdateCommitment)
+		})
+	}
+
+	t.Run("success - patches in one update", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := updateServer(t, delta)
+
+		defer serv.Close()
+
+		addPatch, err := patch.NewAddServiceEndpointsPatch(
+			`[{"id":"agent2","type":"did-communication","endpoint":"https://agent2.example.com"}]`)
+		require.NoError(t, err)
+
+		opts := append(signingOpts(t), WithRemovePublicKeys("key1"), WithAddServices(service),
+			WithPatch(removePatch, addPatch), WithUpdateSidetreeEndpoint(serv.URL))
+
+		require.NoError(t, New().UpdateDID(updateTestDID, opts...))
+		require.Len(t, delta.Patches, 4)
+
+		var actions []string
+		for _, p := range delta.Patches {
+			actions = append(actions, fmt.Sprint(p["action"]))
+		}
+
+		require.Equal(t, []string{"remove-public-keys", "add-service-endpoints", "remove-public-keys",
+			"add-service-endpoints"}, actions)
+	})
+
+	t.Run("success - add public keys without their controller and extension properties", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := updateServer(t, delta)
+
+		defer serv.Close()
+
+		withProperties := *key
+		withProperties.Controller = "did:example:controller"
+		withProperties.Properties = map[string]interface{}{"expires": "2030-01-01T00:00:00Z"}
+
+		opts := append(signingOpts(t), WithAddPublicKeys(&withProperties), WithUpdateSidetreeEndpoint(serv.URL))
+
+		require.NoError(t, New().UpdateDID(updateTestDID, opts...))
+		require.Len(t, delta.Patches, 1)
+
+		addedKeys, ok := delta.Patches[0].GetValue(patch.PublicKeys).([]interface{})
+		require.True(t, ok)
+		require.Len(t, addedKeys, 1)
+
+		addedKey, ok := addedKeys[0].(map[string]interface{})
+		require.True(t, ok)
+		require.Len(t, addedKey, 4)
+		require.NotContains(t, addedKey, "controller")
+		require.NotContains(t, addedKey, "expires")
+	})
+
+	t.Run("success - replace document content", func(t *testing.T) {
+		delta := &model.DeltaModel{}
+		serv := updateServer(t, delta)
+
+		defer serv.Close()
+
+		c := New()
+		c.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) ([]*models.Endpoint, error) {
+				require.Equal(t, "testnet.example.com", domain)
+
+				return []*models.Endpoint{{URL: serv.URL}}, nil
+			}}
+
+		opts := append(signingOpts(t), WithReplaceDocument(&Doc{PublicKey: []PublicKey{*key}}))
+
+		require.NoError(t, c.UpdateDID(updateTestDID, opts...))
+
+		doc, ok := delta.Patches[0]["document"].(map[string]interface{})
+		require.True(t, ok)
+		require.Len(t, doc["public_keys"], 1)
+	})
+
+	t.Run("failure - invalid DID", func(t *testing.T) {
+		err := New().UpdateDID("did:trustbloc:EiAtestsuffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:trustbloc DID")
+	})
+
+	t.Run("failure - get endpoints", func(t *testing.T) {
+		c := New()
+		c.endpointService = endpoint.NewService(discoveryMock(nil, fmt.Errorf("discover error")),
+			selectionMock(nil, nil))
+
+		err := c.UpdateDID(updateTestDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discover error")
+
+		c.endpointService = endpoint.NewService(discoveryMock(nil, nil), selectionMock(nil, nil))
+
+		err = c.UpdateDID(updateTestDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list of endpoints is empty")
+	})
+
+	t.Run("failure - patches", func(t *testing.T) {
+		opts := append(signingOpts(t), WithUpdateSidetreeEndpoint("https://sidetree.example.com"))
+
+		err := New().UpdateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one patch is required")
+
+		err = New().UpdateDID(updateTestDID, append(opts, WithRemovePublicKeys())...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid patch: missing public key ids")
+
+		err = New().UpdateDID(updateTestDID, append(opts, WithJSONPatch(`{"op":"add"}`))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid patch: ietf-json-patch")
+
+		err = New().UpdateDID(updateTestDID,
+			append(opts, WithJSONPatch(`[{"op":"replace","path":"/service/0/endpoint","value":"https://x.com"}]`))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot modify services")
+
+		err = New().UpdateDID(updateTestDID, append(opts, WithRemoveServices("#agent"))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid patch: invalid document: ids[0]")
+
+		err = New().UpdateDID(updateTestDID, append(opts, WithAddServices(&docdid.Service{ID: "agent"}))...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service[0]: service type is missing")
+	})
+
+	t.Run("failure - keys", func(t *testing.T) {
+		opts := []UpdateDIDOption{WithRemovePublicKeys("key1"),
+			WithUpdateSidetreeEndpoint("https://sidetree.example.com")}
+
+		err := New().UpdateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signing key is required")
+
+		_, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		opts = append(opts, WithSigningKey(edsigner.New(privKey, "EdDSA", updateKeyID), "wrong"))
+
+		err = New().UpdateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid update public key")
+
+		opts = append(opts, WithSigningKey(edsigner.New(privKey, "EdDSA", updateKeyID), privKey.Public()))
+
+		err = New().UpdateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid next update public key")
+	})
+
+	t.Run("failure - sidetree error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+
+		defer serv.Close()
+
+		opts := append(signingOpts(t), WithRemovePublicKeys("key1"), WithUpdateSidetreeEndpoint(serv.URL))
+
+		err := New().UpdateDID(updateTestDID, opts...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send update sidetree request")
+	})
+}
+
+func TestDocumentPatches(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	doc := &Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+		KeyType: Ed25519KeyType, Value: pubKey, Purpose: []string{KeyPurposeGeneral}}},
+		Service: []docdid.Service{{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com"}}}
+
+	t.Run("success - replace", func(t *testing.T) {
+		patches, err := DocumentPatches(doc, OperationModeReplace)
+		require.NoError(t, err)
+		require.Len(t, patches, 1)
+		require.Equal(t, patch.Replace, patches[0][patch.ActionKey])
+
+		patches, err = DocumentPatches(&Doc{}, OperationModeReplace)
+		require.NoError(t, err)
+		require.Len(t, patches, 1)
+	})
+
+	t.Run("success - add", func(t *testing.T) {
+		patches, err := DocumentPatches(doc, OperationModeAdd)
+		require.NoError(t, err)
+		require.Len(t, patches, 2)
+		require.Equal(t, patch.AddPublicKeys, patches[0][patch.ActionKey])
+		require.Equal(t, patch.AddServiceEndpoints, patches[1][patch.ActionKey])
+
+		patches, err = DocumentPatches(&Doc{Service: doc.Service}, OperationModeAdd)
+		require.NoError(t, err)
+		require.Len(t, patches, 1)
+		require.Equal(t, patch.AddServiceEndpoints, patches[0][patch.ActionKey])
+
+		patches, err = DocumentPatches(&Doc{}, OperationModeAdd)
+		require.NoError(t, err)
+		require.Empty(t, patches)
+	})
+
+	t.Run("failure - invalid document", func(t *testing.T) {
+		invalid := &Doc{Service: []docdid.Service{{ID: "agent"}}}
+
+		for _, mode := range []OperationMode{OperationModeAdd, OperationModeReplace} {
+			_, err := DocumentPatches(invalid, mode)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "service[0]: service type is missing")
+		}
+
+		_, err := DocumentPatches(&Doc{PublicKey: []PublicKey{{ID: "key1"}}}, OperationModeAdd)
+		require.Error(t, err)
+	})
+
+	t.Run("failure - unsupported mode", func(t *testing.T) {
+		_, err := DocumentPatches(doc, "merge")
+		require.EqualError(t, err, "unsupported operation mode: merge")
+	})
+}
+
+func TestNewUpdateRequest(t *testing.T) {
+	updatePubKey, updatePrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	updateKey, err := pubkey.GetPublicKeyJWK(updatePubKey)
+	require.NoError(t, err)
+
+	signer := edsigner.New(updatePrivKey, "EdDSA", updateKeyID)
+
+	removePatch, err := patch.NewRemovePublicKeysPatch(`["key1"]`)
+	require.NoError(t, err)
+
+	t.Run("success - same request as the sidetree helper for a single patch", func(t *testing.T) {
+		req, err := newUpdateRequest(&updateRequestInfo{didSuffix: "EiAtestsuffix", patches: []patch.Patch{removePatch},
+			updateCommitment: "commitment", updateKey: updateKey, signer: signer})
+		require.NoError(t, err)
+
+		expected, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{DidSuffix: "EiAtestsuffix",
+			Patch: removePatch, UpdateCommitment: "commitment", UpdateKey: updateKey, MultihashCode: sha2_256,
+			Signer: signer})
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(req))
+	})
+
+	t.Run("success - patches of a diff in one signed delta", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		patches, err := DiffDocs(&Doc{PublicKey: []PublicKey{{ID: "key1", Type: JWSVerificationKey2020,
+			Encoding: PublicKeyEncodingJwk, KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral}}}},
+			&Doc{Service: []docdid.Service{{ID: "agent", Type: "did-communication",
+				ServiceEndpoint: "https://agent.example.com"}}})
+		require.NoError(t, err)
+		require.Len(t, patches, 2)
+
+		reqBytes, err := newUpdateRequest(&updateRequestInfo{didSuffix: "EiAtestsuffix", patches: patches,
+			updateCommitment: "commitment", updateKey: updateKey, signer: signer})
+		require.NoError(t, err)
+
+		req := &model.UpdateRequest{}
+		require.NoError(t, json.Unmarshal(reqBytes, req))
+
+		deltaBytes, err := docutil.DecodeString(req.Delta)
+		require.NoError(t, err)
+
+		delta := &model.DeltaModel{}
+		require.NoError(t, json.Unmarshal(deltaBytes, delta))
+		require.Len(t, delta.Patches, 2)
+		require.Equal(t, "remove-public-keys", fmt.Sprint(delta.Patches[0]["action"]))
+		require.Equal(t, "add-service-endpoints", fmt.Sprint(delta.Patches[1]["action"]))
+
+		parts := strings.Split(req.SignedData, ".")
+		require.Len(t, parts, 3)
+
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(updatePubKey, []byte(parts[0]+"."+parts[1]), signature))
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+
+		signedData := &model.UpdateSignedDataModel{}
+		require.NoError(t, json.Unmarshal(payload, signedData))
+		require.Equal(t, updateKey, signedData.UpdateKey)
+
+		deltaHash, err := docutil.ComputeMultihash(sha2_256, deltaBytes)
+		require.NoError(t, err)
+		require.Equal(t, docutil.EncodeToString(deltaHash), signedData.DeltaHash)
+	})
+
+	t.Run("failure - protected headers", func(t *testing.T) {
+		for _, tc := range []struct {
+			headers jws.Headers
+			msg     string
+		}{
+			{headers: jws.Headers{jws.HeaderAlgorithm: "EdDSA"}, msg: "kid must be present"},
+			{headers: jws.Headers{jws.HeaderKeyID: "key1"}, msg: "algorithm must be present"},
+			{headers: jws.Headers{jws.HeaderKeyID: "key1", jws.HeaderAlgorithm: "EdDSA", "typ": "JWT"},
+				msg: "protected headers can only contain kid and alg"},
+		} {
+			_, err := newUpdateRequest(&updateRequestInfo{didSuffix: "EiAtestsuffix",
+				patches: []patch.Patch{removePatch}, signer: &headersSigner{Signer: signer, headers: tc.headers}})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.msg)
+		}
+	})
+
+	t.Run("failure - sign error", func(t *testing.T) {
+		_, err := newUpdateRequest(&updateRequestInfo{didSuffix: "EiAtestsuffix", patches: []patch.Patch{removePatch},
+			signer: &headersSigner{Signer: signer, headers: signer.Headers(), err: errors.New("sign error")}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to sign update: sign error")
+	})
+}
+
+// headersSigner overrides the protected headers and the result of a signer
+type headersSigner struct {
+	helper.Signer
+	headers jws.Headers
+	err     error
+}
+
+func (s *headersSigner) Headers() jws.Headers {
+	return s.headers
+}
+
+func (s *headersSigner) Sign(data []byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.Signer.Sign(data)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// limits enforced by sidetree nodes on the counts of public keys and services
+const (
+	maxPublicKeys = 50
+	maxServices   = 50
+
+	// X25519KeyAgreementKey2019 defines key type key agreement
+	X25519KeyAgreementKey2019 = "X25519KeyAgreementKey2019"
+)
+
+// ValidationError lists the problems found in a document or patch, before it is submitted to sidetree
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid document: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateDocument checks the public keys and services of the sidetree payload of a document with the validators of
+// sidetree nodes (ID charset and length, purposes allowed for the key type, JWK members, service endpoint URIs), so
+// invalid documents are rejected locally with the offending field. The counts of public keys and services and the
+// uniqueness of their IDs are checked too, key controllers must be DIDs, and contexts and alsoKnownAs identifiers
+// must be URIs. Recovery and update keys aren't part of the document and are ignored.
+func ValidateDocument(doc *Doc) error {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return err
+	}
+
+	payload, err := document.DidDocumentFromBytes(docBytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse document payload: %w", err)
+	}
+
+	var problems []string
+
+	publicKeys := payload.PublicKeys()
+	if len(publicKeys) > maxPublicKeys {
+		problems = append(problems, fmt.Sprintf("publicKey: at most %d public keys are allowed", maxPublicKeys))
+	}
+
+	services := payload.Services()
+	if len(services) > maxServices {
+		problems = append(problems, fmt.Sprintf("service: at most %d services are allowed", maxServices))
+	}
+
+	ids := map[string]bool{}
+
+	for i, pk := range publicKeys {
+		field := fmt.Sprintf("publicKey[%d]", i)
+		problems = append(problems, fieldProblems(field, document.ValidatePublicKeys([]document.PublicKey{pk}))...)
+		problems = append(problems, duplicateIDProblems(field, pk.ID(), ids)...)
+	}
+
+	for i, service := range services {
+		field := fmt.Sprintf("service[%d]", i)
+		problems = append(problems, fieldProblems(field, document.ValidateServices([]document.Service{service}))...)
+		problems = append(problems, duplicateIDProblems(field, service.ID(), ids)...)
+	}
+
+	problems = append(problems, controllerProblems(doc.PublicKey)...)
+	problems = append(problems, validateURIs("@context", doc.Context)...)
+	problems = append(problems, validateURIs("alsoKnownAs", doc.AlsoKnownAs)...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// validateIDs checks the IDs of the public keys or services removed by a patch
+func validateIDs(ids []string) error {
+	var problems []string
+
+	for i, id := range ids {
+		problems = append(problems, fieldProblems(fmt.Sprintf("ids[%d]", i), document.ValidateID(id))...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// fieldProblems returns the error of a sidetree validator as a problem of the given field
+func fieldProblems(field string, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	return []string{field + ": " + err.Error()}
+}
+
+// controllerProblems checks the controllers of the keys, which aren't part of the sidetree payload
+func controllerProblems(publicKeys []PublicKey) []string {
+	var problems []string
+
+	i := 0
+
+	for _, pk := range publicKeys {
+		if pk.Recovery || pk.Update {
+			continue
+		}
+
+		if pk.Controller != "" && !strings.HasPrefix(pk.Controller, "did:") {
+			problems = append(problems, fmt.Sprintf("publicKey[%d].controller: controller must be a DID", i))
+		}
+
+		i++
+	}
+
+	return problems
+}
+
+func duplicateIDProblems(field, id string, ids map[string]bool) []string {
+	if id == "" {
+		return nil
+	}
+
+	if ids[id] {
+		return []string{fmt.Sprintf("%s.id: duplicate id '%s'", field, id)}
+	}
+
+	ids[id] = true
+
+	return nil
+}
+
+func validateURIs(field string, uris []string) []string {
+	var problems []string
+
+	for i, uri := range uris {
+		if _, err := url.ParseRequestURI(uri); err != nil {
+			problems = append(problems, fmt.Sprintf("%s[%d]: '%s' must be a valid URI", field, i, uri))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDocument(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	validKey := func() PublicKey {
+		return PublicKey{ID: "key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: pub, Purpose: []string{KeyPurposeGeneral, KeyPurposeAuth}}
+	}
+
+	validService := func() docdid.Service {
+		return docdid.Service{ID: "agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com"}
+	}
+
+	t.Run("valid document", func(t *testing.T) {
+		require.NoError(t, ValidateDocument(&Doc{PublicKey: []PublicKey{validKey(),
+			{Type: Ed25519VerificationKey2018, Recovery: true}}, Service: []docdid.Service{validService()}}))
+	})
+
+	tests := []struct {
+		name     string
+		modify   func(doc *Doc)
+		problems []string
+	}{
+		{
+			name: "too many public keys and services",
+			modify: func(doc *Doc) {
+				for i := 0; i < maxPublicKeys; i++ {
+					key := validKey()
+					key.ID = fmt.Sprintf("key%d", i+2)
+					doc.PublicKey = append(doc.PublicKey, key)
+
+					service := validService()
+					service.ID = fmt.Sprintf("agent%d", i+2)
+					doc.Service = append(doc.Service, service)
+				}
+			},
+			problems: []string{"publicKey: at most 50 public keys are allowed",
+				"service: at most 50 services are allowed"},
+		},
+		{
+			name: "invalid ids",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].ID = "#key1"
+				doc.Service[0].ID = strings.Repeat("a", 51)
+			},
+			problems: []string{"publicKey[0]: public key: id contains invalid characters",
+				"service[0]: service: id exceeds maximum length: 50"},
+		},
+		{
+			name: "duplicate ids",
+			modify: func(doc *Doc) {
+				doc.Service[0].ID = doc.PublicKey[0].ID
+			},
+			problems: []string{"service[0].id: duplicate id 'key1'"},
+		},
+		{
+			name: "key type not allowed for purpose",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].Type = X25519KeyAgreementKey2019
+			},
+			problems: []string{"publicKey[0]: invalid key type: X25519KeyAgreementKey2019"},
+		},
+		{
+			name: "unsupported purpose",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].Purpose = []string{KeyPurposeAuth, "signing"}
+			},
+			problems: []string{"publicKey[0]: invalid purpose: signing"},
+		},
+		{
+			name: "missing purpose",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].Purpose = nil
+			},
+			problems: []string{"publicKey[0]: key 'key1' is missing purpose"},
+		},
+		{
+			name: "multibase encoded key",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].Encoding = PublicKeyEncodingMultibase
+			},
+			problems: []string{"publicKey[0]: key has to be in JWK format"},
+		},
+		{
+			name: "invalid context and alsoKnownAs",
+			modify: func(doc *Doc) {
+				doc.Context = []string{"https://example.com/context/v1", "context"}
+				doc.AlsoKnownAs = []string{"user"}
+			},
+			problems: []string{"@context[1]: 'context' must be a valid URI",
+				"alsoKnownAs[0]: 'user' must be a valid URI"},
+		},
+		{
+			name: "invalid controller",
+			modify: func(doc *Doc) {
+				doc.PublicKey[0].Controller = "controller"
+			},
+			problems: []string{"publicKey[0].controller: controller must be a DID"},
+		},
+		{
+			name: "missing service type",
+			modify: func(doc *Doc) {
+				doc.Service[0].Type = ""
+			},
+			problems: []string{"service[0]: service type is missing"},
+		},
+		{
+			name: "missing service endpoint",
+			modify: func(doc *Doc) {
+				doc.Service[0].ServiceEndpoint = ""
+			},
+			problems: []string{"service[0]: service endpoint is missing"},
+		},
+		{
+			name: "service type too long",
+			modify: func(doc *Doc) {
+				doc.Service[0].Type = strings.Repeat("a", 31)
+			},
+			problems: []string{"service[0]: service type exceeds maximum length: 30"},
+		},
+		{
+			name: "service endpoint too long",
+			modify: func(doc *Doc) {
+				doc.Service[0].ServiceEndpoint = "https://" + strings.Repeat("a", 100)
+			},
+			problems: []string{"service[0]: service endpoint exceeds maximum length: 100"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			doc := &Doc{PublicKey: []PublicKey{validKey()}, Service: []docdid.Service{validService()}}
+			tc.modify(doc)
+
+			err := ValidateDocument(doc)
+			require.Error(t, err)
+
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Equal(t, tc.problems, validationErr.Problems)
+		})
+	}
+
+	t.Run("invalid service endpoint", func(t *testing.T) {
+		service := validService()
+		service.ServiceEndpoint = "agent.example.com"
+
+		err := ValidateDocument(&Doc{Service: []docdid.Service{service}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid document: service[0]: service endpoint is not valid URI")
+	})
+
+	t.Run("invalid key value", func(t *testing.T) {
+		key := validKey()
+		key.KeyType = "RSA"
+
+		err := ValidateDocument(&Doc{PublicKey: []PublicKey{key}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid key type: RSA")
+	})
+}
+
+func TestValidateIDs(t *testing.T) {
+	require.NoError(t, validateIDs([]string{"key1", "agent"}))
+
+	err := validateIDs([]string{"key1", "did:example:123#key2"})
+	require.EqualError(t, err, "invalid document: ids[1]: id contains invalid characters")
+}
+
+func TestClient_CreateDID_Validation(t *testing.T) {
+	_, err := New().CreateDID("", WithSidetreeEndpoint("https://sidetree.example.com"),
+		WithPublicKey(&PublicKey{ID: "#key1", Type: JWSVerificationKey2020, Encoding: PublicKeyEncodingJwk,
+			KeyType: Ed25519KeyType, Value: make([]byte, ed25519.PublicKeySize), Purpose: []string{KeyPurposeGeneral}}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid document: publicKey[0]: public key: id contains invalid characters")
+}
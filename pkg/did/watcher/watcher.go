@@ -0,0 +1,244 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watcher
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/workerpool"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+const (
+	defaultInterval   = time.Minute
+	subscriptionQueue = 16
+)
+
+type resolver interface {
+	Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error)
+}
+
+// Event notifies that the doc of a watched DID changed
+type Event struct {
+	DID         string          `json:"did"`
+	Changes     []string        `json:"changes"`
+	DIDDocument json.RawMessage `json:"didDocument"`
+	Time        time.Time       `json:"time"`
+}
+
+// Subscription receives the events of the DIDs it's subscribed to. Events are dropped if the subscriber doesn't
+// keep up with them.
+type Subscription struct {
+	Events <-chan *Event
+	events chan *Event
+	dids   []string
+}
+
+// Watcher periodically resolves the subscribed DIDs, bypassing resolution caches, and notifies their subscribers
+// when their docs change, eg. so that relying parties learn about the key rotations of their counterparties
+type Watcher struct {
+	resolver    resolver
+	interval    time.Duration
+	concurrency int
+	pool        *workerpool.Pool
+	subs        map[string]map[*Subscription]struct{}
+	docs        map[string]*docdid.Doc
+	mutex       sync.Mutex
+	stop        chan struct{}
+	stopOnce    sync.Once
+	logger      log.Logger
+}
+
+// New creates a watcher resolving DIDs with the given resolver. Start must be called to start watching.
+func New(resolver resolver, opts ...Option) *Watcher {
+	w := &Watcher{
+		resolver:    resolver,
+		interval:    defaultInterval,
+		concurrency: 1,
+		subs:        map[string]map[*Subscription]struct{}{},
+		docs:        map[string]*docdid.Doc{},
+		stop:        make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.pool = workerpool.New(w.concurrency)
+	w.logger = log.OrDefault(w.logger)
+
+	return w
+}
+
+// Start polls the subscribed DIDs in the background, until Stop is called
+func (w *Watcher) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.Poll()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Subscribe subscribes to the events of the given DIDs. A DID's first resolution after it's subscribed to is the
+// reference its changes are detected against.
+func (w *Watcher) Subscribe(dids ...string) *Subscription {
+	events := make(chan *Event, subscriptionQueue)
+	s := &Subscription{Events: events, events: events, dids: dids}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, d := range dids {
+		if w.subs[d] == nil {
+			w.subs[d] = map[*Subscription]struct{}{}
+		}
+
+		w.subs[d][s] = struct{}{}
+	}
+
+	return s
+}
+
+// Unsubscribe cancels a subscription. DIDs without subscribers aren't watched anymore.
+func (w *Watcher) Unsubscribe(s *Subscription) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, d := range s.dids {
+		delete(w.subs[d], s)
+
+		if len(w.subs[d]) == 0 {
+			delete(w.subs, d)
+			delete(w.docs, d)
+		}
+	}
+}
+
+// Poll resolves each subscribed DID once, notifying the subscribers of the DIDs whose docs changed
+func (w *Watcher) Poll() {
+	w.mutex.Lock()
+
+	dids := make([]string, 0, len(w.subs))
+	for d := range w.subs {
+		dids = append(dids, d)
+	}
+
+	w.mutex.Unlock()
+
+	w.pool.Run(len(dids), w.concurrency, func(i int) {
+		doc, err := w.resolver.Read(dids[i], vdriapi.WithNoCache(true))
+		if err != nil {
+			w.logger.Warnf("watcher failed to resolve %s: %v", dids[i], err)
+
+			return
+		}
+
+		w.update(dids[i], doc)
+	})
+}
+
+// update records the latest doc of a DID, notifying its subscribers if it changed
+func (w *Watcher) update(didID string, doc *docdid.Doc) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	subs, ok := w.subs[didID]
+	if !ok {
+		return
+	}
+
+	previous := w.docs[didID]
+	w.docs[didID] = doc
+
+	if previous == nil {
+		return
+	}
+
+	diffs, err := did.Compare(previous, doc)
+	if err != nil {
+		w.logger.Warnf("watcher failed to compare docs of %s: %v", didID, err)
+
+		return
+	}
+
+	if len(diffs) == 0 {
+		return
+	}
+
+	event, err := newEvent(didID, doc, diffs)
+	if err != nil {
+		w.logger.Warnf("watcher failed to marshal doc of %s: %v", didID, err)
+
+		return
+	}
+
+	for s := range subs {
+		select {
+		case s.events <- event:
+		default:
+			w.logger.Warnf("watcher dropped an event of %s, as a subscriber doesn't keep up", didID)
+		}
+	}
+}
+
+func newEvent(didID string, doc *docdid.Doc, diffs []did.Difference) (*Event, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]string, len(diffs))
+	for i, diff := range diffs {
+		changes[i] = diff.String()
+	}
+
+	return &Event{DID: didID, Changes: changes, DIDDocument: docBytes, Time: time.Now().UTC()}, nil
+}
+
+// Option configures the watcher
+type Option func(opts *Watcher)
+
+// WithInterval option sets the interval between polls of the subscribed DIDs, one minute by default
+func WithInterval(interval time.Duration) Option {
+	return func(opts *Watcher) {
+		opts.interval = interval
+	}
+}
+
+// WithConcurrency option sets how many subscribed DIDs are resolved concurrently during a poll, one by default
+func WithConcurrency(n int) Option {
+	return func(opts *Watcher) {
+		opts.concurrency = n
+	}
+}
+
+// WithLogger option sets the logger of the watcher. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *Watcher) {
+		opts.logger = logger
+	}
+}
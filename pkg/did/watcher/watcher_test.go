@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watcher
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+)
+
+type mockResolver struct {
+	docs  map[string]*docdid.Doc
+	err   error
+	mutex sync.Mutex
+}
+
+func (m *mockResolver) Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	resolveOpts := &vdriapi.ResolveDIDOpts{}
+	for _, opt := range opts {
+		opt(resolveOpts)
+	}
+
+	if !resolveOpts.NoCache {
+		return nil, fmt.Errorf("cache not bypassed")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.docs[did], nil
+}
+
+func (m *mockResolver) set(did, endpoint string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.docs[did] = &docdid.Doc{
+		Context: []string{"https://w3id.org/did/v1"},
+		ID:      did,
+		Service: []docdid.Service{{ID: did + "#agent", Type: "did-communication", ServiceEndpoint: endpoint}},
+	}
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	const didID = "did:trustbloc:testnet:123"
+
+	resolver := &mockResolver{docs: map[string]*docdid.Doc{}}
+	resolver.set(didID, "https://agent.one")
+	resolver.set("did:trustbloc:testnet:456", "https://agent.one")
+
+	w := New(resolver)
+
+	sub := w.Subscribe(didID)
+	other := w.Subscribe(didID, "did:trustbloc:testnet:456")
+
+	// the first resolution is the reference
+	w.Poll()
+	require.Empty(t, sub.Events)
+
+	// unchanged doc
+	w.Poll()
+	require.Empty(t, sub.Events)
+
+	resolver.set(didID, "https://agent.two")
+	w.Poll()
+
+	require.Len(t, sub.Events, 1)
+	require.Len(t, other.Events, 1)
+
+	event := <-sub.Events
+	require.Equal(t, didID, event.DID)
+	require.Equal(t, []string{
+		`service[did:trustbloc:testnet:123#agent].serviceEndpoint: "https://agent.one" != "https://agent.two"`,
+	}, event.Changes)
+	require.Contains(t, string(event.DIDDocument), "https://agent.two")
+
+	t.Run("resolution failures are skipped", func(t *testing.T) {
+		resolver.err = fmt.Errorf("unreachable")
+		w.Poll()
+		resolver.err = nil
+
+		require.Empty(t, sub.Events)
+	})
+
+	t.Run("unsubscribed DIDs aren't watched", func(t *testing.T) {
+		<-other.Events
+
+		w.Unsubscribe(sub)
+		w.Unsubscribe(other)
+		require.Empty(t, w.subs)
+		require.Empty(t, w.docs)
+
+		resolver.set(didID, "https://agent.three")
+		w.Poll()
+		w.update(didID, resolver.docs[didID])
+
+		require.Empty(t, other.Events)
+	})
+
+	t.Run("events are dropped for slow subscribers", func(t *testing.T) {
+		slow := w.Subscribe(didID)
+
+		for i := 0; i < subscriptionQueue+2; i++ {
+			resolver.set(didID, fmt.Sprintf("https://agent.%d", i))
+			w.Poll()
+		}
+
+		require.Len(t, slow.Events, subscriptionQueue)
+	})
+}
+
+func TestWatcher_Start(t *testing.T) {
+	const didID = "did:trustbloc:testnet:123"
+
+	resolver := &mockResolver{docs: map[string]*docdid.Doc{}}
+	resolver.set(didID, "https://agent.one")
+
+	w := New(resolver, WithInterval(10*time.Millisecond))
+	sub := w.Subscribe(didID)
+
+	w.Start()
+	defer w.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	resolver.set(didID, "https://agent.two")
+
+	select {
+	case event := <-sub.Events:
+		require.Equal(t, didID, event.DID)
+	case <-time.After(time.Second):
+		require.Fail(t, "no event received")
+	}
+
+	w.Stop()
+}
+
+func TestWatcher_Concurrency(t *testing.T) {
+	resolver := &mockResolver{docs: map[string]*docdid.Doc{}}
+
+	w := New(resolver, WithConcurrency(4))
+
+	var subs []*Subscription
+
+	for i := 0; i < 10; i++ {
+		didID := fmt.Sprintf("did:trustbloc:testnet:%d", i)
+		resolver.set(didID, "https://agent.one")
+		subs = append(subs, w.Subscribe(didID))
+	}
+
+	w.Poll()
+
+	for i := 0; i < 10; i++ {
+		resolver.set(fmt.Sprintf("did:trustbloc:testnet:%d", i), "https://agent.two")
+	}
+
+	w.Poll()
+
+	for _, s := range subs {
+		require.Len(t, s.Events, 1)
+	}
+}
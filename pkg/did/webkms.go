@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/webkms"
+)
+
+// WithWebKMSSigningKey signs the update with a key held by a remote WebKMS keystore, which must be the current
+// update key of the DID. The update public key is the one exported from the KMS.
+func WithWebKMSSigningKey(signer *webkms.Signer) UpdateDIDOption {
+	return WithSigningKey(signer, signer.Public().Key)
+}
+
+// WithWebKMSRecoverySigningKey signs the recovery with a key held by a remote WebKMS keystore, which must be the
+// current recovery key of the DID. The recovery public key is the one exported from the KMS.
+func WithWebKMSRecoverySigningKey(signer *webkms.Signer) RecoverDIDOption {
+	return WithRecoverySigningKey(signer, signer.Public().Key)
+}
+
+// WithWebKMSDeactivateSigningKey signs the deactivation with a key held by a remote WebKMS keystore, which must be
+// the current recovery key of the DID. The recovery public key is the one exported from the KMS.
+func WithWebKMSDeactivateSigningKey(signer *webkms.Signer) DeactivateDIDOption {
+	return WithDeactivateSigningKey(signer, signer.Public().Key)
+}
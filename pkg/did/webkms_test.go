@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/webkms"
+)
+
+// webKMSServer serves a WebKMS keystore holding the given ed25519 key, counting the signatures it makes
+func webKMSServer(t *testing.T, privKey ed25519.PrivateKey, signCount *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/keys/key1/export":
+			fmt.Fprintf(w, `{"publicKey":"%s"}`, base64.RawURLEncoding.EncodeToString(privKey.Public().(ed25519.PublicKey)))
+		case "/keys/key1/sign":
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			req := &struct {
+				Message string `json:"message"`
+			}{}
+			require.NoError(t, json.Unmarshal(body, req))
+
+			msg, err := base64.RawURLEncoding.DecodeString(req.Message)
+			require.NoError(t, err)
+
+			*signCount++
+
+			fmt.Fprintf(w, `{"signature":"%s"}`, base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, msg)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// verifySignedData verifies the compact JWS of a sidetree operation, returning its payload
+func verifySignedData(t *testing.T, signedData string, pubKey ed25519.PublicKey) map[string]interface{} {
+	jws, err := jose.ParseSigned(signedData)
+	require.NoError(t, err)
+
+	payload, err := jws.Verify(pubKey)
+	require.NoError(t, err)
+
+	signed := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(payload, &signed))
+
+	return signed
+}
+
+func TestWebKMSSigningKeys(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	nextPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var signCount int
+
+	kms := webKMSServer(t, privKey, &signCount)
+	defer kms.Close()
+
+	signer, err := webkms.NewSigner(kms.URL+"/keys/key1", webkms.WithAuthToken("token"), webkms.WithKeyID("key1"))
+	require.NoError(t, err)
+
+	t.Run("update", func(t *testing.T) {
+		signCount = 0
+		req := &model.UpdateRequest{}
+		serv := operationServer(t, model.OperationTypeUpdate, req)
+
+		defer serv.Close()
+
+		err := New().UpdateDID(updateTestDID, WithWebKMSSigningKey(signer), WithNextUpdatePublicKey(nextPubKey),
+			WithRemovePublicKeys("key1"), WithUpdateSidetreeEndpoint(serv.URL))
+		require.NoError(t, err)
+		require.Equal(t, 1, signCount)
+
+		signed := verifySignedData(t, req.SignedData, pubKey)
+		require.NotNil(t, signed["update_key"])
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		signCount = 0
+		req := &model.RecoverRequest{}
+		serv := operationServer(t, model.OperationTypeRecover, req)
+
+		defer serv.Close()
+
+		err := New().RecoverDID(updateTestDID, WithWebKMSRecoverySigningKey(signer),
+			WithNextRecoveryPublicKey(nextPubKey), WithRecoverNextUpdatePublicKey(nextPubKey),
+			WithRecoverSidetreeEndpoint(serv.URL))
+		require.NoError(t, err)
+		require.Equal(t, 1, signCount)
+
+		signed := verifySignedData(t, req.SignedData, pubKey)
+		require.NotNil(t, signed["recovery_key"])
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		signCount = 0
+		req := &model.DeactivateRequest{}
+		serv := operationServer(t, model.OperationTypeDeactivate, req)
+
+		defer serv.Close()
+
+		err := New().DeactivateDID(updateTestDID, WithWebKMSDeactivateSigningKey(signer),
+			WithDeactivateSidetreeEndpoint(serv.URL))
+		require.NoError(t, err)
+		require.Equal(t, 1, signCount)
+
+		signed := verifySignedData(t, req.SignedData, pubKey)
+		require.Equal(t, "EiAtestsuffix", signed["did_suffix"])
+	})
+}
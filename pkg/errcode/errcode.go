@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package errcode attaches stable, machine-readable codes to the errors of the DID method, so monitoring and
+// support can key off codes rather than message strings. Codes are grouped by hundreds: DID and request errors
+// (DM-0xx), consortium trust errors (DM-1xx), resolution errors (DM-2xx) and registration errors (DM-3xx).
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Header is the HTTP response header the code of a failed request is returned in
+const Header = "Error-Code"
+
+// Code is a stable error code
+type Code string
+
+const (
+	// InvalidDID is returned for a malformed DID
+	InvalidDID Code = "DM-001"
+	// DIDNotFound is returned when a DID doesn't exist
+	DIDNotFound Code = "DM-002"
+	// InvalidRequest is returned for a malformed or invalid request
+	InvalidRequest Code = "DM-003"
+
+	// ConsortiumUnavailable is returned when the consortium config can't be fetched or parsed
+	ConsortiumUnavailable Code = "DM-101"
+	// StakeholdersUnavailable is returned when not enough stakeholder configs of a consortium can be fetched
+	StakeholdersUnavailable Code = "DM-102"
+	// StakeholderVerificationFailed is returned when a stakeholder's DID, did-configuration or signature is invalid
+	StakeholderVerificationFailed Code = "DM-103"
+	// EndorsementThresholdNotMet is returned when fewer stakeholders than required endorse a consortium config
+	EndorsementThresholdNotMet Code = "DM-104"
+
+	// EndpointsUnavailable is returned when no sidetree endpoint of a consortium can be selected
+	EndpointsUnavailable Code = "DM-201"
+	// ResolutionFailed is returned when a sidetree endpoint fails to resolve a DID
+	ResolutionFailed Code = "DM-202"
+
+	// RegistrationFailed is returned when a DID can't be created
+	RegistrationFailed Code = "DM-301"
+)
+
+// exitCodes are the process exit codes of the commands failing with a coded error. Exit codes are limited to a
+// byte, so they're numbered by tens within the groups of the codes.
+// nolint: gochecknoglobals
+var exitCodes = map[Code]int{
+	InvalidDID:                    10,
+	DIDNotFound:                   11,
+	InvalidRequest:                12,
+	ConsortiumUnavailable:         20,
+	StakeholdersUnavailable:       21,
+	StakeholderVerificationFailed: 22,
+	EndorsementThresholdNotMet:    23,
+	EndpointsUnavailable:          30,
+	ResolutionFailed:              31,
+	RegistrationFailed:            40,
+}
+
+// Error is an error carrying a code. Its message is the message of the underlying error.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches a code to an error, unless the error already carries a code: the first code attached to an error
+// is the most specific one. Returns nil if err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if c := Of(err); c != "" {
+		code = c
+	}
+
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error like fmt.Errorf, attaching a code to it unless an error wrapped with %w carries one
+func Errorf(code Code, format string, args ...interface{}) error {
+	return Wrap(code, fmt.Errorf(format, args...))
+}
+
+// Of returns the code of an error, or an empty code if it carries none
+func Of(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return ""
+}
+
+// OrDefault returns the code of an error, or the given default code if it carries none
+func OrDefault(err error, code Code) Code {
+	if c := Of(err); c != "" {
+		return c
+	}
+
+	return code
+}
+
+// ExitCode returns the process exit code of a command failing with the given error: 0 if err is nil, the exit
+// code of its code if it carries one, or 1 otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitCode, ok := exitCodes[Of(err)]; ok {
+		return exitCode
+	}
+
+	return 1
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	t.Run("success - code attached", func(t *testing.T) {
+		cause := errors.New("no such host")
+
+		err := Wrap(ConsortiumUnavailable, cause)
+		require.EqualError(t, err, "no such host")
+		require.Equal(t, ConsortiumUnavailable, Of(err))
+		require.True(t, errors.Is(err, cause))
+	})
+
+	t.Run("success - most specific code kept", func(t *testing.T) {
+		err := Errorf(ResolutionFailed, "failed to resolve did: %w", Wrap(DIDNotFound, errors.New("not found")))
+		require.EqualError(t, err, "failed to resolve did: not found")
+		require.Equal(t, DIDNotFound, Of(err))
+
+		err = fmt.Errorf("invalid consortium: %w", err)
+		require.Equal(t, DIDNotFound, Of(err))
+	})
+
+	t.Run("success - nil error", func(t *testing.T) {
+		require.NoError(t, Wrap(InvalidDID, nil))
+	})
+
+	t.Run("success - no code", func(t *testing.T) {
+		require.Empty(t, Of(errors.New("no code")))
+		require.Equal(t, InvalidRequest, OrDefault(errors.New("no code"), InvalidRequest))
+		require.Equal(t, InvalidDID, OrDefault(Errorf(InvalidDID, "wrong did"), InvalidRequest))
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	require.Equal(t, 0, ExitCode(nil))
+	require.Equal(t, 1, ExitCode(errors.New("no code")))
+	require.Equal(t, 1, ExitCode(Wrap("DM-999", errors.New("unknown code"))))
+	require.Equal(t, 23, ExitCode(fmt.Errorf("failed: %w", Errorf(EndorsementThresholdNotMet, "insufficient"))))
+
+	for code, exitCode := range exitCodes {
+		require.True(t, exitCode > 1 && exitCode < 256, code)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the DIDMethod gRPC service defined in didmethod.proto
+const ServiceName = "trustbloc.didmethod.v1.DIDMethod"
+
+// DIDMethodServer is the server API of the DIDMethod service
+type DIDMethodServer interface {
+	Resolve(context.Context, *wrappers.StringValue) (*wrappers.BytesValue, error)
+	ResolveStream(ResolveStreamServer) error
+	Create(context.Context, *wrappers.BytesValue) (*wrappers.BytesValue, error)
+	Update(context.Context, *wrappers.BytesValue) (*empty.Empty, error)
+	Deactivate(context.Context, *wrappers.BytesValue) (*empty.Empty, error)
+	ValidateConsortium(context.Context, *wrappers.StringValue) (*duration.Duration, error)
+}
+
+// ResolveStreamServer is the server side of a ResolveStream call
+type ResolveStreamServer interface {
+	Send(*wrappers.BytesValue) error
+	Recv() (*wrappers.StringValue, error)
+	grpc.ServerStream
+}
+
+// RegisterDIDMethodServer registers the DIDMethod service implementation with a gRPC server
+func RegisterDIDMethodServer(s *grpc.Server, srv DIDMethodServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+var serviceDesc = grpc.ServiceDesc{ // nolint: gochecknoglobals
+	ServiceName: ServiceName,
+	HandlerType: (*DIDMethodServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Resolve", Handler: resolveHandler},
+		{MethodName: "Create", Handler: createHandler},
+		{MethodName: "Update", Handler: updateHandler},
+		{MethodName: "Deactivate", Handler: deactivateHandler},
+		{MethodName: "ValidateConsortium", Handler: validateConsortiumHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ResolveStream", Handler: resolveStreamHandler, ServerStreams: true, ClientStreams: true},
+	},
+	Metadata: "didmethod.proto",
+}
+
+func resolveHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.StringValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDMethodServer).Resolve(ctx, req.(*wrappers.StringValue))
+	}
+
+	return intercept(ctx, in, srv, "Resolve", handler, interceptor)
+}
+
+func createHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.BytesValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDMethodServer).Create(ctx, req.(*wrappers.BytesValue))
+	}
+
+	return intercept(ctx, in, srv, "Create", handler, interceptor)
+}
+
+func updateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.BytesValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDMethodServer).Update(ctx, req.(*wrappers.BytesValue))
+	}
+
+	return intercept(ctx, in, srv, "Update", handler, interceptor)
+}
+
+func deactivateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.BytesValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDMethodServer).Deactivate(ctx, req.(*wrappers.BytesValue))
+	}
+
+	return intercept(ctx, in, srv, "Deactivate", handler, interceptor)
+}
+
+func validateConsortiumHandler(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.StringValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDMethodServer).ValidateConsortium(ctx, req.(*wrappers.StringValue))
+	}
+
+	return intercept(ctx, in, srv, "ValidateConsortium", handler, interceptor)
+}
+
+func intercept(ctx context.Context, in, srv interface{}, method string, handler grpc.UnaryHandler,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+func resolveStreamHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DIDMethodServer).ResolveStream(&resolveStreamServer{stream})
+}
+
+type resolveStreamServer struct {
+	grpc.ServerStream
+}
+
+func (s *resolveStreamServer) Send(m *wrappers.BytesValue) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *resolveStreamServer) Recv() (*wrappers.StringValue, error) {
+	m := new(wrappers.StringValue)
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DIDMethodClient is the client API of the DIDMethod service
+type DIDMethodClient interface {
+	Resolve(ctx context.Context, in *wrappers.StringValue, opts ...grpc.CallOption) (*wrappers.BytesValue, error)
+	ResolveStream(ctx context.Context, opts ...grpc.CallOption) (ResolveStreamClient, error)
+	Create(ctx context.Context, in *wrappers.BytesValue, opts ...grpc.CallOption) (*wrappers.BytesValue, error)
+	Update(ctx context.Context, in *wrappers.BytesValue, opts ...grpc.CallOption) (*empty.Empty, error)
+	Deactivate(ctx context.Context, in *wrappers.BytesValue, opts ...grpc.CallOption) (*empty.Empty, error)
+	ValidateConsortium(ctx context.Context, in *wrappers.StringValue,
+		opts ...grpc.CallOption) (*duration.Duration, error)
+}
+
+// ResolveStreamClient is the client side of a ResolveStream call
+type ResolveStreamClient interface {
+	Send(*wrappers.StringValue) error
+	Recv() (*wrappers.BytesValue, error)
+	grpc.ClientStream
+}
+
+type didMethodClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewDIDMethodClient creates a DIDMethod service client
+func NewDIDMethodClient(cc grpc.ClientConnInterface) DIDMethodClient {
+	return &didMethodClient{cc}
+}
+
+func (c *didMethodClient) Resolve(ctx context.Context, in *wrappers.StringValue,
+	opts ...grpc.CallOption) (*wrappers.BytesValue, error) {
+	out := new(wrappers.BytesValue)
+
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Resolve", in, out, opts...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (c *didMethodClient) ResolveStream(ctx context.Context, opts ...grpc.CallOption) (ResolveStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+ServiceName+"/ResolveStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolveStreamClient{stream}, nil
+}
+
+type resolveStreamClient struct {
+	grpc.ClientStream
+}
+
+func (s *resolveStreamClient) Send(m *wrappers.StringValue) error {
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *resolveStreamClient) Recv() (*wrappers.BytesValue, error) {
+	m := new(wrappers.BytesValue)
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (c *didMethodClient) Create(ctx context.Context, in *wrappers.BytesValue,
+	opts ...grpc.CallOption) (*wrappers.BytesValue, error) {
+	out := new(wrappers.BytesValue)
+
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Create", in, out, opts...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (c *didMethodClient) Update(ctx context.Context, in *wrappers.BytesValue,
+	opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Update", in, out, opts...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (c *didMethodClient) Deactivate(ctx context.Context, in *wrappers.BytesValue,
+	opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Deactivate", in, out, opts...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (c *didMethodClient) ValidateConsortium(ctx context.Context, in *wrappers.StringValue,
+	opts ...grpc.CallOption) (*duration.Duration, error) {
+	out := new(duration.Duration)
+
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/ValidateConsortium", in, out, opts...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod/operation"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	// modes, as for the REST API
+	registrarMode = "registrar"
+	resolverMode  = "resolver"
+	proxyMode     = "proxy"
+)
+
+type didMethod interface {
+	ResolveDIDWithContext(ctx context.Context, did string) (*did.Doc, error)
+	RegisterDIDWithContext(ctx context.Context, requester string,
+		data *operation.RegisterDIDRequest) *operation.RegisterResponse
+	ValidateConsortiumWithContext(ctx context.Context, domain string) (*time.Duration, error)
+	UpdateDID(requester, didID string, req []byte) error
+	DeactivateDID(requester, didID string, req []byte) error
+}
+
+// OperationRequest is the JSON request of the Update and Deactivate operations: a DID and its sidetree operation
+// request, built and signed by the DID controller as the service holds no keys
+type OperationRequest struct {
+	DID     string          `json:"did"`
+	Request json.RawMessage `json:"request"`
+}
+
+// Service implements the DIDMethod gRPC service with the operations of the REST API
+type Service struct {
+	didMethod didMethod
+	mode      string
+}
+
+// New returns the DIDMethod gRPC service. As for the REST API, the mode selects whether the resolver operations,
+// the registrar operations or both are enabled.
+func New(didMethod didMethod, mode string) *Service {
+	return &Service{didMethod: didMethod, mode: mode}
+}
+
+// Resolve resolves a DID
+func (s *Service) Resolve(ctx context.Context, in *wrappers.StringValue) (*wrappers.BytesValue, error) {
+	if err := s.checkMode(resolverMode); err != nil {
+		return nil, err
+	}
+
+	result, err := s.resolve(ctx, in.GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappers.BytesValue{Value: result}, nil
+}
+
+// ResolveStream resolves each DID received on the stream, sending back the resolution results in order
+func (s *Service) ResolveStream(stream ResolveStreamServer) error {
+	if err := s.checkMode(resolverMode); err != nil {
+		return err
+	}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		result, err := s.resolve(stream.Context(), in.GetValue())
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(&wrappers.BytesValue{Value: result}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Service) resolve(ctx context.Context, didID string) ([]byte, error) {
+	if didID == "" {
+		return nil, status.Error(codes.InvalidArgument, "did is missing")
+	}
+
+	doc, err := s.didMethod.ResolveDIDWithContext(ctx, didID)
+	if err != nil {
+		return nil, errorStatus(err, codes.Unknown, "failed to resolve did")
+	}
+
+	result, err := models.MakeDIDResolutionResult(doc)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal did doc: %s", err)
+	}
+
+	return result, nil
+}
+
+// Create registers a DID from a JSON register request, returning the JSON register response
+func (s *Service) Create(ctx context.Context, in *wrappers.BytesValue) (*wrappers.BytesValue, error) {
+	if err := s.checkMode(registrarMode); err != nil {
+		return nil, err
+	}
+
+	data := operation.RegisterDIDRequest{}
+
+	if err := json.Unmarshal(in.GetValue(), &data); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %s", err)
+	}
+
+	result, err := json.Marshal(s.didMethod.RegisterDIDWithContext(ctx, requester(ctx), &data))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal register response: %s", err)
+	}
+
+	return &wrappers.BytesValue{Value: result}, nil
+}
+
+// requester identifies the requester of an operation by the subject of its TLS client certificate, or by its address
+func requester(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if tlsInfo, isTLS := p.AuthInfo.(credentials.TLSInfo); isTLS && len(tlsInfo.State.PeerCertificates) > 0 {
+		return tlsInfo.State.PeerCertificates[0].Subject.String()
+	}
+
+	return p.Addr.String()
+}
+
+// Update sends the signed sidetree update request of a JSON operation request
+func (s *Service) Update(ctx context.Context, in *wrappers.BytesValue) (*empty.Empty, error) {
+	return s.sendOperation(requester(ctx), in.GetValue(), "update", s.didMethod.UpdateDID)
+}
+
+// Deactivate sends the signed sidetree deactivate request of a JSON operation request
+func (s *Service) Deactivate(ctx context.Context, in *wrappers.BytesValue) (*empty.Empty, error) {
+	return s.sendOperation(requester(ctx), in.GetValue(), "deactivate", s.didMethod.DeactivateDID)
+}
+
+func (s *Service) sendOperation(requester string, in []byte, name string,
+	send func(requester, didID string, req []byte) error) (*empty.Empty, error) {
+	if err := s.checkMode(registrarMode); err != nil {
+		return nil, err
+	}
+
+	data := OperationRequest{}
+
+	if err := json.Unmarshal(in, &data); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %s", err)
+	}
+
+	if data.DID == "" || len(data.Request) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "did or sidetree request is missing")
+	}
+
+	if err := send(requester, data.DID, data.Request); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to %s did: %s", name, err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+// ValidateConsortium validates the consortium of a domain, returning the cache lifetime of its config
+func (s *Service) ValidateConsortium(ctx context.Context, in *wrappers.StringValue) (*duration.Duration, error) {
+	if err := s.checkMode(resolverMode); err != nil {
+		return nil, err
+	}
+
+	if in.GetValue() == "" {
+		return nil, status.Error(codes.InvalidArgument, "domain is missing")
+	}
+
+	lifetime, err := s.didMethod.ValidateConsortiumWithContext(ctx, in.GetValue())
+	if err != nil {
+		return nil, errorStatus(err, codes.FailedPrecondition, "invalid consortium")
+	}
+
+	if lifetime == nil {
+		return ptypes.DurationProto(0), nil
+	}
+
+	return ptypes.DurationProto(*lifetime), nil
+}
+
+func (s *Service) checkMode(mode string) error {
+	// a resolver proxy serves the resolver operations
+	if s.mode == proxyMode && mode == resolverMode {
+		return nil
+	}
+
+	if s.mode != "" && s.mode != mode && s.mode != "combined" {
+		return status.Errorf(codes.Unimplemented, "%s operations are disabled in %s mode", mode, s.mode)
+	}
+
+	return nil
+}
+
+// errorStatus returns the status of an operation error: the deadline or cancellation of the call, or the given code
+func errorStatus(err error, code codes.Code, msg string) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+
+	return status.Errorf(code, "%s: %s", msg, err)
+}
@@ -0,0 +1,386 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didmethod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/restapi/didmethod/operation"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const testDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:trustbloc:testnet:123"
+}`
+
+type mockDIDMethod struct {
+	resolveDIDFunc         func(ctx context.Context, did string) (*did.Doc, error)
+	registerDIDFunc        func(requester string, data *operation.RegisterDIDRequest) *operation.RegisterResponse
+	validateConsortiumFunc func(ctx context.Context, domain string) (*time.Duration, error)
+	updateDIDFunc          func(requester, didID string, req []byte) error
+	deactivateDIDFunc      func(requester, didID string, req []byte) error
+}
+
+func (m *mockDIDMethod) ResolveDIDWithContext(ctx context.Context, didID string) (*did.Doc, error) {
+	return m.resolveDIDFunc(ctx, didID)
+}
+
+func (m *mockDIDMethod) RegisterDIDWithContext(_ context.Context, requester string,
+	data *operation.RegisterDIDRequest) *operation.RegisterResponse {
+	return m.registerDIDFunc(requester, data)
+}
+
+func (m *mockDIDMethod) ValidateConsortiumWithContext(ctx context.Context, domain string) (*time.Duration, error) {
+	return m.validateConsortiumFunc(ctx, domain)
+}
+
+func (m *mockDIDMethod) UpdateDID(requester, didID string, req []byte) error {
+	return m.updateDIDFunc(requester, didID, req)
+}
+
+func (m *mockDIDMethod) DeactivateDID(requester, didID string, req []byte) error {
+	return m.deactivateDIDFunc(requester, didID, req)
+}
+
+func newClient(t *testing.T, svc *Service) (DIDMethodClient, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer()
+	RegisterDIDMethodServer(srv, svc)
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			panic(err)
+		}
+	}()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	require.NoError(t, err)
+
+	return NewDIDMethodClient(conn), func() {
+		require.NoError(t, conn.Close())
+		srv.Stop()
+	}
+}
+
+func TestService_Resolve(t *testing.T) {
+	doc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	expected, err := models.MakeDIDResolutionResult(doc)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{resolveDIDFunc: func(_ context.Context,
+			didID string) (*did.Doc, error) {
+			require.Equal(t, doc.ID, didID)
+			return doc, nil
+		}}, ""))
+		defer stop()
+
+		res, err := client.Resolve(context.Background(), &wrappers.StringValue{Value: doc.ID})
+		require.NoError(t, err)
+		require.Equal(t, expected, res.Value)
+	})
+
+	t.Run("failure - did is missing", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, ""))
+		defer stop()
+
+		_, err := client.Resolve(context.Background(), &wrappers.StringValue{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("failure - resolve error", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{resolveDIDFunc: func(context.Context, string) (*did.Doc, error) {
+			return nil, errors.New("resolve error")
+		}}, ""))
+		defer stop()
+
+		_, err := client.Resolve(context.Background(), &wrappers.StringValue{Value: doc.ID})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve did: resolve error")
+	})
+
+	t.Run("failure - deadline exceeded", func(t *testing.T) {
+		aborted := make(chan error, 1)
+
+		client, stop := newClient(t, New(&mockDIDMethod{resolveDIDFunc: func(ctx context.Context,
+			_ string) (*did.Doc, error) {
+			<-ctx.Done()
+			aborted <- ctx.Err()
+
+			return nil, ctx.Err()
+		}}, ""))
+		defer stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Resolve(ctx, &wrappers.StringValue{Value: doc.ID})
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		// the deadline reaches the resolution
+		require.Error(t, <-aborted)
+	})
+
+	t.Run("failure - disabled in registrar mode", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, registrarMode))
+		defer stop()
+
+		_, err := client.Resolve(context.Background(), &wrappers.StringValue{Value: doc.ID})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("success - proxy mode", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{resolveDIDFunc: func(_ context.Context,
+			didID string) (*did.Doc, error) {
+			return doc, nil
+		}}, proxyMode))
+		defer stop()
+
+		_, err := client.Resolve(context.Background(), &wrappers.StringValue{Value: doc.ID})
+		require.NoError(t, err)
+	})
+}
+
+func TestService_ResolveStream(t *testing.T) {
+	client, stop := newClient(t, New(&mockDIDMethod{resolveDIDFunc: func(_ context.Context,
+		didID string) (*did.Doc, error) {
+		return &did.Doc{Context: []string{"https://w3id.org/did/v1"}, ID: didID}, nil
+	}}, ""))
+	defer stop()
+
+	stream, err := client.ResolveStream(context.Background())
+	require.NoError(t, err)
+
+	dids := []string{"did:trustbloc:testnet:1", "did:trustbloc:testnet:2"}
+
+	for _, didID := range dids {
+		require.NoError(t, stream.Send(&wrappers.StringValue{Value: didID}))
+	}
+
+	require.NoError(t, stream.CloseSend())
+
+	for _, didID := range dids {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+
+		var result models.DIDResolutionResult
+		require.NoError(t, json.Unmarshal(res.Value, &result))
+
+		doc, err := did.ParseDocument(result.DIDDocument)
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+	}
+
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{
+			registerDIDFunc: func(requester string, data *operation.RegisterDIDRequest) *operation.RegisterResponse {
+				require.Equal(t, "bufconn", requester)
+
+				return &operation.RegisterResponse{JobID: data.JobID,
+					DIDState: operation.DIDState{Identifier: "did:trustbloc:testnet:123",
+						State: operation.RegistrationStateFinished}}
+			}}, registrarMode))
+		defer stop()
+
+		req, err := json.Marshal(&operation.RegisterDIDRequest{JobID: "job1"})
+		require.NoError(t, err)
+
+		res, err := client.Create(context.Background(), &wrappers.BytesValue{Value: req})
+		require.NoError(t, err)
+
+		var resp operation.RegisterResponse
+		require.NoError(t, json.Unmarshal(res.Value, &resp))
+		require.Equal(t, "job1", resp.JobID)
+		require.Equal(t, operation.RegistrationStateFinished, resp.DIDState.State)
+	})
+
+	t.Run("failure - invalid request", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, ""))
+		defer stop()
+
+		_, err := client.Create(context.Background(), &wrappers.BytesValue{Value: []byte("{")})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("failure - disabled in resolver mode", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, resolverMode))
+		defer stop()
+
+		_, err := client.Create(context.Background(), &wrappers.BytesValue{Value: []byte("{}")})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("failure - disabled in proxy mode", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, proxyMode))
+		defer stop()
+
+		_, err := client.Create(context.Background(), &wrappers.BytesValue{Value: []byte("{}")})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	const updateRequest = `{"type":"update","did_suffix":"123"}`
+
+	req, err := json.Marshal(&OperationRequest{DID: "did:trustbloc:testnet:123",
+		Request: json.RawMessage(updateRequest)})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{updateDIDFunc: func(requester, didID string, req []byte) error {
+			require.Equal(t, "bufconn", requester)
+			require.Equal(t, "did:trustbloc:testnet:123", didID)
+			require.Equal(t, updateRequest, string(req))
+
+			return nil
+		}}, registrarMode))
+		defer stop()
+
+		_, err := client.Update(context.Background(), &wrappers.BytesValue{Value: req})
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - update error", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{updateDIDFunc: func(string, string, []byte) error {
+			return errors.New("sidetree request suffix doesn't match did")
+		}}, ""))
+		defer stop()
+
+		_, err := client.Update(context.Background(), &wrappers.BytesValue{Value: req})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		require.Contains(t, err.Error(), "failed to update did: sidetree request suffix doesn't match did")
+	})
+
+	t.Run("failure - invalid request", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, ""))
+		defer stop()
+
+		_, err := client.Update(context.Background(), &wrappers.BytesValue{Value: []byte("{")})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.Update(context.Background(), &wrappers.BytesValue{Value: []byte(`{"did":"did:example:123"}`)})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Contains(t, err.Error(), "did or sidetree request is missing")
+	})
+
+	t.Run("failure - disabled in resolver mode", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, resolverMode))
+		defer stop()
+
+		_, err := client.Update(context.Background(), &wrappers.BytesValue{Value: req})
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestService_Deactivate(t *testing.T) {
+	const deactivateRequest = `{"type":"deactivate","did_suffix":"123"}`
+
+	req, err := json.Marshal(&OperationRequest{DID: "did:trustbloc:testnet:123",
+		Request: json.RawMessage(deactivateRequest)})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{deactivateDIDFunc: func(_, didID string, req []byte) error {
+			require.Equal(t, "did:trustbloc:testnet:123", didID)
+			require.Equal(t, deactivateRequest, string(req))
+
+			return nil
+		}}, ""))
+		defer stop()
+
+		_, err := client.Deactivate(context.Background(), &wrappers.BytesValue{Value: req})
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - deactivate error", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{deactivateDIDFunc: func(string, string, []byte) error {
+			return errors.New("got unexpected response")
+		}}, ""))
+		defer stop()
+
+		_, err := client.Deactivate(context.Background(), &wrappers.BytesValue{Value: req})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		require.Contains(t, err.Error(), "failed to deactivate did: got unexpected response")
+	})
+}
+
+func TestService_ValidateConsortium(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		lifetime := time.Hour
+
+		client, stop := newClient(t, New(&mockDIDMethod{
+			validateConsortiumFunc: func(_ context.Context, domain string) (*time.Duration, error) {
+				require.Equal(t, "testnet", domain)
+				return &lifetime, nil
+			}}, ""))
+		defer stop()
+
+		res, err := client.ValidateConsortium(context.Background(), &wrappers.StringValue{Value: "testnet"})
+		require.NoError(t, err)
+
+		d, err := ptypes.Duration(res)
+		require.NoError(t, err)
+		require.Equal(t, lifetime, d)
+	})
+
+	t.Run("failure - invalid consortium", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{validateConsortiumFunc: func(context.Context,
+			string) (*time.Duration, error) {
+			return nil, errors.New("insufficient stakeholder endorsement")
+		}}, ""))
+		defer stop()
+
+		_, err := client.ValidateConsortium(context.Background(), &wrappers.StringValue{Value: "testnet"})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("failure - domain is missing", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{}, ""))
+		defer stop()
+
+		_, err := client.ValidateConsortium(context.Background(), &wrappers.StringValue{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("failure - canceled", func(t *testing.T) {
+		client, stop := newClient(t, New(&mockDIDMethod{validateConsortiumFunc: func(context.Context,
+			string) (*time.Duration, error) {
+			return nil, fmt.Errorf("consortium invalid: %w", context.Canceled)
+		}}, ""))
+		defer stop()
+
+		_, err := client.ValidateConsortium(context.Background(), &wrappers.StringValue{Value: "testnet"})
+		require.Equal(t, codes.Canceled, status.Code(err))
+	})
+}
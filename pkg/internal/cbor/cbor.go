@@ -0,0 +1,517 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cbor implements the subset of CBOR (RFC 7049) needed for COSE signed config files: definite length
+// items, tags and simple values. Decoded items are uint64, int64, []byte, string, []interface{},
+// map[interface{}]interface{}, Tag, bool, float64 or nil.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// major types
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// additional info values
+const (
+	infoUint8      = 24
+	infoUint16     = 25
+	infoUint32     = 26
+	infoUint64     = 27
+	infoIndefinite = 31
+)
+
+// simple values
+const (
+	simpleFalse = 20
+	simpleTrue  = 21
+	simpleNull  = 22
+)
+
+// maximum nesting of arrays, maps and tags
+const maxDepth = 32
+
+// Tag is a tagged item
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// Unmarshal decodes a single CBOR item
+func Unmarshal(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errors.New("cbor: extra data after item")
+	}
+
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+// head reads the head of an item, returning its major type, additional info and argument
+func (d *decoder) head() (byte, byte, uint64, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info := b[0]>>5, b[0]&0x1f
+
+	var size uint64
+
+	switch {
+	case info < infoUint8:
+		return major, info, uint64(info), nil
+	case info == infoUint8:
+		size = 1
+	case info == infoUint16:
+		size = 2
+	case info == infoUint32:
+		size = 4
+	case info == infoUint64:
+		size = 8
+	case info == infoIndefinite:
+		return 0, 0, 0, errors.New("cbor: indefinite length items are not supported")
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: invalid additional info %d", info)
+	}
+
+	arg, err := d.read(size)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var val uint64
+	for _, c := range arg {
+		val = val<<8 | uint64(c)
+	}
+
+	return major, info, val, nil
+}
+
+func (d *decoder) decode(depth int) (interface{}, error) { // nolint: gocyclo
+	if depth > maxDepth {
+		return nil, errors.New("cbor: maximum nesting depth exceeded")
+	}
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		return arg, nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflow")
+		}
+
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+	case majorText:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(b), nil
+	case majorArray:
+		return d.decodeArray(arg, depth)
+	case majorMap:
+		return d.decodeMap(arg, depth)
+	case majorTag:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return Tag{Number: arg, Content: content}, nil
+	default:
+		return decodeSimple(info, arg)
+	}
+}
+
+func (d *decoder) decodeArray(n uint64, depth int) (interface{}, error) {
+	// each item takes at least one byte
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+
+	arr := make([]interface{}, n)
+
+	for i := range arr {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		arr[i] = item
+	}
+
+	return arr, nil
+}
+
+func (d *decoder) decodeMap(n uint64, depth int) (interface{}, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+
+	m := make(map[interface{}]interface{}, n)
+
+	for i := uint64(0); i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key.(type) {
+		case uint64, int64, string:
+		default:
+			return nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+		}
+
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("cbor: duplicate map key %v", key)
+		}
+
+		val, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = val
+	}
+
+	return m, nil
+}
+
+func decodeSimple(info byte, arg uint64) (interface{}, error) {
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull:
+		return nil, nil
+	case infoUint16:
+		return float64(float16(uint16(arg))), nil
+	case infoUint32:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case infoUint64:
+		return math.Float64frombits(arg), nil
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+// float16 converts a half precision float
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var val float64
+
+	switch exp {
+	case 0:
+		val = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			val = math.Inf(1)
+		} else {
+			val = math.NaN()
+		}
+	default:
+		val = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -val
+	}
+
+	return val
+}
+
+// Marshal encodes a value as CBOR. Supported values are nil, bool, integers, float64, string, []byte, Tag,
+// []interface{}, map[string]interface{} and map[interface{}]interface{} with integer or string keys. Map keys
+// are sorted in the canonical order of RFC 7049, so the encoding is deterministic.
+func Marshal(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if err := encode(buf, v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < infoUint8:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(major<<5 | infoUint8)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | infoUint16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(arg)) // nolint: errcheck
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | infoUint32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(arg)) // nolint: errcheck
+	default:
+		buf.WriteByte(major<<5 | infoUint64)
+		_ = binary.Write(buf, binary.BigEndian, arg) // nolint: errcheck
+	}
+}
+
+func encode(buf *bytes.Buffer, v interface{}) error { // nolint: gocyclo
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(majorSimple<<5 | simpleNull)
+	case bool:
+		if val {
+			buf.WriteByte(majorSimple<<5 | simpleTrue)
+		} else {
+			buf.WriteByte(majorSimple<<5 | simpleFalse)
+		}
+	case int:
+		encodeInt(buf, int64(val))
+	case int64:
+		encodeInt(buf, val)
+	case uint64:
+		writeHead(buf, majorUint, val)
+	case float64:
+		buf.WriteByte(majorSimple<<5 | infoUint64)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(val)) // nolint: errcheck
+	case string:
+		writeHead(buf, majorText, uint64(len(val)))
+		buf.WriteString(val)
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(val)))
+		buf.Write(val)
+	case Tag:
+		writeHead(buf, majorTag, val.Number)
+		return encode(buf, val.Content)
+	case []interface{}:
+		writeHead(buf, majorArray, uint64(len(val)))
+
+		for _, item := range val {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(val))
+		for k, item := range val {
+			m[k] = item
+		}
+
+		return encodeMap(buf, m)
+	case map[interface{}]interface{}:
+		return encodeMap(buf, val)
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, i int64) {
+	if i < 0 {
+		writeHead(buf, majorNegInt, uint64(-1-i))
+
+		return
+	}
+
+	writeHead(buf, majorUint, uint64(i))
+}
+
+func encodeMap(buf *bytes.Buffer, m map[interface{}]interface{}) error {
+	type entry struct {
+		key []byte
+		val interface{}
+	}
+
+	entries := make([]entry, 0, len(m))
+
+	for k, val := range m {
+		switch k.(type) {
+		case int, int64, uint64, string:
+		default:
+			return fmt.Errorf("cbor: unsupported map key type %T", k)
+		}
+
+		key, err := Marshal(k)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry{key: key, val: val})
+	}
+
+	// canonical order: shorter keys first, then bytewise
+	sort.Slice(entries, func(i, j int) bool {
+		if len(entries[i].key) != len(entries[j].key) {
+			return len(entries[i].key) < len(entries[j].key)
+		}
+
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeHead(buf, majorMap, uint64(len(entries)))
+
+	for _, e := range entries {
+		buf.Write(e.key)
+
+		if err := encode(buf, e.val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ToJSON converts a CBOR encoded item to JSON. Maps must have string keys, byte strings and tags aren't supported.
+func ToJSON(data []byte) ([]byte, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonValue, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonValue)
+}
+
+// FromJSON converts JSON to CBOR, encoding integral numbers as integers
+func FromJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return Marshal(fromJSONValue(v))
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+
+		for k, item := range val {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("cbor: map key %v is not a string", k)
+			}
+
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			m[key] = converted
+		}
+
+		return m, nil
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+
+		for i, item := range val {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			arr[i] = converted
+		}
+
+		return arr, nil
+	case []byte, Tag:
+		return nil, fmt.Errorf("cbor: %T can't be converted to json", v)
+	default:
+		return v, nil
+	}
+}
+
+func fromJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = fromJSONValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = fromJSONValue(item)
+		}
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+
+		if u, err := strconv.ParseUint(string(val), 10, 64); err == nil {
+			return u
+		}
+
+		f, err := val.Float64()
+		if err != nil {
+			return string(val)
+		}
+
+		return f
+	}
+
+	return v
+}
@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	// examples from RFC 7049 appendix A
+	tests := []struct {
+		hex   string
+		value interface{}
+	}{
+		{"00", uint64(0)},
+		{"17", uint64(23)},
+		{"1818", uint64(24)},
+		{"1903e8", uint64(1000)},
+		{"1a000f4240", uint64(1000000)},
+		{"1b000000e8d4a51000", uint64(1000000000000)},
+		{"20", int64(-1)},
+		{"3903e7", int64(-1000)},
+		{"f90000", float64(0)},
+		{"f93c00", float64(1)},
+		{"f9c400", float64(-4)},
+		{"fa47c35000", float64(100000)},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"40", []byte{}},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"83010203", []interface{}{uint64(1), uint64(2), uint64(3)}},
+		{"a201020304", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		{"a26161016162820203", map[interface{}]interface{}{
+			"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"c074323031332d30332d32315432303a30343a30305a", Tag{Number: 0, Content: "2013-03-21T20:04:00Z"}},
+	}
+
+	for _, tc := range tests {
+		data, err := hex.DecodeString(tc.hex)
+		require.NoError(t, err)
+
+		v, err := Unmarshal(data)
+		require.NoError(t, err, tc.hex)
+		require.Equal(t, tc.value, v, tc.hex)
+	}
+
+	t.Run("special floats", func(t *testing.T) {
+		v, err := Unmarshal([]byte{0xf9, 0x7c, 0x00})
+		require.NoError(t, err)
+		require.True(t, math.IsInf(v.(float64), 1))
+
+		v, err = Unmarshal([]byte{0xf9, 0x7e, 0x00})
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(v.(float64)))
+
+		v, err = Unmarshal([]byte{0xf9, 0x00, 0x01})
+		require.NoError(t, err)
+		require.Equal(t, 5.960464477539063e-8, v)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		errTests := []struct {
+			hex string
+			err string
+		}{
+			{"", "cbor: unexpected end of data"},
+			{"1903", "cbor: unexpected end of data"},
+			{"0000", "cbor: extra data after item"},
+			{"5f", "cbor: indefinite length items are not supported"},
+			{"1c", "cbor: invalid additional info 28"},
+			{"3bffffffffffffffff", "cbor: negative integer overflow"},
+			{"4501", "cbor: unexpected end of data"},
+			{"6501", "cbor: unexpected end of data"},
+			{"9bffffffffffffffff", "cbor: unexpected end of data"},
+			{"bbffffffffffffffff", "cbor: unexpected end of data"},
+			{"8201", "cbor: unexpected end of data"},
+			{"a1f600", "cbor: unsupported map key type <nil>"},
+			{"a2010201", "cbor: duplicate map key 1"},
+			{"a101", "cbor: unexpected end of data"},
+			{"a1", "cbor: unexpected end of data"},
+			{"c1", "cbor: unexpected end of data"},
+			{"f0", "cbor: unsupported simple value 16"},
+		}
+
+		for _, tc := range errTests {
+			data, err := hex.DecodeString(tc.hex)
+			require.NoError(t, err)
+
+			_, err = Unmarshal(data)
+			require.EqualError(t, err, tc.err, tc.hex)
+		}
+	})
+
+	t.Run("error - too deeply nested", func(t *testing.T) {
+		data := make([]byte, maxDepth+2)
+		for i := range data {
+			data[i] = 0x81
+		}
+
+		_, err := Unmarshal(append(data, 0x00))
+		require.EqualError(t, err, "cbor: maximum nesting depth exceeded")
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		hex   string
+	}{
+		{nil, "f6"},
+		{true, "f5"},
+		{false, "f4"},
+		{0, "00"},
+		{int64(1000), "1903e8"},
+		{uint64(1000000), "1a000f4240"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{"IETF", "6449455446"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{Tag{Number: 18, Content: []interface{}{}}, "d280"},
+		{[]interface{}{1, []interface{}{2, 3}}, "8201820203"},
+		// keys are sorted by length, then bytewise
+		{map[string]interface{}{"bb": 2, "a": 1, "c": 3}, "a361610161630362626202"},
+		{map[interface{}]interface{}{"a": 3, -1: 2, 10: 1}, "a30a012002616103"},
+	}
+
+	for _, tc := range tests {
+		data, err := Marshal(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.hex, hex.EncodeToString(data))
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Marshal(struct{}{})
+		require.EqualError(t, err, "cbor: unsupported type struct {}")
+
+		_, err = Marshal([]interface{}{struct{}{}})
+		require.EqualError(t, err, "cbor: unsupported type struct {}")
+
+		_, err = Marshal(map[interface{}]interface{}{true: 1})
+		require.EqualError(t, err, "cbor: unsupported map key type bool")
+
+		_, err = Marshal(map[string]interface{}{"a": struct{}{}})
+		require.EqualError(t, err, "cbor: unsupported type struct {}")
+
+		_, err = Marshal(Tag{Number: 1, Content: struct{}{}})
+		require.EqualError(t, err, "cbor: unsupported type struct {}")
+	})
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		doc := `{"domain":"consortium.net","members":[{"did":"did:example:123","weight":-2}],` +
+			`"policy":{"cache":{"max_age":18446744073709551615},"ratio":0.5},"previous":null,"signed":true}`
+
+		data, err := FromJSON([]byte(doc))
+		require.NoError(t, err)
+
+		v, err := Unmarshal(data)
+		require.NoError(t, err)
+		require.Equal(t, "consortium.net", v.(map[interface{}]interface{})["domain"])
+
+		converted, err := ToJSON(data)
+		require.NoError(t, err)
+		require.JSONEq(t, doc, string(converted))
+	})
+
+	t.Run("error - invalid json", func(t *testing.T) {
+		_, err := FromJSON([]byte("{"))
+		require.Error(t, err)
+	})
+
+	t.Run("error - invalid cbor", func(t *testing.T) {
+		_, err := ToJSON([]byte{0xa1})
+		require.EqualError(t, err, "cbor: unexpected end of data")
+	})
+
+	t.Run("error - not json compatible", func(t *testing.T) {
+		_, err := ToJSON([]byte{0xa1, 0x01, 0x02})
+		require.EqualError(t, err, "cbor: map key 1 is not a string")
+
+		_, err = ToJSON([]byte{0x81, 0x41, 0x00})
+		require.EqualError(t, err, "cbor: []uint8 can't be converted to json")
+
+		_, err = ToJSON([]byte{0xa1, 0x61, 0x61, 0xc1, 0x00})
+		require.EqualError(t, err, "cbor: cbor.Tag can't be converted to json")
+	})
+}
@@ -0,0 +1,17 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package clock adapts injected time sources to the clocks of caches.
+package clock
+
+import "time"
+
+// Func adapts a function returning the current time to a cache clock
+type Func func() time.Time
+
+// Now returns the current time of the function
+func (f Func) Now() time.Time {
+	return f()
+}
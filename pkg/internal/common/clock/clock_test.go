@@ -0,0 +1,30 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunc(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	cache := gcache.New(1).LRU().Clock(Func(func() time.Time { return now })).Build()
+	require.NoError(t, cache.SetWithExpire("key", "value", time.Minute))
+
+	value, err := cache.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+
+	now = now.Add(time.Minute + time.Second)
+
+	_, err = cache.Get("key")
+	require.Equal(t, gcache.KeyNotFoundError, err)
+}
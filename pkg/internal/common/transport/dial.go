@@ -0,0 +1,21 @@
+//go:build !js
+// +build !js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"context"
+	"net"
+)
+
+func dialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}).DialContext
+}
@@ -0,0 +1,19 @@
+//go:build js
+// +build js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"context"
+	"net"
+)
+
+// dialContext returns no dialer in the browser: a transport without a dialer sends requests with the fetch API
+func dialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CheckHTTPS returns an error if the given url isn't an https url, unless its host is one of the hosts
+// allowed to be reached over plain http (given as hostname or host:port)
+func CheckHTTPS(rawURL string, allowInsecure []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %w", rawURL, err)
+	}
+
+	return checkHTTPS(u, allowInsecure)
+}
+
+func checkHTTPS(u *url.URL, allowInsecure []string) error {
+	if u.Scheme == "https" {
+		return nil
+	}
+
+	if u.Scheme == "http" {
+		for _, host := range allowInsecure {
+			if host == u.Host || host == u.Hostname() {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("refusing %s request to %s: only https is allowed", u.Scheme, u.Host)
+}
+
+type httpsOnly struct {
+	rt            http.RoundTripper
+	allowInsecure []string
+}
+
+// HTTPSOnly wraps the given round tripper to refuse requests other than https, except to the hosts allowed
+// to be reached over plain http
+func HTTPSOnly(rt http.RoundTripper, allowInsecure []string) http.RoundTripper {
+	return &httpsOnly{rt: rt, allowInsecure: allowInsecure}
+}
+
+// RoundTrip executes a single https request
+func (t *httpsOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkHTTPS(req.URL, t.allowInsecure); err != nil {
+		return nil, err
+	}
+
+	return t.rt.RoundTrip(req)
+}
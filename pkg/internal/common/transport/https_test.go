@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHTTPS(t *testing.T) {
+	require.NoError(t, CheckHTTPS("https://example.com/path", nil))
+	require.NoError(t, CheckHTTPS("http://localhost:8080/path", []string{"localhost"}))
+	require.NoError(t, CheckHTTPS("http://localhost:8080/path", []string{"localhost:8080"}))
+
+	err := CheckHTTPS("http://example.com/path", []string{"localhost"})
+	require.EqualError(t, err, "refusing http request to example.com: only https is allowed")
+
+	err = CheckHTTPS("ftp://localhost/path", []string{"localhost"})
+	require.EqualError(t, err, "refusing ftp request to localhost: only https is allowed")
+
+	err = CheckHTTPS("%", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid url")
+}
+
+func TestHTTPSOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Run("success - insecure http allowed", func(t *testing.T) {
+		client := &http.Client{Transport: HTTPSOnly(http.DefaultTransport, []string{"127.0.0.1"})}
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("failure - insecure http refused", func(t *testing.T) {
+		client := &http.Client{Transport: HTTPSOnly(http.DefaultTransport, nil)}
+
+		resp, err := client.Get(srv.URL) //nolint: bodyclose
+		require.Error(t, err)
+		require.Nil(t, resp)
+		require.Contains(t, err.Error(), "only https is allowed")
+	})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// DefaultMaxResponseSize is the default maximum size of a response body read from a server, eg. a config file,
+// a did-configuration or a DID resolution result
+const DefaultMaxResponseSize = 1 << 20
+
+// ErrResponseTooLarge is returned when a response body is larger than the maximum size
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ReadLimited reads a response body, failing with ErrResponseTooLarge if it's larger than max bytes. At most
+// max+1 bytes are read, so a huge response doesn't spike memory.
+func ReadLimited(r io.Reader, max int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > max {
+		return nil, tooLarge(max)
+	}
+
+	return body, nil
+}
+
+// DecodeJSON decodes a JSON response body into v while it's read, instead of buffering it first, failing with
+// ErrResponseTooLarge if it's larger than max bytes
+func DecodeJSON(r io.Reader, max int64, v interface{}) error {
+	lr := &io.LimitedReader{R: r, N: max + 1}
+
+	err := json.NewDecoder(lr).Decode(v)
+
+	// the limit was reached: the body is larger than max, whether or not the decoding failed because of it
+	if lr.N <= 0 {
+		return tooLarge(max)
+	}
+
+	return err
+}
+
+func tooLarge(max int64) error {
+	return fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, max)
+}
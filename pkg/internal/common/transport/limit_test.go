@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLimited(t *testing.T) {
+	body, err := ReadLimited(strings.NewReader("12345"), 5)
+	require.NoError(t, err)
+	require.Equal(t, "12345", string(body))
+
+	_, err = ReadLimited(strings.NewReader("123456"), 5)
+	require.True(t, errors.Is(err, ErrResponseTooLarge))
+	require.EqualError(t, err, "response body too large: more than 5 bytes")
+
+	_, err = ReadLimited(errReader{}, 5)
+	require.EqualError(t, err, "read error")
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var v map[string]string
+
+	require.NoError(t, DecodeJSON(strings.NewReader(`{"a":"b"}`), 9, &v))
+	require.Equal(t, map[string]string{"a": "b"}, v)
+
+	err := DecodeJSON(strings.NewReader(`{"a":"bc"}`), 9, &v)
+	require.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	// the value fits, but the body is larger
+	err = DecodeJSON(strings.NewReader(`{"a":"b"}`+strings.Repeat(" ", 10)), 9, &v)
+	require.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	err = DecodeJSON(strings.NewReader(`{"a":1}`), 9, &v)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot unmarshal number")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
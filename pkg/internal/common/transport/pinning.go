@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const pinPrefix = "sha256/"
+
+// WithPins returns a copy of the given TLS config requiring the certificate chain presented by host to match
+// one of the host's pins. If host has no pins the config is returned as is.
+//
+// A pin is the base64 encoded SHA-256 digest of either the DER encoded certificate or its subject public key
+// info, optionally prefixed with "sha256/" (as in HPKP). A chain matches if any of its certificates matches
+// any of the pins, so pinning an intermediate or root CA key allows leaf certificates to be rotated.
+func WithPins(tlsConfig *tls.Config, host string, pins map[string][]string) *tls.Config {
+	hostPins := pins[hostname(host)]
+	if len(hostPins) == 0 {
+		return tlsConfig
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{} //nolint: gosec
+	}
+
+	c := tlsConfig.Clone()
+	verify := tlsConfig.VerifyPeerCertificate
+
+	c.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if verify != nil {
+			if err := verify(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+
+		return verifyPins(hostname(host), hostPins, rawCerts, verifiedChains)
+	}
+
+	return c
+}
+
+// dialTLS returns a dial function performing the TLS handshake itself, so the certificates presented by each
+// host can be checked against the host's pins
+func dialTLS(tlsConfig *tls.Config, pins map[string][]string,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(network, addr string) (net.Conn, error) {
+	return func(network, addr string) (net.Conn, error) {
+		host := hostname(addr)
+
+		c := WithPins(tlsConfig, host, pins)
+		if c == tlsConfig {
+			c = tlsConfig.Clone()
+		}
+
+		if c.ServerName == "" {
+			c.ServerName = host
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), dialTimeout+tlsHandshakeTimeout)
+		defer cancel()
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, c)
+
+		errc := make(chan error, 1)
+
+		go func() { errc <- tlsConn.Handshake() }()
+
+		select {
+		case err = <-errc:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if err != nil {
+			conn.Close() //nolint: errcheck,gosec
+
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// noProxyForPins wraps the proxy function of a transport to refuse sending https requests to pinned hosts through
+// a proxy: the TLS handshake of a proxied request is made by the http transport instead of dialTLS, so the pins
+// of the host wouldn't be checked
+func noProxyForPins(proxy func(*http.Request) (*url.URL, error),
+	pins map[string][]string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+
+		if req.URL.Scheme == "https" && len(pins[req.URL.Hostname()]) > 0 {
+			return nil, fmt.Errorf("refusing to send request to %s through proxy %s: its certificate is pinned",
+				req.URL.Host, proxyURL.Host)
+		}
+
+		return proxyURL, nil
+	}
+}
+
+func verifyPins(host string, pins []string, rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	var certs []*x509.Certificate
+
+	// with InsecureSkipVerify there are no verified chains, so fall back to the certificates presented by the peer
+	if len(verifiedChains) > 0 {
+		for _, chain := range verifiedChains {
+			certs = append(certs, chain...)
+		}
+	} else {
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse certificate of %s: %w", host, err)
+			}
+
+			certs = append(certs, cert)
+		}
+	}
+
+	for _, cert := range certs {
+		certPin := pin(cert.Raw)
+		keyPin := pin(cert.RawSubjectPublicKeyInfo)
+
+		for _, p := range pins {
+			p = strings.TrimPrefix(p, pinPrefix)
+
+			if p == certPin || p == keyPin {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("certificate chain of %s doesn't match any pinned certificate", host)
+}
+
+func pin(der []byte) string {
+	digest := sha256.Sum256(der)
+
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+func hostname(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
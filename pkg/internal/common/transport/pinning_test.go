@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_PinnedCertificates(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	cert := srv.Certificate()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	get := func(pins ...string) error {
+		tr := New(&tls.Config{RootCAs: roots}, //nolint: gosec
+			&Options{PinnedCertificates: map[string][]string{u.Hostname(): pins}})
+		defer tr.CloseIdleConnections()
+
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	t.Run("success - public key pin", func(t *testing.T) {
+		require.NoError(t, get("other", pinPrefix+pin(cert.RawSubjectPublicKeyInfo)))
+	})
+
+	t.Run("success - certificate pin", func(t *testing.T) {
+		require.NoError(t, get(pin(cert.Raw)))
+	})
+
+	t.Run("success - host isn't pinned", func(t *testing.T) {
+		tr := New(&tls.Config{RootCAs: roots}, //nolint: gosec
+			&Options{PinnedCertificates: map[string][]string{"other.example.com": {"other"}}})
+		defer tr.CloseIdleConnections()
+
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("failure - no pin matches", func(t *testing.T) {
+		err := get("other")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match any pinned certificate")
+	})
+
+	t.Run("failure - pinned host behind a proxy", func(t *testing.T) {
+		proxyURL, err := url.Parse("http://proxy.example.com:3128")
+		require.NoError(t, err)
+
+		tr := New(&tls.Config{RootCAs: roots}, //nolint: gosec
+			&Options{PinnedCertificates: map[string][]string{u.Hostname(): {pin(cert.Raw)}}})
+		defer tr.CloseIdleConnections()
+
+		// the proxy the environment would set
+		tr.Proxy = noProxyForPins(http.ProxyURL(proxyURL), map[string][]string{u.Hostname(): {pin(cert.Raw)}})
+
+		_, err = (&http.Client{Transport: tr}).Get(srv.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "through proxy proxy.example.com:3128: its certificate is pinned")
+	})
+}
+
+func TestNoProxyForPins(t *testing.T) {
+	proxyURL, err := url.Parse("http://proxy.example.com:3128")
+	require.NoError(t, err)
+
+	proxy := noProxyForPins(http.ProxyURL(proxyURL), map[string][]string{"pinned.example.com": {"pin"}})
+
+	for _, reqURL := range []string{"https://other.example.com", "http://pinned.example.com"} {
+		req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+		require.NoError(t, err)
+
+		u, err := proxy(req)
+		require.NoError(t, err)
+		require.Equal(t, proxyURL, u)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://pinned.example.com:443/path", nil)
+	require.NoError(t, err)
+
+	_, err = proxy(req)
+	require.Error(t, err)
+
+	// without a proxy, the pins are checked by dialTLS
+	req, err = http.NewRequest(http.MethodGet, "https://pinned.example.com", nil)
+	require.NoError(t, err)
+
+	u, err := noProxyForPins(http.ProxyFromEnvironment, map[string][]string{"pinned.example.com": {"pin"}})(req)
+	require.NoError(t, err)
+	require.Nil(t, u)
+}
+
+func TestWithPins(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	cert := srv.Certificate()
+	pins := map[string][]string{"example.com": {pin(cert.RawSubjectPublicKeyInfo)}}
+
+	t.Run("host isn't pinned", func(t *testing.T) {
+		tlsConfig := &tls.Config{} //nolint: gosec
+		require.True(t, tlsConfig == WithPins(tlsConfig, "other.example.com", pins))
+	})
+
+	t.Run("verified chains", func(t *testing.T) {
+		c := WithPins(nil, "example.com:443", pins)
+		require.NoError(t, c.VerifyPeerCertificate(nil, [][]*x509.Certificate{{cert}}))
+	})
+
+	t.Run("raw certificates", func(t *testing.T) {
+		c := WithPins(nil, "example.com", pins)
+		require.NoError(t, c.VerifyPeerCertificate([][]byte{cert.Raw}, nil))
+
+		err := c.VerifyPeerCertificate([][]byte{[]byte("invalid")}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse certificate of example.com")
+	})
+
+	t.Run("chains existing verification", func(t *testing.T) {
+		c := WithPins(&tls.Config{ //nolint: gosec
+			VerifyPeerCertificate: func([][]byte, [][]*x509.Certificate) error {
+				return errors.New("custom verification failed")
+			}}, "example.com", pins)
+
+		err := c.VerifyPeerCertificate([][]byte{cert.Raw}, nil)
+		require.EqualError(t, err, "custom verification failed")
+	})
+}
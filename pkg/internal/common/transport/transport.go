@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+)
+
+const (
+	// DefaultMaxIdleConns is the default maximum number of idle (keep-alive) connections across all hosts
+	DefaultMaxIdleConns = 100
+	// DefaultMaxIdleConnsPerHost is the default maximum number of idle (keep-alive) connections kept per host
+	DefaultMaxIdleConnsPerHost = 10
+	// DefaultIdleConnTimeout is the default time an idle connection is kept open before being closed
+	DefaultIdleConnTimeout = 90 * time.Second
+	// DefaultTLSSessionCacheSize is the default number of TLS sessions cached for session resumption
+	DefaultTLSSessionCacheSize = 64
+
+	dialTimeout         = 30 * time.Second
+	keepAlive           = 30 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// Options holds the connection pool settings of a shared transport
+type Options struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	// PinnedCertificates maps hosts to the pins their certificate chain must match, see WithPins
+	PinnedCertificates map[string][]string
+	// DialContext dials the connections of the transport instead of a net.Dialer, eg. to route test domains
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// TLSPolicy is the minimum TLS version and the cipher suites enforced on connections, see WithPolicy
+	TLSPolicy *tlspolicy.Policy
+}
+
+// New creates an http transport intended to be shared by all http clients of a component, so that
+// connections are kept alive and reused across requests instead of re-establishing a TLS session per request.
+func New(tlsConfig *tls.Config, opts *Options) *http.Transport {
+	var policy *tlspolicy.Policy
+
+	if opts != nil {
+		policy = opts.TLSPolicy
+	}
+
+	tlsConfig = WithSessionCache(WithPolicy(tlsConfig, policy))
+
+	o := Options{
+		MaxIdleConns:        DefaultMaxIdleConns,
+		MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     DefaultIdleConnTimeout,
+	}
+
+	if opts != nil {
+		if opts.MaxIdleConns > 0 {
+			o.MaxIdleConns = opts.MaxIdleConns
+		}
+
+		if opts.MaxIdleConnsPerHost > 0 {
+			o.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		}
+
+		if opts.IdleConnTimeout > 0 {
+			o.IdleConnTimeout = opts.IdleConnTimeout
+		}
+
+		o.PinnedCertificates = opts.PinnedCertificates
+		o.DialContext = opts.DialContext
+	}
+
+	dial := o.DialContext
+	if dial == nil {
+		dial = dialContext()
+	}
+
+	t := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dial,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+		MaxIdleConns:        o.MaxIdleConns,
+		MaxIdleConnsPerHost: o.MaxIdleConnsPerHost,
+		IdleConnTimeout:     o.IdleConnTimeout,
+	}
+
+	if len(o.PinnedCertificates) > 0 {
+		t.DialTLS = dialTLS(tlsConfig, o.PinnedCertificates, dial)
+		t.Proxy = noProxyForPins(t.Proxy, o.PinnedCertificates)
+	}
+
+	return t
+}
+
+// WithSessionCache returns a copy of the given TLS config with a client session cache enabled,
+// allowing TLS sessions to be resumed. If the config already has a session cache it is returned as is.
+func WithSessionCache(tlsConfig *tls.Config) *tls.Config {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{} //nolint: gosec
+	}
+
+	if tlsConfig.ClientSessionCache != nil {
+		return tlsConfig
+	}
+
+	c := tlsConfig.Clone()
+	c.ClientSessionCache = tls.NewLRUClientSessionCache(DefaultTLSSessionCacheSize)
+
+	return c
+}
+
+// WithPolicy returns a copy of the given TLS config complying with the given TLS policy, or with the default policy
+// (TLS 1.2 or later with modern cipher suites) if the policy is nil
+func WithPolicy(tlsConfig *tls.Config, policy *tlspolicy.Policy) *tls.Config {
+	if policy == nil {
+		policy = tlspolicy.Default()
+	}
+
+	return policy.Apply(tlsConfig)
+}
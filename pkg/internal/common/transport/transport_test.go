@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("default options", func(t *testing.T) {
+		tr := New(nil, nil)
+		require.Equal(t, DefaultMaxIdleConns, tr.MaxIdleConns)
+		require.Equal(t, DefaultMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+		require.Equal(t, DefaultIdleConnTimeout, tr.IdleConnTimeout)
+		require.NotNil(t, tr.TLSClientConfig.ClientSessionCache)
+		require.Equal(t, uint16(tls.VersionTLS12), tr.TLSClientConfig.MinVersion)
+		require.Equal(t, tlspolicy.DefaultCipherSuites(), tr.TLSClientConfig.CipherSuites)
+	})
+
+	t.Run("custom TLS policy", func(t *testing.T) {
+		tr := New(nil, &Options{TLSPolicy: &tlspolicy.Policy{MinVersion: tls.VersionTLS13,
+			CipherSuites: tlspolicy.DefaultCipherSuites()}})
+		require.Equal(t, uint16(tls.VersionTLS13), tr.TLSClientConfig.MinVersion)
+	})
+
+	t.Run("custom options", func(t *testing.T) {
+		tlsConfig := &tls.Config{ServerName: "example.com"} //nolint: gosec
+
+		tr := New(tlsConfig, &Options{MaxIdleConns: 5, MaxIdleConnsPerHost: 2, IdleConnTimeout: time.Second})
+		require.Equal(t, 5, tr.MaxIdleConns)
+		require.Equal(t, 2, tr.MaxIdleConnsPerHost)
+		require.Equal(t, time.Second, tr.IdleConnTimeout)
+		require.Equal(t, "example.com", tr.TLSClientConfig.ServerName)
+		require.NotNil(t, tr.TLSClientConfig.ClientSessionCache)
+		require.Nil(t, tlsConfig.ClientSessionCache)
+	})
+
+	t.Run("custom dialer", func(t *testing.T) {
+		var dialed string
+
+		tr := New(nil, &Options{DialContext: func(_ context.Context, _, addr string) (net.Conn, error) {
+			dialed = addr
+
+			return nil, errors.New("dial error")
+		}})
+
+		_, err := tr.DialContext(context.Background(), "tcp", "example.com:443")
+		require.EqualError(t, err, "dial error")
+		require.Equal(t, "example.com:443", dialed)
+	})
+}
+
+func TestWithSessionCache(t *testing.T) {
+	tlsConfig := WithSessionCache(nil)
+	require.NotNil(t, tlsConfig.ClientSessionCache)
+
+	require.True(t, tlsConfig == WithSessionCache(tlsConfig))
+}
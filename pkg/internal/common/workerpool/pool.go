@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package workerpool bounds the number of goroutines started by a component for its concurrent and background tasks.
+//
+// A pool doesn't queue tasks: when all its workers are busy, concurrent work is done by the calling goroutine and
+// background tasks are refused. Tasks running on the pool can therefore use it themselves without deadlocking.
+package workerpool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultSize is the default number of workers of a pool
+const DefaultSize = 16
+
+// Pool is a pool of workers, safe for concurrent use
+type Pool struct {
+	workers chan struct{}
+}
+
+// New creates a pool with the given number of workers, at least one
+func New(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Pool{workers: make(chan struct{}, size)}
+}
+
+// Size returns the number of workers of the pool
+func (p *Pool) Size() int {
+	return cap(p.workers)
+}
+
+func (p *Pool) acquire() bool {
+	select {
+	case p.workers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pool) release() {
+	<-p.workers
+}
+
+// Go runs f in the background on a worker of the pool. It returns false, without running f, if all the workers
+// are busy.
+func (p *Pool) Go(f func()) bool {
+	if !p.acquire() {
+		return false
+	}
+
+	go func() {
+		defer p.release()
+
+		f()
+	}()
+
+	return true
+}
+
+// Run calls f for each index in [0,n) and waits for all of them to return. The calls are shared by the calling
+// goroutine and up to limit-1 workers, as available, so at most limit calls run concurrently.
+func (p *Pool) Run(n, limit int, f func(i int)) {
+	next := int64(-1)
+
+	work := func() {
+		for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+			f(i)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	// the calling goroutine is a worker too
+	for w := 1; w < limit && w < n && p.acquire(); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer p.release()
+
+			work()
+		}()
+	}
+
+	work()
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workerpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// meter records the maximum number of calls running at the same time
+type meter struct {
+	running int32
+	max     int32
+}
+
+func (m *meter) run(d time.Duration) {
+	n := atomic.AddInt32(&m.running, 1)
+
+	for {
+		max := atomic.LoadInt32(&m.max)
+		if n <= max || atomic.CompareAndSwapInt32(&m.max, max, n) {
+			break
+		}
+	}
+
+	time.Sleep(d)
+	atomic.AddInt32(&m.running, -1)
+}
+
+func TestPool_Run(t *testing.T) {
+	t.Run("all calls are made", func(t *testing.T) {
+		for _, limit := range []int{0, 1, 4, 100} {
+			p := New(4)
+			called := make([]int32, 20)
+
+			p.Run(len(called), limit, func(i int) {
+				atomic.AddInt32(&called[i], 1)
+			})
+
+			for i := range called {
+				require.EqualValues(t, 1, called[i], "limit %d, call %d", limit, i)
+			}
+		}
+	})
+
+	t.Run("concurrency is bounded by the limit and the pool size", func(t *testing.T) {
+		m := &meter{}
+		New(8).Run(20, 3, func(int) { m.run(5 * time.Millisecond) })
+		require.EqualValues(t, 3, m.max)
+
+		m = &meter{}
+		New(2).Run(20, 10, func(int) { m.run(5 * time.Millisecond) })
+		require.EqualValues(t, 3, m.max) // the pool workers and the calling goroutine
+
+		m = &meter{}
+		New(8).Run(20, 1, func(int) { m.run(time.Millisecond) })
+		require.EqualValues(t, 1, m.max)
+	})
+
+	t.Run("nested use doesn't deadlock", func(t *testing.T) {
+		p := New(2)
+
+		var count int32
+
+		p.Run(4, 4, func(int) {
+			p.Run(4, 4, func(int) {
+				atomic.AddInt32(&count, 1)
+			})
+		})
+
+		require.EqualValues(t, 16, count)
+	})
+}
+
+func TestPool_Go(t *testing.T) {
+	p := New(0)
+	require.Equal(t, 1, p.Size())
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	release := make(chan struct{})
+
+	require.True(t, p.Go(func() {
+		defer wg.Done()
+		<-release
+	}))
+
+	// the only worker is busy
+	require.False(t, p.Go(func() {}))
+
+	close(release)
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return p.Go(func() {})
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package jsoncanonicalizer implements the JSON Canonicalization Scheme (JCS) defined by RFC 8785.
+package jsoncanonicalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// Transform returns the RFC 8785 canonical form of the given JSON data
+func Transform(jsonData []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level json value")
+	}
+
+	return Canonicalize(value)
+}
+
+// Canonicalize returns the RFC 8785 canonical form of the given value, which must consist of the types
+// produced by unmarshalling JSON into an interface{} (numbers may be float64 or json.Number)
+func Canonicalize(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := write(&buf, value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func write(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeString(buf, v)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %s: %w", v, err)
+		}
+
+		return writeNumber(buf, f)
+	case float64:
+		return writeNumber(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := write(buf, elem); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		return writeObject(buf, v)
+	default:
+		return fmt.Errorf("unsupported json type %T", value)
+	}
+
+	return nil
+}
+
+func writeObject(buf *bytes.Buffer, obj map[string]interface{}) error {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	// properties are sorted by the UTF-16 code units of their names
+	sort.Slice(keys, func(i, j int) bool {
+		return lessUTF16(keys[i], keys[j])
+	})
+
+	buf.WriteByte('{')
+
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		writeString(buf, k)
+		buf.WriteByte(':')
+
+		if err := write(buf, obj[k]); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+
+	return len(ua) < len(ub)
+}
+
+// writeString serializes a string as specified for JSON.stringify in ECMAScript,
+// escaping only quotes, backslashes and control characters
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xF])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+
+	buf.WriteByte('"')
+}
+
+// writeNumber serializes a number as specified for Number.prototype.toString in ECMAScript
+func writeNumber(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("invalid number %v", f)
+	}
+
+	if f == 0 {
+		buf.WriteByte('0')
+
+		return nil
+	}
+
+	if f < 0 {
+		buf.WriteByte('-')
+
+		f = -f
+	}
+
+	format := byte('e')
+	if f >= 1e-6 && f < 1e21 {
+		format = 'f'
+	}
+
+	s := strconv.FormatFloat(f, format, -1, 64)
+
+	// ECMAScript doesn't zero-pad the exponent, eg. 1e+07 is written as 1e+7
+	if e := strings.IndexByte(s, 'e'); e > 0 && s[e+2] == '0' {
+		s = s[:e+2] + s[e+3:]
+	}
+
+	buf.WriteString(s)
+
+	return nil
+}
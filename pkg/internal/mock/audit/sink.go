@@ -0,0 +1,23 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
+)
+
+// Sink is the mock audit sink
+type Sink struct {
+	Records  []*audit.Record
+	WriteErr error
+}
+
+// Write records the audit record
+func (s *Sink) Write(record *audit.Record) error {
+	s.Records = append(s.Records, record)
+
+	return s.WriteErr
+}
@@ -15,9 +15,22 @@ import (
 type Client struct {
 	CreateDIDValue *did.Doc
 	CreateDIDErr   error
+
+	SendUpdateRequestErr     error
+	SendDeactivateRequestErr error
 }
 
 // CreateDID create did
 func (c *Client) CreateDID(domain string, opts ...didclient.CreateDIDOption) (*did.Doc, error) {
 	return c.CreateDIDValue, c.CreateDIDErr
 }
+
+// SendUpdateRequest send update request
+func (c *Client) SendUpdateRequest(didID string, req []byte) error {
+	return c.SendUpdateRequestErr
+}
+
+// SendDeactivateRequest send deactivate request
+func (c *Client) SendDeactivateRequest(didID string, req []byte) error {
+	return c.SendDeactivateRequestErr
+}
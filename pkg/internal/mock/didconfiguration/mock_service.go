@@ -6,19 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 package didconfiguration
 
 import (
-	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
 )
 
 // MockDIDConfigService implements a mock DID configuration verification service
-type MockDIDConfigService struct {
-	VerifyStakeholderFunc func(domain string, doc *did.Doc) error
-}
-
-// VerifyStakeholder fetch and verify a did configuration for a given stakeholder
-func (m *MockDIDConfigService) VerifyStakeholder(domain string, doc *did.Doc) error {
-	if m.VerifyStakeholderFunc != nil {
-		return m.VerifyStakeholderFunc(domain, doc)
-	}
-
-	return nil
-}
+type MockDIDConfigService = mock.DIDConfigService
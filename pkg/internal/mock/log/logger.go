@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package log
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+// Entry is a log entry recorded by the mock logger
+type Entry struct {
+	Level   string
+	Message string
+	Fields  log.Fields
+}
+
+// Logger is the mock logger, recording its entries
+type Logger struct {
+	mutex   *sync.Mutex
+	entries *[]*Entry
+	fields  log.Fields
+}
+
+// NewLogger creates a mock logger
+func NewLogger() *Logger {
+	return &Logger{mutex: &sync.Mutex{}, entries: &[]*Entry{}}
+}
+
+// Debugf records a debug entry
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.record("debug", format, args)
+}
+
+// Infof records an info entry
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.record("info", format, args)
+}
+
+// Warnf records a warning entry
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.record("warning", format, args)
+}
+
+// Errorf records an error entry
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.record("error", format, args)
+}
+
+// WithFields returns a logger recording to the same entries, with the given fields added
+func (l *Logger) WithFields(fields log.Fields) log.Logger {
+	merged := log.Fields{}
+
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{mutex: l.mutex, entries: l.entries, fields: merged}
+}
+
+// Entries returns the entries recorded at the given level, or all the entries if the level is empty
+func (l *Logger) Entries(level string) []*Entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var entries []*Entry
+
+	for _, entry := range *l.entries {
+		if level == "" || entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func (l *Logger) record(level, format string, args []interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	*l.entries = append(*l.entries, &Entry{Level: level, Message: fmt.Sprintf(format, args...), Fields: l.fields})
+}
@@ -6,9 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 package models
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/cbor"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
 
@@ -18,6 +21,21 @@ func DummyJWSWrap(data string) string {
 	return `{"payload":"` + dataB64 + `","signatures":[{"header":{"kid":""}, "signature":""}]}`
 }
 
+// DummyCOSEWrap converts a config JSON to CBOR and wraps it in a COSE message signed with a new key
+func DummyCOSEWrap(data string) ([]byte, error) {
+	payload, err := cbor.FromJSON([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.SignCOSE(payload, key)
+}
+
 // DummyConsortium creates a default consortium object
 func DummyConsortium(consortiumDomain string, stakeholders []*models.StakeholderListElement) *models.Consortium {
 	cc := &models.Consortium{
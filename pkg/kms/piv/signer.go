@@ -0,0 +1,49 @@
+//go:build !js
+// +build !js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package piv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/kms/pkcs11"
+)
+
+// PIV key slots
+const (
+	SlotAuthentication     = "9a"
+	SlotSignature          = "9c"
+	SlotKeyManagement      = "9d"
+	SlotCardAuthentication = "9e"
+)
+
+// slotObjectIDs are the CKA_IDs of the keys of each PIV slot, as exposed by the PKCS#11 modules of PIV tokens
+// (eg. ykcs11 for YubiKeys, or OpenSC)
+// nolint: gochecknoglobals
+var slotObjectIDs = map[string]byte{
+	SlotAuthentication:     1,
+	SlotSignature:          2,
+	SlotKeyManagement:      3,
+	SlotCardAuthentication: 4,
+}
+
+// NewSigner returns a signer using the key of the given slot of a PIV token (eg. a YubiKey), accessed through the
+// token's PKCS#11 module. The key ID of the signer defaults to the slot, and the token's PIN is set with
+// pkcs11.WithPIN. Close must be called to release the token when the Signer is no longer needed.
+func NewSigner(module, slot string, opts ...pkcs11.Option) (*pkcs11.Signer, error) {
+	slot = strings.ToLower(slot)
+
+	id, ok := slotObjectIDs[slot]
+	if !ok {
+		return nil, fmt.Errorf("unsupported piv slot '%s', expected one of %s, %s, %s or %s", slot,
+			SlotAuthentication, SlotSignature, SlotKeyManagement, SlotCardAuthentication)
+	}
+
+	return pkcs11.NewSigner(module, slot, append([]pkcs11.Option{pkcs11.WithObjectID([]byte{id})}, opts...)...)
+}
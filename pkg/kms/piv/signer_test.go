@@ -0,0 +1,29 @@
+//go:build !js
+// +build !js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package piv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSigner(t *testing.T) {
+	t.Run("failure - unsupported slot", func(t *testing.T) {
+		_, err := NewSigner("module.so", "82")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported piv slot '82'")
+	})
+
+	t.Run("failure - module not found", func(t *testing.T) {
+		_, err := NewSigner("/not/a/module.so", "9C")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load pkcs11 module")
+	})
+}
@@ -0,0 +1,418 @@
+//go:build !js
+// +build !js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	"github.com/square/go-jose/v3"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	// ckkECEdwards and ckmEdDSA are defined by PKCS#11 v3.0, and are not yet available in the pkcs11 package
+	ckkECEdwards = 0x00000040
+	ckmEdDSA     = 0x00001057
+
+	maxObjects = 2
+
+	// p256PointSize is the size of an uncompressed P-256 point
+	p256PointSize = 65
+)
+
+// nolint: gochecknoglobals
+var oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+// context is the subset of the PKCS#11 API used by the Signer
+type context interface {
+	Initialize() error
+	Finalize() error
+	Destroy()
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error)
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	Logout(sh pkcs11.SessionHandle) error
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+	SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error
+	Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error)
+}
+
+// newContext loads the PKCS#11 module. This variable may be overridden by unit tests.
+// nolint: gochecknoglobals
+var newContext = func(module string) (context, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load pkcs11 module '%s'", module)
+	}
+
+	return ctx, nil
+}
+
+// Signer signs data with a key held in an HSM, accessed through a PKCS#11 module.
+// The private key never leaves the HSM.
+//
+// Signer implements the sidetree request Signer interface (Sign, Headers), for signing DID operations,
+// as well as jose.OpaqueSigner, so it can be used as a jose.SigningKey for signing config files and
+// did-configurations.
+type Signer struct {
+	ctx        context
+	session    pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	tokenLabel string
+	pin        string
+	kid        string
+	objectID   []byte
+	alg        jose.SignatureAlgorithm
+	publicKey  *jose.JSONWebKey
+
+	// PKCS#11 sessions can't be used for concurrent operations
+	mutex sync.Mutex
+}
+
+// NewSigner opens a session on the token, logs in and finds the key pair with the given label.
+// Close must be called to release the session when the Signer is no longer needed.
+func NewSigner(module, keyLabel string, opts ...Option) (*Signer, error) {
+	if keyLabel == "" {
+		return nil, errors.New("pkcs11 key label is empty")
+	}
+
+	s := &Signer{kid: keyLabel}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	ctx, err := newContext(module)
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx = ctx
+
+	if err := s.openSession(); err != nil {
+		ctx.Destroy()
+
+		return nil, err
+	}
+
+	if err := s.loadKey(keyLabel); err != nil {
+		s.Close() // nolint: errcheck,gosec
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close logs out, and releases the PKCS#11 session and module
+func (s *Signer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pin != "" {
+		// ignore logout errors, the session is closed regardless
+		s.ctx.Logout(s.session) // nolint: errcheck,gosec
+	}
+
+	err := s.ctx.CloseSession(s.session)
+	if err != nil {
+		return fmt.Errorf("failed to close pkcs11 session: %w", err)
+	}
+
+	err = s.ctx.Finalize()
+	if err != nil {
+		return fmt.Errorf("failed to finalize pkcs11 module: %w", err)
+	}
+
+	s.ctx.Destroy()
+
+	return nil
+}
+
+// Public returns the public key of the HSM signing key
+func (s *Signer) Public() *jose.JSONWebKey {
+	return s.publicKey
+}
+
+// Algs returns the signature algorithms supported by the HSM signing key
+func (s *Signer) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{s.alg}
+}
+
+// SignPayload signs the payload using the HSM key, with the given algorithm
+func (s *Signer) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	if alg != s.alg {
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", alg)
+	}
+
+	return s.Sign(payload)
+}
+
+// Headers provides the JWS protected headers for signing sidetree requests
+func (s *Signer) Headers() jws.Headers {
+	headers := make(jws.Headers)
+	headers[jws.HeaderAlgorithm] = string(s.alg)
+	headers[jws.HeaderKeyID] = s.kid
+
+	return headers
+}
+
+// Sign signs the given data using the HSM key, returning a signature in JWS format
+func (s *Signer) Sign(data []byte) ([]byte, error) {
+	mechanism := pkcs11.CKM_ECDSA
+	msg := data
+
+	if s.alg == jose.EdDSA {
+		mechanism = ckmEdDSA
+	} else {
+		// CKM_ECDSA signs a precomputed digest, and returns the R||S signature format used by JWS
+		digest := sha256.Sum256(data)
+		msg = digest[:]
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(uint(mechanism), nil)}, s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11 sign init failed: %w", err)
+	}
+
+	sig, err := s.ctx.Sign(s.session, msg)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11 sign failed: %w", err)
+	}
+
+	return sig, nil
+}
+
+func (s *Signer) openSession() error {
+	err := s.ctx.Initialize()
+	if err != nil {
+		return fmt.Errorf("failed to initialize pkcs11 module: %w", err)
+	}
+
+	slot, err := s.findSlot()
+	if err != nil {
+		return err
+	}
+
+	s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open pkcs11 session: %w", err)
+	}
+
+	if s.pin != "" {
+		err = s.ctx.Login(s.session, pkcs11.CKU_USER, s.pin)
+		if err != nil {
+			return fmt.Errorf("failed to login to pkcs11 token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findSlot returns the slot holding the token with the configured label,
+// or the first slot with a token present if no label was given
+func (s *Signer) findSlot() (uint, error) {
+	slots, err := s.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pkcs11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		if s.tokenLabel == "" {
+			return slot, nil
+		}
+
+		info, err := s.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pkcs11 token info: %w", err)
+		}
+
+		if info.Label == s.tokenLabel {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("pkcs11 token '%s' not found", s.tokenLabel)
+}
+
+func (s *Signer) loadKey(label string) error {
+	privateKey, err := s.findObject(pkcs11.CKO_PRIVATE_KEY, label)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := s.findObject(pkcs11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := s.ctx.GetAttributeValue(s.session, publicKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read pkcs11 public key '%s': %w", label, err)
+	}
+
+	jwk, alg, err := parsePublicKey(attrs)
+	if err != nil {
+		return fmt.Errorf("pkcs11 public key '%s': %w", label, err)
+	}
+
+	jwk.KeyID = s.kid
+	jwk.Algorithm = string(alg)
+
+	s.privateKey = privateKey
+	s.publicKey = jwk
+	s.alg = alg
+
+	return nil
+}
+
+func (s *Signer) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	key := pkcs11.NewAttribute(pkcs11.CKA_LABEL, label)
+	if s.objectID != nil {
+		key = pkcs11.NewAttribute(pkcs11.CKA_ID, s.objectID)
+	}
+
+	err := s.ctx.FindObjectsInit(s.session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_CLASS, class), key})
+	if err != nil {
+		return 0, fmt.Errorf("failed to search pkcs11 objects: %w", err)
+	}
+
+	objects, _, err := s.ctx.FindObjects(s.session, maxObjects)
+
+	// the search must be finalized even if it failed
+	if e := s.ctx.FindObjectsFinal(s.session); e != nil && err == nil {
+		err = e
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to search pkcs11 objects: %w", err)
+	}
+
+	if len(objects) != 1 {
+		if s.objectID != nil {
+			return 0, fmt.Errorf("expected exactly one pkcs11 key with id %x, found %d", s.objectID, len(objects))
+		}
+
+		return 0, fmt.Errorf("expected exactly one pkcs11 key with label '%s', found %d", label, len(objects))
+	}
+
+	return objects[0], nil
+}
+
+func parsePublicKey(attrs []*pkcs11.Attribute) (*jose.JSONWebKey, jose.SignatureAlgorithm, error) {
+	var keyType, params, point []byte
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case pkcs11.CKA_KEY_TYPE:
+			keyType = attr.Value
+		case pkcs11.CKA_EC_PARAMS:
+			params = attr.Value
+		case pkcs11.CKA_EC_POINT:
+			point = attr.Value
+		}
+	}
+
+	switch {
+	case isKeyType(keyType, ckkECEdwards):
+		rawPoint := ecPoint(point, ed25519.PublicKeySize)
+		if len(rawPoint) != ed25519.PublicKeySize {
+			return nil, "", errors.New("invalid Ed25519 public key")
+		}
+
+		return &jose.JSONWebKey{Key: ed25519.PublicKey(rawPoint)}, jose.EdDSA, nil
+	case isKeyType(keyType, pkcs11.CKK_EC):
+		var curve asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(params, &curve); err != nil || !curve.Equal(oidNamedCurveP256) {
+			return nil, "", errors.New("unsupported EC curve, only P-256 is supported")
+		}
+
+		x, y := elliptic.Unmarshal(elliptic.P256(), ecPoint(point, p256PointSize))
+		if x == nil {
+			return nil, "", errors.New("invalid P-256 public key")
+		}
+
+		return &jose.JSONWebKey{Key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, jose.ES256, nil
+	default:
+		return nil, "", errors.New("unsupported key type, expected EC or EC_EDWARDS")
+	}
+}
+
+// ecPoint returns the raw EC point of a CKA_EC_POINT value, given the size of raw points of the curve.
+// EC points are wrapped in a DER OCTET STRING, though some modules return the raw point
+func ecPoint(point []byte, size int) []byte {
+	if len(point) == size {
+		return point
+	}
+
+	var rawPoint []byte
+	if rest, err := asn1.Unmarshal(point, &rawPoint); err == nil && len(rest) == 0 {
+		return rawPoint
+	}
+
+	return point
+}
+
+// isKeyType compares a CKA_KEY_TYPE value, which is a CK_ULONG in native byte order, with the given key type
+func isKeyType(value []byte, keyType uint) bool {
+	return bytes.Equal(value, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType).Value)
+}
+
+// Option is a PKCS#11 signer option
+type Option func(opts *Signer)
+
+// WithTokenLabel selects the token holding the key by its label
+func WithTokenLabel(label string) Option {
+	return func(opts *Signer) {
+		opts.tokenLabel = label
+	}
+}
+
+// WithPIN sets the user PIN used to login to the token
+func WithPIN(pin string) Option {
+	return func(opts *Signer) {
+		opts.pin = pin
+	}
+}
+
+// WithKeyID sets the key ID reported in the signer's public key and JWS headers (defaults to the key label)
+func WithKeyID(kid string) Option {
+	return func(opts *Signer) {
+		opts.kid = kid
+	}
+}
+
+// WithObjectID finds the key pair by its CKA_ID instead of its label, for tokens labelling the private and public
+// keys of a pair differently, eg. PIV tokens. The key label is then only used as the default key ID
+func WithObjectID(id []byte) Option {
+	return func(opts *Signer) {
+		opts.objectID = id
+	}
+}
@@ -0,0 +1,285 @@
+//go:build !js
+// +build !js
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	privateKeyHandle = pkcs11.ObjectHandle(1)
+	publicKeyHandle  = pkcs11.ObjectHandle(2)
+)
+
+type mockContext struct {
+	tokenLabel   string
+	keyType      uint
+	ecParams     []byte
+	ecPoint      []byte
+	edKey        ed25519.PrivateKey
+	ecKey        *ecdsa.PrivateKey
+	loginErr     error
+	findClass    []byte
+	findKey      *pkcs11.Attribute
+	closed       bool
+	mechanism    uint
+	numPublicKey int
+}
+
+func (m *mockContext) Initialize() error { return nil }
+func (m *mockContext) Finalize() error   { return nil }
+func (m *mockContext) Destroy()          { m.closed = true }
+
+func (m *mockContext) GetSlotList(bool) ([]uint, error) { return []uint{0, 1}, nil }
+
+func (m *mockContext) GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error) {
+	if slotID == 1 {
+		return pkcs11.TokenInfo{Label: m.tokenLabel}, nil
+	}
+
+	return pkcs11.TokenInfo{Label: "other"}, nil
+}
+
+func (m *mockContext) OpenSession(uint, uint) (pkcs11.SessionHandle, error) { return 1, nil }
+func (m *mockContext) CloseSession(pkcs11.SessionHandle) error              { return nil }
+func (m *mockContext) Login(pkcs11.SessionHandle, uint, string) error       { return m.loginErr }
+func (m *mockContext) Logout(pkcs11.SessionHandle) error                    { return nil }
+func (m *mockContext) FindObjectsFinal(pkcs11.SessionHandle) error          { return nil }
+
+func (m *mockContext) FindObjectsInit(_ pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	m.findClass = temp[0].Value
+	m.findKey = temp[1]
+
+	return nil
+}
+
+func (m *mockContext) FindObjects(pkcs11.SessionHandle, int) ([]pkcs11.ObjectHandle, bool, error) {
+	if string(m.findClass) == string(pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY).Value) {
+		return []pkcs11.ObjectHandle{privateKeyHandle}, false, nil
+	}
+
+	objects := make([]pkcs11.ObjectHandle, m.numPublicKey)
+	for i := range objects {
+		objects[i] = publicKeyHandle
+	}
+
+	return objects, false, nil
+}
+
+func (m *mockContext) GetAttributeValue(_ pkcs11.SessionHandle, _ pkcs11.ObjectHandle,
+	_ []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, m.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, m.ecParams),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, m.ecPoint),
+	}, nil
+}
+
+func (m *mockContext) SignInit(_ pkcs11.SessionHandle, mech []*pkcs11.Mechanism, _ pkcs11.ObjectHandle) error {
+	m.mechanism = mech[0].Mechanism
+
+	return nil
+}
+
+func (m *mockContext) Sign(_ pkcs11.SessionHandle, message []byte) ([]byte, error) {
+	if m.mechanism == ckmEdDSA {
+		return ed25519.Sign(m.edKey, message), nil
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, m.ecKey, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(copyPadded(r.Bytes()), copyPadded(s.Bytes())...), nil
+}
+
+func copyPadded(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+
+	return out
+}
+
+func edContext(t *testing.T) *mockContext {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	point, err := asn1.Marshal([]byte(pub))
+	require.NoError(t, err)
+
+	return &mockContext{tokenLabel: "token", keyType: ckkECEdwards, ecPoint: point, edKey: priv, numPublicKey: 1}
+}
+
+func withContext(t *testing.T, ctx *mockContext) {
+	prev := newContext
+	newContext = func(string) (context, error) { return ctx, nil }
+
+	t.Cleanup(func() { newContext = prev })
+}
+
+func TestSigner(t *testing.T) {
+	t.Run("success - Ed25519 key signs jws", func(t *testing.T) {
+		ctx := edContext(t)
+		withContext(t, ctx)
+
+		s, err := NewSigner("module.so", "key1", WithTokenLabel("token"), WithPIN("1234"))
+		require.NoError(t, err)
+		require.Equal(t, "key1", s.Public().KeyID)
+		require.Equal(t, []jose.SignatureAlgorithm{jose.EdDSA}, s.Algs())
+		require.Equal(t, "key1", s.Headers()[jws.HeaderKeyID])
+
+		signer, err := jose.NewSigner(jose.SigningKey{Key: s, Algorithm: jose.EdDSA}, nil)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("payload"))
+		require.NoError(t, err)
+
+		_, err = sig.Verify(ctx.edKey.Public())
+		require.NoError(t, err)
+
+		_, err = s.SignPayload([]byte("payload"), jose.ES256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported signature algorithm")
+
+		require.NoError(t, s.Close())
+		require.True(t, ctx.closed)
+	})
+
+	t.Run("success - P-256 key signs jws", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		params, err := asn1.Marshal(oidNamedCurveP256)
+		require.NoError(t, err)
+
+		withContext(t, &mockContext{keyType: pkcs11.CKK_EC, ecParams: params, numPublicKey: 1,
+			ecPoint: elliptic.Marshal(elliptic.P256(), priv.X, priv.Y), ecKey: priv})
+
+		s, err := NewSigner("module.so", "key1", WithKeyID("kid1"))
+		require.NoError(t, err)
+		require.Equal(t, "kid1", s.Public().KeyID)
+		require.Equal(t, []jose.SignatureAlgorithm{jose.ES256}, s.Algs())
+
+		signer, err := jose.NewSigner(jose.SigningKey{Key: s, Algorithm: jose.ES256}, nil)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("payload"))
+		require.NoError(t, err)
+
+		_, err = sig.Verify(&priv.PublicKey)
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - missing key label", func(t *testing.T) {
+		_, err := NewSigner("module.so", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "pkcs11 key label is empty")
+	})
+
+	t.Run("failure - module not found", func(t *testing.T) {
+		_, err := NewSigner("/not/a/module.so", "key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load pkcs11 module")
+	})
+
+	t.Run("failure - token not found", func(t *testing.T) {
+		withContext(t, edContext(t))
+
+		_, err := NewSigner("module.so", "key1", WithTokenLabel("missing"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "pkcs11 token 'missing' not found")
+	})
+
+	t.Run("failure - login", func(t *testing.T) {
+		ctx := edContext(t)
+		ctx.loginErr = errors.New("bad pin")
+		withContext(t, ctx)
+
+		_, err := NewSigner("module.so", "key1", WithPIN("0000"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad pin")
+	})
+
+	t.Run("failure - key not found", func(t *testing.T) {
+		ctx := edContext(t)
+		ctx.numPublicKey = 0
+		withContext(t, ctx)
+
+		_, err := NewSigner("module.so", "key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected exactly one pkcs11 key with label 'key1', found 0")
+		require.True(t, ctx.closed)
+	})
+
+	t.Run("success - key found by object id", func(t *testing.T) {
+		ctx := edContext(t)
+		withContext(t, ctx)
+
+		s, err := NewSigner("module.so", "9c", WithObjectID([]byte{2}))
+		require.NoError(t, err)
+		require.Equal(t, "9c", s.Public().KeyID)
+		require.Equal(t, uint(pkcs11.CKA_ID), ctx.findKey.Type)
+		require.Equal(t, []byte{2}, ctx.findKey.Value)
+	})
+
+	t.Run("failure - key not found by object id", func(t *testing.T) {
+		ctx := edContext(t)
+		ctx.numPublicKey = 0
+		withContext(t, ctx)
+
+		_, err := NewSigner("module.so", "9c", WithObjectID([]byte{2}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected exactly one pkcs11 key with id 02, found 0")
+	})
+
+	t.Run("failure - unsupported curve", func(t *testing.T) {
+		params, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 34})
+		require.NoError(t, err)
+
+		withContext(t, &mockContext{keyType: pkcs11.CKK_EC, ecParams: params, numPublicKey: 1})
+
+		_, err = NewSigner("module.so", "key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported EC curve")
+	})
+
+	t.Run("failure - unsupported key type", func(t *testing.T) {
+		withContext(t, &mockContext{keyType: pkcs11.CKK_RSA, numPublicKey: 1})
+
+		_, err := NewSigner("module.so", "key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported key type")
+	})
+}
+
+func Test_ecPoint(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	raw := elliptic.Marshal(elliptic.P256(), priv.X, priv.Y)
+
+	wrapped, err := asn1.Marshal(raw)
+	require.NoError(t, err)
+
+	require.Equal(t, raw, ecPoint(raw, p256PointSize))
+	require.Equal(t, raw, ecPoint(wrapped, p256PointSize))
+	require.Equal(t, []byte("bad"), ecPoint([]byte("bad"), p256PointSize))
+}
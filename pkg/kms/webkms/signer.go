@@ -0,0 +1,299 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/tls"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/square/go-jose/v3"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+const (
+	signPath   = "/sign"
+	exportPath = "/export"
+
+	p256KeySize = 32
+
+	// defaultTimeout is the default time limit of a request to the KMS, including reading its response
+	defaultTimeout = 10 * time.Second
+)
+
+// Signer signs data with a key held by a remote WebKMS keystore. The private key never leaves the KMS service.
+//
+// Signer implements the sidetree request Signer interface (Sign, Headers), for signing DID operations (see
+// did.WithWebKMSSigningKey), as well as jose.OpaqueSigner, so it can be used as a jose.SigningKey for signing
+// config files and did-configurations.
+type Signer struct {
+	keyURL     string
+	kid        string
+	alg        jose.SignatureAlgorithm
+	authToken  string
+	tlsConfig  *tls.Config
+	timeout    time.Duration
+	httpClient *http.Client
+	publicKey  *jose.JSONWebKey
+	logger     log.Logger
+}
+
+type signReq struct {
+	Message string `json:"message"`
+}
+
+type signResp struct {
+	Signature string `json:"signature"`
+}
+
+type exportResp struct {
+	PublicKey string `json:"publicKey"`
+}
+
+// NewSigner creates a Signer for the key at the given WebKMS key URL
+// (eg, https://kms.example.com/kms/keystores/{keystoreID}/keys/{keyID}).
+// The public key is exported from the KMS on creation, and used to determine the signature algorithm.
+func NewSigner(keyURL string, opts ...Option) (*Signer, error) {
+	if keyURL == "" {
+		return nil, errors.New("webkms key url is empty")
+	}
+
+	s := &Signer{keyURL: strings.TrimSuffix(keyURL, "/"), timeout: defaultTimeout}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.logger = log.OrDefault(s.logger)
+	s.httpClient = &http.Client{Timeout: s.timeout, Transport: transport.New(s.tlsConfig, nil)}
+
+	pubKey, err := s.exportPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export public key from webkms: %w", err)
+	}
+
+	s.publicKey = pubKey
+
+	return s, nil
+}
+
+// Public returns the public key of the WebKMS signing key
+func (s *Signer) Public() *jose.JSONWebKey {
+	return s.publicKey
+}
+
+// Algs returns the signature algorithms supported by the WebKMS signing key
+func (s *Signer) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{s.alg}
+}
+
+// SignPayload signs the payload using the WebKMS key, with the given algorithm
+func (s *Signer) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	if alg != s.alg {
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", alg)
+	}
+
+	return s.Sign(payload)
+}
+
+// Headers provides the JWS protected headers for signing sidetree requests
+func (s *Signer) Headers() jws.Headers {
+	headers := make(jws.Headers)
+	headers[jws.HeaderAlgorithm] = string(s.alg)
+
+	if s.kid != "" {
+		headers[jws.HeaderKeyID] = s.kid
+	}
+
+	return headers
+}
+
+// Sign signs the given data using the WebKMS key, returning a signature in JWS format
+func (s *Signer) Sign(data []byte) ([]byte, error) {
+	reqBytes, err := json.Marshal(signReq{Message: base64.RawURLEncoding.EncodeToString(data)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sign request: %w", err)
+	}
+
+	respBytes, err := s.send(http.MethodPost, s.keyURL+signPath, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp signResp
+
+	if err = json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse webkms sign response: %w", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webkms signature: %w", err)
+	}
+
+	if s.alg == jose.ES256 {
+		return toJWSSignature(sig)
+	}
+
+	return sig, nil
+}
+
+func (s *Signer) exportPublicKey() (*jose.JSONWebKey, error) {
+	respBytes, err := s.send(http.MethodGet, s.keyURL+exportPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp exportResp
+
+	if err = json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse webkms export response: %w", err)
+	}
+
+	keyBytes, err := base64.RawURLEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webkms public key: %w", err)
+	}
+
+	jwk := &jose.JSONWebKey{KeyID: s.kid}
+
+	switch {
+	case len(keyBytes) == ed25519.PublicKeySize:
+		jwk.Key = ed25519.PublicKey(keyBytes)
+		s.alg = jose.EdDSA
+	default:
+		x, y := elliptic.Unmarshal(elliptic.P256(), keyBytes)
+		if x == nil {
+			return nil, errors.New("unsupported webkms public key type")
+		}
+
+		jwk.Key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		s.alg = jose.ES256
+	}
+
+	jwk.Algorithm = string(s.alg)
+
+	return jwk, nil
+}
+
+func (s *Signer) send(method, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to webkms: %w", err)
+	}
+
+	defer s.closeResponseBody(resp.Body)
+
+	// a KMS response is a small JSON object: its size is limited so a huge response can't spike memory
+	respBytes, err := transport.ReadLimited(resp.Body, transport.DefaultMaxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webkms response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webkms request to %s failed: status '%d' body %s", url, resp.StatusCode, respBytes)
+	}
+
+	return respBytes, nil
+}
+
+// toJWSSignature converts an ECDSA signature to the fixed-size R||S form required by JWS,
+// since KMS services commonly return ASN.1 DER-encoded signatures
+func toJWSSignature(sig []byte) ([]byte, error) {
+	if len(sig) == 2*p256KeySize {
+		return sig, nil
+	}
+
+	var derSig struct {
+		R, S *big.Int
+	}
+
+	if _, err := asn1.Unmarshal(sig, &derSig); err != nil {
+		return nil, fmt.Errorf("failed to parse ECDSA signature: %w", err)
+	}
+
+	return append(copyPadded(derSig.R.Bytes(), p256KeySize), copyPadded(derSig.S.Bytes(), p256KeySize)...), nil
+}
+
+func copyPadded(source []byte, size int) []byte {
+	dest := make([]byte, size)
+	copy(dest[size-len(source):], source)
+
+	return dest
+}
+
+func (s *Signer) closeResponseBody(respBody io.Closer) {
+	e := respBody.Close()
+	if e != nil {
+		s.logger.Errorf("Failed to close response body: %v", e)
+	}
+}
+
+// Option is a WebKMS signer option
+type Option func(opts *Signer)
+
+// WithAuthToken sets the bearer token used to authorize requests to the WebKMS service
+func WithAuthToken(authToken string) Option {
+	return func(opts *Signer) {
+		opts.authToken = authToken
+	}
+}
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *Signer) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithTimeout sets the time limit of a request to the KMS, including reading its response. Defaults to 10 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *Signer) {
+		opts.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger of the signer. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *Signer) {
+		opts.logger = logger
+	}
+}
+
+// WithKeyID sets the key ID reported in the signer's public key and JWS headers
+func WithKeyID(kid string) Option {
+	return func(opts *Signer) {
+		opts.kid = kid
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/didconfiguration"
+)
+
+const sha2_256 = 18
+
+func kmsServer(t *testing.T, pubKey []byte, sign func([]byte) []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/keys/key1/export":
+			fmt.Fprintf(w, `{"publicKey":"%s"}`, base64.RawURLEncoding.EncodeToString(pubKey))
+		case "/keys/key1/sign":
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var req signReq
+			require.NoError(t, json.Unmarshal(body, &req))
+
+			msg, err := base64.RawURLEncoding.DecodeString(req.Message)
+			require.NoError(t, err)
+
+			fmt.Fprintf(w, `{"signature":"%s"}`, base64.RawURLEncoding.EncodeToString(sign(msg)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSigner(t *testing.T) {
+	t.Run("success - ed25519 key signs jws", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		srv := kmsServer(t, pub, func(msg []byte) []byte {
+			return ed25519.Sign(priv, msg)
+		})
+		defer srv.Close()
+
+		s, err := NewSigner(srv.URL+"/keys/key1/", WithAuthToken("token"), WithKeyID("key1"))
+		require.NoError(t, err)
+		require.Equal(t, "key1", s.Public().KeyID)
+		require.Equal(t, []jose.SignatureAlgorithm{jose.EdDSA}, s.Algs())
+		require.Equal(t, "EdDSA", s.Headers()[jws.HeaderAlgorithm])
+		require.Equal(t, "key1", s.Headers()[jws.HeaderKeyID])
+
+		signer, err := jose.NewSigner(jose.SigningKey{Key: s, Algorithm: jose.EdDSA}, nil)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("payload"))
+		require.NoError(t, err)
+
+		payload, err := sig.Verify(pub)
+		require.NoError(t, err)
+		require.Equal(t, "payload", string(payload))
+	})
+
+	t.Run("success - ed25519 key signs sidetree update request", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		srv := kmsServer(t, pub, func(msg []byte) []byte {
+			return ed25519.Sign(priv, msg)
+		})
+		defer srv.Close()
+
+		s, err := NewSigner(srv.URL+"/keys/key1", WithAuthToken("token"), WithKeyID("update"))
+		require.NoError(t, err)
+
+		updateKey, err := pubkey.GetPublicKeyJWK(s.Public().Key)
+		require.NoError(t, err)
+
+		removePatch, err := patch.NewRemovePublicKeysPatch(`["key1"]`)
+		require.NoError(t, err)
+
+		reqBytes, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{
+			DidSuffix:        "EiAtestsuffix",
+			Patch:            removePatch,
+			UpdateCommitment: "commitment",
+			UpdateKey:        updateKey,
+			MultihashCode:    sha2_256,
+			Signer:           s,
+		})
+		require.NoError(t, err)
+
+		req := &model.UpdateRequest{}
+		require.NoError(t, json.Unmarshal(reqBytes, req))
+
+		signed, err := jose.ParseSigned(req.SignedData)
+		require.NoError(t, err)
+
+		_, err = signed.Verify(pub)
+		require.NoError(t, err)
+	})
+
+	t.Run("success - P-256 key with DER signature creates did configuration", func(t *testing.T) {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		srv := kmsServer(t, elliptic.Marshal(elliptic.P256(), priv.X, priv.Y), func(msg []byte) []byte {
+			digest := sha256.Sum256(msg)
+
+			r, s, e := ecdsa.Sign(rand.Reader, priv, digest[:])
+			require.NoError(t, e)
+
+			sig, e := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+			require.NoError(t, e)
+
+			return sig
+		})
+		defer srv.Close()
+
+		s, err := NewSigner(srv.URL+"/keys/key1", WithAuthToken("token"), WithKeyID("update"))
+		require.NoError(t, err)
+		require.Equal(t, []jose.SignatureAlgorithm{jose.ES256}, s.Algs())
+
+		conf, err := didconfiguration.CreateDIDConfiguration("foo.bar", "did:example:foo", 0,
+			&jose.SigningKey{Key: s, Algorithm: jose.ES256})
+		require.NoError(t, err)
+		require.Len(t, conf.Entries, 1)
+
+		parsed, err := jose.ParseSigned(conf.Entries[0].JWT)
+		require.NoError(t, err)
+
+		_, err = parsed.Verify(&priv.PublicKey)
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - empty key url", func(t *testing.T) {
+		_, err := NewSigner("")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "webkms key url is empty")
+	})
+
+	t.Run("failure - unauthorized", func(t *testing.T) {
+		srv := kmsServer(t, nil, nil)
+		defer srv.Close()
+
+		_, err := NewSigner(srv.URL + "/keys/key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status '401'")
+	})
+
+	t.Run("failure - response too large", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"publicKey":"%s"}`, strings.Repeat("a", transport.DefaultMaxResponseSize))
+		}))
+		defer srv.Close()
+
+		_, err := NewSigner(srv.URL + "/keys/key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "response body too large")
+	})
+
+	t.Run("failure - timeout", func(t *testing.T) {
+		release := make(chan struct{})
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		_, err := NewSigner(srv.URL+"/keys/key1", WithTimeout(50*time.Millisecond))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Client.Timeout exceeded")
+	})
+
+	t.Run("failure - unsupported public key", func(t *testing.T) {
+		srv := kmsServer(t, []byte("bad key"), nil)
+		defer srv.Close()
+
+		_, err := NewSigner(srv.URL+"/keys/key1", WithAuthToken("token"), WithKeyID("update"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported webkms public key type")
+	})
+
+	t.Run("failure - sign with unsupported algorithm", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		srv := kmsServer(t, pub, nil)
+		defer srv.Close()
+
+		s, err := NewSigner(srv.URL+"/keys/key1", WithAuthToken("token"), WithKeyID("update"))
+		require.NoError(t, err)
+
+		_, err = s.SignPayload([]byte("payload"), jose.ES256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported signature algorithm")
+	})
+
+	t.Run("failure - bad signature response", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		srv := kmsServer(t, pub, func(msg []byte) []byte {
+			return []byte("sig")
+		})
+		defer srv.Close()
+
+		s, err := NewSigner(srv.URL+"/keys/key1", WithAuthToken("token"), WithKeyID("update"))
+		require.NoError(t, err)
+
+		s.alg = jose.ES256
+
+		_, err = s.Sign([]byte("payload"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse ECDSA signature")
+	})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package log defines the leveled, structured logger the packages of the method log to. A logger is injected once
+// when a VDRI or the REST API is constructed, and passed down to the services they create, so host applications
+// control all log output by implementing Logger or configuring the logrus logger given to New.
+package log
+
+import (
+	"io/ioutil"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Fields are the structured fields of a log entry
+type Fields map[string]interface{}
+
+// Logger is a leveled logger supporting structured fields
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	// WithFields returns a logger adding the given fields to all its entries
+	WithFields(fields Fields) Logger
+}
+
+// New returns a logger writing to the given logrus logger
+func New(logger *logrus.Logger) Logger {
+	return &logrusLogger{entry: logrus.NewEntry(logger)}
+}
+
+// Default returns the default logger, writing to the standard logrus logger
+func Default() Logger {
+	return New(logrus.StandardLogger())
+}
+
+// Nop returns a logger discarding all entries
+func Nop() Logger {
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+
+	return New(l)
+}
+
+// OrDefault returns the given logger, or the default logger if it's nil
+func OrDefault(logger Logger) Logger {
+	if logger == nil {
+		return Default()
+	}
+
+	return logger
+}
+
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+func (l *logrusLogger) Debugf(format string, args ...interface{}) {
+	l.entry.Debugf(format, args...)
+}
+
+func (l *logrusLogger) Infof(format string, args ...interface{}) {
+	l.entry.Infof(format, args...)
+}
+
+func (l *logrusLogger) Warnf(format string, args ...interface{}) {
+	l.entry.Warnf(format, args...)
+}
+
+func (l *logrusLogger) Errorf(format string, args ...interface{}) {
+	l.entry.Errorf(format, args...)
+}
+
+func (l *logrusLogger) WithFields(fields Fields) Logger {
+	return &logrusLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	l := logrus.New()
+	l.SetOutput(out)
+	l.SetLevel(logrus.InfoLevel)
+	l.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	logger := New(l)
+
+	logger.Debugf("debug %d", 1)
+	require.Empty(t, out.String())
+
+	logger.Infof("info %d", 2)
+	require.Contains(t, out.String(), `level=info msg="info 2"`)
+
+	logger.WithFields(Fields{"domain": "testnet"}).Warnf("warn %d", 3)
+	require.Contains(t, out.String(), `level=warning msg="warn 3" domain=testnet`)
+
+	logger.Errorf("error %d", 4)
+	require.Contains(t, out.String(), `level=error msg="error 4"`)
+}
+
+func TestOrDefault(t *testing.T) {
+	require.Equal(t, Default(), OrDefault(nil))
+
+	logger := Nop()
+	require.Equal(t, logger, OrDefault(logger))
+
+	logger.WithFields(Fields{"domain": "testnet"}).Errorf("discarded")
+}
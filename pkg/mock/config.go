@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mock
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// ConfigService is a mock config service, serving the consortium and stakeholder configs it's given
+type ConfigService struct {
+	GetConsortiumFunc  func(string, string) (*models.ConsortiumFileData, error)
+	GetStakeholderFunc func(string, string) (*models.StakeholderFileData, error)
+
+	consortiums  map[string]*models.ConsortiumFileData
+	stakeholders map[string]*models.StakeholderFileData
+	errs         map[string]error
+}
+
+// NewConfigService returns a config service without configs
+func NewConfigService() *ConfigService {
+	return &ConfigService{}
+}
+
+// WithConsortium serves the given consortium config for a domain
+func (m *ConfigService) WithConsortium(domain string, consortium *models.ConsortiumFileData) *ConfigService {
+	if m.consortiums == nil {
+		m.consortiums = map[string]*models.ConsortiumFileData{}
+	}
+
+	m.consortiums[domain] = consortium
+
+	return m
+}
+
+// WithStakeholder serves the given stakeholder config for a domain
+func (m *ConfigService) WithStakeholder(domain string, stakeholder *models.StakeholderFileData) *ConfigService {
+	if m.stakeholders == nil {
+		m.stakeholders = map[string]*models.StakeholderFileData{}
+	}
+
+	m.stakeholders[domain] = stakeholder
+
+	return m
+}
+
+// WithError fails fetching the configs of a domain with the given error
+func (m *ConfigService) WithError(domain string, err error) *ConfigService {
+	if m.errs == nil {
+		m.errs = map[string]error{}
+	}
+
+	m.errs[domain] = err
+
+	return m
+}
+
+// GetConsortium get the consortium config file for a given domain from the given url
+func (m *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
+	if m.GetConsortiumFunc != nil {
+		return m.GetConsortiumFunc(url, domain)
+	}
+
+	if err, ok := m.errs[domain]; ok {
+		return nil, err
+	}
+
+	if consortium, ok := m.consortiums[domain]; ok {
+		return consortium, nil
+	}
+
+	return nil, fmt.Errorf("consortium config of %s not found", domain)
+}
+
+// GetStakeholder get the stakeholder config file for a given domain from the given url
+func (m *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
+	if m.GetStakeholderFunc != nil {
+		return m.GetStakeholderFunc(url, domain)
+	}
+
+	if err, ok := m.errs[domain]; ok {
+		return nil, err
+	}
+
+	if stakeholder, ok := m.stakeholders[domain]; ok {
+		return stakeholder, nil
+	}
+
+	return nil, fmt.Errorf("stakeholder config of %s not found", domain)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mock
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+// DIDConfigService is a mock DID configuration verification service, verifying all stakeholders but the ones
+// given an error
+type DIDConfigService struct {
+	VerifyStakeholderFunc func(domain string, doc *did.Doc) error
+
+	errs map[string]error
+}
+
+// NewDIDConfigService returns a DID configuration service verifying all stakeholders
+func NewDIDConfigService() *DIDConfigService {
+	return &DIDConfigService{}
+}
+
+// WithError fails verifying the stakeholder of a domain with the given error
+func (m *DIDConfigService) WithError(domain string, err error) *DIDConfigService {
+	if m.errs == nil {
+		m.errs = map[string]error{}
+	}
+
+	m.errs[domain] = err
+
+	return m
+}
+
+// VerifyStakeholder fetch and verify a did configuration for a given stakeholder
+func (m *DIDConfigService) VerifyStakeholder(domain string, doc *did.Doc) error {
+	if m.VerifyStakeholderFunc != nil {
+		return m.VerifyStakeholderFunc(domain, doc)
+	}
+
+	return m.errs[domain]
+}
@@ -0,0 +1,15 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package mock provides mocks of the did:trustbloc VDRI and of the services it depends on, so applications
+// embedding the VDRI can unit-test their error paths. Mocks are configured with builder methods, eg.
+//
+//	configService := mock.NewConfigService().
+//		WithConsortium("consortium.example.com", consortiumData).
+//		WithError("stakeholder.example.com", errors.New("unavailable"))
+//
+// and injected with the trustbloc.WithConfigService, trustbloc.WithEndpointService and
+// trustbloc.WithDIDConfigService options. Setting the func fields of a mock overrides its builder configuration.
+package mock
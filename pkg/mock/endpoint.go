@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mock
+
+import (
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// EndpointService is a mock endpoint service, returning the endpoints it's given for each consortium domain
+type EndpointService struct {
+	GetEndpointsFunc func(domain string) ([]*models.Endpoint, error)
+
+	endpoints map[string][]*models.Endpoint
+	errs      map[string]error
+}
+
+// NewEndpointService returns an endpoint service without endpoints
+func NewEndpointService() *EndpointService {
+	return &EndpointService{}
+}
+
+// WithEndpoints returns the given endpoints for a consortium domain
+func (m *EndpointService) WithEndpoints(domain string, endpoints ...*models.Endpoint) *EndpointService {
+	if m.endpoints == nil {
+		m.endpoints = map[string][]*models.Endpoint{}
+	}
+
+	m.endpoints[domain] = endpoints
+
+	return m
+}
+
+// WithError fails getting the endpoints of a consortium domain with the given error
+func (m *EndpointService) WithError(domain string, err error) *EndpointService {
+	if m.errs == nil {
+		m.errs = map[string]error{}
+	}
+
+	m.errs[domain] = err
+
+	return m
+}
+
+// GetEndpoints discover endpoints for a consortium domain
+func (m *EndpointService) GetEndpoints(domain string) ([]*models.Endpoint, error) {
+	if m.GetEndpointsFunc != nil {
+		return m.GetEndpointsFunc(domain)
+	}
+
+	if err, ok := m.errs[domain]; ok {
+		return nil, err
+	}
+
+	return m.endpoints[domain], nil
+}
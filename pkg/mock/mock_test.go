@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestConfigService(t *testing.T) {
+	consortium := &models.ConsortiumFileData{Config: &models.Consortium{Domain: "consortium.example.com"}}
+	stakeholder := &models.StakeholderFileData{Config: &models.Stakeholder{Domain: "stakeholder.example.com"}}
+
+	m := NewConfigService().
+		WithConsortium("consortium.example.com", consortium).
+		WithStakeholder("stakeholder.example.com", stakeholder).
+		WithError("down.example.com", errors.New("unavailable"))
+
+	c, err := m.GetConsortium("consortium.example.com", "consortium.example.com")
+	require.NoError(t, err)
+	require.Equal(t, consortium, c)
+
+	s, err := m.GetStakeholder("stakeholder.example.com", "stakeholder.example.com")
+	require.NoError(t, err)
+	require.Equal(t, stakeholder, s)
+
+	_, err = m.GetConsortium("down.example.com", "down.example.com")
+	require.EqualError(t, err, "unavailable")
+
+	_, err = m.GetStakeholder("down.example.com", "down.example.com")
+	require.EqualError(t, err, "unavailable")
+
+	_, err = m.GetConsortium("other.example.com", "other.example.com")
+	require.EqualError(t, err, "consortium config of other.example.com not found")
+
+	_, err = m.GetStakeholder("other.example.com", "other.example.com")
+	require.EqualError(t, err, "stakeholder config of other.example.com not found")
+
+	m.GetConsortiumFunc = func(string, string) (*models.ConsortiumFileData, error) {
+		return nil, errors.New("func error")
+	}
+
+	_, err = m.GetConsortium("consortium.example.com", "consortium.example.com")
+	require.EqualError(t, err, "func error")
+}
+
+func TestEndpointService(t *testing.T) {
+	endpoint := &models.Endpoint{URL: "https://sidetree.example.com"}
+
+	m := NewEndpointService().
+		WithEndpoints("consortium.example.com", endpoint).
+		WithError("down.example.com", errors.New("unavailable"))
+
+	endpoints, err := m.GetEndpoints("consortium.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []*models.Endpoint{endpoint}, endpoints)
+
+	_, err = m.GetEndpoints("down.example.com")
+	require.EqualError(t, err, "unavailable")
+
+	endpoints, err = m.GetEndpoints("other.example.com")
+	require.NoError(t, err)
+	require.Empty(t, endpoints)
+}
+
+func TestDIDConfigService(t *testing.T) {
+	m := NewDIDConfigService().WithError("down.example.com", errors.New("unavailable"))
+
+	require.NoError(t, m.VerifyStakeholder("stakeholder.example.com", &did.Doc{}))
+	require.EqualError(t, m.VerifyStakeholder("down.example.com", &did.Doc{}), "unavailable")
+}
+
+func TestVDRI(t *testing.T) {
+	doc := &did.Doc{ID: "did:trustbloc:consortium.example.com:EiAtest"}
+	endpoint := &models.Endpoint{URL: "https://sidetree.example.com"}
+
+	m := NewVDRI().
+		WithDoc(doc).
+		WithReadError("did:trustbloc:consortium.example.com:EiAfail", errors.New("read error")).
+		WithEndpoints("consortium.example.com", endpoint).
+		WithConsortiumError("down.example.com", errors.New("invalid consortium"))
+
+	require.True(t, m.Accept("trustbloc"))
+	require.False(t, m.Accept("web"))
+	require.NoError(t, m.Store(nil, nil))
+	require.NoError(t, m.Close())
+
+	_, err := m.Build(nil)
+	require.Error(t, err)
+
+	resolved, err := m.Read(doc.ID)
+	require.NoError(t, err)
+	require.Equal(t, doc, resolved)
+
+	_, err = m.Read("did:trustbloc:consortium.example.com:EiAfail")
+	require.EqualError(t, err, "read error")
+
+	_, err = m.Read("did:trustbloc:consortium.example.com:EiAunknown")
+	require.True(t, errors.Is(err, vdriapi.ErrNotFound))
+
+	_, err = m.Read("did:trustbloc:down.example.com:EiAtest")
+	require.EqualError(t, err, "invalid consortium")
+
+	endpoints, err := m.Endpoints(doc.ID)
+	require.NoError(t, err)
+	require.Equal(t, []*models.Endpoint{endpoint}, endpoints)
+
+	_, err = m.Endpoints("did:trustbloc:down.example.com:EiAtest")
+	require.EqualError(t, err, "invalid consortium")
+
+	_, err = m.ValidateConsortium("consortium.example.com")
+	require.NoError(t, err)
+
+	_, err = m.ValidateConsortium("down.example.com")
+	require.EqualError(t, err, "invalid consortium")
+
+	endpoints, err = (&VDRI{}).Endpoints(doc.ID)
+	require.NoError(t, err)
+	require.Empty(t, endpoints)
+}
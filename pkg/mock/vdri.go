@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mock
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const trustblocDIDMethod = "trustbloc"
+
+// VDRI is a mock of the did:trustbloc VDRI, resolving the docs it's given
+type VDRI struct {
+	ReadFunc               func(did string, opts ...vdriapi.ResolveOpts) (*did.Doc, error)
+	EndpointsFunc          func(did string) ([]*models.Endpoint, error)
+	ValidateConsortiumFunc func(consortiumDomain string) (*time.Duration, error)
+
+	docs          map[string]*did.Doc
+	readErrs      map[string]error
+	endpoints     *EndpointService
+	consortiumErr map[string]error
+}
+
+// NewVDRI returns a VDRI without docs
+func NewVDRI() *VDRI {
+	return &VDRI{endpoints: NewEndpointService()}
+}
+
+// WithDoc resolves the given doc for its DID
+func (m *VDRI) WithDoc(doc *did.Doc) *VDRI {
+	if m.docs == nil {
+		m.docs = map[string]*did.Doc{}
+	}
+
+	m.docs[doc.ID] = doc
+
+	return m
+}
+
+// WithReadError fails resolving a DID with the given error
+func (m *VDRI) WithReadError(didID string, err error) *VDRI {
+	if m.readErrs == nil {
+		m.readErrs = map[string]error{}
+	}
+
+	m.readErrs[didID] = err
+
+	return m
+}
+
+// WithEndpoints returns the given endpoints for the DIDs of a consortium domain
+func (m *VDRI) WithEndpoints(domain string, endpoints ...*models.Endpoint) *VDRI {
+	if m.endpoints == nil {
+		m.endpoints = NewEndpointService()
+	}
+
+	m.endpoints.WithEndpoints(domain, endpoints...)
+
+	return m
+}
+
+// WithConsortiumError fails validating a consortium, and resolving its DIDs, with the given error
+func (m *VDRI) WithConsortiumError(domain string, err error) *VDRI {
+	if m.consortiumErr == nil {
+		m.consortiumErr = map[string]error{}
+	}
+
+	m.consortiumErr[domain] = err
+
+	return m
+}
+
+// Read resolves a DID
+func (m *VDRI) Read(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+	if m.ReadFunc != nil {
+		return m.ReadFunc(didID, opts...)
+	}
+
+	if err, ok := m.consortiumErr[domain(didID)]; ok {
+		return nil, err
+	}
+
+	if err, ok := m.readErrs[didID]; ok {
+		return nil, err
+	}
+
+	if doc, ok := m.docs[didID]; ok {
+		return doc, nil
+	}
+
+	return nil, vdriapi.ErrNotFound
+}
+
+// Endpoints returns the endpoints used to resolve a DID
+func (m *VDRI) Endpoints(didID string) ([]*models.Endpoint, error) {
+	if m.EndpointsFunc != nil {
+		return m.EndpointsFunc(didID)
+	}
+
+	if err, ok := m.consortiumErr[domain(didID)]; ok {
+		return nil, err
+	}
+
+	if m.endpoints == nil {
+		return nil, nil
+	}
+
+	return m.endpoints.GetEndpoints(domain(didID))
+}
+
+// ValidateConsortium validates a consortium, returning the lifetime of its config
+func (m *VDRI) ValidateConsortium(consortiumDomain string) (*time.Duration, error) {
+	if m.ValidateConsortiumFunc != nil {
+		return m.ValidateConsortiumFunc(consortiumDomain)
+	}
+
+	if err, ok := m.consortiumErr[consortiumDomain]; ok {
+		return nil, err
+	}
+
+	var lifetime time.Duration
+
+	return &lifetime, nil
+}
+
+// Accept accepts the did:trustbloc method
+func (m *VDRI) Accept(method string) bool {
+	return method == trustblocDIDMethod
+}
+
+// Store does nothing, as for the VDRI
+func (m *VDRI) Store(*did.Doc, *[]vdriapi.ModifiedBy) error {
+	return nil
+}
+
+// Build fails, as for the VDRI
+func (m *VDRI) Build(*vdriapi.PubKey, ...vdriapi.DocOpts) (*did.Doc, error) {
+	return nil, errors.New("build method not supported for did bloc")
+}
+
+// Close closes the VDRI
+func (m *VDRI) Close() error {
+	return nil
+}
+
+// domain returns the consortium domain of a did:trustbloc DID
+func domain(didID string) string {
+	parts := strings.Split(didID, ":")
+	if len(parts) != 4 { // nolint: gomnd
+		return ""
+	}
+
+	return parts[2]
+}
@@ -22,12 +22,18 @@ func New(config *operation.Config) (*Controller, error) {
 
 	allHandlers = append(allHandlers, handlers...)
 
-	return &Controller{handlers: allHandlers}, nil
+	return &Controller{handlers: allHandlers, service: didMethodService}, nil
 }
 
 // Controller contains handlers for controller
 type Controller struct {
 	handlers []operation.Handler
+	service  *operation.Operation
+}
+
+// Service returns the did method operations served by the controller, so they can be exposed by other APIs
+func (c *Controller) Service() *operation.Operation {
+	return c.service
 }
 
 // GetOperations returns all controller endpoints
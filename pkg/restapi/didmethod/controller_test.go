@@ -30,5 +30,11 @@ func TestController_GetOperations(t *testing.T) {
 	require.NotNil(t, controller)
 
 	ops := controller.GetOperations()
-	require.Equal(t, 2, len(ops))
+	require.Equal(t, 4, len(ops))
+}
+
+func TestController_Service(t *testing.T) {
+	controller, err := New(&operation.Config{Mode: "combined"})
+	require.NoError(t, err)
+	require.NotNil(t, controller.Service())
 }
@@ -0,0 +1,238 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/support"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	trustAnchorEndpoint       = "/admin/trust-anchor/{domain}"
+	rotateTrustAnchorEndpoint = trustAnchorEndpoint + "/rotate"
+	trustLogEndpoint          = "/admin/trust-log"
+	coseContentType           = "application/cose"
+
+	// trust anchor actions
+	pinAction    = "pinned"
+	rotateAction = "rotated"
+)
+
+type trustAnchorStore interface {
+	TrustAnchor(domain string) *models.ConsortiumFileData
+	PinTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error
+	RotateTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error
+}
+
+// trustAnchorAudit records who changed the trust anchors of the service, and when
+type trustAnchorAudit struct {
+	changes map[string]*TrustAnchorChange
+	mutex   sync.RWMutex
+}
+
+func (a *trustAnchorAudit) get(domain string) *TrustAnchorChange {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.changes[domain]
+}
+
+func (a *trustAnchorAudit) set(domain string, change *TrustAnchorChange) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.changes[domain] = change
+}
+
+// admin returns the name of the admin whose token authorizes the request, or an empty string
+func (o *Operation) admin(req *http.Request) string {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return ""
+	}
+
+	for name, adminToken := range o.adminTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// adminOnly wraps an admin API handler, refusing requests without a valid admin token
+func (o *Operation) adminOnly(handle func(rw http.ResponseWriter, req *http.Request, admin string)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		admin := o.admin(req)
+		if admin == "" {
+			o.logger.Warnf("audit: unauthorized %s %s from %s", req.Method, req.URL.Path, req.RemoteAddr)
+
+			o.writeErrorResponse(rw, http.StatusUnauthorized, "unauthorized")
+
+			return
+		}
+
+		handle(rw, req, admin)
+	}
+}
+
+// adminHandler wraps an admin API handler, refusing requests without a valid admin token or if the vdri doesn't
+// support trust anchors
+func (o *Operation) adminHandler(handle func(rw http.ResponseWriter, req *http.Request, admin string,
+	store trustAnchorStore)) http.HandlerFunc {
+	return o.adminOnly(func(rw http.ResponseWriter, req *http.Request, admin string) {
+		store, ok := o.blocVDRI.(trustAnchorStore)
+		if !ok {
+			o.writeErrorResponse(rw, http.StatusNotImplemented, "trust anchors are not supported by the vdri")
+
+			return
+		}
+
+		handle(rw, req, admin, store)
+	})
+}
+
+func (o *Operation) getTrustAnchorHandler(rw http.ResponseWriter, req *http.Request, _ string,
+	store trustAnchorStore) {
+	domain := mux.Vars(req)["domain"]
+
+	anchor := store.TrustAnchor(domain)
+	if anchor == nil {
+		o.writeErrorResponse(rw, http.StatusNotFound, fmt.Sprintf("no trust anchor pinned for %s", domain))
+
+		return
+	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	o.writeResponse(rw, &TrustAnchorResponse{Domain: domain, Config: anchor.Config,
+		LastChange: o.trustAnchorAudit.get(domain)})
+}
+
+func (o *Operation) pinTrustAnchorHandler(rw http.ResponseWriter, req *http.Request, admin string,
+	store trustAnchorStore) {
+	o.changeTrustAnchor(rw, req, admin, pinAction, store, store.PinTrustAnchor)
+}
+
+func (o *Operation) rotateTrustAnchorHandler(rw http.ResponseWriter, req *http.Request, admin string,
+	store trustAnchorStore) {
+	o.changeTrustAnchor(rw, req, admin, rotateAction, store, store.RotateTrustAnchor)
+}
+
+// changeTrustAnchor pins the consortium config file in the request body as the trust anchor of a domain, logging
+// the change to the audit log
+func (o *Operation) changeTrustAnchor(rw http.ResponseWriter, req *http.Request, admin, action string,
+	store trustAnchorStore, change func(domain string, consortiumData *models.ConsortiumFileData) error) {
+	domain := mux.Vars(req)["domain"]
+
+	consortiumData, err := parseTrustAnchor(req)
+	if err != nil {
+		o.writeErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	previous := store.TrustAnchor(domain)
+
+	if err = change(domain, consortiumData); err != nil {
+		o.logger.Warnf("audit: admin %s failed to change the trust anchor of %s to version %d: %v",
+			admin, domain, consortiumData.Config.Version, err)
+
+		o.writeErrorResponse(rw, http.StatusConflict, fmt.Sprintf("failed to change trust anchor: %s", err.Error()))
+
+		return
+	}
+
+	record := &TrustAnchorChange{Action: action, Admin: admin, Time: time.Now().UTC(),
+		Version: consortiumData.Config.Version}
+
+	if previous != nil {
+		record.PreviousVersion = &previous.Config.Version
+	}
+
+	o.trustAnchorAudit.set(domain, record)
+
+	o.logger.Infof("audit: admin %s %s the trust anchor of %s to version %d from %s at %s",
+		admin, action, domain, record.Version, req.RemoteAddr, record.Time.Format(time.RFC3339))
+
+	rw.Header().Set("Content-type", "application/json")
+
+	o.writeResponse(rw, &TrustAnchorResponse{Domain: domain, Config: consortiumData.Config, LastChange: record})
+}
+
+// getTrustLogHandler returns the entries of the trust log, of the consortium domain given in the domain query
+// parameter or of all domains, and whether the hash chain of the log is intact
+func (o *Operation) getTrustLogHandler(rw http.ResponseWriter, req *http.Request, admin string) {
+	if o.trustLog == nil {
+		o.writeErrorResponse(rw, http.StatusNotImplemented, "trust log is not enabled")
+
+		return
+	}
+
+	entries, err := o.trustLog.Entries(req.URL.Query().Get("domain"))
+	if err != nil {
+		o.writeErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to read trust log: %s", err))
+
+		return
+	}
+
+	response := &TrustLogResponse{Entries: entries, Verified: true}
+
+	if err = o.trustLog.Verify(); err != nil {
+		o.logger.Warnf("audit: trust log verification failed for admin %s: %v", admin, err)
+
+		response.Verified = false
+		response.Error = err.Error()
+	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	o.writeResponse(rw, response)
+}
+
+// parseTrustAnchor parses the consortium config file in a request body, as a COSE message or a JWS
+func parseTrustAnchor(req *http.Request) (*models.ConsortiumFileData, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) == 0 {
+		return nil, errors.New("missing consortium config file")
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), coseContentType) {
+		return models.ParseConsortiumCOSE(body)
+	}
+
+	return models.ParseConsortium(body)
+}
+
+func (o *Operation) adminHandlers() []Handler {
+	if len(o.adminTokens) == 0 {
+		return nil
+	}
+
+	return []Handler{
+		support.NewHTTPHandler(trustAnchorEndpoint, http.MethodGet, o.adminHandler(o.getTrustAnchorHandler)),
+		support.NewHTTPHandler(trustAnchorEndpoint, http.MethodPut,
+			o.limitRequest("", o.adminHandler(o.pinTrustAnchorHandler))),
+		support.NewHTTPHandler(rotateTrustAnchorEndpoint, http.MethodPost,
+			o.limitRequest("", o.adminHandler(o.rotateTrustAnchorHandler))),
+		support.NewHTTPHandler(trustLogEndpoint, http.MethodGet, o.adminOnly(o.getTrustLogHandler)),
+	}
+}
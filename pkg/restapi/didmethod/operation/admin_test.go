@@ -0,0 +1,265 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+)
+
+const adminToken = "admin-token"
+
+type mockTrustAnchorVDRI struct {
+	mockvdri.MockVDRI
+	anchors   map[string]*models.ConsortiumFileData
+	rotateErr error
+}
+
+func (m *mockTrustAnchorVDRI) TrustAnchor(domain string) *models.ConsortiumFileData {
+	return m.anchors[domain]
+}
+
+func (m *mockTrustAnchorVDRI) PinTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error {
+	m.anchors[domain] = consortiumData
+
+	return nil
+}
+
+func (m *mockTrustAnchorVDRI) RotateTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error {
+	if m.rotateErr != nil {
+		return m.rotateErr
+	}
+
+	m.anchors[domain] = consortiumData
+
+	return nil
+}
+
+func adminOperation(t *testing.T, blocVDRI vdri.VDRI) *Operation {
+	svc := New(&Config{AdminTokens: map[string]string{"alice": adminToken}})
+	require.NotNil(t, svc)
+
+	if blocVDRI != nil {
+		svc.blocVDRI = blocVDRI
+	}
+
+	return svc
+}
+
+func adminRequest(t *testing.T, op *Operation, method, path, token, contentType string,
+	body []byte) (*bytes.Buffer, int) {
+	handlers, err := op.GetRESTHandlers(resolverMode)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+
+	for _, handler := range handlers {
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+	}
+
+	req, err := http.NewRequest(method, path, bytes.NewBuffer(body))
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	return rr.Body, rr.Code
+}
+
+func consortiumFile(t *testing.T, version uint64) []byte {
+	consortium := mockmodels.DummyConsortium("testnet", nil)
+	consortium.Version = version
+
+	file, err := mockmodels.WrapConsortium(consortium)
+	require.NoError(t, err)
+
+	return []byte(file)
+}
+
+func TestAdminHandlers(t *testing.T) {
+	t.Run("test admin API disabled without admin tokens", func(t *testing.T) {
+		handlers, err := New(&Config{}).GetRESTHandlers(combinedMode)
+		require.NoError(t, err)
+
+		for _, h := range handlers {
+			require.NotEqual(t, trustAnchorEndpoint, h.Path())
+		}
+
+		handlers, err = adminOperation(t, nil).GetRESTHandlers(combinedMode)
+		require.NoError(t, err)
+		require.Len(t, handlers, 8)
+	})
+
+	t.Run("test unauthorized", func(t *testing.T) {
+		op := adminOperation(t, &mockTrustAnchorVDRI{anchors: map[string]*models.ConsortiumFileData{}})
+
+		body, status := adminRequest(t, op, http.MethodGet, "/admin/trust-anchor/testnet", "", "", nil)
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.Equal(t, "unauthorized", body.String())
+
+		_, status = adminRequest(t, op, http.MethodPut, "/admin/trust-anchor/testnet", "wrong", "",
+			consortiumFile(t, 1))
+		require.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("test trust anchors not supported by the vdri", func(t *testing.T) {
+		body, status := adminRequest(t, adminOperation(t, &mockvdri.MockVDRI{}), http.MethodGet,
+			"/admin/trust-anchor/testnet", adminToken, "", nil)
+		require.Equal(t, http.StatusNotImplemented, status)
+		require.Contains(t, body.String(), "trust anchors are not supported")
+	})
+
+	t.Run("test pin, view and rotate", func(t *testing.T) {
+		blocVDRI := &mockTrustAnchorVDRI{anchors: map[string]*models.ConsortiumFileData{}}
+		op := adminOperation(t, blocVDRI)
+
+		body, status := adminRequest(t, op, http.MethodGet, "/admin/trust-anchor/testnet", adminToken, "", nil)
+		require.Equal(t, http.StatusNotFound, status)
+		require.Contains(t, body.String(), "no trust anchor pinned for testnet")
+
+		body, status = adminRequest(t, op, http.MethodPut, "/admin/trust-anchor/testnet", adminToken, "",
+			consortiumFile(t, 1))
+		require.Equal(t, http.StatusOK, status, body.String())
+
+		resp := &TrustAnchorResponse{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), resp))
+		require.Equal(t, uint64(1), resp.Config.Version)
+		require.Equal(t, pinAction, resp.LastChange.Action)
+		require.Equal(t, "alice", resp.LastChange.Admin)
+		require.Nil(t, resp.LastChange.PreviousVersion)
+
+		body, status = adminRequest(t, op, http.MethodPost, "/admin/trust-anchor/testnet/rotate", adminToken, "",
+			consortiumFile(t, 2))
+		require.Equal(t, http.StatusOK, status, body.String())
+
+		body, status = adminRequest(t, op, http.MethodGet, "/admin/trust-anchor/testnet", adminToken, "", nil)
+		require.Equal(t, http.StatusOK, status)
+
+		resp = &TrustAnchorResponse{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), resp))
+		require.Equal(t, "testnet", resp.Domain)
+		require.Equal(t, uint64(2), resp.Config.Version)
+		require.Equal(t, rotateAction, resp.LastChange.Action)
+		require.Equal(t, uint64(1), *resp.LastChange.PreviousVersion)
+		require.False(t, resp.LastChange.Time.IsZero())
+	})
+
+	t.Run("test pin COSE config file", func(t *testing.T) {
+		blocVDRI := &mockTrustAnchorVDRI{anchors: map[string]*models.ConsortiumFileData{}}
+
+		consortium, err := mockmodels.DummyConsortiumJSON("testnet", nil)
+		require.NoError(t, err)
+
+		file, err := mockmodels.DummyCOSEWrap(consortium)
+		require.NoError(t, err)
+
+		body, status := adminRequest(t, adminOperation(t, blocVDRI), http.MethodPut, "/admin/trust-anchor/testnet",
+			adminToken, coseContentType, file)
+		require.Equal(t, http.StatusOK, status, body.String())
+		require.NotNil(t, blocVDRI.anchors["testnet"].COSE)
+	})
+
+	t.Run("test invalid config file", func(t *testing.T) {
+		op := adminOperation(t, &mockTrustAnchorVDRI{anchors: map[string]*models.ConsortiumFileData{}})
+
+		body, status := adminRequest(t, op, http.MethodPut, "/admin/trust-anchor/testnet", adminToken, "", nil)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body.String(), "missing consortium config file")
+
+		_, status = adminRequest(t, op, http.MethodPut, "/admin/trust-anchor/testnet", adminToken, "",
+			[]byte("{"))
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("test rotation refused", func(t *testing.T) {
+		op := adminOperation(t, &mockTrustAnchorVDRI{anchors: map[string]*models.ConsortiumFileData{},
+			rotateErr: fmt.Errorf("version 1 is older than the trusted version 2")})
+
+		body, status := adminRequest(t, op, http.MethodPost, "/admin/trust-anchor/testnet/rotate", adminToken, "",
+			consortiumFile(t, 1))
+		require.Equal(t, http.StatusConflict, status)
+		require.Contains(t, body.String(), "failed to change trust anchor: version 1 is older")
+	})
+
+	t.Run("test trust log", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		trustLog, err := trustlog.New(provider)
+		require.NoError(t, err)
+
+		for _, domain := range []string{"testnet", "other"} {
+			file, e := models.ParseConsortium(consortiumFile(t, 1))
+			require.NoError(t, e)
+
+			_, e = trustLog.Record(domain, file, []string{"stakeholder.one"}, false)
+			require.NoError(t, e)
+		}
+
+		op := adminOperation(t, nil)
+
+		body, status := adminRequest(t, op, http.MethodGet, "/admin/trust-log", adminToken, "", nil)
+		require.Equal(t, http.StatusNotImplemented, status)
+		require.Contains(t, body.String(), "trust log is not enabled")
+
+		op.trustLog = trustLog
+
+		_, status = adminRequest(t, op, http.MethodGet, "/admin/trust-log", "", "", nil)
+		require.Equal(t, http.StatusUnauthorized, status)
+
+		body, status = adminRequest(t, op, http.MethodGet, "/admin/trust-log?domain=testnet", adminToken, "", nil)
+		require.Equal(t, http.StatusOK, status, body.String())
+
+		resp := &TrustLogResponse{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), resp))
+		require.True(t, resp.Verified)
+		require.Len(t, resp.Entries, 1)
+		require.Equal(t, "testnet", resp.Entries[0].Domain)
+		require.Equal(t, []string{"stakeholder.one"}, resp.Entries[0].Evidence.Stakeholders)
+
+		// the log is tampered with
+		store, err := provider.OpenStore(trustlog.StoreName)
+		require.NoError(t, err)
+		require.NoError(t, store.Put("entry_00000000000000000000", []byte(`{"domain":"testnet","sequence":0}`)))
+
+		body, status = adminRequest(t, op, http.MethodGet, "/admin/trust-log", adminToken, "", nil)
+		require.Equal(t, http.StatusOK, status, body.String())
+
+		resp = &TrustLogResponse{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), resp))
+		require.False(t, resp.Verified)
+		require.Len(t, resp.Entries, 2)
+		require.Equal(t, "trust log entry 0 doesn't match its hash", resp.Error)
+
+		require.NoError(t, store.Put("entry_00000000000000000000", []byte(`{`)))
+
+		body, status = adminRequest(t, op, http.MethodGet, "/admin/trust-log", adminToken, "", nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Contains(t, body.String(), "failed to read trust log")
+	})
+}
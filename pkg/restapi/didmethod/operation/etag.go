@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	didclient "github.com/trustbloc/trustbloc-did-method/pkg/did"
+)
+
+// docETag returns the strong ETag of a DID doc, computed from its canonical form so that it only changes
+// when the doc contents do. The suffix distinguishes the representations of the doc.
+func docETag(doc *did.Doc, suffix string) (string, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return "", err
+	}
+
+	canonical, err := didclient.Canonicalize(docBytes)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(canonical)
+
+	return `"` + base64.RawURLEncoding.EncodeToString(hash[:]) + suffix + `"`, nil
+}
+
+// etagMatches returns true if the If-None-Match header value matches the ETag, using the weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/support"
+)
+
+const (
+	eventsEndpoint       = "/events"
+	didUpdateEvent       = "did-update"
+	maxEventDIDs         = 100
+	keepAliveInterval    = 30 * time.Second
+	defaultWatchInterval = time.Minute
+)
+
+// eventsHandler streams the update events of the DIDs given by the `did` url params as server-sent events, until
+// the client disconnects
+func (o *Operation) eventsHandler(rw http.ResponseWriter, req *http.Request) {
+	dids := req.URL.Query()["did"]
+
+	if len(dids) == 0 || len(dids) > maxEventDIDs {
+		o.writeCodedErrorResponse(rw, http.StatusBadRequest, errcode.InvalidRequest,
+			fmt.Sprintf("url param 'did' must be set between 1 and %d times", maxEventDIDs))
+
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		o.writeErrorResponse(rw, http.StatusInternalServerError, "streaming is not supported")
+
+		return
+	}
+
+	sub := o.watcher.Subscribe(dids...)
+	defer o.watcher.Unsubscribe(sub)
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(o.keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-req.Context().Done():
+			return
+		case event := <-sub.Events:
+			var data []byte
+
+			data, err = json.Marshal(event)
+			if err == nil {
+				_, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", didUpdateEvent, data)
+			}
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(rw, ": keep-alive\n\n")
+		}
+
+		if err != nil {
+			o.logger.Errorf("Unable to send event, %s", err)
+
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+func (o *Operation) eventHandlers() []Handler {
+	if o.watcher == nil {
+		return nil
+	}
+
+	return []Handler{support.NewHTTPHandler(eventsEndpoint, http.MethodGet, o.eventsHandler)}
+}
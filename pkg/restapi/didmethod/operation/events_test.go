@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did/watcher"
+)
+
+func TestEventsHandler(t *testing.T) {
+	const didID = "did:trustbloc:testnet:123"
+
+	var (
+		endpoint = "https://agent.one"
+		mutex    sync.Mutex
+	)
+
+	blocVDRI := &mockvdri.MockVDRI{
+		ReadFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return &did.Doc{Context: []string{"https://w3id.org/did/v1"}, ID: didID,
+				Service: []did.Service{{ID: didID + "#agent", Type: "did-communication", ServiceEndpoint: endpoint}},
+			}, nil
+		}}
+
+	svc := New(&Config{Mode: proxyMode})
+	svc.watcher.Stop()
+	svc.watcher = watcher.New(blocVDRI)
+	svc.keepAliveInterval = 10 * time.Millisecond
+
+	handlers, err := svc.GetRESTHandlers(proxyMode)
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+
+	for _, handler := range handlers {
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+	}
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	t.Run("test events streamed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+eventsEndpoint+"?did="+didID, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, resp.Body.Close()) }()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		svc.watcher.Poll()
+
+		mutex.Lock()
+		endpoint = "https://agent.two"
+		mutex.Unlock()
+
+		svc.watcher.Poll()
+
+		reader := bufio.NewReader(resp.Body)
+
+		var eventName, data string
+
+		for data == "" {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				eventName = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+
+		require.Equal(t, didUpdateEvent, eventName)
+
+		event := &watcher.Event{}
+		require.NoError(t, json.Unmarshal([]byte(data), event))
+		require.Equal(t, didID, event.DID)
+		require.Len(t, event.Changes, 1)
+		require.Contains(t, event.Changes[0], "https://agent.two")
+	})
+
+	t.Run("test did param missing", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + eventsEndpoint)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("test streaming not supported", func(t *testing.T) {
+		rw := &nonFlushingWriter{header: http.Header{}}
+
+		req, err := http.NewRequest(http.MethodGet, eventsEndpoint+"?did="+didID, nil)
+		require.NoError(t, err)
+
+		svc.eventsHandler(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.status)
+	})
+}
+
+type nonFlushingWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *nonFlushingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *nonFlushingWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *nonFlushingWriter) WriteHeader(status int) {
+	w.status = status
+}
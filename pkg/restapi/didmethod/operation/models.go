@@ -5,6 +5,14 @@ SPDX-License-Identifier: Apache-2.0
 
 package operation
 
+import (
+	"time"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+)
+
 const (
 	// RegistrationStateFinished registration state finished
 	RegistrationStateFinished = "finished"
@@ -19,10 +27,25 @@ type RegisterDIDRequest struct {
 	DIDDocument DIDDocument       `json:"didDocument,omitempty"`
 }
 
+// ValidationErrorResponse is returned when a request fails validation, with the reason of each invalid field
+type ValidationErrorResponse struct {
+	Message string       `json:"message"`
+	Code    errcode.Code `json:"code"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError is the validation error of a request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // DIDDocument did doc
 type DIDDocument struct {
-	PublicKey []*PublicKey `json:"publicKey,omitempty"`
-	Service   []*Service   `json:"service,omitempty"`
+	Context     []string     `json:"@context,omitempty"`
+	PublicKey   []*PublicKey `json:"publicKey,omitempty"`
+	Service     []*Service   `json:"service,omitempty"`
+	AlsoKnownAs []string     `json:"alsoKnownAs,omitempty"`
 }
 
 // RegisterResponse register response
@@ -35,10 +58,11 @@ type RegisterResponse struct {
 
 // DIDState did state
 type DIDState struct {
-	Identifier string `json:"identifier,omitempty"`
-	Reason     string `json:"reason,omitempty"`
-	State      string `json:"state,omitempty"`
-	Secret     Secret `json:"secret,omitempty"`
+	Identifier string       `json:"identifier,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+	Code       errcode.Code `json:"code,omitempty"`
+	State      string       `json:"state,omitempty"`
+	Secret     Secret       `json:"secret,omitempty"`
 }
 
 // Secret include keys
@@ -76,3 +100,27 @@ type Service struct {
 	RoutingKeys   []string `json:"routingKeys,omitempty"`
 	Endpoint      string   `json:"endpoint,omitempty"`
 }
+
+// TrustAnchorResponse is the consortium config pinned as the root of trust of a domain
+type TrustAnchorResponse struct {
+	Domain     string             `json:"domain"`
+	Config     *models.Consortium `json:"config"`
+	LastChange *TrustAnchorChange `json:"lastChange,omitempty"`
+}
+
+// TrustLogResponse lists the consortium config versions trusted by the service
+type TrustLogResponse struct {
+	Entries []*trustlog.Entry `json:"entries"`
+	// Verified is true if the hash chain of the whole log is intact
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// TrustAnchorChange is the audit record of a change of a trust anchor
+type TrustAnchorChange struct {
+	Action          string    `json:"action"`
+	Admin           string    `json:"admin"`
+	Time            time.Time `json:"time"`
+	Version         uint64    `json:"version"`
+	PreviousVersion *uint64   `json:"previousVersion,omitempty"`
+}
@@ -6,28 +6,42 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
-	log "github.com/sirupsen/logrus"
+	"github.com/square/go-jose/v3"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
 	didclient "github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/did/watcher"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
 	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/support"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/proxy"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+	"github.com/trustbloc/trustbloc-did-method/pkg/version"
 )
 
 const (
 	registerBasePath     = "/1.0"
 	registerPath         = registerBasePath + "/register"
 	resolveDIDEndpoint   = "/resolveDID"
+	healthEndpoint       = "/consortium/{domain}/health"
+	infoEndpoint         = "/info"
 	didLDJson            = "application/did+ld+json"
 	invalidRequestErrMsg = "invalid request"
 
@@ -35,6 +49,10 @@ const (
 	registrarMode = "registrar"
 	resolverMode  = "resolver"
 	combinedMode  = "combined"
+	proxyMode     = "proxy"
+
+	defaultProxyCacheTTL = time.Minute
+	signedETagSuffix     = "-jws"
 )
 
 // Handler http handler for each controller API endpoint
@@ -46,9 +64,18 @@ type Handler interface {
 
 // Operation defines handlers
 type Operation struct {
-	blocVDRI      vdri.VDRI
-	didBlocClient didBlocClient
-	blocDomain    string
+	blocVDRI          vdri.VDRI
+	didBlocClient     didBlocClient
+	blocDomain        string
+	adminTokens       map[string]string
+	trustAnchorAudit  *trustAnchorAudit
+	signingKey        *jose.SigningKey
+	watcher           *watcher.Watcher
+	keepAliveInterval time.Duration
+	auditSink         audit.Sink
+	trustLog          *trustlog.Log
+	maxBodySize       int64
+	logger            log.Logger
 }
 
 // Config defines configuration for trustbloc did method operations
@@ -58,58 +85,218 @@ type Config struct {
 	Mode               string
 	SidetreeReadToken  string
 	SidetreeWriteToken string
+	// AdminTokens are the bearer tokens of the admin API by admin name. The admin API is disabled if empty.
+	AdminTokens map[string]string
+	// ProxySigningKey signs the DID resolution results served to the clients of a resolver proxy
+	ProxySigningKey *jose.SigningKey
+	// ProxyCacheTTL is the TTL of the DID docs cached by a resolver proxy, one minute by default
+	ProxyCacheTTL time.Duration
+	// WatchInterval is the interval between the polls of the DIDs whose update events are streamed by a resolver
+	// proxy, one minute by default
+	WatchInterval time.Duration
+	// AuditSink records the DID operations processed by the registrar. Operations aren't audited if nil.
+	AuditSink audit.Sink
+	// MaxRequestBodySize is the maximum size in bytes of request bodies, 64 KiB by default
+	MaxRequestBodySize int64
+	// TrustLog records the consortium config versions trusted by the service, served by the admin API. Trusted
+	// configs aren't logged if nil.
+	TrustLog *trustlog.Log
+	// Logger is the logger of the operations, and of the VDRI, DID client and watcher they create. Defaults to the
+	// standard logrus logger.
+	Logger log.Logger
+}
+
+type consortiumValidator interface {
+	ValidateConsortium(domain string) (*time.Duration, error)
+}
+
+type contextConsortiumValidator interface {
+	ValidateConsortiumWithContext(ctx context.Context, domain string) (*time.Duration, error)
+}
+
+type contextVDRI interface {
+	ReadWithContext(ctx context.Context, did string, opts ...vdri.ResolveOpts) (*did.Doc, error)
+}
+
+type consortiumHealthChecker interface {
+	ConsortiumHealth(domain string) (*trustbloc.ConsortiumHealth, error)
 }
 
 type didBlocClient interface {
 	CreateDID(domain string, opts ...didclient.CreateDIDOption) (*did.Doc, error)
+	SendUpdateRequest(didID string, req []byte) error
+	SendDeactivateRequest(didID string, req []byte) error
 }
 
 // New returns did method operation instance
 func New(config *Config) *Operation {
-	svc := &Operation{blocVDRI: trustbloc.New(trustbloc.WithTLSConfig(config.TLSConfig),
-		trustbloc.WithAuthToken(config.SidetreeReadToken)),
+	logger := log.OrDefault(config.Logger)
+
+	vdriOpts := []trustbloc.Option{trustbloc.WithTLSConfig(config.TLSConfig),
+		trustbloc.WithAuthToken(config.SidetreeReadToken), trustbloc.WithLogger(logger)}
+
+	if config.Mode == proxyMode {
+		// a proxy resolves DIDs for many clients, so resolved docs are cached
+		ttl := config.ProxyCacheTTL
+		if ttl <= 0 {
+			ttl = defaultProxyCacheTTL
+		}
+
+		vdriOpts = append(vdriOpts, trustbloc.WithResolutionCacheTTL(ttl))
+	}
+
+	if config.TrustLog != nil {
+		vdriOpts = append(vdriOpts, trustbloc.WithTrustLog(config.TrustLog))
+	}
+
+	blocVDRI := trustbloc.New(vdriOpts...)
+
+	svc := &Operation{blocVDRI: blocVDRI,
 		didBlocClient: didclient.New(didclient.WithTLSConfig(config.TLSConfig),
-			didclient.WithAuthToken(config.SidetreeWriteToken)),
-		blocDomain: config.BlocDomain}
+			didclient.WithAuthToken(config.SidetreeWriteToken), didclient.WithLogger(logger)),
+		blocDomain:        config.BlocDomain,
+		adminTokens:       config.AdminTokens,
+		trustAnchorAudit:  &trustAnchorAudit{changes: map[string]*TrustAnchorChange{}},
+		signingKey:        config.ProxySigningKey,
+		keepAliveInterval: keepAliveInterval,
+		auditSink:         config.AuditSink,
+		trustLog:          config.TrustLog,
+		maxBodySize:       config.MaxRequestBodySize,
+		logger:            logger}
+
+	if svc.maxBodySize <= 0 {
+		svc.maxBodySize = defaultMaxBodySize
+	}
+
+	if config.Mode == proxyMode {
+		interval := config.WatchInterval
+		if interval <= 0 {
+			interval = defaultWatchInterval
+		}
+
+		svc.watcher = watcher.New(blocVDRI, watcher.WithInterval(interval), watcher.WithLogger(logger))
+		svc.watcher.Start()
+	}
+
+	if config.BlocDomain != "" {
+		// warm up the consortium caches in the background, failures are retried on the first resolution
+		go func() {
+			if err := blocVDRI.Prefetch(config.BlocDomain); err != nil {
+				logger.Warnf("failed to prefetch consortium %s: %v", config.BlocDomain, err)
+			}
+		}()
+	}
 
 	return svc
 }
 
-func (o *Operation) registerDIDHandler(rw http.ResponseWriter, req *http.Request) { //nolint: funlen
+func (o *Operation) registerDIDHandler(rw http.ResponseWriter, req *http.Request) {
 	data := RegisterDIDRequest{}
 
-	if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
-		o.writeErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&data); err != nil {
+		o.writeCodedErrorResponse(rw, http.StatusBadRequest, errcode.InvalidRequest,
+			fmt.Sprintf(invalidRequestErrMsg+": %s", err.Error()))
+
+		return
+	}
+
+	if fieldErrs := data.validate(); len(fieldErrs) > 0 {
+		rw.Header().Set("Content-Type", jsonContentType)
+		rw.Header().Set(errcode.Header, string(errcode.InvalidRequest))
+		rw.WriteHeader(http.StatusBadRequest)
+		o.writeResponse(rw, &ValidationErrorResponse{Message: validationFailedErrMsg, Code: errcode.InvalidRequest,
+			Fields: fieldErrs})
+
+		return
+	}
+
+	o.writeResponse(rw, o.RegisterDID(requester(req), &data))
+}
+
+// requester identifies the requester of an operation by the subject of its TLS client certificate, or by its address
+func requester(req *http.Request) string {
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return req.TLS.PeerCertificates[0].Subject.String()
+	}
+
+	return req.RemoteAddr
+}
+
+// RegisterDID creates the DID described by the given register request on behalf of the given requester, recording
+// the operation to the audit sink. Registration failures are reported in the DID state of the response.
+func (o *Operation) RegisterDID(requester string, data *RegisterDIDRequest) *RegisterResponse {
+	return o.RegisterDIDWithContext(context.Background(), requester, data)
+}
+
+// RegisterDIDWithContext creates a DID like RegisterDID, aborting the create request when the context is done
+func (o *Operation) RegisterDIDWithContext(ctx context.Context, requester string,
+	data *RegisterDIDRequest) *RegisterResponse {
+	registerResponse := o.registerDID(ctx, data)
+
+	if o.auditSink != nil {
+		o.auditCreate(requester, data, &registerResponse.DIDState)
+	}
+
+	return registerResponse
+}
+
+// auditCreate records a create operation, whose single patch replaces the empty doc with the requested one
+func (o *Operation) auditCreate(requester string, data *RegisterDIDRequest, state *DIDState) {
+	record := &audit.Record{Time: time.Now().UTC(), Operation: audit.Create, Requester: requester,
+		DID: state.Identifier, Success: state.State == RegistrationStateFinished}
+
+	if !record.Success {
+		record.Error = state.Reason
+	}
+
+	request, err := json.Marshal(data)
+	if err != nil {
+		o.logger.Errorf("failed to audit create operation of %s: %s", requester, err)
+
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"action": "replace", "document": data.DIDDocument})
+	if err != nil {
+		o.logger.Errorf("failed to audit create operation of %s: %s", requester, err)
 
 		return
 	}
 
+	record.OperationHash = audit.Hash(request)
+	record.Patches = []json.RawMessage{patch}
+
+	if err = o.auditSink.Write(record); err != nil {
+		o.logger.Errorf("failed to audit create operation of %s: %s", requester, err)
+	}
+}
+
+func (o *Operation) registerDID(ctx context.Context, data *RegisterDIDRequest) *RegisterResponse {
 	var opts []didclient.CreateDIDOption
 
-	registerResponse := RegisterResponse{JobID: data.JobID}
+	registerResponse := &RegisterResponse{JobID: data.JobID}
 	keysID := make(map[string][]byte)
 
 	if len(data.DIDDocument.PublicKey) == 0 {
 		registerResponse.DIDState = DIDState{Reason: fmt.Sprintf("AddPublicKeys is empty"),
-			State: RegistrationStateFailure}
+			Code: errcode.InvalidRequest, State: RegistrationStateFailure}
 
-		o.writeResponse(rw, registerResponse)
-
-		return
+		return registerResponse
 	}
 
 	// Add public keys
 	for _, v := range data.DIDDocument.PublicKey {
 		keyValue, err := base64.StdEncoding.DecodeString(v.Value)
 		if err != nil {
-			log.Errorf("failed to decode public key value : %s", err.Error())
+			o.logger.Errorf("failed to decode public key value : %s", err.Error())
 
 			registerResponse.DIDState = DIDState{Reason: fmt.Sprintf("failed to decode public key value : %s",
-				err.Error()), State: RegistrationStateFailure}
+				err.Error()), Code: errcode.InvalidRequest, State: RegistrationStateFailure}
 
-			o.writeResponse(rw, registerResponse)
-
-			return
+			return registerResponse
 		}
 
 		opts = append(opts, didclient.WithPublicKey(&didclient.PublicKey{ID: v.ID, Type: v.Type, Value: keyValue,
@@ -127,22 +314,23 @@ func (o *Operation) registerDIDHandler(rw http.ResponseWriter, req *http.Request
 			ServiceEndpoint: service.Endpoint}))
 	}
 
+	opts = append(opts, didclient.WithContext(data.DIDDocument.Context...),
+		didclient.WithAlsoKnownAs(data.DIDDocument.AlsoKnownAs...), didclient.WithCreateContext(ctx))
+
 	didDoc, err := o.didBlocClient.CreateDID(o.blocDomain, opts...)
 	if err != nil {
-		log.Errorf("failed to create did doc : %s", err.Error())
+		o.logger.Errorf("failed to create did doc : %s", err.Error())
 
 		registerResponse.DIDState = DIDState{Reason: fmt.Sprintf("failed to create did doc : %s", err.Error()),
-			State: RegistrationStateFailure}
-
-		o.writeResponse(rw, registerResponse)
+			Code: errcode.OrDefault(err, errcode.RegistrationFailed), State: RegistrationStateFailure}
 
-		return
+		return registerResponse
 	}
 
 	registerResponse.DIDState = DIDState{Identifier: didDoc.ID, State: RegistrationStateFinished,
 		Secret: Secret{Keys: createKeys(keysID, didDoc.ID)}}
 
-	o.writeResponse(rw, registerResponse)
+	return registerResponse
 }
 
 func createKeys(keysID map[string][]byte, didID string) []Key {
@@ -159,7 +347,7 @@ func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request)
 	didParam, ok := req.URL.Query()["did"]
 
 	if !ok || didParam[0] == "" {
-		o.writeErrorResponse(rw, http.StatusBadRequest,
+		o.writeCodedErrorResponse(rw, http.StatusBadRequest, errcode.InvalidRequest,
 			fmt.Sprintf("url param 'did' is missing"))
 
 		return
@@ -167,12 +355,43 @@ func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request)
 
 	didDoc, err := o.blocVDRI.Read(didParam[0])
 	if err != nil {
-		o.writeErrorResponse(rw, http.StatusBadRequest,
+		o.writeCodedErrorResponse(rw, http.StatusBadRequest, errcode.OrDefault(err, errcode.ResolutionFailed),
 			fmt.Sprintf("failed to resolve did: %s", err.Error()))
 
 		return
 	}
 
+	// the clients of a resolver proxy request results signed with its key
+	signed := o.signingKey != nil && strings.Contains(req.Header.Get("Accept"), proxy.SignedResultContentType)
+
+	etagSuffix := ""
+	if signed {
+		etagSuffix = signedETagSuffix
+	}
+
+	etag, err := docETag(didDoc, etagSuffix)
+	if err != nil {
+		o.writeErrorResponse(rw, http.StatusInternalServerError,
+			fmt.Sprintf("failed to compute etag of did doc: %s", err.Error()))
+
+		return
+	}
+
+	rw.Header().Set("ETag", etag)
+	rw.Header().Set("Vary", "Accept")
+
+	// polling clients transfer nothing if the doc didn't change
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		rw.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	o.writeResolutionResult(rw, didDoc, signed)
+}
+
+// writeResolutionResult writes the resolution result of a DID doc, signed with the proxy's key if requested
+func (o *Operation) writeResolutionResult(rw http.ResponseWriter, didDoc *did.Doc, signed bool) {
 	bytes, err := models.MakeDIDResolutionResult(didDoc)
 	if err != nil {
 		o.writeErrorResponse(rw, http.StatusInternalServerError,
@@ -181,12 +400,96 @@ func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	rw.Header().Set("Content-type", didLDJson)
+	contentType := didLDJson
+
+	if signed {
+		jws, signErr := models.SignDIDResolutionResult(bytes, *o.signingKey)
+		if signErr != nil {
+			o.writeErrorResponse(rw, http.StatusInternalServerError,
+				fmt.Sprintf("failed to sign did resolution result: %s", signErr.Error()))
+
+			return
+		}
+
+		bytes, contentType = []byte(jws), proxy.SignedResultContentType
+	}
+
+	rw.Header().Set("Content-type", contentType)
 	rw.WriteHeader(http.StatusOK)
 
 	if _, err := rw.Write(bytes); err != nil {
-		log.Errorf("Unable to send error message, %s", err)
+		o.logger.Errorf("Unable to send error message, %s", err)
+	}
+}
+
+// ResolveDID resolves a DID
+func (o *Operation) ResolveDID(didID string) (*did.Doc, error) {
+	return o.ResolveDIDWithContext(context.Background(), didID)
+}
+
+// ResolveDIDWithContext resolves a DID, aborting the resolution when the context is done if the vdri supports it
+func (o *Operation) ResolveDIDWithContext(ctx context.Context, didID string) (*did.Doc, error) {
+	if v, ok := o.blocVDRI.(contextVDRI); ok {
+		return v.ReadWithContext(ctx, didID)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return o.blocVDRI.Read(didID)
+}
+
+// ValidateConsortium validates the consortium config of the given domain, returning its cache lifetime
+func (o *Operation) ValidateConsortium(domain string) (*time.Duration, error) {
+	return o.ValidateConsortiumWithContext(context.Background(), domain)
+}
+
+// ValidateConsortiumWithContext validates the consortium config of the given domain like ValidateConsortium,
+// aborting the validation when the context is done if the vdri supports it
+func (o *Operation) ValidateConsortiumWithContext(ctx context.Context, domain string) (*time.Duration, error) {
+	if validator, ok := o.blocVDRI.(contextConsortiumValidator); ok {
+		return validator.ValidateConsortiumWithContext(ctx, domain)
+	}
+
+	validator, ok := o.blocVDRI.(consortiumValidator)
+	if !ok {
+		return nil, errors.New("consortium validation is not supported by the vdri")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return validator.ValidateConsortium(domain)
+}
+
+func (o *Operation) consortiumHealthHandler(rw http.ResponseWriter, req *http.Request) {
+	checker, ok := o.blocVDRI.(consortiumHealthChecker)
+	if !ok {
+		o.writeErrorResponse(rw, http.StatusNotImplemented, "consortium health is not supported by the vdri")
+
+		return
+	}
+
+	health, err := checker.ConsortiumHealth(mux.Vars(req)["domain"])
+	if err != nil {
+		o.writeCodedErrorResponse(rw, http.StatusBadGateway, errcode.OrDefault(err, errcode.ConsortiumUnavailable),
+			fmt.Sprintf("failed to check consortium health: %s", err.Error()))
+
+		return
 	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	o.writeResponse(rw, health)
+}
+
+// infoHandler serves the version and build information of the service, so operators can verify what's deployed
+func (o *Operation) infoHandler(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-type", "application/json")
+
+	o.writeResponse(rw, version.Get())
 }
 
 // writeErrorResponse writes interface value to response
@@ -194,41 +497,53 @@ func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, msg s
 	rw.WriteHeader(status)
 
 	if _, err := rw.Write([]byte(msg)); err != nil {
-		log.Errorf("Unable to send error message, %s", err)
+		o.logger.Errorf("Unable to send error message, %s", err)
 	}
 }
 
+// writeCodedErrorResponse writes an error response with its code in the Error-Code header
+func (o *Operation) writeCodedErrorResponse(rw http.ResponseWriter, status int, code errcode.Code, msg string) {
+	rw.Header().Set(errcode.Header, string(code))
+
+	o.writeErrorResponse(rw, status, msg)
+}
+
 // writeResponse writes interface value to response
 func (o *Operation) writeResponse(rw io.Writer, v interface{}) {
 	err := json.NewEncoder(rw).Encode(v)
 	if err != nil {
-		log.Errorf("Unable to send error response, %s", err)
+		o.logger.Errorf("Unable to send error response, %s", err)
 	}
 }
 
 func (o *Operation) registrarHandlers() []Handler {
 	return []Handler{
-		support.NewHTTPHandler(registerPath, http.MethodPost, o.registerDIDHandler)}
+		support.NewHTTPHandler(registerPath, http.MethodPost, o.limitRequest(jsonContentType, o.registerDIDHandler))}
 }
 
 func (o *Operation) resolverHandlers() []Handler {
 	return []Handler{
-		support.NewHTTPHandler(resolveDIDEndpoint, http.MethodGet, o.resolveDIDHandler)}
+		support.NewHTTPHandler(resolveDIDEndpoint, http.MethodGet, o.resolveDIDHandler),
+		support.NewHTTPHandler(healthEndpoint, http.MethodGet, o.consortiumHealthHandler)}
 }
 
 // GetRESTHandlers get all controller API handler available for this service
 func (o *Operation) GetRESTHandlers(mode string) ([]Handler, error) {
+	var handlers []Handler
+
 	switch mode {
 	case registrarMode:
-		return o.registrarHandlers(), nil
-	case resolverMode:
-		return o.resolverHandlers(), nil
+		handlers = o.registrarHandlers()
+	case resolverMode, proxyMode:
+		handlers = append(append(o.resolverHandlers(), o.eventHandlers()...), o.adminHandlers()...)
 	case combinedMode:
 		vh := o.registrarHandlers()
 		ih := o.resolverHandlers()
 
-		return append(vh, ih...), nil
+		handlers = append(append(vh, ih...), o.adminHandlers()...)
 	default:
 		return nil, fmt.Errorf("invalid operation mode: %s", mode)
 	}
+
+	return append(handlers, support.NewHTTPHandler(infoEndpoint, http.MethodGet, o.infoHandler)), nil
 }
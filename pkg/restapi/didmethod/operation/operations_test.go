@@ -7,20 +7,36 @@ package operation
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	mockaudit "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/audit"
 	"github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/didbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/proxy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/version"
 )
 
 func TestNew(t *testing.T) {
@@ -30,9 +46,11 @@ func TestNew(t *testing.T) {
 		handlers, err := svc.GetRESTHandlers(combinedMode)
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
-		require.Equal(t, 2, len(handlers))
+		require.Equal(t, 4, len(handlers))
 		require.Equal(t, registerPath, handlers[0].Path())
 		require.Equal(t, resolveDIDEndpoint, handlers[1].Path())
+		require.Equal(t, healthEndpoint, handlers[2].Path())
+		require.Equal(t, infoEndpoint, handlers[3].Path())
 	})
 
 	t.Run("test registrar mode", func(t *testing.T) {
@@ -41,8 +59,9 @@ func TestNew(t *testing.T) {
 		handlers, err := svc.GetRESTHandlers(registrarMode)
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
-		require.Equal(t, 1, len(handlers))
+		require.Equal(t, 2, len(handlers))
 		require.Equal(t, registerPath, handlers[0].Path())
+		require.Equal(t, infoEndpoint, handlers[1].Path())
 	})
 
 	t.Run("test resolver mode", func(t *testing.T) {
@@ -51,8 +70,21 @@ func TestNew(t *testing.T) {
 		handlers, err := svc.GetRESTHandlers(resolverMode)
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
-		require.Equal(t, 1, len(handlers))
+		require.Equal(t, 3, len(handlers))
+		require.Equal(t, resolveDIDEndpoint, handlers[0].Path())
+		require.Equal(t, healthEndpoint, handlers[1].Path())
+		require.Equal(t, infoEndpoint, handlers[2].Path())
+	})
+
+	t.Run("test proxy mode", func(t *testing.T) {
+		svc := New(&Config{Mode: proxyMode})
+		require.NotNil(t, svc)
+		handlers, err := svc.GetRESTHandlers(proxyMode)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(handlers))
 		require.Equal(t, resolveDIDEndpoint, handlers[0].Path())
+		require.Equal(t, eventsEndpoint, handlers[2].Path())
+		require.Equal(t, infoEndpoint, handlers[3].Path())
 	})
 
 	t.Run("test invalid mode", func(t *testing.T) {
@@ -82,14 +114,14 @@ func TestRegisterDIDHandler(t *testing.T) {
 
 		body, status, err := handleRequest(handler, registerPath, req)
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, http.StatusBadRequest, status)
 
-		var registerResponse RegisterResponse
-		require.NoError(t, json.Unmarshal(body.Bytes(), &registerResponse))
+		var errResponse ValidationErrorResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &errResponse))
 
-		require.Equal(t, "1", registerResponse.JobID)
-		require.Equal(t, RegistrationStateFailure, registerResponse.DIDState.State)
-		require.Contains(t, registerResponse.DIDState.Reason, "AddPublicKeys is empty")
+		require.Equal(t, []FieldError{{Field: "didDocument.publicKey",
+			Message: "at least one public key is required"}}, errResponse.Fields)
+		require.Equal(t, errcode.InvalidRequest, errResponse.Code)
 	})
 
 	t.Run("test wrong value for public key", func(t *testing.T) {
@@ -102,14 +134,13 @@ func TestRegisterDIDHandler(t *testing.T) {
 
 		body, status, err := handleRequest(handler, registerPath, req)
 		require.NoError(t, err)
-		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, http.StatusBadRequest, status)
 
-		var registerResponse RegisterResponse
-		require.NoError(t, json.Unmarshal(body.Bytes(), &registerResponse))
+		var errResponse ValidationErrorResponse
+		require.NoError(t, json.Unmarshal(body.Bytes(), &errResponse))
 
-		require.Equal(t, "1", registerResponse.JobID)
-		require.Equal(t, RegistrationStateFailure, registerResponse.DIDState.State)
-		require.Contains(t, registerResponse.DIDState.Reason, "failed to decode public key value")
+		require.Equal(t, []FieldError{{Field: "didDocument.publicKey[0].value",
+			Message: "value must be base64 encoded"}}, errResponse.Fields)
 	})
 
 	t.Run("test error from create did", func(t *testing.T) {
@@ -131,6 +162,7 @@ func TestRegisterDIDHandler(t *testing.T) {
 		require.Equal(t, "1", registerResponse.JobID)
 		require.Equal(t, RegistrationStateFailure, registerResponse.DIDState.State)
 		require.Contains(t, registerResponse.DIDState.Reason, "error create did")
+		require.Equal(t, errcode.RegistrationFailed, registerResponse.DIDState.Code)
 	})
 
 	t.Run("test success with provided public key", func(t *testing.T) {
@@ -140,7 +172,7 @@ func TestRegisterDIDHandler(t *testing.T) {
 		req, err := json.Marshal(RegisterDIDRequest{JobID: "1", DIDDocument: DIDDocument{
 			PublicKey: []*PublicKey{{ID: "key2",
 				Type: "type", Value: base64.StdEncoding.EncodeToString([]byte("value"))}},
-			Service: []*Service{{ID: "serviceID"}}}})
+			Service: []*Service{{ID: "serviceID", Type: "did-communication", Endpoint: "https://agent.example.com"}}}})
 		require.NoError(t, err)
 
 		body, status, err := handleRequest(handler, registerPath, req)
@@ -159,6 +191,68 @@ func TestRegisterDIDHandler(t *testing.T) {
 	})
 }
 
+func TestRegisterDID_Audit(t *testing.T) {
+	data := &RegisterDIDRequest{JobID: "1", DIDDocument: DIDDocument{
+		PublicKey: []*PublicKey{{ID: "key2",
+			Type: "type", Value: base64.StdEncoding.EncodeToString([]byte("value"))}}}}
+
+	t.Run("success", func(t *testing.T) {
+		sink := &mockaudit.Sink{}
+
+		svc := New(&Config{AuditSink: sink})
+		svc.didBlocClient = &didbloc.Client{CreateDIDValue: &did.Doc{ID: "did1"}}
+
+		resp := svc.RegisterDID("CN=client", data)
+		require.Equal(t, RegistrationStateFinished, resp.DIDState.State)
+
+		request, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		require.Len(t, sink.Records, 1)
+		record := sink.Records[0]
+		require.Equal(t, audit.Create, record.Operation)
+		require.Equal(t, "CN=client", record.Requester)
+		require.Equal(t, "did1", record.DID)
+		require.Equal(t, audit.Hash(request), record.OperationHash)
+		require.True(t, record.Success)
+		require.Len(t, record.Patches, 1)
+		require.Contains(t, string(record.Patches[0]), `"action":"replace"`)
+		require.Contains(t, string(record.Patches[0]), `"id":"key2"`)
+	})
+
+	t.Run("failed operations are audited", func(t *testing.T) {
+		sink := &mockaudit.Sink{}
+
+		svc := New(&Config{AuditSink: sink})
+		svc.didBlocClient = &didbloc.Client{CreateDIDErr: fmt.Errorf("error create did")}
+
+		resp := svc.RegisterDID("CN=client", data)
+		require.Equal(t, RegistrationStateFailure, resp.DIDState.State)
+
+		require.Len(t, sink.Records, 1)
+		require.False(t, sink.Records[0].Success)
+		require.Empty(t, sink.Records[0].DID)
+		require.Contains(t, sink.Records[0].Error, "error create did")
+	})
+
+	t.Run("audit failures don't fail the operation", func(t *testing.T) {
+		svc := New(&Config{AuditSink: &mockaudit.Sink{WriteErr: fmt.Errorf("sink unavailable")}})
+		svc.didBlocClient = &didbloc.Client{CreateDIDValue: &did.Doc{ID: "did1"}}
+
+		resp := svc.RegisterDID("CN=client", data)
+		require.Equal(t, RegistrationStateFinished, resp.DIDState.State)
+	})
+}
+
+func TestRequester(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, registerPath, nil)
+	require.Equal(t, req.RemoteAddr, requester(req))
+
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+		{Subject: pkix.Name{CommonName: "client", Organization: []string{"org"}}}}}
+	require.Equal(t, "CN=client,O=org", requester(req))
+}
+
 func TestResolveDIDHandler(t *testing.T) {
 	t.Run("test did param missing", func(t *testing.T) {
 		handler := getHandler(t, nil, nil, resolveDIDEndpoint)
@@ -194,12 +288,274 @@ func TestResolveDIDHandler(t *testing.T) {
 	})
 }
 
+func TestOperation_WithContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("context-aware vdri", func(t *testing.T) {
+		svc := New(&Config{})
+
+		_, err := svc.ResolveDIDWithContext(canceled, "did:trustbloc:testnet:123")
+		require.True(t, errors.Is(err, context.Canceled))
+
+		_, err = svc.ValidateConsortiumWithContext(canceled, "testnet")
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("vdri without context isn't called once the context is done", func(t *testing.T) {
+		svc := New(&Config{})
+		svc.blocVDRI = &mockvdri.MockVDRI{ReadFunc: func(string, ...vdri.ResolveOpts) (*did.Doc, error) {
+			return nil, errors.New("unexpected read")
+		}}
+
+		_, err := svc.ResolveDIDWithContext(canceled, "did:trustbloc:testnet:123")
+		require.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestResolveDIDHandler_ErrorCode(t *testing.T) {
+	resolve := func(t *testing.T, readErr error, path string) *httptest.ResponseRecorder {
+		handler := getHandler(t, &mockvdri.MockVDRI{
+			ReadFunc: func(didID string, opts ...vdri.ResolveOpts) (doc *did.Doc, err error) {
+				return nil, readErr
+			}}, nil, resolveDIDEndpoint)
+
+		rr := httptest.NewRecorder()
+		handler.Handle()(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		return rr
+	}
+
+	t.Run("test code of the error", func(t *testing.T) {
+		rr := resolve(t, errcode.Errorf(errcode.DIDNotFound, "DID does not exist"), resolveDIDEndpoint+"?did=123")
+		require.Equal(t, string(errcode.DIDNotFound), rr.Header().Get(errcode.Header))
+		require.Contains(t, rr.Body.String(), "DID does not exist")
+	})
+
+	t.Run("test default code", func(t *testing.T) {
+		rr := resolve(t, fmt.Errorf("read error"), resolveDIDEndpoint+"?did=123")
+		require.Equal(t, string(errcode.ResolutionFailed), rr.Header().Get(errcode.Header))
+	})
+
+	t.Run("test invalid request", func(t *testing.T) {
+		rr := resolve(t, nil, resolveDIDEndpoint)
+		require.Equal(t, string(errcode.InvalidRequest), rr.Header().Get(errcode.Header))
+	})
+}
+
+type mockHealthVDRI struct {
+	mockvdri.MockVDRI
+	health *trustbloc.ConsortiumHealth
+	err    error
+}
+
+func (m *mockHealthVDRI) ConsortiumHealth(domain string) (*trustbloc.ConsortiumHealth, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.health.Domain = domain
+
+	return m.health, nil
+}
+
+func TestInfoHandler(t *testing.T) {
+	handler := getHandler(t, nil, nil, infoEndpoint)
+
+	body, status, err := handleRequest(handler, infoEndpoint, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+
+	info := &version.Info{}
+	require.NoError(t, json.Unmarshal(body.Bytes(), info))
+	require.Equal(t, version.Get(), info)
+}
+
+func TestConsortiumHealthHandler(t *testing.T) {
+	t.Run("test not supported by the vdri", func(t *testing.T) {
+		handler := getHandler(t, &mockvdri.MockVDRI{}, nil, healthEndpoint)
+
+		body, status, err := handleRequest(handler, "/consortium/testnet/health", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotImplemented, status)
+		require.Contains(t, body.String(), "consortium health is not supported")
+	})
+
+	t.Run("test error from consortium health", func(t *testing.T) {
+		handler := getHandler(t, &mockHealthVDRI{err: fmt.Errorf("consortium invalid")}, nil, healthEndpoint)
+
+		body, status, err := handleRequest(handler, "/consortium/testnet/health", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadGateway, status)
+		require.Contains(t, body.String(), "consortium invalid")
+	})
+
+	t.Run("test success", func(t *testing.T) {
+		handler := getHandler(t, &mockHealthVDRI{health: &trustbloc.ConsortiumHealth{
+			Stakeholders: []*trustbloc.StakeholderHealth{{Domain: "stakeholder.one", Healthy: true}},
+		}}, nil, healthEndpoint)
+
+		body, status, err := handleRequest(handler, "/consortium/testnet/health", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+
+		health := &trustbloc.ConsortiumHealth{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), health))
+		require.Equal(t, "testnet", health.Domain)
+		require.Len(t, health.Stakeholders, 1)
+		require.True(t, health.Stakeholders[0].Healthy)
+	})
+}
+
+func TestResolveDIDHandler_Signed(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	blocVDRI := &mockvdri.MockVDRI{
+		ReadFunc: func(didID string, opts ...vdri.ResolveOpts) (doc *did.Doc, err error) {
+			return &did.Doc{ID: didID, Context: []string{"https://w3id.org/did/v1"}}, nil
+		}}
+
+	signedRequest := func(svc *Operation) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		handler := handlerLookup(t, svc, resolveDIDEndpoint)
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+		req, err := http.NewRequest(http.MethodGet, resolveDIDEndpoint+"?did=did:trustbloc:testnet:123", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept", proxy.SignedResultContentType)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("test signed result", func(t *testing.T) {
+		svc := New(&Config{Mode: proxyMode, ProxyCacheTTL: time.Second,
+			ProxySigningKey: &jose.SigningKey{Algorithm: jose.EdDSA, Key: priv}})
+		svc.blocVDRI = blocVDRI
+
+		rr := signedRequest(svc)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, proxy.SignedResultContentType, rr.Header().Get("Content-type"))
+
+		doc, err := models.VerifyDIDResolutionResult(rr.Body.Bytes(), pub)
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123", doc.ID)
+	})
+
+	t.Run("test unsigned result without signing key", func(t *testing.T) {
+		svc := New(&Config{})
+		svc.blocVDRI = blocVDRI
+
+		rr := signedRequest(svc)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, didLDJson, rr.Header().Get("Content-type"))
+	})
+
+	t.Run("test error signing result", func(t *testing.T) {
+		svc := New(&Config{ProxySigningKey: &jose.SigningKey{Algorithm: jose.EdDSA, Key: "invalid"}})
+		svc.blocVDRI = blocVDRI
+
+		rr := signedRequest(svc)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Contains(t, rr.Body.String(), "failed to sign did resolution result")
+	})
+}
+
+func TestResolveDIDHandler_ETag(t *testing.T) {
+	doc := &did.Doc{ID: "did:trustbloc:testnet:123", Context: []string{"https://w3id.org/did/v1"},
+		Service: []did.Service{{ID: "#agent", Type: "did-communication", ServiceEndpoint: "https://agent.one"},
+			{ID: "#hub", Type: "hub", ServiceEndpoint: "https://hub.one"}}}
+
+	svc := New(&Config{})
+	svc.blocVDRI = &mockvdri.MockVDRI{
+		ReadFunc: func(didID string, opts ...vdri.ResolveOpts) (*did.Doc, error) {
+			return doc, nil
+		}}
+
+	handler := handlerLookup(t, svc, resolveDIDEndpoint)
+
+	resolve := func(ifNoneMatch, accept string) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+		req, err := http.NewRequest(http.MethodGet, resolveDIDEndpoint+"?did=did:trustbloc:testnet:123", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		req.Header.Set("Accept", accept)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	rr := resolve("", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, "Accept", rr.Header().Get("Vary"))
+
+	t.Run("unchanged doc", func(t *testing.T) {
+		for _, ifNoneMatch := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+			rr = resolve(ifNoneMatch, "")
+			require.Equal(t, http.StatusNotModified, rr.Code)
+			require.Empty(t, rr.Body.String())
+			require.Equal(t, etag, rr.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("etag doesn't depend on the order of the doc sets", func(t *testing.T) {
+		doc.Service[0], doc.Service[1] = doc.Service[1], doc.Service[0]
+
+		rr = resolve(etag, "")
+		require.Equal(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("changed doc", func(t *testing.T) {
+		doc.Service[0].ServiceEndpoint = "https://hub.two"
+
+		rr = resolve(etag, "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NotEqual(t, etag, rr.Header().Get("ETag"))
+		require.Contains(t, rr.Body.String(), "https://hub.two")
+	})
+
+	t.Run("signed results have another etag", func(t *testing.T) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		svc.signingKey = &jose.SigningKey{Algorithm: jose.EdDSA, Key: priv}
+
+		unsignedETag := resolve("", "").Header().Get("ETag")
+
+		rr = resolve(unsignedETag, proxy.SignedResultContentType)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, proxy.SignedResultContentType, rr.Header().Get("Content-type"))
+
+		signedETag := rr.Header().Get("ETag")
+		require.NotEqual(t, unsignedETag, signedETag)
+
+		rr = resolve(signedETag, proxy.SignedResultContentType)
+		require.Equal(t, http.StatusNotModified, rr.Code)
+	})
+}
+
 func handleRequest(handler Handler, path string, body []byte) (*bytes.Buffer, int, error) { //nolint:lll
 	req, err := http.NewRequest(handler.Method(), path, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, 0, err
 	}
 
+	req.Header.Set("Content-Type", jsonContentType)
+
 	router := mux.NewRouter()
 
 	router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"time"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
+)
+
+// UpdateDID sends the sidetree update request of a DID to the sidetree endpoint of its consortium on behalf of the
+// given requester, recording the operation to the audit sink. The request is built and signed by the DID
+// controller, as the service holds no keys of the DIDs it registers.
+func (o *Operation) UpdateDID(requester, didID string, req []byte) error {
+	err := o.didBlocClient.SendUpdateRequest(didID, req)
+
+	if o.auditSink != nil {
+		o.auditOperation(requester, audit.Update, didID, req, err)
+	}
+
+	return err
+}
+
+// DeactivateDID sends the sidetree deactivate request of a DID, built and signed by the DID controller, to the
+// sidetree endpoint of its consortium on behalf of the given requester, recording the operation to the audit sink
+func (o *Operation) DeactivateDID(requester, didID string, req []byte) error {
+	err := o.didBlocClient.SendDeactivateRequest(didID, req)
+
+	if o.auditSink != nil {
+		o.auditOperation(requester, audit.Deactivate, didID, req, err)
+	}
+
+	return err
+}
+
+// auditOperation records an operation sent as a signed sidetree request
+func (o *Operation) auditOperation(requester string, op audit.Operation, didID string, req []byte, opErr error) {
+	record := &audit.Record{Time: time.Now().UTC(), Operation: op, Requester: requester, DID: didID,
+		OperationHash: audit.Hash(req), Success: opErr == nil}
+
+	if opErr != nil {
+		record.Error = opErr.Error()
+	}
+
+	if err := o.auditSink.Write(record); err != nil {
+		o.logger.Errorf("failed to audit %s operation of %s: %s", op, requester, err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/audit"
+	mockaudit "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/audit"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/didbloc"
+)
+
+func TestOperation_UpdateDID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		sink := &mockaudit.Sink{}
+
+		svc := New(&Config{AuditSink: sink})
+		svc.didBlocClient = &didbloc.Client{}
+
+		require.NoError(t, svc.UpdateDID("CN=client", "did:trustbloc:testnet:123", []byte("{}")))
+
+		require.Len(t, sink.Records, 1)
+		require.Equal(t, audit.Update, sink.Records[0].Operation)
+		require.Equal(t, "CN=client", sink.Records[0].Requester)
+		require.Equal(t, "did:trustbloc:testnet:123", sink.Records[0].DID)
+		require.Equal(t, audit.Hash([]byte("{}")), sink.Records[0].OperationHash)
+		require.True(t, sink.Records[0].Success)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		sink := &mockaudit.Sink{}
+
+		svc := New(&Config{AuditSink: sink})
+		svc.didBlocClient = &didbloc.Client{SendUpdateRequestErr: errors.New("update error")}
+
+		require.EqualError(t, svc.UpdateDID("CN=client", "did:trustbloc:testnet:123", []byte("{}")), "update error")
+
+		require.Len(t, sink.Records, 1)
+		require.False(t, sink.Records[0].Success)
+		require.Equal(t, "update error", sink.Records[0].Error)
+	})
+
+	t.Run("audit sink failure doesn't fail the update", func(t *testing.T) {
+		svc := New(&Config{AuditSink: &mockaudit.Sink{WriteErr: errors.New("sink unavailable")}})
+		svc.didBlocClient = &didbloc.Client{}
+
+		require.NoError(t, svc.UpdateDID("CN=client", "did:trustbloc:testnet:123", []byte("{}")))
+	})
+}
+
+func TestOperation_DeactivateDID(t *testing.T) {
+	sink := &mockaudit.Sink{}
+
+	svc := New(&Config{AuditSink: sink})
+	svc.didBlocClient = &didbloc.Client{}
+
+	require.NoError(t, svc.DeactivateDID("CN=client", "did:trustbloc:testnet:123", []byte("{}")))
+	require.Len(t, sink.Records, 1)
+	require.Equal(t, audit.Deactivate, sink.Records[0].Operation)
+
+	svc = New(&Config{})
+	svc.didBlocClient = &didbloc.Client{SendDeactivateRequestErr: errors.New("deactivate error")}
+
+	require.EqualError(t, svc.DeactivateDID("CN=client", "did:trustbloc:testnet:123", []byte("{}")),
+		"deactivate error")
+}
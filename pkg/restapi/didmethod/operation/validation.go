@@ -0,0 +1,203 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	didclient "github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+)
+
+const (
+	jsonContentType        = "application/json"
+	defaultMaxBodySize     = 64 * 1024
+	maxPublicKeys          = 50
+	maxServices            = 50
+	maxIDLength            = 50
+	requestTooLargeErrMsg  = "request body exceeds the maximum size of %d bytes"
+	invalidContentTypeMsg  = "unsupported content type '%s', expected %s"
+	validationFailedErrMsg = "invalid request: validation failed"
+)
+
+// sidetree restricts the IDs of public keys and services to base64url characters
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`) // nolint: gochecknoglobals
+
+// nolint: gochecknoglobals
+var (
+	supportedEncodings = map[string]bool{didclient.PublicKeyEncodingJwk: true,
+		didclient.PublicKeyEncodingMultibase: true}
+	supportedKeyTypes = map[string]bool{didclient.Ed25519KeyType: true, didclient.P256KeyType: true,
+		didclient.P384KeyType: true, didclient.P521KeyType: true, didclient.Bls12381G2KeyType: true,
+		didclient.X25519KeyType: true, didclient.Secp256k1KeyType: true}
+	supportedPurposes = map[string]bool{didclient.KeyPurposeAuth: true, didclient.KeyPurposeAssertion: true,
+		didclient.KeyPurposeDelegation: true, didclient.KeyPurposeInvocation: true, didclient.KeyPurposeGeneral: true,
+		didclient.KeyPurposeAgreement: true}
+)
+
+// limitRequest wraps a handler, refusing requests whose body is larger than the given size with 413, and requests
+// of another content type than the given one, if any, with 415
+func (o *Operation) limitRequest(contentType string, handle http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil || mediaType != contentType {
+				o.writeCodedErrorResponse(rw, http.StatusUnsupportedMediaType, errcode.InvalidRequest,
+					fmt.Sprintf(invalidContentTypeMsg, req.Header.Get("Content-Type"), contentType))
+
+				return
+			}
+		}
+
+		if req.ContentLength > o.maxBodySize {
+			o.writeCodedErrorResponse(rw, http.StatusRequestEntityTooLarge, errcode.InvalidRequest,
+				fmt.Sprintf(requestTooLargeErrMsg, o.maxBodySize))
+
+			return
+		}
+
+		// the content length may not be set, so one byte more than the limit is read to detect larger bodies
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, o.maxBodySize+1))
+		if err != nil {
+			o.writeCodedErrorResponse(rw, http.StatusBadRequest, errcode.InvalidRequest,
+				fmt.Sprintf(invalidRequestErrMsg+": %s", err))
+
+			return
+		}
+
+		if int64(len(body)) > o.maxBodySize {
+			o.writeCodedErrorResponse(rw, http.StatusRequestEntityTooLarge, errcode.InvalidRequest,
+				fmt.Sprintf(requestTooLargeErrMsg, o.maxBodySize))
+
+			return
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		handle(rw, req)
+	}
+}
+
+// validate checks the register request against the registrar's schema, returning an error per invalid field
+func (r *RegisterDIDRequest) validate() []FieldError {
+	var errs []FieldError
+
+	addErr := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case len(r.DIDDocument.PublicKey) == 0:
+		addErr("didDocument.publicKey", "at least one public key is required")
+	case len(r.DIDDocument.PublicKey) > maxPublicKeys:
+		addErr("didDocument.publicKey", "at most %d public keys are allowed", maxPublicKeys)
+	}
+
+	if len(r.DIDDocument.Service) > maxServices {
+		addErr("didDocument.service", "at most %d services are allowed", maxServices)
+	}
+
+	ids := map[string]bool{}
+
+	for i, pk := range r.DIDDocument.PublicKey {
+		field := fmt.Sprintf("didDocument.publicKey[%d]", i)
+
+		if pk == nil {
+			addErr(field, "public key is required")
+
+			continue
+		}
+
+		errs = append(errs, validateID(field+".id", pk.ID, ids)...)
+		errs = append(errs, pk.validate(field)...)
+	}
+
+	for i, service := range r.DIDDocument.Service {
+		field := fmt.Sprintf("didDocument.service[%d]", i)
+
+		if service == nil {
+			addErr(field, "service is required")
+
+			continue
+		}
+
+		errs = append(errs, validateID(field+".id", service.ID, ids)...)
+		errs = append(errs, service.validate(field)...)
+	}
+
+	return errs
+}
+
+// validateID checks that the id of a public key or service is well formed and unique
+func validateID(field, id string, ids map[string]bool) []FieldError {
+	switch {
+	case id == "":
+		return []FieldError{{Field: field, Message: "id is required"}}
+	case len(id) > maxIDLength:
+		return []FieldError{{Field: field, Message: fmt.Sprintf("id must be at most %d characters", maxIDLength)}}
+	case !idPattern.MatchString(id):
+		return []FieldError{{Field: field, Message: "id must only contain base64url characters"}}
+	case ids[id]:
+		return []FieldError{{Field: field, Message: fmt.Sprintf("duplicate id '%s'", id)}}
+	}
+
+	ids[id] = true
+
+	return nil
+}
+
+func (pk *PublicKey) validate(field string) []FieldError {
+	var errs []FieldError
+
+	if pk.Type == "" {
+		errs = append(errs, FieldError{Field: field + ".type", Message: "type is required"})
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(pk.Value); err != nil || pk.Value == "" {
+		errs = append(errs, FieldError{Field: field + ".value", Message: "value must be base64 encoded"})
+	}
+
+	if pk.Encoding != "" && !supportedEncodings[pk.Encoding] {
+		errs = append(errs, FieldError{Field: field + ".encoding",
+			Message: fmt.Sprintf("unsupported encoding '%s'", pk.Encoding)})
+	}
+
+	if pk.KeyType != "" && !supportedKeyTypes[pk.KeyType] {
+		errs = append(errs, FieldError{Field: field + ".keyType",
+			Message: fmt.Sprintf("unsupported key type '%s'", pk.KeyType)})
+	}
+
+	for i, purpose := range pk.Purpose {
+		if !supportedPurposes[purpose] {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("%s.purpose[%d]", field, i),
+				Message: fmt.Sprintf("unsupported purpose '%s'", purpose)})
+		}
+	}
+
+	return errs
+}
+
+func (s *Service) validate(field string) []FieldError {
+	var errs []FieldError
+
+	if s.Type == "" {
+		errs = append(errs, FieldError{Field: field + ".type", Message: "type is required"})
+	}
+
+	if u, err := url.Parse(s.Endpoint); err != nil || !u.IsAbs() {
+		errs = append(errs, FieldError{Field: field + ".endpoint", Message: "endpoint must be an absolute url"})
+	}
+
+	return errs
+}
@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestLimitRequest(t *testing.T) {
+	svc := New(&Config{MaxRequestBodySize: 10})
+
+	handle := svc.limitRequest(jsonContentType, func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		_, err = rw.Write(body)
+		require.NoError(t, err)
+	})
+
+	serve := func(contentType string, body []byte, contentLength int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, registerPath, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.ContentLength = contentLength
+
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+
+		return rr
+	}
+
+	t.Run("success", func(t *testing.T) {
+		rr := serve("application/json; charset=utf-8", []byte(`{"a":"b"}`), 9)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `{"a":"b"}`, rr.Body.String())
+	})
+
+	t.Run("failure - unsupported content type", func(t *testing.T) {
+		rr := serve("text/plain", []byte(`{}`), 2)
+		require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+		require.Contains(t, rr.Body.String(), "unsupported content type 'text/plain'")
+
+		rr = serve("", []byte(`{}`), 2)
+		require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	})
+
+	t.Run("failure - content length too large", func(t *testing.T) {
+		rr := serve(jsonContentType, []byte(`{"a":"bcdef"}`), 13)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		require.Contains(t, rr.Body.String(), "request body exceeds the maximum size of 10 bytes")
+	})
+
+	t.Run("failure - body too large without content length", func(t *testing.T) {
+		rr := serve(jsonContentType, []byte(`{"a":"bcdef"}`), -1)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("failure - body not read", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, registerPath, &failingReader{})
+		req.Header.Set("Content-Type", jsonContentType)
+
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "read failed")
+	})
+}
+
+func TestRegisterDIDHandler_Validation(t *testing.T) {
+	handler := getHandler(t, nil, nil, registerPath)
+
+	t.Run("unknown fields", func(t *testing.T) {
+		body, status, err := handleRequest(handler, registerPath, []byte(`{"didDocument":{"publicKeys":[]}}`))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body.String(), `unknown field "publicKeys"`)
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		body, status, err := handleRequest(handler, registerPath, []byte(`{"didDocument":{"publicKey":[{}]}}`))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, body.String(), `{"field":"didDocument.publicKey[0].id","message":"id is required"}`)
+	})
+}
+
+func TestRegisterDIDRequest_Validate(t *testing.T) {
+	value := base64.StdEncoding.EncodeToString([]byte("value"))
+
+	validKey := func() *PublicKey {
+		return &PublicKey{ID: "key1", Type: "JwsVerificationKey2020", Value: value, Encoding: "Jwk",
+			KeyType: "Ed25519", Purpose: []string{"general", "auth"}}
+	}
+
+	validService := func() *Service {
+		return &Service{ID: "agent", Type: "did-communication", Endpoint: "https://agent.example.com"}
+	}
+
+	t.Run("valid request", func(t *testing.T) {
+		r := &RegisterDIDRequest{DIDDocument: DIDDocument{PublicKey: []*PublicKey{validKey()},
+			Service: []*Service{validService()}}}
+		require.Empty(t, r.validate())
+	})
+
+	t.Run("valid key agreement key", func(t *testing.T) {
+		r := &RegisterDIDRequest{DIDDocument: DIDDocument{PublicKey: []*PublicKey{{ID: "key1",
+			Type: "X25519KeyAgreementKey2019", Value: value, Encoding: "Jwk", KeyType: "X25519",
+			Purpose: []string{"agreement"}}}}}
+		require.Empty(t, r.validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(doc *DIDDocument)
+		errs   []FieldError
+	}{
+		{
+			name: "too many public keys",
+			modify: func(doc *DIDDocument) {
+				for i := 0; i < maxPublicKeys; i++ {
+					key := validKey()
+					key.ID = fmt.Sprintf("key%d", i+2)
+					doc.PublicKey = append(doc.PublicKey, key)
+				}
+			},
+			errs: []FieldError{{Field: "didDocument.publicKey", Message: "at most 50 public keys are allowed"}},
+		},
+		{
+			name: "too many services",
+			modify: func(doc *DIDDocument) {
+				for i := 0; i < maxServices; i++ {
+					service := validService()
+					service.ID = fmt.Sprintf("agent%d", i+2)
+					doc.Service = append(doc.Service, service)
+				}
+			},
+			errs: []FieldError{{Field: "didDocument.service", Message: "at most 50 services are allowed"}},
+		},
+		{
+			name: "missing public key and service",
+			modify: func(doc *DIDDocument) {
+				doc.PublicKey = append(doc.PublicKey, nil)
+				doc.Service = append(doc.Service, nil)
+			},
+			errs: []FieldError{{Field: "didDocument.publicKey[1]", Message: "public key is required"},
+				{Field: "didDocument.service[1]", Message: "service is required"}},
+		},
+		{
+			name: "invalid ids",
+			modify: func(doc *DIDDocument) {
+				doc.PublicKey[0].ID = "#key1"
+				doc.Service[0].ID = strings.Repeat("a", maxIDLength+1)
+			},
+			errs: []FieldError{{Field: "didDocument.publicKey[0].id", Message: "id must only contain base64url characters"},
+				{Field: "didDocument.service[0].id", Message: "id must be at most 50 characters"}},
+		},
+		{
+			name: "duplicate ids",
+			modify: func(doc *DIDDocument) {
+				doc.Service[0].ID = doc.PublicKey[0].ID
+			},
+			errs: []FieldError{{Field: "didDocument.service[0].id", Message: "duplicate id 'key1'"}},
+		},
+		{
+			name: "invalid public key",
+			modify: func(doc *DIDDocument) {
+				doc.PublicKey[0] = &PublicKey{ID: "key1", Value: "%", Encoding: "Base58", KeyType: "RSA",
+					Purpose: []string{"general", "signing"}}
+			},
+			errs: []FieldError{
+				{Field: "didDocument.publicKey[0].type", Message: "type is required"},
+				{Field: "didDocument.publicKey[0].value", Message: "value must be base64 encoded"},
+				{Field: "didDocument.publicKey[0].encoding", Message: "unsupported encoding 'Base58'"},
+				{Field: "didDocument.publicKey[0].keyType", Message: "unsupported key type 'RSA'"},
+				{Field: "didDocument.publicKey[0].purpose[1]", Message: "unsupported purpose 'signing'"},
+			},
+		},
+		{
+			name: "invalid service",
+			modify: func(doc *DIDDocument) {
+				doc.Service[0] = &Service{ID: "agent", Endpoint: "agent.example.com"}
+			},
+			errs: []FieldError{
+				{Field: "didDocument.service[0].type", Message: "type is required"},
+				{Field: "didDocument.service[0].endpoint", Message: "endpoint must be an absolute url"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			r := &RegisterDIDRequest{DIDDocument: DIDDocument{PublicKey: []*PublicKey{validKey()},
+				Service: []*Service{validService()}}}
+			tc.modify(&r.DIDDocument)
+
+			require.Equal(t, tc.errs, r.validate())
+		})
+	}
+}
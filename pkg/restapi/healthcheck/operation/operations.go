@@ -10,9 +10,8 @@ import (
 	"net/http"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/support"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
 )
 
 // API endpoints.
@@ -33,12 +32,31 @@ type Handler interface {
 }
 
 // New returns CreateCredential instance.
-func New() *Operation {
-	return &Operation{}
+func New(opts ...Option) *Operation {
+	o := &Operation{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	o.logger = log.OrDefault(o.logger)
+
+	return o
 }
 
 // Operation defines handlers for rp operations.
 type Operation struct {
+	logger log.Logger
+}
+
+// Option is a health check operation option
+type Option func(opts *Operation)
+
+// WithLogger option sets the logger of the health check. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *Operation) {
+		opts.logger = logger
+	}
 }
 
 // GetRESTHandlers get all controller API handler available for this service.
@@ -56,6 +74,6 @@ func (o *Operation) healthCheckHandler(rw http.ResponseWriter, r *http.Request)
 		CurrentTime: time.Now(),
 	})
 	if err != nil {
-		log.Errorf("healthcheck response failure, %s", err)
+		o.logger.Errorf("healthcheck response failure, %s", err)
 	}
 }
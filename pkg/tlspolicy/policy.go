@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tlspolicy enforces a minimum TLS version and a list of modern cipher suites on TLS configs. The trust
+// model of the method relies on transport security, so every outbound connection and the REST server use a policy.
+package tlspolicy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMinVersion is the default minimum TLS version, TLS 1.2
+const DefaultMinVersion = tls.VersionTLS12
+
+// cipherSuites are the modern TLS 1.2 cipher suites a policy may allow: ECDHE key exchange with AEAD ciphers.
+// TLS 1.3 cipher suites aren't configurable, all of them are secure.
+// nolint: gochecknoglobals
+var cipherSuites = map[string]uint16{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256":       tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384":       tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256": tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256":   tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// nolint: gochecknoglobals
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// DefaultCipherSuites returns the cipher suites allowed by default, all the modern TLS 1.2 cipher suites
+func DefaultCipherSuites() []uint16 {
+	return []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	}
+}
+
+// Policy is a minimum TLS version and the TLS 1.2 cipher suites allowed
+type Policy struct {
+	MinVersion   uint16
+	CipherSuites []uint16
+}
+
+// Default returns the default policy: TLS 1.2 or later with the modern cipher suites
+func Default() *Policy {
+	return &Policy{MinVersion: DefaultMinVersion, CipherSuites: DefaultCipherSuites()}
+}
+
+// New creates a policy from a minimum TLS version ("1.2" or "1.3") and the names of the cipher suites allowed, eg.
+// TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256. The defaults are used for an empty version or an empty list of suites.
+func New(minVersion string, cipherSuiteNames []string) (*Policy, error) {
+	p := Default()
+
+	if minVersion != "" {
+		v, err := ParseVersion(minVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		p.MinVersion = v
+	}
+
+	if len(cipherSuiteNames) > 0 {
+		suites, err := ParseCipherSuites(cipherSuiteNames)
+		if err != nil {
+			return nil, err
+		}
+
+		p.CipherSuites = suites
+	}
+
+	return p, nil
+}
+
+// ParseVersion parses a TLS version, "1.2" or "1.3". Older versions are refused.
+func ParseVersion(version string) (uint16, error) {
+	v, ok := versions[strings.TrimPrefix(strings.TrimSpace(version), "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %s: must be one of %s", version, names(versions))
+	}
+
+	return v, nil
+}
+
+// ParseCipherSuites parses the names of TLS 1.2 cipher suites. Suites without forward secrecy or an AEAD cipher
+// are refused.
+func ParseCipherSuites(cipherSuiteNames []string) ([]uint16, error) {
+	suites := make([]uint16, 0, len(cipherSuiteNames))
+
+	for _, name := range cipherSuiteNames {
+		suite, ok := cipherSuites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS cipher suite %s: must be one of %s", name, names(cipherSuites))
+		}
+
+		suites = append(suites, suite)
+	}
+
+	return suites, nil
+}
+
+// Validate checks that the policy allows neither versions older than TLS 1.2 nor insecure cipher suites
+func (p *Policy) Validate() error {
+	if p.MinVersion < DefaultMinVersion {
+		return fmt.Errorf("minimum TLS version %#04x is older than TLS 1.2", p.MinVersion)
+	}
+
+	for _, suite := range p.CipherSuites {
+		if !allowed(suite) {
+			return fmt.Errorf("TLS cipher suite %#04x isn't allowed", suite)
+		}
+	}
+
+	return nil
+}
+
+// Apply returns a copy of the given TLS config complying with the policy. The minimum version of the config is raised
+// to the minimum version of the policy, and cipher suites not allowed by the policy are removed. The cipher suites of
+// the policy are used if none remain. A nil config gives a config with the policy settings only.
+func (p *Policy) Apply(tlsConfig *tls.Config) *tls.Config {
+	var c *tls.Config
+
+	if tlsConfig == nil {
+		c = &tls.Config{} //nolint: gosec
+	} else {
+		c = tlsConfig.Clone()
+	}
+
+	if c.MinVersion < p.MinVersion {
+		c.MinVersion = p.MinVersion
+	}
+
+	var suites []uint16
+
+	for _, suite := range c.CipherSuites {
+		if contains(p.CipherSuites, suite) {
+			suites = append(suites, suite)
+		}
+	}
+
+	if len(suites) == 0 {
+		suites = append(suites, p.CipherSuites...)
+	}
+
+	c.CipherSuites = suites
+
+	return c
+}
+
+// Apply returns a copy of the given TLS config complying with the default policy, see Policy.Apply
+func Apply(tlsConfig *tls.Config) *tls.Config {
+	return Default().Apply(tlsConfig)
+}
+
+func allowed(suite uint16) bool {
+	for _, s := range cipherSuites {
+		if s == suite {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(suites []uint16, suite uint16) bool {
+	for _, s := range suites {
+		if s == suite {
+			return true
+		}
+	}
+
+	return false
+}
+
+func names(m map[string]uint16) string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return strings.Join(keys, ", ")
+}
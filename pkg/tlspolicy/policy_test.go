@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tlspolicy
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("success - defaults", func(t *testing.T) {
+		p, err := New("", nil)
+		require.NoError(t, err)
+		require.Equal(t, Default(), p)
+		require.NoError(t, p.Validate())
+	})
+
+	t.Run("success - TLS 1.3 with one cipher suite", func(t *testing.T) {
+		p, err := New("1.3", []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"})
+		require.NoError(t, err)
+		require.Equal(t, uint16(tls.VersionTLS13), p.MinVersion)
+		require.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, p.CipherSuites)
+		require.NoError(t, p.Validate())
+	})
+
+	t.Run("failure - TLS 1.1", func(t *testing.T) {
+		_, err := New("1.1", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported TLS version 1.1")
+	})
+
+	t.Run("failure - insecure cipher suite", func(t *testing.T) {
+		_, err := New("1.2", []string{"TLS_RSA_WITH_AES_128_CBC_SHA"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported TLS cipher suite TLS_RSA_WITH_AES_128_CBC_SHA")
+	})
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Run("failure - old version", func(t *testing.T) {
+		err := (&Policy{MinVersion: tls.VersionTLS11}).Validate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is older than TLS 1.2")
+	})
+
+	t.Run("failure - insecure cipher suite", func(t *testing.T) {
+		err := (&Policy{MinVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}}).Validate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isn't allowed")
+	})
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		c := Apply(nil)
+		require.Equal(t, uint16(tls.VersionTLS12), c.MinVersion)
+		require.Equal(t, DefaultCipherSuites(), c.CipherSuites)
+	})
+
+	t.Run("config is copied and hardened", func(t *testing.T) {
+		original := &tls.Config{MinVersion: tls.VersionTLS10, ServerName: "example.com", // nolint: gosec
+			CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}}
+
+		c := Apply(original)
+		require.Equal(t, uint16(tls.VersionTLS12), c.MinVersion)
+		require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, c.CipherSuites)
+		require.Equal(t, "example.com", c.ServerName)
+		require.Equal(t, uint16(tls.VersionTLS10), original.MinVersion)
+	})
+
+	t.Run("higher minimum version is kept", func(t *testing.T) {
+		c := Apply(&tls.Config{MinVersion: tls.VersionTLS13})
+		require.Equal(t, uint16(tls.VersionTLS13), c.MinVersion)
+	})
+}
@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package didcomm resolves DIDs by sending resolve requests over DIDComm to resolver agents, for ecosystems
+// where resolvers are agents rather than plain HTTP servers.
+package didcomm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	httptransport "github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	// ResolveRequestType is the message type of DID resolve requests
+	ResolveRequestType = "https://trustbloc.dev/did-resolution/1.0/resolve"
+	// ResolveResponseType is the message type of DID resolve responses
+	ResolveResponseType = "https://trustbloc.dev/did-resolution/1.0/resolve-response"
+
+	forwardType = "https://didcomm.org/routing/1.0/forward"
+)
+
+// ResolveRequest asks a resolver agent to resolve a DID
+type ResolveRequest struct {
+	ID   string `json:"@id"`
+	Type string `json:"@type"`
+	DID  string `json:"did"`
+}
+
+// ResolveResponse holds the DID resolution result of a resolve request, or the reason the resolution failed
+type ResolveResponse struct {
+	ID            string          `json:"@id"`
+	Type          string          `json:"@type"`
+	Thread        *Thread         `json:"~thread,omitempty"`
+	DIDResolution json.RawMessage `json:"didResolution,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+// Thread decorator, correlating a response with its request
+type Thread struct {
+	ID string `json:"thid"`
+}
+
+type forward struct {
+	ID   string          `json:"@id"`
+	Type string          `json:"@type"`
+	To   string          `json:"to"`
+	Msg  json.RawMessage `json:"msg"`
+}
+
+// Resolver sends DID resolve requests to resolver agents, expecting the response on the return route
+// of the outbound transport (eg. the response body of the http transport). Only responses authenticated
+// (authcrypt) with a recipient key of the resolver agent are accepted.
+type Resolver struct {
+	packager      commontransport.Packager
+	outbound      transport.OutboundTransport
+	senderKey     []byte
+	allowInsecure []string
+}
+
+// New creates a DIDComm resolver packing messages with the given packager, and sending them with the given
+// outbound transport. Both are typically provided by the context of an aries agent.
+func New(packager commontransport.Packager, outbound transport.OutboundTransport, opts ...Option) *Resolver {
+	r := &Resolver{packager: packager, outbound: outbound}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolve resolves a DID by sending a resolve request to the agent with the given DID doc, which must have
+// a did-communication service
+func (r *Resolver) Resolve(did string, agentDoc *docdid.Doc) (*docdid.Doc, error) {
+	dest, err := service.CreateDestination(agentDoc)
+	if err != nil {
+		return nil, fmt.Errorf("resolver agent %s: %w", agentDoc.ID, err)
+	}
+
+	if err = httptransport.CheckHTTPS(dest.ServiceEndpoint, r.allowInsecure); err != nil {
+		return nil, fmt.Errorf("resolver agent %s: %w", agentDoc.ID, err)
+	}
+
+	req := &ResolveRequest{ID: uuid.New().String(), Type: ResolveRequestType, DID: did}
+
+	msg, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resolve request: %w", err)
+	}
+
+	packed, err := r.pack(msg, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := r.outbound.Send(packed, dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send resolve request to %s: %w", dest.ServiceEndpoint, err)
+	}
+
+	if reply == "" {
+		return nil, fmt.Errorf("resolver agent %s didn't reply on the return route", agentDoc.ID)
+	}
+
+	envelope, err := r.packager.UnpackMessage([]byte(reply))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack resolve response: %w", err)
+	}
+
+	if !isRecipientKey(envelope.FromKey, dest.RecipientKeys) {
+		return nil, fmt.Errorf("resolve response isn't authenticated by resolver agent %s", agentDoc.ID)
+	}
+
+	return parseResponse(envelope.Message, req)
+}
+
+// isRecipientKey returns whether the key, as unpacked from an authcrypt message, is one of the base58 encoded
+// recipient keys. An anoncrypt message has no sender key.
+func isRecipientKey(key []byte, recipientKeys []string) bool {
+	if len(key) == 0 {
+		return false
+	}
+
+	encoded := base58.Encode(key)
+
+	for _, recipientKey := range recipientKeys {
+		if recipientKey == encoded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pack packs a message for the recipient, wrapping it in a forward message for the mediator if the
+// destination has routing keys
+func (r *Resolver) pack(msg []byte, dest *service.Destination) ([]byte, error) {
+	packed, err := r.packager.PackMessage(&commontransport.Envelope{
+		Message: msg,
+		FromKey: r.senderKey,
+		ToKeys:  dest.RecipientKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack resolve request: %w", err)
+	}
+
+	if len(dest.RoutingKeys) == 0 {
+		return packed, nil
+	}
+
+	fwd, err := json.Marshal(&forward{
+		ID:   uuid.New().String(),
+		Type: forwardType,
+		To:   dest.RecipientKeys[0],
+		Msg:  packed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal forward message: %w", err)
+	}
+
+	packed, err = r.packager.PackMessage(&commontransport.Envelope{
+		Message: fwd,
+		FromKey: r.senderKey,
+		ToKeys:  dest.RoutingKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack forward message: %w", err)
+	}
+
+	return packed, nil
+}
+
+func parseResponse(msg []byte, req *ResolveRequest) (*docdid.Doc, error) {
+	var resp ResolveResponse
+
+	if err := json.Unmarshal(msg, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse resolve response: %w", err)
+	}
+
+	if resp.Type != ResolveResponseType {
+		return nil, fmt.Errorf("unexpected message type %s in reply to resolve request", resp.Type)
+	}
+
+	if resp.Thread == nil || resp.Thread.ID != req.ID {
+		return nil, errors.New("resolve response doesn't reply to the resolve request")
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("resolver agent failed to resolve %s: %s", req.DID, resp.Error)
+	}
+
+	var result models.DIDResolutionResult
+
+	if err := json.Unmarshal(resp.DIDResolution, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse did resolution result: %w", err)
+	}
+
+	doc, err := docdid.ParseDocument(result.DIDDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse did document: %w", err)
+	}
+
+	if doc.ID != req.DID {
+		return nil, fmt.Errorf("resolved did document id %s doesn't match did %s", doc.ID, req.DID)
+	}
+
+	return doc, nil
+}
+
+// Option configures the DIDComm resolver
+type Option func(opts *Resolver)
+
+// WithSenderKey sets the verification key of the resolving agent, so resolve requests are authenticated
+// (authcrypt). Requests are sent anonymously (anoncrypt) if no sender key is set.
+func WithSenderKey(key []byte) Option {
+	return func(opts *Resolver) {
+		opts.senderKey = key
+	}
+}
+
+// WithAllowInsecureHTTP option allows resolve requests to be sent over plain http to the agents at the given hosts
+// (hostname or host:port), eg. for local development. By default only https endpoints are used.
+func WithAllowInsecureHTTP(hosts ...string) Option {
+	return func(opts *Resolver) {
+		opts.allowInsecure = hosts
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcomm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const testDID = "did:trustbloc:testnet:123"
+
+// the base58 encoded verification key of the resolver agent
+var recipientKey = base58.Encode([]byte("recipientKey")) // nolint: gochecknoglobals
+
+// mockPackager "packs" messages as plain json envelopes
+type mockPackager struct {
+	packErr error
+}
+
+type mockEnvelope struct {
+	FromKey []byte          `json:"from"`
+	ToKeys  []string        `json:"to"`
+	Message json.RawMessage `json:"msg"`
+}
+
+func (p *mockPackager) PackMessage(envelope *commontransport.Envelope) ([]byte, error) {
+	if p.packErr != nil {
+		return nil, p.packErr
+	}
+
+	return json.Marshal(&mockEnvelope{FromKey: envelope.FromKey, ToKeys: envelope.ToKeys, Message: envelope.Message})
+}
+
+func (p *mockPackager) UnpackMessage(encMessage []byte) (*commontransport.Envelope, error) {
+	var env mockEnvelope
+	if err := json.Unmarshal(encMessage, &env); err != nil {
+		return nil, err
+	}
+
+	return &commontransport.Envelope{FromKey: env.FromKey, ToKeys: env.ToKeys, Message: env.Message}, nil
+}
+
+// mockAgent replies to resolve requests on the return route, authenticated with its first recipient key unless
+// another reply key is set
+type mockAgent struct {
+	transport.OutboundTransport
+	reply    func(req *ResolveRequest) *ResolveResponse
+	replyKey []byte
+	sendErr  error
+	sent     []*mockEnvelope
+}
+
+func (a *mockAgent) Send(data []byte, dest *service.Destination) (string, error) {
+	if a.sendErr != nil {
+		return "", a.sendErr
+	}
+
+	env := &mockEnvelope{}
+	if err := json.Unmarshal(data, env); err != nil {
+		return "", err
+	}
+
+	a.sent = append(a.sent, env)
+
+	msg := env.Message
+	if len(dest.RoutingKeys) > 0 {
+		var fwd forward
+		if err := json.Unmarshal(msg, &fwd); err != nil {
+			return "", err
+		}
+
+		inner := &mockEnvelope{}
+		if err := json.Unmarshal(fwd.Msg, inner); err != nil {
+			return "", err
+		}
+
+		msg = inner.Message
+	}
+
+	var req ResolveRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return "", err
+	}
+
+	if a.reply == nil {
+		return "", nil
+	}
+
+	respMsg, err := json.Marshal(a.reply(&req))
+	if err != nil {
+		return "", err
+	}
+
+	replyKey := a.replyKey
+	if replyKey == nil {
+		replyKey = base58.Decode(dest.RecipientKeys[0])
+	}
+
+	resp, err := json.Marshal(&mockEnvelope{FromKey: replyKey, Message: respMsg})
+
+	return string(resp), err
+}
+
+func resolved(t *testing.T, didID string) func(req *ResolveRequest) *ResolveResponse {
+	result, err := models.MakeDIDResolutionResult(&docdid.Doc{Context: []string{"https://w3id.org/did/v1"},
+		ID: didID})
+	require.NoError(t, err)
+
+	return func(req *ResolveRequest) *ResolveResponse {
+		return &ResolveResponse{ID: "reply", Type: ResolveResponseType, Thread: &Thread{ID: req.ID},
+			DIDResolution: result}
+	}
+}
+
+func agentDoc(routingKeys ...string) *docdid.Doc {
+	return agentDocAt("https://agent.example.com", routingKeys...)
+}
+
+func agentDocAt(endpoint string, routingKeys ...string) *docdid.Doc {
+	return &docdid.Doc{
+		ID: "did:example:agent",
+		Service: []docdid.Service{{
+			ID:              "did:example:agent#didcomm",
+			Type:            "did-communication",
+			ServiceEndpoint: endpoint,
+			RecipientKeys:   []string{recipientKey},
+			RoutingKeys:     routingKeys,
+		}},
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID)}
+
+		r := New(&mockPackager{}, agent, WithSenderKey([]byte("senderKey")))
+
+		doc, err := r.Resolve(testDID, agentDoc())
+		require.NoError(t, err)
+		require.Equal(t, testDID, doc.ID)
+
+		require.Len(t, agent.sent, 1)
+		require.Equal(t, []string{recipientKey}, agent.sent[0].ToKeys)
+		require.Equal(t, []byte("senderKey"), agent.sent[0].FromKey)
+	})
+
+	t.Run("success - forwarded through mediator", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID)}
+
+		doc, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc("routingKey"))
+		require.NoError(t, err)
+		require.Equal(t, testDID, doc.ID)
+
+		require.Len(t, agent.sent, 1)
+		require.Equal(t, []string{"routingKey"}, agent.sent[0].ToKeys)
+	})
+
+	t.Run("success - insecure http allowed", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID)}
+
+		doc, err := New(&mockPackager{}, agent, WithAllowInsecureHTTP("localhost")).
+			Resolve(testDID, agentDocAt("http://localhost:8080"))
+		require.NoError(t, err)
+		require.Equal(t, testDID, doc.ID)
+	})
+
+	t.Run("failure - insecure http", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID)}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDocAt("http://agent.example.com"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only https is allowed")
+		require.Empty(t, agent.sent)
+	})
+
+	t.Run("failure - anonymous reply", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID), replyKey: []byte{}}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve response isn't authenticated by resolver agent did:example:agent")
+	})
+
+	t.Run("failure - reply authenticated by another key", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, testDID), replyKey: []byte("otherKey")}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve response isn't authenticated")
+	})
+
+	t.Run("failure - agent has no didcomm service", func(t *testing.T) {
+		_, err := New(&mockPackager{}, &mockAgent{}).Resolve(testDID, &docdid.Doc{ID: "did:example:agent"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing DID doc service")
+	})
+
+	t.Run("failure - pack error", func(t *testing.T) {
+		_, err := New(&mockPackager{packErr: errors.New("pack error")}, &mockAgent{}).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to pack resolve request: pack error")
+	})
+
+	t.Run("failure - send error", func(t *testing.T) {
+		_, err := New(&mockPackager{}, &mockAgent{sendErr: errors.New("send error")}).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send resolve request to https://agent.example.com: send error")
+	})
+
+	t.Run("failure - no reply", func(t *testing.T) {
+		_, err := New(&mockPackager{}, &mockAgent{}).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "didn't reply on the return route")
+	})
+
+	t.Run("failure - resolution error", func(t *testing.T) {
+		agent := &mockAgent{reply: func(req *ResolveRequest) *ResolveResponse {
+			return &ResolveResponse{Type: ResolveResponseType, Thread: &Thread{ID: req.ID}, Error: "not found"}
+		}}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolver agent failed to resolve "+testDID+": not found")
+	})
+
+	t.Run("failure - reply to another request", func(t *testing.T) {
+		agent := &mockAgent{reply: func(req *ResolveRequest) *ResolveResponse {
+			return &ResolveResponse{Type: ResolveResponseType, Thread: &Thread{ID: "other"}}
+		}}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't reply to the resolve request")
+	})
+
+	t.Run("failure - unexpected message type", func(t *testing.T) {
+		agent := &mockAgent{reply: func(req *ResolveRequest) *ResolveResponse {
+			return &ResolveResponse{Type: "other", Thread: &Thread{ID: req.ID}}
+		}}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected message type other")
+	})
+
+	t.Run("failure - resolved doc of another did", func(t *testing.T) {
+		agent := &mockAgent{reply: resolved(t, "did:trustbloc:testnet:456")}
+
+		_, err := New(&mockPackager{}, agent).Resolve(testDID, agentDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match did "+testDID)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proxy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	trustblocDIDMethod = "trustbloc"
+	resolveDIDPath     = "/resolveDID"
+
+	// SignedResultContentType is the content type of the signed DID resolution results served by a resolver proxy
+	SignedResultContentType = "application/jose"
+)
+
+// VDRI resolves did:trustbloc DIDs with a resolver proxy, which validates consortiums and resolves DIDs server-side.
+// The proxy signs the results it serves, which are verified with its pinned public key, so lightweight clients
+// only need the proxy's URL and key.
+type VDRI struct {
+	proxyURL        string
+	key             interface{}
+	algs            []jose.SignatureAlgorithm
+	httpClient      *http.Client
+	tlsConfig       *tls.Config
+	transport       http.RoundTripper
+	maxResponseSize int64
+}
+
+// New creates a vdri resolving DIDs with the resolver proxy at the given url, verifying its results with the
+// proxy's public key
+func New(proxyURL string, key interface{}, opts ...Option) *VDRI {
+	v := &VDRI{proxyURL: proxyURL, key: key, httpClient: &http.Client{},
+		maxResponseSize: transport.DefaultMaxResponseSize}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.transport == nil {
+		v.transport = &http.Transport{TLSClientConfig: tlspolicy.Apply(v.tlsConfig)}
+	}
+
+	v.httpClient.Transport = v.transport
+
+	return v
+}
+
+// Accept did method
+func (v *VDRI) Accept(method string) bool {
+	return method == trustblocDIDMethod
+}
+
+// Close vdri
+func (v *VDRI) Close() error {
+	return nil
+}
+
+// Store did doc
+func (v *VDRI) Store(doc *docdid.Doc, by *[]vdriapi.ModifiedBy) error {
+	return nil
+}
+
+// Build did doc
+func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	return nil, fmt.Errorf("build method not supported for did proxy")
+}
+
+// Read resolves a DID with the resolver proxy, verifying the signature of the result
+func (v *VDRI) Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	req, err := http.NewRequest(http.MethodGet, v.proxyURL+resolveDIDPath+"?did="+url.QueryEscape(did), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", SignedResultContentType)
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve did with proxy %s: %w", v.proxyURL, err)
+	}
+
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	body, err := transport.ReadLimited(resp.Body, v.maxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of proxy %s: %w", v.proxyURL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to resolve did with proxy %s: status '%d' body %s",
+			v.proxyURL, resp.StatusCode, body)
+	}
+
+	doc, err := models.VerifyDIDResolutionResult(body, v.key, v.algs...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result of proxy %s: %w", v.proxyURL, err)
+	}
+
+	if doc.ID != did {
+		return nil, fmt.Errorf("did document id %s doesn't match did %s", doc.ID, did)
+	}
+
+	return doc, nil
+}
+
+// Option configures the proxy vdri
+type Option func(opts *VDRI)
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *VDRI) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithTransport option sets the http transport used to reach the proxy. If set, the tls.Config option is ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *VDRI) {
+		opts.transport = transport
+	}
+}
+
+// WithSignatureAlgorithms option sets the JWS algorithms accepted for the proxy's signature of resolution results.
+// Defaults to models.DefaultSignatureAlgorithms.
+func WithSignatureAlgorithms(algs ...jose.SignatureAlgorithm) Option {
+	return func(opts *VDRI) {
+		opts.algs = algs
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size of a resolution result, in bytes. Larger responses are refused
+// without being read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *VDRI) {
+		opts.maxResponseSize = max
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proxy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func proxyServer(t *testing.T, key ed25519.PrivateKey, docID string, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, resolveDIDPath, r.URL.Path)
+		require.Equal(t, SignedResultContentType, r.Header.Get("Accept"))
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+
+			return
+		}
+
+		result, err := models.MakeDIDResolutionResult(&did.Doc{Context: []string{"https://w3id.org/did/v1"},
+			ID: docID})
+		require.NoError(t, err)
+
+		jws, err := models.SignDIDResolutionResult(result, jose.SigningKey{Algorithm: jose.EdDSA, Key: key})
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", SignedResultContentType)
+		_, err = w.Write([]byte(jws))
+		require.NoError(t, err)
+	}))
+}
+
+func TestVDRI_Read(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		srv := proxyServer(t, priv, "did:trustbloc:testnet:123", http.StatusOK)
+		defer srv.Close()
+
+		doc, err := New(srv.URL, pub).Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123", doc.ID)
+	})
+
+	t.Run("failure - result signed with another key", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		srv := proxyServer(t, otherKey, "did:trustbloc:testnet:123", http.StatusOK)
+		defer srv.Close()
+
+		_, err = New(srv.URL, pub).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify did resolution result")
+	})
+
+	t.Run("failure - algorithm not allowed", func(t *testing.T) {
+		srv := proxyServer(t, priv, "did:trustbloc:testnet:123", http.StatusOK)
+		defer srv.Close()
+
+		_, err := New(srv.URL, pub, WithSignatureAlgorithms(jose.ES256)).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature algorithm 'EdDSA' is not allowed")
+	})
+
+	t.Run("failure - doc of another DID", func(t *testing.T) {
+		srv := proxyServer(t, priv, "did:trustbloc:testnet:456", http.StatusOK)
+		defer srv.Close()
+
+		_, err := New(srv.URL, pub).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match did did:trustbloc:testnet:123")
+	})
+
+	t.Run("failure - result too large", func(t *testing.T) {
+		srv := proxyServer(t, priv, "did:trustbloc:testnet:123", http.StatusOK)
+		defer srv.Close()
+
+		_, err := New(srv.URL, pub, WithMaxResponseSize(10)).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "response body too large")
+	})
+
+	t.Run("failure - proxy error", func(t *testing.T) {
+		srv := proxyServer(t, priv, "", http.StatusBadRequest)
+		defer srv.Close()
+
+		_, err := New(srv.URL, pub, WithTLSConfig(nil)).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status '400'")
+	})
+
+	t.Run("failure - proxy unreachable", func(t *testing.T) {
+		_, err := New("http://127.0.0.1:0", pub, WithTransport(&http.Transport{})).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve did with proxy")
+
+		_, err = New("http://[::1", pub).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create request")
+	})
+}
+
+func TestVDRI(t *testing.T) {
+	v := New("https://proxy.example.com", nil)
+
+	require.True(t, v.Accept("trustbloc"))
+	require.False(t, v.Accept("web"))
+	require.NoError(t, v.Close())
+	require.NoError(t, v.Store(nil, nil))
+
+	_, err := v.Build(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,272 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	gojose "github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+)
+
+const (
+	// the master key URI of the default aries KMS
+	defaultMasterKeyURI = "local-lock://default/master/key/"
+
+	ariesServiceID = "didcomm"
+)
+
+// AriesOption configures the registration of did:trustbloc with an aries framework
+type AriesOption func(opts *ariesRegistration)
+
+type ariesRegistration struct {
+	vdriOpts   []Option
+	kmsCreator kms.Creator
+	domain     string
+}
+
+// WithVDRIOptions option sets the options of the registered VDRI, eg. its TLS config or resolver URL
+func WithVDRIOptions(opts ...Option) AriesOption {
+	return func(reg *ariesRegistration) {
+		reg.vdriOpts = append(reg.vdriOpts, opts...)
+	}
+}
+
+// WithCreateDomain option sets the consortium domain that DIDs created with the VDRI registry of the framework
+// are created in. Without it, the registered VDRI only resolves DIDs.
+func WithCreateDomain(domain string) AriesOption {
+	return func(reg *ariesRegistration) {
+		reg.domain = domain
+	}
+}
+
+// WithAriesKMS option sets the creator of the KMS of the framework, used instead of the aries.WithKMS option.
+// Defaults to the local KMS, as the framework does.
+func WithAriesKMS(creator kms.Creator) AriesOption {
+	return func(reg *ariesRegistration) {
+		reg.kmsCreator = creator
+	}
+}
+
+// RegisterWithAries creates a did:trustbloc VDRI and returns the aries framework option registering it. The option
+// also sets the KMS of the framework, so the VDRI creates the recovery and update keys of new DIDs with it: once
+// registered, DIDs are created in the WithCreateDomain consortium by the Create method of the framework's VDRI
+// registry, with the key it generated as the public key of the document.
+func RegisterWithAries(opts ...AriesOption) aries.Option {
+	reg := &ariesRegistration{kmsCreator: localKMS}
+
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	v := New(reg.vdriOpts...)
+
+	return reg.ariesOption(&ariesVDRI{vdri: v, client: v.operationClient(), domain: reg.domain})
+}
+
+// ariesOption returns the aries framework option registering the VDRI, and wiring it to the KMS of the framework
+func (reg *ariesRegistration) ariesOption(v *ariesVDRI) aries.Option {
+	kmsOpt := aries.WithKMS(func(provider kms.Provider) (kms.KeyManager, error) {
+		km, err := reg.kmsCreator(provider)
+		if err != nil {
+			return nil, err
+		}
+
+		v.setKeyManager(km)
+
+		return km, nil
+	})
+
+	vdriOpt := aries.WithVDRI(v)
+
+	return func(framework *aries.Aries) error {
+		if err := kmsOpt(framework); err != nil {
+			return err
+		}
+
+		return vdriOpt(framework)
+	}
+}
+
+// localKMS creates the local KMS, the default KMS of the framework. As the framework does for its default KMS, keys
+// are stored unencrypted unless a secret lock is set with the aries.WithSecretLock option.
+func localKMS(provider kms.Provider) (kms.KeyManager, error) {
+	if provider.SecretLock() == nil {
+		provider = noLockProvider{provider}
+	}
+
+	return localkms.New(defaultMasterKeyURI, provider)
+}
+
+// noLockProvider provides the noop secret lock to a KMS
+type noLockProvider struct {
+	kms.Provider
+}
+
+func (noLockProvider) SecretLock() secretlock.Service {
+	return &noop.NoLock{}
+}
+
+// ariesVDRI is the VDRI registered with an aries framework, creating DIDs with the keys of the framework's KMS
+type ariesVDRI struct {
+	vdri       *VDRI
+	client     *did.Client
+	domain     string
+	keyManager kms.KeyManager
+	mutex      sync.RWMutex
+}
+
+func (a *ariesVDRI) setKeyManager(km kms.KeyManager) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.keyManager = km
+}
+
+func (a *ariesVDRI) getKeyManager() kms.KeyManager {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.keyManager
+}
+
+// Read resolves a did:trustbloc DID
+func (a *ariesVDRI) Read(didID string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	return a.vdri.Read(didID, opts...)
+}
+
+// Store did doc
+func (a *ariesVDRI) Store(doc *docdid.Doc, by *[]vdriapi.ModifiedBy) error {
+	return a.vdri.Store(doc, by)
+}
+
+// Accept did method
+func (a *ariesVDRI) Accept(method string) bool {
+	return a.vdri.Accept(method)
+}
+
+// Close frees the resources of the VDRI
+func (a *ariesVDRI) Close() error {
+	return a.vdri.Close()
+}
+
+// Build creates a DID in the consortium domain with the given public key, and a DIDComm service if the options
+// set a service endpoint. Its recovery and update keys are created with the KMS of the framework.
+func (a *ariesVDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	km := a.getKeyManager()
+	if km == nil {
+		return nil, errors.New("no KMS to create the recovery and update keys of the DID")
+	}
+
+	if a.domain == "" {
+		return nil, errors.New("no consortium domain to create the DID in")
+	}
+
+	docOpts := &vdriapi.CreateDIDOpts{}
+	for _, opt := range opts {
+		opt(docOpts)
+	}
+
+	doc, err := ariesBuildDoc(pubKey, docOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	sidetreeEndpoint, err := a.vdri.sidetreeEndpoint(context.Background(), a.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [2]ed25519.PublicKey
+
+	for i, name := range []string{"recovery key", "update key"} {
+		_, key, keyErr := km.CreateAndExportPubKeyBytes(kms.ED25519Type)
+		if keyErr != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", name, keyErr)
+		}
+
+		keys[i] = key
+	}
+
+	createOpts, err := createDIDOptions(doc, keys[0], keys[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return a.client.CreateDID("", append(createOpts, did.WithSidetreeEndpoint(sidetreeEndpoint))...)
+}
+
+// createDIDOptions returns the options creating a DID with the public keys and services of the doc, and the given
+// recovery and update keys
+func createDIDOptions(doc *docdid.Doc, recoveryKey, updateKey ed25519.PublicKey) ([]did.CreateDIDOption, error) {
+	clientDoc, err := did.FromAriesDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []did.CreateDIDOption{
+		did.WithPublicKey(&did.PublicKey{ID: "recovery", Encoding: did.PublicKeyEncodingJwk,
+			KeyType: did.Ed25519KeyType, Value: recoveryKey, Recovery: true}),
+		did.WithPublicKey(&did.PublicKey{ID: "update", Encoding: did.PublicKeyEncodingJwk,
+			KeyType: did.Ed25519KeyType, Value: updateKey, Update: true}),
+	}
+
+	for i := range clientDoc.PublicKey {
+		opts = append(opts, did.WithPublicKey(&clientDoc.PublicKey[i]))
+	}
+
+	for i := range clientDoc.Service {
+		opts = append(opts, did.WithService(&clientDoc.Service[i]))
+	}
+
+	return opts, nil
+}
+
+// ariesBuildDoc returns the doc of a new DID: the ed25519 key generated by the VDRI registry, used for
+// authentication, and a DIDComm service
+func ariesBuildDoc(pubKey *vdriapi.PubKey, docOpts *vdriapi.CreateDIDOpts) (*docdid.Doc, error) {
+	keyType := pubKey.Type
+	if keyType == "" {
+		keyType = did.Ed25519VerificationKey2018
+	}
+
+	pk, err := docdid.NewPublicKeyFromJWK(pubKey.ID, keyType, "",
+		&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: ed25519.PublicKey(pubKey.Value)}})
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	auth := docdid.NewReferencedVerificationMethod(pk, docdid.Authentication, false)
+
+	doc := &docdid.Doc{
+		PublicKey:      []docdid.PublicKey{*pk},
+		Authentication: []docdid.VerificationMethod{*auth},
+	}
+
+	if docOpts.ServiceEndpoint != "" {
+		doc.Service = []docdid.Service{{
+			ID:              ariesServiceID,
+			Type:            docOpts.ServiceType,
+			ServiceEndpoint: docOpts.ServiceEndpoint,
+			RoutingKeys:     docOpts.RoutingKeys,
+		}}
+	}
+
+	return doc, nil
+}
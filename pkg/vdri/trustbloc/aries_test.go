@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func newTestAries(opt aries.Option) (*aries.Aries, error) {
+	return aries.New(opt,
+		aries.WithStoreProvider(mem.NewProvider()),
+		aries.WithProtocolStateStoreProvider(mem.NewProvider()))
+}
+
+func ariesContext(t *testing.T, opt aries.Option) (*context.Provider, func()) {
+	framework, err := newTestAries(opt)
+	require.NoError(t, err)
+
+	ctx, err := framework.Context()
+	require.NoError(t, err)
+
+	return ctx, func() {
+		require.NoError(t, framework.Close())
+	}
+}
+
+func TestRegisterWithAries(t *testing.T) {
+	t.Run("DIDs are created with the VDRI registry", func(t *testing.T) {
+		var request map[string]interface{}
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &request))
+
+			_, err = fmt.Fprint(w, strings.ReplaceAll(testDoc, "did:example:", "did:trustbloc:testnet:"))
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		reg := &ariesRegistration{kmsCreator: localKMS, domain: "testnet"}
+
+		vdri := New(WithEndpointService(mock.NewEndpointService().
+			WithEndpoints("testnet", &models.Endpoint{URL: serv.URL})))
+		vdri.setValidatedConsortium("testnet")
+
+		v := &ariesVDRI{vdri: vdri, client: vdri.operationClient(), domain: "testnet"}
+
+		ctx, closeFramework := ariesContext(t, reg.ariesOption(v))
+		defer closeFramework()
+
+		doc, err := ctx.VDRIRegistry().Create("trustbloc",
+			vdriapi.WithServiceType("did-communication"),
+			vdriapi.WithServiceEndpoint("https://agent.example.com"))
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123456789abcdefghi", doc.ID)
+
+		require.Equal(t, "create", request["type"])
+		require.NotEmpty(t, request["suffix_data"])
+
+		delta, err := base64.RawURLEncoding.DecodeString(request["delta"].(string))
+		require.NoError(t, err)
+		require.Contains(t, string(delta), `"id":"didcomm"`)
+		require.Contains(t, string(delta), `"purpose":["general","auth"]`)
+	})
+
+	t.Run("create fails without a domain", func(t *testing.T) {
+		ctx, closeFramework := ariesContext(t, RegisterWithAries())
+		defer closeFramework()
+
+		_, err := ctx.VDRIRegistry().Create("trustbloc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no consortium domain to create the DID in")
+	})
+
+	t.Run("create fails with an invalid consortium", func(t *testing.T) {
+		ctx, closeFramework := ariesContext(t, RegisterWithAries(WithCreateDomain("testnet"),
+			WithVDRIOptions(WithConfigService(mock.NewConfigService().
+				WithError("testnet", errors.New("config error"))))))
+		defer closeFramework()
+
+		_, err := ctx.VDRIRegistry().Create("trustbloc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints of testnet")
+	})
+
+	t.Run("KMS error", func(t *testing.T) {
+		_, err := newTestAries(RegisterWithAries(WithAriesKMS(func(kms.Provider) (kms.KeyManager, error) {
+			return nil, errors.New("kms error")
+		})))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "kms error")
+	})
+
+	t.Run("create fails without a KMS", func(t *testing.T) {
+		_, err := (&ariesVDRI{domain: "testnet"}).Build(&vdriapi.PubKey{})
+		require.EqualError(t, err, "no KMS to create the recovery and update keys of the DID")
+	})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// OperationKeysHandler is called with the recovery and update keys generated for a DID created by Build, before its
+// create request is sent, so the keys are kept before the DID can exist. An error aborts the build.
+type OperationKeysHandler func(didID string, keys *did.OperationKeys) error
+
+// Build creates a DID in the consortium domain set with WithBuildDomain, with the public key and services of the doc
+// options, and returns its document. The DID is sent to the first sidetree endpoint of the validated consortium.
+// Its recovery and update keys are generated and passed to the handler set with WithOperationKeysHandler, which is
+// required: without it the keys would be lost, and the DID couldn't be updated, recovered or deactivated.
+func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	return v.BuildWithContext(context.Background(), pubKey, opts...)
+}
+
+// BuildWithContext creates a DID like Build. The validation of the consortium, the discovery of its endpoints and the
+// create request are aborted when the context is done: the DID may still have been created if the request was sent.
+func (v *VDRI) BuildWithContext(ctx context.Context, pubKey *vdriapi.PubKey,
+	opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	if v.buildDomain == "" {
+		return nil, errors.New("no consortium domain to create the DID in")
+	}
+
+	if v.operationKeysHandler == nil {
+		return nil, errors.New("no operation keys handler to keep the keys of the DID")
+	}
+
+	docOpts := &vdriapi.CreateDIDOpts{}
+	for _, opt := range opts {
+		opt(docOpts)
+	}
+
+	doc, err := ariesBuildDoc(pubKey, docOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	sidetreeEndpoint, err := v.sidetreeEndpoint(ctx, v.buildDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := did.NewOperationKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate operation keys: %w", err)
+	}
+
+	createOpts, err := createDIDOptions(doc, keys.Recovery.PublicKey(), keys.Update.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	handleKeys := func(didSuffix string) error {
+		didID := (&models.DID{Domain: v.buildDomain, Suffix: didSuffix}).String()
+
+		if err := v.operationKeysHandler(didID, keys); err != nil {
+			return fmt.Errorf("operation keys handler of %s failed: %w", didID, err)
+		}
+
+		return nil
+	}
+
+	return v.operationClient().CreateDID("", append(createOpts, did.WithSidetreeEndpoint(sidetreeEndpoint),
+		did.WithCreateContext(ctx), did.WithCreateRequestHandler(handleKeys))...)
+}
+
+// sidetreeEndpoint returns the sidetree endpoint DIDs are created with in a domain: DIDs are only created with the
+// endpoints of a validated consortium
+func (v *VDRI) sidetreeEndpoint(ctx context.Context, domain string) (string, error) {
+	endpoints, err := v.GetEndpointsWithContext(ctx, domain)
+	if err != nil {
+		return "", fmt.Errorf("failed to get endpoints of %s: %w", domain, err)
+	}
+
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("no endpoints in %s", domain)
+	}
+
+	return endpoints[0].URL, nil
+}
+
+// operationClient returns the DID client sending the DID operations of the vdri, sharing its TLS config and auth
+// token
+func (v *VDRI) operationClient() *did.Client {
+	v.didClientOnce.Do(func() {
+		clientOpts := []did.Option{did.WithTLSConfig(v.tlsConfig),
+			did.WithPinnedCertificates(v.transportOpts.PinnedCertificates)}
+		if v.authToken != "" {
+			clientOpts = append(clientOpts, did.WithAuthToken(v.authToken))
+		}
+
+		v.didClient = did.New(clientOpts...)
+	})
+
+	return v.didClient
+}
+
+// WithBuildDomain option sets the consortium domain the DIDs created by Build are created in
+func WithBuildDomain(domain string) Option {
+	return func(opts *VDRI) {
+		opts.buildDomain = domain
+	}
+}
+
+// WithOperationKeysHandler option sets the handler of the recovery and update keys of the DIDs created by Build,
+// eg. to keep them in a key store
+func WithOperationKeysHandler(handler OperationKeysHandler) Option {
+	return func(opts *VDRI) {
+		opts.operationKeysHandler = handler
+	}
+}
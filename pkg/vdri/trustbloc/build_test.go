@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_Build(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	createdDoc := strings.ReplaceAll(testDoc, "did:example:", "did:trustbloc:testnet:")
+
+	keepKeys := WithOperationKeysHandler(func(string, *did.OperationKeys) error { return nil })
+
+	newBuildVDRI := func(url string, opts ...Option) *VDRI {
+		v := New(append(append([]Option{keepKeys}, opts...), WithBuildDomain("testnet"),
+			WithEndpointService(mock.NewEndpointService().WithEndpoints("testnet", &models.Endpoint{URL: url})))...)
+		v.setValidatedConsortium("testnet")
+
+		return v
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var request map[string]interface{}
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &request))
+
+			_, err = fmt.Fprint(w, createdDoc)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		var (
+			handled   *did.OperationKeys
+			handledID string
+		)
+
+		v := newBuildVDRI(serv.URL, WithOperationKeysHandler(func(didID string, keys *did.OperationKeys) error {
+			require.Nil(t, request, "the keys are handled before the request is sent")
+
+			handledID = didID
+			handled = keys
+
+			return nil
+		}))
+
+		doc, err := v.Build(&vdriapi.PubKey{ID: "key1", Value: pubKey},
+			vdriapi.WithServiceType("did-communication"),
+			vdriapi.WithServiceEndpoint("https://agent.example.com"))
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123456789abcdefghi", doc.ID)
+
+		require.Equal(t, "create", request["type"])
+
+		delta, err := base64.RawURLEncoding.DecodeString(request["delta"].(string))
+		require.NoError(t, err)
+		require.Contains(t, string(delta), `"id":"didcomm"`)
+
+		require.Contains(t, string(delta), handled.Update.Commitment)
+
+		suffix, err := docutil.CalculateUniqueSuffix(request["suffix_data"].(string), sha2_256)
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:"+suffix, handledID)
+	})
+
+	t.Run("failure - no operation keys handler", func(t *testing.T) {
+		v := New(WithBuildDomain("testnet"))
+
+		_, err := v.Build(&vdriapi.PubKey{Value: pubKey})
+		require.EqualError(t, err, "no operation keys handler to keep the keys of the DID")
+	})
+
+	t.Run("failure - no domain", func(t *testing.T) {
+		_, err := New().Build(&vdriapi.PubKey{Value: pubKey})
+		require.EqualError(t, err, "no consortium domain to create the DID in")
+	})
+
+	t.Run("failure - invalid consortium", func(t *testing.T) {
+		v := New(keepKeys, WithBuildDomain("testnet"), WithConfigService(mock.NewConfigService().
+			WithError("testnet", errors.New("config error"))))
+
+		_, err := v.Build(&vdriapi.PubKey{Value: pubKey})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints of testnet")
+
+		v = New(keepKeys, WithBuildDomain("testnet"), WithEndpointService(mock.NewEndpointService()))
+		v.setValidatedConsortium("testnet")
+
+		_, err = v.Build(&vdriapi.PubKey{Value: pubKey})
+		require.EqualError(t, err, "no endpoints in testnet")
+	})
+
+	t.Run("failure - create", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer serv.Close()
+
+		_, err := newBuildVDRI(serv.URL).Build(&vdriapi.PubKey{Value: pubKey})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send create sidetree request")
+	})
+
+	t.Run("failure - operation keys handler", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Fail(t, "the request isn't sent when the keys can't be kept")
+		}))
+		defer serv.Close()
+
+		v := newBuildVDRI(serv.URL, WithOperationKeysHandler(func(string, *did.OperationKeys) error {
+			return errors.New("store error")
+		}))
+
+		_, err := v.Build(&vdriapi.PubKey{Value: pubKey})
+		require.Error(t, err)
+		require.Regexp(t, "^operation keys handler of did:trustbloc:testnet:[A-Za-z0-9_-]+ failed: store error$",
+			err.Error())
+	})
+}
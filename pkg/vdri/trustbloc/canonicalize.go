@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+// DocCanonicalization is the canonicalization applied to resolved DID docs before comparing them
+type DocCanonicalization int
+
+const (
+	// JCSCanonicalization canonicalizes docs using the JSON Canonicalization Scheme (RFC 8785),
+	// with the unordered sets of a DID doc (keys, verification relationships and services) sorted. This is the default.
+	JCSCanonicalization DocCanonicalization = iota
+	// JSONLDCanonicalization canonicalizes docs using JSON-LD normalization, which may fetch remote JSON-LD contexts
+	// (see WithJSONLDDocumentLoader)
+	JSONLDCanonicalization
+)
+
+// the processor and its default context loader are shared, so that remote JSON-LD contexts are fetched once
+// per process rather than on every canonicalization
+var (
+	ldProcessor      = jsonld.Default()
+	ldDocumentLoader = newCachingDocumentLoader(ld.NewDefaultDocumentLoader(&http.Client{}))
+)
+
+// cachingDocumentLoader caches loaded JSON-LD documents. Unlike ld.CachingDocumentLoader it is safe for concurrent use.
+type cachingDocumentLoader struct {
+	next  ld.DocumentLoader
+	cache map[string]*ld.RemoteDocument
+	mutex sync.RWMutex
+}
+
+func newCachingDocumentLoader(next ld.DocumentLoader) *cachingDocumentLoader {
+	return &cachingDocumentLoader{next: next, cache: make(map[string]*ld.RemoteDocument)}
+}
+
+// LoadDocument returns the cached document for the given url, loading it with the wrapped loader on a cache miss
+func (l *cachingDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.mutex.RLock()
+	doc, ok := l.cache[u]
+	l.mutex.RUnlock()
+
+	if ok {
+		return doc, nil
+	}
+
+	doc, err := l.next.LoadDocument(u)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mutex.Lock()
+	l.cache[u] = doc
+	l.mutex.Unlock()
+
+	return doc, nil
+}
+
+// comparableDoc holds a resolved DID doc with its JSON bytes, canonicalized only when
+// a plain byte comparison with another doc is inconclusive
+type comparableDoc struct {
+	doc          *docdid.Doc
+	raw          []byte
+	canonical    []byte
+	canonicalize func([]byte) ([]byte, error)
+}
+
+func newComparableDoc(doc *docdid.Doc, canonicalization DocCanonicalization,
+	loader ld.DocumentLoader) (*comparableDoc, error) {
+	raw, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &comparableDoc{doc: doc, raw: raw, canonicalize: canonicalizer(canonicalization, loader)}, nil
+}
+
+func (c *comparableDoc) canonicalBytes() ([]byte, error) {
+	if c.canonical == nil {
+		canonical, err := c.canonicalize(c.raw)
+		if err != nil {
+			return nil, err
+		}
+
+		c.canonical = canonical
+	}
+
+	return c.canonical, nil
+}
+
+// equal returns true if both docs have the same canonical form
+func (c *comparableDoc) equal(other *comparableDoc) (bool, error) {
+	if bytes.Equal(c.raw, other.raw) {
+		return true, nil
+	}
+
+	canonical, err := c.canonicalBytes()
+	if err != nil {
+		return false, err
+	}
+
+	otherCanonical, err := other.canonicalBytes()
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(canonical, otherCanonical), nil
+}
+
+// logDocMismatch logs the differences between the docs of a DID resolved from different endpoints
+func logDocMismatch(logger log.Logger, didID string, doc1, doc2 *docdid.Doc) {
+	diffs, err := did.Compare(doc1, doc2)
+	if err != nil {
+		logger.Debugf("mismatch in document contents for did %s: %v", didID, err)
+
+		return
+	}
+
+	// docs can be equal for JCS but not for JSON-LD canonicalization, eg. with equivalent contexts
+	if len(diffs) == 0 {
+		logger.Debugf("mismatch in canonical document contents for did %s", didID)
+
+		return
+	}
+
+	for _, diff := range diffs {
+		logger.Debugf("mismatch in document contents for did %s: %s", didID, diff)
+	}
+}
+
+// canonicalizer returns the function canonicalizing docs with the given canonicalization. JSON-LD contexts are
+// loaded with the given loader, or the shared default loader if it's nil.
+func canonicalizer(canonicalization DocCanonicalization, loader ld.DocumentLoader) func([]byte) ([]byte, error) {
+	if canonicalization == JSONLDCanonicalization {
+		if loader == nil {
+			loader = ldDocumentLoader
+		}
+
+		return func(docBytes []byte) ([]byte, error) {
+			return canonicalizeJSONLD(docBytes, loader)
+		}
+	}
+
+	return canonicalizeJCS
+}
+
+func canonicalizeJSONLD(docBytes []byte, loader ld.DocumentLoader) ([]byte, error) {
+	docMap := map[string]interface{}{}
+
+	err := json.Unmarshal(docBytes, &docMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return ldProcessor.GetCanonicalDocument(docMap, jsonld.WithDocumentLoader(loader))
+}
+
+func canonicalizeJCS(docBytes []byte) ([]byte, error) {
+	return did.Canonicalize(docBytes)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ldTestDoc1 = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:example:123",
+  "publicKey": [{
+    "id": "did:example:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }, {
+    "id": "did:example:123#key-2",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "GUXiqNHCdirb6NKpH6wYG4px3YfMjiCh6dQhU3zxQVQ7"
+  }]
+}`
+
+	// same as ldTestDoc1 with the keys in a different order
+	ldTestDoc2 = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:example:123",
+  "publicKey": [{
+    "id": "did:example:123#key-2",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "GUXiqNHCdirb6NKpH6wYG4px3YfMjiCh6dQhU3zxQVQ7"
+  }, {
+    "id": "did:example:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }]
+}`
+)
+
+type mockDocumentLoader struct {
+	loadCount int
+	err       error
+}
+
+func (m *mockDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	m.loadCount++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &ld.RemoteDocument{DocumentURL: u, Document: map[string]interface{}{
+		"@context": map[string]interface{}{"@vocab": "https://example.com/vocab#"},
+	}}, nil
+}
+
+func withDocumentLoader(tb testing.TB, loader ld.DocumentLoader) {
+	prev := ldDocumentLoader
+	ldDocumentLoader = newCachingDocumentLoader(loader)
+
+	tb.Cleanup(func() { ldDocumentLoader = prev })
+}
+
+func parseComparableDoc(tb testing.TB, docJSON string) *comparableDoc {
+	doc, err := did.ParseDocument([]byte(docJSON))
+	require.NoError(tb, err)
+
+	c, err := newComparableDoc(doc, JSONLDCanonicalization, nil)
+	require.NoError(tb, err)
+
+	return c
+}
+
+func TestComparableDoc(t *testing.T) {
+	t.Run("identical docs are equal without canonicalization", func(t *testing.T) {
+		loader := &mockDocumentLoader{}
+		withDocumentLoader(t, loader)
+
+		doc1 := parseComparableDoc(t, ldTestDoc1)
+
+		equal, err := doc1.equal(parseComparableDoc(t, ldTestDoc1))
+		require.NoError(t, err)
+		require.True(t, equal)
+		require.Nil(t, doc1.canonical)
+		require.Equal(t, 0, loader.loadCount)
+	})
+
+	t.Run("reordered docs are equal after canonicalization", func(t *testing.T) {
+		loader := &mockDocumentLoader{}
+		withDocumentLoader(t, loader)
+
+		doc1 := parseComparableDoc(t, ldTestDoc1)
+		doc2 := parseComparableDoc(t, ldTestDoc2)
+
+		equal, err := doc1.equal(doc2)
+		require.NoError(t, err)
+		require.True(t, equal)
+		require.NotEmpty(t, doc1.canonical)
+
+		// the context is loaded once and then served from the cache
+		require.Equal(t, 1, loader.loadCount)
+	})
+
+	t.Run("error loading context", func(t *testing.T) {
+		withDocumentLoader(t, &mockDocumentLoader{err: errors.New("offline")})
+
+		_, err := parseComparableDoc(t, ldTestDoc1).equal(parseComparableDoc(t, ldTestDoc2))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "loading remote context failed")
+	})
+
+	t.Run("custom loader", func(t *testing.T) {
+		defaultLoader := &mockDocumentLoader{}
+		withDocumentLoader(t, defaultLoader)
+
+		loader := &mockDocumentLoader{}
+
+		doc1, err := did.ParseDocument([]byte(ldTestDoc1))
+		require.NoError(t, err)
+
+		doc2, err := did.ParseDocument([]byte(ldTestDoc2))
+		require.NoError(t, err)
+
+		c1, err := newComparableDoc(doc1, JSONLDCanonicalization, loader)
+		require.NoError(t, err)
+
+		c2, err := newComparableDoc(doc2, JSONLDCanonicalization, loader)
+		require.NoError(t, err)
+
+		equal, err := c1.equal(c2)
+		require.NoError(t, err)
+		require.True(t, equal)
+		require.NotZero(t, loader.loadCount)
+		require.Equal(t, 0, defaultLoader.loadCount)
+	})
+}
+
+func BenchmarkComparableDoc_Equal(b *testing.B) {
+	withDocumentLoader(b, &mockDocumentLoader{})
+
+	doc1, err := did.ParseDocument([]byte(ldTestDoc1))
+	require.NoError(b, err)
+
+	doc2, err := did.ParseDocument([]byte(ldTestDoc1))
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c1, err := newComparableDoc(doc1, JCSCanonicalization, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		c2, err := newComparableDoc(doc2, JCSCanonicalization, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := c1.equal(c2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
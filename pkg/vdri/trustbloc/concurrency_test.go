@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// concurrencyMeter records the maximum number of calls running at the same time
+type concurrencyMeter struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+}
+
+func (m *concurrencyMeter) run(f func()) {
+	m.mutex.Lock()
+	m.running++
+
+	if m.running > m.max {
+		m.max = m.running
+	}
+	m.mutex.Unlock()
+
+	f()
+
+	m.mutex.Lock()
+	m.running--
+	m.mutex.Unlock()
+}
+
+// identitySource selects stakeholders in the order of the consortium members
+type identitySource struct{}
+
+func (identitySource) Intn(n int) int {
+	return 0
+}
+
+func (identitySource) Perm(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	return perm
+}
+
+func TestVDRI_ConcurrentStakeholders(t *testing.T) {
+	members := func(n int) []*models.StakeholderListElement {
+		var list []*models.StakeholderListElement
+		for i := 0; i < n; i++ {
+			list = append(list, &models.StakeholderListElement{Domain: fmt.Sprintf("stakeholder%d.example.com", i)})
+		}
+
+		return list
+	}
+
+	t.Run("stakeholder configs are fetched concurrently, bounded", func(t *testing.T) {
+		for _, concurrency := range []int{1, 3} {
+			meter := &concurrencyMeter{}
+
+			var calls int32
+
+			v := New(WithVerificationConcurrency(concurrency))
+			v.configService = &mockconfig.MockConfigService{
+				GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+					atomic.AddInt32(&calls, 1)
+					meter.run(func() {
+						time.Sleep(10 * time.Millisecond)
+					})
+
+					return nil, errors.New("unreachable")
+				},
+			}
+
+			_, _, err := v.selectStakeholders(context.Background(), "testnet", &models.Consortium{Members: members(6)})
+			require.EqualError(t, err, "insufficient valid stakeholders")
+			require.EqualValues(t, 6, atomic.LoadInt32(&calls))
+			require.LessOrEqual(t, meter.max, concurrency)
+
+			if concurrency > 1 {
+				require.Greater(t, meter.max, 1)
+			}
+		}
+	})
+
+	t.Run("concurrency is bounded by the worker pool", func(t *testing.T) {
+		meter := &concurrencyMeter{}
+
+		v := New(WithVerificationConcurrency(6), WithWorkerPoolSize(1))
+		v.configService = &mockconfig.MockConfigService{
+			GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+				meter.run(func() {
+					time.Sleep(10 * time.Millisecond)
+				})
+
+				return nil, errors.New("unreachable")
+			},
+		}
+
+		_, _, err := v.selectStakeholders(context.Background(), "testnet", &models.Consortium{Members: members(6)})
+		require.EqualError(t, err, "insufficient valid stakeholders")
+		// the worker and the calling goroutine
+		require.Equal(t, 2, meter.max)
+	})
+
+	t.Run("tenants and domains share the worker pool", func(t *testing.T) {
+		v := New(WithTenant("tenant"), WithDomainOptions("testnet"))
+		require.Same(t, v.pool, v.tenantVDRIs["tenant"].pool)
+		require.Same(t, v.pool, v.domainVDRIs["testnet"].pool)
+	})
+
+	t.Run("missing stakeholders are fetched in later rounds", func(t *testing.T) {
+		var fetched []string
+
+		var mutex sync.Mutex
+
+		v := New(WithRandomSource(identitySource{}))
+		v.configService = &mockconfig.MockConfigService{
+			GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+				mutex.Lock()
+				fetched = append(fetched, d)
+				mutex.Unlock()
+
+				if d == "stakeholder0.example.com" {
+					return nil, errors.New("unreachable")
+				}
+
+				return &models.StakeholderFileData{Config: &models.Stakeholder{Domain: d}}, nil
+			},
+		}
+
+		stakeholders, warnings, err := v.selectStakeholders(context.Background(), "testnet",
+			&models.Consortium{Members: members(4), Policy: models.ConsortiumPolicy{NumQueries: 2}})
+		require.NoError(t, err)
+		require.Len(t, stakeholders, 2)
+		require.Equal(t, "stakeholder1.example.com", stakeholders[0].Config.Domain)
+		require.Equal(t, "stakeholder2.example.com", stakeholders[1].Config.Domain)
+		require.Len(t, warnings, 1)
+		require.Equal(t, "stakeholder0.example.com", warnings[0].Stakeholder)
+		require.ElementsMatch(t,
+			[]string{"stakeholder0.example.com", "stakeholder1.example.com", "stakeholder2.example.com"}, fetched)
+	})
+
+	t.Run("verification errors are aggregated", func(t *testing.T) {
+		v := New(WithVerificationConcurrency(4))
+		v.configService = &mockconfig.MockConfigService{
+			GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+				// stakeholders without config fail verification
+				return &models.StakeholderFileData{}, nil
+			},
+		}
+
+		trace := &ResolutionTrace{}
+
+		_, err := v.verifyStakeholders(context.Background(), "testnet",
+			&models.ConsortiumFileData{Config: &models.Consortium{Members: members(4)}}, trace)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "insufficient stakeholders verified")
+		require.Equal(t, errcode.EndorsementThresholdNotMet, errcode.Of(err))
+		require.Equal(t, 4, strings.Count(err.Error(), "stakeholder has nil config"))
+		require.Len(t, trace.Steps, 5)
+	})
+}
@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package config has the helpers shared by the config services, which wrap each other to fetch, verify and cache
+// consortium and stakeholder configs.
+package config
+
+import (
+	"context"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// Service fetches consortium and stakeholder configs
+type Service interface {
+	GetConsortium(url, domain string) (*models.ConsortiumFileData, error)
+	GetStakeholder(url, domain string) (*models.StakeholderFileData, error)
+}
+
+// ContextService fetches consortium and stakeholder configs, aborting the fetches when the context is done
+type ContextService interface {
+	GetConsortiumWithContext(ctx context.Context, url, domain string) (*models.ConsortiumFileData, error)
+	GetStakeholderWithContext(ctx context.Context, url, domain string) (*models.StakeholderFileData, error)
+}
+
+// GetConsortium fetches a consortium config with the given service, passing it the context if it's a ContextService.
+// Other services can't be aborted: the context error is returned if the context is done before the fetch starts.
+func GetConsortium(ctx context.Context, service Service, url, domain string) (*models.ConsortiumFileData, error) {
+	if cs, ok := service.(ContextService); ok {
+		return cs.GetConsortiumWithContext(ctx, url, domain)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.GetConsortium(url, domain)
+}
+
+// GetStakeholder fetches a stakeholder config with the given service, passing it the context if it's a
+// ContextService, like GetConsortium
+func GetStakeholder(ctx context.Context, service Service, url, domain string) (*models.StakeholderFileData, error) {
+	if cs, ok := service.(ContextService); ok {
+		return cs.GetStakeholderWithContext(ctx, url, domain)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.GetStakeholder(url, domain)
+}
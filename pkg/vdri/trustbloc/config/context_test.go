@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+type ctxKey struct{}
+
+// contextService records the context it's called with
+type contextService struct {
+	*mock.ConfigService
+	ctx context.Context
+}
+
+func (s *contextService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	s.ctx = ctx
+
+	return s.GetConsortium(url, domain)
+}
+
+func (s *contextService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	s.ctx = ctx
+
+	return s.GetStakeholder(url, domain)
+}
+
+func TestGetConsortium(t *testing.T) {
+	consortium := &models.ConsortiumFileData{Config: &models.Consortium{Domain: "consortium.net"}}
+	service := mock.NewConfigService().WithConsortium("consortium.net", consortium)
+
+	t.Run("success - context service", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		s := &contextService{ConfigService: service}
+
+		c, err := GetConsortium(ctx, s, "consortium.net", "consortium.net")
+		require.NoError(t, err)
+		require.Equal(t, consortium, c)
+		require.Equal(t, ctx, s.ctx)
+	})
+
+	t.Run("success - service without context", func(t *testing.T) {
+		c, err := GetConsortium(context.Background(), service, "consortium.net", "consortium.net")
+		require.NoError(t, err)
+		require.Equal(t, consortium, c)
+	})
+
+	t.Run("failure - context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := GetConsortium(ctx, mock.NewConfigService().WithError("consortium.net", errors.New("fetched")),
+			"consortium.net", "consortium.net")
+		require.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestGetStakeholder(t *testing.T) {
+	stakeholder := &models.StakeholderFileData{Config: &models.Stakeholder{Domain: "stakeholder.net"}}
+	service := mock.NewConfigService().WithStakeholder("stakeholder.net", stakeholder)
+
+	t.Run("success - context service", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		s := &contextService{ConfigService: service}
+
+		sfd, err := GetStakeholder(ctx, s, "stakeholder.net", "stakeholder.net")
+		require.NoError(t, err)
+		require.Equal(t, stakeholder, sfd)
+		require.Equal(t, ctx, s.ctx)
+	})
+
+	t.Run("success - service without context", func(t *testing.T) {
+		sfd, err := GetStakeholder(context.Background(), service, "stakeholder.net", "stakeholder.net")
+		require.NoError(t, err)
+		require.Equal(t, stakeholder, sfd)
+	})
+
+	t.Run("failure - context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := GetStakeholder(ctx, service, "stakeholder.net", "stakeholder.net")
+		require.Equal(t, context.Canceled, err)
+	})
+}
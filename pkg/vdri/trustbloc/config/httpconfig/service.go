@@ -6,30 +6,39 @@ SPDX-License-Identifier: Apache-2.0
 package httpconfig
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
-	"io/ioutil"
+	"mime"
 	"net/http"
 	"strings"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
 
 // ConfigService fetches consortium and stakeholder configs over http
 type ConfigService struct {
-	httpClient *http.Client
-	tlsConfig  *tls.Config
+	httpClient      *http.Client
+	tlsConfig       *tls.Config
+	transport       http.RoundTripper
+	maxResponseSize int64
 }
 
 // NewService create new ConfigService
 func NewService(opts ...Option) *ConfigService {
-	configService := &ConfigService{httpClient: &http.Client{}}
+	configService := &ConfigService{httpClient: &http.Client{}, maxResponseSize: transport.DefaultMaxResponseSize}
 
 	for _, opt := range opts {
 		opt(configService)
 	}
 
-	configService.httpClient.Transport = &http.Transport{TLSClientConfig: configService.tlsConfig}
+	if configService.transport == nil {
+		configService.transport = &http.Transport{TLSClientConfig: tlspolicy.Apply(configService.tlsConfig)}
+	}
+
+	configService.httpClient.Transport = configService.transport
 
 	return configService
 }
@@ -46,24 +55,28 @@ func configURL(urlDomain, consortiumDomain string) string {
 	return prefix + urlDomain + consortiumURLInfix + consortiumDomain + consortiumURLSuffix
 }
 
+// media types of config files: COSE messages with a CBOR payload are preferred over JWS
+const (
+	coseMediaType = "application/cose"
+	acceptHeader  = coseMediaType + ", application/jose, application/json;q=0.9"
+)
+
 // GetConsortium fetches and parses the consortium file at the given domain
 func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
-	res, err := cs.httpClient.Get(configURL(url, domain))
-	if err != nil {
-		return nil, err
-	}
-
-	// nolint: errcheck
-	defer res.Body.Close()
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
 
-	body, err := ioutil.ReadAll(res.Body)
+// GetConsortiumWithContext fetches and parses the consortium file at the given domain, aborting the request when
+// the context is done
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	body, cose, err := cs.fetch(ctx, url, domain, "consortium")
 	if err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		// TODO retry https://github.com/trustbloc/trustbloc-did-method/issues/159
-		return nil, fmt.Errorf("consortium config request failed: error %d, `%s`", res.StatusCode, string(body))
+	if cose {
+		return models.ParseConsortiumCOSE(body)
 	}
 
 	return models.ParseConsortium(body)
@@ -71,25 +84,55 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 
 // GetStakeholder fetches and parses a stakeholder file under the given url with the given domain
 func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
-	res, err := cs.httpClient.Get(configURL(url, domain))
+	return cs.GetStakeholderWithContext(context.Background(), url, domain)
+}
+
+// GetStakeholderWithContext fetches and parses a stakeholder file under the given url with the given domain,
+// aborting the request when the context is done
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	body, cose, err := cs.fetch(ctx, url, domain, "stakeholder")
 	if err != nil {
 		return nil, err
 	}
 
+	if cose {
+		return models.ParseStakeholderCOSE(body)
+	}
+
+	return models.ParseStakeholder(body)
+}
+
+// fetch fetches a config file, returning its contents and whether the server sent it as a COSE message
+func (cs *ConfigService) fetch(ctx context.Context, url, domain, kind string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL(url, domain), nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("Accept", acceptHeader)
+
+	res, err := cs.httpClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+
 	// nolint: errcheck
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := transport.ReadLimited(res.Body, cs.maxResponseSize)
 	if err != nil {
-		return nil, err
+		return nil, false, fmt.Errorf("failed to read %s config: %w", kind, err)
 	}
 
 	if res.StatusCode != http.StatusOK {
 		// TODO retry https://github.com/trustbloc/trustbloc-did-method/issues/159
-		return nil, fmt.Errorf("stakeholder config request failed: error %d, `%s`", res.StatusCode, string(body))
+		return nil, false, fmt.Errorf("%s config request failed: error %d, `%s`", kind, res.StatusCode, string(body))
 	}
 
-	return models.ParseStakeholder(body)
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+
+	return body, err == nil && mediaType == coseMediaType, nil
 }
 
 // Option is a config service instance option
@@ -101,3 +144,19 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 		opts.tlsConfig = tlsConfig
 	}
 }
+
+// WithTransport option sets the http transport used to fetch files, allowing a connection pool to be shared
+// with other services. If set, the tls.Config option is ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *ConfigService) {
+		opts.transport = transport
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size of a config file, in bytes. Larger files are refused without
+// being read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *ConfigService) {
+		opts.maxResponseSize = max
+	}
+}
@@ -6,11 +6,16 @@ SPDX-License-Identifier: Apache-2.0
 package httpconfig
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -45,6 +50,31 @@ func TestConfigService_GetConsortium(t *testing.T) {
 		require.Equal(t, "foo.bar", conf.Config.Domain)
 	})
 
+	t.Run("success - COSE", func(t *testing.T) {
+		consortiumJSON, err := json.Marshal(mockmodels.DummyConsortium("foo.bar", nil))
+		require.NoError(t, err)
+
+		consortiumFile, err := mockmodels.DummyCOSEWrap(string(consortiumJSON))
+		require.NoError(t, err)
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Contains(t, r.Header.Get("Accept"), "application/cose")
+			w.Header().Set("Content-Type", "application/cose; cose-type=\"cose-sign\"")
+			_, err := w.Write(consortiumFile)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		cs := NewService()
+
+		conf, err := cs.GetConsortium(serv.URL, "foo.bar")
+		require.NoError(t, err)
+
+		require.Equal(t, "foo.bar", conf.Config.Domain)
+		require.NotNil(t, conf.COSE)
+		require.Nil(t, conf.JWS)
+	})
+
 	t.Run("failure: can't reach server", func(t *testing.T) {
 		cs := NewService()
 
@@ -77,6 +107,19 @@ func TestConfigService_GetConsortium(t *testing.T) {
 
 		require.Contains(t, err.Error(), "consortium config data should be a JWS")
 	})
+
+	t.Run("failure: response too large", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, strings.Repeat("x", 11))
+		}))
+		defer serv.Close()
+
+		cs := NewService(WithMaxResponseSize(10))
+
+		_, err := cs.GetConsortium(serv.URL, "foo.bar")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read consortium config: response body too large")
+	})
 }
 
 func TestConfigService_GetStakeholder(t *testing.T) {
@@ -102,6 +145,44 @@ func TestConfigService_GetStakeholder(t *testing.T) {
 		require.Equal(t, "foo.bar", conf.Config.Domain)
 	})
 
+	t.Run("success - COSE", func(t *testing.T) {
+		stakeholderJSON, err := json.Marshal(mockmodels.DummyStakeholder("foo.bar", []string{"endpoint.website"}))
+		require.NoError(t, err)
+
+		stakeholderFile, err := mockmodels.DummyCOSEWrap(string(stakeholderJSON))
+		require.NoError(t, err)
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/cose")
+			_, err := w.Write(stakeholderFile)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		cs := NewService()
+
+		conf, err := cs.GetStakeholder(serv.URL, "foo.bar")
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"endpoint.website"}, conf.Config.Endpoints)
+		require.NotNil(t, conf.COSE)
+	})
+
+	t.Run("failure: JWS sent as COSE", func(t *testing.T) {
+		stakeholderFile, err := mockmodels.WrapStakeholder(mockmodels.DummyStakeholder("foo.bar", nil))
+		require.NoError(t, err)
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/cose")
+			fmt.Fprint(w, stakeholderFile)
+		}))
+		defer serv.Close()
+
+		_, err = NewService().GetStakeholder(serv.URL, "foo.bar")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder config data should be a COSE message")
+	})
+
 	t.Run("failure: can't reach server", func(t *testing.T) {
 		cs := NewService()
 
@@ -136,6 +217,39 @@ func TestConfigService_GetStakeholder(t *testing.T) {
 	})
 }
 
+func TestConfigService_Context(t *testing.T) {
+	release := make(chan struct{})
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer serv.Close()
+	defer close(release)
+
+	cs := NewService()
+
+	t.Run("failure: consortium deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := cs.GetConsortiumWithContext(ctx, serv.URL, "foo.bar")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("failure: stakeholder canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := cs.GetStakeholderWithContext(ctx, serv.URL, "foo.bar")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
 func Test_configURL(t *testing.T) {
 	tests := [][2]string{ // first element is the test value, second is the correct value
 		{
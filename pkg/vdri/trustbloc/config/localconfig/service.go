@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const configFileSuffix = ".json"
+
+// ConfigService serves local config files for overridden consortium domains, for development networks that
+// don't have a signing bootstrap, and fetches the configs of other domains using a wrapped config service.
+//
+// The config files of an overridden consortium are read from a directory laid out like the well-known
+// config location of a domain: `<dir>/<consortium domain>.json` for the consortium and
+// `<dir>/<stakeholder domain>.json` for each of its stakeholders. Files may be signed (JWS) or plain JSON
+// configs, and their signatures are NOT verified.
+type ConfigService struct {
+	config config.Service
+
+	// override directories, by consortium domain
+	overrides map[string]string
+	logger    log.Logger
+}
+
+// NewService create new ConfigService, with override directories by consortium domain
+func NewService(wrapped config.Service, overrides map[string]string, opts ...Option) *ConfigService {
+	cs := &ConfigService{config: wrapped, overrides: overrides}
+
+	for _, opt := range opts {
+		opt(cs)
+	}
+
+	cs.logger = log.OrDefault(cs.logger)
+
+	for domain, dir := range overrides {
+		cs.logger.Warnf("DEV MODE: consortium %s uses the local config override in %s, config signatures are NOT "+
+			"verified. Never use config overrides in production!", domain, dir)
+	}
+
+	return cs
+}
+
+// GetConsortium returns the local consortium config of an overridden domain, or fetches it with the wrapped
+// config service
+func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
+
+// GetConsortiumWithContext returns the local consortium config of an overridden domain, or fetches it with the
+// wrapped config service, aborting the fetch when the context is done
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	dir, ok := cs.overrides[domain]
+	if !ok {
+		return config.GetConsortium(ctx, cs.config, url, domain)
+	}
+
+	data, err := readConfig(dir, domain)
+	if err != nil {
+		return nil, fmt.Errorf("local consortium config override: %w", err)
+	}
+
+	if isJWS(data) {
+		return models.ParseConsortium(data)
+	}
+
+	consortium := &models.Consortium{}
+	if err := json.Unmarshal(data, consortium); err != nil {
+		return nil, fmt.Errorf("local consortium config override: %w", err)
+	}
+
+	return &models.ConsortiumFileData{Config: consortium}, nil
+}
+
+// GetStakeholder returns the local config of a stakeholder of an overridden domain, or fetches it with the
+// wrapped config service
+func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
+	return cs.GetStakeholderWithContext(context.Background(), url, domain)
+}
+
+// GetStakeholderWithContext returns the local config of a stakeholder of an overridden domain, or fetches it with
+// the wrapped config service, aborting the fetch when the context is done
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	dir, ok := cs.stakeholderOverride(domain)
+	if !ok {
+		return config.GetStakeholder(ctx, cs.config, url, domain)
+	}
+
+	data, err := readConfig(dir, domain)
+	if err != nil {
+		return nil, fmt.Errorf("local stakeholder config override: %w", err)
+	}
+
+	if isJWS(data) {
+		return models.ParseStakeholder(data)
+	}
+
+	stakeholder := &models.Stakeholder{}
+	if err := json.Unmarshal(data, stakeholder); err != nil {
+		return nil, fmt.Errorf("local stakeholder config override: %w", err)
+	}
+
+	return &models.StakeholderFileData{Config: stakeholder}, nil
+}
+
+// stakeholderOverride returns the override directory containing the config of a stakeholder
+func (cs *ConfigService) stakeholderOverride(domain string) (string, bool) {
+	if !validDomain(domain) {
+		return "", false
+	}
+
+	for _, dir := range cs.overrides {
+		if _, err := os.Stat(configPath(dir, domain)); err == nil {
+			return dir, true
+		}
+	}
+
+	return "", false
+}
+
+func configPath(dir, domain string) string {
+	return filepath.Join(dir, domain+configFileSuffix)
+}
+
+// validDomain returns false for domains that would escape the override directory
+func validDomain(domain string) bool {
+	return domain != "" && !strings.ContainsAny(domain, `/\`) && !strings.HasPrefix(domain, ".")
+}
+
+func readConfig(dir, domain string) ([]byte, error) {
+	if !validDomain(domain) {
+		return nil, fmt.Errorf("invalid domain '%s'", domain)
+	}
+
+	return ioutil.ReadFile(configPath(dir, domain)) // nolint: gosec
+}
+
+// isJWS returns true if a config file is a JWS, in compact or JSON serialization, rather than a plain config
+func isJWS(data []byte) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return true
+	}
+
+	_, ok := obj["payload"]
+
+	return ok
+}
+
+// Option is a local config service option
+type Option func(opts *ConfigService)
+
+// WithLogger option sets the logger of the config service. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *ConfigService) {
+		opts.logger = logger
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localconfig
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func writeConfig(t *testing.T, dir, domain, data string) {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, domain+".json"), []byte(data), 0600))
+}
+
+func TestConfigService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localconfig")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	writeConfig(t, dir, "testnet.trustbloc.local",
+		`{"domain":"testnet.trustbloc.local","members":[{"domain":"stakeholder.one"}]}`)
+	writeConfig(t, dir, "stakeholder.one", `{"domain":"stakeholder.one","endpoints":["http://localhost:48326"]}`)
+
+	signed, err := mockmodels.WrapStakeholder(mockmodels.DummyStakeholder("stakeholder.two", nil))
+	require.NoError(t, err)
+
+	writeConfig(t, dir, "stakeholder.two", signed)
+	writeConfig(t, dir, "stakeholder.bad", `{"endpoints":"bad"}`)
+	writeConfig(t, dir, "bad.local", `{"members":"bad"}`)
+
+	errRemote := errors.New("remote config")
+
+	cs := NewService(&mockconfig.MockConfigService{
+		GetConsortiumFunc: func(string, string) (*models.ConsortiumFileData, error) {
+			return nil, errRemote
+		},
+		GetStakeholderFunc: func(string, string) (*models.StakeholderFileData, error) {
+			return nil, errRemote
+		},
+	}, map[string]string{"testnet.trustbloc.local": dir, "bad.local": dir, "missing.local": dir})
+
+	t.Run("success - overridden consortium", func(t *testing.T) {
+		cfd, err := cs.GetConsortium("stakeholder.one", "testnet.trustbloc.local")
+		require.NoError(t, err)
+		require.Equal(t, "stakeholder.one", cfd.Config.Members[0].Domain)
+		require.Nil(t, cfd.JWS)
+	})
+
+	t.Run("success - plain and signed stakeholders", func(t *testing.T) {
+		sfd, err := cs.GetStakeholder("stakeholder.one", "stakeholder.one")
+		require.NoError(t, err)
+		require.Equal(t, []string{"http://localhost:48326"}, sfd.Config.Endpoints)
+
+		sfd, err = cs.GetStakeholder("stakeholder.two", "stakeholder.two")
+		require.NoError(t, err)
+		require.Equal(t, "stakeholder.two", sfd.Config.Domain)
+		require.NotNil(t, sfd.JWS)
+	})
+
+	t.Run("success - other domains are fetched", func(t *testing.T) {
+		_, err := cs.GetConsortium("consortium.net", "consortium.net")
+		require.Equal(t, errRemote, err)
+
+		_, err = cs.GetStakeholder("stakeholder.three", "stakeholder.three")
+		require.Equal(t, errRemote, err)
+
+		_, err = cs.GetStakeholder("..", "../"+filepath.Base(dir)+"/stakeholder.one")
+		require.Equal(t, errRemote, err)
+	})
+
+	t.Run("success - overrides are read with a done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		cfd, err := cs.GetConsortiumWithContext(ctx, "testnet.trustbloc.local", "testnet.trustbloc.local")
+		require.NoError(t, err)
+		require.Equal(t, "testnet.trustbloc.local", cfd.Config.Domain)
+
+		_, err = cs.GetConsortiumWithContext(ctx, "consortium.net", "consortium.net")
+		require.Equal(t, context.Canceled, err)
+
+		_, err = cs.GetStakeholderWithContext(ctx, "stakeholder.three", "stakeholder.three")
+		require.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("failure - invalid configs", func(t *testing.T) {
+		_, err := cs.GetConsortium("bad.local", "bad.local")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "local consortium config override: json: cannot unmarshal")
+
+		_, err = cs.GetStakeholder("stakeholder.bad", "stakeholder.bad")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "local stakeholder config override: json: cannot unmarshal")
+	})
+
+	t.Run("failure - missing config", func(t *testing.T) {
+		_, err := cs.GetConsortium("missing.local", "missing.local")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "local consortium config override: open")
+	})
+
+	t.Run("failure - invalid domain", func(t *testing.T) {
+		_, err := readConfig(dir, "../etc")
+		require.EqualError(t, err, "invalid domain '../etc'")
+	})
+}
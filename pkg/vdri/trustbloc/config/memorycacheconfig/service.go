@@ -6,57 +6,98 @@ SPDX-License-Identifier: Apache-2.0
 package memorycacheconfig
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/clock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
 
 	"github.com/bluele/gcache"
 )
 
-type config interface {
-	GetConsortium(string, string) (*models.ConsortiumFileData, error)
-	GetStakeholder(string, string) (*models.StakeholderFileData, error)
-}
-
 // ConfigService fetches consortium and stakeholder configs using a wrapped config service, caching results in-memory
 type ConfigService struct {
-	config config
+	config config.Service
 	cCache gcache.Cache
 	sCache gcache.Cache
+	clock  gcache.Clock
+
+	// fetchers of the consortium and stakeholder cache entries
+	fetchConsortium  fetcher
+	fetchStakeholder fetcher
+
+	// newest consortium config trusted so far, by consortium domain
+	trusted      map[string]*models.ConsortiumFileData
+	trustedMutex sync.Mutex
 }
 
 // NewService create new ConfigService
-func NewService(config config) *ConfigService {
+func NewService(wrapped config.Service, opts ...Option) *ConfigService {
 	configService := &ConfigService{
-		config: config,
+		config:  wrapped,
+		trusted: map[string]*models.ConsortiumFileData{},
+		clock:   gcache.NewRealClock(),
 	}
 
-	configService.cCache = makeCache(
-		configService.getNewCacheable(func(url, domain string) (cacheable, error) {
-			return configService.config.GetConsortium(url, domain)
-		}))
+	for _, opt := range opts {
+		opt(configService)
+	}
+
+	configService.fetchConsortium = getNewCacheable(
+		func(ctx context.Context, url, domain string) (cacheable, error) {
+			consortiumData, err := config.GetConsortium(ctx, configService.config, url, domain)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := configService.checkUpdate(domain, consortiumData); err != nil {
+				return nil, err
+			}
 
-	configService.sCache = makeCache(
-		configService.getNewCacheable(func(url, domain string) (cacheable, error) {
-			return configService.config.GetStakeholder(url, domain)
-		}))
+			return consortiumCacheable{consortiumData}, nil
+		})
+
+	configService.fetchStakeholder = getNewCacheable(
+		func(ctx context.Context, url, domain string) (cacheable, error) {
+			return config.GetStakeholder(ctx, configService.config, url, domain)
+		})
+
+	configService.cCache = configService.makeCache(configService.fetchConsortium)
+	configService.sCache = configService.makeCache(configService.fetchStakeholder)
 
 	return configService
 }
 
+// Option is a config service instance option
+type Option func(opts *ConfigService)
+
+// WithClock option sets the clock the cache lifetimes of configs are measured with, eg. a fake clock for
+// reproducible tests. Defaults to the system clock.
+func WithClock(now func() time.Time) Option {
+	return func(opts *ConfigService) {
+		opts.clock = clock.Func(now)
+	}
+}
+
 type stringPair struct {
 	url, domain string
 }
 
-func makeCache(fetcher func(url, domain string) (interface{}, *time.Duration, error)) gcache.Cache {
-	return gcache.New(0).LoaderExpireFunc(func(key interface{}) (interface{}, *time.Duration, error) {
+// fetcher fetches the value of a cache entry, returning it with its cache lifetime
+type fetcher func(ctx context.Context, url, domain string) (interface{}, *time.Duration, error)
+
+func (cs *ConfigService) makeCache(fetch fetcher) gcache.Cache {
+	return gcache.New(0).Clock(cs.clock).LoaderExpireFunc(func(key interface{}) (interface{}, *time.Duration, error) {
 		keyStrPair, ok := key.(stringPair)
 		if !ok {
 			return nil, nil, fmt.Errorf("key must be stringPair")
 		}
 
-		return fetcher(keyStrPair.url, keyStrPair.domain)
+		return fetch(context.Background(), keyStrPair.url, keyStrPair.domain)
 	}).Build()
 }
 
@@ -64,11 +105,9 @@ type cacheable interface {
 	CacheLifetime() (time.Duration, error)
 }
 
-func (cs *ConfigService) getNewCacheable(
-	fetcher func(url, domain string) (cacheable, error),
-) func(url, domain string) (interface{}, *time.Duration, error) {
-	return func(url, domain string) (interface{}, *time.Duration, error) {
-		data, err := fetcher(url, domain)
+func getNewCacheable(fetch func(ctx context.Context, url, domain string) (cacheable, error)) fetcher {
+	return func(ctx context.Context, url, domain string) (interface{}, *time.Duration, error) {
+		data, err := fetch(ctx, url, domain)
 		if err != nil {
 			return nil, nil, fmt.Errorf("fetching cacheable object: %w", err)
 		}
@@ -91,25 +130,106 @@ func getEntryHelper(cache gcache.Cache, key interface{}, objectName string) (int
 	return data, nil
 }
 
+// getEntry returns a cache entry, fetching it with the context if it's missing. Entries fetched without a context
+// that can be done are loaded by the cache, which merges the concurrent fetches of an entry.
+func getEntry(ctx context.Context, cache gcache.Cache, key stringPair, fetch fetcher,
+	objectName string) (interface{}, error) {
+	if ctx.Done() == nil || cache.Has(key) {
+		return getEntryHelper(cache, key, objectName)
+	}
+
+	data, expiry, err := fetch(ctx, key.url, key.domain)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s from cache: %w", objectName, err)
+	}
+
+	if err = cache.SetWithExpire(key, data, *expiry); err != nil {
+		return nil, fmt.Errorf("caching %s: %w", objectName, err)
+	}
+
+	return data, nil
+}
+
+// consortiumCacheable caches consortium configs for the cache lifetime of their policy
+type consortiumCacheable struct {
+	*models.ConsortiumFileData
+}
+
+func (c consortiumCacheable) CacheLifetime() (time.Duration, error) {
+	if c.Config == nil {
+		return 0, fmt.Errorf("missing config object")
+	}
+
+	return policy.New(c.Config).CacheLifetime(), nil
+}
+
+// checkUpdate refuses a consortium config that the policy of the newest one trusted so far doesn't allow to replace
+// it, so a compromised server can't roll a consortium back to a previous config or skip its history
+func (cs *ConfigService) checkUpdate(domain string, consortiumData *models.ConsortiumFileData) error {
+	if consortiumData == nil || consortiumData.Config == nil {
+		return nil
+	}
+
+	cs.trustedMutex.Lock()
+	defer cs.trustedMutex.Unlock()
+
+	if err := policy.CheckUpdate(cs.trusted[domain], consortiumData); err != nil {
+		return err
+	}
+
+	cs.trusted[domain] = consortiumData
+
+	return nil
+}
+
+// Pin sets the consortium config trusted for a domain, eg. a genesis config used as the root of trust, which newer
+// configs must succeed as allowed by its policy. Cached consortium configs of the domain are dropped.
+func (cs *ConfigService) Pin(domain string, consortiumData *models.ConsortiumFileData) {
+	cs.trustedMutex.Lock()
+	defer cs.trustedMutex.Unlock()
+
+	cs.trusted[domain] = consortiumData
+
+	for _, key := range cs.cCache.Keys(false) {
+		if pair, ok := key.(stringPair); ok && pair.domain == domain {
+			cs.cCache.Remove(key)
+		}
+	}
+}
+
 // GetConsortium fetches and parses the consortium file at the given domain, caching the value
 func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
-	consortiumDataInterface, err := getEntryHelper(cs.cCache, stringPair{
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
+
+// GetConsortiumWithContext fetches and parses the consortium file at the given domain, caching the value. A fetch
+// is aborted when the context is done.
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	consortiumDataInterface, err := getEntry(ctx, cs.cCache, stringPair{
 		url:    url,
 		domain: domain,
-	}, "consortium")
+	}, cs.fetchConsortium, "consortium")
 	if err != nil {
 		return nil, err
 	}
 
-	return consortiumDataInterface.(*models.ConsortiumFileData), nil
+	return consortiumDataInterface.(consortiumCacheable).ConsortiumFileData, nil
 }
 
 // GetStakeholder returns the stakeholder config file fetched by the wrapped config service, caching the value
 func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
-	stakeholderDataInterface, err := getEntryHelper(cs.sCache, stringPair{
+	return cs.GetStakeholderWithContext(context.Background(), url, domain)
+}
+
+// GetStakeholderWithContext returns the stakeholder config file fetched by the wrapped config service, caching the
+// value. A fetch is aborted when the context is done.
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	stakeholderDataInterface, err := getEntry(ctx, cs.sCache, stringPair{
 		url:    url,
 		domain: domain,
-	}, "stakeholder")
+	}, cs.fetchStakeholder, "stakeholder")
 	if err != nil {
 		return nil, err
 	}
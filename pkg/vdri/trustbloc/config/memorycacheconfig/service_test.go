@@ -6,14 +6,18 @@ SPDX-License-Identifier: Apache-2.0
 package memorycacheconfig
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
 	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
 )
 
 func TestConfigService_GetConsortium(t *testing.T) {
@@ -109,6 +113,71 @@ func TestConfigService_GetConsortium(t *testing.T) {
 		require.Contains(t, err.Error(), "double-call")
 	})
 
+	t.Run("failure - refuse older version than trusted", func(t *testing.T) {
+		versions := []uint64{2, 2, 1, 3}
+		callCount := 0
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				consortiumData := mockmodels.DummyConsortium("foo.bar", nil)
+				consortiumData.Policy.Cache.MaxAge = 0
+				consortiumData.Version = versions[callCount]
+				callCount++
+
+				return &models.ConsortiumFileData{Config: consortiumData}, nil
+			}})
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), conf.Config.Version)
+
+		// the same version is fetched from another server
+		conf, err = cs.GetConsortium("stakeholder.one", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), conf.Config.Version)
+
+		_, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "consortium config version 1 is older than the trusted version 2")
+
+		conf, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), conf.Config.Version)
+	})
+
+	t.Run("failure - history required by the trusted policy", func(t *testing.T) {
+		previous := ""
+		callCount := 0
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				consortiumData := mockmodels.DummyConsortium("foo.bar", nil)
+				consortiumData.Policy.VerifyHistory = true
+				consortiumData.Version = uint64(callCount)
+				consortiumData.Previous = previous
+				callCount++
+
+				return &models.ConsortiumFileData{Config: consortiumData}, nil
+			}})
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+
+		// the files have no payload, so the history id is the hash of an empty payload
+		previous, err = policy.New(conf.Config).HistoryID(conf)
+		require.NoError(t, err)
+
+		conf, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), conf.Config.Version)
+
+		previous = "other"
+
+		_, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "consortium config version 2 doesn't reference the trusted version 1")
+	})
+
 	t.Run("failure - nil pointer", func(t *testing.T) {
 		cs := NewService(&mockconfig.MockConfigService{
 			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
@@ -120,6 +189,71 @@ func TestConfigService_GetConsortium(t *testing.T) {
 
 		require.Contains(t, err.Error(), "missing")
 	})
+
+	t.Run("success - expiry measured with the given clock", func(t *testing.T) {
+		consortiumData := mockmodels.DummyConsortium("foo.bar", nil)
+		consortiumData.Policy.Cache.MaxAge = 60
+
+		callCount := 0
+		now := time.Now()
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				callCount++
+
+				return &models.ConsortiumFileData{Config: consortiumData}, nil
+			}}, WithClock(func() time.Time { return now }))
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+
+		now = now.Add(59 * time.Second)
+
+		_, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, 1, callCount)
+
+		now = now.Add(2 * time.Second)
+
+		_, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, 2, callCount)
+	})
+}
+
+func TestConfigService_Pin(t *testing.T) {
+	version := uint64(2)
+
+	cs := NewService(&mockconfig.MockConfigService{
+		GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+			consortiumData := mockmodels.DummyConsortium("foo.bar", nil)
+			consortiumData.Version = version
+
+			return &models.ConsortiumFileData{Config: consortiumData}, nil
+		}})
+
+	conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), conf.Config.Version)
+
+	pinned := mockmodels.DummyConsortium("foo.bar", nil)
+	pinned.Version = 5
+
+	cs.Pin("foo.bar", &models.ConsortiumFileData{Config: pinned})
+	cs.Pin("other.domain", &models.ConsortiumFileData{Config: pinned})
+
+	// the cached config is dropped, and the re-fetched one is older than the pinned one
+	version = 3
+
+	_, err = cs.GetConsortium("foo.bar", "foo.bar")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "consortium config version 3 is older than the trusted version 5")
+
+	version = 5
+
+	conf, err = cs.GetConsortium("foo.bar", "foo.bar")
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), conf.Config.Version)
 }
 
 func TestConfigService_GetStakeholder(t *testing.T) {
@@ -222,3 +356,55 @@ func TestConfigService_GetStakeholder(t *testing.T) {
 		require.Contains(t, err.Error(), "key must be stringPair")
 	})
 }
+
+func TestConfigService_Context(t *testing.T) {
+	consortiumData := mockmodels.DummyConsortium("foo.bar", []*models.StakeholderListElement{{Domain: "bar.baz"}})
+	consortiumData.Policy.Cache.MaxAge = 1000
+
+	callCount := 0
+
+	cs := NewService(&mockconfig.MockConfigService{
+		GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+			callCount++
+
+			return &models.ConsortiumFileData{Config: consortiumData}, nil
+		}})
+
+	t.Run("failure - canceled before fetch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := cs.GetConsortiumWithContext(ctx, "foo.bar", "foo.bar")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, 0, callCount)
+
+		_, err = cs.GetStakeholderWithContext(ctx, "bar.baz", "bar.baz")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("success - fetch with context is cached", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		conf, err := cs.GetConsortiumWithContext(ctx, "foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, "foo.bar", conf.Config.Domain)
+
+		conf, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, "foo.bar", conf.Config.Domain)
+		require.Equal(t, 1, callCount)
+	})
+
+	t.Run("success - cached value with done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		conf, err := cs.GetConsortiumWithContext(ctx, "foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, "foo.bar", conf.Config.Domain)
+		require.Equal(t, 1, callCount)
+	})
+}
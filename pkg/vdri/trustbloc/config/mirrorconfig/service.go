@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirrorconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
+)
+
+// ConfigService fetches consortium configs using a wrapped config service. If a consortium domain is unreachable,
+// the config is fetched from the stakeholders listed in the last config fetched for the consortium, as stakeholders
+// are required to mirror it. Mirrored configs are verified like the original ones by the services wrapping this one.
+type ConfigService struct {
+	config config.Service
+	rand   random.Source
+	logger log.Logger
+
+	// stakeholder domains of the last config fetched, by consortium domain
+	mirrors      map[string][]string
+	mirrorsMutex sync.RWMutex
+}
+
+// NewService create new ConfigService
+func NewService(wrapped config.Service, opts ...Option) *ConfigService {
+	configService := &ConfigService{
+		config:  wrapped,
+		mirrors: map[string][]string{},
+	}
+
+	for _, opt := range opts {
+		opt(configService)
+	}
+
+	configService.rand = random.OrDefault(configService.rand)
+	configService.logger = log.OrDefault(configService.logger)
+
+	return configService
+}
+
+// Option is a config service instance option
+type Option func(opts *ConfigService)
+
+// WithRandomSource option sets the source of randomness the order the stakeholder mirrors of a consortium config
+// are tried in is drawn from, eg. a seeded source for reproducible tests. Defaults to random.Default().
+func WithRandomSource(source random.Source) Option {
+	return func(opts *ConfigService) {
+		opts.rand = source
+	}
+}
+
+// GetConsortium fetches the consortium config at the given url, falling back to the stakeholder mirrors when
+// fetching it from the consortium domain fails
+func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
+
+// GetConsortiumWithContext fetches the consortium config at the given url like GetConsortium, aborting the fetches
+// when the context is done
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	consortiumData, err := config.GetConsortium(ctx, cs.config, url, domain)
+	// configs fetched from stakeholders, eg. to compare them with the original, aren't mirrored
+	if url != domain {
+		return consortiumData, err
+	}
+
+	if err == nil {
+		cs.setMirrors(domain, consortiumData)
+
+		return consortiumData, nil
+	}
+
+	// the mirrors aren't tried once the context is done
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	mirrors := cs.getMirrors(domain)
+
+	for _, i := range cs.rand.Perm(len(mirrors)) {
+		mirrorData, mirrorErr := config.GetConsortium(ctx, cs.config, mirrors[i], domain)
+		if mirrorErr != nil {
+			cs.logger.Warnf("stakeholder %s failed to return mirrored consortium config of %s: %v", mirrors[i], domain,
+				mirrorErr)
+
+			continue
+		}
+
+		cs.logger.Warnf("failed to fetch consortium config of %s, using the config mirrored by %s: %v", domain,
+			mirrors[i], err)
+
+		cs.setMirrors(domain, mirrorData)
+
+		return mirrorData, nil
+	}
+
+	if len(mirrors) > 0 {
+		return nil, fmt.Errorf("consortium config and all its mirrors are unavailable: %w", err)
+	}
+
+	return nil, err
+}
+
+// GetStakeholder returns the stakeholder config file fetched by the wrapped config service
+func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
+	return cs.config.GetStakeholder(url, domain)
+}
+
+// GetStakeholderWithContext returns the stakeholder config file fetched by the wrapped config service, aborting
+// the fetch when the context is done
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	return config.GetStakeholder(ctx, cs.config, url, domain)
+}
+
+func (cs *ConfigService) getMirrors(domain string) []string {
+	cs.mirrorsMutex.RLock()
+	defer cs.mirrorsMutex.RUnlock()
+
+	return cs.mirrors[domain]
+}
+
+func (cs *ConfigService) setMirrors(domain string, consortiumData *models.ConsortiumFileData) {
+	if consortiumData == nil || consortiumData.Config == nil {
+		return
+	}
+
+	mirrors := make([]string, 0, len(consortiumData.Config.Members))
+
+	for _, member := range consortiumData.Config.Members {
+		if member != nil && member.Domain != "" {
+			mirrors = append(mirrors, member.Domain)
+		}
+	}
+
+	cs.mirrorsMutex.Lock()
+	defer cs.mirrorsMutex.Unlock()
+
+	cs.mirrors[domain] = mirrors
+}
+
+// WithLogger option sets the logger of the config service. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *ConfigService) {
+		opts.logger = logger
+	}
+}
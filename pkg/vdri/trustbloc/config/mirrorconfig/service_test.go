@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mirrorconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestConfigService_GetConsortium(t *testing.T) {
+	consortiumData := &models.ConsortiumFileData{Config: mockmodels.DummyConsortium("foo.bar",
+		[]*models.StakeholderListElement{{Domain: "bar.baz"}, {Domain: "baz.qux"}})}
+
+	t.Run("success - consortium domain", func(t *testing.T) {
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return consortiumData, nil
+			}})
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, consortiumData, conf)
+		require.ElementsMatch(t, []string{"bar.baz", "baz.qux"}, cs.getMirrors("foo.bar"))
+	})
+
+	t.Run("success - fallback to mirror", func(t *testing.T) {
+		consortiumDown := false
+
+		var fetched []string
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				fetched = append(fetched, u)
+
+				switch {
+				case u == "foo.bar" && consortiumDown:
+					return nil, fmt.Errorf("consortium unreachable")
+				case u == "bar.baz":
+					return nil, fmt.Errorf("mirror unreachable")
+				}
+
+				return consortiumData, nil
+			}})
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+
+		consortiumDown = true
+		fetched = nil
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, consortiumData, conf)
+		require.Equal(t, "foo.bar", fetched[0])
+		require.Equal(t, "baz.qux", fetched[len(fetched)-1])
+	})
+
+	t.Run("failure - no known mirrors", func(t *testing.T) {
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("consortium unreachable")
+			}})
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.EqualError(t, err, "consortium unreachable")
+	})
+
+	t.Run("failure - all mirrors unavailable", func(t *testing.T) {
+		calls := 0
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				calls++
+				if calls == 1 {
+					return consortiumData, nil
+				}
+
+				return nil, fmt.Errorf("%s unreachable", u)
+			}})
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+
+		_, err = cs.GetConsortium("foo.bar", "foo.bar")
+		require.EqualError(t, err, "consortium config and all its mirrors are unavailable: foo.bar unreachable")
+	})
+
+	t.Run("failure - mirrors aren't tried once the context is done", func(t *testing.T) {
+		var fetched []string
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				fetched = append(fetched, u)
+
+				return consortiumData, nil
+			}})
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = cs.GetConsortiumWithContext(ctx, "foo.bar", "foo.bar")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, []string{"foo.bar"}, fetched)
+	})
+
+	t.Run("stakeholder copies aren't mirrored", func(t *testing.T) {
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				if u == "bar.baz" {
+					return nil, fmt.Errorf("stakeholder unreachable")
+				}
+
+				return consortiumData, nil
+			}})
+
+		_, err := cs.GetConsortium("bar.baz", "foo.bar")
+		require.EqualError(t, err, "stakeholder unreachable")
+		require.Empty(t, cs.getMirrors("foo.bar"))
+	})
+}
+
+func TestConfigService_GetStakeholder(t *testing.T) {
+	cs := NewService(&mockconfig.MockConfigService{
+		GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+			return &models.StakeholderFileData{Config: &models.Stakeholder{Domain: d}}, nil
+		}})
+
+	conf, err := cs.GetStakeholder("bar.baz", "bar.baz")
+	require.NoError(t, err)
+	require.Equal(t, "bar.baz", conf.Config.Domain)
+}
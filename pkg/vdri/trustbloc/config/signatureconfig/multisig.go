@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signatureconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/square/go-jose/v3"
+)
+
+const compactJWSParts = 3
+
+type jwsSignature struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+type jwsJSON struct {
+	Payload    string         `json:"payload"`
+	Signatures []jwsSignature `json:"signatures"`
+}
+
+// SignDetached signs a config file payload with a single stakeholder key, returning the signature
+// in detached compact form (protected header..signature), so that it can be produced independently
+// of the other stakeholders and later combined using AssembleJWS.
+func SignDetached(payload []byte, key *jose.SigningKey) (string, error) {
+	if key == nil {
+		return "", errors.New("signing key is nil")
+	}
+
+	signer, err := jose.NewSigner(*key, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create signer: %w", err)
+	}
+
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign payload: %w", err)
+	}
+
+	return jws.DetachedCompactSerialize()
+}
+
+// AssembleJWS combines detached signatures over the same payload, collected from multiple stakeholders,
+// into a single multi-signature JWS in JSON serialization, as verified by the signatureconfig ConfigService.
+// Duplicate signatures are ignored.
+func AssembleJWS(payload []byte, detachedSignatures ...string) (string, error) {
+	if len(detachedSignatures) == 0 {
+		return "", errors.New("no signatures to assemble")
+	}
+
+	assembled := jwsJSON{Payload: base64.RawURLEncoding.EncodeToString(payload)}
+	seen := make(map[string]bool)
+
+	for i, sig := range detachedSignatures {
+		parts := strings.Split(strings.TrimSpace(sig), ".")
+		if len(parts) != compactJWSParts || parts[1] != "" {
+			return "", fmt.Errorf("signature %d is not a detached compact JWS", i)
+		}
+
+		if _, err := jose.ParseDetached(sig, payload); err != nil {
+			return "", fmt.Errorf("failed to parse signature %d: %w", i, err)
+		}
+
+		if seen[parts[2]] {
+			continue
+		}
+
+		seen[parts[2]] = true
+
+		assembled.Signatures = append(assembled.Signatures, jwsSignature{Protected: parts[0], Signature: parts[2]})
+	}
+
+	jwsBytes, err := json.Marshal(assembled)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal assembled jws: %w", err)
+	}
+
+	// sanity check that the result parses as a JWS
+	if _, err := jose.ParseSigned(string(jwsBytes)); err != nil {
+		return "", fmt.Errorf("failed to parse assembled jws: %w", err)
+	}
+
+	return string(jwsBytes), nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signatureconfig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func newStakeholder(t *testing.T, domain string) (*models.StakeholderListElement, *jose.SigningKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := (&jose.JSONWebKey{Key: pub, KeyID: domain}).MarshalJSON()
+	require.NoError(t, err)
+
+	return &models.StakeholderListElement{Domain: domain, PublicKey: models.PublicKey{JWK: jwk}},
+		&jose.SigningKey{Key: priv, Algorithm: jose.EdDSA}
+}
+
+func TestAssembleJWS(t *testing.T) {
+	member1, key1 := newStakeholder(t, "stakeholder.one")
+	member2, key2 := newStakeholder(t, "stakeholder.two")
+
+	config := &models.Consortium{Domain: "consortium.net", Members: []*models.StakeholderListElement{member1, member2}}
+
+	payload, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	sig1, err := SignDetached(payload, key1)
+	require.NoError(t, err)
+
+	sig2, err := SignDetached(payload, key2)
+	require.NoError(t, err)
+
+	t.Run("success - assembled jws is verified by all stakeholders", func(t *testing.T) {
+		jws, err := AssembleJWS(payload, sig1, sig2, sig1)
+		require.NoError(t, err)
+
+		fileData, err := models.ParseConsortium([]byte(jws))
+		require.NoError(t, err)
+		require.Len(t, fileData.JWS.Signatures, 2)
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(string, string) (*models.ConsortiumFileData, error) {
+				return fileData, nil
+			},
+		})
+
+		_, err = cs.GetConsortium("consortium.net", "consortium.net")
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - missing stakeholder signature", func(t *testing.T) {
+		jws, err := AssembleJWS(payload, sig1)
+		require.NoError(t, err)
+
+		fileData, err := models.ParseConsortium([]byte(jws))
+		require.NoError(t, err)
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(string, string) (*models.ConsortiumFileData, error) {
+				return fileData, nil
+			},
+		})
+
+		_, err = cs.GetConsortium("consortium.net", "consortium.net")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "insufficient stakeholder endorsement")
+	})
+
+	t.Run("failure - no signatures", func(t *testing.T) {
+		_, err := AssembleJWS(payload)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no signatures to assemble")
+	})
+
+	t.Run("failure - signature is not detached", func(t *testing.T) {
+		_, err := AssembleJWS(payload, "a.b.c")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature 0 is not a detached compact JWS")
+	})
+
+	t.Run("failure - malformed signature", func(t *testing.T) {
+		_, err := AssembleJWS(payload, "a..c")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse signature 0")
+	})
+
+	t.Run("failure - nil signing key", func(t *testing.T) {
+		_, err := SignDetached(payload, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signing key is nil")
+	})
+
+	t.Run("failure - bad signing key", func(t *testing.T) {
+		_, err := SignDetached(payload, &jose.SigningKey{Key: "bad", Algorithm: jose.EdDSA})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create signer")
+	})
+}
@@ -6,35 +6,57 @@ SPDX-License-Identifier: Apache-2.0
 package signatureconfig
 
 import (
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"math/rand"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/bluele/gcache"
 	"github.com/square/go-jose/v3"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
 )
 
-type config interface {
-	GetConsortium(string, string) (*models.ConsortiumFileData, error)
-	GetStakeholder(string, string) (*models.StakeholderFileData, error)
-}
+const verifiedCacheSize = 100
 
-// ConfigService fetches consortium and stakeholder configs over http
+// ConfigService verifies the stakeholder signatures of consortium configs fetched by a wrapped config service
 type ConfigService struct {
-	config config
+	config   config.Service
+	verified gcache.Cache
+	algs     []jose.SignatureAlgorithm
+	warn     models.WarningHandler
+	rand     random.Source
+	logger   log.Logger
 }
 
 // NewService create new ConfigService
-func NewService(config config) *ConfigService {
-	configService := &ConfigService{config: config}
+func NewService(wrapped config.Service, opts ...Option) *ConfigService {
+	configService := &ConfigService{config: wrapped, verified: gcache.New(verifiedCacheSize).LRU().Build()}
+
+	for _, opt := range opts {
+		opt(configService)
+	}
+
+	configService.rand = random.OrDefault(configService.rand)
+	configService.logger = log.OrDefault(configService.logger)
 
 	return configService
 }
 
 // GetConsortium fetches and parses the consortium file at the given domain
 func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
-	consortiumData, err := cs.config.GetConsortium(url, domain)
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
+
+// GetConsortiumWithContext fetches and parses the consortium file at the given domain, aborting the fetch when the
+// context is done
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	consortiumData, err := config.GetConsortium(ctx, cs.config, url, domain)
 	if err != nil {
 		return nil, fmt.Errorf("wrapped config service: %w", err)
 	}
@@ -44,33 +66,67 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 		return nil, fmt.Errorf("consortium is nil")
 	}
 
-	n := consortium.Policy.NumQueries
-	if n == 0 || n > len(consortium.Members) {
-		n = len(consortium.Members)
+	var signed []byte
+
+	switch {
+	case consortiumData.COSE != nil:
+		signed = consortiumData.COSE.Bytes()
+	case consortiumData.JWS != nil:
+		signed = []byte(consortiumData.JWS.FullSerialize())
+	default:
+		return nil, fmt.Errorf("consortium jws is nil")
+	}
+
+	// identical config files, eg. fetched from mirrors, are only verified once
+	hash := sha256.Sum256(signed)
+	if cs.verified.Has(hash) {
+		return consortiumData, nil
+	}
+
+	if err := cs.verifyConsortium(consortiumData); err != nil {
+		return nil, err
 	}
 
-	perm := rand.Perm(len(consortium.Members))
+	if err := cs.verified.Set(hash, true); err != nil {
+		cs.logger.Warnf("failed to memoize consortium verification: %v", err)
+	}
+
+	return consortiumData, nil
+}
+
+func (cs *ConfigService) verifyConsortium(consortiumData *models.ConsortiumFileData) error {
+	consortium := consortiumData.Config
+
+	n := policy.New(consortium).NumStakeholderQueries()
+
+	perm := cs.rand.Perm(len(consortium.Members))
 	verifiedCount := 0
 	verificationErrors := ""
 
+	var warnings []*models.VerificationWarning
+
+	fail := func(stakeholder string, err error) {
+		msg := err.Error() + " for stakeholder: " + stakeholder
+		cs.logger.Warnf("%s", msg)
+		verificationErrors += msg + ", "
+
+		warnings = append(warnings, &models.VerificationWarning{
+			Consortium: consortium.Domain, Stakeholder: stakeholder, Check: models.CheckEndorsement, Err: err,
+		})
+	}
+
 	for i := 0; i < len(consortium.Members); i++ {
-		keyData := consortium.Members[perm[i]].PublicKey.JWK
+		member := consortium.Members[perm[i]]
 		key := jose.JSONWebKey{}
 
-		err := key.UnmarshalJSON(keyData)
-		if err != nil {
-			msg := "bad key for stakeholder: " + consortium.Members[perm[i]].Domain
-			log.Warn(msg)
-			verificationErrors += msg + ", "
+		if err := key.UnmarshalJSON(member.PublicKey.JWK); err != nil {
+			fail(member.Domain, errors.New("bad key"))
 
 			continue
 		}
 
-		_, _, _, err = consortiumData.JWS.VerifyMulti(key)
-		if err != nil {
-			msg := "key fails to verify for stakeholder: " + consortium.Members[perm[i]].Domain
-			log.Warn(msg)
-			verificationErrors += msg + ", "
+		if err := verifySignature(consortiumData, key, cs.algs); err != nil {
+			fail(member.Domain, err)
 
 			continue
 		}
@@ -83,15 +139,85 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 	}
 
 	if verifiedCount < n {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"insufficient stakeholder endorsement of consortium config file. errors are: [%s]",
 			verificationErrors)
 	}
 
-	return consortiumData, nil
+	cs.warn.Warn(warnings, verifiedCount, n)
+
+	return nil
+}
+
+// verifySignature verifies the JWS or COSE signature of a consortium file with a stakeholder key
+func verifySignature(consortiumData *models.ConsortiumFileData, key jose.JSONWebKey,
+	algs []jose.SignatureAlgorithm) error {
+	if consortiumData.COSE != nil {
+		if _, err := consortiumData.COSE.Verify(key.Key, algs...); err != nil {
+			return fmt.Errorf("key fails to verify (%w)", err)
+		}
+
+		return nil
+	}
+
+	_, sig, _, err := consortiumData.JWS.VerifyMulti(key)
+	if err != nil {
+		return errors.New("key fails to verify")
+	}
+
+	return checkSignature(&sig, algs)
+}
+
+func checkSignature(sig *jose.Signature, algs []jose.SignatureAlgorithm) error {
+	if err := models.CheckSignatureHeaders(sig); err != nil {
+		return err
+	}
+
+	return models.CheckSignatureAlgorithm(sig, algs)
 }
 
 // GetStakeholder returns the stakeholder config file fetched by the wrapped config service
 func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
 	return cs.config.GetStakeholder(url, domain)
 }
+
+// GetStakeholderWithContext returns the stakeholder config file fetched by the wrapped config service, aborting
+// the fetch when the context is done
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	return config.GetStakeholder(ctx, cs.config, url, domain)
+}
+
+// Option is a config service instance option
+type Option func(opts *ConfigService)
+
+// WithWarningHandler option sets a handler of the stakeholders that fail to endorse a consortium config, when
+// enough other stakeholders endorse it
+func WithWarningHandler(handler models.WarningHandler) Option {
+	return func(opts *ConfigService) {
+		opts.warn = handler
+	}
+}
+
+// WithSignatureAlgorithms option sets the JWS algorithms accepted for stakeholder signatures of consortium
+// configs. Defaults to models.DefaultSignatureAlgorithms.
+func WithSignatureAlgorithms(algs ...jose.SignatureAlgorithm) Option {
+	return func(opts *ConfigService) {
+		opts.algs = algs
+	}
+}
+
+// WithRandomSource option sets the source of randomness the stakeholders verifying a consortium config are sampled
+// with, eg. a seeded source for reproducible tests. Defaults to random.Default().
+func WithRandomSource(source random.Source) Option {
+	return func(opts *ConfigService) {
+		opts.rand = source
+	}
+}
+
+// WithLogger option sets the logger of the config service. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *ConfigService) {
+		opts.logger = logger
+	}
+}
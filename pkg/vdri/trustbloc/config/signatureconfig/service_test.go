@@ -6,6 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 package signatureconfig
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -28,7 +29,13 @@ func signConsortium(consortium *models.Consortium, keys ...jose.SigningKey) (*jo
 		return nil, err
 	}
 
-	return signer.Sign(consortiumBytes)
+	jws, err := signer.Sign(consortiumBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// round-trip so the signature headers are populated, as for configs fetched from a server
+	return jose.ParseSigned(jws.FullSerialize())
 }
 
 func TestConfigService_GetConsortium(t *testing.T) {
@@ -76,6 +83,84 @@ func TestConfigService_GetConsortium(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("success - COSE", func(t *testing.T) {
+		rawPubKey := []byte(`{
+  "kty": "OKP",
+  "kid": "key1",
+  "crv": "Ed25519",
+  "x": "bWRCy8DtNhRO3HdKTFB2eEG5Ac1J00D0DQPffOwtAD0"
+}`)
+
+		config := models.Consortium{
+			Members: []*models.StakeholderListElement{
+				{Domain: "stakeholder.one", PublicKey: models.PublicKey{JWK: json.RawMessage(rawPubKey)}},
+			},
+		}
+
+		msgBytes, err := models.SignCOSE([]byte("payload"), key.Key.(ed25519.PrivateKey))
+		require.NoError(t, err)
+
+		msg, err := models.ParseCOSE(msgBytes)
+		require.NoError(t, err)
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return &models.ConsortiumFileData{
+					Config: &config,
+					COSE:   msg,
+				}, nil
+			},
+		})
+
+		_, err = cs.GetConsortium("foo", "foo")
+		require.NoError(t, err)
+
+		cs = NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return &models.ConsortiumFileData{
+					Config: &config,
+					COSE:   msg,
+				}, nil
+			},
+		}, WithSignatureAlgorithms(jose.ES256))
+
+		_, err = cs.GetConsortium("foo", "foo")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key fails to verify (failed to verify COSE message: "+
+			"[signature algorithm 'EdDSA' is not allowed]) for stakeholder: stakeholder.one")
+	})
+
+	t.Run("failure: signature algorithm not allowed", func(t *testing.T) {
+		rawPubKey := []byte(`{
+  "kty": "OKP",
+  "kid": "key1",
+  "crv": "Ed25519",
+  "x": "bWRCy8DtNhRO3HdKTFB2eEG5Ac1J00D0DQPffOwtAD0"
+}`)
+
+		config := models.Consortium{
+			Members: []*models.StakeholderListElement{
+				{Domain: "stakeholder.one", PublicKey: models.PublicKey{JWK: json.RawMessage(rawPubKey)}},
+			},
+		}
+
+		sig, err := signConsortium(&config, sigKey)
+		require.NoError(t, err)
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return &models.ConsortiumFileData{
+					Config: &config,
+					JWS:    sig,
+				}, nil
+			},
+		}, WithSignatureAlgorithms(jose.ES256))
+
+		_, err = cs.GetConsortium("foo", "foo")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature algorithm 'EdDSA' is not allowed for stakeholder: stakeholder.one")
+	})
+
 	t.Run("failure: can't parse key", func(t *testing.T) {
 		rawPubKey := []byte(`[]`)
 
@@ -267,6 +352,44 @@ func TestConfigService_GetConsortium_MultiSig(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("success - warning for a stakeholder that doesn't endorse", func(t *testing.T) {
+		member1, key1 := newStakeholder(t, "stakeholder.one")
+		member2, _ := newStakeholder(t, "stakeholder.two")
+
+		config := models.Consortium{
+			Domain:  "consortium.net",
+			Members: []*models.StakeholderListElement{member1, member2},
+			Policy:  models.ConsortiumPolicy{NumQueries: 1},
+		}
+
+		sig, err := signConsortium(&config, *key1)
+		require.NoError(t, err)
+
+		var warnings []*models.VerificationWarning
+
+		// stakeholders are checked in random order, so stakeholder.two is only checked in about half the runs
+		for i := 0; i < 50; i++ {
+			cs := NewService(&mockconfig.MockConfigService{
+				GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+					return &models.ConsortiumFileData{Config: &config, JWS: sig}, nil
+				},
+			}, WithWarningHandler(func(warning *models.VerificationWarning) {
+				warnings = append(warnings, warning)
+			}))
+
+			_, err = cs.GetConsortium("consortium.net", "consortium.net")
+			require.NoError(t, err)
+		}
+
+		require.NotEmpty(t, warnings)
+		require.Equal(t, "consortium.net", warnings[0].Consortium)
+		require.Equal(t, "stakeholder.two", warnings[0].Stakeholder)
+		require.Equal(t, models.CheckEndorsement, warnings[0].Check)
+		require.EqualError(t, warnings[0].Err, "key fails to verify")
+		require.Equal(t, 1, warnings[0].Passed)
+		require.Equal(t, 1, warnings[0].Required)
+	})
+
 	t.Run("failure - one key is bad, and both need to verify", func(t *testing.T) {
 		rawPubKeys := [][]byte{[]byte(`{
   "kty": "OKP",
@@ -310,6 +433,46 @@ func TestConfigService_GetConsortium_MultiSig(t *testing.T) {
 	})
 }
 
+func TestConfigService_GetConsortium_Memoized(t *testing.T) {
+	t.Run("success: identical config file is verified once", func(t *testing.T) {
+		member, sigKey := newStakeholder(t, "stakeholder.one")
+
+		config := models.Consortium{Members: []*models.StakeholderListElement{member}}
+
+		sig, err := signConsortium(&config, *sigKey)
+		require.NoError(t, err)
+
+		fileData := &models.ConsortiumFileData{Config: &config, JWS: sig}
+
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return fileData, nil
+			},
+		})
+
+		_, err = cs.GetConsortium("foo", "foo")
+		require.NoError(t, err)
+
+		// a second verification of the same file would fail with this key
+		config.Members[0].PublicKey.JWK = json.RawMessage(`[]`)
+
+		_, err = cs.GetConsortium("mirror", "foo")
+		require.NoError(t, err)
+	})
+
+	t.Run("failure: missing jws", func(t *testing.T) {
+		cs := NewService(&mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return &models.ConsortiumFileData{Config: &models.Consortium{}}, nil
+			},
+		})
+
+		_, err := cs.GetConsortium("foo", "foo")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "consortium jws is nil")
+	})
+}
+
 func TestConfigService_GetStakeholder(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		cs := NewService(&mockconfig.MockConfigService{
@@ -7,36 +7,82 @@ package verifyingconfig
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"math/rand"
-
-	log "github.com/sirupsen/logrus"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
 )
 
-type config interface {
-	GetConsortium(string, string) (*models.ConsortiumFileData, error)
-	GetStakeholder(string, string) (*models.StakeholderFileData, error)
-}
-
 // ConfigService fetches consortium and stakeholder configs over http
 type ConfigService struct {
-	config config
+	config config.Service
+	quorum int
+	warn   models.WarningHandler
+	rand   random.Source
+	logger log.Logger
 }
 
 // NewService create new ConfigService
-func NewService(config config) *ConfigService {
+func NewService(wrapped config.Service, opts ...Option) *ConfigService {
 	configService := &ConfigService{
-		config: config,
+		config: wrapped,
 	}
 
+	for _, opt := range opts {
+		opt(configService)
+	}
+
+	configService.rand = random.OrDefault(configService.rand)
+	configService.logger = log.OrDefault(configService.logger)
+
 	return configService
 }
 
+// Option is a config service instance option
+type Option func(opts *ConfigService)
+
+// WithQuorum option makes the service trust the consortium config returned by at least quorum of the sources it's
+// fetched from (the given url and the queried stakeholders), even if it's not the one returned by the given url,
+// so that a single compromised origin is detected and outvoted. By default, the copies of all queried stakeholders
+// must match the config returned by the given url.
+func WithQuorum(quorum int) Option {
+	return func(opts *ConfigService) {
+		opts.quorum = quorum
+	}
+}
+
+// WithWarningHandler option sets a handler of the stakeholders whose copy of a consortium config is missing or
+// doesn't match the agreed one, when a quorum of sources agree
+func WithWarningHandler(handler models.WarningHandler) Option {
+	return func(opts *ConfigService) {
+		opts.warn = handler
+	}
+}
+
+// WithRandomSource option sets the source of randomness the stakeholders queried for their copy of a consortium
+// config are sampled with, eg. a seeded source for reproducible tests. Defaults to random.Default().
+func WithRandomSource(source random.Source) Option {
+	return func(opts *ConfigService) {
+		opts.rand = source
+	}
+}
+
 // GetConsortium fetches and parses the consortium file at the given domain
 func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFileData, error) {
-	consortiumData, err := cs.config.GetConsortium(url, domain)
+	return cs.GetConsortiumWithContext(context.Background(), url, domain)
+}
+
+// GetConsortiumWithContext fetches and parses the consortium file at the given domain, aborting the fetches of the
+// config and of the stakeholder copies when the context is done
+func (cs *ConfigService) GetConsortiumWithContext(ctx context.Context, url,
+	domain string) (*models.ConsortiumFileData, error) {
+	consortiumData, err := config.GetConsortium(ctx, cs.config, url, domain)
 	if err != nil {
 		return nil, fmt.Errorf("wrapped config service: %w", err)
 	}
@@ -46,14 +92,13 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 		return nil, fmt.Errorf("consortium is nil")
 	}
 
-	n := consortium.Policy.NumQueries
+	n := policy.New(consortium).NumStakeholderQueries()
 
-	// if ds.numStakeholders is 0, then we use all stakeholders
-	if n == 0 {
-		n = len(consortium.Members)
+	if cs.quorum > 0 {
+		return cs.quorumConsortium(ctx, consortiumData, domain, n)
 	}
 
-	perm := rand.Perm(len(consortium.Members))
+	perm := cs.rand.Perm(len(consortium.Members))
 
 	// number of stakeholders that have verified
 	verifiedCount := 0
@@ -63,16 +108,16 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 	for i := 0; i < n; i++ {
 		stakeholder := consortium.Members[perm[i]].Domain
 		// get consortium file from stakeholder server
-		file, err := cs.config.GetConsortium(stakeholder, domain)
+		file, err := config.GetConsortium(ctx, cs.config, stakeholder, domain)
 		if err != nil {
 			msg := "stakeholder peer failed to return consortium config: " + err.Error()
-			log.Warn(msg)
+			cs.logger.Warnf("%s", msg)
 			verificationErrors += msg + ", "
 
 			continue // skip failed stakeholders
 		}
 
-		if !bytes.Equal(file.JWS.UnsafePayloadWithoutVerification(), consortiumData.JWS.UnsafePayloadWithoutVerification()) {
+		if !bytes.Equal(file.Payload(), consortiumData.Payload()) {
 			verificationErrors += "stakeholder copy of consortium file does not match, "
 			continue
 		}
@@ -89,7 +134,89 @@ func (cs *ConfigService) GetConsortium(url, domain string) (*models.ConsortiumFi
 	return consortiumData, nil
 }
 
+// quorumConsortium returns the consortium config returned by most sources among the origin and n stakeholders,
+// if at least a quorum of them agree
+func (cs *ConfigService) quorumConsortium(ctx context.Context, origin *models.ConsortiumFileData,
+	domain string, n int) (*models.ConsortiumFileData, error) {
+	sources := []*models.ConsortiumFileData{origin}
+	stakeholders := []string{domain}
+
+	var warnings []*models.VerificationWarning
+
+	for _, i := range cs.rand.Perm(len(origin.Config.Members))[:n] {
+		stakeholder := origin.Config.Members[i].Domain
+
+		file, err := config.GetConsortium(ctx, cs.config, stakeholder, domain)
+		if err != nil {
+			cs.logger.Warnf("stakeholder peer failed to return consortium config: %v", err)
+
+			warnings = append(warnings, &models.VerificationWarning{
+				Consortium: domain, Stakeholder: stakeholder, Check: models.CheckMirror, Err: err,
+			})
+
+			continue
+		}
+
+		sources = append(sources, file)
+		stakeholders = append(stakeholders, stakeholder)
+	}
+
+	hashes := make([][sha256.Size]byte, len(sources))
+	votes := make(map[[sha256.Size]byte]int)
+
+	best := 0
+
+	for i, file := range sources {
+		hashes[i] = sha256.Sum256(file.Payload())
+
+		votes[hashes[i]]++
+
+		// the origin wins ties
+		if votes[hashes[i]] > votes[hashes[best]] {
+			best = i
+		}
+	}
+
+	agreed := votes[hashes[best]]
+
+	if agreed < cs.quorum {
+		return nil, fmt.Errorf("insufficient agreement on consortium config file: %d of %d sources agree, quorum is %d",
+			agreed, len(sources), cs.quorum)
+	}
+
+	if best != 0 {
+		cs.logger.Warnf("consortium config of %s returned by the origin is outvoted by its stakeholders", domain)
+	}
+
+	for i := 1; i < len(sources); i++ {
+		if hashes[i] != hashes[best] {
+			warnings = append(warnings, &models.VerificationWarning{
+				Consortium: domain, Stakeholder: stakeholders[i], Check: models.CheckMirror,
+				Err: errors.New("stakeholder copy of consortium file does not match"),
+			})
+		}
+	}
+
+	cs.warn.Warn(warnings, agreed, cs.quorum)
+
+	return sources[best], nil
+}
+
 // GetStakeholder returns the stakeholder config file fetched by the wrapped config service
 func (cs *ConfigService) GetStakeholder(url, domain string) (*models.StakeholderFileData, error) {
 	return cs.config.GetStakeholder(url, domain)
 }
+
+// GetStakeholderWithContext returns the stakeholder config file fetched by the wrapped config service, aborting
+// the fetch when the context is done
+func (cs *ConfigService) GetStakeholderWithContext(ctx context.Context, url,
+	domain string) (*models.StakeholderFileData, error) {
+	return config.GetStakeholder(ctx, cs.config, url, domain)
+}
+
+// WithLogger option sets the logger of the config service. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *ConfigService) {
+		opts.logger = logger
+	}
+}
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 package verifyingconfig
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -131,7 +133,8 @@ func TestConfigService_GetConsortium(t *testing.T) {
 		consortiumFile, err = mockmodels.WrapConsortium(consortium)
 		require.NoError(t, err)
 
-		cs := NewService(httpconfig.NewService())
+		// the disagreeing stakeholder is the one queried
+		cs := NewService(httpconfig.NewService(), WithRandomSource(reversedSource{}))
 
 		_, err = cs.GetConsortium(cServ.URL, "foo.bar")
 		require.Error(t, err)
@@ -160,6 +163,103 @@ func TestConfigService_GetConsortium(t *testing.T) {
 	})
 }
 
+func TestConfigService_GetConsortiumQuorum(t *testing.T) {
+	members := []*models.StakeholderListElement{{Domain: "s1"}, {Domain: "s2"}, {Domain: "s3"}}
+
+	consortiumData := dummyConsortiumData(t, "foo.bar", members)
+	otherData := dummyConsortiumData(t, "other", members)
+
+	// copies returns a config service returning the configs of the origin and stakeholders
+	copies := func(files map[string]*models.ConsortiumFileData) *mockconfig.MockConfigService {
+		return &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				file, ok := files[u]
+				if !ok {
+					return nil, fmt.Errorf("%s unreachable", u)
+				}
+
+				return file, nil
+			}}
+	}
+
+	t.Run("success - all sources agree", func(t *testing.T) {
+		cs := NewService(copies(map[string]*models.ConsortiumFileData{
+			"foo.bar": consortiumData, "s1": consortiumData, "s2": consortiumData, "s3": consortiumData,
+		}), WithQuorum(4))
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, consortiumData, conf)
+	})
+
+	t.Run("success - quorum despite a disagreeing and an unreachable stakeholder", func(t *testing.T) {
+		warnings := map[string]*models.VerificationWarning{}
+
+		cs := NewService(copies(map[string]*models.ConsortiumFileData{
+			"foo.bar": consortiumData, "s1": consortiumData, "s2": otherData,
+		}), WithQuorum(2), WithWarningHandler(func(warning *models.VerificationWarning) {
+			warnings[warning.Stakeholder] = warning
+		}))
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, consortiumData, conf)
+
+		require.Len(t, warnings, 2)
+		require.EqualError(t, warnings["s2"].Err, "stakeholder copy of consortium file does not match")
+		require.Equal(t, models.CheckMirror, warnings["s3"].Check)
+		require.Equal(t, "foo.bar", warnings["s3"].Consortium)
+		require.Equal(t, 2, warnings["s3"].Passed)
+		require.Equal(t, 2, warnings["s3"].Required)
+	})
+
+	t.Run("success - compromised origin is outvoted", func(t *testing.T) {
+		cs := NewService(copies(map[string]*models.ConsortiumFileData{
+			"foo.bar": otherData, "s1": consortiumData, "s2": consortiumData, "s3": consortiumData,
+		}), WithQuorum(3))
+
+		conf, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.NoError(t, err)
+		require.Equal(t, "foo.bar", conf.Config.Domain)
+	})
+
+	t.Run("failure - no quorum", func(t *testing.T) {
+		cs := NewService(copies(map[string]*models.ConsortiumFileData{
+			"foo.bar": consortiumData, "s1": otherData,
+		}), WithQuorum(2))
+
+		_, err := cs.GetConsortium("foo.bar", "foo.bar")
+		require.EqualError(t, err,
+			"insufficient agreement on consortium config file: 1 of 2 sources agree, quorum is 2")
+	})
+}
+
+func TestConfigService_Context(t *testing.T) {
+	cs := NewService(httpconfig.NewService())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cs.GetConsortiumWithContext(ctx, "https://foo.bar", "foo.bar")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	_, err = cs.GetStakeholderWithContext(ctx, "https://bar.baz", "bar.baz")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func dummyConsortiumData(t *testing.T, domain string,
+	members []*models.StakeholderListElement) *models.ConsortiumFileData {
+	file, err := mockmodels.DummyConsortiumJSON(domain, members)
+	require.NoError(t, err)
+
+	data, err := models.ParseConsortium([]byte(file))
+	require.NoError(t, err)
+
+	return data
+}
+
 func TestConfigService_GetStakeholder(t *testing.T) {
 	t.Run("pass through", func(t *testing.T) {
 		cs := NewService(&mockconfig.MockConfigService{
@@ -178,3 +278,19 @@ func TestConfigService_GetStakeholder(t *testing.T) {
 		require.Equal(t, conf.Config.Domain, "foo")
 	})
 }
+
+// reversedSource is a random source sampling the last elements first
+type reversedSource struct{}
+
+func (reversedSource) Intn(n int) int {
+	return n - 1
+}
+
+func (reversedSource) Perm(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = n - 1 - i
+	}
+
+	return perm
+}
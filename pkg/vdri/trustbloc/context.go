@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// The services set as options may accept a context, in which case their requests are aborted when the context of
+// the VDRI call is done. Services that don't are called as long as the context isn't done.
+
+type contextEndpointService interface {
+	GetEndpointsWithContext(ctx context.Context, domain string) ([]*models.Endpoint, error)
+}
+
+type contextDIDConfigService interface {
+	VerifyStakeholderWithContext(ctx context.Context, domain string, doc *docdid.Doc) error
+}
+
+type contextKeySetService interface {
+	KeySetWithContext(ctx context.Context, url string) (*jose.JSONWebKeySet, error)
+}
+
+type contextVDRI interface {
+	ReadWithContext(ctx context.Context, did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error)
+}
+
+func (v *VDRI) fetchEndpoints(ctx context.Context, domain string) ([]*models.Endpoint, error) {
+	if s, ok := v.endpointService.(contextEndpointService); ok {
+		return s.GetEndpointsWithContext(ctx, domain)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return v.endpointService.GetEndpoints(domain)
+}
+
+func (v *VDRI) verifyDIDConfiguration(ctx context.Context, domain string, doc *docdid.Doc) error {
+	if s, ok := v.didConfigService.(contextDIDConfigService); ok {
+		return s.VerifyStakeholderWithContext(ctx, domain, doc)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return v.didConfigService.VerifyStakeholder(domain, doc)
+}
+
+func (v *VDRI) fetchKeySet(ctx context.Context, url string) (*jose.JSONWebKeySet, error) {
+	if s, ok := v.keySetService.(contextKeySetService); ok {
+		return s.KeySetWithContext(ctx, url)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return v.keySetService.KeySet(url)
+}
+
+func readWithContext(ctx context.Context, resolver vdri, did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	if r, ok := resolver.(contextVDRI); ok {
+		return r.ReadWithContext(ctx, did, opts...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return resolver.Read(did, opts...)
+}
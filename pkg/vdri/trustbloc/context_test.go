@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_Context(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("read - resolver deadline exceeded", func(t *testing.T) {
+		release := make(chan struct{})
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer serv.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		v := New(WithResolverURL(serv.URL), WithAllowInsecureHTTP("127.0.0.1"))
+
+		_, err := v.ReadWithContext(ctx, "did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("read - resolver without context isn't called", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.ReadWithContext(canceled, "did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.EqualValues(t, 0, atomic.LoadInt32(&reads))
+
+		_, err = v.ReadWithContext(context.Background(), "did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.EqualValues(t, 1, atomic.LoadInt32(&reads))
+	})
+
+	t.Run("consortium validation and endpoints - canceled", func(t *testing.T) {
+		v := New(WithConfigService(mock.NewConfigService().WithConsortium("testnet",
+			&models.ConsortiumFileData{Config: &models.Consortium{Domain: "testnet"}})))
+
+		_, err := v.ValidateConsortiumWithContext(canceled, "testnet")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+
+		_, err = v.GetEndpointsWithContext(canceled, "testnet")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("build - canceled", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		v := New(WithBuildDomain("testnet"), WithEndpointService(mock.NewEndpointService().
+			WithEndpoints("testnet", &models.Endpoint{URL: "url"})),
+			WithOperationKeysHandler(func(string, *did.OperationKeys) error { return nil }))
+		v.setValidatedConsortium("testnet")
+
+		_, err = v.BuildWithContext(canceled, &vdriapi.PubKey{ID: "key1", Value: pubKey})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -7,18 +7,21 @@ SPDX-License-Identifier: Apache-2.0
 package didconfiguration
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	jose2 "github.com/hyperledger/aries-framework-go/pkg/doc/jose"
-	log "github.com/sirupsen/logrus"
 	"github.com/square/go-jose/v3"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
 
+const ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
+
 // CreateDIDConfiguration creates a DID Configuration asserting a given DID's ownership over a given domain
 //   using the given signing keys (which are assumed to belong to the DID)
 // Implements https://identity.foundation/specs/did-configuration/
@@ -75,15 +78,22 @@ func createDomainLinkageAssertion(
 
 // VerifyDIDConfiguration verifies a DID configuration, using the given VDRI to resolve the DID.
 //   returns a list of the DIDs that were successfully authenticated to this domain
-func VerifyDIDConfiguration(domain string, configuration *models.DIDConfiguration, doc *did.Doc) ([]string, error) {
+//   algs is the allowlist of JWS algorithms, models.DefaultSignatureAlgorithms if empty
+func VerifyDIDConfiguration(domain string, configuration *models.DIDConfiguration, doc *did.Doc,
+	algs ...jose.SignatureAlgorithm) ([]string, error) {
+	return verifyDIDConfiguration(log.Default(), domain, configuration, doc, algs...)
+}
+
+func verifyDIDConfiguration(logger log.Logger, domain string, configuration *models.DIDConfiguration, doc *did.Doc,
+	algs ...jose.SignatureAlgorithm) ([]string, error) {
 	didSet := map[string]struct{}{}
 
 	var errs []string
 
 	for i, dla := range configuration.Entries {
-		err := ValidateDomainLinkageAssertion(domain, dla, doc)
+		err := ValidateDomainLinkageAssertion(domain, dla, doc, algs...)
 		if err != nil {
-			log.Debugf("domain linkage assertion %v for %s invalid", i, domain)
+			logger.Debugf("domain linkage assertion %v for %s invalid", i, domain)
 
 			errs = append(errs, err.Error())
 		} else {
@@ -109,7 +119,8 @@ func VerifyDIDConfiguration(domain string, configuration *models.DIDConfiguratio
 }
 
 // ValidateDomainLinkageAssertion validates a domain linkage assertion, using the given VDRI to resolve the DID
-func ValidateDomainLinkageAssertion(domain string, assertion models.DomainLinkageAssertion, doc *did.Doc) error {
+func ValidateDomainLinkageAssertion(domain string, assertion models.DomainLinkageAssertion, doc *did.Doc,
+	algs ...jose.SignatureAlgorithm) error {
 	jws, err := jose.ParseSigned(assertion.JWT)
 	if err != nil {
 		return fmt.Errorf("cannot parse assertion JWT: %w", err)
@@ -136,13 +147,14 @@ func ValidateDomainLinkageAssertion(domain string, assertion models.DomainLinkag
 		return fmt.Errorf("assertion has expired")
 	}
 
-	_, err = VerifyDIDSignature(jws, doc)
+	_, err = VerifyDIDSignature(jws, doc, algs...)
 
 	return err
 }
 
-// VerifyDIDSignature verify a signature using a DID doc
-func VerifyDIDSignature(jws *jose.JSONWebSignature, doc *did.Doc) ([]byte, error) {
+// VerifyDIDSignature verify a signature using a DID doc, accepting only signatures with one of the given
+// JWS algorithms (models.DefaultSignatureAlgorithms if none are given)
+func VerifyDIDSignature(jws *jose.JSONWebSignature, doc *did.Doc, algs ...jose.SignatureAlgorithm) ([]byte, error) {
 	if jws == nil {
 		return nil, fmt.Errorf("jws is nil")
 	}
@@ -162,7 +174,17 @@ func VerifyDIDSignature(jws *jose.JSONWebSignature, doc *did.Doc) ([]byte, error
 			return nil, fmt.Errorf("key is nil")
 		}
 
-		val, err = jws.Verify(key.Key)
+		var sig jose.Signature
+
+		_, sig, val, err = jws.VerifyMulti(key.Key)
+		if err == nil {
+			err = models.CheckSignatureHeaders(&sig)
+		}
+
+		if err == nil {
+			err = models.CheckSignatureAlgorithm(&sig, algs)
+		}
+
 		if err == nil {
 			verified = true
 			break
@@ -185,11 +207,32 @@ func VerifyDIDSignature(jws *jose.JSONWebSignature, doc *did.Doc) ([]byte, error
 	return val, nil
 }
 
+// VerifyDIDCOSESignature verifies a COSE message using a DID doc, accepting only signatures with one of the given
+// algorithms (models.DefaultSignatureAlgorithms if none are given)
+func VerifyDIDCOSESignature(msg *models.COSEMessage, doc *did.Doc, algs ...jose.SignatureAlgorithm) ([]byte, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("cose message is nil")
+	}
+
+	errs := ""
+
+	for _, key := range getJWKs(doc) {
+		val, err := msg.Verify(key.Key, algs...)
+		if err == nil {
+			return val, nil
+		}
+
+		errs += err.Error() + ", "
+	}
+
+	return nil, fmt.Errorf("failed to verify: %s", errs)
+}
+
 func getJWKs(doc *did.Doc) []*jose2.JWK {
 	var jwkList []*jose2.JWK
 
-	for _, pk := range doc.PublicKey {
-		jwk := pk.JSONWebKey()
+	for i := range doc.PublicKey {
+		jwk := publicKeyJWK(&doc.PublicKey[i])
 		if jwk == nil || jwk.Key == nil {
 			continue
 		}
@@ -197,8 +240,8 @@ func getJWKs(doc *did.Doc) []*jose2.JWK {
 		jwkList = append(jwkList, jwk)
 	}
 
-	for _, method := range doc.Authentication {
-		jwk := method.PublicKey.JSONWebKey()
+	for i := range doc.Authentication {
+		jwk := publicKeyJWK(&doc.Authentication[i].PublicKey)
 		if jwk == nil || jwk.Key == nil {
 			continue
 		}
@@ -208,3 +251,17 @@ func getJWKs(doc *did.Doc) []*jose2.JWK {
 
 	return jwkList
 }
+
+// publicKeyJWK returns the JWK of a public key. Ed25519 keys given as raw bytes (eg. the keys of did:key docs)
+// are converted to a JWK so they can be used to verify signatures.
+func publicKeyJWK(pk *did.PublicKey) *jose2.JWK {
+	if jwk := pk.JSONWebKey(); jwk != nil {
+		return jwk
+	}
+
+	if pk.Type == ed25519VerificationKey2018 && len(pk.Value) == ed25519.PublicKeySize {
+		return &jose2.JWK{JSONWebKey: jose.JSONWebKey{Key: ed25519.PublicKey(pk.Value), KeyID: pk.ID}}
+	}
+
+	return nil
+}
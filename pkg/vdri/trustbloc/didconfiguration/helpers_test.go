@@ -7,10 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package didconfiguration
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/fingerprint"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/key"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 
@@ -167,6 +171,24 @@ func TestVerifyDIDConfiguration(t *testing.T) {
 		require.Contains(t, dids, "did:example:123456789abcdefghi")
 	})
 
+	t.Run("successful verification with did:key doc", func(t *testing.T) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		didKey, _ := fingerprint.CreateDIDKey(pub)
+
+		doc, err := key.New().Read(didKey)
+		require.NoError(t, err)
+
+		didConfig, err := CreateDIDConfiguration("domain.website", didKey, 0,
+			&jose.SigningKey{Algorithm: jose.EdDSA, Key: priv})
+		require.NoError(t, err)
+
+		dids, err := VerifyDIDConfiguration("domain.website", didConfig, doc)
+		require.NoError(t, err)
+		require.Equal(t, []string{didKey}, dids)
+	})
+
 	t.Run("failed verification", func(t *testing.T) {
 		dla := models.DomainLinkageAssertion{
 			DID: "did:example:123456789abcdefghi",
@@ -429,11 +451,18 @@ func TestVerifyDIDSignature(t *testing.T) {
 		signer, err := jose.NewSigner(sigKey, nil)
 		require.NoError(t, err)
 
-		jws, err := signer.Sign(claimsBytes)
+		signed, err := signer.Sign(claimsBytes)
+		require.NoError(t, err)
+
+		jws, err := jose.ParseSigned(signed.FullSerialize())
 		require.NoError(t, err)
 
 		_, err = VerifyDIDSignature(jws, doc)
 		require.NoError(t, err)
+
+		_, err = VerifyDIDSignature(jws, doc, jose.ES256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature algorithm 'EdDSA' is not allowed")
 	})
 
 	t.Run("failure - doc has no keys, can't authenticate", func(t *testing.T) {
@@ -473,3 +502,40 @@ func TestVerifyDIDSignature(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to verify")
 	})
 }
+
+func TestVerifyDIDCOSESignature(t *testing.T) {
+	var key jose.JSONWebKey
+	err := key.UnmarshalJSON([]byte(keyJSON))
+	require.NoError(t, err)
+
+	doc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	signed, err := models.SignCOSE([]byte("payload"), key.Key.(ed25519.PrivateKey))
+	require.NoError(t, err)
+
+	msg, err := models.ParseCOSE(signed)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		payload, err := VerifyDIDCOSESignature(msg, doc)
+		require.NoError(t, err)
+		require.Equal(t, []byte("payload"), payload)
+	})
+
+	t.Run("failure - algorithm not allowed", func(t *testing.T) {
+		_, err := VerifyDIDCOSESignature(msg, doc, jose.ES256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature algorithm 'EdDSA' is not allowed")
+	})
+
+	t.Run("failure - doc has no keys", func(t *testing.T) {
+		_, err := VerifyDIDCOSESignature(msg, &did.Doc{})
+		require.EqualError(t, err, "failed to verify: ")
+	})
+
+	t.Run("failure - nil message", func(t *testing.T) {
+		_, err := VerifyDIDCOSESignature(nil, doc)
+		require.EqualError(t, err, "cose message is nil")
+	})
+}
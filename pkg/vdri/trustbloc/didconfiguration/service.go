@@ -7,47 +7,66 @@ SPDX-License-Identifier: Apache-2.0
 package didconfiguration
 
 import (
+	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/square/go-jose/v3"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
 
 // Service fetches and verifies DID-configurations
 type Service struct {
-	httpClient *http.Client
-	tlsConfig  *tls.Config
+	httpClient      *http.Client
+	tlsConfig       *tls.Config
+	transport       http.RoundTripper
+	algs            []jose.SignatureAlgorithm
+	maxResponseSize int64
+	logger          log.Logger
 }
 
 // NewService create new didconfiguration Service
 func NewService(opts ...Option) *Service {
 	service := &Service{
-		httpClient: &http.Client{},
+		httpClient:      &http.Client{},
+		maxResponseSize: transport.DefaultMaxResponseSize,
 	}
 
 	for _, opt := range opts {
 		opt(service)
 	}
 
-	service.httpClient.Transport = &http.Transport{TLSClientConfig: service.tlsConfig}
+	if service.transport == nil {
+		service.transport = &http.Transport{TLSClientConfig: tlspolicy.Apply(service.tlsConfig)}
+	}
+
+	service.httpClient.Transport = service.transport
+	service.logger = log.OrDefault(service.logger)
 
 	return service
 }
 
 // VerifyStakeholder verify the DID configuration on a stakeholder server
 func (s *Service) VerifyStakeholder(domain string, doc *did.Doc) error {
-	conf, err := s.getConfiguration(domain)
+	return s.VerifyStakeholderWithContext(context.Background(), domain, doc)
+}
+
+// VerifyStakeholderWithContext verify the DID configuration on a stakeholder server, aborting the request when the
+// context is done
+func (s *Service) VerifyStakeholderWithContext(ctx context.Context, domain string, doc *did.Doc) error {
+	conf, err := s.getConfiguration(ctx, domain)
 	if err != nil {
 		return fmt.Errorf("can't get stakeholder `%s` did configuration: %w", domain, err)
 	}
 
-	_, err = VerifyDIDConfiguration(domain, conf, doc)
+	_, err = verifyDIDConfiguration(s.logger, domain, conf, doc, s.algs...)
 	if err != nil {
 		return fmt.Errorf("stakeholder did configuration invalid: %w", err)
 	}
@@ -55,7 +74,7 @@ func (s *Service) VerifyStakeholder(domain string, doc *did.Doc) error {
 	return nil
 }
 
-func (s *Service) getConfiguration(domain string) (*models.DIDConfiguration, error) {
+func (s *Service) getConfiguration(ctx context.Context, domain string) (*models.DIDConfiguration, error) {
 	var url string
 	if strings.HasPrefix(domain, "http") {
 		url = domain
@@ -65,7 +84,12 @@ func (s *Service) getConfiguration(domain string) (*models.DIDConfiguration, err
 
 	url += "/.well-known/did-configuration.json"
 
-	res, err := s.httpClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -73,9 +97,9 @@ func (s *Service) getConfiguration(domain string) (*models.DIDConfiguration, err
 	// nolint: errcheck
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := transport.ReadLimited(res.Body, s.maxResponseSize)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read did configuration at url %s: %w", url, err)
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -83,14 +107,12 @@ func (s *Service) getConfiguration(domain string) (*models.DIDConfiguration, err
 		return nil, fmt.Errorf("stakeholder did-configuration request failed: error %d, `%s`", res.StatusCode, string(body))
 	}
 
-	var didConfig models.DIDConfiguration
-
-	err = json.Unmarshal(body, &didConfig)
+	didConfig, err := models.ParseDIDConfiguration(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse did configurations from body `%s` at url %s: %w", string(body), url, err)
 	}
 
-	return &didConfig, nil
+	return didConfig, nil
 }
 
 // Option is a didconfiguration service instance option
@@ -102,3 +124,34 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 		opts.tlsConfig = tlsConfig
 	}
 }
+
+// WithTransport option sets the http transport used to fetch files, allowing a connection pool to be shared
+// with other services. If set, the tls.Config option is ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *Service) {
+		opts.transport = transport
+	}
+}
+
+// WithSignatureAlgorithms option sets the JWS algorithms accepted for domain linkage assertions.
+// Defaults to models.DefaultSignatureAlgorithms.
+func WithSignatureAlgorithms(algs ...jose.SignatureAlgorithm) Option {
+	return func(opts *Service) {
+		opts.algs = algs
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size of a did-configuration, in bytes. Larger files are refused
+// without being read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *Service) {
+		opts.maxResponseSize = max
+	}
+}
+
+// WithLogger option sets the logger of the service. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *Service) {
+		opts.logger = logger
+	}
+}
@@ -7,12 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package didconfiguration
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/square/go-jose/v3"
@@ -75,6 +78,19 @@ func TestService_VerifyStakeholder(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to parse did configuration")
 	})
 
+	t.Run("failure - config file too large", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"entries":[]}`)
+		}))
+		defer serv.Close()
+
+		s := NewService(WithMaxResponseSize(10))
+
+		err := s.VerifyStakeholder(serv.URL, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "response body too large: more than 10 bytes")
+	})
+
 	t.Run("failure - configuration fails verification", func(t *testing.T) {
 		var key jose.JSONWebKey
 		err := key.UnmarshalJSON([]byte(keyJSON))
@@ -104,6 +120,26 @@ func TestService_VerifyStakeholder(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "did configuration invalid")
 	})
+
+	t.Run("failure - context deadline exceeded", func(t *testing.T) {
+		release := make(chan struct{})
+
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer serv.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := NewService().VerifyStakeholderWithContext(ctx, serv.URL, nil)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
 }
 
 func TestOpts(t *testing.T) {
@@ -7,23 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package staticdiscovery
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
 )
 
-type config interface {
-	GetConsortium(url, domain string) (*models.ConsortiumFileData, error)
-	GetStakeholder(url, domain string) (*models.StakeholderFileData, error)
-}
-
 // DiscoveryService fetches endpoints for a consortium
 type DiscoveryService struct {
-	config config
+	config config.Service
 }
 
 // NewService create new DiscoveryService
-func NewService(c config) *DiscoveryService {
+func NewService(c config.Service) *DiscoveryService {
 	endpointService := &DiscoveryService{
 		config: c,
 	}
@@ -33,7 +30,14 @@ func NewService(c config) *DiscoveryService {
 
 // GetEndpoints get a list of endpoints to use from a consortium domain
 func (ds *DiscoveryService) GetEndpoints(consortiumDomain string) ([]*models.Endpoint, error) {
-	consortiumData, err := ds.config.GetConsortium(consortiumDomain, consortiumDomain)
+	return ds.GetEndpointsWithContext(context.Background(), consortiumDomain)
+}
+
+// GetEndpointsWithContext get a list of endpoints to use from a consortium domain, aborting the config fetches when
+// the context is done
+func (ds *DiscoveryService) GetEndpointsWithContext(ctx context.Context,
+	consortiumDomain string) ([]*models.Endpoint, error) {
+	consortiumData, err := config.GetConsortium(ctx, ds.config, consortiumDomain, consortiumDomain)
 	if err != nil {
 		return nil, fmt.Errorf("getting consortium: %w", err)
 	}
@@ -43,7 +47,7 @@ func (ds *DiscoveryService) GetEndpoints(consortiumDomain string) ([]*models.End
 		return nil, fmt.Errorf("consortium config is nil")
 	}
 
-	stakeholders, err := ds.getStakeholderConfigs(consortium)
+	stakeholders, err := ds.getStakeholderConfigs(ctx, consortium)
 	if err != nil {
 		return nil, fmt.Errorf("stakeholder config: %w", err)
 	}
@@ -52,11 +56,12 @@ func (ds *DiscoveryService) GetEndpoints(consortiumDomain string) ([]*models.End
 }
 
 // getStakeholderConfigs gets the list of stakeholder configs
-func (ds *DiscoveryService) getStakeholderConfigs(consortium *models.Consortium) ([]models.StakeholderFileData, error) { // nolint: lll
+func (ds *DiscoveryService) getStakeholderConfigs(ctx context.Context,
+	consortium *models.Consortium) ([]models.StakeholderFileData, error) {
 	var stakeholders []models.StakeholderFileData
 
 	for _, s := range consortium.Members {
-		stakeholderConfig, err := ds.config.GetStakeholder(s.Domain, s.Domain)
+		stakeholderConfig, err := config.GetStakeholder(ctx, ds.config, s.Domain, s.Domain)
 		if err != nil {
 			return nil, err
 		}
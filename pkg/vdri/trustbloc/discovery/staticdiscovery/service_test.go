@@ -6,7 +6,9 @@ SPDX-License-Identifier: Apache-2.0
 package staticdiscovery
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
 	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/httpconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
@@ -84,3 +87,19 @@ func TestDiscoveryService_GetEndpoints(t *testing.T) {
 		require.Contains(t, err.Error(), "stakeholder config request failed")
 	})
 }
+
+func TestDiscoveryService_GetEndpointsWithContext(t *testing.T) {
+	s := NewService(&mockconfig.MockConfigService{
+		GetConsortiumFunc: func(string, string) (*models.ConsortiumFileData, error) {
+			return &models.ConsortiumFileData{Config: mockmodels.DummyConsortium("foo.bar",
+				[]*models.StakeholderListElement{{Domain: "bar.baz"}})}, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.GetEndpointsWithContext(ctx, "foo.bar")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/workerpool"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+const defaultResolutionCacheSize = 1000
+
+// ResolutionCache stores the DID docs of the resolution cache, eg. in a store shared by several resolver instances.
+// Docs are keyed by DID, prefixed with the tenant and domain of the VDRI that resolved them, so a cache shared by
+// tenants never serves a doc resolved by one tenant to another. Docs are stored with their expiry time: expired
+// docs should be kept as long as possible, so they can be served while being refreshed. Implementations must be
+// safe for concurrent use.
+type ResolutionCache interface {
+	// Get returns the doc cached for a key and its expiry time, or false if the key isn't cached
+	Get(key string) (*docdid.Doc, time.Time, bool)
+	// Set caches the doc of a key until the expiry time
+	Set(key string, doc *docdid.Doc, expiry time.Time) error
+}
+
+// lruResolutionCache is the default resolution cache, keeping the docs of a maximum number of DIDs in memory
+type lruResolutionCache struct {
+	docs gcache.Cache
+}
+
+type docCacheEntry struct {
+	doc    *docdid.Doc
+	expiry time.Time
+}
+
+func newLRUResolutionCache(size int) *lruResolutionCache {
+	return &lruResolutionCache{docs: gcache.New(size).LRU().Build()}
+}
+
+func (c *lruResolutionCache) Get(key string) (*docdid.Doc, time.Time, bool) {
+	cached, err := c.docs.Get(key)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	entry, ok := cached.(*docCacheEntry)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	return entry.doc, entry.expiry, true
+}
+
+func (c *lruResolutionCache) Set(key string, doc *docdid.Doc, expiry time.Time) error {
+	return c.docs.Set(key, &docCacheEntry{doc: doc, expiry: expiry})
+}
+
+// docCache caches resolved DID docs in a resolution cache. Expired docs are kept until evicted, so they can
+// be served while being refreshed.
+type docCache struct {
+	scope      string
+	ttl        time.Duration
+	now        func() time.Time
+	docs       ResolutionCache
+	refreshing map[string]bool
+	pool       *workerpool.Pool
+	mutex      sync.Mutex
+	logger     log.Logger
+}
+
+func newDocCache(scope string, ttl time.Duration, docs ResolutionCache, now func() time.Time,
+	pool *workerpool.Pool, logger log.Logger) *docCache {
+	return &docCache{
+		scope:      scope,
+		ttl:        ttl,
+		now:        now,
+		docs:       docs,
+		refreshing: make(map[string]bool),
+		pool:       pool,
+		logger:     logger,
+	}
+}
+
+// newResolutionCache returns the cache set with WithCache, or an in-memory LRU cache of the configured size
+func (v *VDRI) newResolutionCache() ResolutionCache {
+	if v.resolutionCache != nil {
+		return v.resolutionCache
+	}
+
+	if v.resolutionSize <= 0 {
+		v.resolutionSize = defaultResolutionCacheSize
+	}
+
+	return newLRUResolutionCache(v.resolutionSize)
+}
+
+// get returns the cached doc of a DID, and whether it's expired
+func (c *docCache) get(did string) (*docdid.Doc, bool, bool) {
+	doc, expiry, ok := c.docs.Get(c.scope + did)
+	if !ok || doc == nil {
+		return nil, false, false
+	}
+
+	return doc, c.now().After(expiry), true
+}
+
+func (c *docCache) set(did string, doc *docdid.Doc) {
+	if err := c.docs.Set(c.scope+did, doc, c.now().Add(c.ttl)); err != nil {
+		c.logger.Warnf("failed to cache doc of did %s: %v", did, err)
+	}
+}
+
+// refresh resolves the doc of a DID in the background, unless it's already being refreshed or no worker is
+// available, in which case a later read of the expired doc retries
+func (c *docCache) refresh(did string, resolve func() (*docdid.Doc, error)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.refreshing[did] {
+		return
+	}
+
+	started := c.pool.Go(func() {
+		doc, err := resolve()
+		if err != nil {
+			c.logger.Warnf("failed to refresh doc of did %s: %v", did, err)
+		} else {
+			c.set(did, doc)
+		}
+
+		c.mutex.Lock()
+		delete(c.refreshing, did)
+		c.mutex.Unlock()
+	})
+	if !started {
+		c.logger.Debugf("no worker available to refresh doc of did %s", did)
+
+		return
+	}
+
+	c.refreshing[did] = true
+}
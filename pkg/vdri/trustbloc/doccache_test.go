@@ -0,0 +1,297 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/stretchr/testify/require"
+)
+
+// countingVDRI returns an http vdri func resolving docs whose service endpoint is the number of reads so far
+func countingVDRI(reads *int32) func(url string) (vdri, error) {
+	return func(url string) (vdri, error) {
+		return &mockvdri.MockVDRI{
+			ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				n := atomic.AddInt32(reads, 1)
+
+				return &did.Doc{ID: didID, Service: []did.Service{{ID: "hub", ServiceEndpoint: fmt.Sprint(n)}}}, nil
+			}}, nil
+	}
+}
+
+// mapCache is a resolution cache storing docs in a map
+type mapCache struct {
+	entries map[string]*docCacheEntry
+	err     error
+	mutex   sync.Mutex
+}
+
+func (c *mapCache) Get(didID string) (*did.Doc, time.Time, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[didID]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	return entry.doc, entry.expiry, true
+}
+
+func (c *mapCache) Set(didID string, doc *did.Doc, expiry time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.entries[didID] = &docCacheEntry{doc: doc, expiry: expiry}
+
+	return nil
+}
+
+func TestVDRI_ResolutionCache(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		for i := 0; i < 2; i++ {
+			_, err := v.Read("did:trustbloc:testnet:123")
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 2, atomic.LoadInt32(&reads))
+	})
+
+	t.Run("cached until expired", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(50*time.Millisecond))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		doc, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+
+		doc, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+		require.False(t, metadata.Stale)
+
+		// bypassing the cache
+		doc, err = v.Read("did:trustbloc:testnet:123", vdriapi.WithNoCache(true))
+		require.NoError(t, err)
+		require.Equal(t, "2", doc.Service[0].ServiceEndpoint)
+
+		time.Sleep(60 * time.Millisecond)
+
+		doc, metadata, err = v.ReadWithMetadata("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "3", doc.Service[0].ServiceEndpoint)
+		require.False(t, metadata.Stale)
+	})
+
+	t.Run("expiry measured with the given clock", func(t *testing.T) {
+		var reads int32
+
+		now := time.Now()
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute),
+			WithClock(func() time.Time { return now }))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		now = now.Add(59 * time.Second)
+
+		doc, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+
+		now = now.Add(2 * time.Second)
+
+		doc, err = v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "2", doc.Service[0].ServiceEndpoint)
+	})
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(50*time.Millisecond), WithStaleWhileRevalidate())
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		time.Sleep(60 * time.Millisecond)
+
+		doc, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+		require.True(t, metadata.Stale)
+
+		require.Eventually(t, func() bool {
+			doc, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+
+			return err == nil && !metadata.Stale && doc.Service[0].ServiceEndpoint == "2"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("failed refresh keeps the stale doc", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Millisecond), WithStaleWhileRevalidate())
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("read error"))
+
+		time.Sleep(5 * time.Millisecond)
+
+		for i := 0; i < 2; i++ {
+			doc, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+			require.NoError(t, err)
+			require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+			require.True(t, metadata.Stale)
+		}
+	})
+
+	t.Run("refresh is retried when no worker is available", func(t *testing.T) {
+		var reads int32
+
+		now := time.Now()
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithStaleWhileRevalidate(),
+			WithClock(func() time.Time { return now }), WithWorkerPoolSize(1))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+
+		// the only worker is busy
+		release := make(chan struct{})
+		require.True(t, v.pool.Go(func() { <-release }))
+
+		_, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.True(t, metadata.Stale)
+		require.EqualValues(t, 1, atomic.LoadInt32(&reads))
+
+		close(release)
+
+		require.Eventually(t, func() bool {
+			doc, metadata, err := v.ReadWithMetadata("did:trustbloc:testnet:123")
+
+			return err == nil && !metadata.Stale && doc.Service[0].ServiceEndpoint == "2"
+		}, time.Second, 10*time.Millisecond)
+	})
+	t.Run("least recently used docs are evicted", func(t *testing.T) {
+		var reads int32
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithResolutionCacheSize(1))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		for _, didID := range []string{"did:trustbloc:testnet:123", "did:trustbloc:testnet:456",
+			"did:trustbloc:testnet:456", "did:trustbloc:testnet:123"} {
+			_, err := v.Read(didID)
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 3, atomic.LoadInt32(&reads))
+	})
+
+	t.Run("custom cache", func(t *testing.T) {
+		var reads int32
+
+		cache := &mapCache{entries: map[string]*docCacheEntry{}}
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithCache(cache))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		doc, _, ok := cache.Get("did:trustbloc:testnet:123")
+		require.True(t, ok)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+
+		// docs cached by another instance are served from the shared cache
+		other := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithCache(cache))
+		other.getHTTPVDRI = countingVDRI(&reads)
+
+		doc, err = other.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "1", doc.Service[0].ServiceEndpoint)
+		require.EqualValues(t, 1, atomic.LoadInt32(&reads))
+	})
+
+	t.Run("custom cache failure", func(t *testing.T) {
+		var reads int32
+
+		cache := &mapCache{entries: map[string]*docCacheEntry{}, err: errors.New("cache unavailable")}
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithCache(cache))
+		v.getHTTPVDRI = countingVDRI(&reads)
+
+		for i := 0; i < 2; i++ {
+			_, err := v.Read("did:trustbloc:testnet:123")
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 2, atomic.LoadInt32(&reads))
+	})
+	t.Run("custom cache shared by tenants and domains", func(t *testing.T) {
+		cache := &mapCache{entries: map[string]*docCacheEntry{}}
+
+		v := New(WithResolverURL("url"), WithResolutionCacheTTL(time.Minute), WithCache(cache),
+			WithTenant("tenant1", WithAuthToken("token1")), WithTenant("tenant2", WithAuthToken("token2")),
+			WithDomainOptions("othernet"))
+
+		resolvers := map[string]*VDRI{"": v, "domain": v.forDomain("othernet")}
+
+		for _, tenant := range []string{"tenant1", "tenant2"} {
+			tv, err := v.Tenant(tenant)
+			require.NoError(t, err)
+
+			resolvers[tenant] = tv
+		}
+
+		for name, resolver := range resolvers {
+			resolver.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123",
+				Service: []did.Service{{ID: "hub", ServiceEndpoint: name}}}, nil)
+		}
+
+		for i := 0; i < 2; i++ {
+			for name, resolver := range resolvers {
+				doc, err := resolver.Read("did:trustbloc:testnet:123")
+				require.NoError(t, err)
+				require.Equal(t, name, doc.Service[0].ServiceEndpoint)
+			}
+		}
+
+		require.Len(t, cache.entries, 4)
+		require.Contains(t, cache.entries, "tenant/tenant1/did:trustbloc:testnet:123")
+		require.Contains(t, cache.entries, "domain/othernet/did:trustbloc:testnet:123")
+	})
+}
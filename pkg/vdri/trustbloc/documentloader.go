@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+// preloadedDocumentLoader serves preloaded JSON-LD documents, delegating the other urls to the next loader
+type preloadedDocumentLoader struct {
+	docs map[string]*ld.RemoteDocument
+	next ld.DocumentLoader
+}
+
+// NewPreloadedDocumentLoader returns a JSON-LD document loader serving the given contexts, by url, from memory.
+// Other urls are loaded with the next loader. If it's nil, they fail to load: contexts are then never fetched from
+// the network, and docs with unknown contexts fail to be canonicalized rather than silently triggering requests.
+func NewPreloadedDocumentLoader(contexts map[string][]byte, next ld.DocumentLoader) (ld.DocumentLoader, error) {
+	docs := make(map[string]*ld.RemoteDocument, len(contexts))
+
+	for u, context := range contexts {
+		doc, err := ld.DocumentFromReader(bytes.NewReader(context))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON-LD context %s: %w", u, err)
+		}
+
+		docs[u] = &ld.RemoteDocument{DocumentURL: u, Document: doc}
+	}
+
+	return &preloadedDocumentLoader{docs: docs, next: next}, nil
+}
+
+// LoadDocument returns the preloaded document of the given url, loading it with the next loader if it's not preloaded
+func (l *preloadedDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	if doc, ok := l.docs[u]; ok {
+		return doc, nil
+	}
+
+	if l.next == nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, fmt.Sprintf("JSON-LD document %s is not preloaded", u))
+	}
+
+	return l.next.LoadDocument(u)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testContext = `{"@context": {"@vocab": "https://example.com/vocab#"}}`
+
+func TestNewPreloadedDocumentLoader(t *testing.T) {
+	contexts := map[string][]byte{"https://w3id.org/did/v1": []byte(testContext)}
+
+	t.Run("success - offline", func(t *testing.T) {
+		loader, err := NewPreloadedDocumentLoader(contexts, nil)
+		require.NoError(t, err)
+
+		doc, err := loader.LoadDocument("https://w3id.org/did/v1")
+		require.NoError(t, err)
+		require.Equal(t, "https://w3id.org/did/v1", doc.DocumentURL)
+		require.NotNil(t, doc.Document)
+
+		_, err = loader.LoadDocument("https://example.com/context")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "JSON-LD document https://example.com/context is not preloaded")
+
+		canonical, err := canonicalizeJSONLD([]byte(ldTestDoc1), loader)
+		require.NoError(t, err)
+		require.NotEmpty(t, canonical)
+	})
+
+	t.Run("success - next loader", func(t *testing.T) {
+		next := &mockDocumentLoader{}
+
+		loader, err := NewPreloadedDocumentLoader(contexts, next)
+		require.NoError(t, err)
+
+		_, err = loader.LoadDocument("https://w3id.org/did/v1")
+		require.NoError(t, err)
+		require.Equal(t, 0, next.loadCount)
+
+		doc, err := loader.LoadDocument("https://example.com/context")
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/context", doc.DocumentURL)
+		require.Equal(t, 1, next.loadCount)
+	})
+
+	t.Run("failure - unknown context of offline loader", func(t *testing.T) {
+		loader, err := NewPreloadedDocumentLoader(nil, nil)
+		require.NoError(t, err)
+
+		_, err = canonicalizeJSONLD([]byte(ldTestDoc1), loader)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "loading remote context failed")
+	})
+
+	t.Run("failure - invalid context", func(t *testing.T) {
+		_, err := NewPreloadedDocumentLoader(map[string][]byte{"https://w3id.org/did/v1": []byte("{")}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid JSON-LD context https://w3id.org/did/v1")
+	})
+}
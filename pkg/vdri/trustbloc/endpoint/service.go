@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package endpoint
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
@@ -20,6 +21,15 @@ type selection interface {
 	SelectEndpoints(domain string, endpoints []*models.Endpoint) ([]*models.Endpoint, error)
 }
 
+type contextDiscovery interface {
+	GetEndpointsWithContext(ctx context.Context, domain string) ([]*models.Endpoint, error)
+}
+
+type contextSelection interface {
+	SelectEndpointsWithContext(ctx context.Context, domain string,
+		endpoints []*models.Endpoint) ([]*models.Endpoint, error)
+}
+
 // EndpointService uses discovery service and selection service to fetch and filter endpoints
 type EndpointService struct { // nolint: golint
 	discovery discovery
@@ -38,15 +48,38 @@ func NewService(d discovery, s selection) *EndpointService {
 
 // GetEndpoints get a list of endpoints to use from a consortium at a given domain
 func (es *EndpointService) GetEndpoints(domain string) ([]*models.Endpoint, error) {
-	eps, err := es.discovery.GetEndpoints(domain)
+	return es.GetEndpointsWithContext(context.Background(), domain)
+}
+
+// GetEndpointsWithContext get a list of endpoints to use from a consortium at a given domain. The discovery and
+// selection are aborted when the context is done, if they accept a context.
+func (es *EndpointService) GetEndpointsWithContext(ctx context.Context, domain string) ([]*models.Endpoint, error) {
+	eps, err := es.discover(ctx, domain)
 	if err != nil {
 		return nil, fmt.Errorf("discovery: %w", err)
 	}
 
-	out, err := es.selection.SelectEndpoints(domain, eps)
+	out, err := es.selectEndpoints(ctx, domain, eps)
 	if err != nil {
 		return nil, fmt.Errorf("selection: %w", err)
 	}
 
 	return out, nil
 }
+
+func (es *EndpointService) discover(ctx context.Context, domain string) ([]*models.Endpoint, error) {
+	if d, ok := es.discovery.(contextDiscovery); ok {
+		return d.GetEndpointsWithContext(ctx, domain)
+	}
+
+	return es.discovery.GetEndpoints(domain)
+}
+
+func (es *EndpointService) selectEndpoints(ctx context.Context, domain string,
+	endpoints []*models.Endpoint) ([]*models.Endpoint, error) {
+	if s, ok := es.selection.(contextSelection); ok {
+		return s.SelectEndpointsWithContext(ctx, domain, endpoints)
+	}
+
+	return es.selection.SelectEndpoints(domain, endpoints)
+}
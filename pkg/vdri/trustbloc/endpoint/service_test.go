@@ -7,11 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package endpoint
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -114,3 +117,26 @@ func TestEndpointService_GetEndpoints(t *testing.T) {
 		require.Contains(t, err.Error(), "selection error")
 	})
 }
+
+func TestEndpointService_GetEndpointsWithContext(t *testing.T) {
+	release := make(chan struct{})
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer serv.Close()
+	defer close(release)
+
+	configService := httpconfig.NewService()
+	endpointService := NewService(staticdiscovery.NewService(configService), staticselection.NewService(configService))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := endpointService.GetEndpointsWithContext(ctx, serv.URL)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+)
+
+const (
+	hashlinkParam  = "hl"
+	hashlinkPrefix = "hl:"
+	// multibase prefix of base58btc encoded data
+	base58btcPrefix = "z"
+	// multihash code and digest length of sha2-256
+	sha256Code   = 0x12
+	sha256Length = 32
+)
+
+// HashlinkMismatchError is returned by Read when the resolved doc of a DID doesn't match
+// the hashlink given in the hl parameter of the DID
+type HashlinkMismatchError struct {
+	DID      string
+	Expected string
+	Actual   string
+}
+
+func (e *HashlinkMismatchError) Error() string {
+	return fmt.Sprintf("resolved doc of did %s doesn't match hashlink: expected %s, got %s", e.DID, e.Expected, e.Actual)
+}
+
+// Hashlink returns the hashlink of a DID doc, to be given in the hl parameter of the DID to pin its resolved doc.
+// The hash is the sha2-256 multihash of the JCS canonical form of the doc, encoded as base58btc multibase.
+func Hashlink(doc *docdid.Doc) (string, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return "", err
+	}
+
+	canonical, err := did.Canonicalize(docBytes)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(canonical)
+
+	return hashlinkPrefix + base58btcPrefix + base58.Encode(append([]byte{sha256Code, sha256Length}, digest[:]...)), nil
+}
+
+// splitHashlink removes the hl parameter from a DID, returning the DID without it and the hashlink
+func splitHashlink(didID string) (string, string, error) {
+	i := strings.Index(didID, "?")
+	if i < 0 {
+		return didID, "", nil
+	}
+
+	params, err := neturl.ParseQuery(didID[i+1:])
+	if err != nil {
+		return "", "", errcode.Errorf(errcode.InvalidDID, "invalid did parameters: %w", err)
+	}
+
+	hl := params.Get(hashlinkParam)
+	if hl == "" {
+		return didID, "", nil
+	}
+
+	params.Del(hashlinkParam)
+
+	didID = didID[:i]
+	if len(params) > 0 {
+		didID += "?" + params.Encode()
+	}
+
+	if !strings.HasPrefix(hl, hashlinkPrefix) {
+		hl = hashlinkPrefix + hl
+	}
+
+	return didID, hl, nil
+}
+
+// verifyHashlink checks that the resolved doc of a DID matches the expected hashlink
+func verifyHashlink(didID string, doc *docdid.Doc, expected string) error {
+	if err := checkHashlink(expected); err != nil {
+		return err
+	}
+
+	actual, err := Hashlink(doc)
+	if err != nil {
+		return fmt.Errorf("failed to compute hashlink of resolved doc: %w", err)
+	}
+
+	if actual != expected {
+		return &HashlinkMismatchError{DID: didID, Expected: expected, Actual: actual}
+	}
+
+	return nil
+}
+
+// checkHashlink checks that a hashlink is a base58btc encoded sha2-256 multihash
+func checkHashlink(hl string) error {
+	encoded := strings.TrimPrefix(hl, hashlinkPrefix)
+
+	if strings.Contains(encoded, ":") {
+		return errors.New("hashlink metadata is not supported")
+	}
+
+	if !strings.HasPrefix(encoded, base58btcPrefix) {
+		return fmt.Errorf("unsupported hashlink encoding: %s", hl)
+	}
+
+	mh := base58.Decode(strings.TrimPrefix(encoded, base58btcPrefix))
+	if len(mh) != sha256Length+2 || mh[0] != sha256Code || mh[1] != sha256Length {
+		return fmt.Errorf("unsupported hashlink hash: %s", hl)
+	}
+
+	return nil
+}
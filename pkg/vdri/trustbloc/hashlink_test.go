@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVDRI_ReadWithHashlink(t *testing.T) {
+	doc := &did.Doc{Context: []string{did.Context}, ID: "did:trustbloc:testnet:123",
+		Service: []did.Service{{ID: "did:trustbloc:testnet:123#hub", Type: "hub"}}}
+
+	hl, err := Hashlink(doc)
+	require.NoError(t, err)
+	require.Regexp(t, "^hl:zQm", hl)
+
+	var resolvedDID string
+
+	v := New(WithResolverURL("url"))
+	v.getHTTPVDRI = func(url string) (vdri, error) {
+		return &mockvdri.MockVDRI{
+			ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				resolvedDID = didID
+
+				return doc, nil
+			}}, nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resolved, err := v.Read("did:trustbloc:testnet:123?hl=" + hl)
+		require.NoError(t, err)
+		require.Equal(t, doc, resolved)
+		require.Equal(t, "did:trustbloc:testnet:123", resolvedDID)
+	})
+
+	t.Run("success - hashlink without prefix and other parameters", func(t *testing.T) {
+		_, err := v.Read("did:trustbloc:testnet:123?hl=" + hl[len("hl:"):] + "&version-id=1")
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123?version-id=1", resolvedDID)
+	})
+
+	t.Run("failure - mismatch", func(t *testing.T) {
+		other := &did.Doc{Context: []string{did.Context}, ID: "did:trustbloc:testnet:456"}
+
+		otherHL, err := Hashlink(other)
+		require.NoError(t, err)
+
+		_, err = v.Read("did:trustbloc:testnet:123?hl=" + otherHL)
+		require.Error(t, err)
+
+		var mismatchErr *HashlinkMismatchError
+		require.True(t, errors.As(err, &mismatchErr))
+		require.Equal(t, "did:trustbloc:testnet:123", mismatchErr.DID)
+		require.Equal(t, otherHL, mismatchErr.Expected)
+		require.Equal(t, hl, mismatchErr.Actual)
+	})
+
+	t.Run("failure - unsupported hashlink", func(t *testing.T) {
+		_, err := v.Read("did:trustbloc:testnet:123?hl=uEiDd")
+		require.EqualError(t, err, "unsupported hashlink encoding: hl:uEiDd")
+
+		_, err = v.Read("did:trustbloc:testnet:123?hl=zabc")
+		require.EqualError(t, err, "unsupported hashlink hash: hl:zabc")
+
+		_, err = v.Read("did:trustbloc:testnet:123?hl=" + hl + ":zmeta")
+		require.EqualError(t, err, "hashlink metadata is not supported")
+	})
+
+	t.Run("failure - invalid parameters", func(t *testing.T) {
+		_, err := v.Read("did:trustbloc:testnet:123?hl=%zz")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did parameters")
+	})
+}
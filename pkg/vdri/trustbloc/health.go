@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const healthProbeTimeout = 10 * time.Second
+
+// ConsortiumHealth is the health of each stakeholder of a consortium, eg. the data source of a monitoring dashboard
+type ConsortiumHealth struct {
+	Domain       string               `json:"domain"`
+	Version      uint64               `json:"version"`
+	Stakeholders []*StakeholderHealth `json:"stakeholders"`
+}
+
+// StakeholderHealth is the status of a stakeholder's config, did-configuration and endpoints
+type StakeholderHealth struct {
+	Domain           string            `json:"domain"`
+	Healthy          bool              `json:"healthy"`
+	Config           *HealthCheck      `json:"config"`
+	DIDConfiguration *HealthCheck      `json:"did_configuration"`
+	Endpoints        []*EndpointHealth `json:"endpoints"`
+}
+
+// EndpointHealth is the status of a stakeholder's sidetree endpoint
+type EndpointHealth struct {
+	URL string `json:"url"`
+	HealthCheck
+}
+
+// HealthCheck is the result of a probe, with its error if it failed
+type HealthCheck struct {
+	OK bool `json:"ok"`
+	// LatencyMS is the duration of the probe in milliseconds
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ConsortiumHealth probes the config availability, did-configuration validity and endpoint responsiveness of
+// each stakeholder of a consortium. Probes bypass the stakeholder doc cache, but configs are fetched through the
+// config caches. Only a failure to fetch the consortium config itself is returned as an error.
+func (v *VDRI) ConsortiumHealth(domain string) (*ConsortiumHealth, error) {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.ConsortiumHealth(domain)
+	}
+
+	cfd, err := v.configService.GetConsortium(domain, domain)
+	if err != nil {
+		return nil, fmt.Errorf("consortium invalid: %w", err)
+	}
+
+	health := &ConsortiumHealth{
+		Domain:       domain,
+		Version:      cfd.Config.Version,
+		Stakeholders: make([]*StakeholderHealth, len(cfd.Config.Members)),
+	}
+
+	client := &http.Client{
+		Transport: transport.HTTPSOnly(v.httpTransport, v.allowInsecure),
+		Timeout:   healthProbeTimeout,
+	}
+
+	members := cfd.Config.Members
+
+	v.pool.Run(len(members), len(members), func(i int) {
+		health.Stakeholders[i] = v.stakeholderHealth(members[i].Domain, client)
+	})
+
+	return health, nil
+}
+
+func (v *VDRI) stakeholderHealth(domain string, client *http.Client) *StakeholderHealth {
+	health := &StakeholderHealth{Domain: domain, Endpoints: []*EndpointHealth{}}
+
+	var sfd *models.StakeholderFileData
+
+	health.Config = probe(func() (err error) {
+		sfd, err = v.configService.GetStakeholder(domain, domain)
+		if err == nil && sfd.Config == nil {
+			err = errors.New("stakeholder has nil config")
+		}
+
+		return err
+	})
+
+	if !health.Config.OK {
+		health.DIDConfiguration = &HealthCheck{Error: "stakeholder config unavailable"}
+
+		return health
+	}
+
+	health.DIDConfiguration = probe(func() error {
+		doc, err := v.resolveStakeholderDID(context.Background(), sfd.Config)
+		if err != nil {
+			return fmt.Errorf("can't resolve stakeholder DID: %w", err)
+		}
+
+		return v.didConfigService.VerifyStakeholder(domain, doc)
+	})
+
+	health.Healthy = health.DIDConfiguration.OK
+
+	for _, url := range sfd.Config.Endpoints {
+		e := &EndpointHealth{URL: url, HealthCheck: *probe(func() error {
+			return probeEndpoint(client, url)
+		})}
+
+		health.Endpoints = append(health.Endpoints, e)
+		health.Healthy = health.Healthy && e.OK
+	}
+
+	return health
+}
+
+// probeEndpoint checks that an endpoint responds. Any response but a server error is accepted, as sidetree
+// endpoints don't serve their base URL.
+func probeEndpoint(client *http.Client, url string) error {
+	resp, err := client.Get(url) // nolint: noctx
+	if err != nil {
+		return err
+	}
+
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		return closeErr
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func probe(check func() error) *HealthCheck {
+	start := time.Now()
+	err := check()
+
+	result := &HealthCheck{OK: err == nil, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	mockdidconf "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/didconfiguration"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_ConsortiumHealth(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer up.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	consortium := &models.Consortium{
+		Domain:  "testnet",
+		Version: 3,
+		Members: []*models.StakeholderListElement{
+			{Domain: "stakeholder.one"}, {Domain: "stakeholder.two"},
+			{Domain: "stakeholder.three"}, {Domain: "stakeholder.four"},
+		},
+	}
+
+	stakeholders := map[string]*models.Stakeholder{
+		"stakeholder.one": {Domain: "stakeholder.one", DID: "did:trustbloc:testnet:1", Endpoints: []string{up.URL}},
+		"stakeholder.two": {Domain: "stakeholder.two", DID: "did:trustbloc:testnet:2", Endpoints: []string{up.URL}},
+		"stakeholder.three": {Domain: "stakeholder.three", DID: "did:trustbloc:testnet:3",
+			Endpoints: []string{up.URL, failing.URL}},
+	}
+
+	v := New(WithAllowInsecureHTTP("127.0.0.1"))
+	v.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:1"}, nil)
+	v.configService = &mockconfig.MockConfigService{
+		GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+			return &models.ConsortiumFileData{Config: consortium}, nil
+		},
+		GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+			s, ok := stakeholders[d]
+			if !ok {
+				return nil, fmt.Errorf("not found")
+			}
+
+			return &models.StakeholderFileData{Config: s}, nil
+		},
+	}
+	v.didConfigService = &mockdidconf.MockDIDConfigService{
+		VerifyStakeholderFunc: func(domain string, doc *did.Doc) error {
+			if domain == "stakeholder.two" {
+				return fmt.Errorf("did configuration mismatch")
+			}
+
+			return nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		health, err := v.ConsortiumHealth("testnet")
+		require.NoError(t, err)
+		require.Equal(t, "testnet", health.Domain)
+		require.Equal(t, uint64(3), health.Version)
+		require.Len(t, health.Stakeholders, 4)
+
+		one := health.Stakeholders[0]
+		require.Equal(t, "stakeholder.one", one.Domain)
+		require.True(t, one.Healthy)
+		require.True(t, one.Config.OK)
+		require.True(t, one.DIDConfiguration.OK)
+		require.Len(t, one.Endpoints, 1)
+		require.True(t, one.Endpoints[0].OK)
+
+		two := health.Stakeholders[1]
+		require.False(t, two.Healthy)
+		require.True(t, two.Config.OK)
+		require.False(t, two.DIDConfiguration.OK)
+		require.Equal(t, "did configuration mismatch", two.DIDConfiguration.Error)
+
+		three := health.Stakeholders[2]
+		require.False(t, three.Healthy)
+		require.True(t, three.DIDConfiguration.OK)
+		require.Len(t, three.Endpoints, 2)
+		require.True(t, three.Endpoints[0].OK)
+		require.False(t, three.Endpoints[1].OK)
+		require.Equal(t, failing.URL, three.Endpoints[1].URL)
+		require.Contains(t, three.Endpoints[1].Error, "endpoint responded with status 500")
+
+		four := health.Stakeholders[3]
+		require.False(t, four.Healthy)
+		require.False(t, four.Config.OK)
+		require.Equal(t, "not found", four.Config.Error)
+		require.False(t, four.DIDConfiguration.OK)
+		require.Empty(t, four.Endpoints)
+	})
+
+	t.Run("failure - endpoint refused over http", func(t *testing.T) {
+		vh := New()
+		vh.getHTTPVDRI = v.getHTTPVDRI
+		vh.configService = v.configService
+		vh.didConfigService = v.didConfigService
+
+		health, err := vh.ConsortiumHealth("testnet")
+		require.NoError(t, err)
+		require.False(t, health.Stakeholders[0].Healthy)
+		require.Contains(t, health.Stakeholders[0].Endpoints[0].Error, "only https is allowed")
+	})
+
+	t.Run("failure - consortium config unavailable", func(t *testing.T) {
+		vh := New(WithDomainOptions("testnet"))
+		vh.domainVDRIs["testnet"].configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("unreachable")
+			},
+		}
+
+		_, err := vh.ConsortiumHealth("testnet")
+		require.EqualError(t, err, "consortium invalid: unreachable")
+	})
+}
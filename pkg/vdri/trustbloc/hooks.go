@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// Hooks are callbacks invoked during the lifecycle of resolutions, eg. for audit logging. All hooks are optional,
+// and are called synchronously so they should return quickly.
+type Hooks struct {
+	// OnResolveStart is called when the resolution of a DID starts
+	OnResolveStart func(did string)
+	// OnEndpointSelected is called before a DID is resolved from an endpoint
+	OnEndpointSelected func(did string, endpoint *models.Endpoint)
+	// OnResolveSuccess is called with the resolved doc of a DID
+	OnResolveSuccess func(did string, doc *docdid.Doc)
+	// OnResolveFailure is called with the error of a failed resolution
+	OnResolveFailure func(did string, err error)
+	// OnConsortiumValidated is called when the config of a consortium and the endorsing stakeholders are validated
+	OnConsortiumValidated func(domain string)
+	// OnVerificationWarning is called for each stakeholder that failed a check while its consortium was validated
+	// anyway, as enough other stakeholders passed it
+	OnVerificationWarning func(warning *models.VerificationWarning)
+}
+
+func (h *Hooks) resolveStart(did string) {
+	if h.OnResolveStart != nil {
+		h.OnResolveStart(did)
+	}
+}
+
+func (h *Hooks) endpointSelected(did string, endpoint *models.Endpoint) {
+	if h.OnEndpointSelected != nil {
+		h.OnEndpointSelected(did, endpoint)
+	}
+}
+
+func (h *Hooks) resolveDone(did string, doc *docdid.Doc, err error) {
+	if err != nil {
+		if h.OnResolveFailure != nil {
+			h.OnResolveFailure(did, err)
+		}
+
+		return
+	}
+
+	if h.OnResolveSuccess != nil {
+		h.OnResolveSuccess(did, doc)
+	}
+}
+
+func (h *Hooks) consortiumValidated(domain string) {
+	if h.OnConsortiumValidated != nil {
+		h.OnConsortiumValidated(domain)
+	}
+}
+
+func (h *Hooks) verificationWarning(warning *models.VerificationWarning) {
+	if h.OnVerificationWarning != nil {
+		h.OnVerificationWarning(warning)
+	}
+}
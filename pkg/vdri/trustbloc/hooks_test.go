@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_Hooks(t *testing.T) {
+	var events []string
+
+	v := New(WithHooks(Hooks{
+		OnResolveStart: func(did string) {
+			events = append(events, "start "+did)
+		},
+		OnEndpointSelected: func(did string, endpoint *models.Endpoint) {
+			events = append(events, "endpoint "+endpoint.URL)
+		},
+		OnResolveSuccess: func(did string, doc *did.Doc) {
+			events = append(events, "success "+doc.ID)
+		},
+		OnResolveFailure: func(did string, err error) {
+			events = append(events, "failure "+err.Error())
+		},
+		OnConsortiumValidated: func(domain string) {
+			events = append(events, "validated "+domain)
+		},
+	}))
+
+	v.endpointService = &mockendpoint.MockEndpointService{
+		GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+			return []*models.Endpoint{{URL: "url.1"}, {URL: "url.2"}}, nil
+		}}
+
+	cfd := signedConsortiumFileData(t, &models.Consortium{Domain: "testnet"}, ed25519SigningKey(t, keyJSON))
+
+	v.configService = &mockconfig.MockConfigService{
+		GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+			return cfd, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		events = nil
+		v.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123"}, nil)
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"start did:trustbloc:testnet:123",
+			"validated testnet",
+			"endpoint url.1",
+			"endpoint url.2",
+			"success did:trustbloc:testnet:123",
+		}, events)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		events = nil
+		v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("read error"))
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Len(t, events, 3)
+		require.Equal(t, "start did:trustbloc:testnet:123", events[0])
+		require.Equal(t, "endpoint url.1", events[1])
+		require.Contains(t, events[2], "failure")
+		require.Contains(t, events[2], "read error")
+	})
+}
+
+func TestVDRI_VerificationWarningHook(t *testing.T) {
+	var warnings []*models.VerificationWarning
+
+	v := New(WithHooks(Hooks{
+		OnVerificationWarning: func(warning *models.VerificationWarning) {
+			warnings = append(warnings, warning)
+		},
+	}))
+
+	v.configService = &mockconfig.MockConfigService{
+		GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+			if d == "stakeholder.two" {
+				return nil, fmt.Errorf("unreachable")
+			}
+
+			return &models.StakeholderFileData{Config: &models.Stakeholder{Domain: d}}, nil
+		},
+	}
+
+	consortium := &models.Consortium{
+		Members: []*models.StakeholderListElement{{Domain: "stakeholder.one"}, {Domain: "stakeholder.two"}},
+		Policy:  models.ConsortiumPolicy{NumQueries: 1},
+	}
+
+	// stakeholders are selected in random order, so stakeholder.two is only fetched in about half the runs
+	for i := 0; i < 50 && len(warnings) == 0; i++ {
+		stakeholders, selectWarnings, err := v.selectStakeholders(context.Background(), "testnet", consortium)
+		require.NoError(t, err)
+		require.Len(t, stakeholders, 1)
+
+		models.WarningHandler(v.hooks.verificationWarning).Warn(selectWarnings, 1, 1)
+	}
+
+	require.Len(t, warnings, 1)
+	require.Equal(t, "testnet", warnings[0].Consortium)
+	require.Equal(t, "stakeholder.two", warnings[0].Stakeholder)
+	require.Equal(t, models.CheckStakeholderConfig, warnings[0].Check)
+	require.EqualError(t, warnings[0].Err, "unreachable")
+
+	// no hook set
+	(&Hooks{}).verificationWarning(warnings[0])
+}
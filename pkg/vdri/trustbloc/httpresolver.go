@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+const didLDJSON = "application/did+ld+json"
+
+// httpResolver resolves DIDs with the DID resolution HTTP binding of a sidetree endpoint or resolver. Unlike the
+// aries httpbinding VDRI, it sends its requests with the given client, so resolutions share the transport of the
+// VDRI and its pooled connections, and resolution results are decoded while they're read with their size limited,
+// so a huge response can't spike memory.
+type httpResolver struct {
+	endpointURL     *url.URL
+	client          *http.Client
+	authToken       string
+	maxResponseSize int64
+	logger          log.Logger
+}
+
+func newHTTPResolver(endpointURL string, client *http.Client, authToken string, maxResponseSize int64,
+	logger log.Logger) (*httpResolver, error) {
+	u, err := url.ParseRequestURI(endpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("base URL invalid: %w", err)
+	}
+
+	return &httpResolver{endpointURL: u, client: client, authToken: authToken, maxResponseSize: maxResponseSize,
+		logger: logger}, nil
+}
+
+// Build isn't supported by the http binding
+func (r *httpResolver) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	return nil, errors.New("build not supported in http binding vdri")
+}
+
+// Read resolves a DID, accepting a DID resolution result or a bare DID doc
+func (r *httpResolver) Read(didID string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	return r.ReadWithContext(context.Background(), didID, opts...)
+}
+
+// ReadWithContext resolves a DID like Read, aborting the request when the context is done
+func (r *httpResolver) ReadWithContext(ctx context.Context, didID string,
+	_ ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	reqURL := *r.endpointURL
+	reqURL.Path = path.Join(reqURL.Path, didID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP create get request failed: %w", err)
+	}
+
+	req.Header.Add("Accept", didLDJSON)
+
+	if r.authToken != "" {
+		req.Header.Add("Authorization", r.authToken)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP Get request failed: %w", err)
+	}
+
+	defer func() {
+		if e := resp.Body.Close(); e != nil {
+			r.logger.Errorf("failed to close response body: %v", e)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errcode.Errorf(errcode.DIDNotFound, "DID does not exist for request: %s", reqURL.String())
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-type"), didLDJSON) {
+		body, e := transport.ReadLimited(resp.Body, r.maxResponseSize)
+		if e != nil {
+			body = []byte(e.Error())
+		}
+
+		return nil, fmt.Errorf("unsupported response from DID resolver [%v] header [%s] body [%s]",
+			resp.StatusCode, resp.Header.Get("Content-type"), body)
+	}
+
+	return r.decodeDoc(resp.Body)
+}
+
+func (r *httpResolver) decodeDoc(body io.Reader) (*docdid.Doc, error) {
+	var result map[string]json.RawMessage
+
+	err := transport.DecodeJSON(body, r.maxResponseSize, &result)
+	if errors.Is(err, io.EOF) {
+		return nil, errcode.Wrap(errcode.DIDNotFound, vdriapi.ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode resolution result: %w", err)
+	}
+
+	docBytes, ok := result["didDocument"]
+	if !ok || len(docBytes) == 0 || string(docBytes) == "null" {
+		// the response is a bare DID doc
+		if docBytes, err = json.Marshal(result); err != nil {
+			return nil, err
+		}
+	}
+
+	return docdid.ParseDocument(docBytes)
+}
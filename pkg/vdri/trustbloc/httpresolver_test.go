@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+)
+
+func resolverServer(t *testing.T, status int, contentType, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/identifiers/did:example:123456789abcdefghi", r.URL.Path)
+		require.Equal(t, didLDJSON, r.Header.Get("Accept"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestHTTPResolver_Read(t *testing.T) {
+	read := func(t *testing.T, status int, contentType, body string, maxSize int64) error {
+		serv := resolverServer(t, status, contentType, body)
+		defer serv.Close()
+
+		r, err := newHTTPResolver(serv.URL+"/identifiers", &http.Client{}, "Bearer token", maxSize, log.Nop())
+		require.NoError(t, err)
+
+		doc, err := r.Read("did:example:123456789abcdefghi")
+		if err == nil {
+			require.Equal(t, "did:example:123456789abcdefghi", doc.ID)
+		}
+
+		return err
+	}
+
+	t.Run("success - resolution result", func(t *testing.T) {
+		require.NoError(t, read(t, http.StatusOK, didLDJSON, `{"didDocument":`+testDoc+`}`,
+			transport.DefaultMaxResponseSize))
+	})
+
+	t.Run("success - bare doc", func(t *testing.T) {
+		require.NoError(t, read(t, http.StatusOK, didLDJSON+"; charset=utf-8", testDoc,
+			transport.DefaultMaxResponseSize))
+	})
+
+	t.Run("failure - not found", func(t *testing.T) {
+		err := read(t, http.StatusNotFound, "text/plain", "not found", transport.DefaultMaxResponseSize)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DID does not exist for request")
+		require.Equal(t, errcode.DIDNotFound, errcode.Of(err))
+
+		err = read(t, http.StatusOK, didLDJSON, "", transport.DefaultMaxResponseSize)
+		require.True(t, errors.Is(err, vdriapi.ErrNotFound))
+		require.Equal(t, errcode.DIDNotFound, errcode.Of(err))
+	})
+
+	t.Run("failure - unsupported response", func(t *testing.T) {
+		err := read(t, http.StatusInternalServerError, "text/plain", "server error", transport.DefaultMaxResponseSize)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported response from DID resolver [500] header [text/plain] "+
+			"body [server error]")
+
+		err = read(t, http.StatusOK, "application/json", strings.Repeat("x", 20), 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "response body too large")
+	})
+
+	t.Run("failure - response too large", func(t *testing.T) {
+		err := read(t, http.StatusOK, didLDJSON, testDoc, int64(len(testDoc)-1))
+		require.True(t, errors.Is(err, transport.ErrResponseTooLarge))
+	})
+
+	t.Run("failure - invalid doc", func(t *testing.T) {
+		err := read(t, http.StatusOK, didLDJSON, `{"didDocument":{"id":1}}`, transport.DefaultMaxResponseSize)
+		require.Error(t, err)
+
+		err = read(t, http.StatusOK, didLDJSON, `[]`, transport.DefaultMaxResponseSize)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode resolution result")
+	})
+
+	t.Run("failure - canceled context", func(t *testing.T) {
+		serv := resolverServer(t, http.StatusOK, didLDJSON, testDoc)
+		defer serv.Close()
+
+		r, err := newHTTPResolver(serv.URL+"/identifiers", &http.Client{}, "", transport.DefaultMaxResponseSize, log.Nop())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = r.ReadWithContext(ctx, "did:example:123456789abcdefghi")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("failure - request", func(t *testing.T) {
+		_, err := newHTTPResolver("invalid", &http.Client{}, "", transport.DefaultMaxResponseSize, log.Nop())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "base URL invalid")
+
+		r, err := newHTTPResolver("http://127.0.0.1:0", &http.Client{}, "", transport.DefaultMaxResponseSize, log.Nop())
+		require.NoError(t, err)
+
+		_, err = r.Read("did:example:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "HTTP Get request failed")
+
+		_, err = r.Build(nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/discovery/staticdiscovery"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/selection/staticselection"
+)
+
+// selection decisions of an endpoint
+const (
+	DecisionSelected    = "selected"
+	DecisionNotSelected = "not selected by the consortium policy"
+	DecisionLimited     = "dropped by the maximum number of endpoints"
+	DecisionResolver    = "resolver url configured"
+)
+
+// EndpointsReport details how the endpoints resolving the DIDs of a consortium are discovered and selected,
+// eg. to troubleshoot which nodes a resolution hits
+type EndpointsReport struct {
+	Domain string `json:"domain"`
+	// ResolverURL is set if DIDs are resolved by a resolver instead of the consortium's endpoints
+	ResolverURL  string                  `json:"resolver_url,omitempty"`
+	Stakeholders []*StakeholderEndpoints `json:"stakeholders"`
+}
+
+// StakeholderEndpoints are the endpoints discovered for a stakeholder
+type StakeholderEndpoints struct {
+	Domain    string            `json:"domain"`
+	Endpoints []*EndpointReport `json:"endpoints"`
+}
+
+// EndpointReport is the selection decision and probe result of an endpoint
+type EndpointReport struct {
+	URL      string       `json:"url"`
+	Selected bool         `json:"selected"`
+	Decision string       `json:"decision"`
+	Probe    *HealthCheck `json:"probe"`
+}
+
+// InspectEndpoints runs the discovery and selection of the endpoints of a consortium, as a resolution would, and
+// probes each discovered endpoint. Selection is random among the stakeholders, so the report shows one sample
+// of the selection decisions.
+func (v *VDRI) InspectEndpoints(domain string) (*EndpointsReport, error) {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.InspectEndpoints(domain)
+	}
+
+	report := &EndpointsReport{Domain: domain, Stakeholders: []*StakeholderEndpoints{}}
+
+	client := &http.Client{
+		Transport: transport.HTTPSOnly(v.httpTransport, v.allowInsecure),
+		Timeout:   healthProbeTimeout,
+	}
+
+	if v.resolverURL != "" {
+		report.ResolverURL = v.resolverURL
+		report.Stakeholders = append(report.Stakeholders, &StakeholderEndpoints{Endpoints: []*EndpointReport{
+			{URL: v.resolverURL, Selected: true, Decision: DecisionResolver},
+		}})
+	} else {
+		endpoints, err := v.selectionReport(domain)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Stakeholders = endpoints
+	}
+
+	var endpoints []*EndpointReport
+
+	for _, s := range report.Stakeholders {
+		endpoints = append(endpoints, s.Endpoints...)
+	}
+
+	v.pool.Run(len(endpoints), len(endpoints), func(i int) {
+		endpoints[i].Probe = probe(func() error {
+			return probeEndpoint(client, endpoints[i].URL)
+		})
+	})
+
+	return report, nil
+}
+
+// selectionReport groups the discovered endpoints of a consortium by stakeholder, with their selection decisions
+func (v *VDRI) selectionReport(domain string) ([]*StakeholderEndpoints, error) {
+	if !v.isValidatedConsortium(domain) {
+		if _, err := v.validateConsortium(context.Background(), domain, nil); err != nil {
+			return nil, fmt.Errorf("invalid consortium: %w", err)
+		}
+
+		v.setValidatedConsortium(domain)
+	}
+
+	discovered, err := staticdiscovery.NewService(v.configService).GetEndpoints(domain)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	selected, err := staticselection.NewService(v.configService).SelectEndpoints(domain, discovered)
+	if err != nil {
+		return nil, fmt.Errorf("selection: %w", err)
+	}
+
+	decisions := map[*models.Endpoint]string{}
+
+	for _, e := range selected {
+		decisions[e] = DecisionLimited
+	}
+
+	for _, e := range v.limitEndpoints(selected, v.maxEndpoints) {
+		decisions[e] = DecisionSelected
+	}
+
+	stakeholders := []*StakeholderEndpoints{}
+	byDomain := map[string]*StakeholderEndpoints{}
+
+	for _, e := range discovered {
+		s, ok := byDomain[e.Domain]
+		if !ok {
+			s = &StakeholderEndpoints{Domain: e.Domain}
+			byDomain[e.Domain] = s
+			stakeholders = append(stakeholders, s)
+		}
+
+		decision, ok := decisions[e]
+		if !ok {
+			decision = DecisionNotSelected
+		}
+
+		s.Endpoints = append(s.Endpoints, &EndpointReport{URL: e.URL, Selected: decision == DecisionSelected,
+			Decision: decision})
+	}
+
+	return stakeholders, nil
+}
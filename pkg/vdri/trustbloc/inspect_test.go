@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_InspectEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	consortium := &models.Consortium{
+		Domain:  "testnet",
+		Members: []*models.StakeholderListElement{{Domain: "stakeholder.one"}, {Domain: "stakeholder.two"}},
+		Policy:  models.ConsortiumPolicy{NumQueries: 1},
+	}
+
+	stakeholders := map[string]*models.Stakeholder{
+		"stakeholder.one": {Domain: "stakeholder.one", Endpoints: []string{srv.URL + "/one", srv.URL + "/two"}},
+		"stakeholder.two": {Domain: "stakeholder.two", Endpoints: []string{"http://127.0.0.1:0"}},
+	}
+
+	configService := &mockconfig.MockConfigService{
+		GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+			return &models.ConsortiumFileData{Config: consortium}, nil
+		},
+		GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+			return &models.StakeholderFileData{Config: stakeholders[d]}, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
+		v.configService = configService
+		v.setValidatedConsortium("testnet")
+
+		report, err := v.InspectEndpoints("testnet")
+		require.NoError(t, err)
+		require.Equal(t, "testnet", report.Domain)
+		require.Empty(t, report.ResolverURL)
+		require.Len(t, report.Stakeholders, 2)
+
+		one, two := report.Stakeholders[0], report.Stakeholders[1]
+		require.Equal(t, "stakeholder.one", one.Domain)
+		require.Len(t, one.Endpoints, 2)
+		require.Equal(t, "stakeholder.two", two.Domain)
+		require.Len(t, two.Endpoints, 1)
+
+		// a single endpoint of a single stakeholder is selected
+		selected := 0
+
+		for _, s := range report.Stakeholders {
+			for _, e := range s.Endpoints {
+				if e.Selected {
+					selected++
+
+					require.Equal(t, DecisionSelected, e.Decision)
+				} else {
+					require.Equal(t, DecisionNotSelected, e.Decision)
+				}
+			}
+		}
+
+		require.Equal(t, 1, selected)
+
+		require.True(t, one.Endpoints[0].Probe.OK)
+		require.True(t, one.Endpoints[1].Probe.OK)
+		require.False(t, two.Endpoints[0].Probe.OK)
+		require.NotEmpty(t, two.Endpoints[0].Probe.Error)
+	})
+
+	t.Run("success - endpoints dropped by the maximum number of endpoints", func(t *testing.T) {
+		consortium.Policy.NumQueries = 0
+		defer func() { consortium.Policy.NumQueries = 1 }()
+
+		v := New(WithAllowInsecureHTTP("127.0.0.1"), WithMaxEndpoints(1))
+		v.configService = configService
+		v.setValidatedConsortium("testnet")
+
+		report, err := v.InspectEndpoints("testnet")
+		require.NoError(t, err)
+
+		decisions := map[string]int{}
+
+		for _, s := range report.Stakeholders {
+			for _, e := range s.Endpoints {
+				decisions[e.Decision]++
+			}
+		}
+
+		require.Equal(t, map[string]int{DecisionSelected: 1, DecisionLimited: 1, DecisionNotSelected: 1}, decisions)
+	})
+
+	t.Run("success - resolver url", func(t *testing.T) {
+		v := New(WithResolverURL(srv.URL), WithAllowInsecureHTTP("127.0.0.1"))
+
+		report, err := v.InspectEndpoints("testnet")
+		require.NoError(t, err)
+		require.Equal(t, srv.URL, report.ResolverURL)
+		require.Len(t, report.Stakeholders, 1)
+		require.Equal(t, DecisionResolver, report.Stakeholders[0].Endpoints[0].Decision)
+		require.True(t, report.Stakeholders[0].Endpoints[0].Probe.OK)
+	})
+
+	t.Run("failure - invalid consortium", func(t *testing.T) {
+		v := New()
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("consortium not found")
+			},
+		}
+
+		_, err := v.InspectEndpoints("testnet")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid consortium")
+		require.Contains(t, err.Error(), "consortium not found")
+	})
+
+	t.Run("failure - discovery", func(t *testing.T) {
+		v := New()
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: configService.GetConsortiumFunc,
+			GetStakeholderFunc: func(u string, d string) (*models.StakeholderFileData, error) {
+				return nil, fmt.Errorf("stakeholder not found")
+			},
+		}
+		v.setValidatedConsortium("testnet")
+
+		_, err := v.InspectEndpoints("testnet")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discovery: stakeholder config: stakeholder not found")
+	})
+}
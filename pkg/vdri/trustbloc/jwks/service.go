@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jwks
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/clock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+)
+
+const (
+	// DefaultMaxAge is the cache lifetime of key sets served without cache headers
+	DefaultMaxAge = 5 * time.Minute
+
+	defaultCacheSize = 100
+)
+
+// Service fetches JSON Web Key Sets, caching them as long as their cache headers allow
+type Service struct {
+	httpClient      *http.Client
+	tlsConfig       *tls.Config
+	transport       http.RoundTripper
+	maxResponseSize int64
+	defaultMaxAge   time.Duration
+	now             func() time.Time
+	cache           gcache.Cache
+}
+
+// NewService create new JWKS Service
+func NewService(opts ...Option) *Service {
+	service := &Service{
+		httpClient:      &http.Client{},
+		maxResponseSize: transport.DefaultMaxResponseSize,
+		defaultMaxAge:   DefaultMaxAge,
+		now:             time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	if service.transport == nil {
+		service.transport = &http.Transport{TLSClientConfig: tlspolicy.Apply(service.tlsConfig)}
+	}
+
+	service.httpClient.Transport = service.transport
+
+	service.cache = gcache.New(defaultCacheSize).LRU().Clock(clock.Func(service.now)).Build()
+
+	return service
+}
+
+// KeySet returns the key set at the given URL, from the cache if it hasn't expired
+func (s *Service) KeySet(url string) (*jose.JSONWebKeySet, error) {
+	return s.KeySetWithContext(context.Background(), url)
+}
+
+// KeySetWithContext returns the key set at the given URL like KeySet, aborting the request when the context is done
+func (s *Service) KeySetWithContext(ctx context.Context, url string) (*jose.JSONWebKeySet, error) {
+	if cached, err := s.cache.Get(url); err == nil {
+		if keySet, ok := cached.(*jose.JSONWebKeySet); ok {
+			return keySet, nil
+		}
+	}
+
+	keySet, maxAge, err := s.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxAge > 0 {
+		// nolint: errcheck
+		s.cache.SetWithExpire(url, keySet, maxAge)
+	}
+
+	return keySet, nil
+}
+
+func (s *Service) fetch(ctx context.Context, url string) (*jose.JSONWebKeySet, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create key set request: %w", err)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch key set at url %s: %w", url, err)
+	}
+
+	// nolint: errcheck
+	defer res.Body.Close()
+
+	body, err := transport.ReadLimited(res.Body, s.maxResponseSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read key set at url %s: %w", url, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("key set request failed: error %d, `%s`", res.StatusCode, string(body))
+	}
+
+	keySet := &jose.JSONWebKeySet{}
+
+	err = json.Unmarshal(body, keySet)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse key set at url %s: %w", url, err)
+	}
+
+	return keySet, s.maxAge(res.Header), nil
+}
+
+// maxAge returns the cache lifetime of a response, from its Cache-Control or Expires header. Responses without
+// cache headers are cached for the default max age, responses that mustn't be cached expire immediately.
+func (s *Service) maxAge(header http.Header) time.Duration {
+	if cacheControl := header.Get("Cache-Control"); cacheControl != "" {
+		for _, directive := range strings.Split(cacheControl, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+
+			switch {
+			case directive == "no-store" || directive == "no-cache":
+				return 0
+			case strings.HasPrefix(directive, "max-age="):
+				seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+				if err != nil || seconds < 0 {
+					return 0
+				}
+
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiry, err := http.ParseTime(expires)
+		if err != nil || !expiry.After(s.now()) {
+			return 0
+		}
+
+		return expiry.Sub(s.now())
+	}
+
+	return s.defaultMaxAge
+}
+
+// Option is a JWKS service instance option
+type Option func(opts *Service)
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *Service) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithTransport option sets the http transport used to fetch key sets, allowing a connection pool to be shared
+// with other services. If set, the tls.Config option is ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *Service) {
+		opts.transport = transport
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size of a key set, in bytes. Larger files are refused without being
+// read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *Service) {
+		opts.maxResponseSize = max
+	}
+}
+
+// WithDefaultMaxAge option sets how long key sets served without Cache-Control or Expires headers are cached.
+// Defaults to 5 minutes.
+func WithDefaultMaxAge(maxAge time.Duration) Option {
+	return func(opts *Service) {
+		opts.defaultMaxAge = maxAge
+	}
+}
+
+// WithClock option sets the clock the cache lifetimes of key sets are measured with, eg. a fake clock for
+// reproducible tests. Defaults to the system clock.
+func WithClock(now func() time.Time) Option {
+	return func(opts *Service) {
+		opts.now = now
+	}
+}
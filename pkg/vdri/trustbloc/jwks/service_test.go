@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jwks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const keySetJSON = `{"keys": [{
+  "kty": "OKP",
+  "kid": "key1",
+  "use": "sig",
+  "crv": "Ed25519",
+  "x": "bWRCy8DtNhRO3HdKTFB2eEG5Ac1J00D0DQPffOwtAD0"
+}]}`
+
+// keySetServer serves keySetJSON with the given headers, counting the requests
+func keySetServer(headers map[string]string) (*httptest.Server, *int32) {
+	var requests int32
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+
+		fmt.Fprint(w, keySetJSON)
+	})), &requests
+}
+
+func TestService_KeySet(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	t.Run("success", func(t *testing.T) {
+		serv, _ := keySetServer(nil)
+		defer serv.Close()
+
+		keySet, err := NewService().KeySet(serv.URL)
+		require.NoError(t, err)
+		require.Len(t, keySet.Keys, 1)
+		require.Equal(t, "key1", keySet.Keys[0].KeyID)
+		require.Equal(t, "sig", keySet.Keys[0].Use)
+	})
+
+	t.Run("cached for max-age", func(t *testing.T) {
+		serv, requests := keySetServer(map[string]string{"Cache-Control": "public, max-age=60"})
+		defer serv.Close()
+
+		now = time.Now()
+		s := NewService(WithClock(clock))
+
+		for i := 0; i < 3; i++ {
+			_, err := s.KeySet(serv.URL)
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 1, atomic.LoadInt32(requests))
+
+		now = now.Add(61 * time.Second)
+
+		_, err := s.KeySet(serv.URL)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, atomic.LoadInt32(requests))
+	})
+
+	t.Run("cached until expiry", func(t *testing.T) {
+		now = time.Now()
+
+		serv, requests := keySetServer(map[string]string{
+			"Expires": now.Add(time.Hour).UTC().Format(http.TimeFormat)})
+		defer serv.Close()
+
+		s := NewService(WithClock(clock))
+
+		_, err := s.KeySet(serv.URL)
+		require.NoError(t, err)
+
+		now = now.Add(30 * time.Minute)
+
+		_, err = s.KeySet(serv.URL)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, atomic.LoadInt32(requests))
+
+		now = now.Add(time.Hour)
+
+		_, err = s.KeySet(serv.URL)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, atomic.LoadInt32(requests))
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"Cache-Control": "no-store"},
+			{"Cache-Control": "max-age=0"},
+			{"Cache-Control": "max-age=soon"},
+			{"Expires": "0"},
+		} {
+			serv, requests := keySetServer(headers)
+
+			s := NewService(WithClock(clock))
+
+			for i := 0; i < 2; i++ {
+				_, err := s.KeySet(serv.URL)
+				require.NoError(t, err)
+			}
+
+			require.EqualValues(t, 2, atomic.LoadInt32(requests), headers)
+
+			serv.Close()
+		}
+	})
+
+	t.Run("default max age", func(t *testing.T) {
+		serv, requests := keySetServer(nil)
+		defer serv.Close()
+
+		now = time.Now()
+		s := NewService(WithClock(clock), WithDefaultMaxAge(time.Minute))
+
+		_, err := s.KeySet(serv.URL)
+		require.NoError(t, err)
+
+		now = now.Add(59 * time.Second)
+
+		_, err = s.KeySet(serv.URL)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, atomic.LoadInt32(requests))
+
+		now = now.Add(2 * time.Second)
+
+		_, err = s.KeySet(serv.URL)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, atomic.LoadInt32(requests))
+	})
+
+	t.Run("failure - server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer serv.Close()
+
+		_, err := NewService().KeySet(serv.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key set request failed: error 404")
+	})
+
+	t.Run("failure - bad key set", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"keys": [{"kty": "foo"}]}`)
+		}))
+		defer serv.Close()
+
+		_, err := NewService().KeySet(serv.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse key set")
+	})
+
+	t.Run("failure - key set too large", func(t *testing.T) {
+		serv, _ := keySetServer(nil)
+		defer serv.Close()
+
+		_, err := NewService(WithMaxResponseSize(10)).KeySet(serv.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read key set")
+	})
+
+	t.Run("failure - unreachable", func(t *testing.T) {
+		_, err := NewService(WithTransport(http.DefaultTransport)).KeySet("http://127.0.0.1:0/jwks.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch key set")
+	})
+}
+
+func TestService_KeySetWithContext(t *testing.T) {
+	serv, requests := keySetServer(nil)
+	defer serv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewService().KeySetWithContext(ctx, serv.URL)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualValues(t, 0, atomic.LoadInt32(requests))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/composer"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler/didvalidator"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+)
+
+const (
+	// initialStateParam is the DID parameter of long-form DIDs, with the encoded suffix data and delta
+	// of the create operation separated by a dot
+	initialStateParam     = "-trustbloc-initial-state"
+	initialStateSeparator = "."
+	initialStateParts     = 2
+	sha2_256              = 18
+)
+
+// splitInitialState removes the initial state parameter from a long-form DID, returning the short-form DID
+// and the initial state
+func splitInitialState(didID string) (string, string, error) {
+	i := strings.Index(didID, "?")
+	if i < 0 {
+		return didID, "", nil
+	}
+
+	params, err := neturl.ParseQuery(didID[i+1:])
+	if err != nil {
+		return "", "", errcode.Errorf(errcode.InvalidDID, "invalid did parameters: %w", err)
+	}
+
+	initialState := params.Get(initialStateParam)
+	if initialState == "" {
+		return didID, "", nil
+	}
+
+	params.Del(initialStateParam)
+
+	if len(params) > 0 {
+		return "", "", errcode.Errorf(errcode.InvalidDID, "long-form did must not have other parameters")
+	}
+
+	return didID[:i], initialState, nil
+}
+
+// docFromInitialState constructs the doc of a DID from the initial state of its long-form, after checking that
+// the DID suffix matches the initial state
+func docFromInitialState(didID, initialState string) (*docdid.Doc, error) {
+	didParts := strings.Split(didID, ":")
+	if len(didParts) != expectedTrustblocDIDParts {
+		return nil, fmt.Errorf("wrong did %s", didID)
+	}
+
+	parts := strings.Split(initialState, initialStateSeparator)
+	if len(parts) != initialStateParts {
+		return nil, errors.New("initial state should have two parts: suffix data and delta")
+	}
+
+	request, err := json.Marshal(&model.CreateRequest{
+		Operation:  model.OperationTypeCreate,
+		SuffixData: parts[0],
+		Delta:      parts[1],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := operation.ParseCreateOperation(request, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial state: %w", err)
+	}
+
+	if didParts[expectedTrustblocDIDParts-1] != op.UniqueSuffix {
+		return nil, fmt.Errorf("did %s doesn't match its initial state", didID)
+	}
+
+	internal, err := composer.New().ApplyPatches(make(document.Document), op.DeltaModel.Patches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply initial state patches: %w", err)
+	}
+
+	internal[document.IDProperty] = didID
+
+	result, err := didvalidator.New(nil).TransformDocument(internal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform initial state doc: %w", err)
+	}
+
+	docBytes, err := json.Marshal(result.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	return docdid.ParseDocument(docBytes)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+)
+
+func TestVDRI_ReadLongForm(t *testing.T) {
+	suffix, initialState := longFormDID(t)
+	didID := "did:trustbloc:testnet:" + suffix
+
+	v := New(WithResolverURL("url"))
+	v.getHTTPVDRI = func(url string) (vdri, error) {
+		return nil, fmt.Errorf("unexpected network access")
+	}
+
+	t.Run("success", func(t *testing.T) {
+		doc, metadata, err := v.ReadWithMetadata(didID + "?" + initialStateParam + "=" + initialState)
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+		require.Len(t, doc.PublicKey, 1)
+		require.Equal(t, didID+"#key1", doc.PublicKey[0].ID)
+		require.Len(t, doc.Service, 1)
+		require.False(t, metadata.Published)
+		require.Equal(t, initialState, metadata.InitialState)
+
+		doc, err = v.Read(didID + "?" + initialStateParam + "=" + initialState)
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+	})
+
+	t.Run("success - with hashlink", func(t *testing.T) {
+		doc, err := v.Read(didID + "?" + initialStateParam + "=" + initialState)
+		require.NoError(t, err)
+
+		hl, err := Hashlink(doc)
+		require.NoError(t, err)
+
+		_, err = v.Read(didID + "?" + initialStateParam + "=" + initialState + "&hl=" + hl)
+		require.NoError(t, err)
+	})
+
+	t.Run("success - short-form did is resolved from the network", func(t *testing.T) {
+		v.getHTTPVDRI = httpVdriFunc(&docdid.Doc{ID: didID}, nil)
+		defer func() {
+			v.getHTTPVDRI = func(url string) (vdri, error) {
+				return nil, fmt.Errorf("unexpected network access")
+			}
+		}()
+
+		doc, metadata, err := v.ReadWithMetadata(didID, vdriapi.WithNoCache(true))
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+		require.True(t, metadata.Published)
+		require.Empty(t, metadata.InitialState)
+	})
+
+	t.Run("failure - suffix doesn't match initial state", func(t *testing.T) {
+		_, err := v.Read("did:trustbloc:testnet:other?" + initialStateParam + "=" + initialState)
+		require.EqualError(t, err, "did did:trustbloc:testnet:other doesn't match its initial state")
+	})
+
+	t.Run("failure - invalid initial state", func(t *testing.T) {
+		_, err := v.Read(didID + "?" + initialStateParam + "=abc")
+		require.EqualError(t, err, "initial state should have two parts: suffix data and delta")
+
+		_, err = v.Read(didID + "?" + initialStateParam + "=abc.def")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid initial state")
+	})
+
+	t.Run("failure - wrong did", func(t *testing.T) {
+		_, err := v.Read("did:trustbloc:" + suffix + "?" + initialStateParam + "=" + initialState)
+		require.EqualError(t, err, "wrong did did:trustbloc:"+suffix)
+	})
+
+	t.Run("failure - other parameters", func(t *testing.T) {
+		_, err := v.Read(didID + "?" + initialStateParam + "=" + initialState + "&version-id=1")
+		require.EqualError(t, err, "long-form did must not have other parameters")
+		require.Equal(t, errcode.InvalidDID, errcode.Of(err))
+	})
+}
+
+// longFormDID returns the suffix and initial state of a new long-form DID
+func longFormDID(t *testing.T) (string, string) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(pub)
+	require.NoError(t, err)
+
+	c, err := commitment.Calculate(jwk, sha2_256)
+	require.NoError(t, err)
+
+	doc := &did.Doc{
+		PublicKey: []did.PublicKey{{ID: "key1", Type: did.JWSVerificationKey2020, Encoding: did.PublicKeyEncodingJwk,
+			KeyType: did.Ed25519KeyType, Value: pub, Purpose: []string{did.KeyPurposeGeneral}}},
+		Service: []docdid.Service{{ID: "hub", Type: "hub", ServiceEndpoint: "https://example.com/hub"}},
+	}
+
+	docBytes, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	reqBytes, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument:     string(docBytes),
+		RecoveryCommitment: c,
+		UpdateCommitment:   c,
+		MultihashCode:      sha2_256,
+	})
+	require.NoError(t, err)
+
+	var req model.CreateRequest
+	require.NoError(t, json.Unmarshal(reqBytes, &req))
+
+	suffix, err := docutil.CalculateUniqueSuffix(req.SuffixData, sha2_256)
+	require.NoError(t, err)
+
+	return suffix, req.SuffixData + initialStateSeparator + req.Delta
+}
@@ -13,10 +13,12 @@ import (
 	"time"
 
 	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/cbor"
 )
 
 /*
-A consortium config file is a JWS, signed by the stakeholders,
+A consortium config file is a JWS (or a COSE message with a CBOR payload), signed by the stakeholders,
 with the payload being a JSON object containing:
   - The domain name of the consortium
   - Consortium policy configuration settings
@@ -24,6 +26,7 @@ with the payload being a JSON object containing:
     - The web domain where its configuration can be found
     - The did:trustbloc DID of the stakeholder
   - The hash of the previous version of this config file
+  - The version (sequence number) of this config file
 */
 
 // Consortium holds the configuration for a consortium, which is signed by stakeholders
@@ -36,12 +39,55 @@ type Consortium struct {
 	Members []*StakeholderListElement `json:"members"`
 	// Previous contains a hashlink to the previous version of this file. Optional.
 	Previous string `json:"previous,omitempty"`
+	// Version is the sequence number of this file, incremented with each new version. Resolvers refuse
+	// a config with a lower version than the one they already trust, preventing rollbacks.
+	Version uint64 `json:"version,omitempty"`
 }
 
-// ConsortiumPolicy holds consortium policy configuration
+// ConsortiumPolicy holds consortium policy configuration, as evaluated by the policy package
 type ConsortiumPolicy struct {
-	Cache      CacheControl `json:"cache"`
-	NumQueries int          `json:"num-queries"`
+	Cache CacheControl `json:"cache"`
+	// NumQueries is the number of stakeholders to query, all of them if 0
+	NumQueries int `json:"num_queries,omitempty"`
+	// HistoryHash is the hash algorithm identifying previous versions of the config file, SHA256 if empty
+	HistoryHash string `json:"history_hash,omitempty"`
+	// VerifyHistory requires each new version of the config file to reference the version it replaces
+	VerifyHistory bool `json:"verify_history,omitempty"`
+	// Sidetree holds the parameters of sidetree requests
+	Sidetree *SidetreePolicy `json:"sidetree,omitempty"`
+}
+
+// SidetreePolicy holds the sidetree parameters of a consortium
+type SidetreePolicy struct {
+	HashAlgorithm         string `json:"hash_algorithm,omitempty"`
+	KeyAlgorithm          string `json:"key_algorithm,omitempty"`
+	MaxEncodedHashLength  uint64 `json:"max_encoded_hash_length,omitempty"`
+	MaxOperationSize      uint64 `json:"max_operation_size,omitempty"`
+	GenesisTime           uint64 `json:"genesis_time,omitempty"`
+	MaxOperationsPerBatch uint64 `json:"max_operations_per_batch,omitempty"`
+}
+
+// UnmarshalJSON unmarshals a consortium policy, accepting the legacy `num-queries` setting
+func (p *ConsortiumPolicy) UnmarshalJSON(data []byte) error {
+	type policy ConsortiumPolicy
+
+	var legacy struct {
+		NumQueries *int `json:"num-queries"`
+	}
+
+	if err := json.Unmarshal(data, (*policy)(p)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if p.NumQueries == 0 && legacy.NumQueries != nil {
+		p.NumQueries = *legacy.NumQueries
+	}
+
+	return nil
 }
 
 // CacheControl holds cache settings for this file,
@@ -72,6 +118,21 @@ type PublicKey struct {
 type ConsortiumFileData struct {
 	Config *Consortium
 	JWS    *jose.JSONWebSignature
+	// COSE is the signed message of a CBOR encoded config file, in which case JWS is nil
+	COSE *COSEMessage
+}
+
+// Payload returns the signed payload of the consortium file
+func (c ConsortiumFileData) Payload() []byte {
+	if c.COSE != nil {
+		return c.COSE.Payload
+	}
+
+	if c.JWS != nil {
+		return c.JWS.UnsafePayloadWithoutVerification()
+	}
+
+	return nil
 }
 
 // CacheLifetime returns the cache lifetime of the consortium file before it needs to be checked for an update
@@ -83,9 +144,20 @@ func (c ConsortiumFileData) CacheLifetime() (time.Duration, error) {
 	return time.Duration(c.Config.Policy.Cache.MaxAge) * time.Second, nil
 }
 
+// checkMembers refuses null members, which the JSON payload of an untrusted config may contain
+func (c *Consortium) checkMembers() error {
+	for i, member := range c.Members {
+		if member == nil {
+			return fmt.Errorf("consortium config member %d is null", i)
+		}
+	}
+
+	return nil
+}
+
 // ParseConsortium parses the contents of a consortium file into a ConsortiumFileData object
 func ParseConsortium(data []byte) (*ConsortiumFileData, error) {
-	jws, err := jose.ParseSigned(string(data))
+	jws, err := parseJWS(data)
 	if err != nil {
 		return nil, errors.New("consortium config data should be a JWS")
 	}
@@ -99,8 +171,43 @@ func ParseConsortium(data []byte) (*ConsortiumFileData, error) {
 		return nil, err
 	}
 
+	err = config.checkMembers()
+	if err != nil {
+		return nil, err
+	}
+
 	return &ConsortiumFileData{
 		Config: &config,
 		JWS:    jws,
 	}, nil
 }
+
+// ParseConsortiumCOSE parses a consortium file given as a COSE message with a CBOR encoded payload
+func ParseConsortiumCOSE(data []byte) (*ConsortiumFileData, error) {
+	msg, err := ParseCOSE(data)
+	if err != nil {
+		return nil, fmt.Errorf("consortium config data should be a COSE message: %w", err)
+	}
+
+	configBytes, err := cbor.ToJSON(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consortium config payload: %w", err)
+	}
+
+	var config Consortium
+
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.checkMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsortiumFileData{
+		Config: &config,
+		COSE:   msg,
+	}, nil
+}
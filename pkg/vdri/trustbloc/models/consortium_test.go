@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package models_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"testing"
 	"time"
 
@@ -71,6 +73,72 @@ func Test_ParseConsortium(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "invalid character")
 	})
+
+	t.Run("failure: null member", func(t *testing.T) {
+		jws := mockmodels.DummyJWSWrap(`{"domain":"foo.bar","members":[{"domain":"bar.baz"},null]}`)
+
+		_, err := ParseConsortium([]byte(jws))
+		require.EqualError(t, err, "consortium config member 1 is null")
+	})
+}
+
+func Test_ParseConsortiumCOSE(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		msg, err := mockmodels.DummyCOSEWrap(payload)
+		require.NoError(t, err)
+
+		cData, err := ParseConsortiumCOSE(msg)
+		require.NoError(t, err)
+
+		require.Nil(t, cData.JWS)
+		require.Equal(t, "foo.bar", cData.Config.Domain)
+		require.Equal(t, uint32(123456789), cData.Config.Policy.Cache.MaxAge)
+		require.Len(t, cData.Config.Members, 2)
+		require.Equal(t, cData.COSE.Payload, cData.Payload())
+	})
+
+	t.Run("failure: not a COSE message", func(t *testing.T) {
+		_, err := ParseConsortiumCOSE([]byte(mockmodels.DummyJWSWrap(payload)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "consortium config data should be a COSE message")
+	})
+
+	t.Run("failure: payload is not CBOR", func(t *testing.T) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		msg, err := SignCOSE([]byte(payload), key)
+		require.NoError(t, err)
+
+		_, err = ParseConsortiumCOSE(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid consortium config payload")
+	})
+
+	t.Run("failure: malformed payload", func(t *testing.T) {
+		msg, err := mockmodels.DummyCOSEWrap(`{"domain":1}`)
+		require.NoError(t, err)
+
+		_, err = ParseConsortiumCOSE(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot unmarshal number")
+	})
+
+	t.Run("failure: null member", func(t *testing.T) {
+		msg, err := mockmodels.DummyCOSEWrap(`{"domain":"foo.bar","members":[null]}`)
+		require.NoError(t, err)
+
+		_, err = ParseConsortiumCOSE(msg)
+		require.EqualError(t, err, "consortium config member 0 is null")
+	})
+}
+
+func TestConsortiumFileData_Payload(t *testing.T) {
+	cData, err := ParseConsortium([]byte(mockmodels.DummyJWSWrap(payload)))
+	require.NoError(t, err)
+	require.Equal(t, payload, string(cData.Payload()))
+
+	require.Nil(t, ConsortiumFileData{}.Payload())
 }
 
 func TestConsortiumFileData_CacheLifetime(t *testing.T) {
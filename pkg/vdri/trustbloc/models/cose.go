@@ -0,0 +1,380 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/cbor"
+)
+
+// COSE tags (RFC 8152)
+const (
+	coseSignTag  = 98
+	coseSign1Tag = 18
+)
+
+// COSE header labels
+const (
+	headerAlgorithm = 1
+	headerCritical  = 2
+	headerKeyID     = 4
+)
+
+// COSE algorithm identifiers, and the corresponding JWS algorithms
+var coseAlgorithms = map[int64]jose.SignatureAlgorithm{ // nolint: gochecknoglobals
+	-8: jose.EdDSA,
+	-7: jose.ES256,
+}
+
+const (
+	coseMessageParts   = 4
+	coseSignatureParts = 3
+	p256CoordinateSize = 32
+)
+
+// COSEMessage is a COSE_Sign or COSE_Sign1 signed message (RFC 8152), the CBOR counterpart of a JWS
+type COSEMessage struct {
+	Payload    []byte
+	Signatures []COSESignature
+	protected  []byte
+	sign1      bool
+	raw        []byte
+}
+
+// COSESignature is a signature of a COSE message
+type COSESignature struct {
+	Algorithm jose.SignatureAlgorithm
+	KeyID     []byte
+	protected []byte
+	signature []byte
+}
+
+// ParseCOSE parses a tagged COSE_Sign or COSE_Sign1 message. The algorithm of each signature must be in
+// its protected header, and critical header parameters aren't supported.
+func ParseCOSE(data []byte) (*COSEMessage, error) {
+	v, err := cbor.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, ok := v.(cbor.Tag)
+	if !ok || (tag.Number != coseSignTag && tag.Number != coseSign1Tag) {
+		return nil, errors.New("not a COSE_Sign or COSE_Sign1 message")
+	}
+
+	sign1 := tag.Number == coseSign1Tag
+
+	parts, ok := tag.Content.([]interface{})
+	if !ok || len(parts) != coseMessageParts {
+		return nil, errors.New("invalid COSE message structure")
+	}
+
+	msg := &COSEMessage{sign1: sign1, raw: data}
+
+	if msg.protected, ok = parts[0].([]byte); !ok {
+		return nil, errors.New("invalid COSE protected header")
+	}
+
+	if msg.Payload, ok = parts[2].([]byte); !ok {
+		return nil, errors.New("COSE message has no attached payload")
+	}
+
+	if sign1 {
+		sig, err := parseCOSESignature(parts[0], parts[1], parts[3])
+		if err != nil {
+			return nil, err
+		}
+
+		// for COSE_Sign1 the body header is the signature header
+		sig.protected = nil
+		msg.Signatures = []COSESignature{*sig}
+
+		return msg, nil
+	}
+
+	if err := checkHeaders(msg.protected, parts[1], false); err != nil {
+		return nil, err
+	}
+
+	sigs, ok := parts[3].([]interface{})
+	if !ok || len(sigs) == 0 {
+		return nil, errors.New("COSE message has no signatures")
+	}
+
+	for _, s := range sigs {
+		sigParts, ok := s.([]interface{})
+		if !ok || len(sigParts) != coseSignatureParts {
+			return nil, errors.New("invalid COSE signature structure")
+		}
+
+		sig, err := parseCOSESignature(sigParts[0], sigParts[1], sigParts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Signatures = append(msg.Signatures, *sig)
+	}
+
+	return msg, nil
+}
+
+func parseCOSESignature(protected, unprotected, signature interface{}) (*COSESignature, error) {
+	protectedBytes, ok := protected.([]byte)
+	if !ok {
+		return nil, errors.New("invalid COSE protected header")
+	}
+
+	sigBytes, ok := signature.([]byte)
+	if !ok {
+		return nil, errors.New("invalid COSE signature")
+	}
+
+	if err := checkHeaders(protectedBytes, unprotected, true); err != nil {
+		return nil, err
+	}
+
+	header, err := decodeHeader(protectedBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, ok := header[int64(headerAlgorithm)].(int64)
+	if !ok {
+		return nil, errors.New("signature algorithm is not in the protected header")
+	}
+
+	sig := &COSESignature{protected: protectedBytes, signature: sigBytes}
+
+	if sig.Algorithm, ok = coseAlgorithms[alg]; !ok {
+		return nil, fmt.Errorf("unsupported COSE algorithm %d", alg)
+	}
+
+	if kid, ok := header[int64(headerKeyID)].([]byte); ok {
+		sig.KeyID = kid
+	} else if kid, ok := unprotected.(map[interface{}]interface{})[uint64(headerKeyID)].([]byte); ok {
+		sig.KeyID = kid
+	}
+
+	return sig, nil
+}
+
+// decodeHeader decodes a protected header, with integer labels as int64
+func decodeHeader(protected []byte) (map[interface{}]interface{}, error) {
+	if len(protected) == 0 {
+		return map[interface{}]interface{}{}, nil
+	}
+
+	v, err := cbor.Unmarshal(protected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid COSE protected header: %w", err)
+	}
+
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("invalid COSE protected header")
+	}
+
+	header := make(map[interface{}]interface{}, len(m))
+
+	for k, val := range m {
+		if u, ok := k.(uint64); ok {
+			k = int64(u)
+		}
+
+		if u, ok := val.(uint64); ok {
+			val = int64(u)
+		}
+
+		header[k] = val
+	}
+
+	return header, nil
+}
+
+// checkHeaders returns an error if a header relies on its unprotected part for the algorithm or critical
+// parameters, or has critical parameters, none of which are understood
+func checkHeaders(protected []byte, unprotected interface{}, algRequired bool) error {
+	unprotectedMap, ok := unprotected.(map[interface{}]interface{})
+	if !ok {
+		return errors.New("invalid COSE unprotected header")
+	}
+
+	for _, label := range []uint64{headerAlgorithm, headerCritical} {
+		if _, ok := unprotectedMap[label]; ok {
+			return errors.New("signature relies on unprotected header parameters")
+		}
+	}
+
+	header, err := decodeHeader(protected)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := header[int64(headerCritical)]; ok {
+		return errors.New("unsupported critical header parameters")
+	}
+
+	if _, ok := header[int64(headerAlgorithm)]; ok && !algRequired {
+		return errors.New("unexpected algorithm in the body header")
+	}
+
+	return nil
+}
+
+// Bytes returns the encoded message
+func (m *COSEMessage) Bytes() []byte {
+	return m.raw
+}
+
+// Verify verifies the message with the given public key (ed25519.PublicKey or *ecdsa.PublicKey), accepting only
+// signatures with one of the given algorithms (DefaultSignatureAlgorithms if empty). It returns the payload.
+func (m *COSEMessage) Verify(key interface{}, allowed ...jose.SignatureAlgorithm) ([]byte, error) {
+	if len(allowed) == 0 {
+		allowed = DefaultSignatureAlgorithms
+	}
+
+	var errs []string
+
+	for i := range m.Signatures {
+		sig := &m.Signatures[i]
+
+		if !algorithmAllowed(sig.Algorithm, allowed) {
+			errs = append(errs, fmt.Sprintf("signature algorithm '%s' is not allowed", sig.Algorithm))
+
+			continue
+		}
+
+		toBeSigned, err := m.sigStructure(sig)
+		if err != nil {
+			return nil, err
+		}
+
+		if verifySignature(sig.Algorithm, key, toBeSigned, sig.signature) {
+			return m.Payload, nil
+		}
+
+		errs = append(errs, "signature verification failed")
+	}
+
+	return nil, fmt.Errorf("failed to verify COSE message: [%s]", strings.Join(errs, ", "))
+}
+
+func (m *COSEMessage) sigStructure(sig *COSESignature) ([]byte, error) {
+	if m.sign1 {
+		return cbor.Marshal([]interface{}{"Signature1", m.protected, []byte{}, m.Payload})
+	}
+
+	return cbor.Marshal([]interface{}{"Signature", m.protected, sig.protected, []byte{}, m.Payload})
+}
+
+func algorithmAllowed(alg jose.SignatureAlgorithm, allowed []jose.SignatureAlgorithm) bool {
+	for _, a := range allowed {
+		if a == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+func verifySignature(alg jose.SignatureAlgorithm, key interface{}, toBeSigned, signature []byte) bool {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return alg == jose.EdDSA && ed25519.Verify(k, toBeSigned, signature)
+	case *ecdsa.PublicKey:
+		if alg != jose.ES256 || k.Curve != elliptic.P256() || len(signature) != 2*p256CoordinateSize {
+			return false
+		}
+
+		hash := sha256.Sum256(toBeSigned)
+		r := new(big.Int).SetBytes(signature[:p256CoordinateSize])
+		s := new(big.Int).SetBytes(signature[p256CoordinateSize:])
+
+		return ecdsa.Verify(k, hash[:], r, s)
+	}
+
+	return false
+}
+
+// SignCOSE signs a payload with the given keys (ed25519.PrivateKey or P-256 *ecdsa.PrivateKey), returning a
+// tagged COSE_Sign message with one signature per key
+func SignCOSE(payload []byte, keys ...crypto.Signer) ([]byte, error) {
+	msg := &COSEMessage{Payload: payload, protected: []byte{}}
+	signatures := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		var alg int64
+
+		switch k := key.(type) {
+		case ed25519.PrivateKey:
+			alg = -8
+		case *ecdsa.PrivateKey:
+			if k.Curve != elliptic.P256() {
+				return nil, errors.New("unsupported curve " + k.Curve.Params().Name)
+			}
+
+			alg = -7
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+
+		protected, err := cbor.Marshal(map[interface{}]interface{}{headerAlgorithm: alg})
+		if err != nil {
+			return nil, err
+		}
+
+		toBeSigned, err := msg.sigStructure(&COSESignature{protected: protected})
+		if err != nil {
+			return nil, err
+		}
+
+		signature, err := sign(key, toBeSigned)
+		if err != nil {
+			return nil, err
+		}
+
+		signatures = append(signatures, []interface{}{protected, map[interface{}]interface{}{}, signature})
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: coseSignTag, Content: []interface{}{
+		msg.protected, map[interface{}]interface{}{}, payload, signatures,
+	}})
+}
+
+func sign(key crypto.Signer, toBeSigned []byte) ([]byte, error) {
+	k, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return key.Sign(rand.Reader, toBeSigned, crypto.Hash(0))
+	}
+
+	hash := sha256.Sum256(toBeSigned)
+
+	r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// r and s are left-padded to the coordinate size
+	signature := make([]byte, 2*p256CoordinateSize)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+
+	copy(signature[p256CoordinateSize-len(rBytes):], rBytes)
+	copy(signature[2*p256CoordinateSize-len(sBytes):], sBytes)
+
+	return signature, nil
+}
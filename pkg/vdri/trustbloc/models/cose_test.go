@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/cbor"
+	. "github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestCOSE(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	payload := []byte("payload")
+
+	t.Run("success - COSE_Sign", func(t *testing.T) {
+		data, err := SignCOSE(payload, edPriv, ecPriv)
+		require.NoError(t, err)
+
+		msg, err := ParseCOSE(data)
+		require.NoError(t, err)
+		require.Equal(t, payload, msg.Payload)
+		require.Equal(t, data, msg.Bytes())
+		require.Len(t, msg.Signatures, 2)
+		require.Equal(t, jose.EdDSA, msg.Signatures[0].Algorithm)
+		require.Equal(t, jose.ES256, msg.Signatures[1].Algorithm)
+
+		verified, err := msg.Verify(edPub)
+		require.NoError(t, err)
+		require.Equal(t, payload, verified)
+
+		verified, err = msg.Verify(&ecPriv.PublicKey)
+		require.NoError(t, err)
+		require.Equal(t, payload, verified)
+
+		_, err = msg.Verify(otherPub)
+		require.EqualError(t, err, "failed to verify COSE message: "+
+			"[signature verification failed, signature verification failed]")
+
+		_, err = msg.Verify(edPub, jose.ES256)
+		require.EqualError(t, err, "failed to verify COSE message: "+
+			"[signature algorithm 'EdDSA' is not allowed, signature verification failed]")
+	})
+
+	t.Run("success - COSE_Sign1", func(t *testing.T) {
+		protected, err := cbor.Marshal(map[interface{}]interface{}{1: -8, 4: []byte("key1")})
+		require.NoError(t, err)
+
+		toBeSigned, err := cbor.Marshal([]interface{}{"Signature1", protected, []byte{}, payload})
+		require.NoError(t, err)
+
+		data, err := cbor.Marshal(cbor.Tag{Number: 18, Content: []interface{}{
+			protected, map[interface{}]interface{}{}, payload, ed25519.Sign(edPriv, toBeSigned),
+		}})
+		require.NoError(t, err)
+
+		msg, err := ParseCOSE(data)
+		require.NoError(t, err)
+		require.Equal(t, []byte("key1"), msg.Signatures[0].KeyID)
+
+		verified, err := msg.Verify(edPub)
+		require.NoError(t, err)
+		require.Equal(t, payload, verified)
+
+		_, err = msg.Verify(&ecPriv.PublicKey)
+		require.Error(t, err)
+	})
+
+	t.Run("failure - sign with unsupported key", func(t *testing.T) {
+		p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = SignCOSE(payload, p384)
+		require.EqualError(t, err, "unsupported curve P-384")
+	})
+
+	t.Run("failure - invalid messages", func(t *testing.T) {
+		protected, err := cbor.Marshal(map[interface{}]interface{}{1: -8})
+		require.NoError(t, err)
+
+		unsupportedAlg, err := cbor.Marshal(map[interface{}]interface{}{1: -35})
+		require.NoError(t, err)
+
+		critical, err := cbor.Marshal(map[interface{}]interface{}{1: -8, 2: []interface{}{"b64"}})
+		require.NoError(t, err)
+
+		noHeader := map[interface{}]interface{}{}
+
+		sign := func(body, sigProtected []byte, unprotected map[interface{}]interface{}) interface{} {
+			return cbor.Tag{Number: 98, Content: []interface{}{body, noHeader, payload,
+				[]interface{}{[]interface{}{sigProtected, unprotected, []byte("sig")}}}}
+		}
+
+		tests := []struct {
+			name string
+			msg  interface{}
+			err  string
+		}{
+			{"not tagged", []interface{}{}, "not a COSE_Sign or COSE_Sign1 message"},
+			{"wrong tag", cbor.Tag{Number: 17, Content: []interface{}{}}, "not a COSE_Sign or COSE_Sign1 message"},
+			{"wrong structure", cbor.Tag{Number: 98, Content: []interface{}{}}, "invalid COSE message structure"},
+			{"invalid body header", cbor.Tag{Number: 98, Content: []interface{}{"", noHeader, payload, nil}},
+				"invalid COSE protected header"},
+			{"detached payload", cbor.Tag{Number: 98, Content: []interface{}{[]byte{}, noHeader, nil, nil}},
+				"COSE message has no attached payload"},
+			{"no signatures", cbor.Tag{Number: 98, Content: []interface{}{[]byte{}, noHeader, payload, nil}},
+				"COSE message has no signatures"},
+			{"algorithm in body", sign(protected, protected, noHeader), "unexpected algorithm in the body header"},
+			{"no algorithm", sign([]byte{}, []byte{}, noHeader),
+				"signature algorithm is not in the protected header"},
+			{"unprotected algorithm", sign([]byte{}, []byte{}, map[interface{}]interface{}{1: -8}),
+				"signature relies on unprotected header parameters"},
+			{"unsupported algorithm", sign([]byte{}, unsupportedAlg, noHeader), "unsupported COSE algorithm -35"},
+			{"critical parameters", sign([]byte{}, critical, noHeader), "unsupported critical header parameters"},
+			{"invalid header", sign([]byte{}, []byte{0xa1}, noHeader),
+				"invalid COSE protected header: cbor: unexpected end of data"},
+			{"header not a map", sign([]byte{}, []byte{0x01}, noHeader), "invalid COSE protected header"},
+			{"invalid signature structure", cbor.Tag{Number: 98, Content: []interface{}{[]byte{}, noHeader, payload,
+				[]interface{}{[]interface{}{}}}}, "invalid COSE signature structure"},
+			{"invalid signature", cbor.Tag{Number: 98, Content: []interface{}{[]byte{}, noHeader, payload,
+				[]interface{}{[]interface{}{protected, noHeader, "sig"}}}}, "invalid COSE signature"},
+		}
+
+		for _, tc := range tests {
+			data, err := cbor.Marshal(tc.msg)
+			require.NoError(t, err)
+
+			_, err = ParseCOSE(data)
+			require.EqualError(t, err, tc.err, tc.name)
+		}
+
+		_, err = ParseCOSE([]byte{0xd8})
+		require.Error(t, err)
+	})
+}
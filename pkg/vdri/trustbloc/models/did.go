@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	didScheme    = "did"
+	didMethod    = "trustbloc"
+	didParts     = 4
+	domainPart   = 2
+	suffixPart   = 3
+	invalidChars = "/?#"
+)
+
+// DID is a did:trustbloc DID, of the form did:trustbloc:<consortium domain>:<unique suffix>
+type DID struct {
+	// Domain is the domain of the consortium of the DID
+	Domain string
+	// Suffix is the unique suffix of the DID, derived from its create operation
+	Suffix string
+}
+
+// ParseDID parses a short-form did:trustbloc DID. DID URLs, with a path, query or fragment, are refused.
+func ParseDID(didID string) (*DID, error) {
+	parts := strings.Split(didID, ":")
+	if len(parts) != didParts || parts[0] != didScheme || parts[1] != didMethod {
+		return nil, fmt.Errorf("invalid did:trustbloc DID '%s'", didID)
+	}
+
+	d := &DID{Domain: parts[domainPart], Suffix: parts[suffixPart]}
+
+	if d.Domain == "" || strings.ContainsAny(d.Domain, invalidChars) {
+		return nil, fmt.Errorf("invalid consortium domain in DID '%s'", didID)
+	}
+
+	if !isBase64URL(d.Suffix) {
+		return nil, fmt.Errorf("invalid unique suffix in DID '%s'", didID)
+	}
+
+	return d, nil
+}
+
+// String returns the DID
+func (d *DID) String() string {
+	return didScheme + ":" + didMethod + ":" + d.Domain + ":" + d.Suffix
+}
+
+func isBase64URL(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+
+	return true
+}
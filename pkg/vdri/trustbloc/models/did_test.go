@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestParseDID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		d, err := ParseDID("did:trustbloc:testnet.example.com:EiD_suffix-123")
+		require.NoError(t, err)
+		require.Equal(t, "testnet.example.com", d.Domain)
+		require.Equal(t, "EiD_suffix-123", d.Suffix)
+		require.Equal(t, "did:trustbloc:testnet.example.com:EiD_suffix-123", d.String())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tests := []struct {
+			did string
+			err string
+		}{
+			{did: "", err: "invalid did:trustbloc DID"},
+			{did: "did:trustbloc:EiDsuffix", err: "invalid did:trustbloc DID"},
+			{did: "did:example:testnet:EiDsuffix", err: "invalid did:trustbloc DID"},
+			{did: "uri:trustbloc:testnet:EiDsuffix", err: "invalid did:trustbloc DID"},
+			{did: "did:trustbloc:testnet:EiDsuffix:extra", err: "invalid did:trustbloc DID"},
+			{did: "did:trustbloc::EiDsuffix", err: "invalid consortium domain"},
+			{did: "did:trustbloc:testnet/path:EiDsuffix", err: "invalid consortium domain"},
+			{did: "did:trustbloc:testnet:", err: "invalid unique suffix"},
+			{did: "did:trustbloc:testnet:EiDsuffix#key1", err: "invalid unique suffix"},
+			{did: "did:trustbloc:testnet:EiDsuffix?-initial-state=abc", err: "invalid unique suffix"},
+			{did: "did:trustbloc:testnet:EiD\x00", err: "invalid unique suffix"},
+		}
+
+		for _, tc := range tests {
+			_, err := ParseDID(tc.did)
+			require.Error(t, err, tc.did)
+			require.Contains(t, err.Error(), tc.err, tc.did)
+		}
+	})
+}
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package models
 
+import "encoding/json"
+
 // DIDConfiguration asserts DID ownership over web domains using domain linkage assertions.
 // Implements https://identity.foundation/specs/did-configuration/
 type DIDConfiguration struct {
@@ -24,3 +26,14 @@ type DomainLinkageAssertionClaims struct {
 	Domain string `json:"domain"`
 	Exp    int64  `json:"exp,omitempty"`
 }
+
+// ParseDIDConfiguration parses a did-configuration, as served by a domain at /.well-known/did-configuration.json
+func ParseDIDConfiguration(data []byte) (*DIDConfiguration, error) {
+	var didConfig DIDConfiguration
+
+	if err := json.Unmarshal(data, &didConfig); err != nil {
+		return nil, err
+	}
+
+	return &didConfig, nil
+}
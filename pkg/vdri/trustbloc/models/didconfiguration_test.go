@@ -0,0 +1,30 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestParseDIDConfiguration(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		didConfig, err := ParseDIDConfiguration([]byte(`{"entries":[{"did":"did:example:123","jwt":"abc"}]}`))
+		require.NoError(t, err)
+		require.Equal(t, []DomainLinkageAssertion{{DID: "did:example:123", JWT: "abc"}}, didConfig.Entries)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		for _, data := range []string{"", "{", `{"entries":{}}`, `{"entries":[{"did":1}]}`} {
+			_, err := ParseDIDConfiguration([]byte(data))
+			require.Error(t, err, data)
+		}
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/square/go-jose/v3"
 )
 
 const (
@@ -24,8 +25,15 @@ type DIDResolutionResult struct {
 	MethodMetadata MethodMetaData  `json:"methodMetadata"`
 }
 
-// MethodMetaData dummy object
-type MethodMetaData struct{}
+// MethodMetaData holds the method specific metadata of a resolved DID doc
+type MethodMetaData struct {
+	// Published is false if the doc was constructed from the initial state of a long-form DID
+	Published bool `json:"published,omitempty"`
+	// InitialState is the initial state of a long-form DID
+	InitialState string `json:"initialState,omitempty"`
+	// Stale is true if the doc was served from the cache after its TTL expired, while being refreshed
+	Stale bool `json:"stale,omitempty"`
+}
 
 // MakeDIDResolutionResult constructs, marshals, and returns a DID resolution result containing only a DID document
 func MakeDIDResolutionResult(doc *did.Doc) ([]byte, error) {
@@ -41,3 +49,57 @@ func MakeDIDResolutionResult(doc *did.Doc) ([]byte, error) {
 
 	return json.Marshal(drr)
 }
+
+// SignDIDResolutionResult signs a marshalled DID resolution result as a compact JWS, eg. so that clients of a
+// resolver proxy can verify the results it serves with its pinned key
+func SignDIDResolutionResult(result []byte, key jose.SigningKey) (string, error) {
+	signer, err := jose.NewSigner(key, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create signer: %w", err)
+	}
+
+	jws, err := signer.Sign(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign did resolution result: %w", err)
+	}
+
+	return jws.CompactSerialize()
+}
+
+// VerifyDIDResolutionResult verifies a DID resolution result signed as a JWS with the given public key and
+// one of the allowed algorithms (DefaultSignatureAlgorithms if none), and returns its DID doc
+func VerifyDIDResolutionResult(data []byte, key interface{}, algs ...jose.SignatureAlgorithm) (*did.Doc, error) {
+	jws, err := jose.ParseSigned(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("did resolution result should be a JWS: %w", err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return nil, fmt.Errorf("did resolution result should have a single signature")
+	}
+
+	if err = CheckSignatureHeaders(&jws.Signatures[0]); err != nil {
+		return nil, err
+	}
+
+	if err = CheckSignatureAlgorithm(&jws.Signatures[0], algs); err != nil {
+		return nil, err
+	}
+
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify did resolution result: %w", err)
+	}
+
+	result := &DIDResolutionResult{}
+	if err = json.Unmarshal(payload, result); err != nil {
+		return nil, fmt.Errorf("invalid did resolution result: %w", err)
+	}
+
+	doc, err := did.ParseDocument(result.DIDDocument)
+	if err != nil {
+		return nil, fmt.Errorf("invalid did document: %w", err)
+	}
+
+	return doc, nil
+}
@@ -8,10 +8,13 @@ package models
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"testing"
 
 	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
+	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +33,60 @@ func TestMakeDIDResolutionResult(t *testing.T) {
 
 	require.True(t, bytes.Equal(docBytes, result.DIDDocument))
 }
+
+func TestSignDIDResolutionResult(t *testing.T) {
+	mockdoc := mockdiddoc.GetMockDIDDoc()
+
+	resultBytes, err := MakeDIDResolutionResult(mockdoc)
+	require.NoError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jws, err := SignDIDResolutionResult(resultBytes, jose.SigningKey{Algorithm: jose.EdDSA, Key: priv})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		doc, err := VerifyDIDResolutionResult([]byte(jws), pub)
+		require.NoError(t, err)
+		require.Equal(t, mockdoc.ID, doc.ID)
+	})
+
+	t.Run("failure - other key", func(t *testing.T) {
+		otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = VerifyDIDResolutionResult([]byte(jws), otherPub)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify did resolution result")
+	})
+
+	t.Run("failure - algorithm not allowed", func(t *testing.T) {
+		_, err := VerifyDIDResolutionResult([]byte(jws), pub, jose.ES256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not allowed")
+	})
+
+	t.Run("failure - not a JWS", func(t *testing.T) {
+		_, err := VerifyDIDResolutionResult(resultBytes, pub)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did resolution result should be a JWS")
+	})
+
+	t.Run("failure - invalid payload", func(t *testing.T) {
+		for _, payload := range []string{"{", `{"didDocument": {}}`} {
+			invalid, err := SignDIDResolutionResult([]byte(payload), jose.SigningKey{Algorithm: jose.EdDSA, Key: priv})
+			require.NoError(t, err)
+
+			_, err = VerifyDIDResolutionResult([]byte(invalid), pub)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid did")
+		}
+	})
+
+	t.Run("failure - invalid signing key", func(t *testing.T) {
+		_, err := SignDIDResolutionResult(resultBytes, jose.SigningKey{Algorithm: jose.EdDSA, Key: "key"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create signer")
+	})
+}
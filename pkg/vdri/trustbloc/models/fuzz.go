@@ -0,0 +1,46 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+// Entry points for go-fuzz (https://github.com/dvyukov/go-fuzz), fuzzing the parsers of the untrusted data
+// fetched from consortium and stakeholder servers:
+//
+//    go-fuzz-build -func FuzzConsortium github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models
+//    go-fuzz -bin models-fuzz.zip
+//
+// Each entry point returns 1 when the input parsed, giving it priority in the corpus, and 0 otherwise.
+
+// FuzzConsortium fuzzes the parsing of JWS and COSE consortium config files
+func FuzzConsortium(data []byte) int {
+	return fuzzResult(ParseConsortium(data)) | fuzzResult(ParseConsortiumCOSE(data))
+}
+
+// FuzzStakeholder fuzzes the parsing of JWS and COSE stakeholder config files
+func FuzzStakeholder(data []byte) int {
+	return fuzzResult(ParseStakeholder(data)) | fuzzResult(ParseStakeholderCOSE(data))
+}
+
+// FuzzDIDConfiguration fuzzes the parsing of did-configurations
+func FuzzDIDConfiguration(data []byte) int {
+	return fuzzResult(ParseDIDConfiguration(data))
+}
+
+// FuzzDID fuzzes the parsing of did:trustbloc DIDs
+func FuzzDID(data []byte) int {
+	return fuzzResult(ParseDID(string(data)))
+}
+
+func fuzzResult(_ interface{}, err error) int {
+	if err != nil {
+		return 0
+	}
+
+	return 1
+}
@@ -0,0 +1,194 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Names of the JSON Schemas of the models, as accepted by Schema and Validate
+const (
+	// ConsortiumSchema describes the payload of a consortium config file
+	ConsortiumSchema = "consortium"
+	// StakeholderSchema describes the payload of a stakeholder config file
+	StakeholderSchema = "stakeholder"
+	// EndpointSchema describes a sidetree endpoint of a consortium
+	EndpointSchema = "endpoint"
+	// DIDConfigurationSchema describes a did-configuration, as served at /.well-known/did-configuration.json
+	DIDConfigurationSchema = "did-configuration"
+)
+
+const cacheControlSchema = `{
+      "type": "object",
+      "properties": {
+        "max_age": {"type": "integer", "minimum": 0, "maximum": 4294967295}
+      }
+    }`
+
+const consortiumSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "did:trustbloc consortium config",
+  "type": "object",
+  "required": ["domain", "policy", "members"],
+  "properties": {
+    "domain": {"type": "string", "minLength": 1},
+    "policy": {
+      "type": "object",
+      "properties": {
+        "cache": ` + cacheControlSchema + `,
+        "num_queries": {"type": "integer", "minimum": 0},
+        "num-queries": {"type": "integer", "minimum": 0},
+        "history_hash": {"type": "string"},
+        "verify_history": {"type": "boolean"},
+        "sidetree": {
+          "type": "object",
+          "properties": {
+            "hash_algorithm": {"type": "string"},
+            "key_algorithm": {"type": "string"},
+            "max_encoded_hash_length": {"type": "integer", "minimum": 0},
+            "max_operation_size": {"type": "integer", "minimum": 0},
+            "genesis_time": {"type": "integer", "minimum": 0},
+            "max_operations_per_batch": {"type": "integer", "minimum": 0}
+          }
+        }
+      }
+    },
+    "members": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "required": ["domain", "did"],
+        "properties": {
+          "domain": {"type": "string", "minLength": 1},
+          "did": {"type": "string", "pattern": "^did:[a-z0-9]+:"},
+          "public_key": {
+            "type": "object",
+            "properties": {
+              "id": {"type": "string"},
+              "jwk": {"type": "object"}
+            }
+          }
+        }
+      }
+    },
+    "previous": {"type": "string"},
+    "version": {"type": "integer", "minimum": 0}
+  }
+}`
+
+const stakeholderSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "did:trustbloc stakeholder config",
+  "type": "object",
+  "required": ["domain", "did", "policy", "endpoints"],
+  "properties": {
+    "domain": {"type": "string", "minLength": 1},
+    "did": {"type": "string", "pattern": "^did:[a-z0-9]+:"},
+    "policy": {
+      "type": "object",
+      "properties": {
+        "cache": ` + cacheControlSchema + `
+      }
+    },
+    "endpoints": {
+      "type": "array",
+      "items": {"type": "string", "format": "uri"}
+    },
+    "jwks_url": {"type": "string", "format": "uri"},
+    "previous": {"type": "string"}
+  }
+}`
+
+const endpointSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "did:trustbloc sidetree endpoint",
+  "type": "object",
+  "required": ["URL"],
+  "properties": {
+    "URL": {"type": "string", "format": "uri"},
+    "Domain": {"type": "string"}
+  }
+}`
+
+const didConfigurationSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "did-configuration",
+  "type": "object",
+  "required": ["entries"],
+  "properties": {
+    "entries": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["did", "jwt"],
+        "properties": {
+          "did": {"type": "string", "pattern": "^did:[a-z0-9]+:"},
+          "jwt": {"type": "string", "pattern": "^[A-Za-z0-9_-]+\\.[A-Za-z0-9_-]+\\.[A-Za-z0-9_-]*$"}
+        }
+      }
+    }
+  }
+}`
+
+// nolint: gochecknoglobals
+var schemas = map[string]string{
+	ConsortiumSchema:       consortiumSchema,
+	StakeholderSchema:      stakeholderSchema,
+	EndpointSchema:         endpointSchema,
+	DIDConfigurationSchema: didConfigurationSchema,
+}
+
+// SchemaNames returns the names of the JSON Schemas of the models
+func SchemaNames() []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Schema returns the JSON Schema (draft 7) with the given name, eg. to validate files with tools in other languages
+func Schema(name string) ([]byte, error) {
+	schema, ok := schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema '%s'", name)
+	}
+
+	return []byte(schema), nil
+}
+
+// Validate validates a JSON document against the schema with the given name, eg. a config before it's signed
+// and published. Signed config files are validated by their payload.
+func Validate(name string, data []byte) error {
+	schema, err := Schema(name)
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return fmt.Errorf("failed to validate %s: %w", name, err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	problems := make([]string, len(result.Errors()))
+	for i, e := range result.Errors() {
+		problems[i] = e.String()
+	}
+
+	return fmt.Errorf("invalid %s: %s", name, strings.Join(problems, "; "))
+}
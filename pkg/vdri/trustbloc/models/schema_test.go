@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	require.Equal(t, []string{ConsortiumSchema, DIDConfigurationSchema, EndpointSchema, StakeholderSchema},
+		SchemaNames())
+
+	for _, name := range SchemaNames() {
+		schema, err := Schema(name)
+		require.NoError(t, err)
+		require.True(t, json.Valid(schema), name)
+	}
+
+	_, err := Schema("foo")
+	require.EqualError(t, err, "unknown schema 'foo'")
+}
+
+func TestValidate(t *testing.T) {
+	marshal := func(v interface{}) []byte {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	t.Run("models are valid", func(t *testing.T) {
+		require.NoError(t, Validate(ConsortiumSchema, marshal(&Consortium{
+			Domain: "consortium.net",
+			Policy: ConsortiumPolicy{Cache: CacheControl{MaxAge: 600}, NumQueries: 2,
+				Sidetree: &SidetreePolicy{HashAlgorithm: "SHA256"}},
+			Members: []*StakeholderListElement{
+				{Domain: "stakeholder.one", DID: "did:trustbloc:consortium.net:EiA",
+					PublicKey: PublicKey{ID: "did:trustbloc:consortium.net:EiA#key", JWK: []byte(`{"kty":"OKP"}`)}},
+			},
+			Version: 2,
+		})))
+
+		require.NoError(t, Validate(StakeholderSchema, marshal(&Stakeholder{
+			Domain:    "stakeholder.one",
+			DID:       "did:trustbloc:consortium.net:EiA",
+			Policy:    StakeholderSettings{Cache: CacheControl{MaxAge: 600}},
+			Endpoints: []string{"https://stakeholder.one/sidetree/0.0.1"},
+		})))
+
+		require.NoError(t, Validate(EndpointSchema, marshal(&Endpoint{
+			URL: "https://stakeholder.one/sidetree/0.0.1", Domain: "stakeholder.one"})))
+
+		require.NoError(t, Validate(DIDConfigurationSchema, marshal(&DIDConfiguration{
+			Entries: []DomainLinkageAssertion{{DID: "did:key:z6Mk", JWT: "eyJh.eyJp.c2ln"}},
+		})))
+	})
+
+	t.Run("legacy consortium policy", func(t *testing.T) {
+		require.NoError(t, Validate(ConsortiumSchema, []byte(`{"domain": "consortium.net",
+			"policy": {"num-queries": 1}, "members": [{"domain": "stakeholder.one", "did": "did:trustbloc:a:b"}]}`)))
+	})
+
+	t.Run("invalid consortium", func(t *testing.T) {
+		err := Validate(ConsortiumSchema, []byte(`{"domain": "consortium.net", "policy": {"num_queries": -1},
+			"members": [{"domain": "stakeholder.one", "did": "stakeholder"}, null]}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid consortium: ")
+		require.Contains(t, err.Error(), "policy.num_queries")
+		require.Contains(t, err.Error(), "members.0.did")
+		require.Contains(t, err.Error(), "members.1")
+	})
+
+	t.Run("invalid stakeholder", func(t *testing.T) {
+		err := Validate(StakeholderSchema, []byte(`{"domain": "stakeholder.one", "did": "did:trustbloc:a:b",
+			"policy": {"cache": {"max_age": "600"}}}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "endpoints is required")
+		require.Contains(t, err.Error(), "policy.cache.max_age")
+	})
+
+	t.Run("invalid did-configuration", func(t *testing.T) {
+		err := Validate(DIDConfigurationSchema, []byte(`{"entries": [{"did": "did:key:z6Mk", "jwt": "jwt"}]}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "entries.0.jwt")
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		err := Validate(EndpointSchema, []byte(`{"Domain": "stakeholder.one"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "URL is required")
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		err := Validate(ConsortiumSchema, []byte(`{`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to validate consortium")
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		require.EqualError(t, Validate("foo", []byte(`{}`)), "unknown schema 'foo'")
+	})
+}
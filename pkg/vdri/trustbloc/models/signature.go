@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/square/go-jose/v3"
+)
+
+// DefaultSignatureAlgorithms are the JWS algorithms accepted for config files, did-configurations and
+// other signed data when no allowlist is configured
+var DefaultSignatureAlgorithms = []jose.SignatureAlgorithm{jose.EdDSA, jose.ES256} // nolint: gochecknoglobals
+
+// headers that mustn't be taken from the unprotected header, as they change how a signature is verified
+var securityHeaders = []jose.HeaderKey{ // nolint: gochecknoglobals
+	"alg", "kid", "jwk", "jku", "x5u", "x5t", "x5t#S256", "crit", "b64",
+}
+
+// understood critical header parameters, as processed by go-jose
+var supportedCritical = map[string]bool{"b64": true} // nolint: gochecknoglobals
+
+// CheckSignatureHeaders returns an error if a JWS signature relies on its unprotected header for the algorithm,
+// key or critical extensions, or if its protected header marks a parameter critical that isn't understood or present
+func CheckSignatureHeaders(sig *jose.Signature) error {
+	if sig.Protected.Algorithm == "" {
+		return errors.New("signature algorithm is not in the protected header")
+	}
+
+	unprotected := sig.Unprotected
+	if unprotected.Algorithm != "" || unprotected.KeyID != "" || unprotected.JSONWebKey != nil {
+		return errors.New("signature relies on unprotected header parameters")
+	}
+
+	for _, h := range securityHeaders {
+		if _, ok := unprotected.ExtraHeaders[h]; ok {
+			return fmt.Errorf("signature has '%s' in its unprotected header", h)
+		}
+	}
+
+	return checkCritical(sig.Protected)
+}
+
+func checkCritical(protected jose.Header) error {
+	v, ok := protected.ExtraHeaders["crit"]
+	if !ok {
+		return nil
+	}
+
+	crit, ok := v.([]interface{})
+	if !ok || len(crit) == 0 {
+		return errors.New("invalid 'crit' header: must be a non-empty list")
+	}
+
+	for _, c := range crit {
+		name, ok := c.(string)
+		if !ok || !supportedCritical[name] {
+			return fmt.Errorf("unsupported critical header parameter '%v'", c)
+		}
+
+		if _, ok := protected.ExtraHeaders[jose.HeaderKey(name)]; !ok {
+			return fmt.Errorf("critical header parameter '%s' is not in the protected header", name)
+		}
+	}
+
+	return nil
+}
+
+// CheckSignatureAlgorithm returns an error if the algorithm of a JWS signature isn't in the given allowlist,
+// or in DefaultSignatureAlgorithms if the allowlist is empty
+func CheckSignatureAlgorithm(sig *jose.Signature, allowed []jose.SignatureAlgorithm) error {
+	if len(allowed) == 0 {
+		allowed = DefaultSignatureAlgorithms
+	}
+
+	alg := jose.SignatureAlgorithm(sig.Header.Algorithm)
+
+	for _, a := range allowed {
+		if a == alg {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature algorithm '%s' is not allowed", alg)
+}
+
+// parseJWS parses a JWS received from the network. Since the data is untrusted, a panic of the JOSE library on
+// malformed input is turned into an error.
+func parseJWS(data []byte) (jws *jose.JSONWebSignature, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jws, err = nil, fmt.Errorf("malformed JWS: %v", r)
+		}
+	}()
+
+	return jose.ParseSigned(string(data))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSignatureAlgorithm(t *testing.T) {
+	sig := func(alg jose.SignatureAlgorithm) *jose.Signature {
+		return &jose.Signature{Header: jose.Header{Algorithm: string(alg)}}
+	}
+
+	t.Run("default algorithms", func(t *testing.T) {
+		require.NoError(t, CheckSignatureAlgorithm(sig(jose.EdDSA), nil))
+		require.NoError(t, CheckSignatureAlgorithm(sig(jose.ES256), nil))
+
+		err := CheckSignatureAlgorithm(sig(jose.RS256), nil)
+		require.EqualError(t, err, "signature algorithm 'RS256' is not allowed")
+
+		err = CheckSignatureAlgorithm(sig("none"), nil)
+		require.EqualError(t, err, "signature algorithm 'none' is not allowed")
+	})
+
+	t.Run("custom allowlist", func(t *testing.T) {
+		allowed := []jose.SignatureAlgorithm{jose.PS256}
+
+		require.NoError(t, CheckSignatureAlgorithm(sig(jose.PS256), allowed))
+		require.Error(t, CheckSignatureAlgorithm(sig(jose.EdDSA), allowed))
+	})
+}
+
+func TestCheckSignatureHeaders(t *testing.T) {
+	parse := func(protected, unprotected string) *jose.Signature {
+		jws := `{"payload":"e30","protected":"` + base64.RawURLEncoding.EncodeToString([]byte(protected)) + `"`
+		if unprotected != "" {
+			jws += `,"header":` + unprotected
+		}
+
+		jws += `,"signature":"AAAA"}`
+
+		parsed, err := jose.ParseSigned(jws)
+		require.NoError(t, err)
+
+		return &parsed.Signatures[0]
+	}
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, CheckSignatureHeaders(parse(`{"alg":"EdDSA","kid":"key1"}`, "")))
+		require.NoError(t, CheckSignatureHeaders(parse(`{"alg":"EdDSA"}`, `{"custom":"value"}`)))
+		require.NoError(t, CheckSignatureHeaders(parse(`{"alg":"EdDSA","b64":false,"crit":["b64"]}`, "")))
+	})
+
+	t.Run("failure - algorithm in unprotected header", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"kid":"key1"}`, `{"alg":"EdDSA"}`))
+		require.EqualError(t, err, "signature algorithm is not in the protected header")
+	})
+
+	t.Run("failure - key in unprotected header", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"alg":"EdDSA"}`, `{"kid":"key1"}`))
+		require.EqualError(t, err, "signature relies on unprotected header parameters")
+
+		err = CheckSignatureHeaders(parse(`{"alg":"EdDSA"}`, `{"jku":"https://example.com/keys"}`))
+		require.EqualError(t, err, "signature has 'jku' in its unprotected header")
+	})
+
+	t.Run("failure - crit in unprotected header", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"alg":"EdDSA"}`, `{"crit":["b64"]}`))
+		require.EqualError(t, err, "signature has 'crit' in its unprotected header")
+	})
+
+	t.Run("failure - invalid crit", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"alg":"EdDSA","crit":[]}`, ""))
+		require.EqualError(t, err, "invalid 'crit' header: must be a non-empty list")
+
+		err = CheckSignatureHeaders(parse(`{"alg":"EdDSA","crit":"b64"}`, ""))
+		require.EqualError(t, err, "invalid 'crit' header: must be a non-empty list")
+	})
+
+	t.Run("failure - unsupported critical parameter", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"alg":"EdDSA","exp":1,"crit":["exp"]}`, ""))
+		require.EqualError(t, err, "unsupported critical header parameter 'exp'")
+	})
+
+	t.Run("failure - critical parameter is missing", func(t *testing.T) {
+		err := CheckSignatureHeaders(parse(`{"alg":"EdDSA","crit":["b64"]}`, ""))
+		require.EqualError(t, err, "critical header parameter 'b64' is not in the protected header")
+	})
+}
@@ -13,10 +13,12 @@ import (
 	"time"
 
 	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/cbor"
 )
 
 /*
-A stakeholder's config file is a JWS, signed by the stakeholder,
+A stakeholder's config file is a JWS (or a COSE message with a CBOR payload), signed by the stakeholder,
 with the payload being a JSON object containing:
 - The stakeholder's domain
 - The stakeholder's DID (did:trustbloc)
@@ -36,6 +38,9 @@ type Stakeholder struct {
 	Policy StakeholderSettings `json:"policy"`
 	// Endpoints is a list of sidetree endpoints owned by this stakeholder organization
 	Endpoints []string `json:"endpoints"`
+	// JWKSURL is the URL of a JSON Web Key Set served on the stakeholder's domain, holding signing keys trusted in
+	//   addition to the keys of the stakeholder's DID doc, so keys can be rotated the usual JWKS way
+	JWKSURL string `json:"jwks_url,omitempty"`
 	// Previous is a hashlink to the previous version of this file
 	Previous string `json:"previous,omitempty"`
 }
@@ -49,6 +54,21 @@ type StakeholderSettings struct {
 type StakeholderFileData struct {
 	Config *Stakeholder
 	JWS    *jose.JSONWebSignature
+	// COSE is the signed message of a CBOR encoded config file, in which case JWS is nil
+	COSE *COSEMessage
+}
+
+// Payload returns the signed payload of the stakeholder file
+func (s StakeholderFileData) Payload() []byte {
+	if s.COSE != nil {
+		return s.COSE.Payload
+	}
+
+	if s.JWS != nil {
+		return s.JWS.UnsafePayloadWithoutVerification()
+	}
+
+	return nil
 }
 
 // CacheLifetime returns the cache lifetime of the stakeholder file before it needs to be checked for an update
@@ -62,7 +82,7 @@ func (s StakeholderFileData) CacheLifetime() (time.Duration, error) {
 
 // ParseStakeholder parses a stakeholder config within a JWS
 func ParseStakeholder(data []byte) (*StakeholderFileData, error) {
-	jws, err := jose.ParseSigned(string(data))
+	jws, err := parseJWS(data)
 	if err != nil {
 		return nil, errors.New("stakeholder config data should be a JWS")
 	}
@@ -81,3 +101,28 @@ func ParseStakeholder(data []byte) (*StakeholderFileData, error) {
 		JWS:    jws,
 	}, nil
 }
+
+// ParseStakeholderCOSE parses a stakeholder file given as a COSE message with a CBOR encoded payload
+func ParseStakeholderCOSE(data []byte) (*StakeholderFileData, error) {
+	msg, err := ParseCOSE(data)
+	if err != nil {
+		return nil, fmt.Errorf("stakeholder config data should be a COSE message: %w", err)
+	}
+
+	configBytes, err := cbor.ToJSON(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stakeholder config payload: %w", err)
+	}
+
+	var config Stakeholder
+
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StakeholderFileData{
+		Config: &config,
+		COSE:   msg,
+	}, nil
+}
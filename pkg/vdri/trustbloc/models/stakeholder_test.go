@@ -69,6 +69,34 @@ func Test_ParseStakeholder(t *testing.T) {
 	})
 }
 
+func Test_ParseStakeholderCOSE(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		msg, err := mockmodels.DummyCOSEWrap(exampleStakeholders[0])
+		require.NoError(t, err)
+
+		cData, err := ParseStakeholderCOSE(msg)
+		require.NoError(t, err)
+
+		require.Equal(t, "bar.baz", cData.Config.Domain)
+		require.Equal(t, cData.COSE.Payload, cData.Payload())
+	})
+
+	t.Run("failure: not a COSE message", func(t *testing.T) {
+		_, err := ParseStakeholderCOSE([]byte(`{aaaaaaa`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder config data should be a COSE message")
+	})
+
+	t.Run("failure: malformed stakeholder within COSE message", func(t *testing.T) {
+		msg, err := mockmodels.DummyCOSEWrap(`{"endpoints":"data"}`)
+		require.NoError(t, err)
+
+		_, err = ParseStakeholderCOSE(msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot unmarshal string")
+	})
+}
+
 func TestStakeholderFileData_CacheLifetime(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		cfd := StakeholderFileData{
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models
+
+import "fmt"
+
+// stakeholder checks reported by verification warnings
+const (
+	// CheckEndorsement is the verification of a stakeholder's signature of the consortium config
+	CheckEndorsement = "endorsement"
+	// CheckMirror is the comparison of a stakeholder's copy of the consortium config with the original
+	CheckMirror = "mirror"
+	// CheckStakeholderConfig is the fetching of a stakeholder config
+	CheckStakeholderConfig = "stakeholder-config"
+)
+
+// VerificationWarning reports a stakeholder that failed a check while the consortium config was verified anyway,
+// as enough other stakeholders passed it. Warnings let operators alert on a degrading consortium before it falls
+// below the verification threshold.
+type VerificationWarning struct {
+	// Consortium is the domain of the consortium
+	Consortium string
+	// Stakeholder is the domain of the stakeholder that failed the check
+	Stakeholder string
+	// Check is the failed check, eg. CheckEndorsement
+	Check string
+	// Err is the reason of the failure
+	Err error
+	// Passed is the number of stakeholders that passed the check, out of the Required ones
+	Passed   int
+	Required int
+}
+
+// String formats the warning for logs
+func (w *VerificationWarning) String() string {
+	return fmt.Sprintf("consortium %s: stakeholder %s failed the %s check (%d passed, %d required): %v",
+		w.Consortium, w.Stakeholder, w.Check, w.Passed, w.Required, w.Err)
+}
+
+// WarningHandler handles verification warnings
+type WarningHandler func(warning *VerificationWarning)
+
+// Warn calls the handler with the given warnings, if the handler is set
+func (h WarningHandler) Warn(warnings []*VerificationWarning, passed, required int) {
+	if h == nil {
+		return
+	}
+
+	for _, w := range warnings {
+		w.Passed, w.Required = passed, required
+		h(w)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestWarningHandler(t *testing.T) {
+	warnings := []*VerificationWarning{{
+		Consortium: "consortium.net", Stakeholder: "stakeholder.one", Check: CheckEndorsement,
+		Err: errors.New("key fails to verify"),
+	}}
+
+	var handled []*VerificationWarning
+
+	WarningHandler(func(warning *VerificationWarning) {
+		handled = append(handled, warning)
+	}).Warn(warnings, 2, 3)
+
+	require.Len(t, handled, 1)
+	require.Equal(t, 2, handled[0].Passed)
+	require.Equal(t, 3, handled[0].Required)
+	require.Equal(t, "consortium consortium.net: stakeholder stakeholder.one failed the endorsement check "+
+		"(2 passed, 3 required): key fails to verify", handled[0].String())
+
+	// a nil handler ignores warnings
+	WarningHandler(nil).Warn(warnings, 0, 0)
+}
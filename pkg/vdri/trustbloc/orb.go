@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	orbDIDPrefix          = "did:orb:"
+	defaultResolutionPath = "/identifiers"
+)
+
+// orbCompatibility translates between the did:trustbloc conventions and the conventions of orb endpoints
+type orbCompatibility struct {
+	anchorOrigin   string
+	resolutionPath string
+}
+
+// resolutionURL returns the URL DIDs are resolved at, given the URL of an endpoint
+func (o *orbCompatibility) resolutionURL(endpointURL string) string {
+	return endpointURL + o.resolutionPath
+}
+
+// orbDID returns the did:orb DID of a did:trustbloc DID, with the anchor origin of its consortium
+func (o *orbCompatibility) orbDID(didID string) (*models.DID, string, error) {
+	d, err := models.ParseDID(didID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	anchorOrigin := o.anchorOrigin
+	if anchorOrigin == "" {
+		anchorOrigin = "https:" + d.Domain
+	}
+
+	return d, orbDIDPrefix + anchorOrigin + ":" + d.Suffix, nil
+}
+
+// resolve resolves a did:trustbloc DID as a did:orb DID, and translates the IDs of the resolved doc back to the
+// did:trustbloc DID
+func (o *orbCompatibility) resolve(ctx context.Context, v *VDRI, url, didID string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	d, orbDID, err := o.orbDID(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := v.sidetreeResolve(ctx, url, orbDID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromOrbDoc(doc, d)
+}
+
+// fromOrbDoc replaces the did:orb DID in the IDs and references of a doc with the did:trustbloc DID. The orb DID
+// may have been resolved with a different anchor origin than it was requested with, eg. its canonical one.
+func fromOrbDoc(doc *docdid.Doc, d *models.DID) (*docdid.Doc, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal orb doc: %w", err)
+	}
+
+	orbID := regexp.MustCompile(regexp.QuoteMeta(orbDIDPrefix) + `[^"#?/\s]*:` + regexp.QuoteMeta(d.Suffix) + `\b`)
+
+	translated, err := docdid.ParseDocument(orbID.ReplaceAll(docBytes, []byte(d.String())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse translated orb doc: %w", err)
+	}
+
+	return translated, nil
+}
+
+// WithOrbCompatibility option makes the VDRI resolve DIDs from orb endpoints, eg. while the stakeholders of a
+// consortium migrate their infrastructure: did:trustbloc DIDs are requested as did:orb DIDs with the given anchor
+// origin, at the given path of the endpoints, and the IDs of the resolved docs are translated back to the
+// did:trustbloc DIDs. The anchor origin defaults to "https:" followed by the consortium domain, and the resolution
+// path to "/identifiers". Combine with WithDomainOptions to enable it only for the consortiums being migrated.
+func WithOrbCompatibility(anchorOrigin, resolutionPath string) Option {
+	return func(opts *VDRI) {
+		if resolutionPath == "" {
+			resolutionPath = defaultResolutionPath
+		}
+
+		opts.orb = &orbCompatibility{anchorOrigin: anchorOrigin, resolutionPath: resolutionPath}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const orbDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "%[1]s",
+  "publicKey": [{
+    "id": "%[1]s#key1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "%[1]s",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }],
+  "authentication": ["%[1]s#key1"],
+  "service": [{
+    "id": "%[1]s#agent",
+    "type": "did-communication",
+    "serviceEndpoint": "https://agent.example.com"
+  }]
+}`
+
+// orbServer serves the docs of did:orb DIDs, with the given canonical anchor origin, at the given path. The DIDs
+// requested are sent to the returned channel.
+func orbServer(t *testing.T, resolutionPath, canonicalOrigin string) (*httptest.Server, chan string) {
+	requested := make(chan string, 1)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orbDID := strings.TrimPrefix(r.URL.Path, resolutionPath+"/")
+		if orbDID == r.URL.Path || !strings.HasPrefix(orbDID, "did:orb:") {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		requested <- orbDID
+
+		if canonicalOrigin != "" {
+			orbDID = "did:orb:" + canonicalOrigin + orbDID[strings.LastIndex(orbDID, ":"):]
+		}
+
+		w.Header().Set("Content-Type", didLDJSON)
+
+		_, err := fmt.Fprintf(w, `{"didDocument": `+orbDoc+`}`, orbDID)
+		require.NoError(t, err)
+	})), requested
+}
+
+func TestVDRI_OrbCompatibility(t *testing.T) {
+	const didID = "did:trustbloc:testnet.example.com:EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg"
+
+	t.Run("endpoints with the orb path layout", func(t *testing.T) {
+		serv, requested := orbServer(t, "/sidetree/v1/identifiers", "")
+		defer serv.Close()
+
+		v := New(WithAllowInsecureHTTP("127.0.0.1"),
+			WithOrbCompatibility("", "/sidetree/v1/identifiers"),
+			WithEndpointService(mock.NewEndpointService().
+				WithEndpoints("testnet.example.com", &models.Endpoint{URL: serv.URL})))
+		v.setValidatedConsortium("testnet.example.com")
+
+		doc, err := v.Read(didID)
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+		require.Equal(t, didID+"#key1", doc.PublicKey[0].ID)
+		require.Equal(t, didID, doc.PublicKey[0].Controller)
+		require.Equal(t, didID+"#agent", doc.Service[0].ID)
+		require.Equal(t, didID+"#key1", doc.Authentication[0].PublicKey.ID)
+		require.Equal(t, "did:orb:https:testnet.example.com:EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg",
+			<-requested)
+	})
+
+	t.Run("resolver with the anchor origin", func(t *testing.T) {
+		// the doc is resolved with its canonical anchor origin
+		serv, requested := orbServer(t, "/identifiers", "uAAA")
+		defer serv.Close()
+
+		v := New(WithAllowInsecureHTTP("127.0.0.1"), WithResolverURL(serv.URL+"/identifiers"),
+			WithOrbCompatibility("https:orb.example.com", ""))
+
+		doc, err := v.Read(didID)
+		require.NoError(t, err)
+		require.Equal(t, didID, doc.ID)
+		require.Equal(t, didID+"#agent", doc.Service[0].ID)
+		require.Equal(t, "did:orb:https:orb.example.com:EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg", <-requested)
+	})
+
+	t.Run("default anchor origin", func(t *testing.T) {
+		o := &orbCompatibility{}
+
+		_, orbDID, err := o.orbDID(didID)
+		require.NoError(t, err)
+		require.Equal(t, "did:orb:https:testnet.example.com:EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg", orbDID)
+	})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		v := New(WithResolverURL("https://resolver.example.com/identifiers"), WithOrbCompatibility("", ""))
+
+		_, err := v.Read("did:trustbloc:testnet.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid did:trustbloc DID")
+	})
+
+	t.Run("trustbloc endpoints aren't translated", func(t *testing.T) {
+		serv, _ := orbServer(t, "/sidetree/v1/identifiers", "")
+		defer serv.Close()
+
+		v := New(WithAllowInsecureHTTP("127.0.0.1"),
+			WithEndpointService(mock.NewEndpointService().
+				WithEndpoints("testnet.example.com", &models.Endpoint{URL: serv.URL})))
+		v.setValidatedConsortium("testnet.example.com")
+
+		_, err := v.Read(didID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DID does not exist")
+	})
+}
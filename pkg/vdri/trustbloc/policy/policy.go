@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package policy evaluates consortium policies. The config cache, the endpoint selection and the verification
+// of consortium configs all consult a Policy, so the policy settings and their defaults are handled in one place.
+package policy
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	// hash functions available for history hashes
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// DefaultHistoryHash is the history hash algorithm of consortiums that don't set one
+const DefaultHistoryHash = "SHA256"
+
+// history hash algorithms, by policy name
+var historyHashes = map[string]crypto.Hash{ // nolint: gochecknoglobals
+	"SHA256": crypto.SHA256,
+	"SHA384": crypto.SHA384,
+	"SHA512": crypto.SHA512,
+}
+
+// Policy is the policy of a consortium config
+type Policy struct {
+	consortium *models.Consortium
+}
+
+// New returns the policy of a consortium config. A nil config has the default policy.
+func New(consortium *models.Consortium) *Policy {
+	if consortium == nil {
+		consortium = &models.Consortium{}
+	}
+
+	return &Policy{consortium: consortium}
+}
+
+// CacheLifetime returns how long the consortium config may be cached before checking for an update
+func (p *Policy) CacheLifetime() time.Duration {
+	return time.Duration(p.consortium.Policy.Cache.MaxAge) * time.Second
+}
+
+// NumQueries returns the number of stakeholders to query out of the given number of candidates, eg. to endorse
+// the consortium config or to resolve a DID: the num_queries setting, or all candidates if it isn't set or is
+// greater than the number of candidates
+func (p *Policy) NumQueries(candidates int) int {
+	n := p.consortium.Policy.NumQueries
+	if n <= 0 || n > candidates {
+		return candidates
+	}
+
+	return n
+}
+
+// NumStakeholderQueries returns the number of stakeholders of the consortium to query
+func (p *Policy) NumStakeholderQueries() int {
+	return p.NumQueries(len(p.consortium.Members))
+}
+
+// HistoryHash returns the hash algorithm identifying previous versions of the consortium config
+func (p *Policy) HistoryHash() (crypto.Hash, error) {
+	name := p.consortium.Policy.HistoryHash
+	if name == "" {
+		name = DefaultHistoryHash
+	}
+
+	h, ok := historyHashes[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported history hash: %s", name)
+	}
+
+	return h, nil
+}
+
+// HistoryID returns the hex encoded history hash of the payload of a consortium config file, which identifies the
+// file in the history of the consortium and is referenced by the `previous` field of the next version
+func (p *Policy) HistoryID(file *models.ConsortiumFileData) (string, error) {
+	h, err := p.HistoryHash()
+	if err != nil {
+		return "", err
+	}
+
+	hash := h.New()
+	hash.Write(file.Payload()) // nolint: errcheck, gosec
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyHistory returns true if each new version of the consortium config must reference the version it replaces
+func (p *Policy) VerifyHistory() bool {
+	return p.consortium.Policy.VerifyHistory
+}
+
+// CheckUpdate checks that a consortium config may replace the trusted one, according to the policy of the trusted
+// config: its version must not be older, and if history verification is required, a newer config must be the
+// direct successor of the trusted one, referencing it by its history id
+func CheckUpdate(trusted, next *models.ConsortiumFileData) error {
+	if trusted == nil || trusted.Config == nil || next == nil || next.Config == nil {
+		return nil
+	}
+
+	version, trustedVersion := next.Config.Version, trusted.Config.Version
+
+	if version < trustedVersion {
+		return fmt.Errorf("consortium config version %d is older than the trusted version %d", version, trustedVersion)
+	}
+
+	p := New(trusted.Config)
+
+	if version == trustedVersion || !p.VerifyHistory() {
+		return nil
+	}
+
+	if version != trustedVersion+1 {
+		return fmt.Errorf("consortium config version %d doesn't directly succeed the trusted version %d",
+			version, trustedVersion)
+	}
+
+	previous, err := p.HistoryID(trusted)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(next.Config.Previous, previous) {
+		return fmt.Errorf("consortium config version %d doesn't reference the trusted version %d as previous",
+			version, trustedVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policy
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func consortiumFile(t *testing.T, consortium *models.Consortium) *models.ConsortiumFileData {
+	data, err := mockmodels.WrapConsortium(consortium)
+	require.NoError(t, err)
+
+	file, err := models.ParseConsortium([]byte(data))
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestPolicy(t *testing.T) {
+	members := []*models.StakeholderListElement{{Domain: "s1"}, {Domain: "s2"}, {Domain: "s3"}}
+
+	t.Run("defaults", func(t *testing.T) {
+		p := New(nil)
+		require.Equal(t, time.Duration(0), p.CacheLifetime())
+		require.Equal(t, 0, p.NumStakeholderQueries())
+		require.Equal(t, 5, p.NumQueries(5))
+		require.False(t, p.VerifyHistory())
+
+		h, err := p.HistoryHash()
+		require.NoError(t, err)
+		require.Equal(t, crypto.SHA256, h)
+
+		require.Equal(t, 3, New(&models.Consortium{Members: members}).NumStakeholderQueries())
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		var consortium models.Consortium
+
+		require.NoError(t, json.Unmarshal([]byte(`{"members":[{},{},{}],"policy":{"cache":{"max_age":60},
+			"num_queries":2,"history_hash":"sha512","verify_history":true,
+			"sidetree":{"hash_algorithm":"SHA256","max_operation_size":8192}}}`), &consortium))
+
+		p := New(&consortium)
+		require.Equal(t, time.Minute, p.CacheLifetime())
+		require.Equal(t, 2, p.NumStakeholderQueries())
+		require.Equal(t, 1, p.NumQueries(1))
+		require.True(t, p.VerifyHistory())
+		require.Equal(t, uint64(8192), consortium.Policy.Sidetree.MaxOperationSize)
+
+		h, err := p.HistoryHash()
+		require.NoError(t, err)
+		require.Equal(t, crypto.SHA512, h)
+	})
+
+	t.Run("legacy num-queries setting", func(t *testing.T) {
+		var consortium models.Consortium
+
+		require.NoError(t, json.Unmarshal([]byte(`{"members":[{},{},{}],"policy":{"num-queries":1}}`), &consortium))
+		require.Equal(t, 1, New(&consortium).NumStakeholderQueries())
+	})
+
+	t.Run("history id", func(t *testing.T) {
+		file := consortiumFile(t, &models.Consortium{Domain: "consortium.net"})
+
+		id, err := New(file.Config).HistoryID(file)
+		require.NoError(t, err)
+
+		hash := sha256.Sum256(file.Payload())
+		require.Equal(t, hex.EncodeToString(hash[:]), id)
+
+		_, err = New(&models.Consortium{Policy: models.ConsortiumPolicy{HistoryHash: "MD5"}}).HistoryID(file)
+		require.EqualError(t, err, "unsupported history hash: MD5")
+	})
+}
+
+func TestCheckUpdate(t *testing.T) {
+	trusted := consortiumFile(t, &models.Consortium{Domain: "consortium.net", Version: 2})
+
+	t.Run("no trusted config", func(t *testing.T) {
+		require.NoError(t, CheckUpdate(nil, trusted))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		err := CheckUpdate(trusted, consortiumFile(t, &models.Consortium{Version: 1}))
+		require.EqualError(t, err, "consortium config version 1 is older than the trusted version 2")
+	})
+
+	t.Run("history not required", func(t *testing.T) {
+		require.NoError(t, CheckUpdate(trusted, consortiumFile(t, &models.Consortium{Version: 5})))
+	})
+
+	t.Run("history required", func(t *testing.T) {
+		verifying := consortiumFile(t, &models.Consortium{Domain: "consortium.net", Version: 2,
+			Policy: models.ConsortiumPolicy{VerifyHistory: true}})
+
+		previous, err := New(verifying.Config).HistoryID(verifying)
+		require.NoError(t, err)
+
+		require.NoError(t, CheckUpdate(verifying, verifying))
+		require.NoError(t, CheckUpdate(verifying, consortiumFile(t, &models.Consortium{Version: 3,
+			Previous: previous})))
+
+		err = CheckUpdate(verifying, consortiumFile(t, &models.Consortium{Version: 4, Previous: previous}))
+		require.EqualError(t, err, "consortium config version 4 doesn't directly succeed the trusted version 2")
+
+		err = CheckUpdate(verifying, consortiumFile(t, &models.Consortium{Version: 3, Previous: "abc"}))
+		require.EqualError(t, err, "consortium config version 3 doesn't reference the trusted version 2 as previous")
+
+		unsupported := consortiumFile(t, &models.Consortium{Version: 2,
+			Policy: models.ConsortiumPolicy{VerifyHistory: true, HistoryHash: "MD5"}})
+
+		err = CheckUpdate(unsupported, consortiumFile(t, &models.Consortium{Version: 3}))
+		require.EqualError(t, err, "unsupported history hash: MD5")
+	})
+}
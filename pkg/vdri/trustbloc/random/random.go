@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package random provides the sources of randomness the VDRI samples stakeholders, mirrors and endpoints with,
+// so they can be replaced with deterministic sources in tests and simulations.
+//
+// These sources are only used to spread the load and the trust over stakeholders, never for cryptography: keys
+// and nonces are generated with crypto/rand.
+package random
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Source is a source of pseudo-random numbers, safe for concurrent use
+type Source interface {
+	// Intn returns a number in [0,n)
+	Intn(n int) int
+	// Perm returns a permutation of the numbers in [0,n)
+	Perm(n int) []int
+}
+
+type global struct{}
+
+func (global) Intn(n int) int {
+	return rand.Intn(n) // nolint: gosec
+}
+
+func (global) Perm(n int) []int {
+	return rand.Perm(n) // nolint: gosec
+}
+
+// Default returns the source of the math/rand package, used unless another source is set
+func Default() Source {
+	return global{}
+}
+
+type seeded struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewSeeded returns a deterministic source, returning the same sequence of numbers for the same seed
+func NewSeeded(seed int64) Source {
+	return &seeded{rand: rand.New(rand.NewSource(seed))} // nolint: gosec
+}
+
+func (s *seeded) Intn(n int) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rand.Intn(n)
+}
+
+func (s *seeded) Perm(n int) []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rand.Perm(n)
+}
+
+// OrDefault returns the given source, or the default source if it's nil
+func OrDefault(source Source) Source {
+	if source == nil {
+		return Default()
+	}
+
+	return source
+}
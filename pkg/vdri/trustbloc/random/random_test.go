@@ -0,0 +1,29 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSeeded(t *testing.T) {
+	s1, s2 := NewSeeded(42), NewSeeded(42)
+
+	require.Equal(t, s1.Perm(10), s2.Perm(10))
+	require.Equal(t, s1.Intn(100), s2.Intn(100))
+}
+
+func TestOrDefault(t *testing.T) {
+	require.Equal(t, Default(), OrDefault(nil))
+
+	s := NewSeeded(1)
+	require.Equal(t, s, OrDefault(s))
+
+	require.Len(t, Default().Perm(3), 3)
+	require.Less(t, Default().Intn(3), 3)
+}
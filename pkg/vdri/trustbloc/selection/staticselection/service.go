@@ -6,31 +6,57 @@ SPDX-License-Identifier: Apache-2.0
 package staticselection
 
 import (
+	"context"
 	"fmt"
-	"math/rand"
+	"sort"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
 )
 
-type config interface {
-	GetConsortium(url, domain string) (*models.ConsortiumFileData, error)
-	GetStakeholder(url, domain string) (*models.StakeholderFileData, error)
-}
-
 // SelectionService implements a static selection service
 type SelectionService struct {
-	config config
+	config config.Service
+	rand   random.Source
 }
 
 // NewService return static selection service
-func NewService(config config) *SelectionService {
-	return &SelectionService{config: config}
+func NewService(c config.Service, opts ...Option) *SelectionService {
+	s := &SelectionService{config: c}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.rand = random.OrDefault(s.rand)
+
+	return s
+}
+
+// Option is a selection service instance option
+type Option func(opts *SelectionService)
+
+// WithRandomSource option sets the source of randomness the stakeholders and their endpoints are selected with,
+// eg. a seeded source for reproducible tests. Defaults to random.Default().
+func WithRandomSource(source random.Source) Option {
+	return func(opts *SelectionService) {
+		opts.rand = source
+	}
 }
 
 // SelectEndpoints select a random endpoint for each of N random stakeholders in a consortium
-// Where N is the num-queries parameter in the consortium's policy configuration
+// Where N is the num_queries parameter in the consortium's policy configuration
 func (ds *SelectionService) SelectEndpoints(consortiumDomain string, endpoints []*models.Endpoint) ([]*models.Endpoint, error) { // nolint: lll
-	consortiumData, err := ds.config.GetConsortium(consortiumDomain, consortiumDomain)
+	return ds.SelectEndpointsWithContext(context.Background(), consortiumDomain, endpoints)
+}
+
+// SelectEndpointsWithContext selects endpoints like SelectEndpoints, aborting the consortium config fetch when the
+// context is done
+func (ds *SelectionService) SelectEndpointsWithContext(ctx context.Context, consortiumDomain string,
+	endpoints []*models.Endpoint) ([]*models.Endpoint, error) {
+	consortiumData, err := config.GetConsortium(ctx, ds.config, consortiumDomain, consortiumDomain)
 	if err != nil {
 		return nil, fmt.Errorf("getting consortium: %w", err)
 	}
@@ -51,23 +77,16 @@ func (ds *SelectionService) SelectEndpoints(consortiumDomain string, endpoints [
 		d = append(d, domain)
 	}
 
-	consortium := consortiumData.Config
+	// sorted, so that the selection only depends on the random source
+	sort.Strings(d)
 
-	n := 0
-	if consortium != nil {
-		n = consortium.Policy.NumQueries
-	}
-
-	// if ds.numStakeholders is 0, then we use all stakeholders
-	if n == 0 {
-		n = len(d)
-	}
+	n := policy.New(consortiumData.Config).NumQueries(len(d))
 
-	perm := rand.Perm(len(d))
+	perm := ds.rand.Perm(len(d))
 
 	for i := 0; i < n && i < len(d); i++ {
 		list := domains[d[perm[i]]]
-		out = append(out, list[rand.Intn(len(list))])
+		out = append(out, list[ds.rand.Intn(len(list))])
 	}
 
 	return out, nil
@@ -6,12 +6,16 @@ SPDX-License-Identifier: Apache-2.0
 package staticselection
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
 )
 
 func intersectionSize(list, candidates []*models.Endpoint) int {
@@ -98,4 +102,45 @@ func TestSelectionService_SelectEndpoints(t *testing.T) {
 		require.Len(t, selectedEndpoints, 2)
 		require.Equal(t, 2, intersectionSize(selectedEndpoints, endpoints))
 	})
+	t.Run("test success - deterministic with a seeded source", func(t *testing.T) {
+		configService := &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(s string, s2 string) (*models.ConsortiumFileData, error) {
+				return &models.ConsortiumFileData{
+					Config: &models.Consortium{
+						Policy: models.ConsortiumPolicy{NumQueries: 2},
+					},
+				}, nil
+			}}
+
+		var endpoints []*models.Endpoint
+
+		for i := 0; i < 10; i++ {
+			endpoints = append(endpoints,
+				&models.Endpoint{URL: fmt.Sprintf("url.%d.1", i), Domain: fmt.Sprint(i)},
+				&models.Endpoint{URL: fmt.Sprintf("url.%d.2", i), Domain: fmt.Sprint(i)})
+		}
+
+		expected, err := NewService(configService, WithRandomSource(random.NewSeeded(1))).
+			SelectEndpoints("domain", endpoints)
+		require.NoError(t, err)
+		require.Len(t, expected, 2)
+
+		for i := 0; i < 5; i++ {
+			selected, err := NewService(configService, WithRandomSource(random.NewSeeded(1))).
+				SelectEndpoints("domain", endpoints)
+			require.NoError(t, err)
+			require.Equal(t, expected, selected)
+		}
+	})
+}
+
+func TestSelectionService_SelectEndpointsWithContext(t *testing.T) {
+	s := NewService(&mockconfig.MockConfigService{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.SelectEndpointsWithContext(ctx, "domain", []*models.Endpoint{{URL: "url.1", Domain: "1"}})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
 }
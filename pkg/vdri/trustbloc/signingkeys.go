@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+// defaultSigningRelationships are the verification relationships of the keys stakeholders may sign configs with
+// nolint: gochecknoglobals
+var defaultSigningRelationships = []docdid.VerificationRelationship{docdid.Authentication, docdid.AssertionMethod}
+
+// signingKeysDoc returns a doc listing only the keys of a stakeholder DID doc that may sign config files: the keys
+// referenced or embedded by the signing relationships, or all the keys of the doc if no relationship is required
+func (v *VDRI) signingKeysDoc(doc *docdid.Doc) *docdid.Doc {
+	if len(v.signingRelationships) == 0 {
+		return doc
+	}
+
+	out := &docdid.Doc{Context: doc.Context, ID: doc.ID}
+	added := map[string]bool{}
+
+	for _, relationship := range v.signingRelationships {
+		for _, vm := range relationshipMethods(doc, relationship) {
+			if added[vm.PublicKey.ID] {
+				continue
+			}
+
+			added[vm.PublicKey.ID] = true
+
+			out.PublicKey = append(out.PublicKey, vm.PublicKey)
+		}
+	}
+
+	return out
+}
+
+// withStakeholderKeySet returns a copy of the signing keys doc of a stakeholder, with the signing keys of the
+// stakeholder's JSON Web Key Set added. The key set must be served on the stakeholder's domain.
+func (v *VDRI) withStakeholderKeySet(ctx context.Context, s *models.Stakeholder,
+	doc *docdid.Doc) (*docdid.Doc, error) {
+	if err := checkKeySetURL(s.Domain, s.JWKSURL); err != nil {
+		return nil, err
+	}
+
+	keySet, err := v.fetchKeySet(ctx, s.JWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key set of stakeholder %s: %w", s.Domain, err)
+	}
+
+	out := *doc
+	out.PublicKey = append([]docdid.PublicKey{}, doc.PublicKey...)
+
+	for i := range keySet.Keys {
+		key := keySet.Keys[i]
+
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		pk, e := docdid.NewPublicKeyFromJWK(s.JWKSURL+"#"+key.KeyID, did.JSONWebKey2020, s.DID,
+			&jose.JWK{JSONWebKey: key.Public()})
+		if e != nil {
+			v.logger.Warnf("ignoring key %s of the key set of stakeholder %s: %v", key.KeyID, s.Domain, e)
+
+			continue
+		}
+
+		out.PublicKey = append(out.PublicKey, *pk)
+	}
+
+	return &out, nil
+}
+
+// checkKeySetURL checks that a key set is served on the domain of a stakeholder, or one of its subdomains
+func checkKeySetURL(domain, keySetURL string) error {
+	u, err := url.Parse(keySetURL)
+	if err != nil {
+		return fmt.Errorf("invalid key set url %s: %w", keySetURL, err)
+	}
+
+	if !strings.HasPrefix(domain, "http") {
+		domain = "https://" + domain
+	}
+
+	d, err := url.Parse(domain)
+	if err != nil {
+		return fmt.Errorf("invalid stakeholder domain %s: %w", domain, err)
+	}
+
+	if u.Hostname() == "" || (u.Hostname() != d.Hostname() && !strings.HasSuffix(u.Hostname(), "."+d.Hostname())) {
+		return fmt.Errorf("key set url %s isn't on the domain of stakeholder %s", keySetURL, d.Hostname())
+	}
+
+	return nil
+}
+
+func relationshipMethods(doc *docdid.Doc, relationship docdid.VerificationRelationship) []docdid.VerificationMethod {
+	switch relationship { // nolint: exhaustive
+	case docdid.Authentication:
+		return doc.Authentication
+	case docdid.AssertionMethod:
+		return doc.AssertionMethod
+	case docdid.CapabilityDelegation:
+		return doc.CapabilityDelegation
+	case docdid.CapabilityInvocation:
+		return doc.CapabilityInvocation
+	case docdid.KeyAgreement:
+		return doc.KeyAgreement
+	}
+
+	return nil
+}
+
+// WithSigningKeyRelationships option sets the verification relationships (eg. docdid.AssertionMethod) under which
+// the key signing the config file of a stakeholder, and its signature of the consortium config, must be listed in
+// the stakeholder's DID doc. Keys listed in the doc without any of these relationships are refused. Defaults to
+// authentication and assertionMethod; without relationships, any key of the doc is accepted.
+func WithSigningKeyRelationships(relationships ...docdid.VerificationRelationship) Option {
+	return func(opts *VDRI) {
+		opts.signingRelationships = relationships
+	}
+}
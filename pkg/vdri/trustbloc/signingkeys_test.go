@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	didclient "github.com/trustbloc/trustbloc-did-method/pkg/did"
+	mockdidconf "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/didconfiguration"
+)
+
+func TestVDRI_SigningKeyRelationships(t *testing.T) {
+	sigKey := ed25519SigningKey(t, keyJSON)
+
+	doc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	// the signing key of testDoc is embedded in its authentication, the other key isn't in any relationship
+	signingKey := doc.Authentication[0].PublicKey
+	otherKey := doc.PublicKey[0]
+
+	verify := func(doc *did.Doc, opts ...Option) error {
+		cfd := signedConsortiumFileData(t, dummyConsortium("consortium.url", "stakeholder.url"), sigKey)
+		sfd := signedStakeholderFileData(t, dummyStakeholder("stakeholder.url"), sigKey)
+
+		v := New(opts...)
+		v.getHTTPVDRI = httpVdriFunc(doc, nil)
+		v.didConfigService = &mockdidconf.MockDIDConfigService{
+			VerifyStakeholderFunc: func(domain string, doc *did.Doc) error {
+				return nil
+			},
+		}
+
+		return v.verifyStakeholder(context.Background(), cfd, sfd)
+	}
+
+	t.Run("key embedded in authentication", func(t *testing.T) {
+		require.NoError(t, verify(doc))
+	})
+
+	t.Run("key referenced by assertionMethod", func(t *testing.T) {
+		require.NoError(t, verify(&did.Doc{ID: doc.ID, PublicKey: []did.PublicKey{otherKey, signingKey},
+			AssertionMethod: []did.VerificationMethod{*did.NewReferencedVerificationMethod(&signingKey,
+				did.AssertionMethod, false)}}))
+	})
+
+	t.Run("key without a signing relationship", func(t *testing.T) {
+		keyOnly := &did.Doc{ID: doc.ID, PublicKey: []did.PublicKey{otherKey, signingKey},
+			Authentication: []did.VerificationMethod{*did.NewReferencedVerificationMethod(&otherKey,
+				did.Authentication, false)},
+			KeyAgreement: []did.VerificationMethod{*did.NewReferencedVerificationMethod(&signingKey,
+				did.KeyAgreement, false)}}
+
+		err := verify(keyOnly)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder does not sign consortium")
+
+		require.NoError(t, verify(keyOnly, WithSigningKeyRelationships(did.KeyAgreement)))
+
+		// any key of the doc is accepted without relationships
+		require.NoError(t, verify(keyOnly, WithSigningKeyRelationships()))
+	})
+
+	t.Run("relationships are configurable", func(t *testing.T) {
+		err := verify(doc, WithSigningKeyRelationships(did.AssertionMethod, did.CapabilityInvocation))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder does not sign consortium")
+	})
+}
+
+func TestVDRI_StakeholderKeySet(t *testing.T) {
+	rotatedKey := ed25519SigningKey(t, `{
+	"kty":"OKP",
+	"crv":"Ed25519",
+	"kid":"rotated",
+	"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+	"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+}`)
+
+	doc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	keySet := func(t *testing.T, use string) []byte {
+		privateKey := rotatedKey.Key.(jose.JSONWebKey)
+		key := privateKey.Public()
+		key.Use = use
+
+		keySetBytes, e := json.Marshal(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key}})
+		require.NoError(t, e)
+
+		return keySetBytes
+	}
+
+	verify := func(t *testing.T, domain, jwksURL string) error {
+		stakeholder := dummyStakeholder(domain)
+		stakeholder.JWKSURL = jwksURL
+
+		cfd := signedConsortiumFileData(t, dummyConsortium("consortium.url", domain), rotatedKey)
+		sfd := signedStakeholderFileData(t, stakeholder, rotatedKey)
+
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
+		v.getHTTPVDRI = httpVdriFunc(doc, nil)
+		v.didConfigService = &mockdidconf.MockDIDConfigService{
+			VerifyStakeholderFunc: func(domain string, doc *did.Doc) error {
+				return nil
+			},
+		}
+
+		return v.verifyStakeholder(context.Background(), cfd, sfd)
+	}
+
+	keySetServer := func(keySet []byte) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "max-age=60")
+			fmt.Fprint(w, string(keySet))
+		}))
+	}
+
+	t.Run("signed with a key of the key set", func(t *testing.T) {
+		serv := keySetServer(keySet(t, "sig"))
+		defer serv.Close()
+
+		require.NoError(t, verify(t, serv.URL, serv.URL+"/.well-known/jwks.json"))
+	})
+
+	t.Run("keys of the key set are JsonWebKey2020 keys", func(t *testing.T) {
+		serv := keySetServer(keySet(t, "sig"))
+		defer serv.Close()
+
+		stakeholder := dummyStakeholder(serv.URL)
+		stakeholder.JWKSURL = serv.URL + "/.well-known/jwks.json"
+
+		out, err := New(WithAllowInsecureHTTP("127.0.0.1")).withStakeholderKeySet(context.Background(),
+			stakeholder, doc)
+		require.NoError(t, err)
+		require.Len(t, out.PublicKey, len(doc.PublicKey)+1)
+
+		pk := out.PublicKey[len(doc.PublicKey)]
+		require.Equal(t, didclient.JSONWebKey2020, pk.Type)
+		require.Equal(t, stakeholder.JWKSURL+"#rotated", pk.ID)
+		require.Equal(t, "rotated", pk.JSONWebKey().KeyID)
+	})
+
+	t.Run("key set without the signing key", func(t *testing.T) {
+		serv := keySetServer([]byte(`{"keys": []}`))
+		defer serv.Close()
+
+		err := verify(t, serv.URL, serv.URL+"/.well-known/jwks.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder does not sign consortium")
+	})
+
+	t.Run("encryption keys are ignored", func(t *testing.T) {
+		serv := keySetServer(keySet(t, "enc"))
+		defer serv.Close()
+
+		err := verify(t, serv.URL, serv.URL+"/.well-known/jwks.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder does not sign consortium")
+	})
+
+	t.Run("key set not on the stakeholder domain", func(t *testing.T) {
+		err := verify(t, "stakeholder.url", "https://elsewhere.url/jwks.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key set url https://elsewhere.url/jwks.json isn't on the domain")
+	})
+
+	t.Run("key set not found", func(t *testing.T) {
+		serv := httptest.NewServer(http.NotFoundHandler())
+		defer serv.Close()
+
+		err := verify(t, serv.URL, serv.URL+"/.well-known/jwks.json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get key set of stakeholder")
+	})
+}
+
+func Test_checkKeySetURL(t *testing.T) {
+	require.NoError(t, checkKeySetURL("stakeholder.url", "https://stakeholder.url/jwks.json"))
+	require.NoError(t, checkKeySetURL("stakeholder.url", "https://keys.stakeholder.url/jwks.json"))
+	require.NoError(t, checkKeySetURL("https://stakeholder.url:8443", "https://stakeholder.url/jwks.json"))
+	require.Error(t, checkKeySetURL("stakeholder.url", "https://evilstakeholder.url/jwks.json"))
+	require.Error(t, checkKeySetURL("stakeholder.url", "/jwks.json"))
+	require.Error(t, checkKeySetURL("stakeholder.url", "%zz"))
+	require.Error(t, checkKeySetURL("https://%zz", "https://stakeholder.url/jwks.json"))
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+)
+
+// steps of a resolution trace
+const (
+	TraceStepConsortium   = "consortium"
+	TraceStepStakeholder  = "stakeholder"
+	TraceStepEndpoints    = "endpoints"
+	TraceStepEndpoint     = "endpoint"
+	TraceStepInitialState = "initial-state"
+	TraceStepHashlink     = "hashlink"
+)
+
+// ResolutionTrace is a step-by-step report of the resolution of a DID, eg. to troubleshoot consortium deployments
+type ResolutionTrace struct {
+	DID   string       `json:"did"`
+	Steps []*TraceStep `json:"steps"`
+	// Endpoint is the URL of the endpoint whose doc was returned
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// TraceStep is a step of a resolution, with its error if it failed
+type TraceStep struct {
+	Name    string `json:"name"`
+	Details string `json:"details"`
+	Error   string `json:"error,omitempty"`
+}
+
+// add adds a step to the trace, if tracing. A nil trace ignores steps, so that resolution code doesn't need
+// to check whether it's traced.
+func (t *ResolutionTrace) add(name string, err error, format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+
+	step := &TraceStep{Name: name, Details: fmt.Sprintf(format, args...)}
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	t.Steps = append(t.Steps, step)
+}
+
+func (t *ResolutionTrace) setEndpoint(url string) {
+	if t != nil {
+		t.Endpoint = url
+	}
+}
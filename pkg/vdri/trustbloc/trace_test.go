@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/stretchr/testify/require"
+
+	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
+	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func TestVDRI_ReadWithTrace(t *testing.T) {
+	cfd := signedConsortiumFileData(t, &models.Consortium{Domain: "testnet", Version: 3},
+		ed25519SigningKey(t, keyJSON))
+
+	newVDRI := func() *VDRI {
+		v := New(WithResolutionCacheTTL(time.Minute))
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return cfd, nil
+			},
+		}
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return []*models.Endpoint{{URL: "https://stakeholder.one/sidetree", Domain: "stakeholder.one"}}, nil
+			}}
+
+		return v
+	}
+
+	t.Run("success", func(t *testing.T) {
+		v := newVDRI()
+		v.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123"}, nil)
+
+		// validated consortiums and cached docs are ignored when tracing
+		v.setValidatedConsortium("testnet")
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+
+		doc, trace, err := v.ReadWithTrace("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123", doc.ID)
+
+		require.Equal(t, "did:trustbloc:testnet:123", trace.DID)
+		require.Equal(t, "https://stakeholder.one/sidetree", trace.Endpoint)
+		require.Equal(t, []*TraceStep{
+			{Name: TraceStepConsortium, Details: "consortium config of testnet: version 3, 0 members, 0 queries"},
+			{Name: TraceStepConsortium, Details: "0 of 0 stakeholders verified"},
+			{Name: TraceStepEndpoints, Details: "endpoints of consortium testnet: [https://stakeholder.one/sidetree]"},
+			{Name: TraceStepEndpoints, Details: "selected endpoints [https://stakeholder.one/sidetree]"},
+			{Name: TraceStepEndpoint, Details: "resolved from endpoint https://stakeholder.one/sidetree of stakeholder.one"},
+		}, trace.Steps)
+	})
+
+	t.Run("failure - endpoint error", func(t *testing.T) {
+		v := newVDRI()
+		v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("DID does not exist"))
+
+		_, trace, err := v.ReadWithTrace("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Empty(t, trace.Endpoint)
+
+		last := trace.Steps[len(trace.Steps)-1]
+		require.Equal(t, TraceStepEndpoint, last.Name)
+		require.Contains(t, last.Error, "DID does not exist")
+	})
+
+	t.Run("failure - invalid consortium", func(t *testing.T) {
+		v := newVDRI()
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("config error")
+			},
+		}
+
+		_, trace, err := v.ReadWithTrace("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Equal(t, []*TraceStep{
+			{Name: TraceStepConsortium, Details: "consortium config of testnet", Error: "config error"},
+		}, trace.Steps)
+	})
+
+	t.Run("resolver url", func(t *testing.T) {
+		v := New(WithResolverURL("https://resolver.example.com"))
+		v.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123"}, nil)
+
+		_, trace, err := v.ReadWithTrace("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "https://resolver.example.com", trace.Endpoint)
+		require.Len(t, trace.Steps, 1)
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+)
+
+type trustStore interface {
+	Pin(domain string, consortiumData *models.ConsortiumFileData)
+}
+
+// TrustAnchor returns the consortium config pinned as the root of trust of a domain, or nil if none is pinned
+func (v *VDRI) TrustAnchor(domain string) *models.ConsortiumFileData {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.TrustAnchor(domain)
+	}
+
+	v.trustAnchorsMutex.RLock()
+	defer v.trustAnchorsMutex.RUnlock()
+
+	return v.trustAnchors[domain]
+}
+
+// PinTrustAnchor pins a consortium config, eg. the genesis config of the consortium, as the root of trust of its
+// domain, replacing the pinned config if any. Newer consortium configs are only trusted if the policy of the pinned
+// one allows them to succeed it, and the consortium is validated again on the next resolution.
+func (v *VDRI) PinTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.PinTrustAnchor(domain, consortiumData)
+	}
+
+	if err := checkTrustAnchor(domain, consortiumData); err != nil {
+		return err
+	}
+
+	v.trustAnchorsMutex.Lock()
+	defer v.trustAnchorsMutex.Unlock()
+
+	v.pinTrustAnchor(domain, consortiumData)
+
+	return nil
+}
+
+// RotateTrustAnchor replaces the pinned consortium config of a domain with a config its policy allows to replace it,
+// ie. that isn't older and, if history verification is required, is its direct successor. The config is pinned
+// if none is pinned yet.
+func (v *VDRI) RotateTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.RotateTrustAnchor(domain, consortiumData)
+	}
+
+	if err := checkTrustAnchor(domain, consortiumData); err != nil {
+		return err
+	}
+
+	v.trustAnchorsMutex.Lock()
+	defer v.trustAnchorsMutex.Unlock()
+
+	if err := policy.CheckUpdate(v.trustAnchors[domain], consortiumData); err != nil {
+		return fmt.Errorf("invalid trust anchor rotation: %w", err)
+	}
+
+	v.pinTrustAnchor(domain, consortiumData)
+
+	return nil
+}
+
+func (v *VDRI) pinTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) {
+	v.trustAnchors[domain] = consortiumData
+	v.trustStore.Pin(domain, consortiumData)
+
+	v.validatedConsortiumMutex.Lock()
+	defer v.validatedConsortiumMutex.Unlock()
+
+	delete(v.validatedConsortium, domain)
+}
+
+func checkTrustAnchor(domain string, consortiumData *models.ConsortiumFileData) error {
+	if consortiumData == nil || consortiumData.Config == nil {
+		return fmt.Errorf("trust anchor of %s has no consortium config", domain)
+	}
+
+	if consortiumData.Config.Domain != domain {
+		return fmt.Errorf("trust anchor of %s is the consortium config of %s", domain, consortiumData.Config.Domain)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+type mockTrustStore struct {
+	pinned map[string]*models.ConsortiumFileData
+}
+
+func (m *mockTrustStore) Pin(domain string, consortiumData *models.ConsortiumFileData) {
+	m.pinned[domain] = consortiumData
+}
+
+func consortiumVersion(domain string, version uint64, verifyHistory bool) *models.ConsortiumFileData {
+	return &models.ConsortiumFileData{Config: &models.Consortium{
+		Domain:  domain,
+		Version: version,
+		Policy:  models.ConsortiumPolicy{VerifyHistory: verifyHistory},
+	}}
+}
+
+func TestVDRI_TrustAnchor(t *testing.T) {
+	t.Run("success - pin and rotate", func(t *testing.T) {
+		store := &mockTrustStore{pinned: map[string]*models.ConsortiumFileData{}}
+
+		v := New()
+		v.trustStore = store
+		v.setValidatedConsortium("testnet")
+
+		require.Nil(t, v.TrustAnchor("testnet"))
+
+		genesis := consortiumVersion("testnet", 0, false)
+		require.NoError(t, v.PinTrustAnchor("testnet", genesis))
+		require.Equal(t, genesis, v.TrustAnchor("testnet"))
+		require.Equal(t, genesis, store.pinned["testnet"])
+		require.False(t, v.isValidatedConsortium("testnet"))
+
+		next := consortiumVersion("testnet", 2, false)
+		require.NoError(t, v.RotateTrustAnchor("testnet", next))
+		require.Equal(t, next, v.TrustAnchor("testnet"))
+		require.Equal(t, next, store.pinned["testnet"])
+
+		// a rollback is only possible by pinning
+		err := v.RotateTrustAnchor("testnet", genesis)
+		require.EqualError(t, err, "invalid trust anchor rotation: "+
+			"consortium config version 0 is older than the trusted version 2")
+		require.Equal(t, next, v.TrustAnchor("testnet"))
+
+		require.NoError(t, v.PinTrustAnchor("testnet", genesis))
+		require.Equal(t, genesis, v.TrustAnchor("testnet"))
+	})
+
+	t.Run("success - domain options", func(t *testing.T) {
+		v := New(WithDomainOptions("testnet"))
+
+		genesis := consortiumVersion("testnet", 1, false)
+		require.NoError(t, v.PinTrustAnchor("testnet", genesis))
+		require.NoError(t, v.RotateTrustAnchor("testnet", consortiumVersion("testnet", 1, false)))
+		require.Equal(t, uint64(1), v.TrustAnchor("testnet").Config.Version)
+		require.Nil(t, v.trustAnchors["testnet"])
+	})
+
+	t.Run("failure - rotation skipping history", func(t *testing.T) {
+		v := New()
+
+		require.NoError(t, v.RotateTrustAnchor("testnet", consortiumVersion("testnet", 1, true)))
+
+		err := v.RotateTrustAnchor("testnet", consortiumVersion("testnet", 3, true))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't directly succeed the trusted version 1")
+	})
+
+	t.Run("failure - invalid trust anchor", func(t *testing.T) {
+		v := New()
+
+		err := v.PinTrustAnchor("testnet", &models.ConsortiumFileData{})
+		require.EqualError(t, err, "trust anchor of testnet has no consortium config")
+
+		err = v.RotateTrustAnchor("testnet", consortiumVersion("other.net", 1, false))
+		require.EqualError(t, err, "trust anchor of testnet is the consortium config of other.net")
+		require.Nil(t, v.TrustAnchor("testnet"))
+	})
+}
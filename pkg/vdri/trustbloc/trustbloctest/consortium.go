@@ -0,0 +1,289 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package trustbloctest simulates a did:trustbloc consortium, so applications can write integration tests
+// resolving did:trustbloc DIDs without real infrastructure.
+//
+// A Consortium serves, from a single local TLS server, the signed consortium and stakeholder configs, the
+// did-configurations of the stakeholders and the sidetree endpoints resolving the documents added to it.
+// Stakeholders use did:key DIDs, so they're resolved offline.
+package trustbloctest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/fingerprint"
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/didconfiguration"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+const (
+	configPathPrefix    = "/.well-known/did-trustbloc/"
+	configPathSuffix    = ".json"
+	didConfigPath       = "/.well-known/did-configuration.json"
+	sidetreePath        = "/sidetree/0.0.1"
+	identifiersPath     = sidetreePath + "/identifiers/"
+	didResolutionType   = "application/did+ld+json"
+	defaultStakeholders = 2
+)
+
+// Stakeholder is a stakeholder of a simulated consortium
+type Stakeholder struct {
+	Domain     string
+	DID        string
+	KeyID      string
+	PrivateKey ed25519.PrivateKey
+}
+
+// Consortium is a simulated did:trustbloc consortium. It must be closed after use.
+type Consortium struct {
+	Domain       string
+	Stakeholders []*Stakeholder
+
+	server    *httptest.Server
+	tlsConfig *tls.Config
+	configs   map[string][]byte
+	didConfs  map[string][]byte
+	docs      map[string][]byte
+	docsMutex sync.RWMutex
+}
+
+// NewConsortium starts a consortium with the given domain, endorsed by stakeholders with the given domains
+// (two stakeholders if none are given). Its configs are signed by all the stakeholders.
+func NewConsortium(domain string, stakeholderDomains ...string) (*Consortium, error) {
+	if len(stakeholderDomains) == 0 {
+		for i := 1; i <= defaultStakeholders; i++ {
+			stakeholderDomains = append(stakeholderDomains, fmt.Sprintf("stakeholder%d.%s", i, domain))
+		}
+	}
+
+	c := &Consortium{
+		Domain:   domain,
+		configs:  map[string][]byte{},
+		didConfs: map[string][]byte{},
+		docs:     map[string][]byte{},
+	}
+
+	cert, err := newCertificate(append([]string{domain}, stakeholderDomains...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	c.server = httptest.NewUnstartedServer(http.HandlerFunc(c.serveHTTP))
+	c.server.TLS = &tls.Config{Certificates: []tls.Certificate{*cert}, MinVersion: tls.VersionTLS12}
+	c.server.StartTLS()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(c.server.Certificate())
+
+	c.tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	if err := c.publish(stakeholderDomains); err != nil {
+		c.Close()
+
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// publish creates the stakeholders and publishes the signed configs of the consortium
+func (c *Consortium) publish(stakeholderDomains []string) error {
+	consortium := &models.Consortium{Domain: c.Domain, Version: 1}
+
+	var signingKeys []jose.SigningKey
+
+	for _, domain := range stakeholderDomains {
+		s, err := newStakeholder(domain)
+		if err != nil {
+			return err
+		}
+
+		c.Stakeholders = append(c.Stakeholders, s)
+
+		jwk, err := jose.JSONWebKey{Key: s.PrivateKey.Public(), KeyID: s.KeyID}.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("failed to marshal key of stakeholder %s: %w", domain, err)
+		}
+
+		consortium.Members = append(consortium.Members, &models.StakeholderListElement{
+			Domain:    domain,
+			DID:       s.DID,
+			PublicKey: models.PublicKey{ID: s.KeyID, JWK: jwk},
+		})
+
+		signingKey := s.signingKey()
+		signingKeys = append(signingKeys, *signingKey)
+
+		stakeholderConfig := &models.Stakeholder{Domain: domain, DID: s.DID,
+			Endpoints: []string{c.server.URL + sidetreePath}}
+
+		if c.configs[domain], err = sign(stakeholderConfig, *signingKey); err != nil {
+			return fmt.Errorf("failed to sign config of stakeholder %s: %w", domain, err)
+		}
+
+		didConf, err := didconfiguration.CreateDIDConfiguration(domain, s.DID, 0, signingKey)
+		if err != nil {
+			return fmt.Errorf("failed to create did configuration of stakeholder %s: %w", domain, err)
+		}
+
+		if c.didConfs[domain], err = json.Marshal(didConf); err != nil {
+			return err
+		}
+	}
+
+	var err error
+
+	if c.configs[c.Domain], err = sign(consortium, signingKeys...); err != nil {
+		return fmt.Errorf("failed to sign consortium config: %w", err)
+	}
+
+	return nil
+}
+
+func newStakeholder(domain string) (*Stakeholder, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key of stakeholder %s: %w", domain, err)
+	}
+
+	didKey, keyID := fingerprint.CreateDIDKey(pub)
+
+	return &Stakeholder{Domain: domain, DID: didKey, KeyID: keyID, PrivateKey: priv}, nil
+}
+
+func (s *Stakeholder) signingKey() *jose.SigningKey {
+	return &jose.SigningKey{Key: jose.JSONWebKey{Key: s.PrivateKey, KeyID: s.KeyID}, Algorithm: jose.EdDSA}
+}
+
+func sign(config interface{}, keys ...jose.SigningKey) ([]byte, error) {
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := jose.NewMultiSigner(keys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := signer.Sign(configBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(jws.FullSerialize()), nil
+}
+
+// Options returns the VDRI options needed to resolve the DIDs of the consortium: the connections to its domains
+// are routed to the local server, whose certificate is trusted
+func (c *Consortium) Options() []trustbloc.Option {
+	return []trustbloc.Option{trustbloc.WithTLSConfig(c.tlsConfig), trustbloc.WithDialContext(c.dialContext)}
+}
+
+// VDRI returns a VDRI resolving the DIDs of the consortium, with the given additional options
+func (c *Consortium) VDRI(opts ...trustbloc.Option) *trustbloc.VDRI {
+	return trustbloc.New(append(c.Options(), opts...)...)
+}
+
+// AddDoc adds a document, whose ID is a DID of the consortium, to the documents resolved by the sidetree endpoints
+func (c *Consortium) AddDoc(doc *did.Doc) error {
+	if !strings.HasPrefix(doc.ID, c.DIDPrefix()) {
+		return fmt.Errorf("%s isn't a DID of consortium %s", doc.ID, c.Domain)
+	}
+
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal doc: %w", err)
+	}
+
+	c.docsMutex.Lock()
+	defer c.docsMutex.Unlock()
+
+	c.docs[doc.ID] = docBytes
+
+	return nil
+}
+
+// DIDPrefix returns the prefix of the DIDs of the consortium, to be followed by a unique suffix
+func (c *Consortium) DIDPrefix() string {
+	return "did:trustbloc:" + c.Domain + ":"
+}
+
+// Close shuts down the local server of the consortium
+func (c *Consortium) Close() {
+	c.server.Close()
+}
+
+func (c *Consortium) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// sidetree endpoints are served at the address of the server, while the domains are mapped to it
+	if _, ok := c.configs[host]; !ok && addr != c.server.Listener.Addr().String() {
+		return nil, fmt.Errorf("trustbloctest: unknown domain %s", host)
+	}
+
+	return (&net.Dialer{}).DialContext(ctx, network, c.server.Listener.Addr().String())
+}
+
+func (c *Consortium) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == didConfigPath:
+		c.write(w, c.didConfs[hostname(r.Host)], "application/json")
+	case strings.HasPrefix(r.URL.Path, configPathPrefix):
+		// the consortium config is mirrored by the stakeholders
+		domain := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, configPathPrefix), configPathSuffix)
+		c.write(w, c.configs[domain], "application/jose")
+	case strings.HasPrefix(r.URL.Path, identifiersPath):
+		c.docsMutex.RLock()
+		doc := c.docs[strings.TrimPrefix(r.URL.Path, identifiersPath)]
+		c.docsMutex.RUnlock()
+
+		c.write(w, doc, didResolutionType)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c *Consortium) write(w http.ResponseWriter, data []byte, contentType string) {
+	if data == nil {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	if _, err := w.Write(data); err != nil {
+		log.Default().Errorf("trustbloctest: failed to write response: %s", err)
+	}
+}
+
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return host
+}
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustbloctest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+)
+
+func TestConsortium(t *testing.T) {
+	c, err := NewConsortium("consortium.example.com")
+	require.NoError(t, err)
+
+	defer c.Close()
+
+	require.Len(t, c.Stakeholders, 2)
+	require.Equal(t, "stakeholder1.consortium.example.com", c.Stakeholders[0].Domain)
+
+	doc := &did.Doc{Context: []string{did.Context}, ID: c.DIDPrefix() + "EiAtest"}
+	require.NoError(t, c.AddDoc(doc))
+
+	t.Run("success - resolve", func(t *testing.T) {
+		resolved, err := c.VDRI().Read(doc.ID)
+		require.NoError(t, err)
+		require.Equal(t, doc.ID, resolved.ID)
+	})
+
+	t.Run("success - consortium validated", func(t *testing.T) {
+		_, err := c.VDRI().ValidateConsortium(c.Domain)
+		require.NoError(t, err)
+	})
+
+	t.Run("success - trusted config logged", func(t *testing.T) {
+		trustLog, err := trustlog.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		v := c.VDRI(trustbloc.WithTrustLog(trustLog))
+
+		for i := 0; i < 2; i++ {
+			_, err = v.ValidateConsortium(c.Domain)
+			require.NoError(t, err)
+		}
+
+		entries, err := trustLog.Entries(c.Domain)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Len(t, entries[0].Members, 2)
+		require.ElementsMatch(t, []string{c.Stakeholders[0].Domain, c.Stakeholders[1].Domain},
+			entries[0].Evidence.Stakeholders)
+		require.NotEmpty(t, entries[0].Evidence.JWS)
+		require.NoError(t, trustLog.Verify())
+	})
+
+	t.Run("failure - unknown DID", func(t *testing.T) {
+		_, err := c.VDRI().Read(c.DIDPrefix() + "EiAunknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DID does not exist")
+	})
+
+	t.Run("failure - DID of another consortium", func(t *testing.T) {
+		err := c.AddDoc(&did.Doc{ID: "did:trustbloc:other.example.com:EiAtest"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isn't a DID of consortium consortium.example.com")
+
+		_, err = c.VDRI().Read("did:trustbloc:other.example.com:EiAtest")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "trustbloctest: unknown domain other.example.com")
+	})
+
+	t.Run("failure - invalid address", func(t *testing.T) {
+		_, err := c.dialContext(context.Background(), "tcp", "consortium.example.com")
+		require.Error(t, err)
+	})
+}
+
+func TestNewConsortium_Stakeholders(t *testing.T) {
+	c, err := NewConsortium("consortium.example.com", "one.example.com", "two.example.com", "three.example.com")
+	require.NoError(t, err)
+
+	defer c.Close()
+
+	require.Len(t, c.Stakeholders, 3)
+
+	_, err = c.VDRI().ValidateConsortium(c.Domain)
+	require.NoError(t, err)
+}
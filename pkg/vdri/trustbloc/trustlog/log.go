@@ -0,0 +1,276 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+)
+
+const (
+	// StoreName is the name of the store the entries of the trust log are put in
+	StoreName = "trustlog"
+
+	entryKeyPrefix = "entry_"
+)
+
+// Entry is a consortium config version trusted by the resolver. Each entry is linked to the previous one by its
+// hash, so entries can't be removed or modified without breaking the chain.
+type Entry struct {
+	// Sequence is the position of the entry in the log, starting at 0
+	Sequence uint64 `json:"sequence"`
+	// Time is when the config was first trusted
+	Time time.Time `json:"time"`
+	// Domain is the consortium domain
+	Domain string `json:"domain"`
+	// Version is the version of the consortium config
+	Version uint64 `json:"version"`
+	// ConfigID is the hex encoded history hash of the config, as referenced by the `previous` field of its successor
+	ConfigID string `json:"config_id"`
+	// Members are the DIDs of the stakeholders of the consortium, by domain
+	Members map[string]string `json:"members"`
+	// Evidence is what the config was trusted on
+	Evidence Evidence `json:"evidence"`
+	// Previous is the hash of the previous entry, empty for the first entry
+	Previous string `json:"previous,omitempty"`
+	// Hash is the hex encoded SHA-256 hash of the JSON encoding of the entry without its hash
+	Hash string `json:"hash"`
+}
+
+// Evidence is what a consortium config was trusted on
+type Evidence struct {
+	// JWS is the signed config file in JWS JSON serialization, unless the config is a COSE message
+	JWS string `json:"jws,omitempty"`
+	// COSE is the signed config file encoded as a COSE message
+	COSE []byte `json:"cose,omitempty"`
+	// Stakeholders are the domains of the stakeholders whose signatures and did-configurations were verified
+	Stakeholders []string `json:"stakeholders"`
+	// LocalOverride is true if the config is a local override, whose stakeholders aren't verified
+	LocalOverride bool `json:"local_override,omitempty"`
+}
+
+// Log is a hash-linked log of the consortium config versions trusted by a resolver, persisted in a store so security
+// teams can audit which membership set was trusted when. It's safe for concurrent use.
+type Log struct {
+	store storage.Store
+	now   func() time.Time
+	mutex sync.Mutex
+	head  *Entry
+	// latest are the config ids of the latest entries by domain
+	latest map[string]string
+}
+
+// New opens the trust log in the store of the given storage provider
+func New(provider storage.Provider, opts ...Option) (*Log, error) {
+	store, err := provider.OpenStore(StoreName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trust log store: %w", err)
+	}
+
+	l := &Log{store: store, now: time.Now, latest: map[string]string{}}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	entries, err := l.Entries("")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		l.head = entry
+		l.latest[entry.Domain] = entry.ConfigID
+	}
+
+	return l, nil
+}
+
+// Record appends a trusted consortium config to the log, with the domains of the stakeholders that were verified.
+// Nothing is appended if the config is the latest one logged for the domain, in which case the returned entry is nil.
+func (l *Log) Record(domain string, file *models.ConsortiumFileData, stakeholders []string,
+	localOverride bool) (*Entry, error) {
+	if file == nil || file.Config == nil {
+		return nil, fmt.Errorf("missing consortium config")
+	}
+
+	configID, err := configID(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consortium config id: %w", err)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.latest[domain] == configID {
+		return nil, nil
+	}
+
+	entry := &Entry{Time: l.now().UTC(), Domain: domain, Version: file.Config.Version, ConfigID: configID,
+		Members: map[string]string{}, Evidence: newEvidence(file, stakeholders, localOverride)}
+
+	for _, member := range file.Config.Members {
+		if member != nil {
+			entry.Members[member.Domain] = member.DID
+		}
+	}
+
+	if l.head != nil {
+		entry.Sequence = l.head.Sequence + 1
+		entry.Previous = l.head.Hash
+	}
+
+	if entry.Hash, err = entry.hash(); err != nil {
+		return nil, err
+	}
+
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trust log entry: %w", err)
+	}
+
+	if err = l.store.Put(entryKey(entry.Sequence), entryBytes); err != nil {
+		return nil, fmt.Errorf("failed to put trust log entry in store: %w", err)
+	}
+
+	l.head = entry
+	l.latest[domain] = configID
+
+	return entry, nil
+}
+
+// configID returns the history id of a consortium config. Unsigned configs, eg. local overrides, are identified by
+// the hash of their JSON encoding instead.
+func configID(file *models.ConsortiumFileData) (string, error) {
+	if file.Payload() != nil {
+		return policy.New(file.Config).HistoryID(file)
+	}
+
+	configBytes, err := json.Marshal(file.Config)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(configBytes)
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func newEvidence(file *models.ConsortiumFileData, stakeholders []string, localOverride bool) Evidence {
+	evidence := Evidence{Stakeholders: append([]string{}, stakeholders...), LocalOverride: localOverride}
+
+	switch {
+	case file.COSE != nil:
+		evidence.COSE = file.COSE.Bytes()
+	case file.JWS != nil:
+		evidence.JWS = file.JWS.FullSerialize()
+	}
+
+	return evidence
+}
+
+// Entries returns the entries of the log for the given consortium domain, or all the entries if the domain is empty,
+// in the order they were appended
+func (l *Log) Entries(domain string) ([]*Entry, error) {
+	it := l.store.Iterator(entryKeyPrefix, entryKeyPrefix+storage.EndKeySuffix)
+	defer it.Release()
+
+	var entries []*Entry
+
+	for it.Next() {
+		entry := &Entry{}
+
+		if err := json.Unmarshal(it.Value(), entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trust log entry %s: %w", it.Key(), err)
+		}
+
+		if domain == "" || entry.Domain == domain {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read trust log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Verify verifies the hash chain of the log, failing at the first entry that was modified, removed or inserted
+func (l *Log) Verify() error {
+	entries, err := l.Entries("")
+	if err != nil {
+		return err
+	}
+
+	return Verify(entries)
+}
+
+// Verify verifies the hash chain of all the entries of a log, eg. exported from the log of another resolver
+func Verify(entries []*Entry) error {
+	previous := ""
+
+	for i, entry := range entries {
+		if entry.Sequence != uint64(i) {
+			return fmt.Errorf("trust log entry %d has sequence %d", i, entry.Sequence)
+		}
+
+		if entry.Previous != previous {
+			return fmt.Errorf("trust log entry %d isn't linked to the previous entry", i)
+		}
+
+		hash, err := entry.hash()
+		if err != nil {
+			return err
+		}
+
+		if hash != entry.Hash {
+			return fmt.Errorf("trust log entry %d doesn't match its hash", i)
+		}
+
+		previous = entry.Hash
+	}
+
+	return nil
+}
+
+// hash returns the hex encoded SHA-256 hash of the JSON encoding of the entry without its hash
+func (e *Entry) hash() (string, error) {
+	unhashed := *e
+	unhashed.Hash = ""
+
+	entryBytes, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal trust log entry: %w", err)
+	}
+
+	digest := sha256.Sum256(entryBytes)
+
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func entryKey(sequence uint64) string {
+	return fmt.Sprintf("%s%020d", entryKeyPrefix, sequence)
+}
+
+// Option is a trust log option
+type Option func(opts *Log)
+
+// WithClock option sets the clock the times of the entries are read from. Defaults to the system clock.
+func WithClock(now func() time.Time) Option {
+	return func(opts *Log) {
+		opts.now = now
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package trustlog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/stretchr/testify/require"
+
+	mockmodels "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+)
+
+func consortiumFile(t *testing.T, domain string, version uint64, members ...string) *models.ConsortiumFileData {
+	consortium := mockmodels.DummyConsortium(domain, nil)
+	consortium.Version = version
+
+	for _, member := range members {
+		consortium.Members = append(consortium.Members,
+			&models.StakeholderListElement{Domain: member, DID: "did:trustbloc:" + domain + ":" + member})
+	}
+
+	jws, err := mockmodels.WrapConsortium(consortium)
+	require.NoError(t, err)
+
+	file, err := models.ParseConsortium([]byte(jws))
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestLog(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("record and verify", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		l, err := New(provider, WithClock(func() time.Time { return now }))
+		require.NoError(t, err)
+
+		first, err := l.Record("consortium.net", consortiumFile(t, "consortium.net", 1, "a.net", "b.net"),
+			[]string{"a.net"}, false)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, first.Sequence)
+		require.Empty(t, first.Previous)
+		require.Equal(t, now, first.Time)
+		require.EqualValues(t, 1, first.Version)
+		require.Equal(t, map[string]string{"a.net": "did:trustbloc:consortium.net:a.net",
+			"b.net": "did:trustbloc:consortium.net:b.net"}, first.Members)
+		require.Equal(t, []string{"a.net"}, first.Evidence.Stakeholders)
+		require.NotEmpty(t, first.Evidence.JWS)
+		require.NotEmpty(t, first.ConfigID)
+
+		// the same config isn't logged twice
+		entry, err := l.Record("consortium.net", consortiumFile(t, "consortium.net", 1, "a.net", "b.net"),
+			[]string{"a.net", "b.net"}, false)
+		require.NoError(t, err)
+		require.Nil(t, entry)
+
+		second, err := l.Record("other.net", consortiumFile(t, "other.net", 1, "c.net"), nil, true)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, second.Sequence)
+		require.Equal(t, first.Hash, second.Previous)
+		require.True(t, second.Evidence.LocalOverride)
+
+		third, err := l.Record("consortium.net", consortiumFile(t, "consortium.net", 2, "a.net"),
+			[]string{"a.net"}, false)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, third.Sequence)
+		require.Equal(t, second.Hash, third.Previous)
+
+		require.NoError(t, l.Verify())
+
+		entries, err := l.Entries("consortium.net")
+		require.NoError(t, err)
+		require.Equal(t, []*Entry{first, third}, entries)
+
+		// the log is reopened from the store
+		l, err = New(provider)
+		require.NoError(t, err)
+
+		entries, err = l.Entries("")
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		entry, err = l.Record("consortium.net", consortiumFile(t, "consortium.net", 2, "a.net"), nil, false)
+		require.NoError(t, err)
+		require.Nil(t, entry)
+
+		entry, err = l.Record("consortium.net", consortiumFile(t, "consortium.net", 1, "a.net", "b.net"), nil, false)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, entry.Sequence)
+		require.Equal(t, third.Hash, entry.Previous)
+	})
+
+	t.Run("unsigned config", func(t *testing.T) {
+		l, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		entry, err := l.Record("consortium.net", &models.ConsortiumFileData{
+			Config: mockmodels.DummyConsortium("consortium.net", nil)}, nil, true)
+		require.NoError(t, err)
+		require.NotEmpty(t, entry.ConfigID)
+		require.Empty(t, entry.Evidence.JWS)
+
+		entry, err = l.Record("consortium.net", &models.ConsortiumFileData{
+			Config: &models.Consortium{Domain: "consortium.net", Version: 1}}, nil, true)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, entry.Sequence)
+	})
+
+	t.Run("COSE config", func(t *testing.T) {
+		data, err := json.Marshal(mockmodels.DummyConsortium("consortium.net", nil))
+		require.NoError(t, err)
+
+		msg, err := mockmodels.DummyCOSEWrap(string(data))
+		require.NoError(t, err)
+
+		file, err := models.ParseConsortiumCOSE(msg)
+		require.NoError(t, err)
+
+		l, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		entry, err := l.Record("consortium.net", file, nil, false)
+		require.NoError(t, err)
+		require.Equal(t, msg, entry.Evidence.COSE)
+		require.Empty(t, entry.Evidence.JWS)
+	})
+
+	t.Run("tampered log", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		l, err := New(provider)
+		require.NoError(t, err)
+
+		for version := uint64(1); version <= 3; version++ {
+			_, err = l.Record("consortium.net", consortiumFile(t, "consortium.net", version, "a.net"), nil, false)
+			require.NoError(t, err)
+		}
+
+		store, err := provider.OpenStore(StoreName)
+		require.NoError(t, err)
+
+		entries, err := l.Entries("")
+		require.NoError(t, err)
+
+		entries[1].Members["b.net"] = "did:trustbloc:consortium.net:b.net"
+		require.EqualError(t, Verify(entries), "trust log entry 1 doesn't match its hash")
+
+		require.EqualError(t, Verify([]*Entry{entries[0], entries[2]}), "trust log entry 1 has sequence 2")
+
+		entries[2].Sequence = 1
+		require.EqualError(t, Verify([]*Entry{entries[0], entries[2]}),
+			"trust log entry 1 isn't linked to the previous entry")
+
+		require.NoError(t, store.Delete(entryKey(1)))
+		require.Error(t, l.Verify())
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		_, err := New(&failingProvider{openErr: errors.New("open failed")})
+		require.EqualError(t, err, "failed to open trust log store: open failed")
+
+		provider := mem.NewProvider()
+
+		store, err := provider.OpenStore(StoreName)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(entryKey(0), []byte("{")))
+
+		_, err = New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal trust log entry")
+
+		l, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		_, err = l.Record("consortium.net", nil, nil, false)
+		require.EqualError(t, err, "missing consortium config")
+
+		file := consortiumFile(t, "consortium.net", 1)
+		file.Config.Policy.HistoryHash = "MD4"
+
+		_, err = l.Record("consortium.net", file, nil, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get consortium config id")
+	})
+}
+
+type failingProvider struct {
+	storage.Provider
+	openErr error
+}
+
+func (p *failingProvider) OpenStore(string) (storage.Store, error) {
+	return nil, p.openErr
+}
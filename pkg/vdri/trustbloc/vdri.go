@@ -6,30 +6,48 @@ SPDX-License-Identifier: Apache-2.0
 package trustbloc
 
 import (
-	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
-	"encoding/json"
-	"errors"
+	"encoding/hex"
 	"fmt"
-	"math/rand"
+	"net"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bluele/gcache"
 	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
-	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
-	"github.com/hyperledger/aries-framework-go/pkg/vdri/httpbinding"
-	log "github.com/sirupsen/logrus"
-
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/key"
+	"github.com/piprate/json-gold/ld"
+	"github.com/square/go-jose/v3"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/did"
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/clock"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/workerpool"
+	"github.com/trustbloc/trustbloc-did-method/pkg/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/httpconfig"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/localconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/memorycacheconfig"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/mirrorconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/signatureconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/config/verifyingconfig"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/didconfiguration"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/discovery/staticdiscovery"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/endpoint"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/jwks"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/policy"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/random"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/selection/staticselection"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/web"
 )
 
 type configService interface {
@@ -45,58 +63,245 @@ type didConfigService interface {
 	VerifyStakeholder(domain string, doc *docdid.Doc) error
 }
 
+type keySetService interface {
+	KeySet(url string) (*jose.JSONWebKeySet, error)
+}
+
+type didResolver interface {
+	Resolve(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error)
+}
+
+type didcommResolver interface {
+	Resolve(did string, agentDoc *docdid.Doc) (*docdid.Doc, error)
+}
+
 type vdri interface {
 	Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error)
 	Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error)
 }
 
 // VDRI bloc
+//
+// A VDRI is safe for concurrent use by multiple goroutines, so a single instance can be shared by a server.
+// Options must not be changed after New returns.
 type VDRI struct {
 	resolverURL      string
 	configService    configService
 	endpointService  endpointService
 	didConfigService didConfigService
+	keySetService    keySetService
+	trustLog         *trustlog.Log
 	getHTTPVDRI      func(url string) (vdri, error) // needed for unit test
 	tlsConfig        *tls.Config
 	authToken        string
-
-	validatedConsortium map[string]bool
+	transportOpts    transport.Options
+	allowInsecure    []string
+	signatureAlgs    []jose.SignatureAlgorithm
+	canonicalization DocCanonicalization
+	documentLoader   ld.DocumentLoader
+	orb              *orbCompatibility
+	maxEndpoints     int
+	maxResponseSize  int64
+	configQuorum     int
+	hooks            Hooks
+	logger           log.Logger
+	docCacheTTL      time.Duration
+	docCache         *docCache
+	resolutionCache  ResolutionCache
+	cacheScope       string
+	resolutionSize   int
+	staleDocs        bool
+	httpTransport    *http.Transport
+	rand             random.Source
+	now              func() time.Time
+	httpVDRIs        map[string]vdri
+	httpVDRIsMutex   sync.Mutex
+	stakeholderDocs  gcache.Cache
+	docCacheSize     int
+	webVDRI          vdri
+	keyVDRI          vdri
+
+	// stakeholderResolver resolves stakeholder DIDs of other methods than did:trustbloc
+	stakeholderResolver didResolver
+
+	// didcommResolver resolves DIDs over DIDComm with the agents of the stakeholders
+	didcommResolver didcommResolver
+
+	validatedConsortium      map[string]bool
+	validatedConsortiumMutex sync.RWMutex
+
+	// signingRelationships are the verification relationships of the keys stakeholders may sign configs with
+	signingRelationships []docdid.VerificationRelationship
+
+	// verificationConcurrency is the maximum number of stakeholders fetched and verified concurrently
+	verificationConcurrency int
+
+	// pool runs the concurrent and background tasks of the vdri, shared with the vdris of its tenants and domains
+	pool     *workerpool.Pool
+	poolSize int
+
+	// trustAnchors are the consortium configs pinned as roots of trust, by consortium domain
+	trustStore        trustStore
+	trustAnchors      map[string]*models.ConsortiumFileData
+	trustAnchorsMutex sync.RWMutex
+
+	// domainOpts are the option overrides of consortium domains, used to create their own VDRIs
+	domainOpts  map[string][]Option
+	domainVDRIs map[string]*VDRI
+
+	// localConfigs are the directories of local config overrides, by consortium domain
+	localConfigs map[string]string
+
+	// tenantOpts are the option overrides of tenants, used to create their own isolated VDRIs
+	tenantOpts  map[string][]Option
+	tenantVDRIs map[string]*VDRI
+
+	// buildDomain is the consortium domain of the DIDs created by Build, and operationKeysHandler receives their keys
+	buildDomain          string
+	operationKeysHandler OperationKeysHandler
+
+	// didClient sends the create operations of Build, created on first use
+	didClient     *did.Client
+	didClientOnce sync.Once
 }
 
+const (
+	trustblocDIDMethod             = "trustbloc"
+	keyDIDMethod                   = "key"
+	didCommServiceType             = "did-communication"
+	defaultStakeholderDocCacheSize = 100
+	defaultVerificationConcurrency = 8
+)
+
 // New creates new bloc vdri
 func New(opts ...Option) *VDRI {
-	v := &VDRI{}
+	v := &VDRI{
+		docCacheSize:            defaultStakeholderDocCacheSize,
+		verificationConcurrency: defaultVerificationConcurrency,
+		maxResponseSize:         transport.DefaultMaxResponseSize,
+		poolSize:                workerpool.DefaultSize,
+		signingRelationships:    defaultSigningRelationships,
+		now:                     time.Now,
+	}
 
 	for _, opt := range opts {
 		opt(v)
 	}
 
-	v.getHTTPVDRI = func(url string) (vdri, error) {
-		return httpbinding.New(url,
-			httpbinding.WithTLSConfig(v.tlsConfig), httpbinding.WithResolveAuthToken(v.authToken))
+	if v.pool == nil {
+		v.pool = workerpool.New(v.poolSize)
 	}
 
-	configService := httpconfig.NewService(httpconfig.WithTLSConfig(v.tlsConfig))
-	verifyingService := signatureconfig.NewService(verifyingconfig.NewService(configService))
-	v.configService = memorycacheconfig.NewService(verifyingService)
-	v.endpointService = endpoint.NewService(
-		staticdiscovery.NewService(v.configService),
-		staticselection.NewService(v.configService))
+	v.rand = random.OrDefault(v.rand)
+	v.logger = log.OrDefault(v.logger)
+
+	// a single TLS session cache and connection pool is shared by all http clients of the vdri
+	v.tlsConfig = transport.WithSessionCache(transport.WithPolicy(v.tlsConfig, v.transportOpts.TLSPolicy))
+	v.httpTransport = transport.New(v.tlsConfig, &v.transportOpts)
+	v.httpVDRIs = make(map[string]vdri)
+	v.getHTTPVDRI = v.cachedHTTPVDRI
+
+	// configs, did-configurations and did docs are only fetched over https, unless explicitly allowed
+	httpsTransport := transport.HTTPSOnly(v.httpTransport, v.allowInsecure)
+
+	v.initServices(httpsTransport)
 
-	v.didConfigService = didconfiguration.NewService(didconfiguration.WithTLSConfig(v.tlsConfig))
+	v.webVDRI = web.New(web.WithTransport(httpsTransport), web.WithMaxResponseSize(v.maxResponseSize))
+	v.keyVDRI = key.New()
 
 	v.validatedConsortium = map[string]bool{}
 
+	if v.docCacheSize <= 0 {
+		v.docCacheSize = defaultStakeholderDocCacheSize
+	}
+
+	v.stakeholderDocs = gcache.New(v.docCacheSize).LRU().Clock(clock.Func(v.now)).Build()
+
+	if v.docCacheTTL > 0 {
+		v.docCache = newDocCache(v.cacheScope, v.docCacheTTL, v.newResolutionCache(), v.now, v.pool, v.logger)
+	}
+
+	v.tenantVDRIs = make(map[string]*VDRI, len(v.tenantOpts))
+
+	for tenant, overrides := range v.tenantOpts {
+		tenantOpts := append(append([]Option{}, opts...), overrides...)
+		tenantOpts = append(tenantOpts, withoutTenants(), withWorkerPool(v.pool),
+			withCacheScope(v.cacheScope+"tenant/"+tenant+"/"))
+
+		v.tenantVDRIs[tenant] = New(tenantOpts...)
+	}
+
+	v.domainVDRIs = make(map[string]*VDRI, len(v.domainOpts))
+
+	for domain, overrides := range v.domainOpts {
+		domainOpts := append(append([]Option{}, opts...), overrides...)
+		domainOpts = append(domainOpts, withoutDomainOptions(), withWorkerPool(v.pool),
+			withCacheScope(v.cacheScope+"domain/"+domain+"/"))
+
+		v.domainVDRIs[domain] = New(domainOpts...)
+	}
+
 	return v
 }
 
+// initServices creates the config, endpoint and did-configuration services, unless they're set as options
+func (v *VDRI) initServices(httpsTransport http.RoundTripper) {
+	configService := httpconfig.NewService(httpconfig.WithTransport(httpsTransport),
+		httpconfig.WithMaxResponseSize(v.maxResponseSize))
+	mirroredService := verifyingconfig.NewService(
+		mirrorconfig.NewService(configService, mirrorconfig.WithRandomSource(v.rand), mirrorconfig.WithLogger(v.logger)),
+		verifyingconfig.WithQuorum(v.configQuorum), verifyingconfig.WithWarningHandler(v.hooks.verificationWarning),
+		verifyingconfig.WithRandomSource(v.rand), verifyingconfig.WithLogger(v.logger))
+	verifyingService := signatureconfig.NewService(mirroredService,
+		signatureconfig.WithSignatureAlgorithms(v.signatureAlgs...),
+		signatureconfig.WithWarningHandler(v.hooks.verificationWarning),
+		signatureconfig.WithRandomSource(v.rand), signatureconfig.WithLogger(v.logger))
+	cachingService := memorycacheconfig.NewService(verifyingService, memorycacheconfig.WithClock(v.now))
+	v.trustStore = cachingService
+	v.trustAnchors = map[string]*models.ConsortiumFileData{}
+
+	// services set as options replace the built-in ones
+	if v.configService == nil {
+		v.configService = localconfig.NewService(cachingService, v.localConfigs, localconfig.WithLogger(v.logger))
+	}
+
+	if v.endpointService == nil {
+		v.endpointService = endpoint.NewService(
+			staticdiscovery.NewService(v.configService),
+			staticselection.NewService(v.configService, staticselection.WithRandomSource(v.rand)))
+	}
+
+	if v.didConfigService == nil {
+		v.didConfigService = didconfiguration.NewService(didconfiguration.WithTransport(httpsTransport),
+			didconfiguration.WithSignatureAlgorithms(v.signatureAlgs...),
+			didconfiguration.WithMaxResponseSize(v.maxResponseSize), didconfiguration.WithLogger(v.logger))
+	}
+
+	if v.keySetService == nil {
+		v.keySetService = jwks.NewService(jwks.WithTransport(httpsTransport),
+			jwks.WithMaxResponseSize(v.maxResponseSize), jwks.WithClock(v.now))
+	}
+}
+
 // Accept did method
 func (v *VDRI) Accept(method string) bool {
-	return method == "trustbloc"
+	return method == trustblocDIDMethod
 }
 
 // Close vdri
 func (v *VDRI) Close() error {
+	v.httpTransport.CloseIdleConnections()
+
+	for _, dv := range v.domainVDRIs {
+		dv.httpTransport.CloseIdleConnections()
+	}
+
+	for _, tv := range v.tenantVDRIs {
+		if err := tv.Close(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -105,212 +310,779 @@ func (v *VDRI) Store(doc *docdid.Doc, by *[]vdriapi.ModifiedBy) error {
 	return nil
 }
 
-// Build did doc
-func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
-	return nil, fmt.Errorf("build method not supported for did bloc")
+// cachedHTTPVDRI returns the http binding vdri for the given url, reusing a previously created instance
+// so that its keep-alive connections are reused across resolutions
+func (v *VDRI) cachedHTTPVDRI(url string) (vdri, error) {
+	v.httpVDRIsMutex.Lock()
+	defer v.httpVDRIsMutex.Unlock()
+
+	if resolver, ok := v.httpVDRIs[url]; ok {
+		return resolver, nil
+	}
+
+	// resolutions share the transport of the vdri, so connections to a sidetree endpoint are pooled across reads
+	resolver, err := newHTTPResolver(url, &http.Client{Transport: v.httpTransport}, v.authToken, v.maxResponseSize,
+		v.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transport.CheckHTTPS(url, v.allowInsecure); err != nil {
+		return nil, err
+	}
+
+	v.httpVDRIs[url] = resolver
+
+	return resolver, nil
 }
 
-func (v *VDRI) sidetreeResolve(url, did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+func (v *VDRI) sidetreeResolve(ctx context.Context, url, did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
 	resolver, err := v.getHTTPVDRI(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new sidetree vdri: %w", err)
 	}
 
-	doc, err := resolver.Read(did, opts...)
+	doc, err := readWithContext(ctx, resolver, did, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve did: %w", err)
+		return nil, errcode.Errorf(errcode.ResolutionFailed, "failed to resolve did: %w", err)
 	}
 
 	return doc, nil
 }
 
+// resolveAt resolves a DID at the given resolution URL, as an orb DID if orb compatibility is enabled
+func (v *VDRI) resolveAt(ctx context.Context, url, did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	if v.orb != nil {
+		return v.orb.resolve(ctx, v, url, did, opts...)
+	}
+
+	return v.sidetreeResolve(ctx, url, did, opts...)
+}
+
 const (
 	expectedTrustblocDIDParts = 4
 	domainDIDPart             = 2
 )
 
-func (v *VDRI) Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) { //nolint: gocyclo
-	if v.resolverURL != "" {
-		return v.sidetreeResolve(v.resolverURL, did, opts...)
+// Read resolves a DID. If the DID has a hl parameter, the resolved doc must match its hashlink, otherwise
+// a *HashlinkMismatchError is returned.
+func (v *VDRI) Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	return v.ReadWithContext(context.Background(), did, opts...)
+}
+
+// ReadWithContext resolves a DID like Read. The fetches of the consortium and stakeholder configs, the discovery of
+// the endpoints and the resolution requests are aborted when the context is done, returning the context error.
+func (v *VDRI) ReadWithContext(ctx context.Context, did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	doc, _, err := v.readWithContext(ctx, did, opts...)
+
+	return doc, err
+}
+
+// ReadWithMetadata resolves a DID like Read, also returning the method metadata of the resolved doc.
+// The doc of a long-form DID is constructed from its initial state without network access, and is marked
+// as not published.
+func (v *VDRI) ReadWithMetadata(did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, *models.MethodMetaData, error) {
+	return v.readWithContext(context.Background(), did, opts...)
+}
+
+func (v *VDRI) readWithContext(ctx context.Context, did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, *models.MethodMetaData, error) {
+	if dv := v.forDomain(didDomain(did)); dv != v {
+		return dv.readWithContext(ctx, did, opts...)
 	}
 
-	// parse did
-	didParts := strings.Split(did, ":")
-	if len(didParts) != expectedTrustblocDIDParts {
-		return nil, fmt.Errorf("wrong did %s", did)
+	v.hooks.resolveStart(did)
+
+	doc, metadata, err := v.readWithMetadata(ctx, did, nil, opts...)
+
+	v.hooks.resolveDone(did, doc, err)
+
+	return doc, metadata, err
+}
+
+// ReadWithTrace resolves a DID like Read, also returning a step-by-step report of the resolution: the validation
+// of the consortium config and its stakeholders, the endpoints selected, the outcome of each endpoint and the
+// endpoint whose doc was returned. The report is returned even if the resolution fails. Traced resolutions
+// always validate the consortium and bypass the resolution cache.
+func (v *VDRI) ReadWithTrace(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, *ResolutionTrace, error) {
+	if dv := v.forDomain(didDomain(did)); dv != v {
+		return dv.ReadWithTrace(did, opts...)
+	}
+
+	trace := &ResolutionTrace{DID: did}
+
+	v.hooks.resolveStart(did)
+
+	doc, _, err := v.readWithMetadata(context.Background(), did, trace, opts...)
+
+	v.hooks.resolveDone(did, doc, err)
+
+	return doc, trace, err
+}
+
+func (v *VDRI) readWithMetadata(ctx context.Context, did string, trace *ResolutionTrace,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, *models.MethodMetaData, error) {
+	did, hl, err := splitHashlink(did)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if _, ok := v.validatedConsortium[didParts[domainDIDPart]]; !ok {
-		_, err := v.ValidateConsortium(didParts[domainDIDPart])
+	did, initialState, err := splitInitialState(did)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		doc      *docdid.Doc
+		metadata *models.MethodMetaData
+	)
+
+	switch {
+	case initialState != "":
+		doc, err = docFromInitialState(did, initialState)
+		metadata = &models.MethodMetaData{InitialState: initialState}
+
+		trace.add(TraceStepInitialState, err, "doc constructed from the initial state of the long-form did")
+	case trace != nil:
+		doc, err = v.read(ctx, did, trace, opts...)
+		metadata = &models.MethodMetaData{Published: true}
+	default:
+		doc, metadata, err = v.readCached(ctx, did, opts...)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if hl != "" {
+		err = verifyHashlink(did, doc, hl)
+
+		trace.add(TraceStepHashlink, err, "doc verified against hashlink %s", hl)
+
 		if err != nil {
-			return nil, fmt.Errorf("invalid consortium: %w", err)
+			return nil, nil, err
+		}
+	}
+
+	return doc, metadata, nil
+}
+
+// readCached resolves a DID using the resolution cache, if enabled. With stale-while-revalidate, an expired doc
+// is returned as stale while being refreshed in the background, regardless of the context of the call.
+func (v *VDRI) readCached(ctx context.Context, did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, *models.MethodMetaData, error) {
+	if v.docCache == nil || !cacheable(opts) {
+		doc, err := v.read(ctx, did, nil, opts...)
+
+		return doc, &models.MethodMetaData{Published: true}, err
+	}
+
+	if doc, expired, ok := v.docCache.get(did); ok {
+		if !expired {
+			return doc, &models.MethodMetaData{Published: true}, nil
+		}
+
+		if v.staleDocs {
+			v.docCache.refresh(did, func() (*docdid.Doc, error) {
+				return v.read(context.Background(), did, nil, opts...)
+			})
+
+			return doc, &models.MethodMetaData{Published: true, Stale: true}, nil
 		}
+	}
+
+	doc, err := v.read(ctx, did, nil, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v.docCache.set(did, doc)
+
+	return doc, &models.MethodMetaData{Published: true}, nil
+}
+
+// cacheable returns true if a resolution with the given options can be served from the cache, ie. the latest
+// version of the doc is requested and the cache isn't bypassed
+func cacheable(opts []vdriapi.ResolveOpts) bool {
+	resolveOpts := &vdriapi.ResolveDIDOpts{}
+
+	for _, opt := range opts {
+		opt(resolveOpts)
+	}
+
+	return !resolveOpts.NoCache && resolveOpts.VersionID == nil && resolveOpts.VersionTime == ""
+}
+
+func (v *VDRI) read(ctx context.Context, did string, trace *ResolutionTrace,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	if v.resolverURL != "" {
+		doc, err := v.resolveAt(ctx, v.resolverURL, did, opts...)
+
+		trace.add(TraceStepEndpoint, err, "resolved from resolver %s", v.resolverURL)
+		trace.setEndpoint(v.resolverURL)
 
-		v.validatedConsortium[didParts[domainDIDPart]] = true
+		return doc, err
 	}
 
-	endpoints, err := v.endpointService.GetEndpoints(didParts[domainDIDPart])
+	endpoints, err := v.endpoints(ctx, did, trace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get endpoints: %w", err)
+		return nil, err
 	}
 
 	if len(endpoints) == 0 {
-		return nil, errors.New("list of endpoints is empty")
+		return nil, errcode.Errorf(errcode.EndpointsUnavailable, "list of endpoints is empty")
 	}
 
-	var doc *docdid.Doc
+	endpoints = v.limitEndpoints(endpoints, v.maxEndpoints)
+
+	trace.add(TraceStepEndpoints, nil, "selected endpoints %s", endpointURLs(endpoints))
 
-	var docBytes []byte
+	var doc *comparableDoc
 
 	for _, e := range endpoints {
-		resp, err := v.sidetreeResolve(e.URL+"/identifiers", did, opts...)
+		v.hooks.endpointSelected(did, e)
+
+		resp, err := v.resolveFromEndpoint(ctx, e, did, opts...)
+
+		trace.add(TraceStepEndpoint, err, "resolved from endpoint %s of %s", e.URL, e.Domain)
+
 		if err != nil {
 			return nil, err
 		}
 
-		respBytes, err := canonicalizeDoc(resp)
+		trace.setEndpoint(e.URL)
+
+		respDoc, err := newComparableDoc(resp, v.canonicalization, v.documentLoader)
 		if err != nil {
 			return nil, fmt.Errorf("cannot canonicalize resolved doc: %w", err)
 		}
 
-		if doc != nil && !bytes.Equal(docBytes, respBytes) {
-			log.Debugf("mismatch in document contents for did %s. Doc 1: %s, Doc 2: %s",
-				did, string(docBytes), string(respBytes))
+		if doc != nil {
+			equal, err := doc.equal(respDoc)
+			if err != nil {
+				return nil, fmt.Errorf("cannot canonicalize resolved doc: %w", err)
+			}
+
+			if !equal {
+				logDocMismatch(v.logger, did, doc.doc, respDoc.doc)
+			}
+		}
+
+		doc = respDoc
+	}
+
+	return doc.doc, nil
+}
+
+// Endpoints returns the endpoints used to resolve a DID, without resolving it. If a resolver URL is set,
+// it's the only endpoint. Otherwise, these are the endpoints of the DID's consortium (see GetEndpoints).
+func (v *VDRI) Endpoints(did string) ([]*models.Endpoint, error) {
+	if dv := v.forDomain(didDomain(did)); dv != v {
+		return dv.Endpoints(did)
+	}
+
+	return v.endpoints(context.Background(), did, nil)
+}
+
+func (v *VDRI) endpoints(ctx context.Context, did string, trace *ResolutionTrace) ([]*models.Endpoint, error) {
+	if v.resolverURL != "" {
+		return []*models.Endpoint{{URL: v.resolverURL}}, nil
+	}
+
+	didParts := strings.Split(did, ":")
+	if len(didParts) != expectedTrustblocDIDParts {
+		return nil, errcode.Errorf(errcode.InvalidDID, "wrong did %s", did)
+	}
+
+	return v.getEndpoints(ctx, didParts[domainDIDPart], trace)
+}
+
+// GetEndpoints returns the sidetree endpoints selected for resolving the DIDs of a consortium, validating the
+// consortium first if needed. When a maximum number of endpoints is set, a Read only contacts a random subset
+// of them.
+func (v *VDRI) GetEndpoints(domain string) ([]*models.Endpoint, error) {
+	return v.GetEndpointsWithContext(context.Background(), domain)
+}
+
+// GetEndpointsWithContext returns the sidetree endpoints of a consortium like GetEndpoints, aborting the validation
+// of the consortium and the discovery of the endpoints when the context is done
+func (v *VDRI) GetEndpointsWithContext(ctx context.Context, domain string) ([]*models.Endpoint, error) {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.GetEndpointsWithContext(ctx, domain)
+	}
+
+	return v.getEndpoints(ctx, domain, nil)
+}
+
+func (v *VDRI) getEndpoints(ctx context.Context, domain string, trace *ResolutionTrace) ([]*models.Endpoint, error) {
+	if trace != nil || !v.isValidatedConsortium(domain) {
+		_, err := v.validateConsortium(ctx, domain, trace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid consortium: %w", err)
+		}
+
+		v.setValidatedConsortium(domain)
+	}
+
+	endpoints, err := v.fetchEndpoints(ctx, domain)
+
+	trace.add(TraceStepEndpoints, err, "endpoints of consortium %s: %s", domain, endpointURLs(endpoints))
+
+	if err != nil {
+		return nil, errcode.Errorf(errcode.EndpointsUnavailable, "failed to get endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func stakeholderDomain(sfd *models.StakeholderFileData) string {
+	if sfd.Config == nil {
+		return ""
+	}
+
+	return sfd.Config.Domain
+}
+
+func endpointURLs(endpoints []*models.Endpoint) []string {
+	urls := make([]string, len(endpoints))
+	for i, e := range endpoints {
+		urls[i] = e.URL
+	}
+
+	return urls
+}
+
+// limitEndpoints returns a random subset of max endpoints, or all endpoints if max is zero
+func (v *VDRI) limitEndpoints(endpoints []*models.Endpoint, max int) []*models.Endpoint {
+	if max <= 0 || len(endpoints) <= max {
+		return endpoints
+	}
+
+	perm := v.rand.Perm(len(endpoints))
+
+	out := make([]*models.Endpoint, max)
+	for i := range out {
+		out[i] = endpoints[perm[i]]
+	}
+
+	return out
+}
+
+// resolveFromEndpoint resolves a DID from a sidetree endpoint. If DIDComm resolution is enabled and the DID doc
+// of the endpoint's stakeholder lists a did-communication service, the DID is resolved by the stakeholder's agent.
+func (v *VDRI) resolveFromEndpoint(ctx context.Context, e *models.Endpoint, did string,
+	opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	if v.didcommResolver != nil {
+		agentDoc, err := v.stakeholderAgentDoc(ctx, e.Domain)
+		if err != nil {
+			return nil, err
+		}
+
+		if agentDoc != nil {
+			return v.didcommResolver.Resolve(did, agentDoc)
 		}
+	}
+
+	if v.orb != nil {
+		return v.resolveAt(ctx, v.orb.resolutionURL(e.URL), did, opts...)
+	}
+
+	return v.resolveAt(ctx, e.URL+defaultResolutionPath, did, opts...)
+}
+
+// stakeholderAgentDoc returns the DID doc of a stakeholder if it lists a did-communication service, or nil otherwise
+func (v *VDRI) stakeholderAgentDoc(ctx context.Context, domain string) (*docdid.Doc, error) {
+	sfd, err := config.GetStakeholder(ctx, v.configService, domain, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stakeholder config: %w", err)
+	}
 
-		doc = resp
-		docBytes = respBytes
+	doc, err := v.getStakeholderDoc(ctx, sfd)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := docdid.LookupService(doc, didCommServiceType); !ok {
+		return nil, nil
 	}
 
 	return doc, nil
 }
 
+// Prefetch eagerly fetches and verifies the consortium config, stakeholder configs and endpoints of the given
+// consortium domain, warming the caches so that the first resolution doesn't pay the trust bootstrap cost
+func (v *VDRI) Prefetch(domain string) error {
+	if dv := v.forDomain(domain); dv != v {
+		return dv.Prefetch(domain)
+	}
+
+	if _, err := v.ValidateConsortium(domain); err != nil {
+		return fmt.Errorf("invalid consortium: %w", err)
+	}
+
+	v.setValidatedConsortium(domain)
+
+	if _, err := v.endpointService.GetEndpoints(domain); err != nil {
+		return errcode.Errorf(errcode.EndpointsUnavailable, "failed to get endpoints: %w", err)
+	}
+
+	return nil
+}
+
+func (v *VDRI) isValidatedConsortium(domain string) bool {
+	v.validatedConsortiumMutex.RLock()
+	defer v.validatedConsortiumMutex.RUnlock()
+
+	return v.validatedConsortium[domain]
+}
+
+func (v *VDRI) setValidatedConsortium(domain string) {
+	v.validatedConsortiumMutex.Lock()
+	defer v.validatedConsortiumMutex.Unlock()
+
+	v.validatedConsortium[domain] = true
+}
+
 // ValidateConsortium validate the config and endorsement of a consortium and its stakeholders
 // returns the duration after which the consortium config expires and needs re-validation
 func (v *VDRI) ValidateConsortium(consortiumDomain string) (*time.Duration, error) {
-	consortiumConfig, err := v.configService.GetConsortium(consortiumDomain, consortiumDomain)
+	return v.ValidateConsortiumWithContext(context.Background(), consortiumDomain)
+}
+
+// ValidateConsortiumWithContext validates a consortium like ValidateConsortium, aborting the fetches of its config
+// and of its stakeholders' configs, DIDs and did-configurations when the context is done
+func (v *VDRI) ValidateConsortiumWithContext(ctx context.Context, consortiumDomain string) (*time.Duration, error) {
+	if dv := v.forDomain(consortiumDomain); dv != v {
+		return dv.ValidateConsortiumWithContext(ctx, consortiumDomain)
+	}
+
+	return v.validateConsortium(ctx, consortiumDomain, nil)
+}
+
+func (v *VDRI) validateConsortium(ctx context.Context, consortiumDomain string,
+	trace *ResolutionTrace) (*time.Duration, error) {
+	consortiumConfig, err := config.GetConsortium(ctx, v.configService, consortiumDomain, consortiumDomain)
 	if err != nil {
-		return nil, fmt.Errorf("consortium invalid: %w", err)
+		trace.add(TraceStepConsortium, err, "consortium config of %s", consortiumDomain)
+
+		return nil, errcode.Errorf(errcode.ConsortiumUnavailable, "consortium invalid: %w", err)
 	}
 
-	stakeholders, err := v.selectStakeholders(consortiumConfig.Config)
+	trace.add(TraceStepConsortium, nil, "consortium config of %s: version %d, %d members, %d queries",
+		consortiumDomain, consortiumConfig.Config.Version, len(consortiumConfig.Config.Members),
+		consortiumConfig.Config.Policy.NumQueries)
+
+	var verified []string
+
+	_, localOverride := v.localConfigs[consortiumDomain]
+	if localOverride {
+		v.logger.Warnf("DEV MODE: stakeholders of consortium %s are NOT verified, as it uses a local config override",
+			consortiumDomain)
+		trace.add(TraceStepConsortium, nil, "local config override, stakeholders not verified")
+	} else if verified, err = v.verifyStakeholders(ctx, consortiumDomain, consortiumConfig, trace); err != nil {
+		return nil, err
+	}
+
+	lifetime := policy.New(consortiumConfig.Config).CacheLifetime()
+
+	v.recordTrust(consortiumDomain, consortiumConfig, verified, localOverride)
+	v.hooks.consortiumValidated(consortiumDomain)
+
+	return &lifetime, nil
+}
+
+// recordTrust appends a validated consortium config to the trust log, if it's a version not logged yet
+func (v *VDRI) recordTrust(consortiumDomain string, consortiumConfig *models.ConsortiumFileData, verified []string,
+	localOverride bool) {
+	if v.trustLog == nil {
+		return
+	}
+
+	entry, err := v.trustLog.Record(consortiumDomain, consortiumConfig, verified, localOverride)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch stakeholders: %w", err)
+		v.logger.Errorf("failed to record consortium %s config version %d to the trust log: %v", consortiumDomain,
+			consortiumConfig.Config.Version, err)
+
+		return
 	}
 
-	n := consortiumConfig.Config.Policy.NumQueries
-	if n == 0 || n > len(consortiumConfig.Config.Members) {
-		n = len(consortiumConfig.Config.Members)
+	if entry != nil {
+		v.logger.Infof("trusted consortium %s config version %d, recorded as trust log entry %d", consortiumDomain,
+			entry.Version, entry.Sequence)
+	}
+}
+
+// verifyStakeholders verifies that enough stakeholders of a consortium sign its config, returning the domains of the
+// stakeholders verified
+func (v *VDRI) verifyStakeholders(ctx context.Context, consortiumDomain string,
+	consortiumConfig *models.ConsortiumFileData, trace *ResolutionTrace) ([]string, error) {
+	stakeholders, warnings, err := v.selectStakeholders(ctx, consortiumDomain, consortiumConfig.Config)
+	if err != nil {
+		trace.add(TraceStepStakeholder, err, "stakeholders of consortium %s", consortiumDomain)
+
+		return nil, errcode.Errorf(errcode.StakeholdersUnavailable, "failed to fetch stakeholders: %w", err)
 	}
 
-	numVerifications := 0
+	n := policy.New(consortiumConfig.Config).NumStakeholderQueries()
+
+	var verified []string
 
 	verificationErrors := ""
 
-	for _, sfd := range stakeholders {
-		e := v.verifyStakeholder(consortiumConfig, sfd)
+	// the DIDs and did-configurations of the stakeholders are resolved and verified concurrently
+	errs := make([]error, len(stakeholders))
+
+	v.pool.Run(len(stakeholders), v.verificationConcurrency, func(i int) {
+		errs[i] = v.verifyStakeholder(ctx, consortiumConfig, stakeholders[i])
+	})
+
+	for i, sfd := range stakeholders {
+		e := errs[i]
+
+		trace.add(TraceStepStakeholder, e, "stakeholder %s", stakeholderDomain(sfd))
+
 		if e != nil {
 			verificationErrors += e.Error() + ", "
 			continue
 		}
 
-		numVerifications++
+		verified = append(verified, stakeholderDomain(sfd))
 	}
 
-	if numVerifications < n {
-		return nil, fmt.Errorf("insufficient stakeholders verified, all errors: [%s]", verificationErrors)
-	}
+	if len(verified) < n {
+		err = errcode.Errorf(errcode.EndorsementThresholdNotMet, "insufficient stakeholders verified, all errors: [%s]",
+			verificationErrors)
 
-	lifetime, err := consortiumConfig.CacheLifetime()
-	if err != nil {
-		return nil, fmt.Errorf("consortium lifetime error: %w", err)
+		trace.add(TraceStepConsortium, err, "%d of %d stakeholders verified", len(verified), n)
+
+		return nil, err
 	}
 
-	return &lifetime, nil
+	models.WarningHandler(v.hooks.verificationWarning).Warn(warnings, len(verified), n)
+
+	trace.add(TraceStepConsortium, nil, "%d of %d stakeholders verified", len(verified), n)
+
+	return verified, nil
 }
 
-func (v *VDRI) verifyStakeholder(cfd *models.ConsortiumFileData, sfd *models.StakeholderFileData) error {
+func (v *VDRI) verifyStakeholder(ctx context.Context, cfd *models.ConsortiumFileData,
+	sfd *models.StakeholderFileData) error {
 	s := sfd.Config
 	if s == nil {
 		return fmt.Errorf("stakeholder has nil config")
 	}
 
-	ep := s.Endpoints[rand.Intn(len(s.Endpoints))]
-
-	doc, e := v.sidetreeResolve(ep+"/identifiers", s.DID)
+	doc, e := v.getStakeholderDoc(ctx, sfd)
 	if e != nil {
-		return fmt.Errorf("can't resolve stakeholder DID: %w", e)
+		return e
 	}
 
-	// verify did configuration
-	e = v.didConfigService.VerifyStakeholder(s.Domain, doc)
-	if e != nil {
-		return fmt.Errorf("stakeholder did configuration failed to verify: %w", e)
+	doc = v.signingKeysDoc(doc)
+
+	if s.JWKSURL != "" {
+		doc, e = v.withStakeholderKeySet(ctx, s, doc)
+		if e != nil {
+			return e
+		}
 	}
 
-	_, e = didconfiguration.VerifyDIDSignature(cfd.JWS, doc)
+	_, e = v.verifyDIDSignature(cfd.JWS, cfd.COSE, doc)
 	if e != nil {
-		return fmt.Errorf("stakeholder does not sign consortium: %w", e)
+		return errcode.Errorf(errcode.StakeholderVerificationFailed, "stakeholder does not sign consortium: %w", e)
 	}
 
-	_, e = didconfiguration.VerifyDIDSignature(sfd.JWS, doc)
+	_, e = v.verifyDIDSignature(sfd.JWS, sfd.COSE, doc)
 	if e != nil {
-		return fmt.Errorf("stakeholder does not sign itself: %w", e)
+		return errcode.Errorf(errcode.StakeholderVerificationFailed, "stakeholder does not sign itself: %w", e)
 	}
 
 	return nil
 }
 
-// select n random stakeholders from the consortium (where n is the consortium's num-queries policy parameter)
-func (v *VDRI) selectStakeholders(consortium *models.Consortium) ([]*models.StakeholderFileData, error) {
-	n := consortium.Policy.NumQueries
-	if n == 0 || n > len(consortium.Members) {
-		n = len(consortium.Members)
+// verifyDIDSignature verifies a config file, signed as a JWS or a COSE message, with the keys of a DID doc
+func (v *VDRI) verifyDIDSignature(jws *jose.JSONWebSignature, msg *models.COSEMessage,
+	doc *docdid.Doc) ([]byte, error) {
+	if msg != nil {
+		return didconfiguration.VerifyDIDCOSESignature(msg, doc, v.signatureAlgs...)
 	}
 
-	perm := rand.Perm(len(consortium.Members))
+	return didconfiguration.VerifyDIDSignature(jws, doc, v.signatureAlgs...)
+}
 
-	successCount := 0
+// getStakeholderDoc returns the stakeholder's DID doc, verified against the stakeholder's did configuration.
+// Verified docs are cached keyed by DID and stakeholder file hash, so a changed stakeholder file is re-resolved.
+func (v *VDRI) getStakeholderDoc(ctx context.Context, sfd *models.StakeholderFileData) (*docdid.Doc, error) {
+	s := sfd.Config
+	key := stakeholderDocKey(sfd)
 
-	var out []*models.StakeholderFileData
+	if cached, err := v.stakeholderDocs.Get(key); err == nil {
+		if doc, ok := cached.(*docdid.Doc); ok {
+			return doc, nil
+		}
+	}
 
-	for i := 0; i < len(consortium.Members) && successCount < n; i++ {
-		sle := consortium.Members[perm[i]]
+	doc, e := v.resolveStakeholderDID(ctx, s)
+	if e != nil {
+		return nil, errcode.Errorf(errcode.StakeholderVerificationFailed, "can't resolve stakeholder DID: %w", e)
+	}
 
-		s, err := v.configService.GetStakeholder(sle.Domain, sle.Domain)
-		if err != nil {
-			continue
+	// verify did configuration
+	e = v.verifyDIDConfiguration(ctx, s.Domain, doc)
+	if e != nil {
+		return nil, errcode.Errorf(errcode.StakeholderVerificationFailed,
+			"stakeholder did configuration failed to verify: %w", e)
+	}
+
+	lifetime, e := sfd.CacheLifetime()
+	if e == nil && lifetime > 0 {
+		e = v.stakeholderDocs.SetWithExpire(key, doc, lifetime)
+	} else {
+		e = v.stakeholderDocs.Set(key, doc)
+	}
+
+	if e != nil {
+		v.logger.Warnf("failed to cache stakeholder doc for %s: %v", s.DID, e)
+	}
+
+	return doc, nil
+}
+
+// resolveStakeholderDID resolves a stakeholder DID. did:trustbloc DIDs are resolved using the stakeholder's sidetree
+// endpoints, other DID methods are resolved with the stakeholder resolver if one is set, or a built-in resolver.
+// did:key DIDs are resolved offline, so they can be used by stakeholders while bootstrapping a network before any
+// sidetree infrastructure exists.
+func (v *VDRI) resolveStakeholderDID(ctx context.Context, s *models.Stakeholder) (*docdid.Doc, error) {
+	method := didMethod(s.DID)
+
+	if method != trustblocDIDMethod {
+		if v.stakeholderResolver != nil {
+			return v.stakeholderResolver.Resolve(s.DID)
 		}
 
-		out = append(out, s)
+		switch method {
+		case web.DIDMethod:
+			return readWithContext(ctx, v.webVDRI, s.DID)
+		case keyDIDMethod:
+			return readWithContext(ctx, v.keyVDRI, s.DID)
+		}
+	}
 
-		successCount++
+	if len(s.Endpoints) == 0 {
+		return nil, fmt.Errorf("stakeholder %s has no endpoints", s.Domain)
 	}
 
-	if successCount < n {
-		return nil, fmt.Errorf("insufficient valid stakeholders")
+	ep := s.Endpoints[v.rand.Intn(len(s.Endpoints))]
+
+	return v.sidetreeResolve(ctx, ep+"/identifiers", s.DID)
+}
+
+// Tenant returns the VDRI of a tenant, with its own credentials, caches and validated consortiums, so that
+// the resolutions of a tenant don't affect the others
+func (v *VDRI) Tenant(tenant string) (*VDRI, error) {
+	tv, ok := v.tenantVDRIs[tenant]
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant %s", tenant)
 	}
 
-	return out, nil
+	return tv, nil
 }
 
-// canonicalizeDoc canonicalizes a DID doc using json-ld canonicalization
-func canonicalizeDoc(doc *docdid.Doc) ([]byte, error) {
-	marshaled, err := doc.JSONBytes()
-	if err != nil {
-		return nil, err
+// forDomain returns the VDRI with the option overrides of a consortium domain, or v if it has no overrides
+func (v *VDRI) forDomain(domain string) *VDRI {
+	if dv, ok := v.domainVDRIs[domain]; ok {
+		return dv
 	}
 
-	docMap := map[string]interface{}{}
+	return v
+}
 
-	err = json.Unmarshal(marshaled, &docMap)
-	if err != nil {
-		return nil, err
+// didDomain returns the consortium domain of a did:trustbloc DID, or an empty string for other DIDs
+func didDomain(did string) string {
+	parts := strings.SplitN(did, ":", expectedTrustblocDIDParts)
+	if len(parts) != expectedTrustblocDIDParts || parts[0] != "did" || parts[1] != trustblocDIDMethod {
+		return ""
+	}
+
+	return parts[domainDIDPart]
+}
+
+func didMethod(did string) string {
+	parts := strings.SplitN(did, ":", 3) // nolint: gomnd
+	if len(parts) < 3 || parts[0] != "did" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+func stakeholderDocKey(sfd *models.StakeholderFileData) string {
+	hash := sha256.Sum256(sfd.Payload())
+
+	return sfd.Config.DID + "#" + hex.EncodeToString(hash[:])
+}
+
+// select n random stakeholders from the consortium (where n is the consortium's num_queries policy parameter),
+// with warnings for the stakeholders whose config couldn't be fetched. Stakeholder configs are fetched concurrently,
+// in rounds fetching as many configs as are still missing, so no more stakeholders than needed are queried.
+func (v *VDRI) selectStakeholders(ctx context.Context, consortiumDomain string,
+	consortium *models.Consortium) ([]*models.StakeholderFileData, []*models.VerificationWarning, error) {
+	n := policy.New(consortium).NumStakeholderQueries()
+
+	perm := v.rand.Perm(len(consortium.Members))
+
+	var out []*models.StakeholderFileData
+
+	var warnings []*models.VerificationWarning
+
+	for next := 0; len(out) < n && next < len(perm); {
+		end := next + n - len(out)
+		if end > len(perm) {
+			end = len(perm)
+		}
+
+		members := make([]*models.StakeholderListElement, 0, end-next)
+		for _, i := range perm[next:end] {
+			members = append(members, consortium.Members[i])
+		}
+
+		next = end
+
+		configs, errs := v.fetchStakeholders(ctx, members)
+
+		for i, sle := range members {
+			if errs[i] != nil {
+				warnings = append(warnings, &models.VerificationWarning{
+					Consortium: consortiumDomain, Stakeholder: sle.Domain, Check: models.CheckStakeholderConfig,
+					Err: errs[i],
+				})
+
+				continue
+			}
+
+			out = append(out, configs[i])
+		}
+	}
+
+	if len(out) < n {
+		return nil, nil, errcode.Errorf(errcode.StakeholdersUnavailable, "insufficient valid stakeholders")
 	}
 
-	proc := jsonld.Default()
+	return out, warnings, nil
+}
+
+// fetchStakeholders concurrently fetches the configs of stakeholders, returning them with the fetch errors by index
+func (v *VDRI) fetchStakeholders(ctx context.Context,
+	members []*models.StakeholderListElement) ([]*models.StakeholderFileData, []error) {
+	configs := make([]*models.StakeholderFileData, len(members))
+	errs := make([]error, len(members))
+
+	v.pool.Run(len(members), v.verificationConcurrency, func(i int) {
+		configs[i], errs[i] = config.GetStakeholder(ctx, v.configService, members[i].Domain, members[i].Domain)
+	})
 
-	return proc.GetCanonicalDocument(docMap)
+	return configs, errs
 }
 
 // Option configures the bloc vdri
@@ -330,9 +1102,326 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 	}
 }
 
+// WithTLSPolicy option sets the minimum TLS version and the cipher suites enforced on all the connections of the
+// vdri. Defaults to TLS 1.2 or later with modern cipher suites, see tlspolicy.Default.
+func WithTLSPolicy(policy *tlspolicy.Policy) Option {
+	return func(opts *VDRI) {
+		opts.transportOpts.TLSPolicy = policy
+	}
+}
+
+// WithPinnedCertificates option requires the certificate chains presented by the given domains (consortium and
+// stakeholder domains, or the hosts of their endpoints) to match one of the domain's pins, protecting the trust
+// bootstrap against a compromised CA. A pin is the base64 encoded SHA-256 digest of a certificate or of its
+// public key info, optionally prefixed with "sha256/".
+func WithPinnedCertificates(pins map[string][]string) Option {
+	return func(opts *VDRI) {
+		opts.transportOpts.PinnedCertificates = pins
+	}
+}
+
+// WithConfigService option replaces the fetching and verification of consortium and stakeholder configs with the
+// given config service, eg. a mock from the mock package. Trust anchors and local config overrides don't apply to it.
+func WithConfigService(service configService) Option {
+	return func(opts *VDRI) {
+		opts.configService = service
+	}
+}
+
+// WithEndpointService option replaces the discovery and selection of the sidetree endpoints of consortiums with the
+// given endpoint service, eg. a mock from the mock package
+func WithEndpointService(service endpointService) Option {
+	return func(opts *VDRI) {
+		opts.endpointService = service
+	}
+}
+
+// WithDIDConfigService option replaces the verification of the did-configurations of stakeholders with the given
+// service, eg. a mock from the mock package
+func WithDIDConfigService(service didConfigService) Option {
+	return func(opts *VDRI) {
+		opts.didConfigService = service
+	}
+}
+
+// WithKeySetService option replaces the fetching of the JSON Web Key Sets of stakeholders with the given service,
+// eg. one sharing its cache with other components
+func WithKeySetService(service keySetService) Option {
+	return func(opts *VDRI) {
+		opts.keySetService = service
+	}
+}
+
+// WithTrustLog option records each consortium config version trusted by the VDRI to the given hash-linked log,
+// with the stakeholders it was verified by, so it can be audited which membership sets were trusted when
+func WithTrustLog(trustLog *trustlog.Log) Option {
+	return func(opts *VDRI) {
+		opts.trustLog = trustLog
+	}
+}
+
+// WithRandomSource option sets the source of randomness the stakeholders, mirrors and endpoints queried are sampled
+// with, eg. a seeded source so that resolutions are reproducible in tests and simulations. Defaults to
+// random.Default().
+func WithRandomSource(source random.Source) Option {
+	return func(opts *VDRI) {
+		opts.rand = source
+	}
+}
+
+// WithClock option sets the clock the lifetimes of cached configs, stakeholder docs and resolved docs are measured
+// with, eg. a fake clock to test cache expiry. Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(opts *VDRI) {
+		opts.now = now
+	}
+}
+
+// WithDialContext option sets the function dialing the connections used to fetch configs, did-configurations and
+// did:web docs, eg. to route the domains of a test consortium to a local server (see the trustbloctest package)
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(opts *VDRI) {
+		opts.transportOpts.DialContext = dial
+	}
+}
+
+// WithAllowInsecureHTTP option allows configs, did-configurations and resolution results to be fetched over plain
+// http from the given domains (hostname or host:port), eg. for local development. By default only https is used.
+func WithAllowInsecureHTTP(domains ...string) Option {
+	return func(opts *VDRI) {
+		opts.allowInsecure = domains
+	}
+}
+
+// WithSignatureAlgorithms option sets the allowlist of JWS algorithms accepted for the signatures of consortium and
+// stakeholder configs and domain linkage assertions. Defaults to models.DefaultSignatureAlgorithms (EdDSA, ES256).
+func WithSignatureAlgorithms(algs ...jose.SignatureAlgorithm) Option {
+	return func(opts *VDRI) {
+		opts.signatureAlgs = algs
+	}
+}
+
 // WithAuthToken add auth token
 func WithAuthToken(authToken string) Option {
 	return func(opts *VDRI) {
 		opts.authToken = authToken
 	}
 }
+
+// WithMaxIdleConnsPerHost option sets the maximum number of idle (keep-alive) connections kept per host
+func WithMaxIdleConnsPerHost(maxIdleConnsPerHost int) Option {
+	return func(opts *VDRI) {
+		opts.transportOpts.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+}
+
+// WithIdleConnTimeout option sets how long an idle (keep-alive) connection is kept open before being closed
+func WithIdleConnTimeout(idleConnTimeout time.Duration) Option {
+	return func(opts *VDRI) {
+		opts.transportOpts.IdleConnTimeout = idleConnTimeout
+	}
+}
+
+// WithStakeholderDocCacheSize option sets the maximum number of verified stakeholder DID docs kept in the LRU cache
+func WithStakeholderDocCacheSize(size int) Option {
+	return func(opts *VDRI) {
+		opts.docCacheSize = size
+	}
+}
+
+// WithDocCanonicalization option sets the canonicalization used to compare the docs resolved from
+// different endpoints. Defaults to JCSCanonicalization.
+func WithDocCanonicalization(canonicalization DocCanonicalization) Option {
+	return func(opts *VDRI) {
+		opts.canonicalization = canonicalization
+	}
+}
+
+// WithJSONLDDocumentLoader option sets the loader of the JSON-LD contexts of the docs canonicalized with
+// JSONLDCanonicalization. Defaults to a loader fetching the contexts over http and caching them for the process
+// lifetime. In networks without internet access, use a loader of preloaded contexts (see NewPreloadedDocumentLoader).
+func WithJSONLDDocumentLoader(loader ld.DocumentLoader) Option {
+	return func(opts *VDRI) {
+		opts.documentLoader = loader
+	}
+}
+
+// WithMaxEndpoints option bounds how many endpoints are contacted for a single resolution. If more endpoints are
+// selected for the consortium of a DID, a random subset of them is used. Defaults to no limit.
+func WithMaxEndpoints(max int) Option {
+	return func(opts *VDRI) {
+		opts.maxEndpoints = max
+	}
+}
+
+// WithVerificationConcurrency option sets how many stakeholders of a consortium are verified concurrently while
+// validating it: their configs, DIDs and did-configurations are fetched and verified in parallel. Defaults to 8,
+// 1 verifies the stakeholders one at a time.
+func WithVerificationConcurrency(n int) Option {
+	return func(opts *VDRI) {
+		opts.verificationConcurrency = n
+	}
+}
+
+// WithWorkerPoolSize option bounds how many goroutines the VDRI starts for its concurrent and background tasks:
+// stakeholder verification, health checks, endpoint probes and resolution cache refreshes. When all workers are
+// busy, concurrent tasks run on the calling goroutine and cache refreshes are skipped until a later resolution.
+// The pool is shared with the VDRIs of tenants and domains. Defaults to 16.
+func WithWorkerPoolSize(size int) Option {
+	return func(opts *VDRI) {
+		opts.poolSize = size
+	}
+}
+
+// withWorkerPool sets the worker pool, so that the VDRIs of tenants and domains share the pool of the VDRI
+func withWorkerPool(pool *workerpool.Pool) Option {
+	return func(opts *VDRI) {
+		opts.pool = pool
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size, in bytes, of the responses read from consortium and
+// stakeholder servers, sidetree endpoints and resolvers: config files, did-configurations and resolution results.
+// Larger responses are refused without being read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *VDRI) {
+		opts.maxResponseSize = max
+	}
+}
+
+// WithResolutionCacheTTL option enables caching resolved DID docs for the given TTL. Resolutions with the no-cache
+// option, or for a specific version of a doc, bypass the cache. By default resolved docs aren't cached.
+func WithResolutionCacheTTL(ttl time.Duration) Option {
+	return func(opts *VDRI) {
+		opts.docCacheTTL = ttl
+	}
+}
+
+// WithResolutionCacheSize option sets the maximum number of DID docs kept in the resolution cache, evicting the
+// least recently used docs first. Defaults to 1000.
+func WithResolutionCacheSize(size int) Option {
+	return func(opts *VDRI) {
+		opts.resolutionSize = size
+	}
+}
+
+// WithCache option stores the docs of the resolution cache in the given cache instead of in memory, eg. to share
+// them between resolver instances. The cache is only used when enabled with WithResolutionCacheTTL. The VDRIs of
+// the domains and tenants that don't override it store their docs in the cache under keys of their own.
+func WithCache(cache ResolutionCache) Option {
+	return func(opts *VDRI) {
+		opts.resolutionCache = cache
+	}
+}
+
+// withCacheScope sets the prefix of the resolution cache keys of a tenant or domain VDRI
+func withCacheScope(scope string) Option {
+	return func(opts *VDRI) {
+		opts.cacheScope = scope
+	}
+}
+
+// WithStaleWhileRevalidate option makes the resolution cache return an expired doc immediately, flagged as stale in
+// its metadata, while refreshing it in the background. This trades strict freshness for responsiveness.
+func WithStaleWhileRevalidate() Option {
+	return func(opts *VDRI) {
+		opts.staleDocs = true
+	}
+}
+
+// WithDomainOptions option overrides options (eg. the resolver URL, TLS config, auth token or cache TTLs) for the DIDs
+// and configs of a consortium domain, eg. to use a partner's staging network along with a production consortium.
+// The domain gets its own caches and connections, configured with the options of the VDRI and the overrides.
+func WithDomainOptions(domain string, overrides ...Option) Option {
+	return func(opts *VDRI) {
+		if opts.domainOpts == nil {
+			opts.domainOpts = make(map[string][]Option)
+		}
+
+		opts.domainOpts[domain] = append(opts.domainOpts[domain], overrides...)
+	}
+}
+
+// withoutDomainOptions removes the domain overrides, so that the VDRI of a domain doesn't create VDRIs of its own
+func withoutDomainOptions() Option {
+	return func(opts *VDRI) {
+		opts.domainOpts = nil
+	}
+}
+
+// WithTenant option registers a tenant, eg. a customer of a hosted resolver, with option overrides such as its auth
+// token. The tenant's VDRI, returned by Tenant, is configured with the options of the VDRI and the overrides, and
+// doesn't share caches, connections or validated consortiums with the VDRI or other tenants.
+func WithTenant(tenant string, overrides ...Option) Option {
+	return func(opts *VDRI) {
+		if opts.tenantOpts == nil {
+			opts.tenantOpts = make(map[string][]Option)
+		}
+
+		opts.tenantOpts[tenant] = append(opts.tenantOpts[tenant], overrides...)
+	}
+}
+
+// withoutTenants removes the tenants, so that the VDRI of a tenant doesn't create VDRIs of its own
+func withoutTenants() Option {
+	return func(opts *VDRI) {
+		opts.tenantOpts = nil
+	}
+}
+
+// WithLogger option sets the logger of the VDRI, passed down to the config, did-configuration and resolution services
+// it creates, so host applications control all its log output. Defaults to the standard logrus logger.
+func WithLogger(logger log.Logger) Option {
+	return func(opts *VDRI) {
+		opts.logger = logger
+	}
+}
+
+// WithHooks option sets callbacks invoked during the lifecycle of resolutions, eg. for audit logging
+func WithHooks(hooks Hooks) Option {
+	return func(opts *VDRI) {
+		opts.hooks = hooks
+	}
+}
+
+// WithConsortiumQuorum option makes consortium configs trusted only if at least quorum of their sources agree:
+// the consortium domain and the stakeholders queried according to the consortium policy, which mirror the config.
+// This detects a compromised consortium domain. By default, the copies of all queried stakeholders must match
+// the config of the consortium domain.
+func WithConsortiumQuorum(quorum int) Option {
+	return func(opts *VDRI) {
+		opts.configQuorum = quorum
+	}
+}
+
+// WithLocalConfigOverride option makes the VDRI read the consortium and stakeholder configs of a consortium
+// domain from a local directory instead of fetching them, so development networks (eg. testnet.trustbloc.local)
+// don't need a signing bootstrap. The directory contains `<domain>.json` config files, signed or not.
+//
+// DEV MODE ONLY: the signatures of the overridden configs and the stakeholder endorsements of the consortium
+// are NOT verified.
+func WithLocalConfigOverride(domain, dir string) Option {
+	return func(opts *VDRI) {
+		if opts.localConfigs == nil {
+			opts.localConfigs = make(map[string]string)
+		}
+
+		opts.localConfigs[domain] = dir
+	}
+}
+
+// WithStakeholderResolver option sets the resolver used for stakeholder DIDs of methods other than did:trustbloc,
+// eg. an aries vdri registry. By default did:web DIDs are resolved with a built-in resolver.
+func WithStakeholderResolver(resolver didResolver) Option {
+	return func(opts *VDRI) {
+		opts.stakeholderResolver = resolver
+	}
+}
+
+// WithDIDCommResolver enables resolving DIDs over DIDComm: stakeholders whose DID doc lists a did-communication
+// service are asked to resolve DIDs by their agent, instead of their sidetree endpoints.
+func WithDIDCommResolver(resolver didcommResolver) Option {
+	return func(opts *VDRI) {
+		opts.didcommResolver = resolver
+	}
+}
@@ -6,26 +6,41 @@ SPDX-License-Identifier: Apache-2.0
 package trustbloc
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/fingerprint"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/trustbloc-did-method/pkg/errcode"
 	mockconfig "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/config"
 	mockdidconf "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/didconfiguration"
 	mockendpoint "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/endpoint"
+	mocklog "github.com/trustbloc/trustbloc-did-method/pkg/internal/mock/log"
+	"github.com/trustbloc/trustbloc-did-method/pkg/mock"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/didconfiguration"
 	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/models"
+	"github.com/trustbloc/trustbloc-did-method/pkg/vdri/trustbloc/trustlog"
 )
 
 func TestVDRI_Accept(t *testing.T) {
@@ -48,15 +63,6 @@ func TestVDRI_Store(t *testing.T) {
 	})
 }
 
-func TestVDRI_Build(t *testing.T) {
-	t.Run("test error", func(t *testing.T) {
-		v := New()
-		_, err := v.Build(nil)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "build method not supported for did bloc")
-	})
-}
-
 func httpVdriFunc(doc *did.Doc, err error) func(url string) (v vdri, err error) {
 	return func(url string) (v vdri, e error) {
 		return &mockvdri.MockVDRI{
@@ -80,7 +86,13 @@ func confSignature(configBytes []byte, keys []jose.SigningKey) (*jose.JSONWebSig
 		return nil, err
 	}
 
-	return signer.Sign(configBytes)
+	jws, err := signer.Sign(configBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// round-trip so the signature headers are populated, as for configs fetched from a server
+	return jose.ParseSigned(jws.FullSerialize())
 }
 
 func signConfig(config interface{}, keys []jose.SigningKey) (string, error) {
@@ -161,6 +173,54 @@ func signedStakeholderFileData(t *testing.T, stakeholder *models.Stakeholder, ke
 	}
 }
 
+func TestVDRI_HTTPVDRICache(t *testing.T) {
+	v := New(WithMaxIdleConnsPerHost(2), WithIdleConnTimeout(time.Second))
+	require.Equal(t, 2, v.httpTransport.MaxIdleConnsPerHost)
+	require.Equal(t, time.Second, v.httpTransport.IdleConnTimeout)
+	require.NotNil(t, v.tlsConfig.ClientSessionCache)
+
+	r1, err := v.getHTTPVDRI("https://example.com/sidetree")
+	require.NoError(t, err)
+
+	r2, err := v.getHTTPVDRI("https://example.com/sidetree")
+	require.NoError(t, err)
+	require.True(t, r1 == r2)
+
+	r3, err := v.getHTTPVDRI("https://other.example.com/sidetree")
+	require.NoError(t, err)
+	require.False(t, r1 == r3)
+}
+
+func TestVDRI_HTTPVDRITransport(t *testing.T) {
+	var conns int32
+
+	serv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", didLDJSON)
+		fmt.Fprint(w, testDoc)
+	}))
+	serv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+
+	serv.Start()
+	defer serv.Close()
+
+	v := New(WithAllowInsecureHTTP("127.0.0.1"))
+
+	for i := 0; i < 2; i++ {
+		resolver, err := v.getHTTPVDRI(serv.URL)
+		require.NoError(t, err)
+
+		_, err = resolver.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+	}
+
+	// both reads are sent over the pooled connection of the shared transport
+	require.EqualValues(t, 1, atomic.LoadInt32(&conns))
+}
+
 func TestVDRI_Read(t *testing.T) {
 	t.Run("test error from get http vdri for resolver url", func(t *testing.T) {
 		v := New(WithResolverURL("url"))
@@ -210,6 +270,7 @@ func TestVDRI_Read(t *testing.T) {
 		doc, err := v.Read("did:1223")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "wrong did did:1223")
+		require.Equal(t, errcode.InvalidDID, errcode.Of(err))
 		require.Nil(t, doc)
 	})
 
@@ -226,6 +287,7 @@ func TestVDRI_Read(t *testing.T) {
 		doc, err := v.Read("did:trustbloc:testnet:123")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "discover error")
+		require.Equal(t, errcode.EndpointsUnavailable, errcode.Of(err))
 		require.Nil(t, doc)
 
 		v.endpointService = &mockendpoint.MockEndpointService{
@@ -246,6 +308,7 @@ func TestVDRI_Read(t *testing.T) {
 		doc, err = v.Read("did:trustbloc:testnet:123")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "list of endpoints is empty")
+		require.Equal(t, errcode.EndpointsUnavailable, errcode.Of(err))
 		require.Nil(t, doc)
 	})
 
@@ -340,6 +403,73 @@ func TestVDRI_Read(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "did:trustbloc:testnet:123", doc.ID)
 	})
+
+	t.Run("test success with max endpoints", func(t *testing.T) {
+		v := New(WithMaxEndpoints(2))
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return []*models.Endpoint{{URL: "url.1"}, {URL: "url.2"}, {URL: "url.3"}, {URL: "url.4"}}, nil
+			}}
+
+		contacted := make(map[string]bool)
+
+		v.getHTTPVDRI = func(url string) (vdri, error) {
+			contacted[url] = true
+
+			return httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123"}, nil)(url)
+		}
+
+		v.validatedConsortium["testnet"] = true
+
+		doc, err := v.Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:123", doc.ID)
+		require.Len(t, contacted, 2)
+	})
+
+	t.Run("test concurrent reads", func(t *testing.T) {
+		v := New()
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return []*models.Endpoint{{URL: "url"}, {URL: "url.2"}}, nil
+			}}
+
+		v.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:testnet:123"}, nil)
+
+		cfd := signedConsortiumFileData(t, &models.Consortium{Domain: "testnet"}, ed25519SigningKey(t, keyJSON))
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return cfd, nil
+			},
+		}
+
+		const numReads = 10
+
+		var wg sync.WaitGroup
+
+		errs := make(chan error, numReads)
+
+		for i := 0; i < numReads; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				_, err := v.Read(fmt.Sprintf("did:trustbloc:testnet%d:123", i%2))
+				errs <- err
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+	})
 }
 
 const (
@@ -387,11 +517,243 @@ const (
 }`
 )
 
+func TestVDRI_Endpoints(t *testing.T) {
+	t.Run("resolver url", func(t *testing.T) {
+		v := New(WithResolverURL("https://resolver.example.com"))
+
+		endpoints, err := v.Endpoints("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, []*models.Endpoint{{URL: "https://resolver.example.com"}}, endpoints)
+	})
+
+	t.Run("consortium endpoints", func(t *testing.T) {
+		v := New()
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return []*models.Endpoint{{URL: "https://" + domain + "/sidetree"}}, nil
+			}}
+
+		v.validatedConsortium["testnet"] = true
+
+		endpoints, err := v.Endpoints("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, []*models.Endpoint{{URL: "https://testnet/sidetree"}}, endpoints)
+
+		endpoints, err = v.GetEndpoints("testnet")
+		require.NoError(t, err)
+		require.Len(t, endpoints, 1)
+	})
+
+	t.Run("error - wrong did", func(t *testing.T) {
+		_, err := New().Endpoints("did:trustbloc:123")
+		require.EqualError(t, err, "wrong did did:trustbloc:123")
+	})
+
+	t.Run("error - invalid consortium", func(t *testing.T) {
+		v := New()
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("config error")
+			},
+		}
+
+		_, err := v.GetEndpoints("testnet")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid consortium")
+	})
+
+	t.Run("error - get endpoints", func(t *testing.T) {
+		v := New()
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return nil, fmt.Errorf("discovery error")
+			}}
+
+		v.validatedConsortium["testnet"] = true
+
+		_, err := v.GetEndpoints("testnet")
+		require.EqualError(t, err, "failed to get endpoints: discovery error")
+	})
+}
+
+func TestVDRI_LocalConfigOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localconfig")
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	// unsigned configs, with a stakeholder DID that can't be resolved
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "testnet.trustbloc.local.json"), []byte(
+		`{"domain":"testnet.trustbloc.local","members":[{"domain":"stakeholder.one","did":"did:web:none"}]}`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stakeholder.one.json"), []byte(
+		`{"domain":"stakeholder.one","did":"did:web:none","endpoints":["http://localhost:48326/sidetree"]}`), 0600))
+
+	trustLog, err := trustlog.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	logger := mocklog.NewLogger()
+
+	v := New(WithLocalConfigOverride("testnet.trustbloc.local", dir), WithTrustLog(trustLog), WithLogger(logger))
+
+	trace := &ResolutionTrace{}
+
+	_, err = v.validateConsortium(context.Background(), "testnet.trustbloc.local", trace)
+	require.NoError(t, err)
+	require.Equal(t, "local config override, stakeholders not verified", trace.Steps[1].Details)
+
+	// the warnings of the local config service and of the vdri are logged to the injected logger
+	warnings := logger.Entries("warning")
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0].Message, "uses the local config override in "+dir)
+	require.Contains(t, warnings[1].Message, "stakeholders of consortium testnet.trustbloc.local are NOT verified")
+	require.Len(t, logger.Entries("info"), 1)
+
+	entries, err := trustLog.Entries("testnet.trustbloc.local")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, entries[0].Evidence.LocalOverride)
+	require.Empty(t, entries[0].Evidence.Stakeholders)
+	require.Equal(t, map[string]string{"stakeholder.one": "did:web:none"}, entries[0].Members)
+
+	endpoints, err := v.GetEndpoints("testnet.trustbloc.local")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost:48326/sidetree"}, endpointURLs(endpoints))
+
+	// other domains aren't overridden
+	_, err = v.ValidateConsortium("consortium.invalid")
+	require.Error(t, err)
+}
+
+func TestVDRI_DomainOptions(t *testing.T) {
+	v := New(WithResolverURL("https://resolver.example.com"), WithAuthToken("token"),
+		WithDomainOptions("staging.example.com", WithResolverURL("https://staging.example.com/resolver"),
+			WithAuthToken("staging-token")))
+
+	require.Len(t, v.domainVDRIs, 1)
+
+	staging := v.domainVDRIs["staging.example.com"]
+	require.Equal(t, "staging-token", staging.authToken)
+	require.Empty(t, staging.domainVDRIs)
+	require.Equal(t, "token", v.authToken)
+
+	endpoints, err := v.Endpoints("did:trustbloc:testnet:123")
+	require.NoError(t, err)
+	require.Equal(t, "https://resolver.example.com", endpoints[0].URL)
+
+	endpoints, err = v.Endpoints("did:trustbloc:staging.example.com:123")
+	require.NoError(t, err)
+	require.Equal(t, "https://staging.example.com/resolver", endpoints[0].URL)
+
+	staging.getHTTPVDRI = httpVdriFunc(&did.Doc{ID: "did:trustbloc:staging.example.com:123"}, nil)
+	v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("unexpected resolution"))
+
+	doc, err := v.Read("did:trustbloc:staging.example.com:123")
+	require.NoError(t, err)
+	require.Equal(t, "did:trustbloc:staging.example.com:123", doc.ID)
+
+	_, err = v.Read("did:trustbloc:testnet:123")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected resolution")
+
+	require.NoError(t, v.Close())
+}
+
+func TestVDRI_Tenant(t *testing.T) {
+	v := New(WithAuthToken("token"),
+		WithTenant("tenant1", WithAuthToken("token1")),
+		WithTenant("tenant2", WithAuthToken("token2")))
+
+	tenant1, err := v.Tenant("tenant1")
+	require.NoError(t, err)
+	require.Equal(t, "token1", tenant1.authToken)
+	require.Empty(t, tenant1.tenantVDRIs)
+
+	tenant2, err := v.Tenant("tenant2")
+	require.NoError(t, err)
+	require.Equal(t, "token2", tenant2.authToken)
+
+	// validated consortiums and caches aren't shared
+	tenant1.setValidatedConsortium("testnet")
+	require.True(t, tenant1.isValidatedConsortium("testnet"))
+	require.False(t, tenant2.isValidatedConsortium("testnet"))
+	require.False(t, v.isValidatedConsortium("testnet"))
+	require.NotSame(t, tenant1.stakeholderDocs, tenant2.stakeholderDocs)
+	require.NotSame(t, tenant1.httpTransport, tenant2.httpTransport)
+
+	_, err = v.Tenant("tenant3")
+	require.EqualError(t, err, "unknown tenant tenant3")
+
+	require.NoError(t, v.Close())
+}
+
+func TestVDRI_Prefetch(t *testing.T) {
+	cfd := signedConsortiumFileData(t, &models.Consortium{Domain: "testnet"}, ed25519SigningKey(t, keyJSON))
+
+	t.Run("success", func(t *testing.T) {
+		v := New()
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return cfd, nil
+			},
+		}
+
+		endpointsFetched := false
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				endpointsFetched = true
+
+				return []*models.Endpoint{{URL: "url"}}, nil
+			}}
+
+		require.NoError(t, v.Prefetch("testnet"))
+		require.True(t, v.isValidatedConsortium("testnet"))
+		require.True(t, endpointsFetched)
+	})
+
+	t.Run("failure - invalid consortium", func(t *testing.T) {
+		v := New()
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return nil, fmt.Errorf("consortium error")
+			},
+		}
+
+		err := v.Prefetch("testnet")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "consortium error")
+		require.False(t, v.isValidatedConsortium("testnet"))
+	})
+
+	t.Run("failure - endpoints", func(t *testing.T) {
+		v := New()
+
+		v.configService = &mockconfig.MockConfigService{
+			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
+				return cfd, nil
+			},
+		}
+
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(domain string) (endpoints []*models.Endpoint, err error) {
+				return nil, fmt.Errorf("endpoints error")
+			}}
+
+		err := v.Prefetch("testnet")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get endpoints: endpoints error")
+	})
+}
+
 func TestVDRI_ValidateConsortium(t *testing.T) {
 	sigKey := ed25519SigningKey(t, keyJSON)
 
 	t.Run("success - no stakeholders to verify", func(t *testing.T) {
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		var confFile string
 
@@ -416,7 +778,7 @@ func TestVDRI_ValidateConsortium(t *testing.T) {
 	})
 
 	t.Run("failure - consortium invalid", func(t *testing.T) {
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		confFile := `RU^&I*&*&OH`
 
@@ -431,7 +793,7 @@ func TestVDRI_ValidateConsortium(t *testing.T) {
 	})
 
 	t.Run("failure - stakeholders don't sign consortium config", func(t *testing.T) {
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		var consortiumFile, stakeholderFile, didConfFile string
 
@@ -480,7 +842,7 @@ func TestVDRI_ValidateConsortium(t *testing.T) {
 	})
 
 	t.Run("success - verify with one stakeholder", func(t *testing.T) {
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		var consortiumFile, stakeholderFile, didConfFile string
 
@@ -530,7 +892,7 @@ func TestVDRI_ValidateConsortium(t *testing.T) {
 	})
 
 	t.Run("failure - can't resolve stakeholder DID", func(t *testing.T) {
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		var consortiumFile, stakeholderFile, didConfFile string
 
@@ -627,7 +989,7 @@ func TestVDRI_ValidateConsortium(t *testing.T) {
 
 		didConfFile = string(didConfBytes)
 
-		v := New()
+		v := New(WithAllowInsecureHTTP("127.0.0.1"))
 
 		v.configService = &mockconfig.MockConfigService{
 			GetConsortiumFunc: func(u string, d string) (*models.ConsortiumFileData, error) {
@@ -726,7 +1088,7 @@ func Test_verifyStakeholder(t *testing.T) {
 				},
 			}
 
-			err = v.verifyStakeholder(cfd, sfd)
+			err = v.verifyStakeholder(context.Background(), cfd, sfd)
 
 			if test.isErr {
 				require.Error(t, err)
@@ -738,12 +1100,237 @@ func Test_verifyStakeholder(t *testing.T) {
 	}
 }
 
+func Test_verifyStakeholderCache(t *testing.T) {
+	sigKey := ed25519SigningKey(t, keyJSON)
+
+	mockDoc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	cfd := signedConsortiumFileData(t, dummyConsortium("consortium.url", "stakeholder.url"), sigKey)
+	sfd := signedStakeholderFileData(t, dummyStakeholder("stakeholder.url"), sigKey)
+
+	v := New(WithStakeholderDocCacheSize(1))
+
+	resolveCount := 0
+	v.getHTTPVDRI = func(url string) (vdri, error) {
+		resolveCount++
+
+		return httpVdriFunc(mockDoc, nil)(url)
+	}
+
+	v.didConfigService = &mockdidconf.MockDIDConfigService{
+		VerifyStakeholderFunc: func(domain string, doc *did.Doc) error {
+			return nil
+		},
+	}
+
+	require.NoError(t, v.verifyStakeholder(context.Background(), cfd, sfd))
+	require.NoError(t, v.verifyStakeholder(context.Background(), cfd, sfd))
+	require.Equal(t, 1, resolveCount)
+
+	t.Run("changed stakeholder file is re-resolved", func(t *testing.T) {
+		stakeholder := dummyStakeholder("stakeholder.url")
+		stakeholder.Previous = "previous"
+
+		require.NoError(t, v.verifyStakeholder(context.Background(), cfd, signedStakeholderFileData(t, stakeholder, sigKey)))
+		require.Equal(t, 2, resolveCount)
+
+		// the LRU cache holds a single doc, so the original file was evicted
+		require.NoError(t, v.verifyStakeholder(context.Background(), cfd, sfd))
+		require.Equal(t, 3, resolveCount)
+	})
+
+	t.Run("failed did configuration is not cached", func(t *testing.T) {
+		v := New()
+		v.getHTTPVDRI = httpVdriFunc(mockDoc, nil)
+		v.didConfigService = &mockdidconf.MockDIDConfigService{
+			VerifyStakeholderFunc: func(domain string, doc *did.Doc) error {
+				return fmt.Errorf("bad did configuration")
+			},
+		}
+
+		err := v.verifyStakeholder(context.Background(), cfd, sfd)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad did configuration")
+		require.Equal(t, 0, v.stakeholderDocs.Len(false))
+	})
+}
+
+type mockResolver struct {
+	doc *did.Doc
+	err error
+}
+
+func (m *mockResolver) Resolve(string, ...vdriapi.ResolveOpts) (*did.Doc, error) {
+	return m.doc, m.err
+}
+
+func Test_resolveStakeholderDID(t *testing.T) {
+	mockDoc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	t.Run("trustbloc DID resolved from stakeholder endpoints", func(t *testing.T) {
+		v := New(WithStakeholderResolver(&mockResolver{err: fmt.Errorf("unexpected")}))
+		v.getHTTPVDRI = httpVdriFunc(mockDoc, nil)
+
+		doc, err := v.resolveStakeholderDID(context.Background(), &models.Stakeholder{DID: "did:trustbloc:testnet:123",
+			Endpoints: []string{"foo"}})
+		require.NoError(t, err)
+		require.Equal(t, mockDoc, doc)
+	})
+
+	t.Run("other DID method resolved with stakeholder resolver", func(t *testing.T) {
+		v := New(WithStakeholderResolver(&mockResolver{doc: mockDoc}))
+		v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("unexpected"))
+
+		doc, err := v.resolveStakeholderDID(context.Background(), &models.Stakeholder{DID: "did:web:stakeholder.one"})
+		require.NoError(t, err)
+		require.Equal(t, mockDoc, doc)
+	})
+
+	t.Run("did:web resolved with built-in resolver", func(t *testing.T) {
+		v := New()
+		v.webVDRI = &mockvdri.MockVDRI{
+			ReadFunc: func(didID string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				return mockDoc, nil
+			}}
+
+		doc, err := v.resolveStakeholderDID(context.Background(), &models.Stakeholder{DID: "did:web:stakeholder.one"})
+		require.NoError(t, err)
+		require.Equal(t, mockDoc, doc)
+	})
+
+	t.Run("did:key resolved offline", func(t *testing.T) {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		didKey, _ := fingerprint.CreateDIDKey(pub)
+
+		v := New()
+		v.getHTTPVDRI = httpVdriFunc(nil, fmt.Errorf("unexpected"))
+
+		doc, err := v.resolveStakeholderDID(context.Background(), &models.Stakeholder{DID: didKey})
+		require.NoError(t, err)
+		require.Equal(t, didKey, doc.ID)
+		require.Len(t, doc.PublicKey, 1)
+		require.Equal(t, []byte(pub), doc.PublicKey[0].Value)
+	})
+
+	t.Run("failure - no endpoints", func(t *testing.T) {
+		v := New()
+
+		_, err := v.resolveStakeholderDID(context.Background(),
+			&models.Stakeholder{Domain: "stakeholder.one", DID: "did:example:123"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stakeholder stakeholder.one has no endpoints")
+	})
+}
+
+type mockDIDCommResolver struct {
+	agentDoc *did.Doc
+	doc      *did.Doc
+	err      error
+}
+
+func (m *mockDIDCommResolver) Resolve(_ string, agentDoc *did.Doc) (*did.Doc, error) {
+	m.agentDoc = agentDoc
+
+	return m.doc, m.err
+}
+
+func TestVDRI_ReadWithDIDComm(t *testing.T) {
+	mockDoc, err := did.ParseDocument([]byte(testDoc))
+	require.NoError(t, err)
+
+	agentDoc := &did.Doc{ID: "did:web:stakeholder.one", Service: []did.Service{{
+		ID: "did:web:stakeholder.one#didcomm", Type: "did-communication", ServiceEndpoint: "https://agent.one",
+		RecipientKeys: []string{"key1"}}}}
+
+	newVDRI := func(stakeholderDoc *did.Doc, didcomm *mockDIDCommResolver) *VDRI {
+		v := New(WithStakeholderResolver(&mockResolver{doc: stakeholderDoc}), WithDIDCommResolver(didcomm))
+		v.getHTTPVDRI = httpVdriFunc(mockDoc, nil)
+		v.validatedConsortium["testnet"] = true
+		v.didConfigService = &mockdidconf.MockDIDConfigService{}
+		v.endpointService = &mockendpoint.MockEndpointService{
+			GetEndpointsFunc: func(string) ([]*models.Endpoint, error) {
+				return []*models.Endpoint{{URL: "url", Domain: "stakeholder.one"}}, nil
+			}}
+		v.configService = &mockconfig.MockConfigService{
+			GetStakeholderFunc: func(string, string) (*models.StakeholderFileData, error) {
+				return &models.StakeholderFileData{Config: &models.Stakeholder{Domain: "stakeholder.one",
+					DID: stakeholderDoc.ID}}, nil
+			}}
+
+		return v
+	}
+
+	t.Run("resolved by the stakeholder's agent", func(t *testing.T) {
+		didcomm := &mockDIDCommResolver{doc: &did.Doc{ID: "did:trustbloc:testnet:agent"}}
+
+		doc, err := newVDRI(agentDoc, didcomm).Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, "did:trustbloc:testnet:agent", doc.ID)
+		require.Equal(t, agentDoc, didcomm.agentDoc)
+	})
+
+	t.Run("resolved by sidetree endpoint if the stakeholder has no agent", func(t *testing.T) {
+		didcomm := &mockDIDCommResolver{err: fmt.Errorf("unexpected")}
+
+		doc, err := newVDRI(&did.Doc{ID: "did:web:stakeholder.one"}, didcomm).Read("did:trustbloc:testnet:123")
+		require.NoError(t, err)
+		require.Equal(t, mockDoc.ID, doc.ID)
+	})
+
+	t.Run("failure - didcomm resolution error", func(t *testing.T) {
+		didcomm := &mockDIDCommResolver{err: fmt.Errorf("agent unreachable")}
+
+		_, err := newVDRI(agentDoc, didcomm).Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "agent unreachable")
+	})
+
+	t.Run("failure - stakeholder config error", func(t *testing.T) {
+		v := newVDRI(agentDoc, &mockDIDCommResolver{})
+		v.configService = &mockconfig.MockConfigService{
+			GetStakeholderFunc: func(string, string) (*models.StakeholderFileData, error) {
+				return nil, fmt.Errorf("config error")
+			}}
+
+		_, err := v.Read("did:trustbloc:testnet:123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get stakeholder config: config error")
+	})
+}
+
+func TestVDRI_AllowInsecureHTTP(t *testing.T) {
+	_, err := New().getHTTPVDRI("http://localhost/sidetree")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "only https is allowed")
+
+	_, err = New(WithAllowInsecureHTTP("localhost")).getHTTPVDRI("http://localhost/sidetree")
+	require.NoError(t, err)
+
+	_, err = New().configService.GetConsortium("http://localhost", "localhost")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "only https is allowed")
+}
+
+func TestVDRI_PinnedCertificates(t *testing.T) {
+	v := New(WithPinnedCertificates(map[string][]string{"example.com": {"sha256/pin"}}))
+	require.NotNil(t, v.httpTransport.DialTLS)
+
+	_, err := v.getHTTPVDRI("https://example.com/sidetree")
+	require.NoError(t, err)
+
+	require.Nil(t, New().httpTransport.DialTLS)
+}
+
 func TestVDRI_Close(t *testing.T) {
 	v := New()
 	require.NoError(t, v.Close())
 }
 
-func Test_canonicalizeDoc(t *testing.T) {
+func Test_comparableDocEqual(t *testing.T) {
 	var docs = [][2]string{
 		{`{
   "@context": ["https://w3id.org/did/v1"],
@@ -920,21 +1507,29 @@ func Test_canonicalizeDoc(t *testing.T) {
 		"type":"JwsVerificationKey2020"
 	}`
 
-	t.Run("test canonicalization of equal docs", func(t *testing.T) {
-		for _, pair := range docs {
-			doc1, err := did.ParseDocument([]byte(pair[0]))
-			require.NoError(t, err)
-			doc2, err := did.ParseDocument([]byte(pair[1]))
-			require.NoError(t, err)
+	cmpDoc := func(docJSON string) *comparableDoc {
+		doc, err := did.ParseDocument([]byte(docJSON))
+		require.NoError(t, err)
 
-			doc1Canonicalized, err := canonicalizeDoc(doc1)
-			require.NoError(t, err)
-			doc2Canonicalized, err := canonicalizeDoc(doc2)
-			require.NoError(t, err)
+		c, err := newComparableDoc(doc, JCSCanonicalization, nil)
+		require.NoError(t, err)
+
+		return c
+	}
 
-			require.Equal(t, doc1Canonicalized, doc2Canonicalized)
+	t.Run("test comparison of equal docs", func(t *testing.T) {
+		for _, pair := range docs {
+			equal, err := cmpDoc(pair[0]).equal(cmpDoc(pair[1]))
+			require.NoError(t, err)
+			require.True(t, equal)
 		}
 	})
+
+	t.Run("test comparison of different docs", func(t *testing.T) {
+		equal, err := cmpDoc(docs[0][0]).equal(cmpDoc(docs[1][0]))
+		require.NoError(t, err)
+		require.False(t, equal)
+	})
 }
 
 func TestOpts(t *testing.T) {
@@ -952,6 +1547,51 @@ func TestOpts(t *testing.T) {
 
 		require.Equal(t, "test", v.tlsConfig.ServerName)
 		require.Equal(t, "tk1", v.authToken)
+		require.Equal(t, JCSCanonicalization, v.canonicalization)
+
+		WithDocCanonicalization(JSONLDCanonicalization)(v)
+		require.Equal(t, JSONLDCanonicalization, v.canonicalization)
+
+		loader := &mockDocumentLoader{}
+		WithJSONLDDocumentLoader(loader)(v)
+		require.Equal(t, loader, v.documentLoader)
+	})
+}
+
+func TestVDRI_ServiceOptions(t *testing.T) {
+	consortium := dummyConsortium("consortium.example.com", "stakeholder.example.com")
+
+	t.Run("failure - config service", func(t *testing.T) {
+		v := New(WithConfigService(mock.NewConfigService().
+			WithError("consortium.example.com", fmt.Errorf("config error"))))
+
+		_, err := v.ValidateConsortium("consortium.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "config error")
+	})
+
+	t.Run("failure - did configuration service", func(t *testing.T) {
+		v := New(WithConfigService(mock.NewConfigService().
+			WithConsortium("consortium.example.com", &models.ConsortiumFileData{Config: consortium}).
+			WithStakeholder("stakeholder.example.com",
+				&models.StakeholderFileData{Config: dummyStakeholder("stakeholder.example.com")})),
+			WithDIDConfigService(mock.NewDIDConfigService().
+				WithError("stakeholder.example.com", fmt.Errorf("did configuration error"))),
+			WithStakeholderResolver(&mockResolver{doc: &did.Doc{ID: "did:example:foo"}}))
+
+		_, err := v.ValidateConsortium("consortium.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did configuration error")
+	})
+
+	t.Run("failure - endpoint service", func(t *testing.T) {
+		v := New(WithEndpointService(mock.NewEndpointService().
+			WithError("consortium.example.com", fmt.Errorf("endpoint error"))))
+		v.validatedConsortium["consortium.example.com"] = true
+
+		_, err := v.GetEndpoints("consortium.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "endpoint error")
 	})
 }
 
@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	docdid "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+
+	"github.com/trustbloc/trustbloc-did-method/pkg/internal/common/transport"
+	"github.com/trustbloc/trustbloc-did-method/pkg/tlspolicy"
+)
+
+const (
+	// DIDMethod is the did:web method name
+	DIDMethod = "web"
+
+	didPrefix         = "did:" + DIDMethod + ":"
+	wellKnownDIDPath  = "/.well-known"
+	didDocumentSuffix = "/did.json"
+)
+
+// VDRI resolves did:web DIDs by fetching the DID doc from the web domain the DID refers to
+type VDRI struct {
+	httpClient      *http.Client
+	tlsConfig       *tls.Config
+	transport       http.RoundTripper
+	maxResponseSize int64
+}
+
+// New creates a did:web vdri
+func New(opts ...Option) *VDRI {
+	v := &VDRI{httpClient: &http.Client{}, maxResponseSize: transport.DefaultMaxResponseSize}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.transport == nil {
+		v.transport = &http.Transport{TLSClientConfig: tlspolicy.Apply(v.tlsConfig)}
+	}
+
+	v.httpClient.Transport = v.transport
+
+	return v
+}
+
+// Accept did method
+func (v *VDRI) Accept(method string) bool {
+	return method == DIDMethod
+}
+
+// Close vdri
+func (v *VDRI) Close() error {
+	return nil
+}
+
+// Store did doc
+func (v *VDRI) Store(doc *docdid.Doc, by *[]vdriapi.ModifiedBy) error {
+	return nil
+}
+
+// Build did doc
+func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*docdid.Doc, error) {
+	return nil, fmt.Errorf("build method not supported for did web")
+}
+
+// Read resolves a did:web DID
+func (v *VDRI) Read(did string, opts ...vdriapi.ResolveOpts) (*docdid.Doc, error) {
+	docURL, err := DocumentURL(did)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.httpClient.Get(docURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch did document from %s: %w", docURL, err)
+	}
+
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	body, err := transport.ReadLimited(resp.Body, v.maxResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read did document from %s: %w", docURL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch did document from %s: status '%d' body %s",
+			docURL, resp.StatusCode, body)
+	}
+
+	doc, err := docdid.ParseDocument(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse did document from %s: %w", docURL, err)
+	}
+
+	if doc.ID != did {
+		return nil, fmt.Errorf("did document id %s doesn't match did %s", doc.ID, did)
+	}
+
+	return doc, nil
+}
+
+// DocumentURL returns the url of the DID doc of the given did:web DID,
+// eg. did:web:example.com:user:alice resolves to https://example.com/user/alice/did.json
+func DocumentURL(did string) (string, error) {
+	if !strings.HasPrefix(did, didPrefix) {
+		return "", fmt.Errorf("not a did:web DID: %s", did)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(did, didPrefix), ":")
+
+	domain, err := url.PathUnescape(parts[0])
+	if err != nil || domain == "" {
+		return "", fmt.Errorf("invalid did:web domain in %s", did)
+	}
+
+	path := wellKnownDIDPath
+
+	if len(parts) > 1 {
+		segments := make([]string, len(parts)-1)
+
+		for i, p := range parts[1:] {
+			segment, err := url.PathUnescape(p)
+			if err != nil || segment == "" {
+				return "", fmt.Errorf("invalid did:web path in %s", did)
+			}
+
+			segments[i] = segment
+		}
+
+		path = "/" + strings.Join(segments, "/")
+	}
+
+	return "https://" + domain + path + didDocumentSuffix, nil
+}
+
+// Option configures the did:web vdri
+type Option func(opts *VDRI)
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *VDRI) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithTransport option sets the http transport used to fetch DID docs, allowing a connection pool to be shared
+// with other services. If set, the tls.Config option is ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *VDRI) {
+		opts.transport = transport
+	}
+}
+
+// WithMaxResponseSize option sets the maximum size of a DID doc, in bytes. Larger responses are refused
+// without being read entirely. Defaults to 1 MiB.
+func WithMaxResponseSize(max int64) Option {
+	return func(opts *VDRI) {
+		opts.maxResponseSize = max
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const docTemplate = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "%s",
+  "publicKey": [{
+    "id": "%s#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "%s",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }]
+}`
+
+func TestDocumentURL(t *testing.T) {
+	tests := []struct {
+		did    string
+		docURL string
+		err    string
+	}{
+		{did: "did:web:example.com", docURL: "https://example.com/.well-known/did.json"},
+		{did: "did:web:example.com:user:alice", docURL: "https://example.com/user/alice/did.json"},
+		{did: "did:web:localhost%3A8443", docURL: "https://localhost:8443/.well-known/did.json"},
+		{did: "did:key:z6Mk", err: "not a did:web DID"},
+		{did: "did:web:", err: "invalid did:web domain"},
+		{did: "did:web:example.com::alice", err: "invalid did:web path"},
+	}
+
+	for _, test := range tests {
+		docURL, err := DocumentURL(test.did)
+		if test.err != "" {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, test.docURL, docURL)
+	}
+}
+
+func TestVDRI_Read(t *testing.T) {
+	var docDID, doc string
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/did.json" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprint(w, doc)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	docDID = "did:web:" + strings.Replace(u.Host, ":", "%3A", 1)
+
+	v := New(WithTransport(srv.Client().Transport))
+
+	t.Run("success", func(t *testing.T) {
+		doc = fmt.Sprintf(docTemplate, docDID, docDID, docDID)
+
+		result, err := v.Read(docDID)
+		require.NoError(t, err)
+		require.Equal(t, docDID, result.ID)
+		require.Len(t, result.PublicKey, 1)
+	})
+
+	t.Run("failure - doc id mismatch", func(t *testing.T) {
+		doc = fmt.Sprintf(docTemplate, "did:web:other.com", docDID, docDID)
+
+		_, err := v.Read(docDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match did")
+	})
+
+	t.Run("failure - invalid doc", func(t *testing.T) {
+		doc = "{"
+
+		_, err := v.Read(docDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse did document")
+	})
+
+	t.Run("failure - doc too large", func(t *testing.T) {
+		doc = fmt.Sprintf(docTemplate, docDID, docDID, docDID)
+
+		_, err := New(WithTransport(srv.Client().Transport), WithMaxResponseSize(10)).Read(docDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "response body too large")
+	})
+
+	t.Run("failure - not found", func(t *testing.T) {
+		_, err := v.Read(docDID + ":user")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status '404'")
+	})
+
+	t.Run("failure - invalid did", func(t *testing.T) {
+		_, err := v.Read("did:key:z6Mk")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not a did:web DID")
+	})
+
+	t.Run("failure - untrusted server", func(t *testing.T) {
+		_, err := New().Read(docDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch did document")
+	})
+}
+
+func TestVDRI(t *testing.T) {
+	v := New()
+	require.True(t, v.Accept("web"))
+	require.False(t, v.Accept("trustbloc"))
+	require.NoError(t, v.Store(nil, nil))
+	require.NoError(t, v.Close())
+
+	_, err := v.Build(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package version holds the build information of the did-method binaries, set at build time with ldflags by the
+// did-method-rest and did-method-cli targets of the Makefile.
+package version
+
+import "runtime"
+
+// Build information, set at build time with ldflags
+// nolint: gochecknoglobals
+var (
+	// Version is the release version, "dev" for development builds
+	Version = "dev"
+	// GitCommit is the git commit the binary was built from
+	GitCommit = ""
+	// BuildDate is the date the binary was built, in RFC 3339 format
+	BuildDate = ""
+)
+
+// SidetreeProtocolVersion is the version of the sidetree protocol of the operations created and resolved by the method
+const SidetreeProtocolVersion = "0.1.0"
+
+// Info is the version and build information of a did-method binary
+type Info struct {
+	Version                  string   `json:"version"`
+	GitCommit                string   `json:"gitCommit,omitempty"`
+	BuildDate                string   `json:"buildDate,omitempty"`
+	GoVersion                string   `json:"goVersion"`
+	SidetreeProtocolVersions []string `json:"sidetreeProtocolVersions"`
+}
+
+// Get returns the version and build information of the running binary
+func Get() *Info {
+	return &Info{
+		Version:                  Version,
+		GitCommit:                GitCommit,
+		BuildDate:                BuildDate,
+		GoVersion:                runtime.Version(),
+		SidetreeProtocolVersions: []string{SidetreeProtocolVersion},
+	}
+}
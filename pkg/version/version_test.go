@@ -0,0 +1,20 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	require.Equal(t, "dev", info.Version)
+	require.Equal(t, runtime.Version(), info.GoVersion)
+	require.Equal(t, []string{SidetreeProtocolVersion}, info.SidetreeProtocolVersions)
+}